@@ -6,6 +6,7 @@ toolchain go1.24.6
 
 require (
 	github.com/mark3labs/mcp-go v0.41.1
+	go.etcd.io/bbolt v1.4.3
 	helm.sh/helm/v3 v3.19.0
 	k8s.io/api v0.34.1
 	k8s.io/apimachinery v0.34.1
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetFlowControl returns a handler function for the getFlowControl tool.
+// It reports the API Priority and Fairness configuration together with the
+// queued, executing and rejected requests of each priority level. The result
+// is serialized to JSON and returned.
+func GetFlowControl(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		flowControl, err := client.GetFlowControl(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get flow control: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(flowControl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
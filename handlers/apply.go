@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rolloutKinds are the kinds applyResource waits for when waitForRollout is set.
+var rolloutKinds = map[string]bool{"Deployment": true, "StatefulSet": true, "DaemonSet": true}
+
+// ApplyResource returns a handler function for the applyResource tool.
+// It applies the objects of a YAML or JSON manifest with server-side apply,
+// optionally as a server-side dry run or waiting for the rollouts of applied
+// workloads. The applied objects are serialized to JSON and returned.
+func ApplyResource(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		manifest, err := getRequiredStringArg(args, "manifest")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		fieldManager := getStringArg(args, "fieldManager", k8s.DefaultFieldManager)
+		force := getBoolArg(args, "force", false)
+		dryRun := getBoolArg(args, "dryRun", false)
+		rolloutTimeout, err := rolloutWaitArg(args)
+		if err != nil {
+			return nil, err
+		}
+		if dryRun {
+			ctx = k8s.WithDryRun(ctx)
+		}
+
+		applied, err := client.ApplyManifest(ctx, namespace, manifest, fieldManager, force)
+		if err != nil {
+			return nil, err
+		}
+		if rolloutTimeout > 0 && !dryRun {
+			// The timeout bounds the wait for all applied workloads together
+			waitCtx, cancel := context.WithTimeout(ctx, rolloutTimeout)
+			defer cancel()
+			for _, object := range applied {
+				kind, _ := object["kind"].(string)
+				if !rolloutKinds[kind] {
+					continue
+				}
+				name, _ := object["name"].(string)
+				objectNamespace, _ := object["namespace"].(string)
+				rollout, err := client.WaitForRollout(waitCtx, kind, name, objectNamespace, rolloutTimeout)
+				if err != nil {
+					return nil, fmt.Errorf("failed to wait for rollout of %s %s: %w", kind, name, err)
+				}
+				object["rollout"] = rollout
+			}
+		}
+
+		jsonResponse, err := json.Marshal(map[string]interface{}{
+			"applied":      applied,
+			"fieldManager": fieldManager,
+			"dryRun":       dryRun,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// PreviewAdmission returns a handler function for the previewAdmission tool.
+// It dry-runs the objects of a manifest through admission and reports how
+// they would be defaulted and mutated. The previews are serialized to JSON and
+// returned.
+func PreviewAdmission(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		manifest, err := getRequiredStringArg(args, "manifest")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		includeObject := getBoolArg(args, "includeObject", true)
+
+		preview, err := client.PreviewAdmission(ctx, namespace, manifest, includeObject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview admission: %w", err)
+		}
+		if objects, ok := preview["objects"].([]map[string]interface{}); ok {
+			setResultMetadata(ctx, "itemCount", len(objects))
+		}
+
+		jsonResponse, err := json.Marshal(preview)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// namespaceArgs are the tool parameters that name the namespace a call acts in.
+var namespaceArgs = []string{"namespace", "firstNamespace", "secondNamespace"}
+
+// namespaceFreeTools are the tools without a namespace parameter that read no
+// namespaced objects, or only those of the caller's session, and so remain
+// available to clients limited to specific namespaces. Any other tool without
+// a namespace parameter reads across namespaces, e.g. listNamespaces or the
+// node tools, and is refused to such clients.
+var namespaceFreeTools = map[string]bool{
+	"getServerInfo":       true,
+	"listContexts":        true,
+	"getAPIResources":     true,
+	"cacheStats":          true,
+	"refreshCapabilities": true,
+	"listSavedQueries":    true,
+	"deleteSavedQuery":    true,
+	"getWatchedEvents":    true,
+	"stopWatchEvents":     true,
+	"listPortForwards":    true,
+	"stopPortForward":     true,
+	"undoLastChange":      true,
+	"helmRepoList":        true,
+	"helmRepoAdd":         true,
+}
+
+// AuthContextFunc returns an HTTP context function that authenticates the
+// bearer token of each request against config and attaches the matching
+// client to the request context. Requests without a known token carry no
+// client and are rejected by AuthorizationMiddleware.
+func AuthContextFunc(config *policy.AuthConfig) func(ctx context.Context, r *http.Request) context.Context {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			return ctx
+		}
+		if client, ok := config.Authenticate(strings.TrimSpace(token)); ok {
+			return policy.WithClient(ctx, client)
+		}
+		return ctx
+	}
+}
+
+// AuthorizationMiddleware returns a middleware that enforces the tool,
+// namespace and impersonation permissions of the authenticated client before
+// handlers run.
+// Mutating tools, as reported by isWriteTool, are checked against the client's
+// write namespaces. Clients limited to a subset of namespaces must name the
+// namespace explicitly for tools that accept one, as reported by
+// hasNamespaceParam, since an omitted namespace means "default" or all
+// namespaces, and may not call tools that read across namespaces.
+func AuthorizationMiddleware(isWriteTool, hasNamespaceParam func(name string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			client, ok := policy.ClientFromContext(ctx)
+			if !ok {
+				return nil, fmt.Errorf("unauthorized: a valid bearer token is required")
+			}
+			if err := authorizeToolCall(client, request, isWriteTool(request.Params.Name), hasNamespaceParam(request.Params.Name)); err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// NamespacePolicyMiddleware returns a middleware that refuses calls naming a
+// namespace the policy excludes before handlers run. Kubernetes clients
+// enforce the policy on every request they send as well; tools whose API
+// calls they do not see, as reported by unguarded, such as the Helm tools,
+// must name the namespace explicitly while the policy is restricted.
+func NamespacePolicyMiddleware(namespaces *policy.NamespacePolicy, unguarded func(name string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args, _ := request.Params.Arguments.(map[string]interface{})
+			requested := requestNamespaces(args)
+			if len(requested) == 0 && namespaces.Restricted() && unguarded(request.Params.Name) {
+				return nil, fmt.Errorf("refused by namespace policy: tool %s must set the namespace", request.Params.Name)
+			}
+			for _, namespace := range requested {
+				if err := namespaces.Check(namespace); err != nil {
+					return nil, fmt.Errorf("refused by namespace policy: %w", err)
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// authorizeToolCall checks a single tool call against a client's policy.
+func authorizeToolCall(client *policy.ClientPolicy, request mcp.CallToolRequest, write, hasNamespaceParam bool) error {
+	tool := request.Params.Name
+	if !client.AllowsTool(tool) {
+		return fmt.Errorf("forbidden: client %s may not call tool %s", client.Name, tool)
+	}
+
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	user, groups, err := requestImpersonation(args)
+	if err != nil {
+		return err
+	}
+	if (user != "" || len(groups) > 0) && !client.AllowsImpersonating(user, groups) {
+		return fmt.Errorf("forbidden: client %s may not impersonate user %q with groups %v", client.Name, user, groups)
+	}
+
+	namespaces := requestNamespaces(args)
+	if len(namespaces) == 0 && hasNamespaceParam && client.NamespaceRestricted(write) {
+		return fmt.Errorf("forbidden: client %s is limited to specific namespaces and must set the namespace for tool %s", client.Name, tool)
+	}
+	if len(namespaces) == 0 && !hasNamespaceParam && !namespaceFreeTools[tool] && client.NamespaceRestricted(write) {
+		return fmt.Errorf("forbidden: client %s is limited to specific namespaces and may not call tool %s, which reads across namespaces", client.Name, tool)
+	}
+	if client.NamespaceRestricted(write) {
+		// A step without a namespace runs in the namespace of its manifest
+		// or in "default", which the client cannot be trusted to name
+		for i, step := range planSteps(args) {
+			if getStringArg(step, "namespace", "") == "" {
+				return fmt.Errorf("forbidden: client %s is limited to specific namespaces and must set the namespace of %s step %d", client.Name, tool, i+1)
+			}
+		}
+	}
+	for _, namespace := range namespaces {
+		if !client.AllowsNamespace(namespace, write) {
+			access := "read"
+			if write {
+				access = "write"
+			}
+			return fmt.Errorf("forbidden: client %s may not %s in namespace %s", client.Name, access, namespace)
+		}
+	}
+	return nil
+}
+
+// requestNamespaces returns the namespaces named by a tool call's arguments,
+// including those of executePlan steps. A step without a namespace is
+// reported as the namespace of its manifest, or else "default", which is
+// where it runs; the namespace a manifest names is reported even when the
+// step's namespace overrides it. The "*" of listResources across all
+// namespaces names no namespace, like an omitted one.
+func requestNamespaces(args map[string]interface{}) []string {
+	var namespaces []string
+	for _, key := range namespaceArgs {
+		if namespace := getStringArg(args, key, ""); namespace != "" && namespace != "*" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	for _, step := range planSteps(args) {
+		namespace := getStringArg(step, "namespace", "")
+		manifestNamespace := ""
+		if manifest := getStringArg(step, "manifest", ""); manifest != "" {
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err == nil {
+				manifestNamespace = obj.GetNamespace()
+			}
+		}
+		switch {
+		case namespace == "" && manifestNamespace == "":
+			namespaces = append(namespaces, "default")
+		case namespace == "":
+			namespaces = append(namespaces, manifestNamespace)
+		case manifestNamespace == "" || manifestNamespace == namespace:
+			namespaces = append(namespaces, namespace)
+		default:
+			namespaces = append(namespaces, namespace, manifestNamespace)
+		}
+	}
+	return namespaces
+}
+
+// requestImpersonation returns the user, with a service account given as its
+// user name, and the groups a tool call's arguments ask to impersonate.
+func requestImpersonation(args map[string]interface{}) (string, []string, error) {
+	user := getStringArg(args, "impersonateUser", "")
+	if serviceAccount := getStringArg(args, "impersonateServiceAccount", ""); serviceAccount != "" && user == "" {
+		var err error
+		if user, err = k8s.ServiceAccountUser(serviceAccount); err != nil {
+			return "", nil, fmt.Errorf("invalid argument impersonateServiceAccount: %w", err)
+		}
+	}
+	groups, err := getStringListArg(args, "impersonateGroups")
+	if err != nil {
+		return "", nil, err
+	}
+	return user, groups, nil
+}
+
+// planSteps returns the executePlan steps of a tool call's arguments.
+func planSteps(args map[string]interface{}) []map[string]interface{} {
+	rawSteps, _ := args["steps"].([]interface{})
+	steps := make([]map[string]interface{}, 0, len(rawSteps))
+	for _, raw := range rawSteps {
+		step, _ := raw.(map[string]interface{})
+		steps = append(steps, step)
+	}
+	return steps
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+	"github.com/reza-gholizade/k8s-mcp-server/tools"
+)
+
+// TestAuthorizeToolCall tests tool and namespace permissions of a client limited to its team's namespaces
+func TestAuthorizeToolCall(t *testing.T) {
+	client := &policy.ClientPolicy{
+		Name:            "team-a",
+		Tools:           []string{"get*", "list*", "setImage", "executePlan"},
+		WriteNamespaces: []string{"team-a", "team-a-*"},
+	}
+
+	tests := []struct {
+		name              string
+		tool              string
+		args              map[string]interface{}
+		write             bool
+		hasNamespaceParam bool
+		wantErr           string
+	}{
+		{"read any namespace", "listResources", map[string]interface{}{"namespace": "kube-system"}, false, true, ""},
+		{"read all namespaces", "listResources", map[string]interface{}{}, false, true, ""},
+		{"read all namespaces with a wildcard", "listResources", map[string]interface{}{"namespace": "*"}, false, true, ""},
+		{"tool not allowed", "deleteResource", map[string]interface{}{"namespace": "team-a"}, true, true, "may not call tool deleteResource"},
+		{"write own namespace", "setImage", map[string]interface{}{"namespace": "team-a-staging"}, true, true, ""},
+		{"write other namespace", "setImage", map[string]interface{}{"namespace": "team-b"}, true, true, "may not write in namespace team-b"},
+		{"write without namespace", "setImage", map[string]interface{}{}, true, true, "must set the namespace"},
+		{"plan step without namespace", "executePlan", map[string]interface{}{"steps": []interface{}{
+			map[string]interface{}{"operation": "scale", "namespace": "team-a"},
+			map[string]interface{}{"operation": "scale"},
+		}}, true, false, "must set the namespace of executePlan step 2"},
+		{"plan manifest without step namespace", "executePlan", map[string]interface{}{"steps": []interface{}{
+			map[string]interface{}{"operation": "applyManifest", "manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n  namespace: team-a\n"},
+		}}, true, false, "must set the namespace of executePlan step 1"},
+		{"plan manifest in other namespace", "executePlan", map[string]interface{}{"steps": []interface{}{
+			map[string]interface{}{"operation": "applyManifest", "namespace": "team-a", "manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n  namespace: kube-system\n"},
+		}}, true, false, "may not write in namespace kube-system"},
+		{"plan manifest in own namespace", "executePlan", map[string]interface{}{"steps": []interface{}{
+			map[string]interface{}{"operation": "applyManifest", "namespace": "team-a", "manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n  namespace: team-a\n"},
+		}}, true, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tt.tool
+			request.Params.Arguments = tt.args
+
+			err := authorizeToolCall(client, request, tt.write, tt.hasNamespaceParam)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Expected call to be allowed, got %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestAuthorizeToolCallImpersonation tests that clients may only impersonate the users, service accounts and groups their policy lists
+func TestAuthorizeToolCallImpersonation(t *testing.T) {
+	client := &policy.ClientPolicy{
+		Name:              "team-a",
+		ImpersonateUsers:  []string{"alice", "system:serviceaccount:team-a:*"},
+		ImpersonateGroups: []string{"team-a"},
+	}
+
+	tests := []struct {
+		name    string
+		client  *policy.ClientPolicy
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"no impersonation", client, map[string]interface{}{"namespace": "team-a"}, ""},
+		{"allowed user", client, map[string]interface{}{"impersonateUser": "alice"}, ""},
+		{"other user", client, map[string]interface{}{"impersonateUser": "bob"}, `may not impersonate user "bob"`},
+		{"allowed service account", client, map[string]interface{}{"impersonateServiceAccount": "team-a/ci"}, ""},
+		{"other service account", client, map[string]interface{}{"impersonateServiceAccount": "kube-system/admin"}, "may not impersonate user \"system:serviceaccount:kube-system:admin\""},
+		{"invalid service account", client, map[string]interface{}{"impersonateServiceAccount": "admin"}, "invalid argument impersonateServiceAccount"},
+		{"allowed group", client, map[string]interface{}{"impersonateUser": "alice", "impersonateGroups": []interface{}{"team-a"}}, ""},
+		{"other group", client, map[string]interface{}{"impersonateUser": "alice", "impersonateGroups": []interface{}{"team-a", "system:masters"}}, "may not impersonate"},
+		{"groups without user", client, map[string]interface{}{"impersonateGroups": []interface{}{"team-a"}}, "may not impersonate"},
+		{"client without impersonation", &policy.ClientPolicy{Name: "platform"}, map[string]interface{}{"impersonateUser": "alice"}, "client platform may not impersonate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "listResources"
+			request.Params.Arguments = tt.args
+
+			err := authorizeToolCall(tt.client, request, false, true)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Expected call to be allowed, got %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestAuthorizationMiddlewareCrossNamespaceTools tests refusing tools that read across namespaces to clients limited to specific namespaces
+func TestAuthorizationMiddlewareCrossNamespaceTools(t *testing.T) {
+	registered := map[string]mcp.Tool{}
+	for _, tool := range []mcp.Tool{
+		tools.GetPodsOnNodeTool(), tools.GetEvictionRiskTool(), tools.GetPortAllocationsTool(), tools.GetKubeletStatsTool(),
+		tools.GetNodeLogsTool(), tools.GetIngressesTool(), tools.ListNamespacesTool(), tools.ClusterInventoryTool(),
+		tools.ListResourcesTool(), tools.CompareNamespacesTool(), tools.ListPortForwardsTool(),
+	} {
+		registered[tool.Name] = tool
+	}
+	hasNamespaceParam := func(name string) bool {
+		_, ok := registered[name].InputSchema.Properties["namespace"]
+		return ok
+	}
+	isWriteTool := func(name string) bool { return false }
+	handler := AuthorizationMiddleware(isWriteTool, hasNamespaceParam)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	restricted := policy.WithClient(context.Background(), &policy.ClientPolicy{Name: "team-a", Namespaces: []string{"team-a"}})
+	unrestricted := policy.WithClient(context.Background(), &policy.ClientPolicy{Name: "platform"})
+
+	for _, tool := range []string{
+		"getPodsOnNode", "getEvictionRisk", "getPortAllocations", "getKubeletStats",
+		"getNodeLogs", "getIngresses", "listNamespaces", "clusterInventory",
+	} {
+		t.Run(tool, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tool
+			request.Params.Arguments = map[string]interface{}{"nodeName": "worker-1"}
+
+			if _, err := handler(restricted, request); err == nil || !strings.Contains(err.Error(), "reads across namespaces") {
+				t.Errorf("Expected %s to be refused to a client limited to specific namespaces, got %v", tool, err)
+			}
+			if _, err := handler(unrestricted, request); err != nil {
+				t.Errorf("Expected %s to be allowed to an unrestricted client, got %v", tool, err)
+			}
+		})
+	}
+
+	allowed := map[string]map[string]interface{}{
+		"listResources":     {"namespace": "team-a"},
+		"compareNamespaces": {"firstNamespace": "team-a", "secondNamespace": "team-a"},
+		"listPortForwards":  {},
+	}
+	for tool, args := range allowed {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool
+		request.Params.Arguments = args
+		if _, err := handler(restricted, request); err != nil {
+			t.Errorf("Expected %s to be allowed to a client limited to specific namespaces, got %v", tool, err)
+		}
+	}
+}
+
+// TestNamespacePolicyMiddleware tests refusing calls that name excluded namespaces
+func TestNamespacePolicyMiddleware(t *testing.T) {
+	namespaces, err := policy.NewNamespacePolicy([]string{"team-*"}, []string{"team-*-prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unguarded := func(name string) bool { return strings.HasPrefix(name, "helm") }
+	handler := NamespacePolicyMiddleware(namespaces, unguarded)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"allowed namespace", "listResources", map[string]interface{}{"namespace": "team-a"}, ""},
+		{"denied namespace", "listResources", map[string]interface{}{"namespace": "team-a-prod"}, "denied by namespace pattern"},
+		{"namespace not allowed", "compareNamespaces", map[string]interface{}{"firstNamespace": "team-a", "secondNamespace": "default"}, "not in the namespace allowlist"},
+		{"guarded tool without namespace", "listResources", map[string]interface{}{}, ""},
+		{"helm tool without namespace", "helmList", map[string]interface{}{}, "must set the namespace"},
+		{"plan manifest in denied namespace", "executePlan", map[string]interface{}{"steps": []interface{}{
+			map[string]interface{}{"operation": "applyManifest", "manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n  namespace: team-a-prod\n"},
+		}}, "denied by namespace pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tt.tool
+			request.Params.Arguments = tt.args
+
+			_, err := handler(context.Background(), request)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Expected call to be allowed, got %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
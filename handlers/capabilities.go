@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// CapabilityTools holds the tools that need an optional API of the cluster,
+// such as the metrics API or a CRD, and registers them with the MCP server
+// only while the cluster serves it, so clients are not offered tools that are
+// bound to fail.
+type CapabilityTools struct {
+	mu           sync.Mutex
+	server       *server.MCPServer
+	client       *k8s.Client
+	tools        map[string][]server.ServerTool
+	capabilities map[string]bool
+	errors       []string
+	probedAt     time.Time
+}
+
+// NewCapabilityTools creates a CapabilityTools registering tools with s
+// according to the capabilities client probes.
+func NewCapabilityTools(s *server.MCPServer, client *k8s.Client) *CapabilityTools {
+	return &CapabilityTools{server: s, client: client, tools: map[string][]server.ServerTool{}}
+}
+
+// Add records a tool that needs capability. It is registered by the next
+// Refresh that finds the capability available.
+func (t *CapabilityTools) Add(capability string, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tools[capability] = append(t.tools[capability], server.ServerTool{Tool: tool, Handler: handler})
+}
+
+// Refresh probes the cluster's capabilities, registers the tools of the
+// available ones and removes the others, notifying clients when the tool
+// list changes. If the probe fails, the registered tools are left as they
+// are, and all tools are registered if no probe has succeeded yet, rather
+// than hiding tools for a cluster that may just be unreachable.
+// Returns a map with the "capabilities", the "tools" of each, whether they
+// are registered, and "errors", or an error if the probe failed.
+func (t *CapabilityTools) Refresh(ctx context.Context) (map[string]interface{}, error) {
+	capabilities, errors, err := t.client.ProbeCapabilities(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		if t.capabilities == nil {
+			for _, tools := range t.tools {
+				t.server.AddTools(tools...)
+			}
+		}
+		return nil, err
+	}
+	t.capabilities, t.errors, t.probedAt = capabilities, errors, time.Now()
+
+	var added []server.ServerTool
+	var removed []string
+	for capability, tools := range t.tools {
+		for _, tool := range tools {
+			registered := t.server.GetTool(tool.Tool.Name) != nil
+			switch {
+			case capabilities[capability] && !registered:
+				added = append(added, tool)
+			case !capabilities[capability] && registered:
+				removed = append(removed, tool.Tool.Name)
+			}
+		}
+	}
+	if len(added) > 0 {
+		t.server.AddTools(added...)
+	}
+	if len(removed) > 0 {
+		t.server.DeleteTools(removed...)
+	}
+
+	result := t.summary()
+	result["added"] = toolNames(added)
+	sort.Strings(removed)
+	result["removed"] = removed
+	return result, nil
+}
+
+// summary describes the capabilities found by the last probe and their
+// tools. The caller must hold t.mu.
+func (t *CapabilityTools) summary() map[string]interface{} {
+	tools := map[string]interface{}{}
+	for capability, entries := range t.tools {
+		tools[capability] = map[string]interface{}{
+			"available": t.capabilities[capability],
+			"tools":     toolNames(entries),
+		}
+	}
+	return map[string]interface{}{
+		"probedAt":     t.probedAt,
+		"capabilities": t.capabilities,
+		"tools":        tools,
+		"errors":       t.errors,
+	}
+}
+
+// Unavailable returns the sorted capabilities whose tools the last probe
+// left unregistered.
+func (t *CapabilityTools) Unavailable() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	unavailable := []string{}
+	for capability := range t.tools {
+		if t.capabilities != nil && !t.capabilities[capability] {
+			unavailable = append(unavailable, capability)
+		}
+	}
+	sort.Strings(unavailable)
+	return unavailable
+}
+
+// toolNames returns the sorted names of tools.
+func toolNames(tools []server.ServerTool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RefreshCapabilities returns a handler function for the refreshCapabilities tool.
+// It probes the cluster's optional APIs again and registers or removes the
+// tools that depend on them. The result is serialized to JSON and returned.
+func RefreshCapabilities(capabilityTools *CapabilityTools) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := capabilityTools.Refresh(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to probe cluster capabilities: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
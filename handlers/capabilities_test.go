@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+)
+
+// TestCapabilityTools tests registering only the tools of the optional APIs the cluster serves
+func TestCapabilityTools(t *testing.T) {
+	var metricsServed atomic.Bool
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","apiVersion":"v1","groups":[` +
+				`{"name":"metrics.k8s.io","versions":[{"groupVersion":"metrics.k8s.io/v1beta1","version":"v1beta1"}],"preferredVersion":{"groupVersion":"metrics.k8s.io/v1beta1","version":"v1beta1"}},` +
+				`{"name":"keda.sh","versions":[{"groupVersion":"keda.sh/v1alpha1","version":"v1alpha1"}],"preferredVersion":{"groupVersion":"keda.sh/v1alpha1","version":"v1alpha1"}}]}`))
+		case "/apis/metrics.k8s.io/v1beta1":
+			if !metricsServed.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"ServiceUnavailable","code":503}`))
+				return
+			}
+			w.Write([]byte(`{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"metrics.k8s.io/v1beta1","resources":[{"name":"pods","namespaced":true,"kind":"PodMetrics","verbs":["get","list"]}]}`))
+		case "/apis/keda.sh/v1alpha1":
+			w.Write([]byte(`{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"keda.sh/v1alpha1","resources":[{"name":"scaledobjects","namespaced":true,"kind":"ScaledObject","verbs":["get","list"]}]}`))
+		case "/api/v1/secrets":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer apiServer.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	config := `apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster: {server: "` + apiServer.URL + `"}
+users:
+- name: admin
+  user: {token: secret}
+contexts:
+- name: test
+  context: {cluster: test, user: admin}
+`
+	if err := os.WriteFile(kubeconfig, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := k8s.NewClient(kubeconfig)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	capabilityTools := NewCapabilityTools(s, client)
+	capabilityTools.Add("metrics", mcp.NewTool("getPodMetrics"), handler)
+	capabilityTools.Add("keda", mcp.NewTool("getKedaScalers"), handler)
+	capabilityTools.Add("velero", mcp.NewTool("getVeleroBackups"), handler)
+	capabilityTools.Add("helm", mcp.NewTool("helmList"), handler)
+
+	registered := func() map[string]bool {
+		names := map[string]bool{}
+		for name := range s.ListTools() {
+			names[name] = true
+		}
+		return names
+	}
+
+	if _, err := capabilityTools.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if tools := registered(); len(tools) != 1 || !tools["getKedaScalers"] {
+		t.Errorf("Expected only the KEDA tool to be registered, got %v", tools)
+	}
+	if unavailable := capabilityTools.Unavailable(); len(unavailable) != 3 || unavailable[0] != "helm" {
+		t.Errorf("Expected helm, metrics and velero to be unavailable, got %v", unavailable)
+	}
+
+	metricsServed.Store(true)
+	result, err := capabilityTools.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added := result["added"].([]string); len(added) != 1 || added[0] != "getPodMetrics" {
+		t.Errorf("Expected the metrics tool to be added, got %v", added)
+	}
+	if tools := registered(); len(tools) != 2 || !tools["getPodMetrics"] {
+		t.Errorf("Expected the metrics and KEDA tools to be registered, got %v", tools)
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetCapiInventory returns a handler function for the getCapiInventory tool.
+// It summarizes Cluster API objects for the provided namespace and optional
+// cluster name. The result is serialized to JSON and returned.
+func GetCapiInventory(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		clusterName := getStringArg(args, "clusterName", "")
+
+		inventory, err := client.GetCapiInventory(ctx, namespace, clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Cluster API inventory: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(inventory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
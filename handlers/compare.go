@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CompareNamespaces returns a handler function for the compareNamespaces tool.
+// It compares the workloads and configuration of two namespaces by name.
+// The result is serialized to JSON and returned.
+func CompareNamespaces(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		firstNamespace, err := getRequiredStringArg(args, "firstNamespace")
+		if err != nil {
+			return nil, err
+		}
+		secondNamespace, err := getRequiredStringArg(args, "secondNamespace")
+		if err != nil {
+			return nil, err
+		}
+		includeConfig := getBoolArg(args, "includeConfig", true)
+
+		report, err := client.CompareNamespaces(ctx, firstNamespace, secondNamespace, includeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare namespaces: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetConditions returns a handler function for the getConditions tool.
+// It collects the normalized status conditions of the objects of one or more
+// kinds. The result is serialized to JSON and returned.
+func GetConditions(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		kindsStr, err := getRequiredStringArg(args, "kinds")
+		if err != nil {
+			return nil, err
+		}
+		kinds := parseFieldPaths(kindsStr)
+		namespace := getStringArg(args, "namespace", "")
+		name := getStringArg(args, "name", "")
+		labelSelector := getStringArg(args, "labelSelector", "")
+		status := getStringArg(args, "status", "")
+		abnormalOnly := getBoolArg(args, "abnormalOnly", false)
+		if status != "" && status != "True" && status != "False" && status != "Unknown" {
+			return nil, fmt.Errorf("invalid argument status: must be True, False or Unknown")
+		}
+
+		conditions, err := client.GetConditions(ctx, kinds, namespace, name, labelSelector, status, abnormalOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get conditions: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(conditions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
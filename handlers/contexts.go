@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ClientHandler builds the handler of a Kubernetes tool for one client, like
+// the handler constructors of this package.
+type ClientHandler func(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// contextParams are the parameters WithContext adds to a Kubernetes tool.
+var contextParams = map[string]map[string]any{
+	"context": {
+		"type":        "string",
+		"description": "Kubeconfig context of the cluster to query (default: the session's pinned context, else the server's context). See listContexts",
+	},
+	"impersonateUser": {
+		"type":        "string",
+		"description": "Make the call as this Kubernetes user, so it is constrained to the user's RBAC (default: the session's pinned user, else the server's credentials)",
+	},
+	"impersonateGroups": {
+		"type":        "array",
+		"items":       map[string]any{"type": "string"},
+		"description": "Groups to impersonate along with impersonateUser or impersonateServiceAccount",
+	},
+	"impersonateServiceAccount": {
+		"type":        "string",
+		"description": "Make the call as this service account, given as namespace/name; cannot be combined with impersonateUser",
+	},
+}
+
+// WithContext adds optional context and impersonation parameters to a
+// Kubernetes tool and returns a handler that serves each call with the client
+// of the kubeconfig context it names, or the default client when it names
+// none. Calls naming a user or service account to impersonate are served by a
+// copy of that client sending impersonation headers. The handler of each
+// client is built on first use and kept. The context served and the user
+// impersonated are reported in the result metadata, and requests the API
+// server forbids are diagnosed with that client.
+func WithContext(pool *k8s.ClientPool, tool mcp.Tool, newHandler ClientHandler) (mcp.Tool, server.ToolHandlerFunc) {
+	properties := make(map[string]any, len(tool.InputSchema.Properties)+len(contextParams))
+	for name, property := range tool.InputSchema.Properties {
+		properties[name] = property
+	}
+	for name, property := range contextParams {
+		if _, ok := properties[name]; !ok {
+			properties[name] = property
+		}
+	}
+	tool.InputSchema.Properties = properties
+
+	var mu sync.Mutex
+	handlers := map[*k8s.Client]server.ToolHandlerFunc{}
+	return tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, _ := request.Params.Arguments.(map[string]interface{})
+		client, err := pool.Get(getStringArg(args, "context", ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument context: %w", err)
+		}
+		if client, err = impersonatingClient(pool, client, args); err != nil {
+			return nil, err
+		}
+
+		mu.Lock()
+		handler, ok := handlers[client]
+		if !ok {
+			handler = newHandler(client)
+			handlers[client] = handler
+		}
+		mu.Unlock()
+
+		setResultMetadata(ctx, "context", client.ContextName())
+		setResultMetadata(ctx, "server", client.ServerHost())
+		if user := client.Impersonating(); user != "" {
+			setResultMetadata(ctx, "impersonating", user)
+		}
+		result, err := handler(ctx, request)
+		if forbidden, ok := k8s.ParseForbidden(err); ok {
+			err = &diagnosedError{err: err, diagnosis: client.DiagnoseForbidden(ctx, forbidden)}
+		}
+		return result, err
+	}
+}
+
+// impersonatingClient returns the client of the pool impersonating the user
+// or service account and groups named by a call's arguments, or client itself
+// when they name none.
+func impersonatingClient(pool *k8s.ClientPool, client *k8s.Client, args map[string]interface{}) (*k8s.Client, error) {
+	user := getStringArg(args, "impersonateUser", "")
+	if serviceAccount := getStringArg(args, "impersonateServiceAccount", ""); serviceAccount != "" {
+		if user != "" {
+			return nil, fmt.Errorf("invalid argument impersonateServiceAccount: cannot be combined with impersonateUser")
+		}
+		var err error
+		if user, err = k8s.ServiceAccountUser(serviceAccount); err != nil {
+			return nil, fmt.Errorf("invalid argument impersonateServiceAccount: %w", err)
+		}
+	}
+	groups, err := getStringListArg(args, "impersonateGroups")
+	if err != nil {
+		return nil, err
+	}
+	if user == "" {
+		if len(groups) > 0 {
+			return nil, fmt.Errorf("invalid argument impersonateGroups: requires impersonateUser or impersonateServiceAccount")
+		}
+		return client, nil
+	}
+
+	impersonating, err := pool.Impersonate(client, user, groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to impersonate %s: %w", user, err)
+	}
+	return impersonating, nil
+}
+
+// ListContexts returns a handler function for the listContexts tool.
+// It lists the kubeconfig contexts the server can connect to, marking the
+// default one and those already connected. The result is serialized to JSON
+// and returned.
+func ListContexts(pool *k8s.ClientPool) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		contexts, err := pool.ListContexts()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list contexts: %w", err)
+		}
+		if items, ok := contexts["contexts"].([]map[string]interface{}); ok {
+			setResultMetadata(ctx, "itemCount", len(items))
+		}
+
+		jsonResponse, err := json.Marshal(contexts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
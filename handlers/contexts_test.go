@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+)
+
+// TestWithContext tests serving tool calls with the client of the named context
+func TestWithContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	config := `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster: {server: "https://prod.example.com"}
+- name: staging
+  cluster: {server: "https://staging.example.com"}
+users:
+- name: admin
+  user: {token: secret}
+contexts:
+- name: prod
+  context: {cluster: prod, user: admin}
+- name: staging
+  context: {cluster: staging, user: admin}
+`
+	if err := os.WriteFile(kubeconfig, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	defaultClient, err := k8s.NewClient(kubeconfig)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	pool := k8s.NewClientPool(kubeconfig, defaultClient)
+
+	built := 0
+	tool, handler := WithContext(pool, mcp.NewTool("echoServer"), func(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		built++
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(client.ServerHost()), nil
+		}
+	})
+	if _, ok := tool.InputSchema.Properties["context"]; !ok {
+		t.Fatal("Expected a context parameter")
+	}
+
+	call := func(args map[string]interface{}) (string, error) {
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "echoServer", Arguments: args}})
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+	if server, err := call(map[string]interface{}{}); err != nil || server != "https://prod.example.com" {
+		t.Errorf("Expected the default context, got %q, %v", server, err)
+	}
+	for i := 0; i < 2; i++ {
+		if server, err := call(map[string]interface{}{"context": "staging"}); err != nil || server != "https://staging.example.com" {
+			t.Errorf("Expected the staging context, got %q, %v", server, err)
+		}
+	}
+	if built != 2 {
+		t.Errorf("Expected one handler per context, built %d", built)
+	}
+	if _, err := call(map[string]interface{}{"context": "dev"}); err == nil {
+		t.Error("Expected an error for an unknown context")
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"impersonateUser": "jane"},
+		{"impersonateUser": "jane"},
+		{"impersonateServiceAccount": "web/deployer", "impersonateGroups": []interface{}{"devs"}},
+	} {
+		if server, err := call(args); err != nil || server != "https://prod.example.com" {
+			t.Errorf("Expected an impersonating client of the default context for %v, got %q, %v", args, server, err)
+		}
+	}
+	if built != 4 {
+		t.Errorf("Expected one handler per impersonated identity, built %d", built)
+	}
+	for _, args := range []map[string]interface{}{
+		{"impersonateUser": "jane", "impersonateServiceAccount": "web/deployer"},
+		{"impersonateServiceAccount": "deployer"},
+		{"impersonateGroups": []interface{}{"devs"}},
+	} {
+		if _, err := call(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/export"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CollectDiagnostics returns a handler function for the collectDiagnostics tool.
+// It gathers a support bundle for a namespace or workload and writes it as a
+// tar.gz to the requested export target. Without configured targets the bundle
+// is written to a directory under the system temporary directory. A reference
+// to the bundle and a summary of its contents are serialized to JSON and
+// returned.
+func CollectDiagnostics(client *k8s.Client, exporters map[string]export.Exporter) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+		kind := getStringArg(args, "kind", "")
+		name := getStringArg(args, "name", "")
+		if (kind == "") != (name == "") {
+			return nil, fmt.Errorf("invalid arguments: kind and name must be set together")
+		}
+		tailLines := getIntArg(args, "tailLines", 500)
+
+		exporter, err := diagnosticsExporter(exporters, getStringArg(args, "destination", ""))
+		if err != nil {
+			return nil, err
+		}
+
+		bundle, summary, err := client.CollectDiagnostics(ctx, namespace, kind, name, int64(tailLines))
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect diagnostics: %w", err)
+		}
+
+		parts := []string{"diagnostics", namespace}
+		if kind != "" {
+			parts = append(parts, strings.ToLower(kind), name)
+		}
+		parts = append(parts, time.Now().UTC().Format("20060102T150405Z"))
+		ref, err := exporter.Export(ctx, export.Object{
+			Name:        exportName("diagnostics", strings.Join(parts, "-")) + ".tar.gz",
+			ContentType: "application/gzip",
+			Data:        bundle,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write diagnostic bundle: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(map[string]interface{}{
+			"bundle":  ref,
+			"summary": summary,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// diagnosticsExporter returns the exporter a bundle is written to: the named
+// destination, else the local directory or the bucket, whichever is configured,
+// else a directory under the system temporary directory.
+func diagnosticsExporter(exporters map[string]export.Exporter, destination string) (export.Exporter, error) {
+	if destination != "" {
+		if exporter := exporters[destination]; exporter != nil {
+			return exporter, nil
+		}
+		return nil, fmt.Errorf("invalid argument destination: export target %q is not configured on this server", destination)
+	}
+	for _, name := range []string{"file", "s3"} {
+		if exporter := exporters[name]; exporter != nil {
+			return exporter, nil
+		}
+	}
+	return export.NewFileExporter(filepath.Join(os.TempDir(), "k8s-mcp-diagnostics"))
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// AnalyzeEphemeralStorage returns a handler function for the analyzeEphemeralStorage tool.
+// It correlates ephemeral-storage evictions with kubelet filesystem usage.
+// The result is serialized to JSON and returned.
+func AnalyzeEphemeralStorage(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		nodeName := getStringArg(args, "nodeName", "")
+		sampleSeconds := getIntArg(args, "sampleSeconds", 0)
+		topN := getIntArg(args, "topN", 10)
+
+		report, err := client.AnalyzeEphemeralStorage(ctx, namespace, nodeName, sampleSeconds, topN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze ephemeral storage: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
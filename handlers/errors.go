@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Error categories reported in the structured error envelope.
+const (
+	ErrorCategoryNotFound    = "notFound"
+	ErrorCategoryForbidden   = "forbidden"
+	ErrorCategoryInvalidArgs = "invalidArgs"
+	ErrorCategoryTimeout     = "timeout"
+	ErrorCategoryConflict    = "conflict"
+	ErrorCategoryInternal    = "internal"
+)
+
+// errorHints maps each category to a remediation hint for the caller.
+var errorHints = map[string]string{
+	ErrorCategoryNotFound:    "Check the kind, name and namespace; use getAPIResources or listResources to discover what exists.",
+	ErrorCategoryForbidden:   "The server's credentials lack permission for this operation; check the RBAC bindings of the kubeconfig user or service account.",
+	ErrorCategoryInvalidArgs: "Check the tool parameters against the tool's input schema and retry with corrected values.",
+	ErrorCategoryTimeout:     "Narrow the request with namespace, labelSelector or fieldPaths, or retry later.",
+	ErrorCategoryConflict:    "The resource already exists or was modified concurrently; re-read it and retry.",
+	ErrorCategoryInternal:    "Retry the request; if it keeps failing, check the server logs.",
+}
+
+// ToolError is the structured error returned to clients when a tool fails.
+type ToolError struct {
+	Category string `json:"category"`
+	Reason   string `json:"reason,omitempty"`
+	Code     int32  `json:"code,omitempty"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint"`
+	// Diagnosis explains a forbidden request: the permission that is missing
+	// and the role that would need amending.
+	Diagnosis map[string]interface{} `json:"diagnosis,omitempty"`
+}
+
+// diagnosedError is an error annotated with a diagnosis of its cause.
+type diagnosedError struct {
+	err       error
+	diagnosis map[string]interface{}
+}
+
+func (e *diagnosedError) Error() string { return e.err.Error() }
+
+func (e *diagnosedError) Unwrap() error { return e.err }
+
+// NewToolError classifies err into a category, extracting the Kubernetes API
+// status reason and code when the error originates from the API server.
+func NewToolError(err error) ToolError {
+	toolErr := ToolError{Message: err.Error()}
+
+	var statusErr apierrors.APIStatus
+	if errors.As(err, &statusErr) {
+		toolErr.Reason = string(statusErr.Status().Reason)
+		toolErr.Code = statusErr.Status().Code
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		toolErr.Category = ErrorCategoryNotFound
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		toolErr.Category = ErrorCategoryForbidden
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err), apierrors.IsMethodNotSupported(err):
+		toolErr.Category = ErrorCategoryInvalidArgs
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), apierrors.IsTooManyRequests(err),
+		errors.Is(err, context.DeadlineExceeded):
+		toolErr.Category = ErrorCategoryTimeout
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		toolErr.Category = ErrorCategoryConflict
+	default:
+		toolErr.Category = categorizeMessage(err.Error())
+	}
+
+	toolErr.Hint = errorHints[toolErr.Category]
+	var diagnosed *diagnosedError
+	if errors.As(err, &diagnosed) {
+		toolErr.Diagnosis = diagnosed.diagnosis
+	}
+	return toolErr
+}
+
+// categorizeMessage classifies errors that do not carry an API status, such as
+// argument validation failures and errors from the Helm SDK, by their text.
+// Refusals are recognized first, as their messages often explain what would
+// be required or is invalid.
+func categorizeMessage(message string) string {
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "forbidden"), strings.Contains(msg, "unauthorized"), strings.Contains(msg, "change freeze"),
+		strings.Contains(msg, "exec policy"), strings.Contains(msg, "read-only mode"),
+		strings.Contains(msg, "namespace policy"):
+		return ErrorCategoryForbidden
+	case strings.Contains(msg, "invalid argument"), strings.Contains(msg, "required"),
+		strings.Contains(msg, "ambiguous"), strings.Contains(msg, "invalid"), strings.Contains(msg, "must be"):
+		return ErrorCategoryInvalidArgs
+	case strings.Contains(msg, "not found"):
+		return ErrorCategoryNotFound
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "timed out"), strings.Contains(msg, "deadline exceeded"):
+		return ErrorCategoryTimeout
+	case strings.Contains(msg, "already exists"), strings.Contains(msg, "conflict"):
+		return ErrorCategoryConflict
+	}
+	return ErrorCategoryInternal
+}
+
+// ErrorEnvelopeMiddleware converts errors returned by tool handlers into a tool
+// result flagged as an error whose text is a JSON object of the form
+// {"error": ToolError}, so clients can branch on the category instead of
+// parsing raw error strings.
+func ErrorEnvelopeMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err == nil {
+			return result, nil
+		}
+
+		envelope := map[string]interface{}{"error": NewToolError(err)}
+		jsonResponse, marshalErr := json.Marshal(envelope)
+		if marshalErr != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultError(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestErrorEnvelope tests error classification and the error envelope middleware
+func TestErrorEnvelope(t *testing.T) {
+	podsResource := schema.GroupResource{Resource: "pods"}
+
+	tests := []struct {
+		name     string
+		err      error
+		category string
+		reason   string
+	}{
+		{"API not found", fmt.Errorf("failed to get resource: %w", apierrors.NewNotFound(podsResource, "web")), ErrorCategoryNotFound, "NotFound"},
+		{"API forbidden", apierrors.NewForbidden(podsResource, "web", fmt.Errorf("denied")), ErrorCategoryForbidden, "Forbidden"},
+		{"API conflict", apierrors.NewAlreadyExists(podsResource, "web"), ErrorCategoryConflict, "AlreadyExists"},
+		{"API timeout", apierrors.NewTimeoutError("slow", 1), ErrorCategoryTimeout, "Timeout"},
+		{"Argument error", fmt.Errorf("missing required parameter: name"), ErrorCategoryInvalidArgs, ""},
+		{"Unknown kind", fmt.Errorf("resource type Widget not found"), ErrorCategoryNotFound, ""},
+		{"Exec policy", fmt.Errorf(`refused by exec policy: command "sh" is not in the exec allowlist`), ErrorCategoryForbidden, ""},
+		{"Read-only mode", fmt.Errorf("failed to create resource: refused by read-only mode: POST /api/v1/namespaces/web/pods would change the cluster"), ErrorCategoryForbidden, ""},
+		{"Unauthorized", fmt.Errorf("unauthorized: a valid bearer token is required"), ErrorCategoryForbidden, ""},
+		{"Client forbidden", fmt.Errorf("forbidden: client team-a is limited to specific namespaces and must set the namespace for tool setImage"), ErrorCategoryForbidden, ""},
+		{"Forbidden invalid impersonation", fmt.Errorf("forbidden: client team-a may not impersonate an invalid user"), ErrorCategoryForbidden, ""},
+		{"Namespace policy", fmt.Errorf("refused by namespace policy: tool helmList must set the namespace"), ErrorCategoryForbidden, ""},
+		{"Other error", fmt.Errorf("connection refused"), ErrorCategoryInternal, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolErr := NewToolError(tt.err)
+			if toolErr.Category != tt.category {
+				t.Errorf("Expected category %s, got %s", tt.category, toolErr.Category)
+			}
+			if toolErr.Reason != tt.reason {
+				t.Errorf("Expected reason %q, got %q", tt.reason, toolErr.Reason)
+			}
+			if toolErr.Hint == "" {
+				t.Error("Expected a remediation hint")
+			}
+		})
+	}
+
+	t.Run("Diagnosis is kept", func(t *testing.T) {
+		err := &diagnosedError{err: apierrors.NewForbidden(podsResource, "web", fmt.Errorf("denied")), diagnosis: map[string]interface{}{"suggestion": "bind a role"}}
+		toolErr := NewToolError(fmt.Errorf("failed to get resource: %w", err))
+		if toolErr.Category != ErrorCategoryForbidden || toolErr.Diagnosis["suggestion"] != "bind a role" {
+			t.Errorf("Expected a forbidden error with its diagnosis, got %+v", toolErr)
+		}
+	})
+
+	t.Run("Middleware wraps handler errors", func(t *testing.T) {
+		handler := ErrorEnvelopeMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, apierrors.NewNotFound(podsResource, "web")
+		})
+		result, err := handler(context.Background(), mcp.CallToolRequest{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !result.IsError {
+			t.Error("Expected result to be flagged as error")
+		}
+
+		var envelope map[string]ToolError
+		textContent := result.Content[0].(mcp.TextContent)
+		if err := json.Unmarshal([]byte(textContent.Text), &envelope); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if envelope["error"].Category != ErrorCategoryNotFound {
+			t.Errorf("Expected notFound category, got %s", envelope["error"].Category)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxExecTimeoutSeconds bounds how long an execInPod command may run.
+const maxExecTimeoutSeconds = 300
+
+// ExecInPod returns a handler function for the execInPod tool.
+// It runs a command in a pod container once the exec policy allows it, and
+// returns its output and exit code. The result is serialized to JSON and returned.
+func ExecInPod(client *k8s.Client, execPolicy *policy.ExecPolicy) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+		podName, err := getRequiredStringArg(args, "podName")
+		if err != nil {
+			return nil, err
+		}
+		rawCommand, _ := args["command"].([]interface{})
+		if len(rawCommand) == 0 {
+			return nil, fmt.Errorf("missing required parameter: command")
+		}
+		command := make([]string, 0, len(rawCommand))
+		for _, part := range rawCommand {
+			arg, ok := part.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid argument command: every element must be a string")
+			}
+			command = append(command, arg)
+		}
+		timeoutSeconds := getIntArg(args, "timeoutSeconds", 30)
+		if timeoutSeconds <= 0 || timeoutSeconds > maxExecTimeoutSeconds {
+			return nil, fmt.Errorf("invalid argument timeoutSeconds: must be between 1 and %d", maxExecTimeoutSeconds)
+		}
+
+		if err := execPolicy.Check(command); err != nil {
+			return nil, fmt.Errorf("refused by exec policy: %w", err)
+		}
+
+		result, err := client.ExecInPod(ctx, namespace, podName, getStringArg(args, "container", ""), command, time.Duration(timeoutSeconds)*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("failed to exec in pod '%s': %w", podName, err)
+		}
+		setResultMetadata(ctx, "truncated", result["truncated"])
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/export"
+)
+
+// exportPreviewBytes bounds the preview of an exported result that is still
+// returned inline.
+const exportPreviewBytes = 512
+
+// unsafeExportName matches the characters replaced in export names.
+var unsafeExportName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ExportMiddleware returns a middleware that writes the results of tools
+// accepting an exportTo argument, as reported by toolHasParam, to one of the
+// configured exporters and returns a reference in place of the output. A
+// result is exported when the call names a target in exportTo, or when its
+// output exceeds threshold bytes and exactly one exporter is configured. A
+// threshold of zero disables automatic export.
+func ExportMiddleware(exporters map[string]export.Exporter, threshold int, toolHasParam func(tool, param string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tool := request.Params.Name
+			if !toolHasParam(tool, "exportTo") {
+				return next(ctx, request)
+			}
+			args, _ := request.Params.Arguments.(map[string]interface{})
+			target := getStringArg(args, "exportTo", "")
+			if target != "" && exporters[target] == nil {
+				return nil, fmt.Errorf("invalid argument exportTo: export target %q is not configured on this server", target)
+			}
+
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			output := resultText(result)
+			if target == "" {
+				if threshold <= 0 || len(output) <= threshold || len(exporters) != 1 {
+					return result, nil
+				}
+				for name := range exporters {
+					target = name
+				}
+			}
+
+			object := export.Object{
+				Name:        exportName(tool, getStringArg(args, "exportName", "")),
+				ContentType: "text/plain",
+				Data:        []byte(output),
+			}
+			switch {
+			case json.Valid(object.Data):
+				object.ContentType = "application/json"
+				object.Name += ".json"
+			case getStringArg(args, "outputFormat", "") == "yaml":
+				object.ContentType = "application/yaml"
+				object.Name += ".yaml"
+			default:
+				object.Name += ".txt"
+			}
+			ref, err := exporters[target].Export(ctx, object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export result: %w", err)
+			}
+			setResultMetadata(ctx, "exported", true)
+
+			jsonResponse, err := json.Marshal(map[string]interface{}{
+				"exported": ref,
+				"preview":  preview(output, exportPreviewBytes),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize response: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonResponse)), nil
+		}
+	}
+}
+
+// resultText joins the text contents of a result.
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// exportExtensions are the extensions exports are written with, trimmed from
+// requested names so the one matching the export's format is not doubled.
+var exportExtensions = []string{".json", ".yaml", ".yml", ".txt"}
+
+// exportName returns a file name for an export without extension, based on
+// the requested name or, by default, the tool and the current time.
+func exportName(tool, requested string) string {
+	for _, extension := range exportExtensions {
+		if trimmed, ok := strings.CutSuffix(requested, extension); ok {
+			requested = trimmed
+			break
+		}
+	}
+	if name := strings.Trim(unsafeExportName.ReplaceAllString(requested, "-"), ".-"); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s", tool, time.Now().UTC().Format("20060102T150405.000Z"))
+}
+
+// preview returns at most limit bytes of text, cut at a rune boundary.
+func preview(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit] + "..."
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/export"
+)
+
+// TestExportMiddleware tests replacing tool outputs with a reference to the exported file
+func TestExportMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	fileExporter, err := export.NewFileExporter(dir)
+	if err != nil {
+		t.Fatalf("Failed to create exporter: %v", err)
+	}
+	exporters := map[string]export.Exporter{"file": fileExporter}
+	toolHasParam := func(tool, param string) bool { return tool == "listResources" }
+	output := `[{"name":"` + strings.Repeat("a", 100) + `"}]`
+	handler := ExportMiddleware(exporters, 64, toolHasParam)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(output), nil
+	})
+
+	call := func(tool string, args map[string]interface{}) (string, error) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			return "", err
+		}
+		return resultText(result), nil
+	}
+
+	text, err := call("listResources", map[string]interface{}{"exportName": "pods"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var response struct {
+		Exported export.Reference `json:"exported"`
+	}
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		t.Fatalf("Expected a reference, got %s", text)
+	}
+	if response.Exported.ContentType != "application/json" || !strings.HasSuffix(response.Exported.Location, "/pods.json") {
+		t.Errorf("Unexpected reference: %+v", response.Exported)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "pods.json")); string(data) != output {
+		t.Errorf("Unexpected exported content %q", data)
+	}
+
+	if text, _ := call("getResource", nil); text != output {
+		t.Errorf("Expected tools without exportTo to be left alone, got %s", text)
+	}
+	if _, err := call("listResources", map[string]interface{}{"exportTo": "s3"}); err == nil {
+		t.Error("Expected an error for an unconfigured export target")
+	}
+}
+
+// TestExportName tests trimming the extension of every export format from requested names
+func TestExportName(t *testing.T) {
+	tests := map[string]string{
+		"pods":         "pods",
+		"pods.json":    "pods",
+		"pods.yaml":    "pods",
+		"pods.yml":     "pods",
+		"pods.txt":     "pods",
+		"pods.v1.yaml": "pods.v1",
+		"../pods.yaml": "pods",
+		"team a/pods":  "team-a-pods",
+	}
+	for requested, want := range tests {
+		if got := exportName("listResources", requested); got != want {
+			t.Errorf("exportName(%q) = %q, want %q", requested, got, want)
+		}
+	}
+	if got := exportName("listResources", ".yaml"); !strings.HasPrefix(got, "listResources-") {
+		t.Errorf("Expected a name without anything but an extension to default to the tool, got %q", got)
+	}
+}
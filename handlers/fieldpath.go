@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fieldPathSegment is one step of a field path: a map key, a list index,
+// or a wildcard over all map values or list items.
+type fieldPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseFieldPath parses a dot-separated field path whose segments may be
+// followed by list indexes, e.g. spec.containers[*].image or
+// status.conditions[0].type. A segment of * matches every value of a map or
+// item of a list, and [*] every item of a list. Negative indexes count from
+// the end of the list, so [-1] is the last item. Keys containing dots or
+// brackets are quoted, as in
+// metadata.annotations."kubectl.kubernetes.io/last-applied-configuration".
+func parseFieldPath(path string) ([]fieldPathSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("field path is empty")
+	}
+	var segments []fieldPathSegment
+	for i := 0; i < len(path); {
+		// A key, quoted or not, followed by any number of indexes
+		name, quoted := "", false
+		if path[i] == '"' {
+			end := strings.IndexByte(path[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("field path %q has an unterminated quote", path)
+			}
+			name, quoted = path[i+1:i+1+end], true
+			i += end + 2
+		} else {
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			name = path[i : i+end]
+			i += end
+		}
+		switch {
+		case quoted:
+			segments = append(segments, fieldPathSegment{key: name})
+		case name == "*":
+			segments = append(segments, fieldPathSegment{wildcard: true})
+		case name != "":
+			segments = append(segments, fieldPathSegment{key: name})
+		case i >= len(path) || path[i] != '[':
+			return nil, fmt.Errorf("field path %q has an empty segment", path)
+		}
+
+		for i < len(path) && path[i] == '[' {
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("field path %q has an unterminated index", path)
+			}
+			inner := path[i+1 : i+end]
+			i += end + 1
+			if inner == "*" {
+				segments = append(segments, fieldPathSegment{isIndex: true, wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("field path %q has an invalid index %q", path, inner)
+			}
+			segments = append(segments, fieldPathSegment{isIndex: true, index: index})
+		}
+
+		if i < len(path) {
+			if path[i] != '.' {
+				return nil, fmt.Errorf("field path %q has an unexpected %q after a quoted key", path, path[i])
+			}
+			i++
+			if i == len(path) {
+				return nil, fmt.Errorf("field path %q has an empty segment", path)
+			}
+		}
+	}
+	return segments, nil
+}
+
+// validateFieldPaths checks that each path of the argument key parses.
+func validateFieldPaths(key string, paths []string) error {
+	for _, path := range paths {
+		if _, err := parseFieldPath(path); err != nil {
+			return fmt.Errorf("invalid argument %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// hasWildcard reports whether a parsed field path can match several values.
+func hasWildcard(segments []fieldPathSegment) bool {
+	for _, segment := range segments {
+		if segment.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// walkFieldPath calls visit with each value value holds at the path
+// segments, in order.
+func walkFieldPath(value interface{}, segments []fieldPathSegment, visit func(interface{})) {
+	if len(segments) == 0 {
+		visit(value)
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch {
+		case segment.isIndex:
+		case segment.wildcard:
+			for _, key := range sortedKeys(v) {
+				walkFieldPath(v[key], rest, visit)
+			}
+		default:
+			if child, ok := v[segment.key]; ok {
+				walkFieldPath(child, rest, visit)
+			}
+		}
+	case []interface{}:
+		switch {
+		case segment.wildcard:
+			for _, item := range v {
+				walkFieldPath(item, rest, visit)
+			}
+		case segment.isIndex:
+			if i, ok := listIndex(segment.index, len(v)); ok {
+				walkFieldPath(v[i], rest, visit)
+			}
+		}
+	}
+}
+
+// listIndex resolves a possibly negative index into a list of length n.
+func listIndex(index, n int) (int, bool) {
+	if index < 0 {
+		index += n
+	}
+	return index, index >= 0 && index < n
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// projectedMap and projectedList are the parts of an object a projection
+// built, as opposed to values copied from the object, so that the
+// projections of several paths can be merged without modifying the object.
+// A projectedList keeps the selected items by their index in the list.
+type projectedMap map[string]interface{}
+
+type projectedList struct {
+	items map[int]interface{}
+}
+
+// projectFieldPath returns the parts of value selected by the path
+// segments, keeping their position in the object, and whether any part
+// matched.
+func projectFieldPath(value interface{}, segments []fieldPathSegment) (interface{}, bool) {
+	if len(segments) == 0 {
+		return value, true
+	}
+	segment, rest := segments[0], segments[1:]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch {
+		case segment.isIndex:
+		case segment.wildcard:
+			projected := projectedMap{}
+			for key, child := range v {
+				if p, ok := projectFieldPath(child, rest); ok {
+					projected[key] = p
+				}
+			}
+			return projected, len(projected) > 0
+		default:
+			if child, ok := v[segment.key]; ok {
+				if p, ok := projectFieldPath(child, rest); ok {
+					return projectedMap{segment.key: p}, true
+				}
+			}
+		}
+	case []interface{}:
+		projected := projectedList{items: map[int]interface{}{}}
+		switch {
+		case segment.wildcard:
+			for i, item := range v {
+				if p, ok := projectFieldPath(item, rest); ok {
+					projected.items[i] = p
+				}
+			}
+		case segment.isIndex:
+			if i, ok := listIndex(segment.index, len(v)); ok {
+				if p, ok := projectFieldPath(v[i], rest); ok {
+					projected.items[i] = p
+				}
+			}
+		}
+		return projected, len(projected.items) > 0
+	}
+	return nil, false
+}
+
+// mergeProjections merges the projection src into dst. A value copied
+// whole from the object holds everything a projection of its parts could,
+// so it replaces them.
+func mergeProjections(dst, src interface{}) interface{} {
+	switch d := dst.(type) {
+	case projectedMap:
+		s, ok := src.(projectedMap)
+		if !ok {
+			return src
+		}
+		for key, value := range s {
+			if existing, ok := d[key]; ok {
+				d[key] = mergeProjections(existing, value)
+			} else {
+				d[key] = value
+			}
+		}
+		return d
+	case projectedList:
+		s, ok := src.(projectedList)
+		if !ok {
+			return src
+		}
+		for i, value := range s.items {
+			if existing, ok := d.items[i]; ok {
+				d.items[i] = mergeProjections(existing, value)
+			} else {
+				d.items[i] = value
+			}
+		}
+		return d
+	}
+	return dst
+}
+
+// finishProjection turns a merged projection into plain maps and lists,
+// keeping the selected items of each list in their original order.
+func finishProjection(value interface{}) interface{} {
+	switch v := value.(type) {
+	case projectedMap:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			result[key] = finishProjection(child)
+		}
+		return result
+	case projectedList:
+		indexes := make([]int, 0, len(v.items))
+		for i := range v.items {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+		result := make([]interface{}, 0, len(indexes))
+		for _, i := range indexes {
+			result = append(result, finishProjection(v.items[i]))
+		}
+		return result
+	}
+	return value
+}
+
+// removeFieldPath returns value without the fields addressed by the path
+// segments, copying each map and list along the path, and whether anything
+// was removed. Paths that match nothing leave value unchanged.
+func removeFieldPath(value interface{}, segments []fieldPathSegment) (interface{}, bool) {
+	segment, rest := segments[0], segments[1:]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if segment.isIndex {
+			return value, false
+		}
+		keys := []string{segment.key}
+		if segment.wildcard {
+			keys = sortedKeys(v)
+		}
+		var result map[string]interface{}
+		for _, key := range keys {
+			child, ok := v[key]
+			if !ok {
+				continue
+			}
+			var replacement interface{}
+			if len(rest) > 0 {
+				if replacement, ok = removeFieldPath(child, rest); !ok {
+					continue
+				}
+			}
+			if result == nil {
+				result = make(map[string]interface{}, len(v))
+				for k, item := range v {
+					result[k] = item
+				}
+			}
+			if len(rest) == 0 {
+				delete(result, key)
+			} else {
+				result[key] = replacement
+			}
+		}
+		if result == nil {
+			return value, false
+		}
+		return result, true
+	case []interface{}:
+		var indexes []int
+		switch {
+		case segment.wildcard:
+			for i := range v {
+				indexes = append(indexes, i)
+			}
+		case segment.isIndex:
+			if i, ok := listIndex(segment.index, len(v)); ok {
+				indexes = append(indexes, i)
+			}
+		}
+		removed := map[int]bool{}
+		var result []interface{}
+		for _, i := range indexes {
+			if len(rest) == 0 {
+				removed[i] = true
+				continue
+			}
+			replacement, ok := removeFieldPath(v[i], rest)
+			if !ok {
+				continue
+			}
+			if result == nil {
+				result = append([]interface{}{}, v...)
+			}
+			result[i] = replacement
+		}
+		if len(removed) > 0 {
+			kept := make([]interface{}, 0, len(v)-len(removed))
+			for i, item := range v {
+				if !removed[i] {
+					kept = append(kept, item)
+				}
+			}
+			return kept, true
+		}
+		if result == nil {
+			return value, false
+		}
+		return result, true
+	}
+	return value, false
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+)
+
+// FreezeMiddleware returns a middleware that refuses to run mutating tools,
+// as reported by isWriteTool, while a change freeze window of config is in
+// effect. Callers holding the configured override role may still run them;
+// such overrides are logged.
+func FreezeMiddleware(config *policy.FreezeConfig, isWriteTool func(name string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !isWriteTool(request.Params.Name) {
+				return next(ctx, request)
+			}
+			window, end, active := config.ActiveWindow(time.Now())
+			if !active {
+				return next(ctx, request)
+			}
+			if config.OverrideRole != "" && policy.HasRole(ctx, config.OverrideRole) {
+				fmt.Fprintf(os.Stderr, "Change freeze %s overridden by role %s for tool %s\n", window.Name, config.OverrideRole, request.Params.Name)
+				return next(ctx, request)
+			}
+			return nil, fmt.Errorf("change freeze %s is in effect until %s; mutating tool %s is disabled", window.Name, end.Format(time.RFC3339), request.Params.Name)
+		}
+	}
+}
+
+// RolesMiddleware returns a middleware that grants the given roles to every
+// caller, for deployments where the server runs on behalf of a single operator.
+func RolesMiddleware(roles []string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return next(policy.WithRoles(ctx, roles...), request)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetImagePullReport returns a handler function for the getImagePullReport tool.
+// It aggregates image pull events per image, registry and node. The result is
+// serialized to JSON and returned.
+func GetImagePullReport(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		windowMinutes := getIntArg(args, "windowMinutes", 60)
+		if windowMinutes <= 0 {
+			return nil, fmt.Errorf("invalid argument windowMinutes: must be positive")
+		}
+
+		report, err := client.GetImagePullReport(ctx, getStringArg(args, "namespace", ""), windowMinutes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image pull report: %w", err)
+		}
+		if images, ok := report["images"].([]map[string]interface{}); ok {
+			setResultMetadata(ctx, "itemCount", len(images))
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ClusterInventory returns a handler function for the clusterInventory tool.
+// It summarizes the version, nodes, namespaces, workloads and
+// CustomResourceDefinitions of the cluster. The result is serialized to JSON
+// and returned.
+func ClusterInventory(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		inventory, err := client.ClusterInventory(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build cluster inventory: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(inventory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
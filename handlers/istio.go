@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetIstioTrafficConfig returns a handler function for the getIstioTrafficConfig tool.
+// It summarizes the Istio traffic configuration for the provided host or workload.
+// The result is serialized to JSON and returned.
+func GetIstioTrafficConfig(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		host := getStringArg(args, "host", "")
+		workload := getStringArg(args, "workload", "")
+		namespace := getStringArg(args, "namespace", "")
+
+		if host == "" && workload == "" {
+			return nil, fmt.Errorf("either host or workload is required")
+		}
+
+		summary, err := client.GetIstioTrafficConfig(ctx, namespace, host, workload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Istio traffic configuration: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(summary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetSidecarInjectionStatus returns a handler function for the getSidecarInjectionStatus tool.
+// It reports the mesh sidecar injection settings and proxies of the workloads
+// of a namespace. The result is serialized to JSON and returned.
+func GetSidecarInjectionStatus(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		status, err := client.GetSidecarInjectionStatus(ctx, getStringArg(args, "namespace", ""), getStringArg(args, "workload", ""))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sidecar injection status: %w", err)
+		}
+		if workloads, ok := status["workloads"].([]map[string]interface{}); ok {
+			setResultMetadata(ctx, "itemCount", len(workloads))
+		}
+
+		jsonResponse, err := json.Marshal(status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// parseJSONPath parses a kubectl -o jsonpath template such as
+// {.items[*].metadata.name} or
+// {range .items[*]}{.metadata.name}{"\t"}{.status.phase}{"\n"}{end}.
+// A template without braces is taken as a single expression, as kubectl's
+// custom-columns do, so metadata.name is read as {.metadata.name}. Missing
+// keys print nothing, as with kubectl's default
+// --allow-missing-template-keys.
+func parseJSONPath(template string) (*jsonpath.JSONPath, error) {
+	if !strings.Contains(template, "{") {
+		if !strings.HasPrefix(template, ".") && !strings.HasPrefix(template, "$") {
+			template = "." + template
+		}
+		template = "{" + template + "}"
+	}
+	path := jsonpath.New("jsonPath").AllowMissingKeys(true)
+	if err := path.Parse(template); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// jsonPathArg parses the jsonPath argument, if set.
+func jsonPathArg(args map[string]interface{}) (*jsonpath.JSONPath, error) {
+	template := getStringArg(args, "jsonPath", "")
+	if template == "" {
+		return nil, nil
+	}
+	path, err := parseJSONPath(template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid argument jsonPath: %w", err)
+	}
+	return path, nil
+}
+
+// executeJSONPath evaluates a parsed template against data and returns the
+// text it prints, as kubectl -o jsonpath would.
+func executeJSONPath(path *jsonpath.JSONPath, data interface{}) (string, error) {
+	var out bytes.Buffer
+	if err := path.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to evaluate jsonPath: %w", err)
+	}
+	return out.String(), nil
+}
+
+// jsonPathList wraps listed resources in a List, the object kubectl
+// evaluates jsonpath templates against, so that templates written for
+// kubectl get such as {.items[*].metadata.name} work unchanged.
+func jsonPathList(resources []map[string]interface{}) map[string]interface{} {
+	items := make([]interface{}, len(resources))
+	for i, resource := range resources {
+		items[i] = resource
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"metadata":   map[string]interface{}{"resourceVersion": ""},
+		"items":      items,
+	}
+}
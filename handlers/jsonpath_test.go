@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"testing"
+)
+
+// TestJSONPath tests evaluating kubectl jsonpath templates against listed and single resources
+func TestJSONPath(t *testing.T) {
+	pod := func(name, phase string, images ...string) map[string]interface{} {
+		var containers []interface{}
+		for _, image := range images {
+			containers = append(containers, map[string]interface{}{"image": image})
+		}
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"spec":     map[string]interface{}{"containers": containers},
+			"status":   map[string]interface{}{"phase": phase},
+		}
+	}
+	list := jsonPathList([]map[string]interface{}{
+		pod("web", "Running", "nginx:1.25", "envoy:1.30"),
+		pod("job", "Succeeded", "busybox"),
+	})
+
+	tests := []struct {
+		name     string
+		template string
+		data     interface{}
+		expected string
+	}{
+		{"names", "{.items[*].metadata.name}", list, "web job"},
+		{"range", `{range .items[*]}{.metadata.name}{"\t"}{.status.phase}{"\n"}{end}`, list, "web\tRunning\njob\tSucceeded\n"},
+		{"filter", `{.items[?(@.status.phase=="Running")].metadata.name}`, list, "web"},
+		{"kind", "{.kind}", list, "List"},
+		{"relaxed", "spec.containers[*].image", list["items"].([]interface{})[0], "nginx:1.25 envoy:1.30"},
+		{"missing key", "{.status.podIP}", list["items"].([]interface{})[0], ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := parseJSONPath(test.template)
+			if err != nil {
+				t.Fatalf("parseJSONPath(%q) failed: %v", test.template, err)
+			}
+			output, err := executeJSONPath(path, test.data)
+			if err != nil {
+				t.Fatalf("executeJSONPath failed: %v", err)
+			}
+			if output != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, output)
+			}
+		})
+	}
+
+	if _, err := jsonPathArg(map[string]interface{}{"jsonPath": "{.items[*"}); err == nil {
+		t.Error("Expected an unterminated template to be rejected")
+	}
+	if path, err := jsonPathArg(map[string]interface{}{}); path != nil || err != nil {
+		t.Errorf("Expected no template without a jsonPath argument, got %v (%v)", path, err)
+	}
+}
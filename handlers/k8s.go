@@ -5,7 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
 
@@ -27,6 +30,13 @@ func getBoolArg(args map[string]interface{}, key string, defaultValue bool) bool
 	return defaultValue
 }
 
+func getIntArg(args map[string]interface{}, key string, defaultValue int) int {
+	if val, ok := args[key].(float64); ok {
+		return int(val)
+	}
+	return defaultValue
+}
+
 func getRequiredStringArg(args map[string]interface{}, key string) (string, error) {
 	val, ok := args[key].(string)
 	if !ok || val == "" {
@@ -35,58 +45,182 @@ func getRequiredStringArg(args map[string]interface{}, key string) (string, erro
 	return val, nil
 }
 
-// extractFieldValue extracts a value from a nested map using a dot-separated path.
-// For example, "metadata.name" will extract obj["metadata"]["name"]
-func extractFieldValue(obj map[string]interface{}, path string) (interface{}, bool) {
-	parts := strings.Split(path, ".")
-	current := interface{}(obj)
-
-	for _, part := range parts {
-		switch v := current.(type) {
-		case map[string]interface{}:
-			var ok bool
-			current, ok = v[part]
+func getStringListArg(args map[string]interface{}, key string) ([]string, error) {
+	var values []string
+	switch raw := args[key].(type) {
+	case nil:
+	case []string:
+		values = raw
+	case []interface{}:
+		for _, item := range raw {
+			value, ok := item.(string)
 			if !ok {
-				return nil, false
+				return nil, fmt.Errorf("invalid argument %s: every element must be a string", key)
 			}
-		default:
-			return nil, false
+			values = append(values, value)
 		}
+	default:
+		return nil, fmt.Errorf("invalid argument %s: must be an array of strings", key)
 	}
-	return current, true
+	return values, nil
 }
 
-// setFieldValue sets a value in a nested map using a dot-separated path.
-// It creates intermediate maps as needed.
-func setFieldValue(obj map[string]interface{}, path string, value interface{}) {
-	parts := strings.Split(path, ".")
-	current := obj
-
-	for i := 0; i < len(parts)-1; i++ {
-		part := parts[i]
-		if _, ok := current[part]; !ok {
-			current[part] = make(map[string]interface{})
-		}
-		current = current[part].(map[string]interface{})
+// extractFieldValue extracts a value from a nested map using a field path.
+// For example, "metadata.name" will extract obj["metadata"]["name"], and
+// "status.conditions[0].type" the type of the first condition. Paths with
+// wildcards, such as "spec.containers[*].image", extract the list of all
+// matching values.
+func extractFieldValue(obj map[string]interface{}, path string) (interface{}, bool) {
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return nil, false
 	}
-
-	current[parts[len(parts)-1]] = value
+	var values []interface{}
+	walkFieldPath(obj, segments, func(value interface{}) {
+		values = append(values, value)
+	})
+	if len(values) == 0 {
+		return nil, false
+	}
+	if hasWildcard(segments) {
+		return values, true
+	}
+	return values[0], true
 }
 
 // projectFields returns a new map containing only the specified field paths.
-// If fieldPaths is empty, returns the original object.
+// Paths through lists keep the selected items, and only their selected
+// fields, so "spec.containers[*].name,spec.containers[*].image" returns each
+// container's name and image. If fieldPaths is empty, returns the original object.
 func projectFields(obj map[string]interface{}, fieldPaths []string) map[string]interface{} {
 	if len(fieldPaths) == 0 {
 		return obj
 	}
 
-	result := make(map[string]interface{})
+	var result interface{} = projectedMap{}
 	for _, path := range fieldPaths {
-		if value, ok := extractFieldValue(obj, path); ok {
-			setFieldValue(result, path, value)
+		segments, err := parseFieldPath(path)
+		if err != nil {
+			continue
+		}
+		if value, ok := projectFieldPath(obj, segments); ok {
+			result = mergeProjections(result, value)
+		}
+	}
+	projected, _ := finishProjection(result).(map[string]interface{})
+	return projected
+}
+
+// excludeFields returns a copy of obj with the specified field paths removed.
+// Paths can address list items and quoted keys like those of projectFields.
+// Only the maps and lists along each removed path are copied, so obj itself
+// is not modified. If excludePaths is empty, returns the original object.
+func excludeFields(obj map[string]interface{}, excludePaths []string) map[string]interface{} {
+	for _, path := range excludePaths {
+		segments, err := parseFieldPath(path)
+		if err != nil {
+			continue
+		}
+		if result, ok := removeFieldPath(obj, segments); ok {
+			obj = result.(map[string]interface{})
+		}
+	}
+	return obj
+}
+
+// excludeFieldPathsArg returns the paths to remove from results, given as
+// excludeFieldPaths or, for compatibility, excludeFields.
+func excludeFieldPathsArg(args map[string]interface{}) []string {
+	return append(parseFieldPaths(getStringArg(args, "excludeFieldPaths", "")), parseFieldPaths(getStringArg(args, "excludeFields", ""))...)
+}
+
+// applyFieldProjection keeps only fieldPaths (if any) and then removes excludePaths.
+func applyFieldProjection(obj map[string]interface{}, fieldPaths, excludePaths []string) map[string]interface{} {
+	return excludeFields(projectFields(obj, fieldPaths), excludePaths)
+}
+
+// parseFieldPaths splits a comma-separated list of field paths, trimming
+// whitespace and dropping empty entries.
+func parseFieldPaths(fieldPathsStr string) []string {
+	var fieldPaths []string
+	for _, path := range strings.Split(fieldPathsStr, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			fieldPaths = append(fieldPaths, path)
+		}
+	}
+	return fieldPaths
+}
+
+// splitResolvePaths separates the field paths marked with k8s.ResolveSuffix.
+// Returns the field paths with the suffix removed, so that the references
+// themselves are still projected, and the marked paths.
+func splitResolvePaths(fieldPaths []string) ([]string, []string) {
+	var resolvePaths []string
+	for i, path := range fieldPaths {
+		if strings.HasSuffix(path, k8s.ResolveSuffix) {
+			fieldPaths[i] = strings.TrimSuffix(path, k8s.ResolveSuffix)
+			resolvePaths = append(resolvePaths, fieldPaths[i])
+		}
+	}
+	return fieldPaths, resolvePaths
+}
+
+// sortResources sorts resources in place by the value at the dot-separated
+// path, keeping the original order of equal values. Resources without the
+// field sort last in either order.
+func sortResources(resources []map[string]interface{}, path string, descending bool) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, okA := extractFieldValue(resources[i], path)
+		b, okB := extractFieldValue(resources[j], path)
+		if !okA || !okB {
+			return okA && !okB
+		}
+		if descending {
+			return compareFieldValues(b, a) < 0
+		}
+		return compareFieldValues(a, b) < 0
+	})
+}
+
+// compareFieldValues orders two values extracted from objects. Numbers
+// compare numerically and strings, including RFC 3339 timestamps, lexically;
+// values of different types are ordered booleans, numbers, strings, then
+// anything else.
+func compareFieldValues(a, b interface{}) int {
+	rank := func(v interface{}) (int, float64) {
+		switch v := v.(type) {
+		case bool:
+			if v {
+				return 0, 1
+			}
+			return 0, 0
+		case int:
+			return 1, float64(v)
+		case int32:
+			return 1, float64(v)
+		case int64:
+			return 1, float64(v)
+		case float64:
+			return 1, v
+		case string:
+			return 2, 0
+		default:
+			return 3, 0
 		}
 	}
-	return result
+	rankA, numA := rank(a)
+	rankB, numB := rank(b)
+	switch {
+	case rankA != rankB:
+		return rankA - rankB
+	case rankA == 2:
+		return strings.Compare(a.(string), b.(string))
+	case numA < numB:
+		return -1
+	case numA > numB:
+		return 1
+	}
+	return 0
 }
 
 // GetAPIResources returns a handler function for the getAPIResources tool.
@@ -132,7 +266,8 @@ func GetAPIResources(client *k8s.Client) func(ctx context.Context, request mcp.C
 // ListResources returns a handler function for the listResources tool.
 // It lists resources in the Kubernetes cluster based on the provided kind,
 // namespace, and labelSelector. Supports field projection via fieldPaths
-// to limit the size of returned data. The result is serialized to JSON and returned.
+// to limit the size of returned data. The result is serialized to JSON and
+// returned, or, given a kubectl jsonPath template, the text it prints.
 func ListResources(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		fmt.Printf("[ListResources] START - Request: %#v\n", request.Params.Arguments)
@@ -150,42 +285,223 @@ func ListResources(client *k8s.Client) func(ctx context.Context, request mcp.Cal
 
 		namespace := getStringArg(args, "namespace", "")
 		labelSelector := getStringArg(args, "labelSelector", "")
+		fieldSelector := getStringArg(args, "fieldSelector", "")
 		fieldPathsStr := getStringArg(args, "fieldPaths", "")
+		excludePaths := excludeFieldPathsArg(args)
+		limit := getIntArg(args, "limit", 0)
+		if limit < 0 {
+			return nil, fmt.Errorf("invalid argument limit: must not be negative")
+		}
+		continueToken := getStringArg(args, "continue", "")
+		paged := limit > 0 || continueToken != ""
+		sortBy := getStringArg(args, "sortBy", "")
+		order := getStringArg(args, "order", "asc")
+		if order != "asc" && order != "desc" {
+			return nil, fmt.Errorf("invalid argument order: must be asc or desc")
+		}
+		if sortBy != "" && paged {
+			// The API server returns pages in its own order
+			return nil, fmt.Errorf("invalid arguments: sortBy cannot be combined with limit and continue")
+		}
 
-		fmt.Printf("[ListResources] Parsed - kind:%s, namespace:%s, labelSelector:%s, fieldPaths:%s\n", kind, namespace, labelSelector, fieldPathsStr)
+		// "*" is shorthand for allNamespaces
+		allNamespaces := getBoolArg(args, "allNamespaces", false)
+		if namespace == "*" {
+			allNamespaces, namespace = true, ""
+		}
+		if allNamespaces && namespace != "" {
+			return nil, fmt.Errorf("invalid arguments: namespace and allNamespaces cannot be combined")
+		}
+
+		fmt.Printf("[ListResources] Parsed - kind:%s, namespace:%s, labelSelector:%s, fieldPaths:%s, excludeFieldPaths:%v\n", kind, namespace, labelSelector, fieldPathsStr, excludePaths)
 
 		// Parse fieldPaths if provided
-		var fieldPaths []string
-		if fieldPathsStr != "" {
-			fieldPaths = strings.Split(fieldPathsStr, ",")
-			// Trim whitespace from each path
-			for i, path := range fieldPaths {
-				fieldPaths[i] = strings.TrimSpace(path)
+		fieldPaths, resolvePaths := splitResolvePaths(parseFieldPaths(fieldPathsStr))
+		if err := validateFieldPaths("fieldPaths", fieldPaths); err != nil {
+			return nil, err
+		}
+		if err := validateFieldPaths("excludeFieldPaths", excludePaths); err != nil {
+			return nil, err
+		}
+		if sortBy != "" {
+			segments, err := parseFieldPath(sortBy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument sortBy: %w", err)
+			}
+			if hasWildcard(segments) {
+				return nil, fmt.Errorf("invalid argument sortBy: must select a single value; use an index such as [0] instead of a wildcard")
+			}
+		}
+		path, err := jsonPathArg(args)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil {
+			if len(fieldPaths) > 0 || len(excludePaths) > 0 {
+				return nil, fmt.Errorf("invalid arguments: jsonPath cannot be combined with fieldPaths or excludeFieldPaths")
+			}
+			if paged {
+				return nil, fmt.Errorf("invalid arguments: jsonPath cannot be combined with limit and continue")
+			}
+		}
+		if allNamespaces && len(fieldPaths) > 0 && !slices.Contains(fieldPaths, "metadata.namespace") {
+			// Keep rows from different namespaces apart
+			fieldPaths = append(fieldPaths, "metadata.namespace")
+		}
+
+		// Pages are bounded, but full lists across namespaces may be too large
+		if !paged {
+			warning, err := largeScanWarning(ctx, client, args, kind, namespace, labelSelector, fieldSelector)
+			if warning != nil || err != nil {
+				return warning, err
+			}
+		}
+
+		// Return the server-side Table, with kubectl's printed columns, if
+		// requested as JSON with asTable or as markdown with outputFormat table
+		asMarkdown := getStringArg(args, "outputFormat", "") == "table"
+		if getBoolArg(args, "asTable", false) || asMarkdown {
+			tableArg := "asTable"
+			if asMarkdown {
+				tableArg = "outputFormat table"
+			}
+			if len(fieldPaths) > 0 || len(excludePaths) > 0 {
+				return nil, fmt.Errorf("invalid arguments: fieldPaths and excludeFieldPaths cannot be combined with %s", tableArg)
+			}
+			if paged {
+				return nil, fmt.Errorf("invalid arguments: limit and continue cannot be combined with %s", tableArg)
+			}
+			if sortBy != "" {
+				return nil, fmt.Errorf("invalid arguments: sortBy cannot be combined with %s", tableArg)
 			}
+			if path != nil {
+				return nil, fmt.Errorf("invalid arguments: jsonPath cannot be combined with %s", tableArg)
+			}
+			table, err := client.ListResourcesTable(ctx, kind, namespace, labelSelector, fieldSelector)
+			if err != nil && asMarkdown {
+				// Print kubectl's common columns when the API server cannot
+				// render a Table, or the namespace policy refuses one across
+				// all namespaces
+				fmt.Printf("[ListResources] Falling back to synthesized columns: %v\n", err)
+				var resources []map[string]interface{}
+				var skipped []string
+				if allNamespaces {
+					resources, skipped, err = client.ListResourcesAllNamespaces(ctx, kind, labelSelector, fieldSelector)
+				} else {
+					resources, err = client.ListResources(ctx, kind, namespace, labelSelector, fieldSelector)
+				}
+				if len(skipped) > 0 {
+					setResultMetadata(ctx, "skippedNamespaces", skipped)
+				}
+				table = k8s.SynthesizeTable(resources, namespace == "")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list resources for kind '%s': %w", kind, err)
+			}
+			if asMarkdown {
+				if rows, ok := table["rows"].([]map[string]interface{}); ok {
+					setResultMetadata(ctx, "itemCount", len(rows))
+				}
+				return mcp.NewToolResultText(renderMarkdownTable(table)), nil
+			}
+			jsonResponse, err := json.Marshal(table)
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize response: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonResponse)), nil
 		}
 
 		fmt.Printf("[ListResources] Fetching resources from K8s API...\n")
-		// Fetch resources (no fieldSelector, pass empty string)
-		resources, err := client.ListResources(ctx, kind, namespace, labelSelector, "")
+		// Fetch resources, one page at a time if a limit or continue token is given
+		var resources []map[string]interface{}
+		var nextToken string
+		var remaining *int64
+		var skipped []string
+		switch {
+		case paged:
+			resources, nextToken, remaining, err = client.ListResourcesPage(ctx, kind, namespace, labelSelector, fieldSelector, int64(limit), continueToken)
+		case allNamespaces:
+			resources, skipped, err = client.ListResourcesAllNamespaces(ctx, kind, labelSelector, fieldSelector)
+		default:
+			resources, err = client.ListResources(ctx, kind, namespace, labelSelector, fieldSelector)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to list resources for kind '%s': %w", kind, err)
 		}
 		fmt.Printf("[ListResources] Found %d resources\n", len(resources))
 
-		// Apply field projection if fieldPaths is specified
-		if len(fieldPaths) > 0 && len(resources) > 0 {
-			fmt.Printf("[ListResources] Applying field projection for %d paths...\n", len(fieldPaths))
+		// Aggregate the rows of cross-namespace lists by namespace
+		if allNamespaces {
+			counts := map[string]int{}
+			for _, resource := range resources {
+				if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+					if ns, ok := metadata["namespace"].(string); ok && ns != "" {
+						counts[ns]++
+					}
+				}
+			}
+			setResultMetadata(ctx, "namespaceCounts", counts)
+			if len(skipped) > 0 {
+				setResultMetadata(ctx, "skippedNamespaces", skipped)
+			}
+		}
+
+		// Sort before projecting, so that the sort field need not be projected
+		if sortBy != "" {
+			sortResources(resources, sortBy, order == "desc")
+		}
+
+		// Evaluate a JSONPath template against the list, as kubectl get -o jsonpath does
+		if path != nil {
+			output, err := executeJSONPath(path, jsonPathList(resources))
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText(output), nil
+		}
+
+		// Summarize custom resources by their CRD's printer columns unless a projection or full objects were requested
+		if len(fieldPaths) == 0 && len(excludePaths) == 0 && getBoolArg(args, "brief", true) && len(resources) > 0 {
+			if columns, err := client.GetPrinterColumns(ctx, kind); err == nil && len(columns) > 0 {
+				resources = k8s.PrinterColumnView(resources, columns)
+			}
+		}
+
+		// Fetch the objects referenced by fieldPaths marked with @resolve, once per object
+		var resolved []map[string]interface{}
+		if len(resolvePaths) > 0 {
+			resolved = client.ResolveReferences(ctx, resources, resolvePaths)
+		}
+
+		// Apply field projection if fieldPaths or excludeFieldPaths is specified
+		if (len(fieldPaths) > 0 || len(excludePaths) > 0) && len(resources) > 0 {
+			fmt.Printf("[ListResources] Applying field projection for %d paths, excluding %d paths...\n", len(fieldPaths), len(excludePaths))
 			projectedResources := make([]map[string]interface{}, len(resources))
 			for i, resource := range resources {
-				projectedResources[i] = projectFields(resource, fieldPaths)
+				projectedResources[i] = applyFieldProjection(resource, fieldPaths, excludePaths)
+				if resolved != nil {
+					projectedResources[i]["resolved"] = resolved[i]
+				}
 			}
 			resources = projectedResources
 			fmt.Printf("[ListResources] Field projection complete\n")
 		}
 
 		fmt.Printf("[ListResources] Marshaling to JSON...\n")
-		// Serialize response to JSON
-		jsonResponse, err := json.Marshal(resources)
+		// Serialize response to JSON; pages carry the token of the next page
+		var response interface{} = resources
+		if paged {
+			if resources == nil {
+				resources = []map[string]interface{}{}
+			}
+			page := map[string]interface{}{"items": resources, "continue": nextToken}
+			if remaining != nil {
+				page["remainingItemCount"] = *remaining
+			}
+			response = page
+			setResultMetadata(ctx, "truncated", nextToken != "")
+		}
+		jsonResponse, err := json.Marshal(response)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize response: %w", err)
 		}
@@ -198,8 +514,11 @@ func ListResources(client *k8s.Client) func(ctx context.Context, request mcp.Cal
 
 // GetResources returns a handler function for the getResource tool.
 // It retrieves a specific resource from the Kubernetes cluster based on the
-// provided kind, name, and namespace. Supports field projection via fieldPaths
-// to limit the size of returned data. The result is serialized to JSON and returned.
+// provided kind, name, and namespace. Supports the same field projection as
+// listResources: fieldPaths keeps only the given fields and excludeFieldPaths
+// then removes fields, to limit the size of returned data. The result is
+// serialized to JSON and returned, or, given a kubectl jsonPath template, the
+// text it prints.
 func GetResources(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		fmt.Printf("[GetResource] START - Request: %#v\n", request.Params.Arguments)
@@ -221,17 +540,28 @@ func GetResources(client *k8s.Client) func(ctx context.Context, request mcp.Call
 
 		namespace := getStringArg(args, "namespace", "")
 		fieldPathsStr := getStringArg(args, "fieldPaths", "")
+		excludePaths := excludeFieldPathsArg(args)
 
-		fmt.Printf("[GetResource] Parsed args - kind:%s, name:%s, namespace:%s, fieldPaths:%s\n", kind, name, namespace, fieldPathsStr)
+		fmt.Printf("[GetResource] Parsed args - kind:%s, name:%s, namespace:%s, fieldPaths:%s, excludeFieldPaths:%v\n", kind, name, namespace, fieldPathsStr, excludePaths)
 
 		// Parse fieldPaths if provided
-		var fieldPaths []string
-		if fieldPathsStr != "" {
-			fieldPaths = strings.Split(fieldPathsStr, ",")
-			// Trim whitespace from each path
-			for i, path := range fieldPaths {
-				fieldPaths[i] = strings.TrimSpace(path)
-			}
+		fieldPaths, resolvePaths := splitResolvePaths(parseFieldPaths(fieldPathsStr))
+		if err := validateFieldPaths("fieldPaths", fieldPaths); err != nil {
+			return nil, err
+		}
+		if err := validateFieldPaths("excludeFieldPaths", excludePaths); err != nil {
+			return nil, err
+		}
+		path, err := jsonPathArg(args)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil && (len(fieldPaths) > 0 || len(excludePaths) > 0) {
+			return nil, fmt.Errorf("invalid arguments: jsonPath cannot be combined with fieldPaths or excludeFieldPaths")
+		}
+		asMarkdown := getStringArg(args, "outputFormat", "") == "table"
+		if asMarkdown && (len(fieldPaths) > 0 || len(excludePaths) > 0 || path != nil) {
+			return nil, fmt.Errorf("invalid arguments: outputFormat table cannot be combined with fieldPaths, excludeFieldPaths or jsonPath")
 		}
 
 		fmt.Printf("[GetResource] Fetching resource from K8s API...\n")
@@ -241,10 +571,38 @@ func GetResources(client *k8s.Client) func(ctx context.Context, request mcp.Call
 		}
 		fmt.Printf("[GetResource] Resource fetched successfully\n")
 
-		// Apply field projection if fieldPaths is specified
-		if len(fieldPaths) > 0 {
-			fmt.Printf("[GetResource] Applying field projection for %d paths...\n", len(fieldPaths))
-			resource = projectFields(resource, fieldPaths)
+		// Print the resource as the one row kubectl get prints for it
+		if asMarkdown {
+			tableNamespace := namespace
+			if tableNamespace == "" {
+				tableNamespace = "default"
+			}
+			table, err := client.ListResourcesTable(ctx, kind, tableNamespace, "", "metadata.name="+name)
+			if rows, _ := table["rows"].([]map[string]interface{}); err != nil || len(rows) == 0 {
+				table = k8s.SynthesizeTable([]map[string]interface{}{resource}, false)
+			}
+			return mcp.NewToolResultText(renderMarkdownTable(table)), nil
+		}
+
+		if path != nil {
+			output, err := executeJSONPath(path, resource)
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText(output), nil
+		}
+
+		// Apply field projection if fieldPaths or excludeFieldPaths is specified
+		if len(fieldPaths) > 0 || len(excludePaths) > 0 {
+			fmt.Printf("[GetResource] Applying field projection for %d paths, excluding %d paths...\n", len(fieldPaths), len(excludePaths))
+			var resolved []map[string]interface{}
+			if len(resolvePaths) > 0 {
+				resolved = client.ResolveReferences(ctx, []map[string]interface{}{resource}, resolvePaths)
+			}
+			resource = applyFieldProjection(resource, fieldPaths, excludePaths)
+			if resolved != nil {
+				resource["resolved"] = resolved[0]
+			}
 			fmt.Printf("[GetResource] Field projection complete\n")
 		}
 
@@ -328,12 +686,23 @@ func GetPodsLogs(client *k8s.Client) func(ctx context.Context, request mcp.CallT
 			return nil, err
 		}
 
-		containerName := getStringArg(args, "containerName", "")
+		containerName := getStringArg(args, "containerName", getStringArg(args, "container", ""))
+		tailLines := getIntArg(args, "tailLines", 100)
+		sinceSeconds := getIntArg(args, "sinceSeconds", 0)
+		if tailLines < 0 || sinceSeconds < 0 {
+			return nil, fmt.Errorf("invalid arguments: tailLines and sinceSeconds must not be negative")
+		}
+		opts := k8s.LogOptions{
+			TailLines:    int64(tailLines),
+			SinceSeconds: int64(sinceSeconds),
+			Previous:     getBoolArg(args, "previous", false),
+			Timestamps:   getBoolArg(args, "timestamps", false),
+		}
 
-		fmt.Printf("[GetPodsLogs] Parsed - name:%s, namespace:%s, container:%s\n", name, namespace, containerName)
+		fmt.Printf("[GetPodsLogs] Parsed - name:%s, namespace:%s, container:%s, options:%+v\n", name, namespace, containerName, opts)
 		fmt.Printf("[GetPodsLogs] Fetching logs from K8s API...\n")
 		
-		logs, err := client.GetPodsLogs(ctx, namespace, containerName, name)
+		logs, err := client.GetPodsLogs(ctx, namespace, containerName, name, opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get logs for pod '%s': %w", name, err)
 		}
@@ -345,9 +714,9 @@ func GetPodsLogs(client *k8s.Client) func(ctx context.Context, request mcp.CallT
 }
 
 // GetNodeMetrics returns a handler function for the getNodeMetrics tool.
-// It retrieves resource usage metrics for a specific node from the Kubernetes
-// cluster based on the provided node name. The result is serialized to JSON
-// and returned.
+// It retrieves the CPU and memory usage of a specific node, or of all nodes
+// matching a label selector, compared with their allocatable resources. The
+// result is serialized to JSON and returned.
 func GetNodeMetrics(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
@@ -356,14 +725,26 @@ func GetNodeMetrics(client *k8s.Client) func(ctx context.Context, request mcp.Ca
 		}
 
 		// Using capital N to match your tools definition
-		name, err := getRequiredStringArg(args, "Name")
-		if err != nil {
-			return nil, err
+		name := getStringArg(args, "Name", "")
+		sortBy := getStringArg(args, "sortBy", "name")
+		if sortBy != "cpu" && sortBy != "memory" && sortBy != "name" {
+			return nil, fmt.Errorf("invalid argument sortBy: must be 'cpu', 'memory' or 'name'")
 		}
 
-		resourceUsage, err := client.GetNodeMetrics(ctx, name)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get metrics for node '%s': %w", name, err)
+		var resourceUsage map[string]interface{}
+		var err error
+		if name != "" {
+			resourceUsage, err = client.GetNodeMetrics(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get metrics for node '%s': %w", name, err)
+			}
+		} else {
+			resourceUsage, err = client.ListNodeMetrics(ctx, getStringArg(args, "labelSelector", ""), sortBy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get node metrics: %w", err)
+			}
+			nodes, _ := resourceUsage["nodes"].([]map[string]interface{})
+			setResultMetadata(ctx, "itemCount", len(nodes))
 		}
 
 		jsonResponse, err := json.Marshal(resourceUsage)
@@ -376,9 +757,9 @@ func GetNodeMetrics(client *k8s.Client) func(ctx context.Context, request mcp.Ca
 }
 
 // GetPodMetrics returns a handler function for the getPodMetrics tool.
-// It retrieves CPU and Memory metrics for a specific pod from the Kubernetes
-// cluster based on the provided namespace and pod name. The result is
-// serialized to JSON and returned.
+// It retrieves CPU and Memory metrics for a specific pod, or ranks the pods
+// matching a label selector by usage, limited to the top consumers. The
+// result is serialized to JSON and returned.
 func GetPodMetrics(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
@@ -386,19 +767,36 @@ func GetPodMetrics(client *k8s.Client) func(ctx context.Context, request mcp.Cal
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
-		namespace, err := getRequiredStringArg(args, "namespace")
-		if err != nil {
-			return nil, err
+		namespace := getStringArg(args, "namespace", "")
+		podName := getStringArg(args, "podName", "")
+		sortBy := getStringArg(args, "sortBy", "name")
+		if sortBy != "cpu" && sortBy != "memory" && sortBy != "name" {
+			return nil, fmt.Errorf("invalid argument sortBy: must be 'cpu', 'memory' or 'name'")
 		}
-
-		podName, err := getRequiredStringArg(args, "podName")
-		if err != nil {
-			return nil, err
+		limit := getIntArg(args, "limit", 0)
+		if limit < 0 {
+			return nil, fmt.Errorf("invalid argument limit: must not be negative")
 		}
 
-		metrics, err := client.GetPodMetrics(ctx, namespace, podName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get metrics for pod '%s' in namespace '%s': %w", podName, namespace, err)
+		var metrics map[string]interface{}
+		var err error
+		if podName != "" {
+			if namespace == "" {
+				return nil, fmt.Errorf("missing required parameter: namespace")
+			}
+			metrics, err = client.GetPodMetrics(ctx, namespace, podName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get metrics for pod '%s' in namespace '%s': %w", podName, namespace, err)
+			}
+		} else {
+			metrics, err = client.ListPodMetrics(ctx, namespace, getStringArg(args, "labelSelector", ""), sortBy, limit)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get pod metrics: %w", err)
+			}
+			pods, _ := metrics["pods"].([]map[string]interface{})
+			totalPods, _ := metrics["totalPods"].(int)
+			setResultMetadata(ctx, "itemCount", len(pods))
+			setResultMetadata(ctx, "truncated", totalPods > len(pods))
 		}
 
 		jsonResponse, err := json.Marshal(metrics)
@@ -425,6 +823,14 @@ func GetEvents(client *k8s.Client) func(ctx context.Context, request mcp.CallToo
 		namespace := getStringArg(args, "namespace", "")
 		sortBy := getStringArg(args, "sortBy", "lastTime")
 		messageFilter := getStringArg(args, "messageFilter", "")
+		fieldPaths := normalizeEventFieldPaths(parseFieldPaths(getStringArg(args, "fieldPaths", "")))
+		excludePaths := normalizeEventFieldPaths(excludeFieldPathsArg(args))
+		if err := validateFieldPaths("fieldPaths", fieldPaths); err != nil {
+			return nil, err
+		}
+		if err := validateFieldPaths("excludeFieldPaths", excludePaths); err != nil {
+			return nil, err
+		}
 
 		// Get maxEvents with default of 20
 		maxEvents := 20
@@ -435,11 +841,29 @@ func GetEvents(client *k8s.Client) func(ctx context.Context, request mcp.CallToo
 		fmt.Printf("[GetEvents] Parsed - namespace:%s, maxEvents:%d, sortBy:%s, messageFilter:%s\n", namespace, maxEvents, sortBy, messageFilter)
 		fmt.Printf("[GetEvents] Fetching events from K8s API...\n")
 		
-		events, err := client.GetEvents(ctx, namespace, maxEvents, sortBy, messageFilter)
+		var events []map[string]interface{}
+		var err error
+		if workload := getStringArg(args, "workload", ""); workload != "" {
+			workloadKind := getStringArg(args, "workloadKind", "Deployment")
+			events, err = client.GetWorkloadEvents(ctx, namespace, workloadKind, workload, maxEvents, sortBy, messageFilter)
+		} else {
+			// maxEvents bounds the output, but all events are listed
+			if warning, err := largeScanWarning(ctx, client, args, "Event", namespace, "", ""); warning != nil || err != nil {
+				return warning, err
+			}
+			events, err = client.GetEvents(ctx, namespace, maxEvents, sortBy, messageFilter)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to get events: %w", err)
 		}
 
+		if len(fieldPaths) > 0 || len(excludePaths) > 0 {
+			for i, event := range events {
+				events[i] = applyFieldProjection(event, fieldPaths, excludePaths)
+			}
+		}
+		setResultMetadata(ctx, "truncated", maxEvents > 0 && len(events) >= maxEvents)
+
 		fmt.Printf("[GetEvents] Found %d events, marshaling...\n", len(events))
 		jsonResponse, err := json.Marshal(events)
 		if err != nil {
@@ -451,6 +875,32 @@ func GetEvents(client *k8s.Client) func(ctx context.Context, request mcp.CallToo
 	}
 }
 
+// eventFieldAliases maps events.k8s.io/v1 field names and common shorthands to
+// the fields of the normalized event schema.
+var eventFieldAliases = map[string]string{
+	"note":      "message",
+	"regarding": "involvedObject",
+	"counts":    "count",
+	"series":    "count",
+}
+
+// normalizeEventFieldPaths rewrites the first segment of each event field path
+// using eventFieldAliases, so "note" selects "message" and
+// "regarding.name" selects "involvedObject.name".
+func normalizeEventFieldPaths(fieldPaths []string) []string {
+	for i, path := range fieldPaths {
+		head, rest, nested := strings.Cut(path, ".")
+		if alias, ok := eventFieldAliases[head]; ok {
+			if nested {
+				fieldPaths[i] = alias + "." + rest
+			} else {
+				fieldPaths[i] = alias
+			}
+		}
+	}
+	return fieldPaths
+}
+
 // CreateOrUpdateResource returns a handler function for the createOrUpdateResource tool.
 // It creates or updates a resource in the Kubernetes cluster based on the provided
 // namespace and manifest. The result is serialized to JSON and returned.
@@ -537,16 +987,71 @@ func DeleteResource(client *k8s.Client) func(ctx context.Context, request mcp.Ca
 		}
 
 		namespace := getStringArg(args, "namespace", "")
+		opts := k8s.DeleteOptions{
+			PropagationPolicy: getStringArg(args, "propagationPolicy", ""),
+		}
+		if _, ok := args["gracePeriodSeconds"]; ok {
+			gracePeriod := int64(getIntArg(args, "gracePeriodSeconds", 0))
+			opts.GracePeriodSeconds = &gracePeriod
+		}
+		dryRun := getBoolArg(args, "dryRun", false)
+		if dryRun {
+			ctx = k8s.WithDryRun(ctx)
+		}
 
-		err = client.DeleteResource(ctx, kind, name, namespace)
+		err = client.DeleteResource(ctx, kind, name, namespace, opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to delete resource: %w", err)
 		}
 
+		if dryRun {
+			return mcp.NewToolResultText("Resource deletion validated (dry run); nothing was deleted"), nil
+		}
 		return mcp.NewToolResultText("Resource deleted successfully"), nil
 	}
 }
 
+// CleanupFinished returns a handler function for the cleanupFinished tool.
+// It deletes finished pods and Jobs older than the given thresholds and
+// reports what was removed. The result is serialized to JSON and returned.
+func CleanupFinished(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		podAge := getIntArg(args, "podAgeMinutes", 60)
+		if podAge < 0 {
+			return nil, fmt.Errorf("invalid argument podAgeMinutes: must not be negative")
+		}
+		jobTTL := getIntArg(args, "jobTTLMinutes", 1440)
+		if jobTTL < 0 {
+			return nil, fmt.Errorf("invalid argument jobTTLMinutes: must not be negative")
+		}
+		if getBoolArg(args, "dryRun", false) {
+			ctx = k8s.WithDryRun(ctx)
+		}
+
+		result, err := client.CleanupFinished(ctx, namespace, k8s.CleanupOptions{
+			LabelSelector: getStringArg(args, "labelSelector", ""),
+			PodAge:        time.Duration(podAge) * time.Minute,
+			JobTTL:        time.Duration(jobTTL) * time.Minute,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to clean up finished pods and jobs: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
 // getIngresses returns a handler function for the getIngresses tool.
 // It retrieves ingress resources from the Kubernetes cluster based on the provided
 // Host and Path. The result is serialized to JSON and returned.
@@ -573,8 +1078,99 @@ func GetIngresses(client *k8s.Client) func(ctx context.Context, request mcp.Call
 	}
 }
 
+// CheckIngressPorts returns a handler function for the checkIngressPorts tool.
+// It follows the Ingress backends serving a host and path to the container
+// ports of their pods and reports mismatches. The result is serialized to
+// JSON and returned.
+func CheckIngressPorts(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		host := getStringArg(args, "host", "")
+		path := getStringArg(args, "path", "")
+		if path != "" && !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid argument path: must start with /")
+		}
+
+		result, err := client.CheckIngressPorts(ctx, namespace, host, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check ingress ports: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// CheckStatefulSetDNS returns a handler function for the checkStatefulSetDNS
+// tool. It verifies the headless Service and per-pod DNS records of a
+// StatefulSet. The result is serialized to JSON and returned.
+func CheckStatefulSetDNS(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		clusterDomain := strings.Trim(getStringArg(args, "clusterDomain", ""), ".")
+
+		result, err := client.CheckStatefulSetDNS(ctx, namespace, name, clusterDomain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check statefulset dns: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// DiagnoseLoadBalancers returns a handler function for the
+// diagnoseLoadBalancers tool. It explains the provisioning state of
+// LoadBalancer Services. The result is serialized to JSON and returned.
+func DiagnoseLoadBalancers(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name := getStringArg(args, "name", "")
+		namespace := getStringArg(args, "namespace", "")
+
+		result, err := client.DiagnoseLoadBalancers(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diagnose load balancers: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
 // RolloutRestartHandler returns a handler function for the rolloutRestart tool.
-// It calls the Client.RolloutRestart method and serializes the result to JSON.
+// It calls the Client.RolloutRestart method, optionally waits for the restart
+// to roll out, and serializes the result to JSON.
 func RolloutRestart(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
@@ -589,11 +1185,22 @@ func RolloutRestart(client *k8s.Client) func(ctx context.Context, request mcp.Ca
 		if kind == "" || name == "" || namespace == "" {
 			return nil, fmt.Errorf("kind, name, and namespace are required")
 		}
+		rolloutTimeout, err := rolloutWaitArg(args)
+		if err != nil {
+			return nil, err
+		}
 
 		result, err := client.RolloutRestart(ctx, kind, name, namespace)
 		if err != nil {
 			return nil, fmt.Errorf("failed to rollout restart resource: %w", err)
 		}
+		if rolloutTimeout > 0 {
+			rollout, err := client.WaitForRollout(ctx, kind, name, namespace, rolloutTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("failed to wait for rollout: %w", err)
+			}
+			result["rollout"] = rollout
+		}
 
 		jsonResponse, err := json.Marshal(result)
 		if err != nil {
@@ -3,6 +3,11 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -468,6 +473,51 @@ func TestGetResources(t *testing.T) {
 
 		t.Logf("Successfully retrieved pod with field projection: %s", podName)
 	})
+
+	t.Run("Get cluster-scoped resource ignores namespace", func(t *testing.T) {
+		nodeList, err := listHandler(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "listResources",
+				Arguments: map[string]interface{}{
+					"Kind":       "Node",
+					"namespace":  "kube-system",
+					"fieldPaths": "metadata.name",
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to list nodes with a namespace set: %v", err)
+		}
+
+		var nodes []map[string]interface{}
+		if err := json.Unmarshal([]byte(nodeList.Content[0].(mcp.TextContent).Text), &nodes); err != nil {
+			t.Fatalf("Failed to parse nodes: %v", err)
+		}
+		if len(nodes) == 0 {
+			t.Skip("No nodes found, skipping cluster-scoped get")
+		}
+		nodeName, _ := nodes[0]["metadata"].(map[string]interface{})["name"].(string)
+
+		result, err := handler(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "getResource",
+				Arguments: map[string]interface{}{
+					"kind":       "Node",
+					"name":       nodeName,
+					"namespace":  "kube-system",
+					"fieldPaths": "metadata.name",
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to get node %s with a namespace set: %v", nodeName, err)
+		}
+		if result == nil || len(result.Content) == 0 {
+			t.Fatal("Expected content in result")
+		}
+
+		t.Logf("Successfully retrieved cluster-scoped node: %s", nodeName)
+	})
 }
 
 // TestGetAPIResources tests listing API resources
@@ -563,25 +613,100 @@ func TestFieldProjectionHelpers(t *testing.T) {
 		}
 	})
 
-	t.Run("setFieldValue - simple path", func(t *testing.T) {
-		obj := make(map[string]interface{})
-		setFieldValue(obj, "name", "test")
+	t.Run("projectFields - simple path", func(t *testing.T) {
+		obj := map[string]interface{}{"name": "test", "kind": "Pod"}
+		projected := projectFields(obj, []string{"name"})
 
-		if obj["name"] != "test" {
-			t.Errorf("Expected 'test', got %v", obj["name"])
+		if projected["name"] != "test" || len(projected) != 1 {
+			t.Errorf("Expected only name 'test', got %v", projected)
 		}
 	})
 
-	t.Run("setFieldValue - nested path", func(t *testing.T) {
-		obj := make(map[string]interface{})
-		setFieldValue(obj, "metadata.name", "pod-1")
+	t.Run("projectFields - nested path", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "pod-1", "uid": "123"},
+		}
+		projected := projectFields(obj, []string{"metadata.name"})
 
-		metadata, ok := obj["metadata"].(map[string]interface{})
+		metadata, ok := projected["metadata"].(map[string]interface{})
 		if !ok {
 			t.Error("Expected metadata to be a map")
 		}
-		if metadata["name"] != "pod-1" {
-			t.Errorf("Expected 'pod-1', got %v", metadata["name"])
+		if metadata["name"] != "pod-1" || len(metadata) != 1 {
+			t.Errorf("Expected only name 'pod-1', got %v", metadata)
+		}
+		if obj["metadata"].(map[string]interface{})["uid"] != "123" {
+			t.Error("Expected the input to be unchanged")
+		}
+	})
+
+	t.Run("extractFieldValue - list index and wildcard", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Initialized"},
+					map[string]interface{}{"type": "Ready"},
+				},
+			},
+		}
+
+		if value, ok := extractFieldValue(obj, "status.conditions[0].type"); !ok || value != "Initialized" {
+			t.Errorf("Expected 'Initialized', got %v", value)
+		}
+		if value, ok := extractFieldValue(obj, "status.conditions[-1].type"); !ok || value != "Ready" {
+			t.Errorf("Expected 'Ready' for the last condition, got %v", value)
+		}
+		if value, ok := extractFieldValue(obj, "status.conditions[*].type"); !ok || len(value.([]interface{})) != 2 {
+			t.Errorf("Expected both condition types, got %v", value)
+		}
+		if _, ok := extractFieldValue(obj, "status.conditions[2].type"); ok {
+			t.Error("Expected extraction to fail for an index out of range")
+		}
+	})
+
+	t.Run("projectFields - list paths", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"app": "web", "tier": "front"},
+			},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:1", "env": []interface{}{}},
+					map[string]interface{}{"name": "proxy", "image": "envoy:1"},
+				},
+			},
+		}
+
+		projected := projectFields(obj, []string{"spec.containers[*].name", "spec.containers[*].image", "metadata.labels.*"})
+		containers := projected["spec"].(map[string]interface{})["containers"].([]interface{})
+		if len(containers) != 2 {
+			t.Fatalf("Expected 2 containers, got %v", containers)
+		}
+		first := containers[0].(map[string]interface{})
+		if first["name"] != "app" || first["image"] != "app:1" || len(first) != 2 {
+			t.Errorf("Expected only the name and image of the first container, got %v", first)
+		}
+		if labels := projected["metadata"].(map[string]interface{})["labels"].(map[string]interface{}); len(labels) != 2 {
+			t.Errorf("Expected all labels, got %v", labels)
+		}
+
+		projected = projectFields(obj, []string{"spec.containers[1].image"})
+		containers = projected["spec"].(map[string]interface{})["containers"].([]interface{})
+		if len(containers) != 1 || containers[0].(map[string]interface{})["image"] != "envoy:1" {
+			t.Errorf("Expected only the second container's image, got %v", containers)
+		}
+
+		projected = projectFields(obj, []string{"spec.containers[*].name", "spec.containers"})
+		if containers := projected["spec"].(map[string]interface{})["containers"].([]interface{}); len(containers[0].(map[string]interface{})) != 3 {
+			t.Errorf("Expected the whole containers list to win over parts of it, got %v", containers)
+		}
+	})
+
+	t.Run("parseFieldPath - invalid paths", func(t *testing.T) {
+		for _, path := range []string{"spec..containers", "spec.containers[", "spec.containers[x]", "spec.containers[0"} {
+			if _, err := parseFieldPath(path); err == nil {
+				t.Errorf("Expected %q to be rejected", path)
+			}
 		}
 	})
 
@@ -641,4 +766,281 @@ func TestFieldProjectionHelpers(t *testing.T) {
 			t.Error("Expected original object when no paths specified")
 		}
 	})
+
+	t.Run("excludeFields - removes nested path without modifying input", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":          "pod-1",
+				"managedFields": []interface{}{"noise"},
+			},
+			"status": map[string]interface{}{
+				"phase": "Running",
+			},
+		}
+
+		result := excludeFields(obj, []string{"metadata.managedFields", "status", "spec.missing"})
+
+		metadata := result["metadata"].(map[string]interface{})
+		if _, ok := metadata["managedFields"]; ok {
+			t.Error("Expected metadata.managedFields to be removed")
+		}
+		if metadata["name"] != "pod-1" {
+			t.Errorf("Expected name 'pod-1', got %v", metadata["name"])
+		}
+		if _, ok := result["status"]; ok {
+			t.Error("Expected status to be removed")
+		}
+
+		// The original object must be untouched
+		if _, ok := obj["metadata"].(map[string]interface{})["managedFields"]; !ok {
+			t.Error("Expected input object to keep metadata.managedFields")
+		}
+		if _, ok := obj["status"]; !ok {
+			t.Error("Expected input object to keep status")
+		}
+	})
+
+	t.Run("excludeFields - quoted keys and list items", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					"kubectl.kubernetes.io/last-applied-configuration": "{...}",
+					"team": "payments",
+				},
+			},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "env": []interface{}{"noise"}},
+					map[string]interface{}{"name": "proxy"},
+				},
+				"volumes": []interface{}{"a", "b", "c"},
+			},
+		}
+
+		result := excludeFields(obj, []string{
+			`metadata.annotations."kubectl.kubernetes.io/last-applied-configuration"`,
+			"spec.containers[*].env",
+			"spec.volumes[-1]",
+		})
+
+		annotations := result["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
+		if len(annotations) != 1 || annotations["team"] != "payments" {
+			t.Errorf("Expected only the team annotation, got %v", annotations)
+		}
+		spec := result["spec"].(map[string]interface{})
+		if app := spec["containers"].([]interface{})[0].(map[string]interface{}); len(app) != 1 || app["name"] != "app" {
+			t.Errorf("Expected the env of the app container to be removed, got %v", app)
+		}
+		if volumes := spec["volumes"].([]interface{}); len(volumes) != 2 || volumes[1] != "b" {
+			t.Errorf("Expected the last volume to be removed, got %v", volumes)
+		}
+		if _, ok := obj["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})["env"]; !ok {
+			t.Error("Expected input object to keep the container env")
+		}
+		if len(obj["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})) != 2 {
+			t.Error("Expected input object to keep its annotations")
+		}
+	})
+
+	t.Run("excludeFieldPathsArg - accepts both names", func(t *testing.T) {
+		paths := excludeFieldPathsArg(map[string]interface{}{"excludeFieldPaths": "metadata.managedFields", "excludeFields": "status"})
+		if len(paths) != 2 || paths[0] != "metadata.managedFields" || paths[1] != "status" {
+			t.Errorf("Expected paths from both arguments, got %v", paths)
+		}
+	})
+
+	t.Run("parseFieldPath - quoted keys", func(t *testing.T) {
+		segments, err := parseFieldPath(`metadata.annotations."a.b/c"`)
+		if err != nil || len(segments) != 3 || segments[2].key != "a.b/c" {
+			t.Errorf("Expected the quoted key a.b/c, got %v, %v", segments, err)
+		}
+		for _, path := range []string{`metadata."open`, `metadata."a"b`, "metadata."} {
+			if _, err := parseFieldPath(path); err == nil {
+				t.Errorf("Expected %q to be rejected", path)
+			}
+		}
+	})
+
+	t.Run("applyFieldProjection - include then exclude", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"reason":  "BackOff",
+			"message": "Back-off restarting failed container",
+			"count":   3,
+		}
+
+		result := applyFieldProjection(obj, []string{"reason", "message"}, []string{"message"})
+		if len(result) != 1 || result["reason"] != "BackOff" {
+			t.Errorf("Expected only reason in result, got %v", result)
+		}
+	})
+
+	t.Run("parseFieldPaths - trims and drops empty entries", func(t *testing.T) {
+		paths := parseFieldPaths(" metadata.name, ,status.phase,")
+		if len(paths) != 2 || paths[0] != "metadata.name" || paths[1] != "status.phase" {
+			t.Errorf("Unexpected parsed paths: %v", paths)
+		}
+		if parseFieldPaths("") != nil {
+			t.Error("Expected no paths for empty input")
+		}
+	})
+
+	t.Run("normalizeEventFieldPaths - maps event field aliases", func(t *testing.T) {
+		paths := normalizeEventFieldPaths([]string{"note", "regarding.name", "reason"})
+		if paths[0] != "message" || paths[1] != "involvedObject.name" || paths[2] != "reason" {
+			t.Errorf("Unexpected normalized paths: %v", paths)
+		}
+	})
+
+	t.Run("splitResolvePaths - strips the resolve suffix", func(t *testing.T) {
+		paths, resolvePaths := splitResolvePaths([]string{"metadata.name", "spec.serviceAccountName@resolve"})
+		if len(paths) != 2 || paths[1] != "spec.serviceAccountName" {
+			t.Errorf("Unexpected field paths: %v", paths)
+		}
+		if len(resolvePaths) != 1 || resolvePaths[0] != "spec.serviceAccountName" {
+			t.Errorf("Unexpected resolve paths: %v", resolvePaths)
+		}
+	})
+}
+
+// TestSortResources tests sorting listed resources by a field path
+func TestSortResources(t *testing.T) {
+	pod := func(name, created string, restarts interface{}) map[string]interface{} {
+		obj := map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "creationTimestamp": created},
+		}
+		if restarts != nil {
+			obj["status"] = map[string]interface{}{"restarts": restarts}
+		}
+		return obj
+	}
+	names := func(resources []map[string]interface{}) []string {
+		var result []string
+		for _, r := range resources {
+			result = append(result, r["metadata"].(map[string]interface{})["name"].(string))
+		}
+		return result
+	}
+	resources := []map[string]interface{}{
+		pod("b", "2024-03-01T00:00:00Z", int64(10)),
+		pod("a", "2024-01-01T00:00:00Z", nil),
+		pod("c", "2024-02-01T00:00:00Z", float64(2)),
+		pod("d", "2024-02-01T00:00:00Z", int64(2)),
+	}
+
+	sortResources(resources, "metadata.creationTimestamp", false)
+	if got := names(resources); !slices.Equal(got, []string{"a", "c", "d", "b"}) {
+		t.Errorf("Expected oldest first with equal timestamps kept in order, got %v", got)
+	}
+
+	sortResources(resources, "status.restarts", true)
+	if got := names(resources); !slices.Equal(got, []string{"b", "c", "d", "a"}) {
+		t.Errorf("Expected most restarts first and the pod without restarts last, got %v", got)
+	}
+
+	if compareFieldValues(true, int64(1)) >= 0 || compareFieldValues(int64(1), "1") >= 0 {
+		t.Error("Expected booleans before numbers before strings")
+	}
+}
+
+// TestGetResourceFieldProjection tests trimming a single fetched resource with fieldPaths and excludeFieldPaths
+func TestGetResourceFieldProjection(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","apiVersion":"v1","groups":[]}`))
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"v1","resources":[{"name":"pods","singularName":"pod","namespaced":true,"kind":"Pod","shortNames":["po"],"verbs":["get","list"]}]}`))
+		case "/api/v1/namespaces/shop/pods/web":
+			w.Write([]byte(`{"kind":"Pod","apiVersion":"v1","metadata":{"name":"web","namespace":"shop",` +
+				`"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}","team":"payments"},"managedFields":[{"manager":"kubectl"}]},` +
+				`"spec":{"containers":[{"name":"app","image":"shop:1.2","env":[{"name":"MODE","value":"prod"}]},{"name":"proxy","image":"envoy:1.30"}]},` +
+				`"status":{"phase":"Running"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer apiServer.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	config := `apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster: {server: "` + apiServer.URL + `"}
+users:
+- name: admin
+  user: {token: secret}
+contexts:
+- name: test
+  context: {cluster: test, user: admin}
+`
+	if err := os.WriteFile(kubeconfig, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := k8s.NewClient(kubeconfig)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	handler := GetResources(client)
+
+	get := func(args map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		args["kind"], args["name"], args["namespace"] = "pod", "web", "shop"
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "getResource", Arguments: args}})
+		if err != nil {
+			t.Fatalf("getResource failed: %v", err)
+		}
+		var resource map[string]interface{}
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resource); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return resource
+	}
+
+	projected := get(map[string]interface{}{"fieldPaths": "metadata.name,spec.containers[*].image"})
+	if len(projected) != 2 || projected["status"] != nil {
+		t.Errorf("Expected only metadata and spec, got %v", projected)
+	}
+	if containers := projected["spec"].(map[string]interface{})["containers"].([]interface{}); len(containers) != 2 ||
+		len(containers[0].(map[string]interface{})) != 1 || containers[1].(map[string]interface{})["image"] != "envoy:1.30" {
+		t.Errorf("Expected only the image of both containers, got %v", containers)
+	}
+
+	trimmed := get(map[string]interface{}{
+		"excludeFieldPaths": `metadata.managedFields,metadata.annotations."kubectl.kubernetes.io/last-applied-configuration",spec.containers[*].env`,
+	})
+	metadata := trimmed["metadata"].(map[string]interface{})
+	if metadata["managedFields"] != nil || len(metadata["annotations"].(map[string]interface{})) != 1 || trimmed["status"] == nil {
+		t.Errorf("Expected managedFields and the last-applied annotation removed, got %v", trimmed)
+	}
+	if container := trimmed["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{}); container["env"] != nil || container["image"] != "shop:1.2" {
+		t.Errorf("Expected env removed from the containers, got %v", container)
+	}
+
+	both := get(map[string]interface{}{"fieldPaths": "metadata", "excludeFields": "metadata.managedFields"})
+	if metadata := both["metadata"].(map[string]interface{}); metadata["managedFields"] != nil || metadata["name"] != "web" {
+		t.Errorf("Expected excludeFields to apply after fieldPaths, got %v", both)
+	}
+
+	if _, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "getResource",
+		Arguments: map[string]interface{}{"kind": "pod", "name": "web", "namespace": "shop", "excludeFieldPaths": "spec.containers[x]"}}}); err == nil {
+		t.Error("Expected an invalid excludeFieldPaths to be rejected")
+	}
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "getResource",
+		Arguments: map[string]interface{}{"kind": "pod", "name": "web", "namespace": "shop", "jsonPath": "{.spec.containers[*].image}"}}})
+	if err != nil {
+		t.Fatalf("getResource with jsonPath failed: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "shop:1.2 envoy:1.30" {
+		t.Errorf("Expected the printed images, got %q", text)
+	}
+	if _, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "getResource",
+		Arguments: map[string]interface{}{"kind": "pod", "name": "web", "namespace": "shop", "jsonPath": "{.status.phase}", "fieldPaths": "status"}}}); err == nil {
+		t.Error("Expected jsonPath combined with fieldPaths to be rejected")
+	}
 }
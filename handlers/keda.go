@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetKedaScalers returns a handler function for the getKedaScalers tool.
+// It reports KEDA ScaledObjects and ScaledJobs for the provided namespace and
+// optional name. The result is serialized to JSON and returned.
+func GetKedaScalers(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		name := getStringArg(args, "name", "")
+
+		scalers, err := client.GetKedaScalers(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get KEDA scalers: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(scalers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
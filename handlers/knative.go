@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetKnativeServices returns a handler function for the getKnativeServices tool.
+// It reports Knative Services and their revisions for the provided namespace
+// and optional name. The result is serialized to JSON and returned.
+func GetKnativeServices(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		name := getStringArg(args, "name", "")
+
+		services, err := client.GetKnativeServices(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Knative services: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(services)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetLogsBySelector returns a handler function for the getLogsBySelector tool.
+// It retrieves the logs of all pods matching a label selector, prefixed with
+// the pod and container name, interleaved by timestamp or grouped per
+// container. The logs are returned as plain text, preceded by a line naming
+// any containers whose logs could not be read.
+func GetLogsBySelector(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+		labelSelector, err := getRequiredStringArg(args, "labelSelector")
+		if err != nil {
+			return nil, err
+		}
+		container := getStringArg(args, "container", "")
+		tailLines := getIntArg(args, "tailLines", 50)
+		sinceSeconds := getIntArg(args, "sinceSeconds", 0)
+		maxPods := getIntArg(args, "maxPods", 20)
+		if tailLines < 0 || sinceSeconds < 0 || maxPods < 0 {
+			return nil, fmt.Errorf("invalid arguments: tailLines, sinceSeconds and maxPods must not be negative")
+		}
+		mode := getStringArg(args, "mode", "interleaved")
+		if mode != "interleaved" && mode != "grouped" {
+			return nil, fmt.Errorf("invalid argument mode: must be 'interleaved' or 'grouped'")
+		}
+		opts := k8s.LogOptions{
+			TailLines:    int64(tailLines),
+			SinceSeconds: int64(sinceSeconds),
+			Previous:     getBoolArg(args, "previous", false),
+			Timestamps:   getBoolArg(args, "timestamps", false),
+		}
+
+		result, err := client.GetLogsBySelector(ctx, namespace, labelSelector, container, opts, mode == "interleaved", maxPods)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get logs for selector '%s': %w", labelSelector, err)
+		}
+
+		pods, _ := result["pods"].(int)
+		totalPods, _ := result["totalPods"].(int)
+		setResultMetadata(ctx, "itemCount", pods)
+		setResultMetadata(ctx, "truncated", totalPods > pods)
+
+		var text strings.Builder
+		if totalPods > pods {
+			text.WriteString(fmt.Sprintf("--- Showing logs of %d of %d matching pods; raise maxPods or narrow the selector to see the rest ---\n", pods, totalPods))
+		}
+		if errors, _ := result["errors"].([]string); len(errors) > 0 {
+			text.WriteString(fmt.Sprintf("--- Failed to read %d container logs: %s ---\n", len(errors), strings.Join(errors, "; ")))
+		}
+		if totalPods == 0 {
+			text.WriteString(fmt.Sprintf("No pods match selector '%s' in namespace %s\n", labelSelector, namespace))
+		}
+		logs, _ := result["logs"].(string)
+		text.WriteString(logs)
+
+		return mcp.NewToolResultText(text.String()), nil
+	}
+}
+
+// CorrelateEventLogs returns a handler function for the correlateEventLogs tool.
+// It pairs Warning events of pods with the log lines their container wrote
+// around the event time. The result is serialized to JSON and returned.
+func CorrelateEventLogs(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+		eventName := getStringArg(args, "eventName", "")
+		podName := getStringArg(args, "podName", "")
+		reason := getStringArg(args, "reason", "")
+		secondsBefore := getIntArg(args, "secondsBefore", 60)
+		secondsAfter := getIntArg(args, "secondsAfter", 10)
+		maxEvents := getIntArg(args, "maxEvents", 5)
+		maxLines := getIntArg(args, "maxLines", 200)
+		if secondsBefore < 0 || secondsAfter < 0 || maxEvents < 0 || maxLines < 0 {
+			return nil, fmt.Errorf("invalid arguments: secondsBefore, secondsAfter, maxEvents and maxLines must not be negative")
+		}
+
+		result, err := client.CorrelateEventLogs(ctx, namespace, eventName, podName, reason,
+			time.Duration(secondsBefore)*time.Second, time.Duration(secondsAfter)*time.Second, maxEvents, maxLines)
+		if err != nil {
+			return nil, fmt.Errorf("failed to correlate events with logs: %w", err)
+		}
+		correlations, _ := result["correlations"].([]map[string]interface{})
+		matching, _ := result["matchingEvents"].(int)
+		if eventName != "" && matching == 0 {
+			return nil, fmt.Errorf("warning event '%s' for a pod not found in namespace %s", eventName, namespace)
+		}
+		setResultMetadata(ctx, "itemCount", len(correlations))
+		setResultMetadata(ctx, "truncated", matching > len(correlations))
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
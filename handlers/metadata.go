@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+)
+
+// resultMetadataKey is the context key for the metadata collected while a
+// tool call is handled.
+type resultMetadataKey struct{}
+
+// resultMetadata holds metadata fields reported by a handler, such as whether
+// its result was truncated.
+type resultMetadata struct {
+	mu     sync.Mutex
+	fields map[string]interface{}
+}
+
+// setResultMetadata records a metadata field for the current tool call. It is a
+// no-op when the handler runs without MetadataMiddleware.
+func setResultMetadata(ctx context.Context, key string, value interface{}) {
+	meta, ok := ctx.Value(resultMetadataKey{}).(*resultMetadata)
+	if !ok {
+		return
+	}
+	meta.mu.Lock()
+	meta.fields[key] = value
+	meta.mu.Unlock()
+}
+
+// MetadataMiddleware returns a middleware that attaches a metadata block to
+// every tool result. The block reports the cluster context, namespace scope,
+// item count, truncated flag, resourceVersion and elapsed time. It is added
+// both to the result's _meta field and as a trailing text content of the form
+// {"metadata": {...}}, leaving the primary content unchanged.
+func MetadataMiddleware(client *k8s.Client) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			meta := &resultMetadata{fields: map[string]interface{}{}}
+			ctx = context.WithValue(ctx, resultMetadataKey{}, meta)
+
+			start := time.Now()
+			result, err := next(ctx, request)
+			elapsed := time.Since(start)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			metadata := map[string]interface{}{
+				"tool":           request.Params.Name,
+				"context":        client.ContextName(),
+				"server":         client.ServerHost(),
+				"namespaceScope": namespaceScope(request),
+				"elapsedMs":      elapsed.Milliseconds(),
+				"truncated":      false,
+			}
+			if !result.IsError {
+				for key, value := range summarizeResultContent(result) {
+					metadata[key] = value
+				}
+			}
+			meta.mu.Lock()
+			for key, value := range meta.fields {
+				metadata[key] = value
+			}
+			meta.mu.Unlock()
+
+			if result.Meta == nil {
+				result.Meta = &mcp.Meta{}
+			}
+			if result.Meta.AdditionalFields == nil {
+				result.Meta.AdditionalFields = map[string]interface{}{}
+			}
+			result.Meta.AdditionalFields["metadata"] = metadata
+
+			jsonMetadata, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+			if err == nil {
+				result.Content = append(result.Content, mcp.NewTextContent(string(jsonMetadata)))
+			}
+			return result, nil
+		}
+	}
+}
+
+// namespaceScope describes the namespace a tool call was scoped to, or "all"
+// when no namespace or "*" was given.
+func namespaceScope(request mcp.CallToolRequest) string {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return "all"
+	}
+	if namespace := getStringArg(args, "namespace", ""); namespace != "" && namespace != "*" {
+		return namespace
+	}
+	return "all"
+}
+
+// summarizeResultContent derives the item count and resourceVersion from the
+// JSON text of a result. Arrays report their length, objects with an "items"
+// or "events" array report the length of that array, and single objects
+// report their metadata.resourceVersion.
+func summarizeResultContent(result *mcp.CallToolResult) map[string]interface{} {
+	summary := map[string]interface{}{}
+	if len(result.Content) == 0 {
+		return summary
+	}
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return summary
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal([]byte(textContent.Text), &payload); err != nil {
+		return summary
+	}
+
+	switch value := payload.(type) {
+	case []interface{}:
+		summary["itemCount"] = len(value)
+	case map[string]interface{}:
+		for _, key := range []string{"items", "events"} {
+			if items, ok := value[key].([]interface{}); ok {
+				summary["itemCount"] = len(items)
+				break
+			}
+		}
+		if metadata, ok := value["metadata"].(map[string]interface{}); ok {
+			if resourceVersion, ok := metadata["resourceVersion"].(string); ok && resourceVersion != "" {
+				summary["resourceVersion"] = resourceVersion
+			}
+		}
+	}
+	return summary
+}
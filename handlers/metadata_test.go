@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+)
+
+// TestMetadataMiddleware tests the metadata block attached to tool results
+func TestMetadataMiddleware(t *testing.T) {
+	handler := MetadataMiddleware(&k8s.Client{})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		setResultMetadata(ctx, "truncated", true)
+		return mcp.NewToolResultText(`[{"metadata":{"name":"a"}},{"metadata":{"name":"b"}}]`), nil
+	})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "listResources",
+			Arguments: map[string]interface{}{"namespace": "kube-system"},
+		},
+	}
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected result and metadata content, got %d items", len(result.Content))
+	}
+
+	var response map[string]map[string]interface{}
+	textContent := result.Content[1].(mcp.TextContent)
+	if err := json.Unmarshal([]byte(textContent.Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	metadata := response["metadata"]
+	if metadata["itemCount"] != float64(2) {
+		t.Errorf("Expected itemCount 2, got %v", metadata["itemCount"])
+	}
+	if metadata["namespaceScope"] != "kube-system" {
+		t.Errorf("Expected namespaceScope kube-system, got %v", metadata["namespaceScope"])
+	}
+	if metadata["truncated"] != true {
+		t.Errorf("Expected truncated to be true, got %v", metadata["truncated"])
+	}
+	if _, ok := metadata["elapsedMs"]; !ok {
+		t.Error("Expected elapsedMs in metadata")
+	}
+	if result.Meta == nil || result.Meta.AdditionalFields["metadata"] == nil {
+		t.Error("Expected metadata in result _meta")
+	}
+}
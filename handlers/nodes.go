@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetPodsOnNode returns a handler function for the getPodsOnNode tool.
+// It lists the pods on a node with their requests, QoS class and controllers.
+// The result is serialized to JSON and returned.
+func GetPodsOnNode(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		nodeName, err := getRequiredStringArg(args, "nodeName")
+		if err != nil {
+			return nil, err
+		}
+
+		report, err := client.GetPodsOnNode(ctx, nodeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pods on node: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetKubeletStats returns a handler function for the getKubeletStats tool.
+// It reads kubelet statistics and metrics for a node through the API server
+// proxy. The result is serialized to JSON and returned.
+func GetKubeletStats(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		nodeName, err := getRequiredStringArg(args, "nodeName")
+		if err != nil {
+			return nil, err
+		}
+		topPods := getIntArg(args, "topPods", 10)
+
+		stats, err := client.GetKubeletStats(ctx, nodeName, topPods)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kubelet stats: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(stats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetNodeLogs returns a handler function for the getNodeLogs tool.
+// It reads service logs or log files of a node through the API server's node
+// proxy. The result is serialized to JSON and returned.
+func GetNodeLogs(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		nodeName, err := getRequiredStringArg(args, "nodeName")
+		if err != nil {
+			return nil, err
+		}
+		opts := k8s.NodeLogOptions{
+			Query:        getStringArg(args, "query", ""),
+			Path:         getStringArg(args, "path", ""),
+			SinceSeconds: int64(getIntArg(args, "sinceSeconds", 0)),
+			TailLines:    getIntArg(args, "tailLines", 200),
+			Pattern:      getStringArg(args, "pattern", ""),
+		}
+		if opts.SinceSeconds < 0 || opts.TailLines < 0 {
+			return nil, fmt.Errorf("invalid arguments: sinceSeconds and tailLines must not be negative")
+		}
+
+		logs, err := client.GetNodeLogs(ctx, nodeName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get node logs: %w", err)
+		}
+		if lines, ok := logs["lines"].([]string); ok {
+			setResultMetadata(ctx, "itemCount", len(lines))
+			setResultMetadata(ctx, "truncated", logs["truncated"])
+		}
+
+		jsonResponse, err := json.Marshal(logs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetEvictionRisk returns a handler function for the getEvictionRisk tool.
+// It ranks pods per node by their memory-pressure eviction order.
+// The result is serialized to JSON and returned.
+func GetEvictionRisk(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		nodeName := getStringArg(args, "nodeName", "")
+		topN := getIntArg(args, "topN", 10)
+
+		report, err := client.GetEvictionRisk(ctx, nodeName, topN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get eviction risk: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetPortAllocations returns a handler function for the getPortAllocations
+// tool. It reports the NodePorts and hostPorts in use across the cluster and
+// their conflicts. The result is serialized to JSON and returned.
+func GetPortAllocations(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		nodePortRange := getStringArg(args, "nodePortRange", "")
+
+		report, err := client.GetPortAllocations(ctx, nodePortRange)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get port allocations: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"sigs.k8s.io/yaml"
+)
+
+// OutputFormatMiddleware returns a middleware that checks the outputFormat
+// argument of tools accepting one against the formats the tool offers, as
+// reported by toolParamEnum, and renders their results as YAML when it is
+// "yaml". Only the primary JSON content is converted; other text outputs and
+// error results are returned unchanged, and jsonPath templates, which print
+// text of their own, are refused. Other formats, such as "table", are left to
+// the tool's handler. The item count and resourceVersion of the JSON result
+// are recorded as result metadata first, as MetadataMiddleware cannot read
+// them from YAML.
+func OutputFormatMiddleware(toolParamEnum func(tool, param string) ([]string, bool)) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			formats, ok := toolParamEnum(request.Params.Name, "outputFormat")
+			if !ok {
+				return next(ctx, request)
+			}
+			args, _ := request.Params.Arguments.(map[string]interface{})
+			format := getStringArg(args, "outputFormat", "json")
+			if !slices.Contains(formats, format) {
+				return nil, fmt.Errorf("invalid argument outputFormat: must be one of %s, got %q", strings.Join(formats, ", "), format)
+			}
+			if format != "yaml" {
+				return next(ctx, request)
+			}
+			if getStringArg(args, "jsonPath", "") != "" {
+				return nil, fmt.Errorf("invalid arguments: outputFormat yaml cannot be combined with jsonPath")
+			}
+
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError || len(result.Content) == 0 {
+				return result, err
+			}
+			text, ok := result.Content[0].(mcp.TextContent)
+			if !ok || !json.Valid([]byte(text.Text)) {
+				return result, nil
+			}
+			for key, value := range summarizeResultContent(result) {
+				setResultMetadata(ctx, key, value)
+			}
+			output, err := jsonToYAML([]byte(text.Text))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert response to YAML: %w", err)
+			}
+			text.Text = output
+			result.Content[0] = text
+			return result, nil
+		}
+	}
+}
+
+// jsonToYAML converts a JSON result to YAML. A list of Kubernetes objects,
+// each with an apiVersion and kind, becomes a multi-document stream that
+// applyResource and kubectl apply accept as is; any other value is converted
+// as a single document.
+func jsonToYAML(data []byte) (string, error) {
+	var items []map[string]interface{}
+	if err := json.Unmarshal(data, &items); err == nil && len(items) > 0 {
+		documents := make([]string, 0, len(items))
+		for _, item := range items {
+			if item["apiVersion"] == nil || item["kind"] == nil {
+				documents = nil
+				break
+			}
+			document, err := yaml.Marshal(item)
+			if err != nil {
+				return "", err
+			}
+			documents = append(documents, string(document))
+		}
+		if documents != nil {
+			return strings.Join(documents, "---\n"), nil
+		}
+	}
+
+	output, err := yaml.JSONToYAML(data)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestOutputFormatMiddleware tests rendering JSON tool outputs as YAML on request
+func TestOutputFormatMiddleware(t *testing.T) {
+	output := `[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"a"}},{"apiVersion":"v1","kind":"Pod","metadata":{"name":"b"}}]`
+	toolParamEnum := func(tool, param string) ([]string, bool) {
+		switch tool {
+		case "listResources":
+			return []string{"json", "yaml", "table"}, true
+		case "getWorkloadManifest":
+			return []string{"json", "yaml"}, true
+		}
+		return nil, false
+	}
+	handler := OutputFormatMiddleware(toolParamEnum)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(output), nil
+	})
+
+	call := func(tool string, args map[string]interface{}) (string, error) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			return "", err
+		}
+		return resultText(result), nil
+	}
+
+	text, err := call("listResources", map[string]interface{}{"outputFormat": "yaml"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: b\n"
+	if text != expected {
+		t.Errorf("Expected a multi-document stream, got %q", text)
+	}
+
+	output = `{"items":[{"name":"a","replicas":3}],"continue":""}`
+	if text, _ := call("listResources", map[string]interface{}{"outputFormat": "yaml"}); text != "continue: \"\"\nitems:\n- name: a\n  replicas: 3\n" {
+		t.Errorf("Expected a single document, got %q", text)
+	}
+	if text, _ := call("listResources", map[string]interface{}{"outputFormat": "json"}); text != output {
+		t.Errorf("Expected JSON to be left alone, got %s", text)
+	}
+	if text, _ := call("getResource", map[string]interface{}{"outputFormat": "yaml"}); text != output {
+		t.Errorf("Expected tools without outputFormat to be left alone, got %s", text)
+	}
+
+	output = "a b\n"
+	if text, _ := call("listResources", map[string]interface{}{"outputFormat": "yaml"}); text != output {
+		t.Errorf("Expected text output to be left alone, got %q", text)
+	}
+	if _, err := call("listResources", map[string]interface{}{"outputFormat": "xml"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if text, _ := call("listResources", map[string]interface{}{"outputFormat": "table"}); text != output {
+		t.Errorf("Expected tables to be left to the handler, got %q", text)
+	}
+	if _, err := call("getWorkloadManifest", map[string]interface{}{"outputFormat": "table"}); err == nil {
+		t.Error("Expected an error for a format the tool does not offer")
+	}
+	if _, err := call("listResources", map[string]interface{}{"outputFormat": "yaml", "jsonPath": "{.items[*].metadata.name}"}); err == nil {
+		t.Error("Expected an error for yaml combined with jsonPath")
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ExecutePlan returns a handler function for the executePlan tool.
+// It runs an ordered list of mutating operations with per-step dry-run and
+// stop-on-error semantics. The transcript is serialized to JSON and returned.
+func ExecutePlan(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		rawSteps, ok := args["steps"].([]interface{})
+		if !ok || len(rawSteps) == 0 {
+			return nil, fmt.Errorf("missing required parameter: steps")
+		}
+		// Round-trip through JSON to decode the steps into their typed form
+		stepsJSON, err := json.Marshal(rawSteps)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument steps: %w", err)
+		}
+		var steps []k8s.PlanStep
+		if err := json.Unmarshal(stepsJSON, &steps); err != nil {
+			return nil, fmt.Errorf("invalid argument steps: %w", err)
+		}
+		dryRun := getBoolArg(args, "dryRun", false)
+		stopOnError := getBoolArg(args, "stopOnError", true)
+
+		transcript, err := client.ExecutePlan(ctx, steps, dryRun, stopOnError)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute plan: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(transcript)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
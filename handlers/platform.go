@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CheckPlatformCompatibility returns a handler function for the checkPlatformCompatibility tool.
+// It flags pods whose OS/architecture constraints or images do not match any
+// schedulable node. The result is serialized to JSON and returned.
+func CheckPlatformCompatibility(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		labelSelector := getStringArg(args, "labelSelector", "")
+		inspectImages := getBoolArg(args, "inspectImages", false)
+
+		report, err := client.CheckPlatformCompatibility(ctx, namespace, labelSelector, inspectImages)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check platform compatibility: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetPodEnvironment returns a handler function for the getPodEnvironment tool.
+// It resolves the effective environment of a pod's containers, redacting
+// Secret values. The result is serialized to JSON and returned.
+func GetPodEnvironment(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		podName, err := getRequiredStringArg(args, "podName")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		container := getStringArg(args, "container", "")
+
+		environment, err := client.GetPodEnvironment(ctx, namespace, podName, container)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod environment: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(environment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetPodVolumeMounts returns a handler function for the getPodVolumeMounts tool.
+// It resolves each volumeMount of a pod to its source and the files it
+// projects. The result is serialized to JSON and returned.
+func GetPodVolumeMounts(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		podName, err := getRequiredStringArg(args, "podName")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+
+		report, err := client.GetPodVolumeMounts(ctx, namespace, podName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod volume mounts: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetSecretUsage returns a handler function for the getSecretUsage tool.
+// It maps the pods, Ingresses, ServiceAccounts and CSI volumes referencing a
+// Secret. The result is serialized to JSON and returned.
+func GetSecretUsage(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+
+		usage, err := client.GetSecretUsage(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret usage: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(usage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetConfigMapUsage returns a handler function for the getConfigMapUsage tool.
+// It lists the workloads and bare pods consuming a ConfigMap. The result is
+// serialized to JSON and returned.
+func GetConfigMapUsage(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+
+		usage, err := client.GetConfigMapUsage(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get configmap usage: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(usage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// StartPortForward returns a handler function for the startPortForward tool.
+// It forwards a local port to a port of the pod behind the target for the
+// rest of the session. The result is serialized to JSON and returned.
+func StartPortForward(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		kind := getStringArg(args, "kind", "Pod")
+		remotePort := getIntArg(args, "remotePort", 0)
+		if remotePort == 0 {
+			return nil, fmt.Errorf("missing required parameter: remotePort")
+		}
+		localPort := getIntArg(args, "localPort", 0)
+
+		forward, err := client.StartPortForward(ctx, namespace, kind, name, remotePort, localPort)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start port forward to %s/%s: %w", kind, name, err)
+		}
+
+		jsonResponse, err := json.Marshal(forward)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// ListPortForwards returns a handler function for the listPortForwards tool.
+// It lists the port forwards of the session. The result is serialized to
+// JSON and returned.
+func ListPortForwards(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		forwards := client.ListPortForwards(ctx)
+		setResultMetadata(ctx, "itemCount", len(forwards))
+
+		jsonResponse, err := json.Marshal(forwards)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// StopPortForward returns a handler function for the stopPortForward tool.
+// It stops a port forward of the session. The result is serialized to JSON
+// and returned.
+func StopPortForward(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		id, err := getRequiredStringArg(args, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		forward, err := client.StopPortForward(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stop port forward: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(forward)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
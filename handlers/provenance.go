@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetWorkloadManifest returns a handler function for the getWorkloadManifest tool.
+// It returns the cleaned manifest of a resource with its Helm, Argo CD, Flux
+// and kubectl provenance. The result is serialized to JSON and returned.
+func GetWorkloadManifest(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		kind, err := getRequiredStringArg(args, "kind")
+		if err != nil {
+			return nil, err
+		}
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+
+		manifest, err := client.GetWorkloadManifest(ctx, kind, name, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workload manifest: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// queryName restricts saved query names to a portable set of characters.
+var queryName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 ._-]{0,99}$`)
+
+// SavedQuery is a named listResources query: a kind with its selectors and
+// projection.
+type SavedQuery struct {
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	Kind          string    `json:"kind"`
+	Namespace     string    `json:"namespace,omitempty"`
+	LabelSelector string    `json:"labelSelector,omitempty"`
+	FieldSelector string    `json:"fieldSelector,omitempty"`
+	FieldPaths    string    `json:"fieldPaths,omitempty"`
+	ExcludeFields string    `json:"excludeFields,omitempty"`
+	AsTable       bool      `json:"asTable,omitempty"`
+	JSONPath      string    `json:"jsonPath,omitempty"`
+	SavedAt       time.Time `json:"savedAt"`
+}
+
+// arguments returns the listResources arguments of the query.
+func (q SavedQuery) arguments() map[string]interface{} {
+	args := map[string]interface{}{"Kind": q.Kind}
+	for key, value := range map[string]string{
+		"namespace":     q.Namespace,
+		"labelSelector": q.LabelSelector,
+		"fieldSelector": q.FieldSelector,
+		"fieldPaths":    q.FieldPaths,
+		"excludeFields": q.ExcludeFields,
+		"jsonPath":      q.JSONPath,
+	} {
+		if value != "" {
+			args[key] = value
+		}
+	}
+	if q.AsTable {
+		args["asTable"] = true
+	}
+	return args
+}
+
+// queriesBucket is the bucket of the state store holding the saved queries,
+// keyed by name.
+const queriesBucket = "queries"
+
+// QueryStore holds the saved queries shared by all sessions, optionally
+// persisted to a JSON file or to the state store.
+type QueryStore struct {
+	mu      sync.RWMutex
+	path    string
+	state   k8s.StateStore
+	queries map[string]SavedQuery
+}
+
+// NewQueryStore creates a QueryStore. If path is set, queries are loaded from
+// and saved to that file; otherwise they are kept in memory only.
+// Returns the store, or an error if the file exists but cannot be read.
+func NewQueryStore(path string) (*QueryStore, error) {
+	store := &QueryStore{path: path, queries: map[string]SavedQuery{}}
+	if path == "" {
+		return store, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved queries: %w", err)
+	}
+	var queries []SavedQuery
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, fmt.Errorf("failed to parse saved queries: %w", err)
+	}
+	for _, query := range queries {
+		store.queries[query.Name] = query
+	}
+	return store, nil
+}
+
+// PersistTo keeps the queries in the state store instead of the JSON file,
+// adding those saved there by a previous run of the server.
+// Returns an error if the persisted queries cannot be read.
+func (s *QueryStore) PersistTo(state k8s.StateStore) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := state.ForEach(queriesBucket, func(name string, value []byte) error {
+		var query SavedQuery
+		if err := json.Unmarshal(value, &query); err != nil {
+			return fmt.Errorf("failed to parse saved query %s: %w", name, err)
+		}
+		s.queries[query.Name] = query
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.state = state
+	return nil
+}
+
+// list returns the saved queries sorted by name.
+func (s *QueryStore) list() []SavedQuery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	queries := make([]SavedQuery, 0, len(s.queries))
+	for _, query := range s.queries {
+		queries = append(queries, query)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	return queries
+}
+
+// get returns the saved query with the given name.
+func (s *QueryStore) get(name string) (SavedQuery, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	query, ok := s.queries[name]
+	return query, ok
+}
+
+// put saves or replaces a query and persists the store.
+func (s *QueryStore) put(query SavedQuery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.queries[query.Name]
+	s.queries[query.Name] = query
+	if err := s.persist(query.Name); err != nil {
+		if existed {
+			s.queries[query.Name] = previous
+		} else {
+			delete(s.queries, query.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// remove deletes a query and persists the store. Returns false if no query
+// has the given name.
+func (s *QueryStore) remove(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query, ok := s.queries[name]
+	if !ok {
+		return false, nil
+	}
+	delete(s.queries, name)
+	if err := s.persist(name); err != nil {
+		s.queries[name] = query
+		return false, err
+	}
+	return true, nil
+}
+
+// persist saves the change to the named query to the state store, or writes
+// all queries to the store's file, replacing it atomically. The caller must
+// hold the write lock.
+func (s *QueryStore) persist(name string) error {
+	if s.state != nil {
+		if query, ok := s.queries[name]; ok {
+			return s.state.Put(queriesBucket, name, query)
+		}
+		return s.state.Delete(queriesBucket, name)
+	}
+	if s.path == "" {
+		return nil
+	}
+	queries := make([]SavedQuery, 0, len(s.queries))
+	for _, query := range s.queries {
+		queries = append(queries, query)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	data, err := json.MarshalIndent(queries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize saved queries: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".saved-queries-*")
+	if err != nil {
+		return fmt.Errorf("failed to write saved queries: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write saved queries: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write saved queries: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write saved queries: %w", err)
+	}
+	return nil
+}
+
+// SaveQuery returns a handler function for the saveQuery tool.
+// It saves a named listResources query that any session can run later.
+// The saved query is serialized to JSON and returned.
+func SaveQuery(store *QueryStore) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		if !queryName.MatchString(name) {
+			return nil, fmt.Errorf("invalid argument name: must be 1-100 letters, digits, spaces, '.', '_' or '-'")
+		}
+		kind, err := getRequiredStringArg(args, "kind")
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := jsonPathArg(args); err != nil {
+			return nil, err
+		}
+
+		query := SavedQuery{
+			Name:          name,
+			Description:   getStringArg(args, "description", ""),
+			Kind:          kind,
+			Namespace:     getStringArg(args, "namespace", ""),
+			LabelSelector: getStringArg(args, "labelSelector", ""),
+			FieldSelector: getStringArg(args, "fieldSelector", ""),
+			FieldPaths:    getStringArg(args, "fieldPaths", ""),
+			ExcludeFields: strings.Join(excludeFieldPathsArg(args), ","),
+			AsTable:       getBoolArg(args, "asTable", false),
+			JSONPath:      getStringArg(args, "jsonPath", ""),
+			SavedAt:       time.Now().UTC(),
+		}
+		if err := store.put(query); err != nil {
+			return nil, fmt.Errorf("failed to save query: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// RunQuery returns a handler function for the runQuery tool.
+// It runs a saved query through listResources, optionally overriding its
+// namespace. The listed resources are serialized to JSON and returned.
+func RunQuery(client *k8s.Client, store *QueryStore) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	list := ListResources(client)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		query, ok := store.get(name)
+		if !ok {
+			return nil, fmt.Errorf("saved query %s not found; use listSavedQueries to see the available queries", name)
+		}
+
+		queryArgs := query.arguments()
+		if namespace, ok := args["namespace"].(string); ok {
+			queryArgs["namespace"] = namespace
+		}
+		for _, key := range []string{"confirmLargeScan", "outputFormat"} {
+			if value, ok := args[key]; ok {
+				queryArgs[key] = value
+			}
+		}
+		listRequest := request
+		listRequest.Params.Arguments = queryArgs
+		return list(ctx, listRequest)
+	}
+}
+
+// ListSavedQueries returns a handler function for the listSavedQueries tool.
+// It lists the saved queries. The result is serialized to JSON and returned.
+func ListSavedQueries(store *QueryStore) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		jsonResponse, err := json.Marshal(map[string]interface{}{"queries": store.list()})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// DeleteSavedQuery returns a handler function for the deleteSavedQuery tool.
+// It removes a saved query by name.
+func DeleteSavedQuery(store *QueryStore) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		removed, err := store.remove(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete saved query: %w", err)
+		}
+		if !removed {
+			return nil, fmt.Errorf("saved query %s not found", name)
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Saved query %s deleted", name)), nil
+	}
+}
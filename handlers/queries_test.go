@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestQueryStorePersistence tests that saved queries survive reloading the store from its file
+func TestQueryStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.json")
+	store, err := NewQueryStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"name":          "prod payment pods brief",
+		"kind":          "Pod",
+		"namespace":     "prod",
+		"labelSelector": "app=payment",
+		"fieldPaths":    "metadata.name,status.phase",
+	}
+	if _, err := SaveQuery(store)(context.Background(), request); err != nil {
+		t.Fatalf("Failed to save query: %v", err)
+	}
+
+	reloaded, err := NewQueryStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reload store: %v", err)
+	}
+	query, ok := reloaded.get("prod payment pods brief")
+	if !ok {
+		t.Fatal("Expected the saved query after reloading")
+	}
+	args := query.arguments()
+	if args["Kind"] != "Pod" || args["namespace"] != "prod" || args["labelSelector"] != "app=payment" {
+		t.Errorf("Unexpected listResources arguments: %v", args)
+	}
+	if _, ok := args["fieldSelector"]; ok {
+		t.Error("Expected unset fields to be omitted from the arguments")
+	}
+
+	request.Params.Arguments = map[string]interface{}{"name": "prod payment pods brief"}
+	if _, err := DeleteSavedQuery(reloaded)(context.Background(), request); err != nil {
+		t.Fatalf("Failed to delete query: %v", err)
+	}
+	if _, err := DeleteSavedQuery(reloaded)(context.Background(), request); err == nil {
+		t.Error("Expected an error deleting a missing query")
+	}
+	if again, _ := NewQueryStore(path); len(again.list()) != 0 {
+		t.Errorf("Expected no queries after deleting, got %v", again.list())
+	}
+}
+
+// TestSaveQueryRejectsInvalidName tests that query names are validated
+func TestSaveQueryRejectsInvalidName(t *testing.T) {
+	store, _ := NewQueryStore("")
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"name": "../etc", "kind": "Pod"}
+	if _, err := SaveQuery(store)(context.Background(), request); err == nil {
+		t.Error("Expected an error for an invalid name")
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// CompareRoles returns a handler function for the compareRoles tool.
+// It diffs the permissions of a role against another role or a requested
+// rule set. The result is serialized to JSON and returned.
+func CompareRoles(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "role")
+		if err != nil {
+			return nil, err
+		}
+		role := k8s.RoleReference{
+			Kind:      getStringArg(args, "kind", ""),
+			Namespace: getStringArg(args, "namespace", ""),
+			Name:      name,
+		}
+
+		var other *k8s.RoleReference
+		if compareTo := getStringArg(args, "compareTo", ""); compareTo != "" {
+			other = &k8s.RoleReference{
+				Kind:      getStringArg(args, "compareToKind", ""),
+				Namespace: getStringArg(args, "compareToNamespace", ""),
+				Name:      compareTo,
+			}
+		}
+		var rules []rbacv1.PolicyRule
+		if rawRules, ok := args["rules"].([]interface{}); ok && len(rawRules) > 0 {
+			// Round-trip through JSON to decode the rules into their typed form
+			rulesJSON, err := json.Marshal(rawRules)
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument rules: %w", err)
+			}
+			if err := json.Unmarshal(rulesJSON, &rules); err != nil {
+				return nil, fmt.Errorf("invalid argument rules: %w", err)
+			}
+		}
+		if (other == nil) == (len(rules) == 0) {
+			return nil, fmt.Errorf("invalid arguments: exactly one of compareTo or rules must be given")
+		}
+
+		diff, err := client.CompareRoles(ctx, role, other, rules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare roles: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(diff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// CanI returns a handler function for the canI tool.
+// It checks whether the client's identity, or the user it impersonates, may
+// perform an action. The result is serialized to JSON and returned.
+func CanI(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		access, err := accessRequestArgs(args)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.CanI(ctx, access)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check access: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// WhoCan returns a handler function for the whoCan tool.
+// It lists the roles, bindings and subjects RBAC allows to perform an action.
+// The result is serialized to JSON and returned.
+func WhoCan(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		access, err := accessRequestArgs(args)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.WhoCan(ctx, access)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find who can %s: %w", access.Verb, err)
+		}
+		if subjects, ok := result["subjects"].([]map[string]interface{}); ok {
+			setResultMetadata(ctx, "itemCount", len(subjects))
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// accessRequestArgs reads the action the canI and whoCan tools check from
+// their arguments.
+func accessRequestArgs(args map[string]interface{}) (k8s.AccessRequest, error) {
+	verb, err := getRequiredStringArg(args, "verb")
+	if err != nil {
+		return k8s.AccessRequest{}, err
+	}
+	access := k8s.AccessRequest{
+		Verb:        verb,
+		Group:       getStringArg(args, "group", ""),
+		Resource:    getStringArg(args, "resource", ""),
+		Subresource: getStringArg(args, "subresource", ""),
+		Name:        getStringArg(args, "name", ""),
+		Namespace:   getStringArg(args, "namespace", ""),
+		Path:        getStringArg(args, "nonResourceURL", ""),
+	}
+	if (access.Resource == "") == (access.Path == "") {
+		return k8s.AccessRequest{}, fmt.Errorf("invalid arguments: exactly one of resource or nonResourceURL must be given")
+	}
+	return access, nil
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// FindRestartStorms returns a handler function for the findRestartStorms tool.
+// It ranks the containers whose restart count increased within a recent window.
+// The result is serialized to JSON and returned.
+func FindRestartStorms(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		windowMinutes := getIntArg(args, "windowMinutes", 60)
+		sampleSeconds := getIntArg(args, "sampleSeconds", 0)
+		topN := getIntArg(args, "topN", 10)
+		if warning, err := largeScanWarning(ctx, client, args, "Pod", namespace, "", ""); warning != nil || err != nil {
+			return warning, err
+		}
+
+		report, err := client.FindRestartStorms(ctx, namespace, windowMinutes, sampleSeconds, topN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find restart storms: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// ListFailures returns a handler function for the listFailures tool.
+// It lists a page of the pods that are not running or done, grouped by
+// namespace and owner. The result is serialized to JSON and returned.
+func ListFailures(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		limit := getIntArg(args, "limit", k8s.DefaultFailuresLimit)
+		if limit <= 0 {
+			return nil, fmt.Errorf("invalid argument limit: must be positive")
+		}
+		offset := getIntArg(args, "offset", 0)
+		if offset < 0 {
+			return nil, fmt.Errorf("invalid argument offset: must not be negative")
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		if warning, err := largeScanWarning(ctx, client, args, "Pod", namespace, "", ""); warning != nil || err != nil {
+			return warning, err
+		}
+
+		report, err := client.ListFailures(ctx, namespace, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list failures: %w", err)
+		}
+		if total, ok := report["total"].(int); ok {
+			setResultMetadata(ctx, "itemCount", total)
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// DiagnoseInitContainers returns a handler function for the diagnoseInitContainers tool.
+// It analyzes the failing init containers of the named pod, or of every pod
+// of the namespace stuck initializing. The result is serialized to JSON and
+// returned.
+func DiagnoseInitContainers(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		tailLines := getIntArg(args, "tailLines", 50)
+		if tailLines < 0 {
+			return nil, fmt.Errorf("invalid argument tailLines: must not be negative")
+		}
+
+		report, err := client.DiagnoseInitContainers(ctx, getStringArg(args, "namespace", ""), getStringArg(args, "podName", ""),
+			getStringArg(args, "labelSelector", ""), int64(tailLines))
+		if err != nil {
+			return nil, fmt.Errorf("failed to diagnose init containers: %w", err)
+		}
+		if pods, ok := report["pods"].([]map[string]interface{}); ok {
+			setResultMetadata(ctx, "itemCount", len(pods))
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetPodStartupBreakdown returns a handler function for the
+// getPodStartupBreakdown tool.
+// It splits the start of a workload's recent pods into phases. The result is
+// serialized to JSON and returned.
+func GetPodStartupBreakdown(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name := getStringArg(args, "name", "")
+		labelSelector := getStringArg(args, "labelSelector", "")
+		if (name == "") == (labelSelector == "") {
+			return nil, fmt.Errorf("invalid arguments: exactly one of name or labelSelector must be given")
+		}
+		maxPods := getIntArg(args, "maxPods", 10)
+		if maxPods <= 0 {
+			return nil, fmt.Errorf("invalid argument maxPods: must be positive")
+		}
+
+		report, err := client.GetPodStartupBreakdown(ctx, getStringArg(args, "namespace", ""), getStringArg(args, "kind", ""),
+			name, labelSelector, maxPods)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod startup breakdown: %w", err)
+		}
+		if pods, ok := report["pods"].([]map[string]interface{}); ok {
+			setResultMetadata(ctx, "itemCount", len(pods))
+		}
+
+		jsonResponse, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
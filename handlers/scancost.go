@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+)
+
+// largeScanWarning estimates the cost of listing kind across namespaces
+// before a tool makes the list. A list returning more objects than the
+// server's large scan threshold is not made unless the call sets
+// confirmLargeScan; a warning with the estimate is returned in place of the
+// tool's output instead, sparing both the cluster and the caller's context.
+// Estimates that fail let the scan proceed, as the list reports the error.
+// Returns the warning, or nil if the scan may proceed.
+func largeScanWarning(ctx context.Context, client *k8s.Client, args map[string]interface{}, kind, namespace, labelSelector, fieldSelector string) (*mcp.CallToolResult, error) {
+	if getBoolArg(args, "confirmLargeScan", false) {
+		return nil, nil
+	}
+	estimate, err := client.LargeScan(ctx, kind, namespace, labelSelector, fieldSelector)
+	if err != nil {
+		fmt.Printf("[LargeScan] Failed to estimate %s: %v\n", kind, err)
+		return nil, nil
+	}
+	if estimate == nil {
+		return nil, nil
+	}
+
+	about := "about "
+	if estimate.Exact {
+		about = ""
+	} else if estimate.UpperBound {
+		about = "up to "
+	}
+	calls := fmt.Sprintf("%d API calls", estimate.APICalls)
+	if estimate.APICalls == 1 {
+		calls = "1 API call"
+	}
+	jsonResponse, err := json.Marshal(map[string]interface{}{
+		"warning": fmt.Sprintf("This would list %s%d %s objects across namespaces in %s, more than the threshold of %d. "+
+			"Narrow it to a namespace or selector, or call again with confirmLargeScan set to true.",
+			about, estimate.Objects, kind, calls, estimate.Threshold),
+		"estimate":                 estimate,
+		"confirmLargeScanRequired": true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize response: %w", err)
+	}
+	setResultMetadata(ctx, "confirmLargeScanRequired", true)
+	return mcp.NewToolResultText(string(jsonResponse)), nil
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ExplainScheduling returns a handler function for the explainScheduling tool.
+// It simulates the scheduler's filters for a pod or a workload's pod template
+// against the live nodes. The result is serialized to JSON and returned.
+func ExplainScheduling(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		kind := getStringArg(args, "kind", "Pod")
+		namespace := getStringArg(args, "namespace", "")
+
+		result, err := client.ExplainScheduling(ctx, namespace, kind, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain scheduling: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetServerInfo returns a handler function for the getServerInfo tool.
+// It reports how the client connects to the cluster together with the given
+// server settings. The result is serialized to JSON and returned.
+func GetServerInfo(client *k8s.Client, settings map[string]interface{}) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		info := client.GetServerInfo(ctx)
+		info["settings"] = settings
+
+		jsonResponse, err := json.Marshal(info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// CacheStats returns a handler function for the cacheStats tool.
+// It reports the resource cache of the client. The result is serialized to
+// JSON and returned.
+func CacheStats(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		jsonResponse, err := json.Marshal(client.CacheStats())
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+)
+
+// SessionMiddleware attributes each tool call to the MCP session it was made
+// in, and to the authenticated client making it, so that per-session state
+// such as the undo log is kept apart between clients.
+func SessionMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			ctx = k8s.WithSessionID(ctx, session.SessionID())
+		}
+		if client, ok := policy.ClientFromContext(ctx); ok {
+			ctx = k8s.WithCaller(ctx, client.Name)
+		}
+		return next(ctx, request)
+	}
+}
+
+// sessionKey returns the ID of the MCP session of ctx, or an empty string
+// outside of a session.
+func sessionKey(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// defaultsKey returns the keys of the defaults pinned by the session of ctx:
+// the ID of its MCP session, or an empty string outside of a session, and the
+// name of its authenticated client, if any. Clients choose their own session
+// IDs in stateless mode, so keying by client keeps them from sharing defaults.
+func defaultsKey(ctx context.Context) (string, string) {
+	session := sessionKey(ctx)
+	if session == "" {
+		return "", ""
+	}
+	if client, ok := policy.ClientFromContext(ctx); ok {
+		return session, client.Name
+	}
+	return session, ""
+}
+
+// SessionDefaults are the arguments pinned for the rest of an MCP session.
+type SessionDefaults struct {
+	Context                   string   `json:"context,omitempty"`
+	Namespace                 string   `json:"namespace,omitempty"`
+	LabelSelector             string   `json:"labelSelector,omitempty"`
+	ImpersonateUser           string   `json:"impersonateUser,omitempty"`
+	ImpersonateServiceAccount string   `json:"impersonateServiceAccount,omitempty"`
+	ImpersonateGroups         []string `json:"impersonateGroups,omitempty"`
+}
+
+// empty reports whether no argument is pinned.
+func (d SessionDefaults) empty() bool {
+	return d.Context == "" && d.Namespace == "" && d.LabelSelector == "" && !d.impersonating()
+}
+
+// impersonating reports whether an identity to impersonate is pinned.
+func (d SessionDefaults) impersonating() bool {
+	return d.ImpersonateUser != "" || d.ImpersonateServiceAccount != ""
+}
+
+// SessionStore holds the defaults pinned by each MCP session, per
+// authenticated client.
+type SessionStore struct {
+	mu       sync.RWMutex
+	defaults map[string]map[string]SessionDefaults
+}
+
+// NewSessionStore creates an empty SessionStore.
+func NewSessionStore() *SessionStore {
+	return &SessionStore{defaults: map[string]map[string]SessionDefaults{}}
+}
+
+// Get returns the defaults pinned by the session of ctx, or none outside of a
+// session.
+func (s *SessionStore) Get(ctx context.Context) SessionDefaults {
+	session, client := defaultsKey(ctx)
+	if session == "" {
+		return SessionDefaults{}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.defaults[session][client]
+}
+
+// Set replaces the defaults pinned by the session of ctx.
+// Returns an error outside of a session, where there is no session to pin them
+// for.
+func (s *SessionStore) Set(ctx context.Context, defaults SessionDefaults) error {
+	session, client := defaultsKey(ctx)
+	if session == "" {
+		return fmt.Errorf("session defaults can only be pinned within an MCP session; send an Mcp-Session-Id header")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if defaults.empty() {
+		delete(s.defaults[session], client)
+		if len(s.defaults[session]) == 0 {
+			delete(s.defaults, session)
+		}
+		return nil
+	}
+	if s.defaults[session] == nil {
+		s.defaults[session] = map[string]SessionDefaults{}
+	}
+	s.defaults[session][client] = defaults
+	return nil
+}
+
+// Delete removes the defaults pinned in the MCP session with the given ID, by
+// any client, once the session has ended.
+func (s *SessionStore) Delete(session string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.defaults, session)
+}
+
+// SessionDefaultsMiddleware returns a middleware that fills in the context,
+// namespace and labelSelector pinned by the session for tools that accept them, as
+// reported by toolHasParam, when the call omits them. Arguments given
+// explicitly, even as empty strings, are left unchanged. The steps of
+// executePlan inherit the pinned namespace as well. The pinned identity to
+// impersonate is filled in as a whole, unless the call names a user or service
+// account of its own.
+func SessionDefaultsMiddleware(store *SessionStore, toolHasParam func(tool, param string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			defaults := store.Get(ctx)
+			if defaults.empty() {
+				return next(ctx, request)
+			}
+			args, ok := request.Params.Arguments.(map[string]interface{})
+			if !ok && request.Params.Arguments != nil {
+				return next(ctx, request)
+			}
+
+			// Copy the arguments so the defaults do not leak into the caller's request
+			filled := make(map[string]interface{}, len(args)+6)
+			for key, value := range args {
+				filled[key] = value
+			}
+			tool := request.Params.Name
+			pinned := map[string]string{"context": defaults.Context, "namespace": defaults.Namespace, "labelSelector": defaults.LabelSelector}
+			for param, value := range pinned {
+				if _, set := filled[param]; !set && value != "" && toolHasParam(tool, param) {
+					filled[param] = value
+				}
+			}
+			_, userSet := filled["impersonateUser"]
+			_, serviceAccountSet := filled["impersonateServiceAccount"]
+			if defaults.impersonating() && !userSet && !serviceAccountSet && toolHasParam(tool, "impersonateUser") {
+				if defaults.ImpersonateUser != "" {
+					filled["impersonateUser"] = defaults.ImpersonateUser
+				} else {
+					filled["impersonateServiceAccount"] = defaults.ImpersonateServiceAccount
+				}
+				if _, set := filled["impersonateGroups"]; !set && len(defaults.ImpersonateGroups) > 0 {
+					filled["impersonateGroups"] = defaults.ImpersonateGroups
+				}
+			}
+			if steps, ok := filled["steps"].([]interface{}); ok && defaults.Namespace != "" {
+				filledSteps := make([]interface{}, len(steps))
+				for i, raw := range steps {
+					filledSteps[i] = raw
+					step, ok := raw.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					if _, set := step["namespace"]; !set {
+						filledStep := map[string]interface{}{"namespace": defaults.Namespace}
+						for key, value := range step {
+							filledStep[key] = value
+						}
+						filledSteps[i] = filledStep
+					}
+				}
+				filled["steps"] = filledSteps
+			}
+
+			request.Params.Arguments = filled
+			return next(ctx, request)
+		}
+	}
+}
+
+// SetSessionDefaults returns a handler function for the setSessionDefaults tool.
+// It pins a default context, namespace, labelSelector and identity to
+// impersonate for the rest of the session. Pinning a user unpins a service
+// account and vice versa. The resulting defaults are serialized to JSON and returned.
+func SetSessionDefaults(pool *k8s.ClientPool, store *SessionStore) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		defaults := store.Get(ctx)
+		if getBoolArg(args, "clear", false) {
+			defaults = SessionDefaults{}
+		}
+		if contextName, ok := args["context"].(string); ok {
+			if contextName != "" {
+				if _, err := pool.Get(contextName); err != nil {
+					return nil, fmt.Errorf("invalid argument context: %w", err)
+				}
+			}
+			defaults.Context = contextName
+		}
+		if namespace, ok := args["namespace"].(string); ok {
+			defaults.Namespace = namespace
+		}
+		if labelSelector, ok := args["labelSelector"].(string); ok {
+			defaults.LabelSelector = labelSelector
+		}
+		user, userSet := args["impersonateUser"].(string)
+		serviceAccount, serviceAccountSet := args["impersonateServiceAccount"].(string)
+		switch {
+		case user != "" && serviceAccount != "":
+			return nil, fmt.Errorf("invalid argument impersonateServiceAccount: cannot be combined with impersonateUser")
+		case serviceAccount != "":
+			if _, err := k8s.ServiceAccountUser(serviceAccount); err != nil {
+				return nil, fmt.Errorf("invalid argument impersonateServiceAccount: %w", err)
+			}
+			defaults.ImpersonateUser, defaults.ImpersonateServiceAccount = "", serviceAccount
+		case user != "":
+			defaults.ImpersonateUser, defaults.ImpersonateServiceAccount = user, ""
+		default:
+			if userSet {
+				defaults.ImpersonateUser = ""
+			}
+			if serviceAccountSet {
+				defaults.ImpersonateServiceAccount = ""
+			}
+		}
+		if _, ok := args["impersonateGroups"]; ok {
+			groups, err := getStringListArg(args, "impersonateGroups")
+			if err != nil {
+				return nil, err
+			}
+			defaults.ImpersonateGroups = groups
+		}
+		if len(defaults.ImpersonateGroups) > 0 && !defaults.impersonating() {
+			return nil, fmt.Errorf("invalid argument impersonateGroups: requires impersonateUser or impersonateServiceAccount")
+		}
+		if err := store.Set(ctx, defaults); err != nil {
+			return nil, err
+		}
+
+		jsonResponse, err := json.Marshal(map[string]interface{}{"defaults": defaults})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+)
+
+// testSession is an MCP session with a fixed ID.
+type testSession string
+
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                   { return string(s) }
+
+// withTestSession returns a context in the MCP session with the given ID.
+func withTestSession(ctx context.Context, id string) context.Context {
+	return server.NewMCPServer("test", "1.0.0").WithContext(ctx, testSession(id))
+}
+
+// TestSessionDefaultsMiddleware tests filling pinned arguments into tool calls that omit them
+func TestSessionDefaultsMiddleware(t *testing.T) {
+	store := NewSessionStore()
+	ctx := withTestSession(context.Background(), "s")
+	if err := store.Set(ctx, SessionDefaults{Namespace: "team-a", LabelSelector: "app=web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	params := map[string]bool{"namespace": true, "labelSelector": true}
+	toolHasParam := func(tool, param string) bool { return tool == "listResources" && params[param] }
+
+	var got map[string]interface{}
+	handler := SessionDefaultsMiddleware(store, toolHasParam)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		got, _ = request.Params.Arguments.(map[string]interface{})
+		return nil, nil
+	})
+
+	call := func(tool string, args map[string]interface{}) map[string]interface{} {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool
+		request.Params.Arguments = args
+		_, _ = handler(ctx, request)
+		return got
+	}
+
+	args := map[string]interface{}{"kind": "Pod"}
+	filled := call("listResources", args)
+	if filled["namespace"] != "team-a" || filled["labelSelector"] != "app=web" {
+		t.Errorf("Expected pinned namespace and labelSelector, got %v", filled)
+	}
+	if _, ok := args["namespace"]; ok {
+		t.Error("Expected the original arguments to be left unchanged")
+	}
+
+	filled = call("listResources", map[string]interface{}{"kind": "Pod", "namespace": ""})
+	if filled["namespace"] != "" {
+		t.Errorf("Expected an explicit empty namespace to be kept, got %v", filled["namespace"])
+	}
+
+	filled = call("getNodeMetrics", map[string]interface{}{})
+	if _, ok := filled["namespace"]; ok {
+		t.Errorf("Expected no namespace for a tool without a namespace parameter, got %v", filled)
+	}
+
+	filled = call("executePlan", map[string]interface{}{"steps": []interface{}{
+		map[string]interface{}{"operation": "scale"},
+		map[string]interface{}{"operation": "scale", "namespace": "team-b"},
+	}})
+	steps := filled["steps"].([]interface{})
+	if steps[0].(map[string]interface{})["namespace"] != "team-a" || steps[1].(map[string]interface{})["namespace"] != "team-b" {
+		t.Errorf("Expected plan steps to inherit the pinned namespace only when unset, got %v", steps)
+	}
+
+	if err := store.Set(ctx, SessionDefaults{ImpersonateServiceAccount: "web/deployer", ImpersonateGroups: []string{"devs"}}); err != nil {
+		t.Fatal(err)
+	}
+	params["impersonateUser"] = true
+	filled = call("listResources", map[string]interface{}{})
+	if filled["impersonateServiceAccount"] != "web/deployer" || len(filled["impersonateGroups"].([]string)) != 1 {
+		t.Errorf("Expected the pinned identity to be filled in, got %v", filled)
+	}
+	filled = call("listResources", map[string]interface{}{"impersonateUser": "jane"})
+	if _, ok := filled["impersonateServiceAccount"]; ok || filled["impersonateGroups"] != nil {
+		t.Errorf("Expected an explicit user to replace the pinned identity, got %v", filled)
+	}
+}
+
+// TestSessionStoreKeys tests that defaults are kept per session and client, and refused outside of a session
+func TestSessionStoreKeys(t *testing.T) {
+	store := NewSessionStore()
+	if err := store.Set(context.Background(), SessionDefaults{Namespace: "team-a"}); err == nil {
+		t.Error("Expected defaults to be refused outside of a session")
+	}
+
+	alice := withTestSession(policy.WithClient(context.Background(), &policy.ClientPolicy{Name: "alice"}), "s")
+	bob := withTestSession(policy.WithClient(context.Background(), &policy.ClientPolicy{Name: "bob"}), "s")
+	if err := store.Set(alice, SessionDefaults{Namespace: "team-a"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Get(alice); got.Namespace != "team-a" {
+		t.Errorf("Expected alice's defaults, got %v", got)
+	}
+	if got := store.Get(bob); !got.empty() {
+		t.Errorf("Expected bob not to share alice's session ID defaults, got %v", got)
+	}
+	if got := store.Get(context.Background()); !got.empty() {
+		t.Errorf("Expected no defaults outside of a session, got %v", got)
+	}
+}
+
+// TestSessionStoreDelete tests removing the defaults every client pinned in a session once it ends
+func TestSessionStoreDelete(t *testing.T) {
+	store := NewSessionStore()
+	alice := policy.WithClient(context.Background(), &policy.ClientPolicy{Name: "alice"})
+	bob := policy.WithClient(context.Background(), &policy.ClientPolicy{Name: "bob"})
+	for _, ctx := range []context.Context{withTestSession(alice, "s"), withTestSession(bob, "s"), withTestSession(alice, "t")} {
+		if err := store.Set(ctx, SessionDefaults{Namespace: "team-a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store.Delete("s")
+	if got := store.Get(withTestSession(alice, "s")); !got.empty() {
+		t.Errorf("Expected alice's defaults of the ended session to be removed, got %v", got)
+	}
+	if got := store.Get(withTestSession(bob, "s")); !got.empty() {
+		t.Errorf("Expected bob's defaults of the ended session to be removed, got %v", got)
+	}
+	if got := store.Get(withTestSession(alice, "t")); got.Namespace != "team-a" {
+		t.Errorf("Expected the defaults of another session to be kept, got %v", got)
+	}
+	if len(store.defaults) != 1 {
+		t.Errorf("Expected only the remaining session to be stored, got %v", store.defaults)
+	}
+}
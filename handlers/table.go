@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownCellReplacer escapes the characters that would break a markdown
+// table cell.
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+// renderMarkdownTable renders a table of columns and keyed rows, as returned
+// by ListResourcesTable and SynthesizeTable, as a markdown table with
+// kubectl get's upper-case headers. Columns kubectl only prints with -o wide
+// are left out, and a NAMESPACE column comes first when rows report their
+// namespace, as with kubectl get --all-namespaces.
+func renderMarkdownTable(table map[string]interface{}) string {
+	columns, _ := table["columns"].([]map[string]interface{})
+	rows, _ := table["rows"].([]map[string]interface{})
+	if len(rows) == 0 {
+		return "No resources found.\n"
+	}
+
+	var names []string
+	for _, row := range rows {
+		if _, ok := row["namespace"]; ok {
+			names = append(names, "namespace")
+			break
+		}
+	}
+	for _, column := range columns {
+		if priority, ok := column["priority"].(int32); ok && priority > 0 {
+			continue
+		}
+		if name, ok := column["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+
+	var out strings.Builder
+	headers := make([]string, len(names))
+	separators := make([]string, len(names))
+	for i, name := range names {
+		headers[i] = markdownCellReplacer.Replace(strings.ToUpper(name))
+		separators[i] = "---"
+	}
+	out.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	out.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+	for _, row := range rows {
+		cells := make([]string, len(names))
+		for i, name := range names {
+			if value, ok := row[name]; ok && value != nil {
+				cells[i] = markdownCellReplacer.Replace(fmt.Sprint(value))
+			}
+		}
+		out.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return out.String()
+}
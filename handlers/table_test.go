@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+)
+
+// TestRenderMarkdownTable tests printing tables as markdown with kubectl's headers
+func TestRenderMarkdownTable(t *testing.T) {
+	table := map[string]interface{}{
+		"columns": []map[string]interface{}{
+			{"name": "Name", "type": "string"},
+			{"name": "Ready", "type": "string"},
+			{"name": "Node", "type": "string", "priority": int32(1)},
+			{"name": "Message", "type": "string"},
+		},
+		"rows": []map[string]interface{}{
+			{"namespace": "prod", "Name": "web-1", "Ready": "1/1", "Node": "node-a", "Message": "a|b\nc"},
+			{"namespace": "dev", "Name": "web-2", "Ready": "0/1"},
+		},
+	}
+	expected := "| NAMESPACE | NAME | READY | MESSAGE |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| prod | web-1 | 1/1 | a\\|b c |\n" +
+		"| dev | web-2 | 0/1 |  |\n"
+	if got := renderMarkdownTable(table); got != expected {
+		t.Errorf("Unexpected table:\n%s\nwant:\n%s", got, expected)
+	}
+
+	if got := renderMarkdownTable(map[string]interface{}{"columns": table["columns"], "rows": []map[string]interface{}{}}); got != "No resources found.\n" {
+		t.Errorf("Expected an empty table to say so, got %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// UndoLastChange returns a handler function for the undoLastChange tool.
+// It reverts the most recent change the server made in the current session,
+// provided an authenticated client may still write in its namespace; the tool
+// names no namespace for AuthorizationMiddleware to check.
+// The result is serialized to JSON and returned.
+func UndoLastChange(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := client.UndoLastChange(ctx, func(namespace string) error {
+			caller, ok := policy.ClientFromContext(ctx)
+			if !ok || !caller.NamespaceRestricted(true) || caller.AllowsNamespace(namespace, true) {
+				return nil
+			}
+			if namespace == "" {
+				return fmt.Errorf("forbidden: client %s is limited to specific namespaces and may not undo changes to cluster-scoped objects", caller.Name)
+			}
+			return fmt.Errorf("forbidden: client %s may not write in namespace %s", caller.Name, namespace)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to undo last change: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
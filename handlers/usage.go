@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetUsageHistory returns a handler function for the getUsageHistory tool.
+// It reads the CPU and memory samples kept by the usage sampler for a pod or
+// node. The result is serialized to JSON and returned.
+func GetUsageHistory(sampler *k8s.UsageSampler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		kind := getStringArg(args, "kind", "Pod")
+		namespace := getStringArg(args, "namespace", "")
+		minutes := getIntArg(args, "minutes", 30)
+		if minutes <= 0 {
+			return nil, fmt.Errorf("invalid argument minutes: must be greater than zero")
+		}
+
+		result, err := sampler.History(kind, namespace, name, time.Duration(minutes)*time.Minute)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get usage history: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
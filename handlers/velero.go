@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetVeleroBackups returns a handler function for the getVeleroBackups tool.
+// It lists Velero backups and restores in the provided Velero namespace.
+// The result is serialized to JSON and returned.
+func GetVeleroBackups(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		veleroNamespace := getStringArg(args, "veleroNamespace", k8s.DefaultVeleroNamespace)
+		includeRestores := getBoolArg(args, "includeRestores", true)
+
+		backups, err := client.GetVeleroBackups(ctx, veleroNamespace, includeRestores)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Velero backups: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(backups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// CreateVeleroBackup returns a handler function for the createVeleroBackup tool.
+// It creates a Velero Backup of the provided namespace.
+// The result is serialized to JSON and returned.
+func CreateVeleroBackup(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+
+		backupName := getStringArg(args, "backupName", "")
+		ttl := getStringArg(args, "ttl", "")
+		storageLocation := getStringArg(args, "storageLocation", "")
+		veleroNamespace := getStringArg(args, "veleroNamespace", k8s.DefaultVeleroNamespace)
+		if getBoolArg(args, "dryRun", false) {
+			ctx = k8s.WithDryRun(ctx)
+		}
+
+		backup, err := client.CreateVeleroBackup(ctx, veleroNamespace, namespace, backupName, ttl, storageLocation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Velero backup: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(backup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultWaitTimeoutSeconds is how long waitFor waits when no timeout is given.
+const defaultWaitTimeoutSeconds = 60
+
+// WaitFor returns a handler function for the waitFor tool.
+// It waits until an object, or every object matching a label selector,
+// satisfies a condition or the timeout elapses. While waiting it sends
+// progress notifications if the request carries a progress token.
+// The outcome is serialized to JSON and returned.
+func WaitFor(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		kind, err := getRequiredStringArg(args, "kind")
+		if err != nil {
+			return nil, err
+		}
+		expression, err := getRequiredStringArg(args, "for")
+		if err != nil {
+			return nil, err
+		}
+		cond, err := k8s.ParseWaitCondition(expression)
+		if err != nil {
+			return nil, err
+		}
+		name := getStringArg(args, "name", "")
+		labelSelector := getStringArg(args, "labelSelector", "")
+		if (name == "") == (labelSelector == "") {
+			return nil, fmt.Errorf("exactly one of name and labelSelector must be set")
+		}
+		namespace := getStringArg(args, "namespace", "")
+		timeoutSeconds := getIntArg(args, "timeoutSeconds", defaultWaitTimeoutSeconds)
+		if timeoutSeconds <= 0 || time.Duration(timeoutSeconds)*time.Second > k8s.MaxWaitTimeout {
+			return nil, fmt.Errorf("invalid argument timeoutSeconds: must be between 1 and %d", int(k8s.MaxWaitTimeout.Seconds()))
+		}
+		timeout := time.Duration(timeoutSeconds) * time.Second
+
+		result, err := client.WaitFor(ctx, kind, name, namespace, labelSelector, cond, timeout, waitProgress(ctx, request, timeout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to wait for %s: %w", kind, err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// waitProgress returns a progress callback that reports the time waited as a
+// notifications/progress message, or nil if the client did not ask for
+// progress.
+func waitProgress(ctx context.Context, request mcp.CallToolRequest, timeout time.Duration) func(time.Duration, string) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+	token := request.Params.Meta.ProgressToken
+	return func(elapsed time.Duration, pending string) {
+		// Progress is best effort; a client that went away gets its result regardless
+		_ = mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      int(elapsed.Seconds()),
+			"total":         int(timeout.Seconds()),
+			"message":       "waiting for " + pending,
+		})
+	}
+}
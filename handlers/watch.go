@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultEventWatchMinutes is how long watchEvents watches when no duration
+// is given.
+const defaultEventWatchMinutes = 60
+
+// WatchResources returns a handler function for the watchResources tool.
+// It watches the objects of a kind for a bounded duration and records the
+// deltas observed, sending each to the session as a log notification as it
+// arrives. The result is serialized to JSON and returned.
+func WatchResources(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		kind, err := getRequiredStringArg(args, "kind")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		labelSelector := getStringArg(args, "labelSelector", "")
+		fieldSelector := getStringArg(args, "fieldSelector", "")
+		durationSeconds := getIntArg(args, "durationSeconds", 30)
+		if durationSeconds <= 0 || time.Duration(durationSeconds)*time.Second > k8s.MaxWatchDuration {
+			return nil, fmt.Errorf("invalid argument durationSeconds: must be between 1 and %d", int(k8s.MaxWatchDuration.Seconds()))
+		}
+		maxEvents := getIntArg(args, "maxEvents", 100)
+		if maxEvents <= 0 {
+			return nil, fmt.Errorf("invalid argument maxEvents: must be greater than zero")
+		}
+
+		resourceVersion := getStringArg(args, "resourceVersion", "")
+
+		var notify func(map[string]interface{})
+		if log := sessionLogger(ctx, "watchResources"); log != nil {
+			notify = func(delta map[string]interface{}) { log(mcp.LoggingLevelInfo, map[string]interface{}{"delta": delta}) }
+		}
+		result, err := client.WatchResources(ctx, kind, namespace, labelSelector, fieldSelector, resourceVersion, time.Duration(durationSeconds)*time.Second, maxEvents, notify)
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", kind, err)
+		}
+		deltas, _ := result["deltas"].([]map[string]interface{})
+		setResultMetadata(ctx, "itemCount", len(deltas))
+		setResultMetadata(ctx, "truncated", result["truncated"] == true)
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// WatchEvents returns a handler function for the watchEvents tool.
+// It starts a background watch on events that sends each matching event to
+// the session as a log notification. The watch summary is serialized to JSON
+// and returned.
+func WatchEvents(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		types, err := getStringListArg(args, "types")
+		if err != nil {
+			return nil, err
+		}
+		for _, eventType := range types {
+			if eventType != "Normal" && eventType != "Warning" {
+				return nil, fmt.Errorf("invalid argument types: %q is not Normal or Warning", eventType)
+			}
+		}
+		reasons, err := getStringListArg(args, "reasons")
+		if err != nil {
+			return nil, err
+		}
+		durationMinutes := getIntArg(args, "durationMinutes", defaultEventWatchMinutes)
+		duration := time.Duration(durationMinutes) * time.Minute
+		if durationMinutes <= 0 || duration > k8s.MaxEventWatchDuration {
+			return nil, fmt.Errorf("invalid argument durationMinutes: must be between 1 and %d", int(k8s.MaxEventWatchDuration.Minutes()))
+		}
+
+		watch, err := client.StartEventWatch(ctx, getStringArg(args, "namespace", ""), types, reasons, duration, eventNotifier(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch events: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(watch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// eventNotifier returns a callback that sends watched events to the session
+// of ctx as notifications/message log messages, Warning events at warning
+// level and others at info level, or nil outside a session.
+func eventNotifier(ctx context.Context) func(watchID string, event map[string]interface{}) {
+	log := sessionLogger(ctx, "watchEvents")
+	if log == nil {
+		return nil
+	}
+	return func(watchID string, event map[string]interface{}) {
+		level := mcp.LoggingLevelInfo
+		if event["type"] == "Warning" {
+			level = mcp.LoggingLevelWarning
+		}
+		log(level, map[string]interface{}{"watchId": watchID, "event": event})
+	}
+}
+
+// sessionLogger returns a function sending data to the session of ctx as
+// notifications/message log messages of the named logger, or nil outside a
+// session. Messages below the level the client set with logging/setLevel
+// are not sent.
+func sessionLogger(ctx context.Context, logger string) func(level mcp.LoggingLevel, data map[string]interface{}) {
+	mcpServer := server.ServerFromContext(ctx)
+	sessionID := sessionKey(ctx)
+	if mcpServer == nil || sessionID == "" {
+		return nil
+	}
+	return func(level mcp.LoggingLevel, data map[string]interface{}) {
+		// Notifications are best effort; the data is also in the tool's result
+		_ = mcpServer.SendLogMessageToSpecificClient(sessionID, mcp.NewLoggingMessageNotification(level, logger, data))
+	}
+}
+
+// GetWatchedEvents returns a handler function for the getWatchedEvents tool.
+// It collects the events an event watch has seen since the last collection.
+// The result is serialized to JSON and returned.
+func GetWatchedEvents(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		id, err := getRequiredStringArg(args, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.GetWatchedEvents(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get watched events: %w", err)
+		}
+		if events, ok := result["events"].([]map[string]interface{}); ok {
+			setResultMetadata(ctx, "itemCount", len(events))
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// StopWatchEvents returns a handler function for the stopWatchEvents tool.
+// It stops an event watch of the session. The result is serialized to JSON
+// and returned.
+func StopWatchEvents(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		id, err := getRequiredStringArg(args, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.StopEventWatch(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stop event watch: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
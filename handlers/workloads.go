@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SetAutoscaling returns a handler function for the setAutoscaling tool.
+// It creates or updates a HorizontalPodAutoscaler for the given workload.
+// The result is serialized to JSON and returned.
+func SetAutoscaling(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		maxReplicas := getIntArg(args, "maxReplicas", 0)
+		if maxReplicas < 1 {
+			return nil, fmt.Errorf("missing required parameter: maxReplicas")
+		}
+		kind := getStringArg(args, "kind", "Deployment")
+		namespace := getStringArg(args, "namespace", "")
+		minReplicas := getIntArg(args, "minReplicas", 1)
+		cpuUtilization := getIntArg(args, "cpuUtilization", 0)
+		memoryUtilization := getIntArg(args, "memoryUtilization", 0)
+		if getBoolArg(args, "dryRun", false) {
+			ctx = k8s.WithDryRun(ctx)
+		}
+
+		hpa, err := client.SetAutoscaling(ctx, namespace, kind, name,
+			int32(minReplicas), int32(maxReplicas), int32(cpuUtilization), int32(memoryUtilization))
+		if err != nil {
+			return nil, fmt.Errorf("failed to set autoscaling: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(hpa)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// defaultRolloutTimeoutSeconds is how long waitForRollout waits when no
+// rolloutTimeoutSeconds is given.
+const defaultRolloutTimeoutSeconds = 120
+
+// rolloutWaitArg reads the waitForRollout and rolloutTimeoutSeconds arguments
+// of a mutating tool. Returns how long to wait for the rollout, zero if no wait
+// was requested, or an error if the timeout is out of range.
+func rolloutWaitArg(args map[string]interface{}) (time.Duration, error) {
+	if !getBoolArg(args, "waitForRollout", false) {
+		return 0, nil
+	}
+	timeoutSeconds := getIntArg(args, "rolloutTimeoutSeconds", defaultRolloutTimeoutSeconds)
+	if timeoutSeconds <= 0 || time.Duration(timeoutSeconds)*time.Second > k8s.MaxWaitTimeout {
+		return 0, fmt.Errorf("invalid argument rolloutTimeoutSeconds: must be between 1 and %d", int(k8s.MaxWaitTimeout.Seconds()))
+	}
+	return time.Duration(timeoutSeconds) * time.Second, nil
+}
+
+// SetImage returns a handler function for the setImage tool.
+// It updates a container image in a workload's pod template and reports
+// the triggered rollout revision, optionally waiting for the rollout to
+// finish. The result is serialized to JSON and returned.
+func SetImage(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		kind, err := getRequiredStringArg(args, "kind")
+		if err != nil {
+			return nil, err
+		}
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		image, err := getRequiredStringArg(args, "image")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		container := getStringArg(args, "container", "")
+		rolloutTimeout, err := rolloutWaitArg(args)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.SetImage(ctx, namespace, kind, name, container, image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set image: %w", err)
+		}
+		if rolloutTimeout > 0 && result["changed"] == true && !k8s.IsDryRun(ctx) {
+			rollout, err := client.WaitForRollout(ctx, kind, name, namespace, rolloutTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("failed to wait for rollout: %w", err)
+			}
+			result["rollout"] = rollout
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// PauseRollout returns a handler function for the pauseRollout tool.
+// It pauses the rollout of a Deployment. The result is serialized to JSON and returned.
+func PauseRollout(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return setRolloutPaused(client, true)
+}
+
+// ResumeRollout returns a handler function for the resumeRollout tool.
+// It resumes a paused Deployment rollout. The result is serialized to JSON and returned.
+func ResumeRollout(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return setRolloutPaused(client, false)
+}
+
+// setRolloutPaused returns a handler that sets spec.paused on a Deployment.
+func setRolloutPaused(client *k8s.Client, paused bool) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		if getBoolArg(args, "dryRun", false) {
+			ctx = k8s.WithDryRun(ctx)
+		}
+
+		result, err := client.SetRolloutPaused(ctx, namespace, name, paused)
+		if err != nil {
+			return nil, err
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// RolloutHistory returns a handler function for the rolloutHistory tool.
+// It lists the revisions of a Deployment with their change causes.
+// The result is serialized to JSON and returned.
+func RolloutHistory(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+
+		result, err := client.RolloutHistory(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rollout history: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetPodRevision returns a handler function for the getPodRevision tool.
+// It maps a pod to the Deployment revision and change cause it was created
+// from. The result is serialized to JSON and returned.
+func GetPodRevision(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+
+		result, err := client.GetPodRevision(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod revision: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// RolloutUndo returns a handler function for the rolloutUndo tool.
+// It rolls a Deployment back to a previous revision, optionally waiting for
+// the resulting rollout. The result is serialized to JSON and returned.
+func RolloutUndo(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		toRevision := getIntArg(args, "toRevision", 0)
+		rolloutTimeout, err := rolloutWaitArg(args)
+		if err != nil {
+			return nil, err
+		}
+		if getBoolArg(args, "dryRun", false) {
+			ctx = k8s.WithDryRun(ctx)
+		}
+
+		result, err := client.RolloutUndo(ctx, namespace, name, int64(toRevision))
+		if err != nil {
+			return nil, fmt.Errorf("failed to undo rollout: %w", err)
+		}
+		if rolloutTimeout > 0 && result["changed"] == true && !k8s.IsDryRun(ctx) {
+			rollout, err := client.WaitForRollout(ctx, "Deployment", name, namespace, rolloutTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("failed to wait for rollout: %w", err)
+			}
+			result["rollout"] = rollout
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// SetSuspended returns a handler function for the setSuspended tool.
+// It suspends or resumes a CronJob or other object supporting spec.suspend.
+// The result is serialized to JSON and returned.
+func SetSuspended(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		kind := getStringArg(args, "kind", "CronJob")
+		namespace := getStringArg(args, "namespace", "")
+		suspend := getBoolArg(args, "suspend", true)
+		if getBoolArg(args, "dryRun", false) {
+			ctx = k8s.WithDryRun(ctx)
+		}
+
+		result, err := client.SetSuspended(ctx, namespace, kind, name, suspend)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set suspended state: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// ScaleResource returns a handler function for the scaleResource tool.
+// It sets the replica count of a workload through its scale subresource,
+// optionally waiting for the workload to roll out at the new size.
+// The previous and new replica counts are serialized to JSON and returned.
+func ScaleResource(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		kind, err := getRequiredStringArg(args, "kind")
+		if err != nil {
+			return nil, err
+		}
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := args["replicas"].(float64); !ok {
+			return nil, fmt.Errorf("missing required parameter: replicas")
+		}
+		replicas := getIntArg(args, "replicas", 0)
+		namespace := getStringArg(args, "namespace", "")
+		rolloutTimeout, err := rolloutWaitArg(args)
+		if err != nil {
+			return nil, err
+		}
+		if getBoolArg(args, "dryRun", false) {
+			ctx = k8s.WithDryRun(ctx)
+		}
+
+		result, err := client.ScaleResource(ctx, namespace, kind, name, int32(replicas))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scale resource: %w", err)
+		}
+		if rolloutTimeout > 0 && !k8s.IsDryRun(ctx) {
+			rollout, err := client.WaitForRollout(ctx, kind, name, namespace, rolloutTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("failed to wait for rollout: %w", err)
+			}
+			result["rollout"] = rollout
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// RolloutStatus returns a handler function for the rolloutStatus tool.
+// It waits until the rollout of a Deployment, StatefulSet or DaemonSet is
+// healthy or has failed, or checks it once if timeoutSeconds is 0.
+// The outcome is serialized to JSON and returned.
+func RolloutStatus(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		kind := getStringArg(args, "kind", "Deployment")
+		namespace := getStringArg(args, "namespace", "")
+		timeoutSeconds := getIntArg(args, "timeoutSeconds", defaultWaitTimeoutSeconds)
+		if timeoutSeconds < 0 || time.Duration(timeoutSeconds)*time.Second > k8s.MaxWaitTimeout {
+			return nil, fmt.Errorf("invalid argument timeoutSeconds: must be between 0 and %d", int(k8s.MaxWaitTimeout.Seconds()))
+		}
+
+		result, err := client.WaitForRollout(ctx, kind, name, namespace, time.Duration(timeoutSeconds)*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rollout status: %w", err)
+		}
+		result["kind"] = kind
+		result["name"] = name
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// RestartConfigMapConsumers returns a handler function for the
+// restartConfigMapConsumers tool. It restarts the workloads consuming a
+// ConfigMap and, if requested, waits for their rollouts concurrently.
+// The result is serialized to JSON and returned.
+func RestartConfigMapConsumers(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		name, err := getRequiredStringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		namespace := getStringArg(args, "namespace", "")
+		onlyRestartRequired := getBoolArg(args, "onlyRestartRequired", false)
+		rolloutTimeout, err := rolloutWaitArg(args)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.RestartConfigMapConsumers(ctx, namespace, name, onlyRestartRequired)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restart configmap consumers: %w", err)
+		}
+		if rolloutTimeout > 0 {
+			var wg sync.WaitGroup
+			for _, workload := range result["restarted"].([]map[string]interface{}) {
+				wg.Add(1)
+				go func(workload map[string]interface{}) {
+					defer wg.Done()
+					rollout, err := client.WaitForRollout(ctx, workload["kind"].(string), workload["name"].(string), result["namespace"].(string), rolloutTimeout)
+					if err != nil {
+						workload["rolloutError"] = err.Error()
+						return
+					}
+					workload["rollout"] = rollout
+				}(workload)
+			}
+			wg.Wait()
+		}
+
+		jsonResponse, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
@@ -10,15 +10,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/reza-gholizade/k8s-mcp-server/handlers"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/bench"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/export"
 	"github.com/reza-gholizade/k8s-mcp-server/pkg/helm"
 	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/state"
 	"github.com/reza-gholizade/k8s-mcp-server/tools"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -31,12 +41,62 @@ func main() {
 	var readOnly bool
 	var noK8s bool
 	var noHelm bool
+	var freezeConfigPath string
+	var roles string
+	var authConfigPath string
+	var queriesFile string
+	var stateFile string
+	var exportDir string
+	var exportS3 string
+	var exportThreshold int
+	var usageInterval time.Duration
+	var usageRetention time.Duration
+	var cacheKinds string
+	var cacheTTL time.Duration
+	var cacheResync time.Duration
+	var largeScanThreshold int
+	var execAllow string
+	var execDeny string
+	var namespaceAllow string
+	var namespaceDeny string
+	var inCluster bool
+	var benchFile string
+	var benchIterations int
+	var benchConcurrency int
+	var benchFake string
+	var benchProfile string
+	var benchWrites bool
 
 	flag.StringVar(&port, "port", getEnvOrDefault("SERVER_PORT", "8080"), "Server port")
 	flag.StringVar(&mode, "mode", getEnvOrDefault("SERVER_MODE", "sse"), "Server mode: 'stdio', 'sse', or 'streamable-http'")
-	flag.BoolVar(&readOnly, "read-only", false, "Enable read-only mode (disables write operations)")
+	flag.BoolVar(&readOnly, "read-only", getEnvOrDefault("READ_ONLY", "") == "true", "Enable read-only mode (disables write operations)")
+	flag.BoolVar(&inCluster, "in-cluster", getEnvOrDefault("IN_CLUSTER", "") == "true", "Connect with the pod's service account even if a kubeconfig exists")
 	flag.BoolVar(&noK8s, "no-k8s", false, "Disable Kubernetes tools")
 	flag.BoolVar(&noHelm, "no-helm", false, "Disable Helm tools")
+	flag.StringVar(&freezeConfigPath, "freeze-config", getEnvOrDefault("FREEZE_CONFIG", ""), "Path to a YAML or JSON file of change freeze windows")
+	flag.StringVar(&authConfigPath, "auth-config", getEnvOrDefault("AUTH_CONFIG", ""), "Path to a YAML or JSON file mapping client tokens to tool and namespace permissions (SSE and streamable-http modes)")
+	flag.StringVar(&queriesFile, "queries-file", getEnvOrDefault("SAVED_QUERIES_FILE", ""), "Path to a JSON file persisting saved queries (default: in memory)")
+	flag.StringVar(&stateFile, "state-file", getEnvOrDefault("STATE_FILE", ""), "Path to a bbolt file persisting saved queries, undo logs and usage history across restarts (default: in memory)")
+	flag.StringVar(&exportDir, "export-dir", getEnvOrDefault("EXPORT_DIR", ""), "Directory that large tool outputs can be exported to")
+	flag.StringVar(&exportS3, "export-s3", getEnvOrDefault("EXPORT_S3", ""), "S3-compatible bucket that large tool outputs can be exported to, as s3://bucket/prefix")
+	flag.IntVar(&exportThreshold, "export-threshold", getEnvIntOrDefault("EXPORT_THRESHOLD_BYTES", 0), "Export outputs larger than this many bytes automatically when a single export target is configured (0 disables)")
+	flag.DurationVar(&usageInterval, "usage-sample-interval", getEnvDurationOrDefault("USAGE_SAMPLE_INTERVAL", 0), "Sample pod and node metrics on this interval for getUsageHistory, e.g. 30s (0 disables)")
+	flag.DurationVar(&usageRetention, "usage-retention", getEnvDurationOrDefault("USAGE_RETENTION", time.Hour), "How long sampled metrics are kept for getUsageHistory")
+	flag.StringVar(&cacheKinds, "cache-kinds", getEnvOrDefault("CACHE_KINDS", ""), "Comma-separated kinds listResources serves from informer caches, e.g. 'Pod,Event,Node' (default: none)")
+	flag.DurationVar(&cacheTTL, "cache-ttl", getEnvDurationOrDefault("CACHE_TTL", 10*time.Minute), "Stop the informer of a cached kind that has not been listed for this long")
+	flag.DurationVar(&cacheResync, "cache-resync", getEnvDurationOrDefault("CACHE_RESYNC", 0), "Restart informers from a fresh list after this long, e.g. 30m (0 disables)")
+	flag.IntVar(&largeScanThreshold, "large-scan-threshold", getEnvIntOrDefault("LARGE_SCAN_THRESHOLD", k8s.DefaultLargeScanThreshold), "Ask for confirmLargeScan before scans across namespaces estimated to return more objects than this (0 disables)")
+	flag.StringVar(&execAllow, "exec-allow", getEnvOrDefault("EXEC_ALLOW", ""), "Comma-separated command patterns execInPod may run, e.g. 'cat,ls,env' (default: any command not denied)")
+	flag.StringVar(&execDeny, "exec-deny", getEnvOrDefault("EXEC_DENY", ""), "Comma-separated command patterns execInPod refuses, taking precedence over --exec-allow")
+	flag.StringVar(&namespaceAllow, "namespace-allow", getEnvOrDefault("NAMESPACE_ALLOW", ""), "Comma-separated namespace patterns tools may act in, e.g. 'team-a-*,shared' (default: any namespace not denied)")
+	flag.StringVar(&namespaceDeny, "namespace-deny", getEnvOrDefault("NAMESPACE_DENY", ""), "Comma-separated namespace patterns tools may not act in, taking precedence over --namespace-allow")
+	flag.StringVar(&roles, "roles", getEnvOrDefault("MCP_ROLES", ""), "Comma-separated roles granted to callers, e.g. a freeze override role")
+	flag.StringVar(&benchFile, "bench", "", "Replay the tool calls recorded in this JSON lines file, report the latency and allocations of each tool, and exit instead of serving")
+	flag.IntVar(&benchIterations, "bench-iterations", 10, "Times --bench replays the recorded calls")
+	flag.IntVar(&benchConcurrency, "bench-concurrency", 1, "Calls --bench makes at once; allocations are only reported per tool at 1")
+	flag.StringVar(&benchFake, "bench-fake", "", "Run --bench against an in-process API server serving the objects of this YAML or JSON file instead of the cluster")
+	flag.StringVar(&benchProfile, "bench-profile", "", "Directory --bench writes CPU and allocation profiles to")
+	flag.BoolVar(&benchWrites, "bench-writes", false, "Let --bench replay calls of mutating tools against the cluster; they are always replayed with --bench-fake")
 	flag.Parse()
 
 	// Validate flag combinations
@@ -44,6 +104,10 @@ func main() {
 		fmt.Println("Error: Cannot disable both Kubernetes and Helm tools. At least one tool category must be enabled.")
 		os.Exit(1)
 	}
+	if benchFile == "" && (benchFake != "" || benchProfile != "" || benchWrites) {
+		fmt.Println("Error: --bench-fake, --bench-profile and --bench-writes require --bench.")
+		os.Exit(1)
+	}
 
 	// Log read-only mode status
 	if readOnly {
@@ -58,63 +122,467 @@ func main() {
 		fmt.Println("Helm tools disabled")
 	}
 
+	// Load change freeze windows
+	var freezeConfig *policy.FreezeConfig
+	if freezeConfigPath != "" {
+		var err error
+		if freezeConfig, err = policy.LoadFreezeConfig(freezeConfigPath); err != nil {
+			fmt.Printf("Failed to load freeze config: %v\n", err)
+			return
+		}
+		fmt.Printf("Loaded %d change freeze windows\n", len(freezeConfig.Windows))
+	}
+
+	// Load client permissions, which are enforced for authenticated HTTP transports
+	var authConfig *policy.AuthConfig
+	if authConfigPath != "" {
+		if mode == "stdio" {
+			fmt.Println("Ignoring auth config in stdio mode: clients are only authenticated over SSE and streamable-http")
+		} else {
+			var err error
+			if authConfig, err = policy.LoadAuthConfig(authConfigPath); err != nil {
+				fmt.Printf("Failed to load auth config: %v\n", err)
+				return
+			}
+			fmt.Printf("Loaded permissions for %d clients\n", len(authConfig.Clients))
+		}
+	}
+
+	// Configure the targets large outputs can be exported to
+	exporters := map[string]export.Exporter{}
+	if exportDir != "" {
+		fileExporter, err := export.NewFileExporter(exportDir)
+		if err != nil {
+			fmt.Printf("Failed to configure file export: %v\n", err)
+			return
+		}
+		exporters["file"] = fileExporter
+	}
+	if exportS3 != "" {
+		s3Exporter, err := export.NewS3Exporter(exportS3)
+		if err != nil {
+			fmt.Printf("Failed to configure S3 export: %v\n", err)
+			return
+		}
+		exporters["s3"] = s3Exporter
+	}
+
+	// Restrict the commands execInPod may run
+	execPolicy, err := policy.NewExecPolicy(splitList(execAllow), splitList(execDeny))
+	if err != nil {
+		fmt.Printf("Failed to configure exec policy: %v\n", err)
+		return
+	}
+
+	// Restrict the namespaces tools may act in
+	namespacePolicy, err := policy.NewNamespacePolicy(splitList(namespaceAllow), splitList(namespaceDeny))
+	if err != nil {
+		fmt.Printf("Failed to configure namespace policy: %v\n", err)
+		return
+	}
+
+	// Persist operational state across restarts
+	var stateStore *state.Store
+	if stateFile != "" {
+		if stateStore, err = state.Open(stateFile); err != nil {
+			fmt.Printf("Failed to open state file: %v\n", err)
+			return
+		}
+		defer stateStore.Close()
+		fmt.Printf("Persisting state to %s\n", stateFile)
+	}
+
+	// Benchmark against an in-process API server rather than the cluster
+	var kubeconfig string
+	if benchFake != "" {
+		objects, err := bench.LoadFixtures(benchFake)
+		if err != nil {
+			fmt.Printf("Failed to load fixtures: %v\n", err)
+			return
+		}
+		fake, err := bench.NewFakeCluster(objects)
+		if err != nil {
+			fmt.Printf("Failed to start fake cluster: %v\n", err)
+			return
+		}
+		defer fake.Close()
+		dir, err := os.MkdirTemp("", "k8s-mcp-bench")
+		if err != nil {
+			fmt.Printf("Failed to start fake cluster: %v\n", err)
+			return
+		}
+		defer os.RemoveAll(dir)
+		kubeconfig = filepath.Join(dir, "kubeconfig")
+		if err := fake.WriteKubeconfig(kubeconfig); err != nil {
+			fmt.Printf("Failed to start fake cluster: %v\n", err)
+			return
+		}
+		fmt.Printf("Serving %d fixture objects from a fake cluster at %s\n", len(objects), fake.URL())
+	}
+
+	// Create a Kubernetes client, from the kubeconfig unless forced in-cluster
+	var client *k8s.Client
+	if inCluster && kubeconfig == "" {
+		client, err = k8s.NewInClusterClient()
+	} else {
+		client, err = k8s.NewClient(kubeconfig)
+	}
+	if err != nil {
+		fmt.Printf("Failed to create Kubernetes client: %v\n", err)
+		return
+	}
+	if client.InCluster() {
+		fmt.Println("Using in-cluster configuration")
+	}
+	if readOnly {
+		// Refuse writes in the client too, should any tool attempt one
+		if client, err = client.WithReadOnly(); err != nil {
+			fmt.Printf("Failed to create Kubernetes client: %v\n", err)
+			return
+		}
+	}
+
+	if namespacePolicy.Restricted() {
+		// Refuse requests outside the allowed namespaces before they are sent
+		if client, err = client.WithNamespacePolicy(namespacePolicy.Check); err != nil {
+			fmt.Printf("Failed to create Kubernetes client: %v\n", err)
+			return
+		}
+		fmt.Println("Restricting tools to the namespaces allowed by the namespace policy")
+	}
+
+	// Serve lists of hot kinds from informers rather than the API server
+	if kinds := splitList(cacheKinds); len(kinds) > 0 {
+		if err := client.EnableCache(kinds, cacheTTL, cacheResync); err != nil {
+			fmt.Printf("Failed to enable the resource cache: %v\n", err)
+			return
+		}
+		fmt.Printf("Caching %s for %s after their last list\n", strings.Join(kinds, ", "), cacheTTL)
+	}
+
+	// Keep the undo logs of the default context in the state file
+	if stateStore != nil {
+		restored, err := client.PersistUndo(stateStore)
+		if err != nil {
+			fmt.Printf("Failed to restore undo logs: %v\n", err)
+			return
+		}
+		if restored > 0 {
+			fmt.Printf("Restored %d undoable changes\n", restored)
+		}
+	}
+
+	// Estimate scans across namespaces before making them
+	client.SetLargeScanThreshold(largeScanThreshold)
+
+	// Clients of other kubeconfig contexts, created when a call names one
+	clients := k8s.NewClientPool(kubeconfig, client)
+	contextual := func(tool mcp.Tool, newHandler handlers.ClientHandler) (mcp.Tool, server.ToolHandlerFunc) {
+		return handlers.WithContext(clients, tool, newHandler)
+	}
+
+	// Mutating tools, recorded as they are registered, are subject to change
+	// freezes and to the write namespaces of authenticated clients
+	writeTools := map[string]bool{}
+	isWriteTool := func(name string) bool { return writeTools[name] }
+
+	// Helm tools talk to the cluster through their own client, which the
+	// namespace policy does not guard
+	isHelmTool := func(name string) bool { return strings.HasPrefix(name, "helm") }
+
+	var s *server.MCPServer
+	toolHasParam := func(name, param string) bool {
+		tool := s.GetTool(name)
+		if tool == nil {
+			return false
+		}
+		_, ok := tool.Tool.InputSchema.Properties[param]
+		return ok
+	}
+
+	toolParamEnum := func(name, param string) ([]string, bool) {
+		tool := s.GetTool(name)
+		if tool == nil {
+			return nil, false
+		}
+		property, ok := tool.Tool.InputSchema.Properties[param].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		enum, _ := property["enum"].([]string)
+		return enum, true
+	}
+
+	// Client permissions are only enforced when an auth config is loaded
+	authorize := func(next server.ToolHandlerFunc) server.ToolHandlerFunc { return next }
+	if authConfig != nil {
+		hasNamespaceParam := func(name string) bool { return toolHasParam(name, "namespace") }
+		authorize = handlers.AuthorizationMiddleware(isWriteTool, hasNamespaceParam)
+	}
+
+	// Tools accepting exportTo can write their output to the configured targets
+	exportResults := handlers.ExportMiddleware(exporters, exportThreshold, toolHasParam)
+
+	// Defaults pinned with setSessionDefaults
+	sessionStore := handlers.NewSessionStore()
+
+	// Queries saved with saveQuery, shared by all sessions
+	queryStore, err := handlers.NewQueryStore(queriesFile)
+	if err != nil {
+		fmt.Printf("Failed to load saved queries: %v\n", err)
+		return
+	}
+	if stateStore != nil && queriesFile == "" {
+		if err := queryStore.PersistTo(stateStore); err != nil {
+			fmt.Printf("Failed to load saved queries: %v\n", err)
+			return
+		}
+	}
+
+	// Port forwards, event watches and pinned defaults belong to the session
+	// that started them and end with it
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		sessionStore.Delete(session.SessionID())
+		stopped, watches := 0, 0
+		for _, c := range clients.Clients() {
+			stopped += c.StopSessionPortForwards(session.SessionID())
+			watches += c.StopSessionEventWatches(session.SessionID())
+		}
+		if stopped > 0 {
+			fmt.Printf("Stopped %d port forwards of session %s\n", stopped, session.SessionID())
+		}
+		if watches > 0 {
+			fmt.Printf("Stopped %d event watches of session %s\n", watches, session.SessionID())
+		}
+	})
+
 	// Create MCP server
-	s := server.NewMCPServer(
+	s = server.NewMCPServer(
 		"MCP K8S & Helm Server",
 		"1.0.0",
-		server.WithResourceCapabilities(true, true), // Enable resource listing and subscription capabilities
+		server.WithResourceCapabilities(true, true),                                                       // Enable resource listing and subscription capabilities
+		server.WithToolHandlerMiddleware(handlers.SessionDefaultsMiddleware(sessionStore, toolHasParam)),  // Fill in arguments pinned for the session
+		server.WithToolHandlerMiddleware(handlers.MetadataMiddleware(client)),                             // Attach a metadata block to every result
+		server.WithToolHandlerMiddleware(handlers.ErrorEnvelopeMiddleware),                                // Return failures as structured error objects
+		server.WithToolHandlerMiddleware(handlers.SessionMiddleware),                                      // Keep per-session state such as the undo log apart
+		server.WithToolHandlerMiddleware(handlers.RolesMiddleware(splitList(roles))),                      // Grant the configured roles to callers
+		server.WithToolHandlerMiddleware(authorize),                                                       // Enforce per-client tool and namespace permissions
+		server.WithToolHandlerMiddleware(handlers.NamespacePolicyMiddleware(namespacePolicy, isHelmTool)), // Refuse calls in namespaces the policy excludes
+		server.WithToolHandlerMiddleware(handlers.FreezeMiddleware(freezeConfig, isWriteTool)),            // Refuse mutations during change freezes
+		server.WithToolHandlerMiddleware(exportResults),                                                   // Write large outputs to a file or bucket
+		server.WithToolHandlerMiddleware(handlers.OutputFormatMiddleware(toolParamEnum)),                  // Render results as YAML on request
+		server.WithHooks(hooks),           // Clean up after sessions that end
+		server.WithLogging(),              // Send watched events as log notifications
+		server.WithToolCapabilities(true), // Notify clients when refreshCapabilities changes the tools
 	)
 
-	// Create a Kubernetes client
-	client, err := k8s.NewClient("")
-	if err != nil {
-		fmt.Printf("Failed to create Kubernetes client: %v\n", err)
-		return
+	addWriteTool := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		writeTools[tool.Name] = true
+		s.AddTool(tool, handler)
+	}
+
+	// Tools needing optional APIs are only offered while the cluster serves them
+	capabilityTools := handlers.NewCapabilityTools(s, client)
+	addCapabilityTool := func(capability string) func(mcp.Tool, server.ToolHandlerFunc) {
+		return func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+			capabilityTools.Add(capability, tool, handler)
+		}
+	}
+	addWriteCapabilityTool := func(capability string) func(mcp.Tool, server.ToolHandlerFunc) {
+		return func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+			writeTools[tool.Name] = true
+			capabilityTools.Add(capability, tool, handler)
+		}
 	}
 
-	// Create Helm client with default kubeconfig path
-	helmClient, err := helm.NewClient("")
+	// Create Helm client with the default kubeconfig path, or the fake cluster's
+	helmClient, err := helm.NewClient(kubeconfig)
 	if err != nil {
 		fmt.Printf("Failed to create Helm client: %v\n", err)
 		return
 	}
 
+	// Server settings reported by getServerInfo
+	serverSettings := map[string]interface{}{
+		"version":   "1.0.0",
+		"transport": mode,
+		"readOnly":  readOnly,
+		"helmTools": !noHelm,
+	}
+
 	// Register Kubernetes tools
 	if !noK8s {
-		s.AddTool(tools.GetAPIResourcesTool(), handlers.GetAPIResources(client))
-		s.AddTool(tools.ListResourcesTool(), handlers.ListResources(client))
-		s.AddTool(tools.GetResourcesTool(), handlers.GetResources(client))
-		s.AddTool(tools.DescribeResourcesTool(), handlers.DescribeResources(client))
-		s.AddTool(tools.GetPodsLogsTools(), handlers.GetPodsLogs(client))
-		s.AddTool(tools.GetNodeMetricsTools(), handlers.GetNodeMetrics(client))
-		s.AddTool(tools.GetPodMetricsTool(), handlers.GetPodMetrics(client))
-		s.AddTool(tools.GetEventsTool(), handlers.GetEvents(client))
-		s.AddTool(tools.GetIngressesTool(), handlers.GetIngresses(client))
+		s.AddTool(contextual(tools.GetServerInfoTool(), func(c *k8s.Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handlers.GetServerInfo(c, serverSettings)
+		}))
+		s.AddTool(tools.ListContextsTool(), handlers.ListContexts(clients))
+		s.AddTool(contextual(tools.GetAPIResourcesTool(), handlers.GetAPIResources))
+		s.AddTool(contextual(tools.ClusterInventoryTool(), handlers.ClusterInventory))
+		s.AddTool(contextual(tools.ListNamespacesTool(), handlers.ListNamespaces))
+		s.AddTool(contextual(tools.ListResourcesTool(), handlers.ListResources))
+		if cacheKinds != "" {
+			s.AddTool(tools.CacheStatsTool(), handlers.CacheStats(client))
+		}
+		s.AddTool(contextual(tools.GetResourcesTool(), handlers.GetResources))
+		s.AddTool(contextual(tools.DescribeResourcesTool(), handlers.DescribeResources))
+		s.AddTool(contextual(tools.GetPodsLogsTools(), handlers.GetPodsLogs))
+		s.AddTool(contextual(tools.GetLogsBySelectorTool(), handlers.GetLogsBySelector))
+		s.AddTool(contextual(tools.CorrelateEventLogsTool(), handlers.CorrelateEventLogs))
+		addCapabilityTool("metrics")(contextual(tools.GetNodeMetricsTools(), handlers.GetNodeMetrics))
+		addCapabilityTool("metrics")(contextual(tools.GetPodMetricsTool(), handlers.GetPodMetrics))
+		s.AddTool(contextual(tools.GetEventsTool(), handlers.GetEvents))
+		s.AddTool(contextual(tools.GetIngressesTool(), handlers.GetIngresses))
+		s.AddTool(contextual(tools.CheckIngressPortsTool(), handlers.CheckIngressPorts))
+		s.AddTool(contextual(tools.CheckStatefulSetDNSTool(), handlers.CheckStatefulSetDNS))
+		s.AddTool(contextual(tools.DiagnoseLoadBalancersTool(), handlers.DiagnoseLoadBalancers))
+		addCapabilityTool("istio")(contextual(tools.GetIstioTrafficConfigTool(), handlers.GetIstioTrafficConfig))
+		s.AddTool(contextual(tools.GetSidecarInjectionStatusTool(), handlers.GetSidecarInjectionStatus))
+		addCapabilityTool("keda")(contextual(tools.GetKedaScalersTool(), handlers.GetKedaScalers))
+		addCapabilityTool("knative")(contextual(tools.GetKnativeServicesTool(), handlers.GetKnativeServices))
+		addCapabilityTool("velero")(contextual(tools.GetVeleroBackupsTool(), handlers.GetVeleroBackups))
+		addCapabilityTool("clusterAPI")(contextual(tools.GetCapiInventoryTool(), handlers.GetCapiInventory))
+		s.AddTool(contextual(tools.CheckPlatformCompatibilityTool(), handlers.CheckPlatformCompatibility))
+		s.AddTool(contextual(tools.GetImagePullReportTool(), handlers.GetImagePullReport))
+		addCapabilityTool("olm")(contextual(tools.GetOLMSubscriptionsTool(), handlers.GetOLMSubscriptions))
+		s.AddTool(contextual(tools.GetPodEnvironmentTool(), handlers.GetPodEnvironment))
+		s.AddTool(contextual(tools.GetPodVolumeMountsTool(), handlers.GetPodVolumeMounts))
+		s.AddTool(contextual(tools.GetSecretUsageTool(), handlers.GetSecretUsage))
+		s.AddTool(contextual(tools.GetConfigMapUsageTool(), handlers.GetConfigMapUsage))
+		s.AddTool(contextual(tools.GetPodsOnNodeTool(), handlers.GetPodsOnNode))
+		s.AddTool(contextual(tools.GetKubeletStatsTool(), handlers.GetKubeletStats))
+		s.AddTool(contextual(tools.GetNodeLogsTool(), handlers.GetNodeLogs))
+		s.AddTool(contextual(tools.GetFlowControlTool(), handlers.GetFlowControl))
+		s.AddTool(contextual(tools.AnalyzeEphemeralStorageTool(), handlers.AnalyzeEphemeralStorage))
+		s.AddTool(contextual(tools.GetEvictionRiskTool(), handlers.GetEvictionRisk))
+		s.AddTool(contextual(tools.GetPortAllocationsTool(), handlers.GetPortAllocations))
+		s.AddTool(contextual(tools.FindRestartStormsTool(), handlers.FindRestartStorms))
+		s.AddTool(contextual(tools.ListFailuresTool(), handlers.ListFailures))
+		s.AddTool(contextual(tools.DiagnoseInitContainersTool(), handlers.DiagnoseInitContainers))
+		s.AddTool(contextual(tools.GetPodStartupBreakdownTool(), handlers.GetPodStartupBreakdown))
+		s.AddTool(contextual(tools.CompareNamespacesTool(), handlers.CompareNamespaces))
+		s.AddTool(contextual(tools.CompareRolesTool(), handlers.CompareRoles))
+		s.AddTool(contextual(tools.CanITool(), handlers.CanI))
+		s.AddTool(contextual(tools.WhoCanTool(), handlers.WhoCan))
+		s.AddTool(contextual(tools.GetWorkloadManifestTool(), handlers.GetWorkloadManifest))
+		s.AddTool(contextual(tools.PreviewAdmissionTool(), handlers.PreviewAdmission))
+		s.AddTool(tools.SetSessionDefaultsTool(), handlers.SetSessionDefaults(clients, sessionStore))
+		s.AddTool(tools.SaveQueryTool(), handlers.SaveQuery(queryStore))
+		s.AddTool(contextual(tools.RunQueryTool(), func(c *k8s.Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handlers.RunQuery(c, queryStore)
+		}))
+		s.AddTool(tools.ListSavedQueriesTool(), handlers.ListSavedQueries(queryStore))
+		s.AddTool(tools.DeleteSavedQueryTool(), handlers.DeleteSavedQuery(queryStore))
+		s.AddTool(contextual(tools.CollectDiagnosticsTool(), func(c *k8s.Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handlers.CollectDiagnostics(c, exporters)
+		}))
+		s.AddTool(contextual(tools.GetConditionsTool(), handlers.GetConditions))
+		s.AddTool(contextual(tools.WaitForTool(), handlers.WaitFor))
+		s.AddTool(contextual(tools.WatchResourcesTool(), handlers.WatchResources))
+		s.AddTool(contextual(tools.WatchEventsTool(), handlers.WatchEvents))
+		s.AddTool(contextual(tools.GetWatchedEventsTool(), handlers.GetWatchedEvents))
+		s.AddTool(contextual(tools.StopWatchEventsTool(), handlers.StopWatchEvents))
+		s.AddTool(contextual(tools.ExplainSchedulingTool(), handlers.ExplainScheduling))
+		s.AddTool(contextual(tools.RolloutStatusTool(), handlers.RolloutStatus))
+		s.AddTool(contextual(tools.RolloutHistoryTool(), handlers.RolloutHistory))
+		s.AddTool(contextual(tools.GetPodRevisionTool(), handlers.GetPodRevision))
+		s.AddTool(contextual(tools.StartPortForwardTool(), handlers.StartPortForward))
+		s.AddTool(contextual(tools.ListPortForwardsTool(), handlers.ListPortForwards))
+		s.AddTool(contextual(tools.StopPortForwardTool(), handlers.StopPortForward))
+
+		// Sample metrics in the background for getUsageHistory
+		if usageInterval > 0 {
+			sampler := k8s.NewUsageSampler(client, usageInterval, usageRetention)
+			if stateStore != nil {
+				restored, err := sampler.Persist(stateStore)
+				if err != nil {
+					fmt.Printf("Failed to restore usage history: %v\n", err)
+					return
+				}
+				fmt.Printf("Restored %d usage samples\n", restored)
+			}
+			sampler.Start(context.Background())
+			addCapabilityTool("metrics")(tools.GetUsageHistoryTool(), handlers.GetUsageHistory(sampler))
+			fmt.Printf("Sampling usage every %s, keeping %s of history\n", usageInterval, usageRetention)
+		}
 
 		// Register write operations only if not in read-only mode
 		if !readOnly {
-			s.AddTool(tools.CreateOrUpdateResourceJSONTool(), handlers.CreateOrUpdateResourceJSON(client))
-			s.AddTool(tools.CreateOrUpdateResourceYAMLTool(), handlers.CreateOrUpdateResourceYAML(client))
-			s.AddTool(tools.DeleteResourceTool(), handlers.DeleteResource(client))
-			s.AddTool(tools.RolloutRestartTool(), handlers.RolloutRestart(client))
+			addWriteTool(contextual(tools.CreateOrUpdateResourceJSONTool(), handlers.CreateOrUpdateResourceJSON))
+			addWriteTool(contextual(tools.CreateOrUpdateResourceYAMLTool(), handlers.CreateOrUpdateResourceYAML))
+			addWriteTool(contextual(tools.ApplyResourceTool(), handlers.ApplyResource))
+			addWriteTool(contextual(tools.DeleteResourceTool(), handlers.DeleteResource))
+			addWriteTool(contextual(tools.CleanupFinishedTool(), handlers.CleanupFinished))
+			addWriteTool(contextual(tools.RolloutRestartTool(), handlers.RolloutRestart))
+			addWriteTool(contextual(tools.RestartConfigMapConsumersTool(), handlers.RestartConfigMapConsumers))
+			addWriteCapabilityTool("velero")(contextual(tools.CreateVeleroBackupTool(), handlers.CreateVeleroBackup))
+			addWriteTool(contextual(tools.SetAutoscalingTool(), handlers.SetAutoscaling))
+			addWriteTool(contextual(tools.SetImageTool(), handlers.SetImage))
+			addWriteTool(contextual(tools.ScaleResourceTool(), handlers.ScaleResource))
+			addWriteTool(contextual(tools.PauseRolloutTool(), handlers.PauseRollout))
+			addWriteTool(contextual(tools.ResumeRolloutTool(), handlers.ResumeRollout))
+			addWriteTool(contextual(tools.RolloutUndoTool(), handlers.RolloutUndo))
+			addWriteTool(contextual(tools.ExecInPodTool(), func(c *k8s.Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return handlers.ExecInPod(c, execPolicy)
+			}))
+			addWriteTool(contextual(tools.SetSuspendedTool(), handlers.SetSuspended))
+			addWriteTool(contextual(tools.ExecutePlanTool(), handlers.ExecutePlan))
+			addWriteTool(contextual(tools.UndoLastChangeTool(), handlers.UndoLastChange))
 		}
 	}
 
 	// Register Helm tools
 	if !noHelm {
-		s.AddTool(tools.HelmListTool(), handlers.HelmList(helmClient))
-		s.AddTool(tools.HelmGetTool(), handlers.HelmGet(helmClient))
-		s.AddTool(tools.HelmHistoryTool(), handlers.HelmHistory(helmClient))
-		s.AddTool(tools.HelmRepoListTool(), handlers.HelmRepoList(helmClient))
+		addCapabilityTool("helm")(tools.HelmListTool(), handlers.HelmList(helmClient))
+		addCapabilityTool("helm")(tools.HelmGetTool(), handlers.HelmGet(helmClient))
+		addCapabilityTool("helm")(tools.HelmHistoryTool(), handlers.HelmHistory(helmClient))
+		addCapabilityTool("helm")(tools.HelmRepoListTool(), handlers.HelmRepoList(helmClient))
 
 		// Register write operations only if not in read-only mode
 		if !readOnly {
-			s.AddTool(tools.HelmInstallTool(), handlers.HelmInstall(helmClient))
-			s.AddTool(tools.HelmUpgradeTool(), handlers.HelmUpgrade(helmClient))
-			s.AddTool(tools.HelmUninstallTool(), handlers.HelmUninstall(helmClient))
-			s.AddTool(tools.HelmRollbackTool(), handlers.HelmRollback(helmClient))
-			s.AddTool(tools.HelmRepoAddTool(), handlers.HelmRepoAdd(helmClient))
+			addWriteCapabilityTool("helm")(tools.HelmInstallTool(), handlers.HelmInstall(helmClient))
+			addWriteCapabilityTool("helm")(tools.HelmUpgradeTool(), handlers.HelmUpgrade(helmClient))
+			addWriteCapabilityTool("helm")(tools.HelmUninstallTool(), handlers.HelmUninstall(helmClient))
+			addWriteCapabilityTool("helm")(tools.HelmRollbackTool(), handlers.HelmRollback(helmClient))
+			addWriteCapabilityTool("helm")(tools.HelmRepoAddTool(), handlers.HelmRepoAdd(helmClient))
+		}
+	}
+
+	// Register the tools of the optional APIs the cluster serves
+	if _, err := capabilityTools.Refresh(context.Background()); err != nil {
+		fmt.Printf("Failed to probe cluster capabilities, registering all tools: %v\n", err)
+	} else if unavailable := capabilityTools.Unavailable(); len(unavailable) > 0 {
+		fmt.Printf("Skipping tools of capabilities the cluster lacks: %s\n", strings.Join(unavailable, ", "))
+	}
+	s.AddTool(tools.RefreshCapabilitiesTool(), handlers.RefreshCapabilities(capabilityTools))
+
+	// Replay recorded tool calls instead of serving clients
+	if benchFile != "" {
+		calls, err := bench.LoadCalls(benchFile)
+		if err != nil {
+			fmt.Printf("Failed to load recorded calls: %v\n", err)
+			return
+		}
+		fmt.Printf("Replaying %d recorded tool calls %d times...\n", len(calls), benchIterations)
+		report, err := bench.Run(context.Background(), s, calls, bench.Options{
+			Iterations:  benchIterations,
+			Concurrency: benchConcurrency,
+			ProfileDir:  benchProfile,
+			IsWrite:     isWriteTool,
+			AllowWrites: benchWrites || benchFake != "",
+		})
+		if err != nil {
+			fmt.Printf("Failed to run benchmark: %v\n", err)
+			return
 		}
+		if err := report.Write(os.Stdout); err != nil {
+			fmt.Printf("Failed to write benchmark report: %v\n", err)
+			return
+		}
+		if benchProfile != "" {
+			fmt.Printf("Wrote cpu.pprof and allocs.pprof to %s\n", benchProfile)
+		}
+		return
 	}
 
 	// Start server based on mode
@@ -127,7 +595,11 @@ func main() {
 		}
 	case "sse":
 		fmt.Printf("Starting server in SSE mode on port %s...\n", port)
-		sse := server.NewSSEServer(s)
+		var sseOpts []server.SSEOption
+		if authConfig != nil {
+			sseOpts = append(sseOpts, server.WithSSEContextFunc(handlers.AuthContextFunc(authConfig)))
+		}
+		sse := server.NewSSEServer(s, sseOpts...)
 		if err := sse.Start(":" + port); err != nil {
 			fmt.Printf("Failed to start SSE server: %v\n", err)
 			return
@@ -135,7 +607,11 @@ func main() {
 		fmt.Printf("SSE server started on port %s\n", port)
 	case "streamable-http":
 		fmt.Printf("Starting server in streamable-http mode on port %s...\n", port)
-		streamableHTTP := server.NewStreamableHTTPServer(s, server.WithStateLess(true))
+		httpOpts := []server.StreamableHTTPOption{server.WithStateLess(true)}
+		if authConfig != nil {
+			httpOpts = append(httpOpts, server.WithHTTPContextFunc(handlers.AuthContextFunc(authConfig)))
+		}
+		streamableHTTP := server.NewStreamableHTTPServer(s, httpOpts...)
 		if err := streamableHTTP.Start(":" + port); err != nil {
 			fmt.Printf("Failed to start streamable-http server: %v\n", err)
 			return
@@ -154,3 +630,30 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvIntOrDefault returns the integer value of the environment variable or the default value if not set or invalid
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDurationOrDefault returns the duration value of the environment variable or the default value if not set or invalid
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
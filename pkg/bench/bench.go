@@ -0,0 +1,347 @@
+// Package bench replays recorded tool calls against an MCP server and reports
+// the latency and allocations of each tool's handler, so that changes to the
+// handlers can be measured rather than guessed at.
+package bench
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Call is a recorded tool call.
+type Call struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// LoadCalls reads recorded tool calls from a file of JSON lines. Each line is
+// either a tools/call JSON-RPC request, as captured from client traffic, or an
+// object with the tool's name and arguments. Blank lines, lines starting with
+// "#" and JSON-RPC messages other than tools/call are skipped.
+// Returns the calls in the order they were recorded, or an error.
+func LoadCalls(path string) ([]Call, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded calls: %w", err)
+	}
+	defer file.Close()
+
+	var calls []Call
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var record struct {
+			Call
+			Method string `json:"method"`
+			Params *Call  `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse recorded call on line %d: %w", line, err)
+		}
+		call := record.Call
+		if record.Method != "" {
+			if record.Method != string(mcp.MethodToolsCall) || record.Params == nil {
+				continue
+			}
+			call = *record.Params
+		}
+		if call.Name == "" {
+			return nil, fmt.Errorf("recorded call on line %d names no tool", line)
+		}
+		calls = append(calls, call)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recorded calls: %w", err)
+	}
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("no tool calls recorded in %s", path)
+	}
+	return calls, nil
+}
+
+// Options configure a benchmark run.
+type Options struct {
+	Iterations  int    // Times the recorded calls are replayed, 1 if zero
+	Concurrency int    // Calls made at once, 1 if zero; allocations are only attributed to tools at 1
+	ProfileDir  string // Directory cpu.pprof and allocs.pprof are written to, if set
+
+	// IsWrite reports the tools that change the cluster. Recorded calls of
+	// them are refused unless AllowWrites is set, as they would be replayed
+	// against the cluster on every iteration.
+	IsWrite     func(name string) bool
+	AllowWrites bool
+}
+
+// ToolStats are the measurements of the calls of one tool.
+type ToolStats struct {
+	Tool          string        `json:"tool"`
+	Calls         int           `json:"calls"`
+	Errors        int           `json:"errors"`
+	Mean          time.Duration `json:"mean"`
+	P50           time.Duration `json:"p50"`
+	P95           time.Duration `json:"p95"`
+	Max           time.Duration `json:"max"`
+	AllocsPerCall uint64        `json:"allocsPerCall,omitempty"`
+	BytesPerCall  uint64        `json:"bytesPerCall,omitempty"`
+	ResponseBytes int           `json:"responseBytes"` // Mean size of the encoded response
+}
+
+// Report is the outcome of a benchmark run.
+type Report struct {
+	Calls       int           `json:"calls"`
+	Errors      int           `json:"errors"`
+	Concurrency int           `json:"concurrency"`
+	Elapsed     time.Duration `json:"elapsed"`
+	Tools       []ToolStats   `json:"tools"`
+}
+
+// sample is the measurement of a single call.
+type sample struct {
+	latency  time.Duration
+	failed   bool
+	allocs   uint64
+	bytes    uint64
+	response int
+}
+
+// Run replays calls against s the configured number of times and measures
+// each call. Calls go through the server's request handling and tool handler
+// middlewares, as calls from a client do. A call fails if it returns a
+// JSON-RPC error or an error result. When ProfileDir is set, a CPU profile
+// with samples labelled by tool, viewable with
+// "go tool pprof -tagfocus tool=<name>", and an allocation profile are
+// written there.
+// Returns the report, or an error if a call changes the cluster and writes
+// are not allowed, the profiles cannot be written or ctx ends.
+func Run(ctx context.Context, s *server.MCPServer, calls []Call, opts Options) (*Report, error) {
+	iterations := max(opts.Iterations, 1)
+	concurrency := max(opts.Concurrency, 1)
+
+	if opts.IsWrite != nil && !opts.AllowWrites {
+		for i, call := range calls {
+			if opts.IsWrite(call.Name) {
+				return nil, fmt.Errorf("recorded call %d to %s changes the cluster and is not replayed unless writes are allowed", i+1, call.Name)
+			}
+		}
+	}
+
+	messages := make([]json.RawMessage, len(calls))
+	for i, call := range calls {
+		if call.Arguments == nil {
+			// Sent as an empty object, as clients do for tools without
+			// required parameters
+			call.Arguments = map[string]interface{}{}
+		}
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": mcp.JSONRPC_VERSION,
+			"id":      i + 1,
+			"method":  mcp.MethodToolsCall,
+			"params":  call,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode call %d: %w", i+1, err)
+		}
+		messages[i] = message
+	}
+
+	if opts.ProfileDir != "" {
+		if err := os.MkdirAll(opts.ProfileDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create profile directory: %w", err)
+		}
+		cpuProfile, err := os.Create(filepath.Join(opts.ProfileDir, "cpu.pprof"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		defer cpuProfile.Close()
+		if err := pprof.StartCPUProfile(cpuProfile); err != nil {
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	samples := make([][]sample, len(calls))
+	for i := range samples {
+		samples[i] = make([]sample, iterations)
+	}
+	measureAllocs := concurrency == 1
+
+	type job struct{ call, iteration int }
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				var sampled sample
+				pprof.Do(ctx, pprof.Labels("tool", calls[j.call].Name), func(ctx context.Context) {
+					sampled = measure(ctx, s, messages[j.call], measureAllocs)
+				})
+				samples[j.call][j.iteration] = sampled
+			}
+		}()
+	}
+replay:
+	for iteration := range iterations {
+		for call := range calls {
+			select {
+			case jobs <- job{call, iteration}:
+			case <-ctx.Done():
+				break replay
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if opts.ProfileDir != "" {
+		pprof.StopCPUProfile()
+		allocsProfile, err := os.Create(filepath.Join(opts.ProfileDir, "allocs.pprof"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create allocation profile: %w", err)
+		}
+		defer allocsProfile.Close()
+		if err := pprof.Lookup("allocs").WriteTo(allocsProfile, 0); err != nil {
+			return nil, fmt.Errorf("failed to write allocation profile: %w", err)
+		}
+	}
+
+	return newReport(calls, samples, concurrency, elapsed, measureAllocs), nil
+}
+
+// measure makes a call and measures its latency, including encoding the
+// response as a transport would, the size of the encoded response and, if
+// allocs is set, the allocations made while it ran.
+func measure(ctx context.Context, s *server.MCPServer, message json.RawMessage, allocs bool) sample {
+	var before, after runtime.MemStats
+	if allocs {
+		runtime.ReadMemStats(&before)
+	}
+	start := time.Now()
+	response := s.HandleMessage(ctx, message)
+	encoded, err := json.Marshal(response)
+	sampled := sample{latency: time.Since(start), response: len(encoded)}
+	if allocs {
+		runtime.ReadMemStats(&after)
+		sampled.allocs = after.Mallocs - before.Mallocs
+		sampled.bytes = after.TotalAlloc - before.TotalAlloc
+	}
+
+	sampled.failed = true
+	if response, ok := response.(mcp.JSONRPCResponse); ok && err == nil {
+		result, ok := response.Result.(mcp.CallToolResult)
+		sampled.failed = !ok || result.IsError
+	}
+	return sampled
+}
+
+// newReport aggregates the samples of each call into the stats of each tool,
+// slowest tool first.
+func newReport(calls []Call, samples [][]sample, concurrency int, elapsed time.Duration, allocs bool) *Report {
+	byTool := map[string][]sample{}
+	for i, call := range calls {
+		byTool[call.Name] = append(byTool[call.Name], samples[i]...)
+	}
+
+	report := &Report{Concurrency: concurrency, Elapsed: elapsed}
+	for tool, toolSamples := range byTool {
+		stats := ToolStats{Tool: tool, Calls: len(toolSamples)}
+		latencies := make([]time.Duration, len(toolSamples))
+		var total time.Duration
+		var totalAllocs, totalBytes uint64
+		var totalResponse int
+		for i, s := range toolSamples {
+			latencies[i] = s.latency
+			total += s.latency
+			totalAllocs += s.allocs
+			totalBytes += s.bytes
+			totalResponse += s.response
+			if s.failed {
+				stats.Errors++
+			}
+		}
+		slices.Sort(latencies)
+		stats.Mean = total / time.Duration(len(latencies))
+		stats.P50 = percentile(latencies, 50)
+		stats.P95 = percentile(latencies, 95)
+		stats.Max = latencies[len(latencies)-1]
+		stats.ResponseBytes = totalResponse / len(toolSamples)
+		if allocs {
+			stats.AllocsPerCall = totalAllocs / uint64(len(toolSamples))
+			stats.BytesPerCall = totalBytes / uint64(len(toolSamples))
+		}
+		report.Calls += stats.Calls
+		report.Errors += stats.Errors
+		report.Tools = append(report.Tools, stats)
+	}
+	slices.SortFunc(report.Tools, func(a, b ToolStats) int {
+		return cmp.Or(cmp.Compare(b.Mean, a.Mean), strings.Compare(a.Tool, b.Tool))
+	})
+	return report
+}
+
+// percentile returns the pth percentile of sorted latencies, by the nearest
+// rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// Write writes the report as a table, one row per tool.
+func (r *Report) Write(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TOOL\tCALLS\tERRORS\tMEAN\tP50\tP95\tMAX\tALLOCS/CALL\tBYTES/CALL\tRESPONSE BYTES")
+	for _, stats := range r.Tools {
+		allocs, bytes := "-", "-"
+		if r.Concurrency == 1 {
+			allocs, bytes = fmt.Sprint(stats.AllocsPerCall), fmt.Sprint(stats.BytesPerCall)
+		}
+		fmt.Fprintf(table, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			stats.Tool, stats.Calls, stats.Errors, roundDuration(stats.Mean), roundDuration(stats.P50),
+			roundDuration(stats.P95), roundDuration(stats.Max), allocs, bytes, stats.ResponseBytes)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	throughput := float64(r.Calls) / r.Elapsed.Seconds()
+	_, err := fmt.Fprintf(w, "\n%d calls, %d errors, in %s with concurrency %d (%.1f calls/s)\n",
+		r.Calls, r.Errors, roundDuration(r.Elapsed), r.Concurrency, throughput)
+	return err
+}
+
+// roundDuration rounds d to three significant digits for display.
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= 100*time.Millisecond:
+		return d.Round(time.Millisecond)
+	case d >= 100*time.Microsecond:
+		return d.Round(time.Microsecond)
+	default:
+		return d.Round(100 * time.Nanosecond)
+	}
+}
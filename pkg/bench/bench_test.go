@@ -0,0 +1,129 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestLoadCalls tests reading recorded calls from JSON-RPC traffic and plain call records
+func TestLoadCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.jsonl")
+	recorded := `# captured from a session
+{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}
+{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"listResources","arguments":{"kind":"Pod"}}}
+
+{"name":"getEvents","arguments":{"namespace":"web"}}
+`
+	if err := os.WriteFile(path, []byte(recorded), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	calls, err := LoadCalls(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0].Name != "listResources" || calls[0].Arguments["kind"] != "Pod" || calls[1].Name != "getEvents" {
+		t.Errorf("Expected the listResources and getEvents calls, got %+v", calls)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"arguments":{}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCalls(path); err == nil {
+		t.Error("Expected an error for a call naming no tool")
+	}
+}
+
+// TestRun tests replaying calls through a server and aggregating them per tool
+func TestRun(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strings.Repeat("x", 100)), nil
+	})
+	s.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, fmt.Errorf("failed")
+	})
+
+	calls := []Call{{Name: "echo"}, {Name: "fail"}, {Name: "echo", Arguments: map[string]interface{}{"a": 1}}, {Name: "missing"}}
+	dir := t.TempDir()
+	report, err := Run(context.Background(), s, calls, Options{Iterations: 3, ProfileDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Calls != 12 || report.Errors != 6 || len(report.Tools) != 3 {
+		t.Fatalf("Expected 12 calls of 3 tools with 6 errors, got %+v", report)
+	}
+	for _, stats := range report.Tools {
+		switch stats.Tool {
+		case "echo":
+			if stats.Calls != 6 || stats.Errors != 0 || stats.ResponseBytes < 100 || stats.AllocsPerCall == 0 {
+				t.Errorf("Expected 6 measured echo calls, got %+v", stats)
+			}
+		case "fail", "missing":
+			if stats.Calls != 3 || stats.Errors != 3 {
+				t.Errorf("Expected 3 failed %s calls, got %+v", stats.Tool, stats)
+			}
+		}
+		if stats.P50 > stats.P95 || stats.P95 > stats.Max {
+			t.Errorf("Expected ordered percentiles, got %+v", stats)
+		}
+	}
+	for _, profile := range []string{"cpu.pprof", "allocs.pprof"} {
+		if _, err := os.Stat(filepath.Join(dir, profile)); err != nil {
+			t.Errorf("Expected %s to be written: %v", profile, err)
+		}
+	}
+
+	var out strings.Builder
+	if err := report.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "echo") || !strings.Contains(out.String(), "12 calls, 6 errors") {
+		t.Errorf("Expected a row per tool and a summary, got:\n%s", out.String())
+	}
+
+	report, err = Run(context.Background(), s, calls[:1], Options{Iterations: 4, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Calls != 4 || report.Tools[0].AllocsPerCall != 0 {
+		t.Errorf("Expected 4 calls without allocations attributed, got %+v", report.Tools)
+	}
+}
+
+// TestRunWrites tests refusing to replay calls of mutating tools unless writes are allowed
+func TestRunWrites(t *testing.T) {
+	deleted := 0
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("listResources"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("[]"), nil
+	})
+	s.AddTool(mcp.NewTool("deleteResource"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		deleted++
+		return mcp.NewToolResultText("deleted"), nil
+	})
+	isWrite := func(name string) bool { return name == "deleteResource" }
+	calls := []Call{{Name: "listResources"}, {Name: "deleteResource"}}
+
+	_, err := Run(context.Background(), s, calls, Options{Iterations: 2, IsWrite: isWrite})
+	if err == nil || !strings.Contains(err.Error(), "recorded call 2 to deleteResource changes the cluster") {
+		t.Errorf("Expected the mutating call to be refused, got %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("Expected no call to be replayed, got %d deletes", deleted)
+	}
+
+	report, err := Run(context.Background(), s, calls, Options{Iterations: 2, IsWrite: isWrite, AllowWrites: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Calls != 4 || deleted != 2 {
+		t.Errorf("Expected the mutating call to be replayed once writes are allowed, got %+v and %d deletes", report, deleted)
+	}
+}
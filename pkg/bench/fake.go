@@ -0,0 +1,355 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// fakeResource is an API resource served by a FakeCluster.
+type fakeResource struct {
+	groupVersion string
+	name         string
+	kind         string
+	namespaced   bool
+}
+
+// builtinResources are served by every FakeCluster, with or without objects,
+// so that tools probing them find an empty list rather than a missing API.
+var builtinResources = []fakeResource{
+	{"v1", "pods", "Pod", true},
+	{"v1", "services", "Service", true},
+	{"v1", "endpoints", "Endpoints", true},
+	{"v1", "configmaps", "ConfigMap", true},
+	{"v1", "secrets", "Secret", true},
+	{"v1", "serviceaccounts", "ServiceAccount", true},
+	{"v1", "persistentvolumeclaims", "PersistentVolumeClaim", true},
+	{"v1", "events", "Event", true},
+	{"v1", "namespaces", "Namespace", false},
+	{"v1", "nodes", "Node", false},
+	{"v1", "persistentvolumes", "PersistentVolume", false},
+	{"apps/v1", "deployments", "Deployment", true},
+	{"apps/v1", "statefulsets", "StatefulSet", true},
+	{"apps/v1", "daemonsets", "DaemonSet", true},
+	{"apps/v1", "replicasets", "ReplicaSet", true},
+	{"batch/v1", "jobs", "Job", true},
+	{"batch/v1", "cronjobs", "CronJob", true},
+}
+
+// FakeCluster is an in-process API server serving a fixed set of objects, so
+// that recorded tool calls can be replayed without a cluster. It serves
+// discovery, and gets and lists of the objects filtered by namespace, label
+// selector and metadata field selectors. Everything else, including writes,
+// watches and subresources such as logs, is refused, and the calls making
+// them fail.
+type FakeCluster struct {
+	server    *httptest.Server
+	resources []fakeResource
+	objects   map[fakeResource][]map[string]interface{}
+}
+
+// LoadFixtures reads the objects a FakeCluster serves from a YAML or JSON
+// file. YAML files may hold several documents separated by "---", and List
+// objects are expanded into their items.
+// Returns the objects, or an error.
+func LoadFixtures(path string) ([]map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	defer file.Close()
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(file, 4096)
+	var objects []map[string]interface{}
+	for document := 1; ; document++ {
+		raw := map[string]interface{}{}
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse fixtures document %d: %w", document, err)
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: raw}
+		if !obj.IsList() {
+			objects = append(objects, raw)
+			continue
+		}
+		list, err := obj.ToList()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse fixtures document %d: %w", document, err)
+		}
+		for _, item := range list.Items {
+			objects = append(objects, item.Object)
+		}
+	}
+	return objects, nil
+}
+
+// NewFakeCluster starts a FakeCluster serving objects. Kinds other than the
+// common built-in ones are served under the group version of their objects,
+// and are namespaced if any of their objects has a namespace. Objects of
+// namespaced kinds without a namespace are placed in "default".
+// Returns the cluster, or an error if an object lacks apiVersion, kind or
+// name.
+func NewFakeCluster(objects []map[string]interface{}) (*FakeCluster, error) {
+	f := &FakeCluster{
+		resources: append([]fakeResource(nil), builtinResources...),
+		objects:   map[fakeResource][]map[string]interface{}{},
+	}
+	for i, raw := range objects {
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("fixture object %d: apiVersion, kind and metadata.name are required", i+1)
+		}
+		resource := f.resourceForKind(obj.GetAPIVersion(), obj.GetKind())
+		if resource == nil {
+			f.resources = append(f.resources, fakeResource{obj.GetAPIVersion(), pluralize(obj.GetKind()), obj.GetKind(), false})
+			resource = &f.resources[len(f.resources)-1]
+		}
+		if obj.GetNamespace() != "" {
+			resource.namespaced = true
+		}
+	}
+	for _, raw := range objects {
+		obj := &unstructured.Unstructured{Object: raw}
+		resource := f.resourceForKind(obj.GetAPIVersion(), obj.GetKind())
+		if !resource.namespaced {
+			obj.SetNamespace("")
+		} else if obj.GetNamespace() == "" {
+			obj.SetNamespace("default")
+		}
+		f.objects[*resource] = append(f.objects[*resource], obj.Object)
+	}
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f, nil
+}
+
+// URL returns the address of the cluster's API server.
+func (f *FakeCluster) URL() string {
+	return f.server.URL
+}
+
+// Close stops the cluster's API server.
+func (f *FakeCluster) Close() {
+	f.server.Close()
+}
+
+// WriteKubeconfig writes a kubeconfig file with a single context, "fake",
+// connecting to the cluster.
+// Returns an error if the file cannot be written.
+func (f *FakeCluster) WriteKubeconfig(path string) error {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["fake"] = &clientcmdapi.Cluster{Server: f.server.URL}
+	config.AuthInfos["fake"] = &clientcmdapi.AuthInfo{}
+	config.Contexts["fake"] = &clientcmdapi.Context{Cluster: "fake", AuthInfo: "fake"}
+	config.CurrentContext = "fake"
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return nil
+}
+
+// resourceForKind returns the resource serving kind in groupVersion, or nil.
+func (f *FakeCluster) resourceForKind(groupVersion, kind string) *fakeResource {
+	for i := range f.resources {
+		if f.resources[i].groupVersion == groupVersion && f.resources[i].kind == kind {
+			return &f.resources[i]
+		}
+	}
+	return nil
+}
+
+// resourceForName returns the resource named name in groupVersion, or nil.
+func (f *FakeCluster) resourceForName(groupVersion, name string) *fakeResource {
+	for i := range f.resources {
+		if f.resources[i].groupVersion == groupVersion && f.resources[i].name == name {
+			return &f.resources[i]
+		}
+	}
+	return nil
+}
+
+// serve handles a request to the cluster's API server.
+func (f *FakeCluster) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "the fake cluster is read-only")
+		return
+	}
+	if r.URL.Query().Get("watch") == "true" || r.URL.Query().Get("watch") == "1" {
+		writeStatus(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "the fake cluster does not support watches")
+		return
+	}
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/version":
+		writeJSON(w, map[string]interface{}{"major": "1", "minor": "34", "gitVersion": "v1.34.0", "platform": "linux/amd64"})
+	case r.URL.Path == "/api":
+		writeJSON(w, map[string]interface{}{"kind": "APIVersions", "versions": []string{"v1"}})
+	case r.URL.Path == "/apis":
+		writeJSON(w, f.groupList())
+	case path[0] == "api" && len(path) >= 2:
+		f.serveResources(w, r, path[1], path[2:])
+	case path[0] == "apis" && len(path) >= 3:
+		f.serveResources(w, r, path[1]+"/"+path[2], path[3:])
+	default:
+		writeStatus(w, http.StatusNotFound, "NotFound", fmt.Sprintf("the server could not find the requested resource %s", r.URL.Path))
+	}
+}
+
+// serveResources serves discovery of groupVersion, or the gets and lists of
+// its resources addressed by path.
+func (f *FakeCluster) serveResources(w http.ResponseWriter, r *http.Request, groupVersion string, path []string) {
+	if len(path) == 0 {
+		var resources []map[string]interface{}
+		for _, resource := range f.resources {
+			if resource.groupVersion == groupVersion {
+				resources = append(resources, map[string]interface{}{
+					"name":       resource.name,
+					"kind":       resource.kind,
+					"namespaced": resource.namespaced,
+					"verbs":      []string{"get", "list"},
+				})
+			}
+		}
+		if resources == nil {
+			writeStatus(w, http.StatusNotFound, "NotFound", fmt.Sprintf("the server could not find the requested resource %s", r.URL.Path))
+			return
+		}
+		writeJSON(w, map[string]interface{}{"kind": "APIResourceList", "groupVersion": groupVersion, "resources": resources})
+		return
+	}
+
+	namespace := ""
+	if len(path) >= 3 && path[0] == "namespaces" {
+		namespace, path = path[1], path[2:]
+	}
+	resource := f.resourceForName(groupVersion, path[0])
+	if resource == nil || len(path) > 2 || (namespace != "" && !resource.namespaced) {
+		writeStatus(w, http.StatusNotFound, "NotFound", fmt.Sprintf("the server could not find the requested resource %s", r.URL.Path))
+		return
+	}
+
+	if len(path) == 2 {
+		for _, obj := range f.objects[*resource] {
+			object := unstructured.Unstructured{Object: obj}
+			if object.GetName() == path[1] && object.GetNamespace() == namespace {
+				writeJSON(w, obj)
+				return
+			}
+		}
+		writeStatus(w, http.StatusNotFound, "NotFound", fmt.Sprintf("%s %q not found", resource.name, path[1]))
+		return
+	}
+
+	labelSelector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, "BadRequest", fmt.Sprintf("invalid label selector: %v", err))
+		return
+	}
+	fieldSelector, err := fields.ParseSelector(r.URL.Query().Get("fieldSelector"))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, "BadRequest", fmt.Sprintf("invalid field selector: %v", err))
+		return
+	}
+	items := []map[string]interface{}{}
+	for _, obj := range f.objects[*resource] {
+		object := unstructured.Unstructured{Object: obj}
+		if namespace != "" && object.GetNamespace() != namespace {
+			continue
+		}
+		metadataFields := fields.Set{"metadata.name": object.GetName(), "metadata.namespace": object.GetNamespace()}
+		if labelSelector.Matches(labels.Set(object.GetLabels())) && fieldSelector.Matches(metadataFields) {
+			items = append(items, obj)
+		}
+	}
+	writeJSON(w, map[string]interface{}{
+		"kind":       resource.kind + "List",
+		"apiVersion": groupVersion,
+		"metadata":   map[string]interface{}{"resourceVersion": "1"},
+		"items":      items,
+	})
+}
+
+// groupList returns the API groups of the cluster's resources, other than the
+// core group.
+func (f *FakeCluster) groupList() map[string]interface{} {
+	versions := map[string][]string{}
+	for _, resource := range f.resources {
+		group, version, ok := strings.Cut(resource.groupVersion, "/")
+		if !ok {
+			continue
+		}
+		if !slices.Contains(versions[group], version) {
+			versions[group] = append(versions[group], version)
+		}
+	}
+	names := make([]string, 0, len(versions))
+	for group := range versions {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	groups := []map[string]interface{}{}
+	for _, group := range names {
+		var groupVersions []map[string]interface{}
+		for _, version := range versions[group] {
+			groupVersions = append(groupVersions, map[string]interface{}{"groupVersion": group + "/" + version, "version": version})
+		}
+		groups = append(groups, map[string]interface{}{
+			"name":             group,
+			"versions":         groupVersions,
+			"preferredVersion": groupVersions[0],
+		})
+	}
+	return map[string]interface{}{"kind": "APIGroupList", "apiVersion": "v1", "groups": groups}
+}
+
+// pluralize returns the resource name of kind, following the English plural
+// rules the API server's defaults use.
+func pluralize(kind string) string {
+	name := strings.ToLower(kind)
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case len(name) > 1 && strings.HasSuffix(name, "y") && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou"):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeStatus writes a failure Status response, as the API server does.
+func writeStatus(w http.ResponseWriter, code int, reason, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"kind":       "Status",
+		"apiVersion": "v1",
+		"status":     "Failure",
+		"message":    message,
+		"reason":     reason,
+		"code":       code,
+	})
+}
@@ -0,0 +1,74 @@
+package bench
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+)
+
+// TestFakeCluster tests serving fixture objects to a client through a kubeconfig
+func TestFakeCluster(t *testing.T) {
+	objects := []map[string]interface{}{
+		{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{"name": "api", "namespace": "web", "labels": map[string]interface{}{"app": "api"}}},
+		{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{"name": "worker", "labels": map[string]interface{}{"app": "worker"}}},
+		{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": map[string]interface{}{"name": "w", "namespace": "web"}},
+	}
+	fake, err := NewFakeCluster(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := fake.WriteKubeconfig(kubeconfig); err != nil {
+		t.Fatal(err)
+	}
+	client, err := k8s.NewClient(kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	pods, err := client.ListResources(ctx, "Pod", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 2 {
+		t.Errorf("Expected 2 pods across namespaces, got %d", len(pods))
+	}
+	pods, err = client.ListResources(ctx, "Pod", "", "app=worker", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 1 || pods[0]["metadata"].(map[string]interface{})["namespace"] != "default" {
+		t.Errorf("Expected the worker pod in the default namespace, got %v", pods)
+	}
+	if pods, err := client.ListResources(ctx, "Pod", "web", "", "metadata.name=api"); err != nil || len(pods) != 1 {
+		t.Errorf("Expected the api pod, got %v, %v", pods, err)
+	}
+
+	widget, err := client.GetResource(ctx, "Widget", "w", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if widget["kind"] != "Widget" {
+		t.Errorf("Expected the widget, got %v", widget)
+	}
+	if _, err := client.GetResource(ctx, "Pod", "missing", "web"); err == nil {
+		t.Error("Expected an error for a missing pod")
+	}
+	if err := client.DeleteResource(ctx, "Pod", "api", "web", k8s.DeleteOptions{}); err == nil {
+		t.Error("Expected the fake cluster to refuse writes")
+	}
+}
+
+// TestPluralize tests deriving resource names from kinds
+func TestPluralize(t *testing.T) {
+	for kind, expected := range map[string]string{"Widget": "widgets", "Policy": "policies", "Gateway": "gateways", "Ingress": "ingresses", "Y": "ys"} {
+		if name := pluralize(kind); name != expected {
+			t.Errorf("Expected %s to be served as %s, got %s", kind, expected, name)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package export writes large tool results to a local directory or an
+// S3-compatible bucket, so that tools can return a reference to the output
+// instead of inlining it.
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Object is a tool result to export.
+type Object struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Reference describes where an exported object was written.
+type Reference struct {
+	Location    string `json:"location"`
+	Bytes       int    `json:"bytes"`
+	SHA256      string `json:"sha256"`
+	ContentType string `json:"contentType"`
+}
+
+// Exporter writes objects to a storage target.
+type Exporter interface {
+	// Export writes the object and returns a reference to it, or an error.
+	Export(ctx context.Context, object Object) (Reference, error)
+}
+
+// newReference returns the reference to object stored at location.
+func newReference(location string, object Object) Reference {
+	sum := sha256.Sum256(object.Data)
+	return Reference{
+		Location:    location,
+		Bytes:       len(object.Data),
+		SHA256:      hex.EncodeToString(sum[:]),
+		ContentType: object.ContentType,
+	}
+}
+
+// FileExporter writes objects as files in a local directory.
+type FileExporter struct {
+	Dir string
+}
+
+// NewFileExporter creates a FileExporter for dir, creating the directory if
+// needed.
+// Returns the exporter, or an error if the directory cannot be created.
+func NewFileExporter(dir string) (*FileExporter, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve export directory: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	return &FileExporter{Dir: abs}, nil
+}
+
+// Export writes the object to a file named after it in the export directory.
+// Returns a file:// reference to the written file, or an error.
+func (e *FileExporter) Export(ctx context.Context, object Object) (Reference, error) {
+	if object.Name == "" || filepath.Base(object.Name) != object.Name {
+		return Reference{}, fmt.Errorf("invalid export name %q", object.Name)
+	}
+	target := filepath.Join(e.Dir, object.Name)
+
+	tmp, err := os.CreateTemp(e.Dir, ".export-*")
+	if err != nil {
+		return Reference{}, fmt.Errorf("failed to write export: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(object.Data); err != nil {
+		tmp.Close()
+		return Reference{}, fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return Reference{}, fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return Reference{}, fmt.Errorf("failed to write export: %w", err)
+	}
+	return newReference("file://"+filepath.ToSlash(target), object), nil
+}
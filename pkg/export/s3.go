@@ -0,0 +1,193 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Exporter uploads objects to an S3-compatible bucket, signing requests with
+// AWS Signature Version 4.
+type S3Exporter struct {
+	Bucket       string
+	Prefix       string
+	Endpoint     *url.URL
+	PathStyle    bool
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	HTTPClient   *http.Client
+}
+
+// NewS3Exporter creates an S3Exporter for a target of the form
+// s3://bucket/prefix. Credentials and region are read from the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION
+// (or AWS_DEFAULT_REGION) environment variables. A custom endpoint for other
+// S3-compatible stores, addressed path-style, is read from AWS_ENDPOINT_URL_S3
+// or AWS_ENDPOINT_URL.
+// Returns the exporter, or an error if the target or credentials are invalid.
+func NewS3Exporter(target string) (*S3Exporter, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 export target %q: expected s3://bucket/prefix", target)
+	}
+	e := &S3Exporter{
+		Bucket:       u.Host,
+		Prefix:       strings.Trim(u.Path, "/"),
+		Region:       firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		HTTPClient:   &http.Client{Timeout: 5 * time.Minute},
+	}
+	if e.AccessKey == "" || e.SecretKey == "" {
+		return nil, fmt.Errorf("S3 export requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	if e.Region == "" {
+		e.Region = "us-east-1"
+	}
+
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		if e.Endpoint, err = url.Parse(endpoint); err != nil || e.Endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+		}
+		e.PathStyle = true
+	} else {
+		e.Endpoint = &url.URL{Scheme: "https", Host: fmt.Sprintf("s3.%s.amazonaws.com", e.Region)}
+	}
+	return e, nil
+}
+
+// Export uploads the object under the exporter's prefix.
+// Returns an s3:// reference to the uploaded object, or an error.
+func (e *S3Exporter) Export(ctx context.Context, object Object) (Reference, error) {
+	key := object.Name
+	if e.Prefix != "" {
+		key = e.Prefix + "/" + object.Name
+	}
+
+	target := *e.Endpoint
+	if e.PathStyle {
+		target.Path = strings.TrimSuffix(target.Path, "/") + "/" + e.Bucket + "/" + key
+	} else {
+		target.Host = e.Bucket + "." + target.Host
+		target.Path = "/" + key
+	}
+	target.RawPath = uriEncodePath(target.Path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(object.Data))
+	if err != nil {
+		return Reference{}, fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", object.ContentType)
+	e.sign(req, object.Data, time.Now().UTC())
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return Reference{}, fmt.Errorf("failed to upload export: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Reference{}, fmt.Errorf("failed to upload export: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return newReference(fmt.Sprintf("s3://%s/%s", e.Bucket, key), object), nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req.
+func (e *S3Exporter) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if e.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", e.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncodePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + e.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+e.SecretKey), date)
+	for _, part := range []string{e.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		e.AccessKey, scope, signedHeaders, signature))
+}
+
+// uriEncodePath encodes a path as SigV4 canonical requests require: every
+// byte except unreserved characters and slashes is percent-encoded.
+func uriEncodePath(path string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || strings.IndexByte("-_.~/", c) >= 0 {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// firstEnv returns the first non-empty value of the named environment
+// variables.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
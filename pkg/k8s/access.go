@@ -0,0 +1,166 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessRequest is an action to check with CanI or WhoCan: a verb on a
+// resource, or on a non-resource Path such as /metrics.
+type AccessRequest struct {
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	Name        string
+	Namespace   string
+	Path        string
+}
+
+// CanI checks whether the client may perform an action, like
+// `kubectl auth can-i`. It asks the API server with a SelfSubjectAccessReview,
+// or, when the client impersonates a user, with a SubjectAccessReview for that
+// user and groups sent with the impersonator's credentials, which need not be
+// allowed to impersonate. A resource given without a group is resolved through
+// discovery, so kinds and short names are accepted. When the action is allowed
+// the rules granting it are looked up with a SelfSubjectRulesReview; rules the
+// client may not review are reported in "errors".
+// Returns a map with the "request", whether it is "allowed" or explicitly
+// "denied", the authorizer's "reason", the "matchingRules" that grant it or
+// the "missingRule" that would, and "errors", or an error.
+func (c *Client) CanI(ctx context.Context, request AccessRequest) (map[string]interface{}, error) {
+	request = c.resolveAccessRequest(request)
+	spec := authorizationv1.SubjectAccessReviewSpec{}
+	if request.Path != "" {
+		spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{Path: request.Path, Verb: request.Verb}
+	} else {
+		spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace:   request.Namespace,
+			Verb:        request.Verb,
+			Group:       request.Group,
+			Resource:    request.Resource,
+			Subresource: request.Subresource,
+			Name:        request.Name,
+		}
+	}
+
+	var status authorizationv1.SubjectAccessReviewStatus
+	result := map[string]interface{}{}
+	if c.impersonating != "" && c.impersonator != nil {
+		spec.User, spec.Groups = c.impersonating, c.impersonatedAs
+		review, err := c.impersonator.clientset.AuthorizationV1().SubjectAccessReviews().Create(ctx,
+			&authorizationv1.SubjectAccessReview{Spec: spec}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review access of %s: %w", c.impersonating, err)
+		}
+		status = review.Status
+		result["review"] = "SubjectAccessReview"
+		result["user"] = c.impersonating
+		result["groups"] = c.impersonatedAs
+	} else {
+		review, err := c.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx,
+			&authorizationv1.SelfSubjectAccessReview{Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes:    spec.ResourceAttributes,
+				NonResourceAttributes: spec.NonResourceAttributes,
+			}}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review access: %w", err)
+		}
+		status = review.Status
+		result["review"] = "SelfSubjectAccessReview"
+	}
+
+	result["request"] = forbiddenRequestSummary(request.forbiddenRequest())
+	result["allowed"] = status.Allowed
+	result["denied"] = status.Denied
+	if status.Reason != "" {
+		result["reason"] = status.Reason
+	}
+	if status.EvaluationError != "" {
+		result["evaluationError"] = status.EvaluationError
+	}
+
+	errs := []string{}
+	if status.Allowed {
+		rules, err := c.matchingRules(ctx, request)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("selfSubjectRulesReview: %v", err))
+		} else {
+			result["matchingRules"] = rules
+		}
+	} else {
+		result["missingRule"] = missingRule(request.forbiddenRequest())
+	}
+	result["errors"] = errs
+	return result, nil
+}
+
+// matchingRules returns the rules of the client's identity in the request's
+// namespace, or the default namespace for cluster-scoped requests, that allow
+// the request. The rules come from a SelfSubjectRulesReview, which only
+// authorizers able to list rules, such as RBAC, contribute to, so an allowed
+// request may have none.
+func (c *Client) matchingRules(ctx context.Context, request AccessRequest) ([]rbacv1.PolicyRule, error) {
+	namespace := request.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	review, err := c.clientset.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx,
+		&authorizationv1.SelfSubjectRulesReview{Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace}}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	permission := request.permission()
+	rules := []rbacv1.PolicyRule{}
+	for _, rule := range review.Status.ResourceRules {
+		policyRule := rbacv1.PolicyRule{Verbs: rule.Verbs, APIGroups: rule.APIGroups, Resources: rule.Resources, ResourceNames: rule.ResourceNames}
+		if rulesAllow([]rbacv1.PolicyRule{policyRule}, permission) {
+			rules = append(rules, policyRule)
+		}
+	}
+	for _, rule := range review.Status.NonResourceRules {
+		policyRule := rbacv1.PolicyRule{Verbs: rule.Verbs, NonResourceURLs: rule.NonResourceURLs}
+		if rulesAllow([]rbacv1.PolicyRule{policyRule}, permission) {
+			rules = append(rules, policyRule)
+		}
+	}
+	return rules, nil
+}
+
+// resolveAccessRequest splits a subresource given as part of the resource,
+// e.g. deployments/scale, and resolves a resource given without a group
+// through discovery, leaving unknown resources and wildcards as they are.
+func (c *Client) resolveAccessRequest(request AccessRequest) AccessRequest {
+	if resource, subresource, ok := strings.Cut(request.Resource, "/"); ok && request.Subresource == "" {
+		request.Resource, request.Subresource = resource, subresource
+	}
+	if request.Group == "" && request.Resource != "" && request.Resource != rbacv1.ResourceAll {
+		if info, err := c.getCachedResource(request.Resource); err == nil {
+			request.Group, request.Resource = info.gvr.Group, info.gvr.Resource
+		}
+	}
+	return request
+}
+
+// forbiddenRequest returns the request in the form DiagnoseForbidden
+// describes refused requests in.
+func (r AccessRequest) forbiddenRequest() ForbiddenRequest {
+	return ForbiddenRequest{
+		Verb: r.Verb, Group: r.Group, Resource: r.Resource, Subresource: r.Subresource,
+		Name: r.Name, Namespace: r.Namespace, Path: r.Path,
+	}
+}
+
+// permission returns the request as the permission a PolicyRule must grant.
+func (r AccessRequest) permission() rbacPermission {
+	if r.Path != "" {
+		return rbacPermission{nonResourceURL: r.Path, verb: r.Verb}
+	}
+	return rbacPermission{apiGroup: r.Group, resource: missingRule(r.forbiddenRequest()).Resources[0], resourceName: r.Name, verb: r.Verb}
+}
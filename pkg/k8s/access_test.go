@@ -0,0 +1,92 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// TestCanI tests access checks with self and subject access reviews and the rules they match
+func TestCanI(t *testing.T) {
+	var reviews []authorizationv1.SubjectAccessReviewSpec
+	var impersonated []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}],` +
+				`"preferredVersion":{"groupVersion":"apps/v1","version":"v1"}}]}`))
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[` +
+				`{"name":"pods","namespaced":true,"kind":"Pod","verbs":["get","list"],"shortNames":["po"]}]}`))
+		case "/apis/apps/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[` +
+				`{"name":"deployments","namespaced":true,"kind":"Deployment","verbs":["get","list","patch"],"shortNames":["deploy"]}]}`))
+		case "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", "/apis/authorization.k8s.io/v1/subjectaccessreviews":
+			impersonated = append(impersonated, r.Header.Get("Impersonate-User"))
+			var review authorizationv1.SubjectAccessReview
+			json.NewDecoder(r.Body).Decode(&review)
+			reviews = append(reviews, review.Spec)
+			allowed := review.Spec.ResourceAttributes != nil && review.Spec.ResourceAttributes.Resource == "deployments"
+			review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: allowed, Reason: `RBAC: allowed by RoleBinding "deployer/web"`}
+			json.NewEncoder(w).Encode(review)
+		case "/apis/authorization.k8s.io/v1/selfsubjectrulesreviews":
+			w.Write([]byte(`{"kind":"SelfSubjectRulesReview","apiVersion":"authorization.k8s.io/v1","status":{"resourceRules":[` +
+				`{"verbs":["get","list"],"apiGroups":["apps"],"resources":["deployments"]},` +
+				`{"verbs":["patch"],"apiGroups":["apps"],"resources":["deployments"],"resourceNames":["api"]},` +
+				`{"verbs":["*"],"apiGroups":[""],"resources":["configmaps"]}],"nonResourceRules":[],"incomplete":false}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	result, err := client.CanI(ctx, AccessRequest{Verb: "patch", Resource: "deploy", Name: "api", Namespace: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["allowed"] != true || result["review"] != "SelfSubjectAccessReview" || result["reason"] == nil {
+		t.Errorf("Expected the patch to be allowed by a self review, got %v", result)
+	}
+	attributes := reviews[0].ResourceAttributes
+	if attributes == nil || attributes.Group != "apps" || attributes.Resource != "deployments" || attributes.Name != "api" {
+		t.Errorf("Expected the short name to be resolved before the review, got %+v", attributes)
+	}
+	rules, _ := result["matchingRules"].([]rbacv1.PolicyRule)
+	if len(rules) != 1 || len(rules[0].ResourceNames) != 1 || rules[0].ResourceNames[0] != "api" {
+		t.Errorf("Expected only the rule for the named deployment to match, got %v", result["matchingRules"])
+	}
+
+	result, err = client.CanI(ctx, AccessRequest{Verb: "get", Resource: "pods/log", Namespace: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, _ := result["missingRule"].(rbacv1.PolicyRule)
+	if result["allowed"] != false || len(missing.Resources) != 1 || missing.Resources[0] != "pods/log" {
+		t.Errorf("Expected the denied subresource to name the missing rule, got %v", result)
+	}
+	if attributes := reviews[1].ResourceAttributes; attributes.Resource != "pods" || attributes.Subresource != "log" {
+		t.Errorf("Expected the subresource to be split off, got %+v", attributes)
+	}
+
+	jane, err := client.Impersonate("jane", []string{"devs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = jane.CanI(ctx, AccessRequest{Verb: "list", Group: "apps", Resource: "deployments", Namespace: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["review"] != "SubjectAccessReview" || result["user"] != "jane" {
+		t.Errorf("Expected a subject access review for the impersonated user, got %v", result)
+	}
+	if review := reviews[2]; review.User != "jane" || len(review.Groups) != 1 || review.Groups[0] != "devs" || impersonated[2] != "" {
+		t.Errorf("Expected the review to name jane and be sent without impersonation, got %+v sent as %q", review, impersonated[2])
+	}
+}
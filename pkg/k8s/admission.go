@@ -0,0 +1,401 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxAdmissionChanges caps the number of changes reported per object.
+const maxAdmissionChanges = 200
+
+// admissionWebhook is a webhook of a Mutating- or ValidatingWebhookConfiguration.
+type admissionWebhook struct {
+	configuration     string
+	name              string
+	rules             []admissionregistrationv1.RuleWithOperations
+	namespaceSelector *metav1.LabelSelector
+	objectSelector    *metav1.LabelSelector
+}
+
+// PreviewAdmission submits the objects of a YAML or JSON manifest as
+// server-side dry runs and reports what defaulting and mutating admission
+// would make of them, without persisting anything. For each object it
+// returns the admitted object and the "changes" from the submitted manifest,
+// the containers admission would add, and, for objects that already exist,
+// the changes applying would make to the live object. Objects admission
+// rejects carry the "error" instead. For workloads with a pod template, a pod
+// built from the template is dry-run created as well, since webhooks such as
+// sidecar injectors act on pods rather than their controllers. The webhooks
+// whose rules match each object are listed; their namespace and object
+// selectors are not evaluated.
+// Returns a map with the preview of each object under "objects", or an error
+// if the manifest cannot be decoded.
+func (c *Client) PreviewAdmission(ctx context.Context, namespace, manifest string, includeObject bool) (map[string]interface{}, error) {
+	objects, err := decodeManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := []string{}
+	mutating, validating, err := c.listAdmissionWebhooks(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("webhooks: %v", err))
+	}
+
+	previews := []map[string]interface{}{}
+	for _, obj := range objects {
+		preview := map[string]interface{}{"kind": obj.GetKind(), "name": obj.GetName()}
+		resource, err := c.applyResourceClient(obj, namespace)
+		if err != nil {
+			preview["error"] = err.Error()
+			previews = append(previews, preview)
+			continue
+		}
+		if obj.GetNamespace() != "" {
+			preview["namespace"] = obj.GetNamespace()
+		}
+		gv, _ := schema.ParseGroupVersion(obj.GetAPIVersion())
+		info, _ := c.getCachedResource(obj.GetKind() + groupSuffix(gv.Group))
+
+		operation := admissionregistrationv1.Create
+		live, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		switch {
+		case err == nil:
+			operation = admissionregistrationv1.Update
+		case !apierrors.IsNotFound(err):
+			preview["error"] = fmt.Sprintf("failed to read the live object: %v", err)
+			previews = append(previews, preview)
+			continue
+		}
+		preview["operation"] = strings.ToLower(string(operation))
+		if info != nil {
+			gvr := info.gvr
+			gvr.Version = gv.Version
+			preview["mutatingWebhooks"] = matchingWebhooks(mutating, operation, gvr, info.namespaced)
+			preview["validatingWebhooks"] = matchingWebhooks(validating, operation, gvr, info.namespaced)
+		}
+
+		submitted := cleanManifest(obj)
+		admitted, err := resource.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: DefaultFieldManager,
+			Force:        true,
+			DryRun:       []string{metav1.DryRunAll},
+		})
+		if err != nil {
+			preview["rejected"] = true
+			preview["error"] = err.Error()
+			previews = append(previews, preview)
+			continue
+		}
+		result := cleanManifest(admitted)
+
+		preview["changes"], preview["truncated"] = limitChanges(diffManifests("", submitted, result))
+		if injected := injectedContainers(submitted, result); len(injected) > 0 {
+			preview["injectedContainers"] = injected
+		}
+		if live != nil {
+			preview["changesToLive"], _ = limitChanges(diffManifests("", cleanManifest(live), result))
+		}
+		if includeObject {
+			preview["object"] = result
+		}
+		if pod := c.previewTemplatePod(ctx, admitted, mutating, validating, includeObject); pod != nil {
+			preview["pod"] = pod
+		}
+		previews = append(previews, preview)
+	}
+
+	return map[string]interface{}{
+		"objects": previews,
+		"errors":  errs,
+	}, nil
+}
+
+// previewTemplatePod dry-run creates a pod from the pod template of a
+// workload and reports what admission makes of it. It returns nil for
+// objects without a pod template.
+func (c *Client) previewTemplatePod(ctx context.Context, workload *unstructured.Unstructured, mutating, validating []admissionWebhook, includeObject bool) map[string]interface{} {
+	template, found, _ := unstructured.NestedMap(workload.Object, "spec", "template")
+	if !found {
+		template, found, _ = unstructured.NestedMap(workload.Object, "spec", "jobTemplate", "spec", "template")
+	}
+	if !found {
+		return nil
+	}
+
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"spec":       template["spec"],
+	}}
+	if metadata, ok := template["metadata"].(map[string]interface{}); ok {
+		pod.Object["metadata"] = metadata
+	}
+	pod.SetName(workload.GetName() + "-admission-preview")
+	pod.SetNamespace(workload.GetNamespace())
+
+	podsGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	preview := map[string]interface{}{
+		"mutatingWebhooks":   matchingWebhooks(mutating, admissionregistrationv1.Create, podsGVR, true),
+		"validatingWebhooks": matchingWebhooks(validating, admissionregistrationv1.Create, podsGVR, true),
+	}
+	submitted := cleanManifest(pod)
+	admitted, err := c.dynamicClient.Resource(podsGVR).Namespace(pod.GetNamespace()).Create(ctx, pod, metav1.CreateOptions{
+		FieldManager: DefaultFieldManager,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		preview["rejected"] = true
+		preview["error"] = err.Error()
+		return preview
+	}
+	result := cleanManifest(admitted)
+	preview["changes"], preview["truncated"] = limitChanges(diffManifests("", submitted, result))
+	if injected := injectedContainers(submitted, result); len(injected) > 0 {
+		preview["injectedContainers"] = injected
+	}
+	if includeObject {
+		preview["object"] = result
+	}
+	return preview
+}
+
+// listAdmissionWebhooks lists the mutating and validating admission webhooks
+// of the cluster.
+func (c *Client) listAdmissionWebhooks(ctx context.Context) (mutating, validating []admissionWebhook, err error) {
+	mutatingConfigs, err := c.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, config := range mutatingConfigs.Items {
+		for _, webhook := range config.Webhooks {
+			mutating = append(mutating, admissionWebhook{config.Name, webhook.Name, webhook.Rules, webhook.NamespaceSelector, webhook.ObjectSelector})
+		}
+	}
+	validatingConfigs, err := c.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return mutating, nil, err
+	}
+	for _, config := range validatingConfigs.Items {
+		for _, webhook := range config.Webhooks {
+			validating = append(validating, admissionWebhook{config.Name, webhook.Name, webhook.Rules, webhook.NamespaceSelector, webhook.ObjectSelector})
+		}
+	}
+	return mutating, validating, nil
+}
+
+// matchingWebhooks returns the webhooks whose rules match an operation on a
+// resource. Webhooks with a namespace or object selector are marked
+// "conditional", as their selectors are not evaluated.
+func matchingWebhooks(webhooks []admissionWebhook, operation admissionregistrationv1.OperationType, gvr schema.GroupVersionResource, namespaced bool) []map[string]interface{} {
+	matched := []map[string]interface{}{}
+	for _, webhook := range webhooks {
+		for _, rule := range webhook.rules {
+			if !ruleMatches(rule, operation, gvr.Group, gvr.Version, gvr.Resource, namespaced) {
+				continue
+			}
+			summary := map[string]interface{}{"configuration": webhook.configuration, "name": webhook.name}
+			if !emptySelector(webhook.namespaceSelector) || !emptySelector(webhook.objectSelector) {
+				summary["conditional"] = true
+			}
+			matched = append(matched, summary)
+			break
+		}
+	}
+	return matched
+}
+
+// ruleMatches reports whether a webhook rule matches an operation on a
+// resource, which may carry a subresource, e.g. "pods/status", following the
+// matching rules of the API server.
+func ruleMatches(rule admissionregistrationv1.RuleWithOperations, operation admissionregistrationv1.OperationType, group, version, resource string, namespaced bool) bool {
+	operationMatches := false
+	for _, op := range rule.Operations {
+		if op == operation || op == admissionregistrationv1.OperationAll {
+			operationMatches = true
+		}
+	}
+	if !operationMatches || !matchesRBAC(rule.APIGroups, group) || !matchesRBAC(rule.APIVersions, version) {
+		return false
+	}
+	if rule.Scope != nil {
+		switch *rule.Scope {
+		case admissionregistrationv1.NamespacedScope:
+			if !namespaced {
+				return false
+			}
+		case admissionregistrationv1.ClusterScope:
+			if namespaced {
+				return false
+			}
+		}
+	}
+	main, sub, _ := strings.Cut(resource, "/")
+	for _, r := range rule.Resources {
+		ruleMain, ruleSub, _ := strings.Cut(r, "/")
+		if (ruleMain == "*" || ruleMain == main) && (ruleSub == sub || (ruleSub == "*" && sub != "")) {
+			return true
+		}
+		if r == "*/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// emptySelector reports whether a label selector selects everything.
+func emptySelector(selector *metav1.LabelSelector) bool {
+	return selector == nil || (len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0)
+}
+
+// groupSuffix returns ".group" for a non-core API group, as used to resolve
+// kinds unambiguously.
+func groupSuffix(group string) string {
+	if group == "" {
+		return ""
+	}
+	return "." + group
+}
+
+// diffManifests lists the fields of after that differ from before, as
+// changes with a field path, an "op" of added, changed or removed, and the
+// values. Lists of objects with names, such as containers, are matched by
+// name and other lists by index.
+func diffManifests(path string, before, after interface{}) []map[string]interface{} {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	switch afterValue := after.(type) {
+	case map[string]interface{}:
+		beforeMap, ok := before.(map[string]interface{})
+		if !ok {
+			break
+		}
+		var changes []map[string]interface{}
+		for _, key := range unionKeys(beforeMap, afterValue) {
+			b, inBefore := beforeMap[key]
+			a, inAfter := afterValue[key]
+			fieldPath := joinFieldPath(path, key)
+			switch {
+			case !inBefore:
+				changes = append(changes, map[string]interface{}{"path": fieldPath, "op": "added", "value": a})
+			case !inAfter:
+				changes = append(changes, map[string]interface{}{"path": fieldPath, "op": "removed", "previous": b})
+			default:
+				changes = append(changes, diffManifests(fieldPath, b, a)...)
+			}
+		}
+		return changes
+	case []interface{}:
+		beforeList, ok := before.([]interface{})
+		if !ok {
+			break
+		}
+		beforeNamed, afterNamed := namedItems(beforeList), namedItems(afterValue)
+		if beforeNamed != nil && afterNamed != nil {
+			var changes []map[string]interface{}
+			for _, name := range unionKeys(beforeNamed, afterNamed) {
+				b, inBefore := beforeNamed[name]
+				a, inAfter := afterNamed[name]
+				itemPath := fmt.Sprintf("%s[name=%s]", path, name)
+				switch {
+				case !inBefore:
+					changes = append(changes, map[string]interface{}{"path": itemPath, "op": "added", "value": a})
+				case !inAfter:
+					changes = append(changes, map[string]interface{}{"path": itemPath, "op": "removed", "previous": b})
+				default:
+					changes = append(changes, diffManifests(itemPath, b, a)...)
+				}
+			}
+			return changes
+		}
+		if len(beforeList) == len(afterValue) {
+			var changes []map[string]interface{}
+			for i := range afterValue {
+				changes = append(changes, diffManifests(fmt.Sprintf("%s[%d]", path, i), beforeList[i], afterValue[i])...)
+			}
+			return changes
+		}
+	}
+	return []map[string]interface{}{{"path": path, "op": "changed", "previous": before, "value": after}}
+}
+
+// namedItems indexes a list of objects by their name, or returns nil if any
+// item is not an object with a unique name.
+func namedItems(items []interface{}) map[string]interface{} {
+	named := map[string]interface{}{}
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		name, ok := object["name"].(string)
+		if !ok || name == "" {
+			return nil
+		}
+		if _, duplicate := named[name]; duplicate {
+			return nil
+		}
+		named[name] = item
+	}
+	return named
+}
+
+// joinFieldPath appends a field to a dotted field path.
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// limitChanges caps changes at maxAdmissionChanges, reporting whether any
+// were dropped.
+func limitChanges(changes []map[string]interface{}) ([]map[string]interface{}, bool) {
+	if changes == nil {
+		changes = []map[string]interface{}{}
+	}
+	if len(changes) > maxAdmissionChanges {
+		return changes[:maxAdmissionChanges], true
+	}
+	return changes, false
+}
+
+// injectedContainers returns the names of the containers and init containers
+// in the pod spec of after, or of its pod template, that before lacks.
+func injectedContainers(before, after map[string]interface{}) []string {
+	names := func(manifest map[string]interface{}) map[string]bool {
+		found := map[string]bool{}
+		for _, specPath := range [][]string{{"spec"}, {"spec", "template", "spec"}, {"spec", "jobTemplate", "spec", "template", "spec"}} {
+			for _, field := range []string{"initContainers", "containers"} {
+				containers, _, _ := unstructured.NestedSlice(manifest, append(specPath, field)...)
+				for _, container := range containers {
+					if c, ok := container.(map[string]interface{}); ok {
+						if name, ok := c["name"].(string); ok {
+							found[field+"/"+name] = true
+						}
+					}
+				}
+			}
+		}
+		return found
+	}
+	beforeNames := names(before)
+	var injected []string
+	for name := range names(after) {
+		if !beforeNames[name] {
+			injected = append(injected, name)
+		}
+	}
+	sort.Strings(injected)
+	return injected
+}
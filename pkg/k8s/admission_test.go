@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+)
+
+// TestDiffManifests tests listing the fields admission added or changed
+func TestDiffManifests(t *testing.T) {
+	submitted := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:1"},
+			},
+		},
+	}
+	admitted := map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{"sidecar.istio.io/status": "injected"}},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "registry.local/app:1", "imagePullPolicy": "IfNotPresent"},
+				map[string]interface{}{"name": "istio-proxy", "image": "proxyv2"},
+			},
+		},
+	}
+
+	changes := diffManifests("", submitted, admitted)
+	want := []map[string]interface{}{
+		{"path": "metadata", "op": "added", "value": admitted["metadata"]},
+		{"path": "spec.containers[name=app].image", "op": "changed", "previous": "app:1", "value": "registry.local/app:1"},
+		{"path": "spec.containers[name=app].imagePullPolicy", "op": "added", "value": "IfNotPresent"},
+		{"path": "spec.containers[name=istio-proxy]", "op": "added", "value": map[string]interface{}{"name": "istio-proxy", "image": "proxyv2"}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("diffManifests() = %v, want %v", changes, want)
+	}
+
+	if injected := injectedContainers(submitted, admitted); !reflect.DeepEqual(injected, []string{"containers/istio-proxy"}) {
+		t.Errorf("Unexpected injected containers %v", injected)
+	}
+	if changes := diffManifests("", submitted, submitted); len(changes) != 0 {
+		t.Errorf("Expected no changes for identical manifests, got %v", changes)
+	}
+}
+
+// TestRuleMatches tests matching webhook rules against an operation on a resource
+func TestRuleMatches(t *testing.T) {
+	namespaced := admissionregistrationv1.NamespacedScope
+	rule := func(operation admissionregistrationv1.OperationType, groups, resources []string) admissionregistrationv1.RuleWithOperations {
+		return admissionregistrationv1.RuleWithOperations{
+			Operations: []admissionregistrationv1.OperationType{operation},
+			Rule:       admissionregistrationv1.Rule{APIGroups: groups, APIVersions: []string{"*"}, Resources: resources, Scope: &namespaced},
+		}
+	}
+	tests := []struct {
+		name     string
+		rule     admissionregistrationv1.RuleWithOperations
+		resource string
+		want     bool
+	}{
+		{"exact", rule(admissionregistrationv1.Create, []string{""}, []string{"pods"}), "pods", true},
+		{"other operation", rule(admissionregistrationv1.Update, []string{""}, []string{"pods"}), "pods", false},
+		{"all operations", rule(admissionregistrationv1.OperationAll, []string{"*"}, []string{"*"}), "pods", true},
+		{"subresource not matched by main", rule(admissionregistrationv1.Create, []string{""}, []string{"pods"}), "pods/ephemeralcontainers", false},
+		{"subresource wildcard", rule(admissionregistrationv1.Create, []string{""}, []string{"pods/*"}), "pods/ephemeralcontainers", true},
+		{"everything", rule(admissionregistrationv1.Create, []string{""}, []string{"*/*"}), "pods/status", true},
+	}
+	for _, tt := range tests {
+		if got := ruleMatches(tt.rule, admissionregistrationv1.Create, "", "v1", tt.resource, true); got != tt.want {
+			t.Errorf("%s: ruleMatches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if ruleMatches(rule(admissionregistrationv1.Create, []string{""}, []string{"*"}), admissionregistrationv1.Create, "", "v1", "nodes", false) {
+		t.Error("Expected a namespaced rule not to match a cluster-scoped resource")
+	}
+}
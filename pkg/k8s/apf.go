@@ -0,0 +1,233 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// prometheusLabelPattern matches one label of a Prometheus sample.
+var prometheusLabelPattern = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\]|\\.)*)"`)
+
+// apfPriorityLevelMetrics are the API server metrics reported per priority
+// level, keyed by the name they are reported under.
+var apfPriorityLevelMetrics = map[string]string{
+	"rejectedRequests":  "apiserver_flowcontrol_rejected_requests_total",
+	"inQueueRequests":   "apiserver_flowcontrol_current_inqueue_requests",
+	"executingRequests": "apiserver_flowcontrol_current_executing_requests",
+	"executingSeats":    "apiserver_flowcontrol_current_executing_seats",
+	"nominalLimitSeats": "apiserver_flowcontrol_nominal_limit_seats",
+}
+
+// prometheusSample is one sample of a Prometheus text exposition.
+type prometheusSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// GetFlowControl reports API Priority and Fairness (APF): the
+// PriorityLevelConfigurations with their concurrency shares and queuing, the
+// FlowSchemas in matching order with the priority level each routes to, and,
+// from the API server's /metrics, the requests currently queued and executing
+// and those rejected so far per priority level and flow schema, to diagnose
+// clients receiving 429 responses. The metrics are those of the API server
+// instance that answered; sections that cannot be read are reported in "errors".
+// Returns a map with "priorityLevels", "flowSchemas", "findings" and "errors",
+// or an error if neither configuration can be read.
+func (c *Client) GetFlowControl(ctx context.Context) (map[string]interface{}, error) {
+	errors := []string{}
+	priorityLevels, plErr := c.clientset.FlowcontrolV1().PriorityLevelConfigurations().List(ctx, metav1.ListOptions{})
+	if plErr != nil {
+		errors = append(errors, fmt.Sprintf("priorityLevelConfigurations: %v", plErr))
+	}
+	flowSchemas, fsErr := c.clientset.FlowcontrolV1().FlowSchemas().List(ctx, metav1.ListOptions{})
+	if fsErr != nil {
+		errors = append(errors, fmt.Sprintf("flowSchemas: %v", fsErr))
+	}
+	if plErr != nil && fsErr != nil {
+		return nil, fmt.Errorf("failed to read flow control configuration: %w", plErr)
+	}
+
+	var samples []prometheusSample
+	raw, err := c.clientset.CoreV1().RESTClient().Get().AbsPath("/metrics").DoRaw(ctx)
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("metrics: %v", err))
+	} else {
+		samples = parsePrometheusSamples(raw, "apiserver_flowcontrol_")
+	}
+
+	var levels []flowcontrolv1.PriorityLevelConfiguration
+	if priorityLevels != nil {
+		levels = priorityLevels.Items
+	}
+	var schemas []flowcontrolv1.FlowSchema
+	if flowSchemas != nil {
+		schemas = flowSchemas.Items
+	}
+	result := summarizeFlowControl(levels, schemas, samples)
+	result["metricsAvailable"] = samples != nil
+	result["errors"] = errors
+	return result, nil
+}
+
+// summarizeFlowControl combines the APF configuration with the flow control
+// metrics and flags priority levels that reject or queue requests and flow
+// schemas that route to a missing priority level.
+func summarizeFlowControl(levels []flowcontrolv1.PriorityLevelConfiguration, schemas []flowcontrolv1.FlowSchema, samples []prometheusSample) map[string]interface{} {
+	findings := []string{}
+
+	levelSummaries := []map[string]interface{}{}
+	for _, level := range levels {
+		summary := map[string]interface{}{
+			"name": level.Name,
+			"type": string(level.Spec.Type),
+		}
+		if limited := level.Spec.Limited; limited != nil {
+			if limited.NominalConcurrencyShares != nil {
+				summary["nominalConcurrencyShares"] = *limited.NominalConcurrencyShares
+			}
+			if limited.LendablePercent != nil {
+				summary["lendablePercent"] = *limited.LendablePercent
+			}
+			if limited.BorrowingLimitPercent != nil {
+				summary["borrowingLimitPercent"] = *limited.BorrowingLimitPercent
+			}
+			summary["limitResponse"] = string(limited.LimitResponse.Type)
+			if queuing := limited.LimitResponse.Queuing; queuing != nil {
+				summary["queues"] = queuing.Queues
+				summary["handSize"] = queuing.HandSize
+				summary["queueLengthLimit"] = queuing.QueueLengthLimit
+			}
+		}
+
+		metrics := map[string]interface{}{}
+		for key, family := range apfPriorityLevelMetrics {
+			if value, ok := sumSamples(samples, family, "priority_level", level.Name); ok {
+				metrics[key] = value
+			}
+		}
+		if rejected := groupSamples(samples, apfPriorityLevelMetrics["rejectedRequests"], "priority_level", level.Name, "reason"); len(rejected) > 0 {
+			metrics["rejectedByReason"] = rejected
+		}
+		if len(metrics) > 0 {
+			summary["metrics"] = metrics
+		}
+		if rejected, _ := metrics["rejectedRequests"].(float64); rejected > 0 {
+			findings = append(findings, fmt.Sprintf("Priority level %s has rejected %.0f requests with 429 since the API server started", level.Name, rejected))
+		}
+		if queued, _ := metrics["inQueueRequests"].(float64); queued > 0 {
+			findings = append(findings, fmt.Sprintf("Priority level %s has %.0f requests waiting in its queues", level.Name, queued))
+		}
+		levelSummaries = append(levelSummaries, summary)
+	}
+
+	sort.SliceStable(schemas, func(i, j int) bool {
+		if schemas[i].Spec.MatchingPrecedence != schemas[j].Spec.MatchingPrecedence {
+			return schemas[i].Spec.MatchingPrecedence < schemas[j].Spec.MatchingPrecedence
+		}
+		return schemas[i].Name < schemas[j].Name
+	})
+	schemaSummaries := []map[string]interface{}{}
+	for _, schema := range schemas {
+		summary := map[string]interface{}{
+			"name":               schema.Name,
+			"priorityLevel":      schema.Spec.PriorityLevelConfiguration.Name,
+			"matchingPrecedence": schema.Spec.MatchingPrecedence,
+			"rules":              len(schema.Spec.Rules),
+		}
+		if schema.Spec.DistinguisherMethod != nil {
+			summary["distinguisherMethod"] = string(schema.Spec.DistinguisherMethod.Type)
+		}
+		if rejected, ok := sumSamples(samples, apfPriorityLevelMetrics["rejectedRequests"], "flow_schema", schema.Name); ok {
+			summary["rejectedRequests"] = rejected
+		}
+		for _, condition := range schema.Status.Conditions {
+			if condition.Type == flowcontrolv1.FlowSchemaConditionDangling && condition.Status == flowcontrolv1.ConditionTrue {
+				summary["dangling"] = true
+				findings = append(findings, fmt.Sprintf("Flow schema %s routes to priority level %s, which does not exist: %s",
+					schema.Name, schema.Spec.PriorityLevelConfiguration.Name, condition.Message))
+			}
+		}
+		schemaSummaries = append(schemaSummaries, summary)
+	}
+
+	return map[string]interface{}{
+		"priorityLevels": levelSummaries,
+		"flowSchemas":    schemaSummaries,
+		"findings":       findings,
+	}
+}
+
+// sumSamples sums the samples of a metric family whose label has the given
+// value. It reports false when there is no such sample.
+func sumSamples(samples []prometheusSample, family, label, value string) (float64, bool) {
+	sum, found := 0.0, false
+	for _, sample := range samples {
+		if sample.name == family && sample.labels[label] == value {
+			sum += sample.value
+			found = true
+		}
+	}
+	return sum, found
+}
+
+// groupSamples sums the samples of a metric family whose label has the given
+// value, grouped by the value of another label.
+func groupSamples(samples []prometheusSample, family, label, value, groupBy string) map[string]float64 {
+	groups := map[string]float64{}
+	for _, sample := range samples {
+		if sample.name == family && sample.labels[label] == value {
+			groups[sample.labels[groupBy]] += sample.value
+		}
+	}
+	return groups
+}
+
+// parsePrometheusSamples parses the samples of the metric families starting
+// with prefix from a Prometheus text exposition, with their labels.
+func parsePrometheusSamples(raw []byte, prefix string) []prometheusSample {
+	samples := []prometheusSample{}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		sample := prometheusSample{name: line, labels: map[string]string{}}
+		rest := ""
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			sample.name, rest = line[:i], line[i:]
+		}
+		if strings.HasPrefix(rest, "{") {
+			end := strings.LastIndex(rest, "}")
+			if end < 0 {
+				continue
+			}
+			for _, match := range prometheusLabelPattern.FindAllStringSubmatch(rest[1:end], -1) {
+				sample.labels[match[1]] = match[2]
+			}
+			rest = rest[end+1:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		sample.value = value
+		samples = append(samples, sample)
+	}
+	return samples
+}
@@ -0,0 +1,98 @@
+package k8s
+
+import (
+	"testing"
+
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestParsePrometheusSamples tests parsing samples with their labels
+func TestParsePrometheusSamples(t *testing.T) {
+	raw := []byte(`# HELP apiserver_flowcontrol_rejected_requests_total Number of requests rejected
+# TYPE apiserver_flowcontrol_rejected_requests_total counter
+apiserver_flowcontrol_rejected_requests_total{flow_schema="service-accounts",priority_level="workload-low",reason="queue-full"} 12
+apiserver_flowcontrol_rejected_requests_total{flow_schema="service-accounts",priority_level="workload-low",reason="time-out"} 3
+apiserver_flowcontrol_current_inqueue_requests{flow_schema="service-accounts",priority_level="workload-low"} 4
+apiserver_request_total{code="200"} 100
+apiserver_flowcontrol_dispatched_requests_total 7
+`)
+
+	samples := parsePrometheusSamples(raw, "apiserver_flowcontrol_")
+	if len(samples) != 4 {
+		t.Fatalf("Expected 4 samples, got %d: %v", len(samples), samples)
+	}
+	if samples[0].labels["reason"] != "queue-full" || samples[0].value != 12 {
+		t.Errorf("Unexpected first sample %v", samples[0])
+	}
+	if samples[3].name != "apiserver_flowcontrol_dispatched_requests_total" || samples[3].value != 7 {
+		t.Errorf("Unexpected unlabelled sample %v", samples[3])
+	}
+	if sum, ok := sumSamples(samples, "apiserver_flowcontrol_rejected_requests_total", "priority_level", "workload-low"); !ok || sum != 15 {
+		t.Errorf("Expected 15 rejected requests, got %v", sum)
+	}
+	if _, ok := sumSamples(samples, "apiserver_flowcontrol_rejected_requests_total", "priority_level", "global-default"); ok {
+		t.Errorf("Expected no samples for global-default")
+	}
+}
+
+// TestSummarizeFlowControl tests combining the APF configuration with its metrics
+func TestSummarizeFlowControl(t *testing.T) {
+	shares := int32(30)
+	levels := []flowcontrolv1.PriorityLevelConfiguration{{
+		ObjectMeta: metav1.ObjectMeta{Name: "workload-low"},
+		Spec: flowcontrolv1.PriorityLevelConfigurationSpec{
+			Type: flowcontrolv1.PriorityLevelEnablementLimited,
+			Limited: &flowcontrolv1.LimitedPriorityLevelConfiguration{
+				NominalConcurrencyShares: &shares,
+				LimitResponse: flowcontrolv1.LimitResponse{
+					Type:    flowcontrolv1.LimitResponseTypeQueue,
+					Queuing: &flowcontrolv1.QueuingConfiguration{Queues: 128, HandSize: 6, QueueLengthLimit: 50},
+				},
+			},
+		},
+	}}
+	schemas := []flowcontrolv1.FlowSchema{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-accounts"},
+			Spec: flowcontrolv1.FlowSchemaSpec{
+				PriorityLevelConfiguration: flowcontrolv1.PriorityLevelConfigurationReference{Name: "workload-low"},
+				MatchingPrecedence:         9000,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "custom"},
+			Spec: flowcontrolv1.FlowSchemaSpec{
+				PriorityLevelConfiguration: flowcontrolv1.PriorityLevelConfigurationReference{Name: "missing"},
+				MatchingPrecedence:         500,
+			},
+			Status: flowcontrolv1.FlowSchemaStatus{Conditions: []flowcontrolv1.FlowSchemaCondition{
+				{Type: flowcontrolv1.FlowSchemaConditionDangling, Status: flowcontrolv1.ConditionTrue, Message: "not found"},
+			}},
+		},
+	}
+	samples := []prometheusSample{
+		{name: "apiserver_flowcontrol_rejected_requests_total", labels: map[string]string{"flow_schema": "service-accounts", "priority_level": "workload-low", "reason": "queue-full"}, value: 12},
+		{name: "apiserver_flowcontrol_current_inqueue_requests", labels: map[string]string{"flow_schema": "service-accounts", "priority_level": "workload-low"}, value: 4},
+	}
+
+	result := summarizeFlowControl(levels, schemas, samples)
+	level := result["priorityLevels"].([]map[string]interface{})[0]
+	if level["queues"] != int32(128) || level["nominalConcurrencyShares"] != int32(30) {
+		t.Errorf("Unexpected priority level %v", level)
+	}
+	metrics := level["metrics"].(map[string]interface{})
+	if metrics["rejectedRequests"] != 12.0 || metrics["inQueueRequests"] != 4.0 {
+		t.Errorf("Unexpected metrics %v", metrics)
+	}
+	flowSchemas := result["flowSchemas"].([]map[string]interface{})
+	if flowSchemas[0]["name"] != "custom" || flowSchemas[0]["dangling"] != true {
+		t.Errorf("Expected the dangling schema first, got %v", flowSchemas[0])
+	}
+	if flowSchemas[1]["rejectedRequests"] != 12.0 {
+		t.Errorf("Unexpected schema rejections %v", flowSchemas[1])
+	}
+	if findings := result["findings"].([]string); len(findings) != 3 {
+		t.Errorf("Expected 3 findings, got %v", findings)
+	}
+}
@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// DefaultFieldManager is the field manager server-side apply uses when none is
+// given.
+const DefaultFieldManager = "k8s-mcp-server"
+
+// decodeManifests decodes a YAML or JSON manifest into objects. YAML input may
+// hold several documents separated by "---"; empty documents are skipped and
+// List objects are expanded into their items.
+// Returns the objects, or an error if a document cannot be decoded or lacks
+// apiVersion, kind or name.
+func decodeManifests(manifest string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	var objects []*unstructured.Unstructured
+	for document := 1; ; document++ {
+		raw := map[string]interface{}{}
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest document %d: %w", document, err)
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse manifest document %d: %w", document, err)
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+		} else {
+			objects = append(objects, obj)
+		}
+	}
+
+	for i, obj := range objects {
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("manifest object %d: apiVersion and kind are required", i+1)
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("manifest object %d (%s): metadata.name is required", i+1, obj.GetKind())
+		}
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("manifest contains no objects")
+	}
+	return objects, nil
+}
+
+// applyResourceClient returns a dynamic client for the resource of obj, using
+// the group and version of its apiVersion. Namespaced objects go to namespace
+// if set, else to the manifest's namespace, else to "default"; the namespace of
+// obj is updated to match.
+func (c *Client) applyResourceClient(obj *unstructured.Unstructured, namespace string) (dynamic.ResourceInterface, error) {
+	gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+	if err != nil {
+		return nil, fmt.Errorf("invalid apiVersion %q: %w", obj.GetAPIVersion(), err)
+	}
+	kind := obj.GetKind()
+	if gv.Group != "" {
+		kind += "." + gv.Group
+	}
+	info, err := c.getCachedResource(kind)
+	if err != nil {
+		return nil, err
+	}
+	gvr := info.gvr
+	gvr.Version = gv.Version
+
+	if !info.namespaced {
+		obj.SetNamespace("")
+		return c.dynamicClient.Resource(gvr), nil
+	}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace(metav1.NamespaceDefault)
+	}
+	return c.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()), nil
+}
+
+// ApplyManifest applies the objects of a YAML or JSON manifest with server-side
+// apply, in order, as fieldManager. If force is set, conflicting fields owned
+// by other managers are taken over instead of failing the apply. The namespace,
+// if set, overrides the namespace of namespaced objects. Each change is
+// recorded for undo, and a context marked with WithDryRun applies nothing.
+// Returns a summary of each applied object, or an error naming the object that
+// failed and how many objects were applied before it.
+func (c *Client) ApplyManifest(ctx context.Context, namespace, manifest, fieldManager string, force bool) ([]map[string]interface{}, error) {
+	objects, err := decodeManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+
+	applied := []map[string]interface{}{}
+	for _, obj := range objects {
+		resource, err := c.applyResourceClient(obj, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s %s after applying %d objects: %w", obj.GetKind(), obj.GetName(), len(applied), err)
+		}
+		recordUndo := c.recordUndo(ctx, "apply", resource, obj.GetKind(), obj.GetName(), obj.GetNamespace())
+		result, err := resource.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: fieldManager,
+			Force:        force,
+			DryRun:       dryRunOption(ctx),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s %s after applying %d objects: %w", obj.GetKind(), obj.GetName(), len(applied), err)
+		}
+		recordUndo()
+
+		summary := map[string]interface{}{
+			"apiVersion":      result.GetAPIVersion(),
+			"kind":            result.GetKind(),
+			"name":            result.GetName(),
+			"resourceVersion": result.GetResourceVersion(),
+			"generation":      result.GetGeneration(),
+		}
+		if result.GetNamespace() != "" {
+			summary["namespace"] = result.GetNamespace()
+		}
+		applied = append(applied, summary)
+	}
+	return applied, nil
+}
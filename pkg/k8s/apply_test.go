@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"testing"
+)
+
+// TestDecodeManifests tests decoding multi-document YAML, JSON and List manifests
+func TestDecodeManifests(t *testing.T) {
+	yamlManifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+`
+	objects, err := decodeManifests(yamlManifest)
+	if err != nil {
+		t.Fatalf("decodeManifests failed: %v", err)
+	}
+	if len(objects) != 2 || objects[0].GetName() != "config" || objects[1].GetKind() != "Deployment" || objects[1].GetNamespace() != "prod" {
+		t.Errorf("Unexpected objects: %v", objects)
+	}
+
+	jsonList := `{"apiVersion": "v1", "kind": "List", "items": [
+		{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}},
+		{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "token"}}
+	]}`
+	objects, err = decodeManifests(jsonList)
+	if err != nil {
+		t.Fatalf("decodeManifests failed: %v", err)
+	}
+	if len(objects) != 2 || objects[0].GetKind() != "Service" || objects[1].GetName() != "token" {
+		t.Errorf("Unexpected objects: %v", objects)
+	}
+
+	for _, manifest := range []string{
+		"",
+		"kind: ConfigMap\nmetadata:\n  name: config\n",
+		"apiVersion: v1\nkind: ConfigMap\n",
+		"apiVersion: v1\nkind: [",
+	} {
+		if _, err := decodeManifests(manifest); err == nil {
+			t.Errorf("Expected an error for manifest %q", manifest)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// helmReleaseSelector selects the Secrets Helm's default storage driver keeps
+// releases in.
+const helmReleaseSelector = "owner=helm"
+
+// optionalAPIs are the API groups behind the optional capabilities of a
+// cluster; a capability is available if any of its groups is served.
+var optionalAPIs = []struct {
+	capability string
+	groups     []string
+}{
+	{"metrics", []string{"metrics.k8s.io"}},
+	{"istio", []string{"networking.istio.io"}},
+	{"keda", []string{"keda.sh"}},
+	{"knative", []string{"serving.knative.dev"}},
+	{"velero", []string{"velero.io"}},
+	{"clusterAPI", []string{"cluster.x-k8s.io"}},
+	{"olm", []string{"operators.coreos.com"}},
+	{"argocd", []string{"argoproj.io"}},
+	{"flux", []string{"source.toolkit.fluxcd.io", "kustomize.toolkit.fluxcd.io", "helm.toolkit.fluxcd.io"}},
+}
+
+// ProbeCapabilities reports which optional APIs the cluster serves: the
+// metrics API, the CRDs of Istio, KEDA, Knative, Velero, Cluster API, OLM,
+// Argo CD and Flux, and Helm's release storage. An API group counts only if
+// its preferred version can be discovered, so aggregated APIs whose backend
+// is down, such as a failing metrics-server, are reported unavailable. Helm
+// counts unless listing its release Secrets is forbidden; other failures
+// leave it available, since Helm may still work in single namespaces.
+// Returns the availability of each capability, the problems that made
+// capabilities unavailable, or an error if the API groups cannot be listed.
+func (c *Client) ProbeCapabilities(ctx context.Context) (map[string]bool, []string, error) {
+	groups, err := c.discoveryClient.ServerGroups()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list API groups: %w", err)
+	}
+	preferred := map[string]string{}
+	for _, group := range groups.Groups {
+		preferred[group.Name] = group.PreferredVersion.GroupVersion
+	}
+
+	capabilities := map[string]bool{}
+	errors := []string{}
+	for _, api := range optionalAPIs {
+		capabilities[api.capability] = false
+		for _, group := range api.groups {
+			groupVersion, ok := preferred[group]
+			if !ok {
+				continue
+			}
+			if _, err := c.discoveryClient.ServerResourcesForGroupVersion(groupVersion); err != nil {
+				errors = append(errors, fmt.Sprintf("%s: %s: %v", api.capability, groupVersion, err))
+				continue
+			}
+			capabilities[api.capability] = true
+			break
+		}
+	}
+
+	capabilities["helm"] = true
+	_, err = c.clientset.CoreV1().Secrets("").List(ctx, metav1.ListOptions{LabelSelector: helmReleaseSelector, Limit: 1})
+	if apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err) {
+		capabilities["helm"] = false
+		errors = append(errors, fmt.Sprintf("helm: %v", err))
+	}
+
+	sort.Strings(errors)
+	return capabilities, errors, nil
+}
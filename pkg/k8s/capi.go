@@ -0,0 +1,128 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Cluster API resources are resolved explicitly because the "Cluster" and
+// "Machine" kinds are also defined by other operators.
+var (
+	capiClusterGVR           = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "clusters"}
+	capiMachineDeploymentGVR = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinedeployments"}
+	capiMachineGVR           = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machines"}
+)
+
+// capiClusterNameLabel links MachineDeployments and Machines to their Cluster.
+const capiClusterNameLabel = "cluster.x-k8s.io/cluster-name"
+
+// GetCapiInventory summarizes Cluster API Clusters, MachineDeployments and
+// Machines on a management cluster, including phases, failure messages and
+// conditions that are not True. If clusterName is set, only that Cluster and
+// its machines are reported.
+// Returns a map with "clusters", "machineDeployments", "machines" and
+// per-phase counts, or an error if Cluster API is not installed.
+func (c *Client) GetCapiInventory(ctx context.Context, namespace, clusterName string) (map[string]interface{}, error) {
+	selector := ""
+	if clusterName != "" {
+		selector = capiClusterNameLabel + "=" + clusterName
+	}
+
+	clusters, err := c.dynamicClient.Resource(capiClusterGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Cluster API clusters (is Cluster API installed?): %w", err)
+	}
+	var clusterSummaries []map[string]interface{}
+	for _, cluster := range clusters.Items {
+		if clusterName != "" && cluster.GetName() != clusterName {
+			continue
+		}
+		content := cluster.UnstructuredContent()
+		clusterSummaries = append(clusterSummaries, map[string]interface{}{
+			"name":                cluster.GetName(),
+			"namespace":           cluster.GetNamespace(),
+			"phase":               nestedValue(content, "status", "phase"),
+			"controlPlaneReady":   nestedValue(content, "status", "controlPlaneReady"),
+			"infrastructureReady": nestedValue(content, "status", "infrastructureReady"),
+			"controlPlaneRef":     nestedValue(content, "spec", "controlPlaneRef"),
+			"infrastructureRef":   nestedValue(content, "spec", "infrastructureRef"),
+			"topologyVersion":     nestedValue(content, "spec", "topology", "version"),
+			"failureReason":       nestedValue(content, "status", "failureReason"),
+			"failureMessage":      nestedValue(content, "status", "failureMessage"),
+			"unhealthyConditions": unhealthyConditions(content),
+		})
+	}
+
+	deployments, err := c.dynamicClient.Resource(capiMachineDeploymentGVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MachineDeployments: %w", err)
+	}
+	var deploymentSummaries []map[string]interface{}
+	for _, md := range deployments.Items {
+		content := md.UnstructuredContent()
+		deploymentSummaries = append(deploymentSummaries, map[string]interface{}{
+			"name":                md.GetName(),
+			"namespace":           md.GetNamespace(),
+			"cluster":             nestedValue(content, "spec", "clusterName"),
+			"phase":               nestedValue(content, "status", "phase"),
+			"version":             nestedValue(content, "spec", "template", "spec", "version"),
+			"replicas":            nestedValue(content, "spec", "replicas"),
+			"readyReplicas":       nestedValue(content, "status", "readyReplicas"),
+			"updatedReplicas":     nestedValue(content, "status", "updatedReplicas"),
+			"unavailableReplicas": nestedValue(content, "status", "unavailableReplicas"),
+			"unhealthyConditions": unhealthyConditions(content),
+		})
+	}
+
+	machines, err := c.dynamicClient.Resource(capiMachineGVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Machines: %w", err)
+	}
+	machinePhases := map[string]int{}
+	var machineSummaries []map[string]interface{}
+	for _, machine := range machines.Items {
+		content := machine.UnstructuredContent()
+		phase, _, _ := unstructured.NestedString(content, "status", "phase")
+		machinePhases[phase]++
+
+		owner := ""
+		for _, ref := range machine.GetOwnerReferences() {
+			owner = ref.Kind + "/" + ref.Name
+		}
+		machineSummaries = append(machineSummaries, map[string]interface{}{
+			"name":                machine.GetName(),
+			"namespace":           machine.GetNamespace(),
+			"cluster":             nestedValue(content, "spec", "clusterName"),
+			"owner":               owner,
+			"phase":               phase,
+			"version":             nestedValue(content, "spec", "version"),
+			"providerID":          nestedValue(content, "spec", "providerID"),
+			"nodeName":            nestedValue(content, "status", "nodeRef", "name"),
+			"failureReason":       nestedValue(content, "status", "failureReason"),
+			"failureMessage":      nestedValue(content, "status", "failureMessage"),
+			"unhealthyConditions": unhealthyConditions(content),
+		})
+	}
+
+	return map[string]interface{}{
+		"clusters":           clusterSummaries,
+		"machineDeployments": deploymentSummaries,
+		"machines":           machineSummaries,
+		"machinePhases":      machinePhases,
+	}, nil
+}
+
+// unhealthyConditions returns the status.conditions whose status is not "True".
+func unhealthyConditions(obj map[string]interface{}) []map[string]interface{} {
+	var conditions []map[string]interface{}
+	for _, condition := range summarizeConditions(obj) {
+		if condition["status"] != "True" {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions
+}
@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// TestGetCapiInventory tests summarizing the Clusters, MachineDeployments and Machines of one cluster
+func TestGetCapiInventory(t *testing.T) {
+	selectors := map[string]string{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selectors[r.URL.Path] = r.URL.Query().Get("labelSelector")
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/cluster.x-k8s.io/v1beta1/namespaces/fleet/clusters":
+			w.Write([]byte(`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"ClusterList","items":[` +
+				`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"Cluster","metadata":{"name":"prod","namespace":"fleet"},"status":{"phase":"Provisioned",` +
+				`"conditions":[{"type":"Ready","status":"True"},{"type":"ControlPlaneReady","status":"False","reason":"ScalingUp"}]}},` +
+				`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"Cluster","metadata":{"name":"staging","namespace":"fleet"}}]}`))
+		case "/apis/cluster.x-k8s.io/v1beta1/namespaces/fleet/machinedeployments":
+			w.Write([]byte(`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"MachineDeploymentList","items":[` +
+				`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"MachineDeployment","metadata":{"name":"prod-md-0","namespace":"fleet"},` +
+				`"spec":{"clusterName":"prod","replicas":2},"status":{"readyReplicas":1}}]}`))
+		case "/apis/cluster.x-k8s.io/v1beta1/namespaces/fleet/machines":
+			w.Write([]byte(`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"MachineList","items":[` +
+				`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"Machine","metadata":{"name":"prod-md-0-a","namespace":"fleet",` +
+				`"ownerReferences":[{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"MachineSet","name":"prod-md-0-x","uid":"1"}]},` +
+				`"spec":{"clusterName":"prod"},"status":{"phase":"Running","nodeRef":{"name":"node-a"}}},` +
+				`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"Machine","metadata":{"name":"prod-md-0-b","namespace":"fleet"},` +
+				`"spec":{"clusterName":"prod"},"status":{"phase":"Failed","failureMessage":"instance terminated"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	inventory, err := client.GetCapiInventory(context.Background(), "fleet", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters := inventory["clusters"].([]map[string]interface{})
+	if len(clusters) != 1 || clusters[0]["name"] != "prod" {
+		t.Fatalf("Expected only the named cluster, got %v", clusters)
+	}
+	if conditions := clusters[0]["unhealthyConditions"].([]map[string]interface{}); len(conditions) != 1 || conditions[0]["type"] != "ControlPlaneReady" {
+		t.Errorf("Expected only the conditions that are not True, got %v", conditions)
+	}
+	if selectors["/apis/cluster.x-k8s.io/v1beta1/namespaces/fleet/machines"] != "cluster.x-k8s.io/cluster-name=prod" {
+		t.Errorf("Expected the machines of the cluster to be selected by label, got %v", selectors)
+	}
+	machines := inventory["machines"].([]map[string]interface{})
+	if len(machines) != 2 || machines[0]["owner"] != "MachineSet/prod-md-0-x" || machines[0]["nodeName"] != "node-a" ||
+		machines[1]["failureMessage"] != "instance terminated" {
+		t.Errorf("Unexpected machines %v", machines)
+	}
+	if phases := inventory["machinePhases"].(map[string]int); phases["Running"] != 1 || phases["Failed"] != 1 {
+		t.Errorf("Expected machines counted per phase, got %v", phases)
+	}
+
+	if _, err := client.GetCapiInventory(context.Background(), "other", ""); err == nil {
+		t.Error("Expected an error when Cluster API is not installed")
+	}
+}
@@ -0,0 +1,188 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CleanupOptions controls which finished pods and Jobs CleanupFinished deletes.
+type CleanupOptions struct {
+	LabelSelector string        // Only consider pods and Jobs with these labels
+	PodAge        time.Duration // Minimum time since a pod finished
+	JobTTL        time.Duration // Minimum time since a Job finished, for Jobs without ttlSecondsAfterFinished
+}
+
+// CleanupFinished deletes pods that have finished, in phase Succeeded or
+// Failed (which includes evicted pods), for at least opts.PodAge, and Jobs
+// that completed or failed longer ago than their ttlSecondsAfterFinished, or
+// opts.JobTTL if they set none. Pods of Jobs are left to be deleted with
+// their Job, so the logs of a Job within its TTL stay available, and mirror
+// pods of static pods are skipped. Jobs are deleted with their pods. An empty
+// namespace cleans up all namespaces. Deletions honour WithDryRun and are
+// not recorded for undo, as recreating finished pods would run them again.
+// Returns a map with the "deletedPods" and "deletedJobs", the number of
+// finished ones "kept" for being too recent, and the "errors" of failed
+// deletions, or an error.
+func (c *Client) CleanupFinished(ctx context.Context, namespace string, opts CleanupOptions) (map[string]interface{}, error) {
+	if opts.PodAge < 0 || opts.JobTTL < 0 {
+		return nil, fmt.Errorf("pod age and job TTL must be zero or greater")
+	}
+	listOptions := metav1.ListOptions{LabelSelector: opts.LabelSelector}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	jobs, err := c.clientset.BatchV1().Jobs(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	now := time.Now()
+	var errors []string
+	kept := map[string]int{"pods": 0, "jobs": 0}
+
+	deletedJobs := []map[string]interface{}{}
+	background := metav1.DeletePropagationBackground
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		status, finishedAt := jobFinished(job)
+		if status == "" {
+			continue
+		}
+		ttl := opts.JobTTL
+		if job.Spec.TTLSecondsAfterFinished != nil {
+			ttl = time.Duration(*job.Spec.TTLSecondsAfterFinished) * time.Second
+		}
+		if now.Sub(finishedAt) < ttl {
+			kept["jobs"]++
+			continue
+		}
+		err := c.clientset.BatchV1().Jobs(job.Namespace).Delete(ctx, job.Name, metav1.DeleteOptions{
+			PropagationPolicy: &background,
+			DryRun:            dryRunOption(ctx),
+		})
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to delete job %s/%s: %v", job.Namespace, job.Name, err))
+			continue
+		}
+		entry := map[string]interface{}{
+			"name":       job.Name,
+			"namespace":  job.Namespace,
+			"status":     status,
+			"finishedAt": finishedAt.UTC().Format(time.RFC3339),
+			"ttl":        ttl.String(),
+		}
+		if owner := metav1.GetControllerOf(job); owner != nil {
+			entry["owner"] = owner.Kind + "/" + owner.Name
+		}
+		deletedJobs = append(deletedJobs, entry)
+	}
+
+	deletedPods := []map[string]interface{}{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			continue
+		}
+		if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+			continue
+		}
+		if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "Job" {
+			continue
+		}
+		finishedAt := podFinishedAt(pod)
+		if now.Sub(finishedAt) < opts.PodAge {
+			kept["pods"]++
+			continue
+		}
+		err := c.clientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{DryRun: dryRunOption(ctx)})
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to delete pod %s/%s: %v", pod.Namespace, pod.Name, err))
+			continue
+		}
+		entry := map[string]interface{}{
+			"name":       pod.Name,
+			"namespace":  pod.Namespace,
+			"phase":      string(pod.Status.Phase),
+			"finishedAt": finishedAt.UTC().Format(time.RFC3339),
+		}
+		if pod.Status.Reason != "" {
+			entry["reason"] = pod.Status.Reason
+		}
+		if pod.Spec.NodeName != "" {
+			entry["node"] = pod.Spec.NodeName
+		}
+		deletedPods = append(deletedPods, entry)
+	}
+
+	for _, deleted := range [][]map[string]interface{}{deletedJobs, deletedPods} {
+		sort.SliceStable(deleted, func(i, j int) bool {
+			if deleted[i]["namespace"] != deleted[j]["namespace"] {
+				return deleted[i]["namespace"].(string) < deleted[j]["namespace"].(string)
+			}
+			return deleted[i]["name"].(string) < deleted[j]["name"].(string)
+		})
+	}
+
+	result := map[string]interface{}{
+		"deletedPods": deletedPods,
+		"deletedJobs": deletedJobs,
+		"kept":        kept,
+		"podAge":      opts.PodAge.String(),
+		"jobTTL":      opts.JobTTL.String(),
+	}
+	if IsDryRun(ctx) {
+		result["dryRun"] = true
+	}
+	if len(errors) > 0 {
+		result["errors"] = errors
+	}
+	return result, nil
+}
+
+// jobFinished returns whether a Job finished as "Complete" or "Failed", and
+// when, or an empty status if it is still running.
+func jobFinished(job *batchv1.Job) (string, time.Time) {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			finishedAt := condition.LastTransitionTime.Time
+			if job.Status.CompletionTime != nil && condition.Type == batchv1.JobComplete {
+				finishedAt = job.Status.CompletionTime.Time
+			}
+			return string(condition.Type), finishedAt
+		}
+	}
+	return "", time.Time{}
+}
+
+// podFinishedAt estimates when a finished pod stopped: when its last
+// container terminated, or else when it stopped being ready, as for pods
+// evicted before their containers reported, falling back to its start and
+// creation times.
+func podFinishedAt(pod *corev1.Pod) time.Time {
+	var finishedAt time.Time
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.FinishedAt.After(finishedAt) {
+			finishedAt = terminated.FinishedAt.Time
+		}
+	}
+	if !finishedAt.IsZero() {
+		return finishedAt
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionFalse && !condition.LastTransitionTime.IsZero() {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return pod.CreationTimestamp.Time
+}
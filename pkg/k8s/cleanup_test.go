@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCleanupFinished tests deleting finished pods and Jobs past their age thresholds
+func TestCleanupFinished(t *testing.T) {
+	recent := time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339)
+	var mu sync.Mutex
+	var deleted []string
+	var dryRuns []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			body, _ := io.ReadAll(r.Body)
+			dryRuns = append(dryRuns, string(body))
+			mu.Unlock()
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Success"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/namespaces/batch/pods":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"done","namespace":"batch"},"status":{"phase":"Succeeded","containerStatuses":[{"name":"app","state":{"terminated":{"exitCode":0,"finishedAt":"2024-05-01T10:00:00Z"}}}]}},` +
+				`{"metadata":{"name":"evicted","namespace":"batch"},"spec":{"nodeName":"node-1"},"status":{"phase":"Failed","reason":"Evicted","conditions":[{"type":"Ready","status":"False","lastTransitionTime":"2024-05-01T11:00:00Z"}]}},` +
+				`{"metadata":{"name":"fresh","namespace":"batch"},"status":{"phase":"Failed","containerStatuses":[{"name":"app","state":{"terminated":{"exitCode":1,"finishedAt":"` + recent + `"}}}]}},` +
+				`{"metadata":{"name":"running","namespace":"batch"},"status":{"phase":"Running","startTime":"2024-05-01T10:00:00Z"}},` +
+				`{"metadata":{"name":"etcd-node-1","namespace":"batch","annotations":{"kubernetes.io/config.mirror":"abc"}},"status":{"phase":"Failed","startTime":"2024-05-01T10:00:00Z"}},` +
+				`{"metadata":{"name":"report-x","namespace":"batch","ownerReferences":[{"apiVersion":"batch/v1","kind":"Job","name":"report","uid":"j1","controller":true}]},"status":{"phase":"Succeeded","startTime":"2024-05-01T10:00:00Z"}}]}`))
+		case "/apis/batch/v1/namespaces/batch/jobs":
+			w.Write([]byte(`{"kind":"JobList","apiVersion":"batch/v1","items":[` +
+				`{"metadata":{"name":"report","namespace":"batch","ownerReferences":[{"apiVersion":"batch/v1","kind":"CronJob","name":"nightly","uid":"c1","controller":true}]},` +
+				`"status":{"completionTime":"2024-05-01T10:00:00Z","conditions":[{"type":"Complete","status":"True","lastTransitionTime":"2024-05-01T10:00:00Z"}]}},` +
+				`{"metadata":{"name":"failed","namespace":"batch"},"status":{"conditions":[{"type":"Failed","status":"True","lastTransitionTime":"` + recent + `"}]}},` +
+				`{"metadata":{"name":"kept","namespace":"batch"},"spec":{"ttlSecondsAfterFinished":86400000},"status":{"conditions":[{"type":"Complete","status":"True","lastTransitionTime":"2024-05-01T10:00:00Z"}]}},` +
+				`{"metadata":{"name":"active","namespace":"batch"},"status":{"active":1}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.CleanupFinished(WithDryRun(context.Background()), "batch", CleanupOptions{PodAge: time.Hour, JobTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["dryRun"] != true || len(dryRuns) == 0 || slices.ContainsFunc(dryRuns, func(body string) bool { return !strings.Contains(body, `"dryRun":["All"]`) }) {
+		t.Errorf("Expected every deletion to be a dry run, got %v", dryRuns)
+	}
+
+	expected := []string{
+		"/apis/batch/v1/namespaces/batch/jobs/report",
+		"/apis/batch/v1/namespaces/batch/jobs/failed",
+		"/api/v1/namespaces/batch/pods/done",
+		"/api/v1/namespaces/batch/pods/evicted",
+	}
+	if !slices.Equal(deleted, expected) {
+		t.Errorf("Expected deletions %v, got %v", expected, deleted)
+	}
+
+	pods := result["deletedPods"].([]map[string]interface{})
+	if len(pods) != 2 || pods[1]["reason"] != "Evicted" || pods[1]["finishedAt"] != "2024-05-01T11:00:00Z" {
+		t.Errorf("Expected the evicted pod with its Ready transition time, got %v", pods)
+	}
+	jobs := result["deletedJobs"].([]map[string]interface{})
+	if len(jobs) != 2 || jobs[1]["name"] != "report" || jobs[1]["owner"] != "CronJob/nightly" {
+		t.Errorf("Expected the report Job of CronJob nightly, got %v", jobs)
+	}
+	if kept := result["kept"].(map[string]int); kept["pods"] != 1 || kept["jobs"] != 1 {
+		t.Errorf("Expected one recent pod and one Job within its own TTL kept, got %v", kept)
+	}
+
+	if _, err := client.CleanupFinished(context.Background(), "batch", CleanupOptions{PodAge: -time.Minute}); err == nil {
+		t.Error("Expected a negative pod age to be rejected")
+	}
+}
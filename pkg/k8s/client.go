@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -40,20 +41,42 @@ type Client struct {
 	discoveryClient  *discovery.DiscoveryClient
 	metricsClientset *metricsclientset.Clientset // Add metrics client
 	restConfig       *rest.Config
-	apiResourceCache map[string]*schema.GroupVersionResource
+	contextName      string
+	inCluster        bool                         // Configured from the pod's service account rather than a kubeconfig
+	readOnly         bool                         // Refuses requests that could change the cluster
+	namespaceCheck   func(namespace string) error // Refuses requests in namespaces a policy excludes
+	impersonating    string                       // User whose identity requests are made with
+	impersonatedAs   []string                     // Groups impersonated along with the user
+	impersonator     *Client                      // Client with the credentials doing the impersonation
+	scanThreshold    int                          // Objects above which a scan across namespaces needs confirmation
+	apiResourceCache map[string]*resourceInfo
 	cacheLock        sync.RWMutex
+	undo             undoLog        // Prior state of mutated objects, per session
+	portForwards     *portForwards  // Port forwards started by each session, shared with copies of the client
+	eventWatches     *eventWatches  // Event watches started by each session, shared with copies of the client
+	cache            *resourceCache // Informers serving lists of hot kinds, not shared with copies of the client
+}
+
+// resourceInfo describes a resolved API resource: its GroupVersionResource
+// and whether it is namespace-scoped, as reported by discovery.
+type resourceInfo struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
 }
 
 // NewClient creates a new Kubernetes client.
 // It initializes the standard clientset, dynamic client, discovery client,
 // and metrics client using the provided kubeconfig path or the default path.
-// If kubeconfigPath is empty, it defaults to ~/.kube/config.
+// If kubeconfigPath is empty, it defaults to ~/.kube/config, and if that file
+// does not exist either, it falls back to the in-cluster configuration.
 func NewClient(kubeconfigPath string) (*Client, error) {
-	var kubeconfig string
-	if kubeconfigPath != "" {
-		kubeconfig = kubeconfigPath
-	} else if home := homedir.HomeDir(); home != "" {
-		kubeconfig = filepath.Join(home, ".kube", "config")
+	kubeconfig := resolveKubeconfigPath(kubeconfigPath)
+	if kubeconfigPath == "" && !fileExists(kubeconfig) {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Kubernetes configuration: no kubeconfig found at %s and in-cluster configuration failed: %w", kubeconfig, err)
+		}
+		return newInClusterClient(config)
 	}
 
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
@@ -61,6 +84,67 @@ func NewClient(kubeconfigPath string) (*Client, error) {
 		return nil, fmt.Errorf("failed to create Kubernetes configuration: %w", err)
 	}
 
+	return newClientForConfig(config, currentContextName(kubeconfig))
+}
+
+// NewInClusterClient creates a new Kubernetes client from the service account
+// of the pod the server runs in, ignoring any kubeconfig.
+func NewInClusterClient() (*Client, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create in-cluster configuration: %w", err)
+	}
+
+	return newInClusterClient(config)
+}
+
+// newInClusterClient creates a Client from an in-cluster configuration.
+func newInClusterClient(config *rest.Config) (*Client, error) {
+	client, err := newClientForConfig(config, "")
+	if err != nil {
+		return nil, err
+	}
+	client.inCluster = true
+	return client, nil
+}
+
+// NewClientForContext creates a new Kubernetes client for the named context of
+// the kubeconfig file, rather than its current context. If kubeconfigPath is
+// empty, it defaults to ~/.kube/config.
+func NewClientForContext(kubeconfigPath, contextName string) (*Client, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: resolveKubeconfigPath(kubeconfigPath)},
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes configuration for context %q: %w", contextName, err)
+	}
+
+	return newClientForConfig(config, contextName)
+}
+
+// fileExists reports whether path names an existing file.
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// resolveKubeconfigPath returns kubeconfigPath, or ~/.kube/config if it is empty.
+func resolveKubeconfigPath(kubeconfigPath string) string {
+	if kubeconfigPath != "" {
+		return kubeconfigPath
+	}
+	if home := homedir.HomeDir(); home != "" {
+		return filepath.Join(home, ".kube", "config")
+	}
+	return ""
+}
+
+// newClientForConfig creates the clients of a Client from a REST configuration.
+func newClientForConfig(config *rest.Config, contextName string) (*Client, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
@@ -88,10 +172,45 @@ func NewClient(kubeconfigPath string) (*Client, error) {
 		discoveryClient:  discoveryClient,
 		metricsClientset: metricsClient, // Assign metrics client
 		restConfig:       config,
-		apiResourceCache: make(map[string]*schema.GroupVersionResource),
+		contextName:      contextName,
+		apiResourceCache: make(map[string]*resourceInfo),
+		portForwards:     &portForwards{},
+		eventWatches:     &eventWatches{},
 	}, nil
 }
 
+// currentContextName returns the current context of the kubeconfig file, or an
+// empty string if it cannot be determined (e.g. when running in-cluster).
+func currentContextName(kubeconfig string) string {
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err != nil {
+		return ""
+	}
+	return rawConfig.CurrentContext
+}
+
+// ContextName returns the kubeconfig context the client was created from.
+func (c *Client) ContextName() string {
+	return c.contextName
+}
+
+// InCluster reports whether the client was configured from the pod's service
+// account rather than a kubeconfig.
+func (c *Client) InCluster() bool {
+	return c.inCluster
+}
+
+// ServerHost returns the address of the Kubernetes API server.
+func (c *Client) ServerHost() string {
+	if c.restConfig == nil {
+		return ""
+	}
+	return c.restConfig.Host
+}
+
 // GetAPIResources retrieves all API resource types in the cluster.
 // It uses the discovery client to fetch server-preferred resources.
 // Filters resources based on includeNamespaceScoped and includeClusterScoped flags.
@@ -124,20 +243,17 @@ func (c *Client) GetAPIResources(ctx context.Context, includeNamespaceScoped, in
 
 // GetResource retrieves detailed information about a specific resource.
 // It uses the dynamic client to fetch the resource by kind, name, and namespace.
+// The namespace is ignored for cluster-scoped kinds and defaults to "default"
+// for namespaced kinds.
 // It utilizes a cached GroupVersionResource (GVR) for efficiency.
 // Returns the unstructured content of the resource as a map, or an error.
 func (c *Client) GetResource(ctx context.Context, kind, name, namespace string) (map[string]interface{}, error) {
-	gvr, err := c.getCachedGVR(kind)
+	resource, err := c.resourceClient(kind, namespace, true)
 	if err != nil {
 		return nil, err
 	}
 
-	var obj *unstructured.Unstructured
-	if namespace != "" {
-		obj, err = c.dynamicClient.Resource(*gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-	} else {
-		obj, err = c.dynamicClient.Resource(*gvr).Get(ctx, name, metav1.GetOptions{})
-	}
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve resource: %w", err)
 	}
@@ -148,27 +264,47 @@ func (c *Client) GetResource(ctx context.Context, kind, name, namespace string)
 // ListResources lists all instances of a specific resource type.
 // It uses the dynamic client and supports filtering by namespace, labelSelector,
 // and fieldSelector.
-// It utilizes a cached GroupVersionResource (GVR) for efficiency.
+// The namespace is ignored for cluster-scoped kinds; an empty namespace lists
+// namespaced kinds across all namespaces.
+// It utilizes a cached GroupVersionResource (GVR) for efficiency, and serves
+// the kinds of the resource cache, if enabled, from their informers.
 // Returns a slice of maps, each representing a resource instance, or an error.
 func (c *Client) ListResources(ctx context.Context, kind, namespace, labelSelector, fieldSelector string) ([]map[string]interface{}, error) {
-	gvr, err := c.getCachedGVR(kind)
+	if resources, ok := c.listCached(kind, namespace, labelSelector, fieldSelector); ok {
+		return resources, nil
+	}
+
+	resources, _, _, err := c.ListResourcesPage(ctx, kind, namespace, labelSelector, fieldSelector, 0, "")
+	return resources, err
+}
+
+// ListResourcesPage lists one page of at most limit instances of a resource
+// type, with the same filtering as ListResources. A non-empty continueToken
+// continues the list where the page that returned it ended; a limit of 0
+// lists all remaining instances. Pages are always listed from the API server,
+// whose continue tokens keep them consistent with the first page.
+// Returns the resources, the continue token of the next page, which is empty
+// on the last page, and the API server's estimate of the instances remaining
+// after the page, if it reports one, or an error.
+func (c *Client) ListResourcesPage(ctx context.Context, kind, namespace, labelSelector, fieldSelector string, limit int64, continueToken string) ([]map[string]interface{}, string, *int64, error) {
+	resource, err := c.resourceClient(kind, namespace, false)
 	if err != nil {
-		return nil, err
+		return nil, "", nil, err
 	}
 
 	options := metav1.ListOptions{
 		LabelSelector: labelSelector,
 		FieldSelector: fieldSelector,
+		Limit:         limit,
+		Continue:      continueToken,
 	}
 
-	var list *unstructured.UnstructuredList
-	if namespace != "" {
-		list, err = c.dynamicClient.Resource(*gvr).Namespace(namespace).List(ctx, options)
-	} else {
-		list, err = c.dynamicClient.Resource(*gvr).List(ctx, options)
+	list, err := resource.List(ctx, options)
+	if errors.IsResourceExpired(err) {
+		return nil, "", nil, fmt.Errorf("the continue token has expired, list again without it to start over: %w", err)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to list resources: %w", err)
+		return nil, "", nil, fmt.Errorf("failed to list resources: %w", err)
 	}
 
 	var resources []map[string]interface{}
@@ -176,7 +312,49 @@ func (c *Client) ListResources(ctx context.Context, kind, namespace, labelSelect
 		resources = append(resources, item.UnstructuredContent())
 	}
 
-	return resources, nil
+	return resources, list.GetContinue(), list.GetRemainingItemCount(), nil
+}
+
+// ListResourcesAllNamespaces lists the instances of a resource type across
+// all namespaces, with the same label and field selector filtering as
+// ListResources. Without a namespace policy this is a single list. A
+// namespace policy refuses lists across all namespaces, so the namespaces it
+// allows are listed one at a time instead and the results combined, in
+// namespace order; namespaces that cannot be listed, e.g. for lack of
+// permission, are skipped and reported. Cluster-scoped kinds are listed as
+// by ListResources.
+// Returns the resources and the errors of the skipped namespaces, or an
+// error if the kind or the namespaces cannot be listed at all.
+func (c *Client) ListResourcesAllNamespaces(ctx context.Context, kind, labelSelector, fieldSelector string) ([]map[string]interface{}, []string, error) {
+	info, err := c.getCachedResource(kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.namespaceCheck == nil || !info.namespaced {
+		resources, err := c.ListResources(ctx, kind, "", labelSelector, fieldSelector)
+		return resources, nil, err
+	}
+
+	namespaces, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	sort.Slice(namespaces.Items, func(i, j int) bool { return namespaces.Items[i].Name < namespaces.Items[j].Name })
+
+	var resources []map[string]interface{}
+	var skipped []string
+	for _, namespace := range namespaces.Items {
+		if c.namespaceCheck(namespace.Name) != nil {
+			continue
+		}
+		items, err := c.ListResources(ctx, kind, namespace.Name, labelSelector, fieldSelector)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("namespace %s: %v", namespace.Name, err))
+			continue
+		}
+		resources = append(resources, items...)
+	}
+	return resources, skipped, nil
 }
 
 // CreateOrUpdateResource creates a new resource or updates an existing one.
@@ -194,11 +372,20 @@ func (c *Client) CreateOrUpdateResourceJSON(ctx context.Context, namespace, mani
 	}
 
 	// Determine the resource GVR
-	gvr, err := c.getCachedGVR(kind)
+	info, err := c.getCachedResource(kind)
 	if err != nil {
 		return nil, err
 	}
 
+	if !info.namespaced {
+		obj.SetNamespace("")
+		result, err := c.applyMergePatchOrCreate(ctx, c.dynamicClient.Resource(info.gvr), obj, []byte(manifestJSON))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create or patch resource: %w", err)
+		}
+		return result.UnstructuredContent(), nil
+	}
+
 	// Check if ns exists
 	_, err = c.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	if err == nil {
@@ -232,20 +419,11 @@ func (c *Client) CreateOrUpdateResourceJSON(ctx context.Context, namespace, mani
 		return nil, fmt.Errorf("resource name is required")
 	}
 
-	resource := c.dynamicClient.Resource(*gvr).Namespace(obj.GetNamespace())
+	resource := c.dynamicClient.Resource(info.gvr).Namespace(obj.GetNamespace())
 
 	// Try to patch; if not found, create
 	rawJSON := []byte(manifestJSON) // manifestJSON is already JSON
-	result, err := resource.Patch(
-		ctx,
-		obj.GetName(),
-		types.MergePatchType,
-		rawJSON,
-		metav1.PatchOptions{},
-	)
-	if errors.IsNotFound(err) {
-		result, err = resource.Create(ctx, obj, metav1.CreateOptions{})
-	}
+	result, err := c.applyMergePatchOrCreate(ctx, resource, obj, rawJSON)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create or patch resource: %w", err)
 	}
@@ -290,34 +468,40 @@ func (c *Client) CreateOrUpdateResourceYAML(ctx context.Context, namespace, yaml
 		return nil, fmt.Errorf("failed to parse converted JSON from YAML manifest: %w", err)
 	}
 
-	// Determine the resource GVR
-	gvr, err := c.getCachedGVR(kind)
+	// Determine the resource GVR, inferring the kind from the manifest if needed
+	if kind == "" {
+		kind = obj.GetKind()
+	}
+	info, err := c.getCachedResource(kind)
 	if err != nil {
 		return nil, err
 	}
 
 	// Set namespace if provided (overrides manifest namespace)
-	if namespace != "" {
+	if !info.namespaced {
+		obj.SetNamespace("")
+	} else if namespace != "" {
 		obj.SetNamespace(namespace)
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace(metav1.NamespaceDefault)
 	}
 
 	if obj.GetName() == "" {
 		return nil, fmt.Errorf("resource name is required in YAML manifest")
 	}
 
-	resource := c.dynamicClient.Resource(*gvr).Namespace(obj.GetNamespace())
+	var resource dynamic.ResourceInterface = c.dynamicClient.Resource(info.gvr)
+	if info.namespaced {
+		resource = c.dynamicClient.Resource(info.gvr).Namespace(obj.GetNamespace())
+	}
 
-	// Try to patch; if not found, create
-	result, err := resource.Patch(
-		ctx,
-		obj.GetName(),
-		types.MergePatchType,
-		jsonData,
-		metav1.PatchOptions{},
-	)
-	if errors.IsNotFound(err) {
-		result, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+	// Try to patch; if not found, create. The patch carries the namespace
+	// the object is applied in rather than the manifest's.
+	patch, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode YAML manifest: %w", err)
 	}
+	result, err := c.applyMergePatchOrCreate(ctx, resource, obj, patch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create or patch resource from YAML manifest: %w", err)
 	}
@@ -325,34 +509,102 @@ func (c *Client) CreateOrUpdateResourceYAML(ctx context.Context, namespace, yaml
 	return result.UnstructuredContent(), nil
 }
 
+// DeleteOptions controls how DeleteResource deletes an object.
+type DeleteOptions struct {
+	GracePeriodSeconds *int64 // Seconds to wait for graceful termination; nil for the object's default, 0 to delete immediately
+	PropagationPolicy  string // How dependents are deleted: Orphan, Background or Foreground; empty for the kind's default
+}
+
+// metaDeleteOptions converts options into the DeleteOptions of a delete request.
+func (o DeleteOptions) metaDeleteOptions(ctx context.Context) metav1.DeleteOptions {
+	deleteOptions := metav1.DeleteOptions{
+		GracePeriodSeconds: o.GracePeriodSeconds,
+		DryRun:             dryRunOption(ctx),
+	}
+	if o.PropagationPolicy != "" {
+		policy := metav1.DeletionPropagation(o.PropagationPolicy)
+		deleteOptions.PropagationPolicy = &policy
+	}
+	return deleteOptions
+}
+
 // DeleteResource deletes a specific resource.
-// It uses the dynamic client to delete the resource by kind, name, and namespace.
+// It uses the dynamic client to delete the resource by kind, name, and namespace,
+// with the grace period and propagation policy set by opts.
 // It utilizes a cached GroupVersionResource (GVR) for efficiency.
 // Returns an error if the deletion fails.
-func (c *Client) DeleteResource(ctx context.Context, kind, name, namespace string) error {
-	gvr, err := c.getCachedGVR(kind)
+func (c *Client) DeleteResource(ctx context.Context, kind, name, namespace string, opts DeleteOptions) error {
+	switch metav1.DeletionPropagation(opts.PropagationPolicy) {
+	case "", metav1.DeletePropagationOrphan, metav1.DeletePropagationBackground, metav1.DeletePropagationForeground:
+	default:
+		return fmt.Errorf("invalid propagation policy %q: must be Orphan, Background or Foreground", opts.PropagationPolicy)
+	}
+	if opts.GracePeriodSeconds != nil && *opts.GracePeriodSeconds < 0 {
+		return fmt.Errorf("grace period must be zero or greater")
+	}
+
+	resource, err := c.resourceClient(kind, namespace, true)
 	if err != nil {
 		return err
 	}
 
-	var deleteErr error
-	if namespace != "" {
-		deleteErr = c.dynamicClient.Resource(*gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-	} else {
-		deleteErr = c.dynamicClient.Resource(*gvr).Delete(ctx, name, metav1.DeleteOptions{})
-	}
-	if deleteErr != nil {
+	recordUndo := c.recordUndo(ctx, "delete", resource, kind, name, namespace)
+	if deleteErr := resource.Delete(ctx, name, opts.metaDeleteOptions(ctx)); deleteErr != nil {
 		return fmt.Errorf("failed to delete resource: %w", deleteErr)
 	}
+	recordUndo()
 	return nil
 }
 
 // getCachedGVR retrieves the GroupVersionResource for a given kind, using a cache for performance
 func (c *Client) getCachedGVR(kind string) (*schema.GroupVersionResource, error) {
+	info, err := c.getCachedResource(kind)
+	if err != nil {
+		return nil, err
+	}
+	return &info.gvr, nil
+}
+
+// resourceClient returns a dynamic client for the given kind, scoped according
+// to discovery: cluster-scoped kinds ignore the namespace, and namespaced kinds
+// use the given namespace. If defaultNamespace is set, an empty namespace falls
+// back to "default" for namespaced kinds; otherwise it spans all namespaces.
+func (c *Client) resourceClient(kind, namespace string, defaultNamespace bool) (dynamic.ResourceInterface, error) {
+	info, err := c.getCachedResource(kind)
+	if err != nil {
+		return nil, err
+	}
+	if !info.namespaced {
+		return c.dynamicClient.Resource(info.gvr), nil
+	}
+	if namespace == "" && defaultNamespace {
+		namespace = metav1.NamespaceDefault
+	}
+	return c.dynamicClient.Resource(info.gvr).Namespace(namespace), nil
+}
+
+// applyMergePatchOrCreate merge-patches an existing object with patch, or creates
+// obj if it does not exist yet.
+func (c *Client) applyMergePatchOrCreate(ctx context.Context, resource dynamic.ResourceInterface, obj *unstructured.Unstructured, patch []byte) (*unstructured.Unstructured, error) {
+	recordUndo := c.recordUndo(ctx, "apply", resource, obj.GetKind(), obj.GetName(), obj.GetNamespace())
+	result, err := resource.Patch(ctx, obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{DryRun: dryRunOption(ctx)})
+	if errors.IsNotFound(err) {
+		result, err = resource.Create(ctx, obj, metav1.CreateOptions{DryRun: dryRunOption(ctx)})
+	}
+	if err == nil {
+		recordUndo()
+	}
+	return result, err
+}
+
+// getCachedResource resolves a kind to its GroupVersionResource and scope using
+// discovery, caching the result for performance. The kind may be given in any of
+// the forms accepted by resolveKind.
+func (c *Client) getCachedResource(kind string) (*resourceInfo, error) {
 	c.cacheLock.RLock()
-	if gvr, exists := c.apiResourceCache[kind]; exists {
+	if info, exists := c.apiResourceCache[kind]; exists {
 		c.cacheLock.RUnlock()
-		return gvr, nil
+		return info, nil
 	}
 	c.cacheLock.RUnlock()
 
@@ -362,27 +614,15 @@ func (c *Client) getCachedGVR(kind string) (*schema.GroupVersionResource, error)
 		return nil, fmt.Errorf("failed to retrieve API resources: %w", err)
 	}
 
-	for _, resourceList := range resourceLists {
-		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
-		if err != nil {
-			continue
-		}
-		for _, resource := range resourceList.APIResources {
-			if resource.Kind == kind {
-				gvr := &schema.GroupVersionResource{
-					Group:    gv.Group,
-					Version:  gv.Version,
-					Resource: resource.Name,
-				}
-				c.cacheLock.Lock()
-				c.apiResourceCache[kind] = gvr
-				c.cacheLock.Unlock()
-				return gvr, nil
-			}
-		}
+	info, err := resolveKind(resourceLists, kind)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("resource type %s not found", kind)
+	c.cacheLock.Lock()
+	c.apiResourceCache[kind] = info
+	c.cacheLock.Unlock()
+	return info, nil
 }
 
 // DescribeResource retrieves detailed information about a specific resource, similar to GetResource.
@@ -391,17 +631,12 @@ func (c *Client) getCachedGVR(kind string) (*schema.GroupVersionResource, error)
 // Returns the unstructured content of the resource as a map, or an error.
 // Note: This function currently has the same implementation as GetResource.
 func (c *Client) DescribeResource(ctx context.Context, kind, name, namespace string) (map[string]interface{}, error) {
-	gvr, err := c.getCachedGVR(kind)
+	resource, err := c.resourceClient(kind, namespace, true)
 	if err != nil {
 		return nil, err
 	}
 
-	var obj *unstructured.Unstructured
-	if namespace != "" {
-		obj, err = c.dynamicClient.Resource(*gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-	} else {
-		obj, err = c.dynamicClient.Resource(*gvr).Get(ctx, name, metav1.GetOptions{})
-	}
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve resource: %w", err)
 	}
@@ -409,16 +644,36 @@ func (c *Client) DescribeResource(ctx context.Context, kind, name, namespace str
 	return obj.UnstructuredContent(), nil
 }
 
+// LogOptions selects the part of a container log that GetPodsLogs returns.
+type LogOptions struct {
+	TailLines    int64 // Number of lines from the end of the log; 0 for the whole log
+	SinceSeconds int64 // Only return lines newer than this many seconds; 0 for no limit
+	Previous     bool  // Return the log of the previous, terminated container instance
+	Timestamps   bool  // Prefix each line with its RFC3339 timestamp
+}
+
+// podLogOptions converts options into the PodLogOptions of a log request.
+func (o LogOptions) podLogOptions() *corev1.PodLogOptions {
+	podLogOptions := &corev1.PodLogOptions{
+		Previous:   o.Previous,
+		Timestamps: o.Timestamps,
+	}
+	if o.TailLines > 0 {
+		podLogOptions.TailLines = &o.TailLines
+	}
+	if o.SinceSeconds > 0 {
+		podLogOptions.SinceSeconds = &o.SinceSeconds
+	}
+	return podLogOptions
+}
+
 // GetPodsLogs retrieves the logs for a specific pod.
-// It uses the corev1 clientset to fetch logs, limiting to the last 100 lines by default.
+// It uses the corev1 clientset to fetch logs, limited as set by opts.
 // If containerName is provided, it gets logs for that specific container.
 // If containerName is empty and the pod has multiple containers, it gets logs from all containers.
 // Returns the logs as a string, or an error.
-func (c *Client) GetPodsLogs(ctx context.Context, namespace, containerName, podName string) (string, error) {
-	tailLines := int64(100)
-	podLogOptions := &corev1.PodLogOptions{
-		TailLines: &tailLines,
-	}
+func (c *Client) GetPodsLogs(ctx context.Context, namespace, containerName, podName string, opts LogOptions) (string, error) {
+	podLogOptions := opts.podLogOptions()
 
 	// If container name is provided, use it
 	if containerName != "" {
@@ -519,59 +774,44 @@ func (c *Client) GetPodMetrics(ctx context.Context, namespace, podName string) (
 }
 
 // GetNodeMetrics retrieves CPU and Memory metrics for a specific Node.
-// It uses the metrics clientset to fetch node metrics.
-// Returns a map containing node metadata and resource usage, or an error.
+// It uses the metrics clientset to fetch node metrics and compares the usage
+// with the node's allocatable resources.
+// Returns a map containing node metadata, resource usage, allocatable and
+// capacity resources and utilization percentages, or an error.
 func (c *Client) GetNodeMetrics(ctx context.Context, nodeName string) (map[string]interface{}, error) {
 	nodeMetrics, err := c.metricsClientset.MetricsV1beta1().NodeMetricses().Get(ctx, nodeName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get metrics for node '%s': %w", nodeName, err)
 	}
-
-	metricsResult := map[string]interface{}{
-		"nodeName":  nodeName,
-		"timestamp": nodeMetrics.Timestamp.Time,
-		"window":    nodeMetrics.Window.Duration.String(),
-		"usage": map[string]string{
-			"cpu":    nodeMetrics.Usage.Cpu().String(),    // Format Quantity
-			"memory": nodeMetrics.Usage.Memory().String(), // Format Quantity
-		},
+	node, err := c.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node '%s': %w", nodeName, err)
 	}
 
+	metricsResult := summarizeNodeUsage(node, nodeMetrics.Usage)
+	metricsResult["timestamp"] = nodeMetrics.Timestamp.Time
+	metricsResult["window"] = nodeMetrics.Window.Duration.String()
+
 	return metricsResult, nil
 }
 
 // GetEvents retrieves events for a specific namespace or all namespaces.
-// It uses the corev1 clientset to fetch events, filters by message content,
-// sorts them, and limits the results.
+// It uses the events.k8s.io/v1 API, falling back to core/v1, and normalizes
+// both into one schema. Events are filtered by message content, sorted, and limited.
 // Returns a slice of maps, each representing an event, or an error.
 func (c *Client) GetEvents(ctx context.Context, namespace string, maxEvents int, sortBy string, messageFilter string) ([]map[string]interface{}, error) {
-	var eventList *corev1.EventList
-	var err error
-
-	if namespace != "" {
-		eventList, err = c.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
-	} else {
-		eventList, err = c.clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{})
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve events: %w", err)
-	}
-
-	var events []map[string]interface{}
-	for _, event := range eventList.Items {
-		events = append(events, map[string]interface{}{
-			"name":      event.Name,
-			"namespace": event.Namespace,
-			"reason":    event.Reason,
-			"message":   event.Message,
-			"source":    event.Source.Component,
-			"type":      event.Type,
-			"count":     event.Count,
-			"firstTime": event.FirstTimestamp.Time,
-			"lastTime":  event.LastTimestamp.Time,
-		})
+	events, err := c.listNormalizedEvents(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
 	}
 
+	return sortFilterLimitEvents(events, maxEvents, sortBy, messageFilter), nil
+}
+
+// sortFilterLimitEvents sorts normalized events by sortBy in descending order,
+// keeps those whose message contains messageFilter, and limits the result to
+// maxEvents.
+func sortFilterLimitEvents(events []map[string]interface{}, maxEvents int, sortBy string, messageFilter string) []map[string]interface{} {
 	// Sort events based on sortBy parameter (descending order - most recent first)
 	sort.Slice(events, func(i, j int) bool {
 		var timeI, timeJ time.Time
@@ -607,7 +847,7 @@ func (c *Client) GetEvents(ctx context.Context, namespace string, maxEvents int,
 		events = events[:maxEvents]
 	}
 
-	return events, nil
+	return events
 }
 
 // GetIngresses retrieves ingresses and returns specific fields: name, namespace, hosts, paths, and backend services.
@@ -679,10 +919,12 @@ func (c *Client) RolloutRestart(ctx context.Context, kind, name, namespace strin
 		time.Now().Format(time.RFC3339),
 	))
 
+	recordUndo := c.recordUndo(ctx, "rolloutRestart", resource, kind, name, namespace)
 	result, err := resource.Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to rollout restart %s %s/%s: %w", kind, namespace, name, err)
 	}
+	recordUndo()
 
 	content := result.UnstructuredContent()
 	spec, found, _ := unstructured.NestedMap(content, "spec", "template")
@@ -692,3 +934,44 @@ func (c *Client) RolloutRestart(ctx context.Context, kind, name, namespace strin
 
 	return content, nil
 }
+
+// summarizeConditions returns status.conditions of an unstructured object
+// reduced to type, status, reason and message.
+func summarizeConditions(obj map[string]interface{}) []map[string]interface{} {
+	rawConditions, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	var conditions []map[string]interface{}
+	for _, rc := range rawConditions {
+		condition, ok := rc.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditions = append(conditions, map[string]interface{}{
+			"type":    condition["type"],
+			"status":  condition["status"],
+			"reason":  condition["reason"],
+			"message": condition["message"],
+		})
+	}
+	return conditions
+}
+
+// conditionStatus returns the status ("True", "False" or "Unknown") of the
+// given condition type in status.conditions, or an empty string if absent.
+func conditionStatus(obj map[string]interface{}, conditionType string) string {
+	for _, condition := range summarizeConditions(obj) {
+		if condition["type"] == conditionType {
+			status, _ := condition["status"].(string)
+			return status
+		}
+	}
+	return ""
+}
+
+// nestedValue returns the value at the given path of an unstructured object, or nil.
+func nestedValue(obj map[string]interface{}, fields ...string) interface{} {
+	value, found, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	if !found || err != nil {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestNewClientWithoutKubeconfig tests falling back to the in-cluster configuration
+func TestNewClientWithoutKubeconfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	_, err := NewClient("")
+	if err == nil || !strings.Contains(err.Error(), "in-cluster configuration failed") {
+		t.Errorf("Expected the in-cluster fallback to fail outside a cluster, got %v", err)
+	}
+}
+
+// TestListResourcesPage tests paging through a list with limit and continue tokens
+func TestListResourcesPage(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[` +
+				`{"name":"pods","namespaced":true,"kind":"Pod","verbs":["get","list"]}]}`))
+		case "/api/v1/namespaces/web/pods":
+			query := r.URL.Query()
+			switch {
+			case query.Get("limit") != "1":
+				t.Errorf("Expected a limit of 1, got %q", query.Get("limit"))
+			case query.Get("continue") == "":
+				w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"page-2","remainingItemCount":1},` +
+					`"items":[{"kind":"Pod","apiVersion":"v1","metadata":{"name":"web-1","namespace":"web"}}]}`))
+			case query.Get("continue") == "page-2":
+				w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},` +
+					`"items":[{"kind":"Pod","apiVersion":"v1","metadata":{"name":"web-2","namespace":"web"}}]}`))
+			default:
+				w.WriteHeader(http.StatusGone)
+				w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Expired","code":410}`))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	pods, next, remaining, err := client.ListResourcesPage(ctx, "Pod", "web", "", "", 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 1 || next != "page-2" || remaining == nil || *remaining != 1 {
+		t.Errorf("Expected the first pod with a continue token and 1 remaining, got %v, %q, %v", pods, next, remaining)
+	}
+	pods, next, _, err = client.ListResourcesPage(ctx, "Pod", "web", "", "", 1, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 1 || pods[0]["metadata"].(map[string]interface{})["name"] != "web-2" || next != "" {
+		t.Errorf("Expected the last page to hold web-2 without a continue token, got %v, %q", pods, next)
+	}
+	if _, _, _, err := client.ListResourcesPage(ctx, "Pod", "web", "", "", 1, "stale"); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected an expired continue token to be reported, got %v", err)
+	}
+}
+
+// TestDeleteResource tests validation, the read-only gate, dry runs and errors of deleting a resource
+func TestDeleteResource(t *testing.T) {
+	pod := "/api/v1/namespaces/web/pods/api"
+	objects := map[string]string{pod: `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"api","namespace":"web"}}`}
+	client, mutations := newWorkloadTestClient(t, objects)
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	grace := int64(0)
+
+	if err := client.DeleteResource(ctx, "Pod", "api", "web", DeleteOptions{PropagationPolicy: "Cascade"}); err == nil || !strings.Contains(err.Error(), "invalid propagation policy") {
+		t.Errorf("Expected an error for an unknown propagation policy, got %v", err)
+	}
+	negative := int64(-1)
+	if err := client.DeleteResource(ctx, "Pod", "api", "web", DeleteOptions{GracePeriodSeconds: &negative}); err == nil || !strings.Contains(err.Error(), "grace period") {
+		t.Errorf("Expected an error for a negative grace period, got %v", err)
+	}
+	if err := readOnly.DeleteResource(ctx, "Pod", "api", "web", DeleteOptions{}); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected a read-only client to refuse the delete, got %v", err)
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("Expected refused deletes not to reach the server, got %v", *mutations)
+	}
+
+	if err := readOnly.DeleteResource(WithDryRun(ctx), "Pod", "api", "web", DeleteOptions{GracePeriodSeconds: &grace, PropagationPolicy: "Foreground"}); err != nil {
+		t.Fatalf("Expected a dry run to pass in read-only mode, got %v", err)
+	}
+	if len(*mutations) != 1 || (*mutations)[0].method != http.MethodDelete || (*mutations)[0].dryRun != "All" ||
+		!strings.Contains((*mutations)[0].body, `"gracePeriodSeconds":0`) || !strings.Contains((*mutations)[0].body, `"propagationPolicy":"Foreground"`) {
+		t.Errorf("Expected a dry-run delete with the given options, got %v", *mutations)
+	}
+
+	objects[pod] = conflictObject
+	if err := client.DeleteResource(ctx, "Pod", "api", "web", DeleteOptions{}); err == nil || !strings.Contains(err.Error(), "failed to delete resource") {
+		t.Errorf("Expected the API server's error to be returned, got %v", err)
+	}
+}
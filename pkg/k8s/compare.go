@@ -0,0 +1,335 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// comparableWorkload is the subset of a workload compared between namespaces.
+type comparableWorkload struct {
+	kind     string
+	name     string
+	replicas *int32
+	template corev1.PodSpec
+}
+
+// CompareNamespaces compares the Deployments, StatefulSets and DaemonSets of
+// two namespaces by kind and name, reporting workloads that exist in only one
+// of them and, for workloads in both, differences in replicas, container
+// images and environment variables. If includeConfig is true, ConfigMaps and
+// Secrets are compared by key as well; Secret values are compared but never
+// returned, and literal environment variables with credential-like names are
+// redacted.
+// Returns a map with "workloads" and, if requested, "config", or an error.
+func (c *Client) CompareNamespaces(ctx context.Context, first, second string, includeConfig bool) (map[string]interface{}, error) {
+	if first == "" || second == "" {
+		return nil, fmt.Errorf("both namespaces are required")
+	}
+
+	firstWorkloads, err := c.listComparableWorkloads(ctx, first)
+	if err != nil {
+		return nil, err
+	}
+	secondWorkloads, err := c.listComparableWorkloads(ctx, second)
+	if err != nil {
+		return nil, err
+	}
+
+	onlyInFirst := []string{}
+	onlyInSecond := []string{}
+	differences := []map[string]interface{}{}
+	identical := 0
+	for _, key := range unionKeys(firstWorkloads, secondWorkloads) {
+		a, inFirst := firstWorkloads[key]
+		b, inSecond := secondWorkloads[key]
+		switch {
+		case !inSecond:
+			onlyInFirst = append(onlyInFirst, key)
+		case !inFirst:
+			onlyInSecond = append(onlyInSecond, key)
+		default:
+			if diff := compareWorkloads(a, b); diff != nil {
+				differences = append(differences, diff)
+			} else {
+				identical++
+			}
+		}
+	}
+
+	report := map[string]interface{}{
+		"namespaces": []string{first, second},
+		"workloads": map[string]interface{}{
+			"onlyInFirst":  onlyInFirst,
+			"onlyInSecond": onlyInSecond,
+			"differences":  differences,
+			"identical":    identical,
+		},
+	}
+
+	if includeConfig {
+		firstConfig, err := c.listComparableConfig(ctx, first)
+		if err != nil {
+			return nil, err
+		}
+		secondConfig, err := c.listComparableConfig(ctx, second)
+		if err != nil {
+			return nil, err
+		}
+		report["config"] = compareConfig(firstConfig, secondConfig)
+	}
+	return report, nil
+}
+
+// listComparableWorkloads returns the Deployments, StatefulSets and DaemonSets
+// of a namespace keyed by "Kind/name".
+func (c *Client) listComparableWorkloads(ctx context.Context, namespace string) (map[string]comparableWorkload, error) {
+	workloads := map[string]comparableWorkload{}
+	add := func(w comparableWorkload) {
+		workloads[w.kind+"/"+w.name] = w
+	}
+
+	deployments, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Deployments in %s: %w", namespace, err)
+	}
+	for _, d := range deployments.Items {
+		add(comparableWorkload{kind: "Deployment", name: d.Name, replicas: d.Spec.Replicas, template: d.Spec.Template.Spec})
+	}
+	statefulSets, err := c.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list StatefulSets in %s: %w", namespace, err)
+	}
+	for _, s := range statefulSets.Items {
+		add(comparableWorkload{kind: "StatefulSet", name: s.Name, replicas: s.Spec.Replicas, template: s.Spec.Template.Spec})
+	}
+	daemonSets, err := c.clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DaemonSets in %s: %w", namespace, err)
+	}
+	for _, d := range daemonSets.Items {
+		add(comparableWorkload{kind: "DaemonSet", name: d.Name, template: d.Spec.Template.Spec})
+	}
+	return workloads, nil
+}
+
+// compareWorkloads returns the differences between two workloads of the same
+// kind and name, or nil if they match.
+func compareWorkloads(a, b comparableWorkload) map[string]interface{} {
+	diff := map[string]interface{}{"kind": a.kind, "name": a.name}
+	changed := false
+
+	if a.replicas != nil && b.replicas != nil && *a.replicas != *b.replicas {
+		diff["replicas"] = map[string]interface{}{"first": *a.replicas, "second": *b.replicas}
+		changed = true
+	}
+
+	firstContainers := containersByName(a.template)
+	secondContainers := containersByName(b.template)
+	var images, env []map[string]interface{}
+	var containersOnlyInFirst, containersOnlyInSecond []string
+	for _, name := range unionKeys(firstContainers, secondContainers) {
+		ca, inFirst := firstContainers[name]
+		cb, inSecond := secondContainers[name]
+		if !inSecond {
+			containersOnlyInFirst = append(containersOnlyInFirst, name)
+			continue
+		}
+		if !inFirst {
+			containersOnlyInSecond = append(containersOnlyInSecond, name)
+			continue
+		}
+		if ca.Image != cb.Image {
+			images = append(images, map[string]interface{}{"container": name, "first": ca.Image, "second": cb.Image})
+		}
+		firstEnv := describeEnv(ca.Env)
+		secondEnv := describeEnv(cb.Env)
+		for _, variable := range unionKeys(firstEnv, secondEnv) {
+			va, inFirst := firstEnv[variable]
+			vb, inSecond := secondEnv[variable]
+			if inFirst && inSecond && va == vb {
+				continue
+			}
+			entry := map[string]interface{}{"container": name, "variable": variable}
+			if sensitiveEnvName.MatchString(variable) {
+				va, vb = redactIfSet(va), redactIfSet(vb)
+			}
+			if inFirst {
+				entry["first"] = va
+			}
+			if inSecond {
+				entry["second"] = vb
+			}
+			env = append(env, entry)
+		}
+	}
+
+	if containersOnlyInFirst != nil {
+		diff["containersOnlyInFirst"] = containersOnlyInFirst
+		changed = true
+	}
+	if containersOnlyInSecond != nil {
+		diff["containersOnlyInSecond"] = containersOnlyInSecond
+		changed = true
+	}
+	if images != nil {
+		diff["images"] = images
+		changed = true
+	}
+	if env != nil {
+		diff["env"] = env
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return diff
+}
+
+// containersByName indexes the containers and init containers of a pod spec by name.
+func containersByName(spec corev1.PodSpec) map[string]corev1.Container {
+	containers := map[string]corev1.Container{}
+	for _, list := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, ctr := range list {
+			containers[ctr.Name] = ctr
+		}
+	}
+	return containers
+}
+
+// describeEnv renders each environment variable as a comparable string: the
+// literal value, or the source of a valueFrom reference.
+func describeEnv(env []corev1.EnvVar) map[string]string {
+	described := map[string]string{}
+	for _, e := range env {
+		switch {
+		case e.ValueFrom == nil:
+			described[e.Name] = e.Value
+		case e.ValueFrom.ConfigMapKeyRef != nil:
+			described[e.Name] = fmt.Sprintf("configMapKeyRef:%s/%s", e.ValueFrom.ConfigMapKeyRef.Name, e.ValueFrom.ConfigMapKeyRef.Key)
+		case e.ValueFrom.SecretKeyRef != nil:
+			described[e.Name] = fmt.Sprintf("secretKeyRef:%s/%s", e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key)
+		case e.ValueFrom.FieldRef != nil:
+			described[e.Name] = "fieldRef:" + e.ValueFrom.FieldRef.FieldPath
+		case e.ValueFrom.ResourceFieldRef != nil:
+			described[e.Name] = "resourceFieldRef:" + e.ValueFrom.ResourceFieldRef.Resource
+		}
+	}
+	return described
+}
+
+// redactIfSet replaces a non-empty value with the redaction marker.
+func redactIfSet(value string) string {
+	if value == "" {
+		return value
+	}
+	return redactedValue
+}
+
+// listComparableConfig returns the data of the ConfigMaps and Secrets of a
+// namespace keyed by "Kind/name". Service account tokens and Helm release
+// Secrets are skipped as they always differ between namespaces.
+func (c *Client) listComparableConfig(ctx context.Context, namespace string) (map[string]map[string][]byte, error) {
+	config := map[string]map[string][]byte{}
+
+	configMaps, err := c.clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ConfigMaps in %s: %w", namespace, err)
+	}
+	for _, cm := range configMaps.Items {
+		data := map[string][]byte{}
+		for key, value := range cm.Data {
+			data[key] = []byte(value)
+		}
+		for key, value := range cm.BinaryData {
+			data[key] = value
+		}
+		config["ConfigMap/"+cm.Name] = data
+	}
+
+	secrets, err := c.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Secrets in %s: %w", namespace, err)
+	}
+	for _, secret := range secrets.Items {
+		if secret.Type == corev1.SecretTypeServiceAccountToken || secret.Type == "helm.sh/release.v1" {
+			continue
+		}
+		config["Secret/"+secret.Name] = secret.Data
+	}
+	return config, nil
+}
+
+// compareConfig reports ConfigMaps and Secrets present in only one namespace
+// and, for those in both, the keys that were added, removed or changed.
+func compareConfig(first, second map[string]map[string][]byte) map[string]interface{} {
+	onlyInFirst := []string{}
+	onlyInSecond := []string{}
+	differences := []map[string]interface{}{}
+	for _, key := range unionKeys(first, second) {
+		a, inFirst := first[key]
+		b, inSecond := second[key]
+		if !inSecond {
+			onlyInFirst = append(onlyInFirst, key)
+			continue
+		}
+		if !inFirst {
+			onlyInSecond = append(onlyInSecond, key)
+			continue
+		}
+
+		var keysOnlyInFirst, keysOnlyInSecond, changedKeys []string
+		for _, dataKey := range unionKeys(a, b) {
+			va, inA := a[dataKey]
+			vb, inB := b[dataKey]
+			switch {
+			case !inB:
+				keysOnlyInFirst = append(keysOnlyInFirst, dataKey)
+			case !inA:
+				keysOnlyInSecond = append(keysOnlyInSecond, dataKey)
+			case !bytes.Equal(va, vb):
+				changedKeys = append(changedKeys, dataKey)
+			}
+		}
+		if keysOnlyInFirst == nil && keysOnlyInSecond == nil && changedKeys == nil {
+			continue
+		}
+		diff := map[string]interface{}{"object": key}
+		if keysOnlyInFirst != nil {
+			diff["keysOnlyInFirst"] = keysOnlyInFirst
+		}
+		if keysOnlyInSecond != nil {
+			diff["keysOnlyInSecond"] = keysOnlyInSecond
+		}
+		if changedKeys != nil {
+			diff["changedKeys"] = changedKeys
+		}
+		differences = append(differences, diff)
+	}
+
+	return map[string]interface{}{
+		"onlyInFirst":  onlyInFirst,
+		"onlyInSecond": onlyInSecond,
+		"differences":  differences,
+	}
+}
+
+// unionKeys returns the sorted union of the keys of two maps.
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
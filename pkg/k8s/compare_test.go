@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestCompareNamespaces tests comparing the workloads and configuration of two namespaces
+func TestCompareNamespaces(t *testing.T) {
+	objects := map[string]string{
+		"/apis/apps/v1/namespaces/staging/deployments": `{"kind":"DeploymentList","apiVersion":"apps/v1","items":[` +
+			`{"metadata":{"name":"api"},"spec":{"replicas":1,"template":{"spec":{"containers":[{"name":"app","image":"api:2",` +
+			`"env":[{"name":"LOG_LEVEL","value":"debug"},{"name":"DB_PASSWORD","value":"staging"}]}]}}}},` +
+			`{"metadata":{"name":"debug"},"spec":{"template":{"spec":{"containers":[{"name":"app","image":"debug"}]}}}}]}`,
+		"/apis/apps/v1/namespaces/prod/deployments": `{"kind":"DeploymentList","apiVersion":"apps/v1","items":[` +
+			`{"metadata":{"name":"api"},"spec":{"replicas":3,"template":{"spec":{"containers":[{"name":"app","image":"api:1",` +
+			`"env":[{"name":"LOG_LEVEL","value":"debug"},{"name":"DB_PASSWORD","value":"prod"}]},{"name":"proxy","image":"envoy"}]}}}}]}`,
+		"/apis/apps/v1/namespaces/staging/statefulsets": `{"kind":"StatefulSetList","apiVersion":"apps/v1","items":[` +
+			`{"metadata":{"name":"db"},"spec":{"replicas":1,"template":{"spec":{"containers":[{"name":"db","image":"postgres:16"}]}}}}]}`,
+		"/apis/apps/v1/namespaces/prod/statefulsets": `{"kind":"StatefulSetList","apiVersion":"apps/v1","items":[` +
+			`{"metadata":{"name":"db"},"spec":{"replicas":1,"template":{"spec":{"containers":[{"name":"db","image":"postgres:16"}]}}}}]}`,
+		"/apis/apps/v1/namespaces/staging/daemonsets": `{"kind":"DaemonSetList","apiVersion":"apps/v1","items":[]}`,
+		"/apis/apps/v1/namespaces/prod/daemonsets":    `{"kind":"DaemonSetList","apiVersion":"apps/v1","items":[]}`,
+		"/api/v1/namespaces/staging/configmaps": `{"kind":"ConfigMapList","apiVersion":"v1","items":[` +
+			`{"metadata":{"name":"settings"},"data":{"mode":"test","color":"blue"}}]}`,
+		"/api/v1/namespaces/prod/configmaps": `{"kind":"ConfigMapList","apiVersion":"v1","items":[` +
+			`{"metadata":{"name":"settings"},"data":{"mode":"live","color":"blue","region":"eu"}}]}`,
+		"/api/v1/namespaces/staging/secrets": `{"kind":"SecretList","apiVersion":"v1","items":[` +
+			`{"metadata":{"name":"creds"},"data":{"token":"YQ=="}},{"metadata":{"name":"sh.helm.release.v1.api.v1"},"type":"helm.sh/release.v1","data":{"release":"YQ=="}}]}`,
+		"/api/v1/namespaces/prod/secrets": `{"kind":"SecretList","apiVersion":"v1","items":[` +
+			`{"metadata":{"name":"creds"},"data":{"token":"Yg=="}}]}`,
+	}
+	client := newPodConfigTestClient(t, objects)
+	ctx := context.Background()
+
+	if _, err := client.CompareNamespaces(ctx, "staging", "", false); err == nil {
+		t.Error("Expected an error without a second namespace")
+	}
+
+	result, err := client.CompareNamespaces(ctx, "staging", "prod", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workloads := result["workloads"].(map[string]interface{})
+	if only := workloads["onlyInFirst"].([]string); len(only) != 1 || only[0] != "Deployment/debug" || workloads["identical"] != 1 {
+		t.Errorf("Expected the staging-only Deployment and the identical StatefulSet, got %v", workloads)
+	}
+	differences := workloads["differences"].([]map[string]interface{})
+	if len(differences) != 1 {
+		t.Fatalf("Expected one differing workload, got %v", differences)
+	}
+	api := differences[0]
+	if replicas := api["replicas"].(map[string]interface{}); replicas["first"] != int32(1) || replicas["second"] != int32(3) {
+		t.Errorf("Expected the replica difference, got %v", replicas)
+	}
+	if images := api["images"].([]map[string]interface{}); len(images) != 1 || images[0]["first"] != "api:2" || images[0]["second"] != "api:1" {
+		t.Errorf("Expected the image difference, got %v", images)
+	}
+	if containers := api["containersOnlyInSecond"].([]string); len(containers) != 1 || containers[0] != "proxy" {
+		t.Errorf("Expected the prod-only container, got %v", containers)
+	}
+	env := api["env"].([]map[string]interface{})
+	if len(env) != 1 || env[0]["variable"] != "DB_PASSWORD" || env[0]["first"] != redactedValue || env[0]["second"] != redactedValue {
+		t.Errorf("Expected only the differing credential, redacted, got %v", env)
+	}
+
+	config := result["config"].(map[string]interface{})
+	if len(config["onlyInFirst"].([]string)) != 0 {
+		t.Errorf("Expected Helm release Secrets to be skipped, got %v", config["onlyInFirst"])
+	}
+	configDifferences := config["differences"].([]map[string]interface{})
+	if len(configDifferences) != 2 || configDifferences[0]["object"] != "ConfigMap/settings" || configDifferences[1]["object"] != "Secret/creds" {
+		t.Fatalf("Expected the differing ConfigMap and Secret, got %v", configDifferences)
+	}
+	settings := configDifferences[0]
+	if changed := settings["changedKeys"].([]string); len(changed) != 1 || changed[0] != "mode" || settings["keysOnlyInSecond"].([]string)[0] != "region" {
+		t.Errorf("Expected the changed and added keys, got %v", settings)
+	}
+	if creds := configDifferences[1]; len(creds) != 2 || creds["changedKeys"].([]string)[0] != "token" {
+		t.Errorf("Expected only the changed Secret key without its values, got %v", creds)
+	}
+
+	delete(objects, "/apis/apps/v1/namespaces/prod/daemonsets")
+	if _, err := client.CompareNamespaces(ctx, "staging", "prod", false); err == nil || !strings.Contains(err.Error(), "failed to list DaemonSets in prod") {
+		t.Errorf("Expected the listing error to be returned, got %v", err)
+	}
+}
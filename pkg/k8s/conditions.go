@@ -0,0 +1,150 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// negativePolarityConditions are condition types that report a problem when
+// True, such as node pressure conditions.
+var negativePolarityConditions = map[string]bool{
+	"MemoryPressure":     true,
+	"DiskPressure":       true,
+	"PIDPressure":        true,
+	"NetworkUnavailable": true,
+	"ReplicaFailure":     true,
+	"DisruptionTarget":   true,
+	"Failed":             true,
+	"FailureTarget":      true,
+	"Degraded":           true,
+	"Stalled":            true,
+}
+
+// GetConditions collects the status.conditions of the objects of the given
+// kinds, or of the named object of each kind, and normalizes them to the
+// object reference, type, status, reason, message, lastTransitionTime and age.
+// Each condition is flagged abnormal when it is Unknown, False for a
+// positive-polarity type such as Ready, or True for a negative-polarity type
+// such as MemoryPressure. Conditions can be filtered by status or to abnormal
+// ones only; abnormal conditions are listed first. Kinds that cannot be listed
+// are reported in "errors".
+// Returns a map with "conditions", "objectsScanned" and "errors", or an error.
+func (c *Client) GetConditions(ctx context.Context, kinds []string, namespace, name, labelSelector, status string, abnormalOnly bool) (map[string]interface{}, error) {
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("at least one kind is required")
+	}
+
+	now := time.Now()
+	conditions := []map[string]interface{}{}
+	errors := []string{}
+	scanned := 0
+	for _, kind := range kinds {
+		var objects []map[string]interface{}
+		if name != "" {
+			object, err := c.GetResource(ctx, kind, name, namespace)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("%s/%s: %v", kind, name, err))
+				continue
+			}
+			objects = append(objects, object)
+		} else {
+			list, err := c.ListResources(ctx, kind, namespace, labelSelector, "")
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("%s: %v", kind, err))
+				continue
+			}
+			objects = list
+		}
+
+		for _, object := range objects {
+			scanned++
+			for _, condition := range normalizeConditions(&unstructured.Unstructured{Object: object}, now) {
+				if status != "" && condition["status"] != status {
+					continue
+				}
+				if abnormalOnly && condition["abnormal"] != true {
+					continue
+				}
+				conditions = append(conditions, condition)
+			}
+		}
+	}
+
+	sort.SliceStable(conditions, func(i, j int) bool {
+		a, b := conditions[i], conditions[j]
+		if a["abnormal"] != b["abnormal"] {
+			return a["abnormal"] == true
+		}
+		for _, key := range []string{"kind", "namespace", "name", "type"} {
+			if a[key] != b[key] {
+				return fmt.Sprint(a[key]) < fmt.Sprint(b[key])
+			}
+		}
+		return false
+	})
+
+	return map[string]interface{}{
+		"conditions":     conditions,
+		"objectsScanned": scanned,
+		"errors":         errors,
+	}, nil
+}
+
+// normalizeConditions returns the status.conditions of obj with a reference
+// to obj, the age of each condition and whether it is abnormal.
+func normalizeConditions(obj *unstructured.Unstructured, now time.Time) []map[string]interface{} {
+	rawConditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	var conditions []map[string]interface{}
+	for _, rc := range rawConditions {
+		condition, ok := rc.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		status, _ := condition["status"].(string)
+		normalized := map[string]interface{}{
+			"kind":     obj.GetKind(),
+			"name":     obj.GetName(),
+			"type":     conditionType,
+			"status":   status,
+			"abnormal": conditionAbnormal(conditionType, status),
+		}
+		if obj.GetNamespace() != "" {
+			normalized["namespace"] = obj.GetNamespace()
+		}
+		for _, key := range []string{"reason", "message"} {
+			if value, ok := condition[key].(string); ok && value != "" {
+				normalized[key] = value
+			}
+		}
+		// Some kinds, such as Nodes, only report a heartbeat time
+		for _, key := range []string{"lastTransitionTime", "lastUpdateTime", "lastHeartbeatTime"} {
+			value, _ := condition[key].(string)
+			if transition, err := time.Parse(time.RFC3339, value); err == nil {
+				normalized["lastTransitionTime"] = value
+				normalized["age"] = duration.HumanDuration(now.Sub(transition))
+				break
+			}
+		}
+		conditions = append(conditions, normalized)
+	}
+	return conditions
+}
+
+// conditionAbnormal reports whether a condition indicates a problem, taking
+// the polarity of its type into account.
+func conditionAbnormal(conditionType, status string) bool {
+	switch status {
+	case "True":
+		return negativePolarityConditions[conditionType]
+	case "False":
+		return !negativePolarityConditions[conditionType]
+	default:
+		return true
+	}
+}
@@ -0,0 +1,45 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestNormalizeConditions tests normalizing conditions and flagging abnormal ones by polarity
+func TestNormalizeConditions(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	node := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Node",
+		"metadata": map[string]interface{}{"name": "node-a"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True", "lastTransitionTime": "2026-01-01T09:00:00Z"},
+				map[string]interface{}{"type": "MemoryPressure", "status": "True", "reason": "KubeletHasInsufficientMemory", "lastHeartbeatTime": "2026-01-01T11:59:00Z"},
+				map[string]interface{}{"type": "DiskPressure", "status": "False"},
+				map[string]interface{}{"type": "NetworkUnavailable", "status": "Unknown"},
+			},
+		},
+	}}
+
+	conditions := normalizeConditions(node, now)
+	if len(conditions) != 4 {
+		t.Fatalf("Expected 4 conditions, got %d", len(conditions))
+	}
+	want := map[string]bool{"Ready": false, "MemoryPressure": true, "DiskPressure": false, "NetworkUnavailable": true}
+	for _, condition := range conditions {
+		if condition["abnormal"] != want[condition["type"].(string)] {
+			t.Errorf("Unexpected abnormal flag for %v", condition)
+		}
+		if _, ok := condition["namespace"]; ok {
+			t.Errorf("Expected no namespace for a cluster-scoped object, got %v", condition)
+		}
+	}
+	if conditions[0]["age"] != "3h" || conditions[1]["age"] != "60s" {
+		t.Errorf("Unexpected ages %v and %v", conditions[0]["age"], conditions[1]["age"])
+	}
+	if conditions[1]["reason"] != "KubeletHasInsufficientMemory" {
+		t.Errorf("Expected the reason, got %v", conditions[1])
+	}
+}
@@ -0,0 +1,192 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// templateConsumers returns the Deployments, StatefulSets, DaemonSets and
+// CronJobs in namespace whose pod template references the ConfigMap or
+// Secret name, with the references. Workloads are found by their template
+// rather than their pods, so those scaled to zero are included. CronJobs are
+// not restartable; their next Job reads the new value. Lists that fail are
+// added to errors.
+func (c *Client) templateConsumers(ctx context.Context, namespace, kind, name string, errors *[]string) []map[string]interface{} {
+	type template struct {
+		kind string
+		name string
+		spec corev1.PodSpec
+	}
+	var templates []template
+
+	if list, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		*errors = append(*errors, fmt.Sprintf("failed to list deployments: %v", err))
+	} else {
+		for _, item := range list.Items {
+			templates = append(templates, template{"Deployment", item.Name, item.Spec.Template.Spec})
+		}
+	}
+	if list, err := c.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		*errors = append(*errors, fmt.Sprintf("failed to list statefulsets: %v", err))
+	} else {
+		for _, item := range list.Items {
+			templates = append(templates, template{"StatefulSet", item.Name, item.Spec.Template.Spec})
+		}
+	}
+	if list, err := c.clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		*errors = append(*errors, fmt.Sprintf("failed to list daemonsets: %v", err))
+	} else {
+		for _, item := range list.Items {
+			templates = append(templates, template{"DaemonSet", item.Name, item.Spec.Template.Spec})
+		}
+	}
+	if list, err := c.clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		*errors = append(*errors, fmt.Sprintf("failed to list cronjobs: %v", err))
+	} else {
+		for _, item := range list.Items {
+			templates = append(templates, template{"CronJob", item.Name, item.Spec.JobTemplate.Spec.Template.Spec})
+		}
+	}
+
+	consumers := []map[string]interface{}{}
+	for _, t := range templates {
+		references := podObjectReferences(&corev1.Pod{Spec: t.spec}, kind, name)
+		if len(references) == 0 {
+			continue
+		}
+		restartRequired := false
+		for _, ref := range references {
+			restartRequired = restartRequired || ref["restartRequired"].(bool)
+		}
+		consumers = append(consumers, map[string]interface{}{
+			"kind":            t.kind,
+			"name":            t.name,
+			"references":      references,
+			"restartRequired": restartRequired,
+			"restartable":     t.kind != "CronJob",
+		})
+	}
+	return consumers
+}
+
+// GetConfigMapUsage lists the consumers of a ConfigMap, to see the impact of
+// changing it: the Deployments, StatefulSets, DaemonSets and CronJobs whose
+// pod template references it through environment variables, envFrom or
+// volumes, and bare pods without a controller that do. Each consumer reports
+// whether it only sees a change after a restart, because it reads the
+// ConfigMap into environment variables or through subPath mounts; other
+// volumes are updated in place, though many applications only read them at
+// startup. Lookups that fail are reported in "errors".
+// Returns a map with the "configMap", its "namespace", whether it "exists",
+// its "keys", and the consuming "workloads" and "pods", or an error.
+func (c *Client) GetConfigMapUsage(ctx context.Context, namespace, name string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	result := map[string]interface{}{"configMap": name, "namespace": namespace}
+	var errors []string
+
+	cm, err := c.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		result["exists"] = false
+	case err != nil:
+		errors = append(errors, fmt.Sprintf("failed to get configmap: %v", err))
+	default:
+		result["exists"] = true
+		keys := []string{}
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		for key := range cm.BinaryData {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result["keys"] = keys
+	}
+
+	result["workloads"] = c.templateConsumers(ctx, namespace, "ConfigMap", name, &errors)
+
+	// Pods of workloads are covered by their template; report the others
+	pods := []map[string]interface{}{}
+	podList, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("failed to list pods: %v", err))
+	} else {
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if metav1.GetControllerOf(pod) != nil {
+				continue
+			}
+			if references := podObjectReferences(pod, "ConfigMap", name); len(references) > 0 {
+				pods = append(pods, map[string]interface{}{"name": pod.Name, "phase": string(pod.Status.Phase), "references": references})
+			}
+		}
+	}
+	result["pods"] = pods
+
+	if len(errors) > 0 {
+		result["errors"] = errors
+	}
+	return result, nil
+}
+
+// RestartConfigMapConsumers triggers a rollout restart of exactly the
+// Deployments, StatefulSets and DaemonSets whose pod template references a
+// ConfigMap, so they pick up a change to it. If onlyRestartRequired is set,
+// consumers that mount it as a volume without subPath, which the kubelet
+// updates in place, are skipped. CronJobs and bare pods are never restarted.
+// Each restart is recorded for undo like rolloutRestart.
+// Returns a map with the "restarted" and "skipped" workloads and the
+// "errors" of failed restarts, or an error if the ConfigMap does not exist,
+// since restarting its consumers would then fail their pods.
+func (c *Client) RestartConfigMapConsumers(ctx context.Context, namespace, name string, onlyRestartRequired bool) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	if _, err := c.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", namespace, name, err)
+	}
+
+	var errors []string
+	consumers := c.templateConsumers(ctx, namespace, "ConfigMap", name, &errors)
+	if len(errors) > 0 {
+		// A partial list would silently leave consumers running the old config
+		return nil, fmt.Errorf("failed to find the consumers of configmap %s/%s: %s", namespace, name, errors[0])
+	}
+
+	restarted := []map[string]interface{}{}
+	skipped := []map[string]interface{}{}
+	for _, consumer := range consumers {
+		kind, workload := consumer["kind"].(string), consumer["name"].(string)
+		switch {
+		case !consumer["restartable"].(bool):
+			skipped = append(skipped, map[string]interface{}{"kind": kind, "name": workload, "reason": "its next Job reads the new ConfigMap"})
+			continue
+		case onlyRestartRequired && !consumer["restartRequired"].(bool):
+			skipped = append(skipped, map[string]interface{}{"kind": kind, "name": workload, "reason": "mounted volumes are updated in place"})
+			continue
+		}
+		if _, err := c.RolloutRestart(ctx, kind, workload, namespace); err != nil {
+			errors = append(errors, err.Error())
+			continue
+		}
+		restarted = append(restarted, map[string]interface{}{"kind": kind, "name": workload})
+	}
+
+	result := map[string]interface{}{
+		"configMap": name,
+		"namespace": namespace,
+		"restarted": restarted,
+		"skipped":   skipped,
+	}
+	if len(errors) > 0 {
+		result["errors"] = errors
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestRestartConfigMapConsumers tests restarting exactly the workloads whose template references a ConfigMap
+func TestRestartConfigMapConsumers(t *testing.T) {
+	var mu sync.Mutex
+	var patched []string
+	template := func(volumes string) string {
+		return `{"spec":{"containers":[{"name":"app","image":"app","volumeMounts":[{"name":"config","mountPath":"/etc/app"}]}],"volumes":[` + volumes + `]}}`
+	}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case r.URL.Path == "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}],"preferredVersion":{"groupVersion":"apps/v1","version":"v1"}}]}`))
+		case r.URL.Path == "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[{"name":"configmaps","namespaced":true,"kind":"ConfigMap","verbs":["get"]}]}`))
+		case r.URL.Path == "/apis/apps/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[` +
+				`{"name":"deployments","namespaced":true,"kind":"Deployment","verbs":["get","list","patch"]}]}`))
+		case r.URL.Path == "/api/v1/namespaces/shop/configmaps/app-config":
+			w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"app-config","namespace":"shop"},"data":{"app.yaml":""}}`))
+		case r.URL.Path == "/apis/apps/v1/namespaces/shop/deployments" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"kind":"DeploymentList","apiVersion":"apps/v1","items":[` +
+				`{"metadata":{"name":"web","namespace":"shop"},"spec":{"template":` + template(`{"name":"config","configMap":{"name":"app-config"}}`) + `}},` +
+				`{"metadata":{"name":"worker","namespace":"shop"},"spec":{"template":` + template(`{"name":"config","configMap":{"name":"other"}}`) + `}}]}`))
+		case r.URL.Path == "/apis/apps/v1/namespaces/shop/statefulsets":
+			w.Write([]byte(`{"kind":"StatefulSetList","apiVersion":"apps/v1","items":[]}`))
+		case r.URL.Path == "/apis/apps/v1/namespaces/shop/daemonsets":
+			w.Write([]byte(`{"kind":"DaemonSetList","apiVersion":"apps/v1","items":[]}`))
+		case r.URL.Path == "/apis/batch/v1/namespaces/shop/cronjobs":
+			w.Write([]byte(`{"kind":"CronJobList","apiVersion":"batch/v1","items":[` +
+				`{"metadata":{"name":"report","namespace":"shop"},"spec":{"schedule":"@daily","jobTemplate":{"spec":{"template":` + template(`{"name":"config","configMap":{"name":"app-config"}}`) + `}}}}]}`))
+		case strings.HasPrefix(r.URL.Path, "/apis/apps/v1/namespaces/shop/deployments/") && r.Method == http.MethodPatch:
+			name := strings.TrimPrefix(r.URL.Path, "/apis/apps/v1/namespaces/shop/deployments/")
+			mu.Lock()
+			patched = append(patched, name)
+			mu.Unlock()
+			w.Write([]byte(`{"kind":"Deployment","apiVersion":"apps/v1","metadata":{"name":"` + name + `","namespace":"shop"},"spec":{"template":{}}}`))
+		case strings.HasPrefix(r.URL.Path, "/apis/apps/v1/namespaces/shop/deployments/"):
+			w.Write([]byte(`{"kind":"Deployment","apiVersion":"apps/v1","metadata":{"name":"web","namespace":"shop"},"spec":{"template":{}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.RestartConfigMapConsumers(context.Background(), "shop", "app-config", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restarted := result["restarted"].([]map[string]interface{}); len(restarted) != 1 || restarted[0]["name"] != "web" {
+		t.Errorf("Expected only web to be restarted, got %v", restarted)
+	}
+	if skipped := result["skipped"].([]map[string]interface{}); len(skipped) != 1 || skipped[0]["kind"] != "CronJob" {
+		t.Errorf("Expected the CronJob to be skipped, got %v", skipped)
+	}
+	if len(patched) != 1 || patched[0] != "web" {
+		t.Errorf("Expected only web to be patched, got %v", patched)
+	}
+
+	// A volume without subPath is updated in place
+	result, err = client.RestartConfigMapConsumers(context.Background(), "shop", "app-config", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restarted := result["restarted"].([]map[string]interface{}); len(restarted) != 0 {
+		t.Errorf("Expected no restarts with onlyRestartRequired, got %v", restarted)
+	}
+}
@@ -0,0 +1,173 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ClientPool holds a Client per kubeconfig context, so that one server can
+// serve several clusters. The client of the context the server started with
+// is created up front; the clients of other contexts are created on first use
+// and kept for the lifetime of the server.
+type ClientPool struct {
+	kubeconfig    string
+	defaultClient *Client
+	mu            sync.Mutex
+	clients       map[string]*Client
+	impersonating map[impersonationKey]*Client
+}
+
+// NewClientPool creates a ClientPool for the contexts of the kubeconfig file,
+// with defaultClient serving calls that name no context. If kubeconfigPath is
+// empty, it defaults to ~/.kube/config.
+func NewClientPool(kubeconfigPath string, defaultClient *Client) *ClientPool {
+	clients := map[string]*Client{}
+	if defaultClient.ContextName() != "" {
+		clients[defaultClient.ContextName()] = defaultClient
+	}
+	return &ClientPool{
+		kubeconfig:    resolveKubeconfigPath(kubeconfigPath),
+		defaultClient: defaultClient,
+		clients:       clients,
+		impersonating: map[impersonationKey]*Client{},
+	}
+}
+
+// Default returns the client of the context the server started with.
+func (p *ClientPool) Default() *Client {
+	return p.defaultClient
+}
+
+// Get returns the client of the named kubeconfig context, creating it on first
+// use with the safeguards of the default client: read-only mode, the
+// namespace policy and the large scan threshold. An empty name returns the
+// default client.
+func (p *ClientPool) Get(contextName string) (*Client, error) {
+	if contextName == "" {
+		return p.defaultClient, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if client, ok := p.clients[contextName]; ok {
+		return client, nil
+	}
+
+	rawConfig, err := p.rawConfig()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := rawConfig.Contexts[contextName]; !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig; available contexts: %s",
+			contextName, strings.Join(contextNames(rawConfig), ", "))
+	}
+	client, err := NewClientForContext(p.kubeconfig, contextName)
+	if err != nil {
+		return nil, err
+	}
+	if p.defaultClient.ReadOnly() {
+		if client, err = client.WithReadOnly(); err != nil {
+			return nil, err
+		}
+	}
+	if check := p.defaultClient.namespaceCheck; check != nil {
+		if client, err = client.WithNamespacePolicy(check); err != nil {
+			return nil, err
+		}
+	}
+	client.scanThreshold = p.defaultClient.scanThreshold
+	p.clients[contextName] = client
+	return client, nil
+}
+
+// Clients returns the clients created so far, the default client first.
+func (p *ClientPool) Clients() []*Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.clients))
+	for name, client := range p.clients {
+		if client != p.defaultClient {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	clients := []*Client{p.defaultClient}
+	for _, name := range names {
+		clients = append(clients, p.clients[name])
+	}
+	return clients
+}
+
+// ListContexts lists the contexts of the kubeconfig file with the cluster,
+// API server, user and namespace each refers to. The context the server
+// started with is marked "default", the kubeconfig's current context
+// "current", and contexts whose client has been created "connected".
+// Returns a map with the "contexts" and the "defaultContext", or an error if
+// the kubeconfig cannot be read.
+func (p *ClientPool) ListContexts() (map[string]interface{}, error) {
+	rawConfig, err := p.rawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	connected := map[string]bool{}
+	for name := range p.clients {
+		connected[name] = true
+	}
+	p.mu.Unlock()
+
+	return map[string]interface{}{
+		"contexts":       summarizeContexts(rawConfig, p.defaultClient.ContextName(), connected),
+		"defaultContext": p.defaultClient.ContextName(),
+	}, nil
+}
+
+// rawConfig reads the kubeconfig file.
+func (p *ClientPool) rawConfig() (clientcmdapi.Config, error) {
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: p.kubeconfig},
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err != nil {
+		return clientcmdapi.Config{}, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	return rawConfig, nil
+}
+
+// summarizeContexts describes the contexts of a kubeconfig, sorted by name.
+func summarizeContexts(rawConfig clientcmdapi.Config, defaultContext string, connected map[string]bool) []map[string]interface{} {
+	contexts := []map[string]interface{}{}
+	for _, name := range contextNames(rawConfig) {
+		kubeContext := rawConfig.Contexts[name]
+		summary := map[string]interface{}{
+			"name":      name,
+			"cluster":   kubeContext.Cluster,
+			"user":      kubeContext.AuthInfo,
+			"namespace": kubeContext.Namespace,
+			"current":   name == rawConfig.CurrentContext,
+			"default":   name == defaultContext,
+			"connected": connected[name],
+		}
+		if cluster, ok := rawConfig.Clusters[kubeContext.Cluster]; ok {
+			summary["server"] = cluster.Server
+		}
+		contexts = append(contexts, summary)
+	}
+	return contexts
+}
+
+// contextNames returns the names of the contexts of a kubeconfig, sorted.
+func contextNames(rawConfig clientcmdapi.Config) []string {
+	names := make([]string, 0, len(rawConfig.Contexts))
+	for name := range rawConfig.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
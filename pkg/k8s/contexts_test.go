@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod-cluster
+  cluster:
+    server: https://prod.example.com:6443
+- name: staging-cluster
+  cluster:
+    server: https://staging.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: admin
+- name: staging
+  context:
+    cluster: staging-cluster
+    user: admin
+    namespace: web
+`
+
+// TestClientPool tests creating and caching clients per kubeconfig context
+func TestClientPool(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	defaultClient, err := NewClient(kubeconfig)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	pool := NewClientPool(kubeconfig, defaultClient)
+
+	if client, err := pool.Get(""); err != nil || client != defaultClient {
+		t.Errorf("Expected the default client for an empty context, got %v, %v", client, err)
+	}
+	if client, err := pool.Get("prod"); err != nil || client != defaultClient {
+		t.Errorf("Expected the default client for its own context, got %v, %v", client, err)
+	}
+	staging, err := pool.Get("staging")
+	if err != nil {
+		t.Fatalf("Get(staging) failed: %v", err)
+	}
+	if staging.ContextName() != "staging" || staging.ServerHost() != "https://staging.example.com:6443" {
+		t.Errorf("Unexpected staging client %s at %s", staging.ContextName(), staging.ServerHost())
+	}
+	if again, _ := pool.Get("staging"); again != staging {
+		t.Errorf("Expected the staging client to be cached")
+	}
+	if _, err := pool.Get("dev"); err == nil || !strings.Contains(err.Error(), "prod, staging") {
+		t.Errorf("Expected an error listing the available contexts, got %v", err)
+	}
+	if clients := pool.Clients(); len(clients) != 2 || clients[0] != defaultClient {
+		t.Errorf("Unexpected clients %v", clients)
+	}
+
+	listed, err := pool.ListContexts()
+	if err != nil {
+		t.Fatalf("ListContexts failed: %v", err)
+	}
+	contexts := listed["contexts"].([]map[string]interface{})
+	if len(contexts) != 2 {
+		t.Fatalf("Expected 2 contexts, got %v", contexts)
+	}
+	if contexts[0]["name"] != "prod" || contexts[0]["default"] != true || contexts[0]["current"] != true {
+		t.Errorf("Unexpected prod context %v", contexts[0])
+	}
+	if contexts[1]["namespace"] != "web" || contexts[1]["connected"] != true || contexts[1]["server"] != "https://staging.example.com:6443" {
+		t.Errorf("Unexpected staging context %v", contexts[1])
+	}
+}
@@ -0,0 +1,173 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// containerFieldPathPattern matches the field path of an event that concerns
+// one container of a pod, e.g. "spec.containers{web}".
+var containerFieldPathPattern = regexp.MustCompile(`^spec\.(?:initContainers|containers|ephemeralContainers)\{(.+)\}$`)
+
+// previousInstanceReasons are the event reasons reported after a container
+// terminated, whose cause is in the log of the previous container instance.
+var previousInstanceReasons = map[string]bool{
+	"BackOff":          true,
+	"CrashLoopBackOff": true,
+	"Killing":          true,
+	"OOMKilling":       true,
+}
+
+// CorrelateEventLogs pairs the Warning events of pods in a namespace with the
+// log lines their container wrote around the time of the event. Events can be
+// narrowed to one by eventName, or by podName and reason; the most recent
+// maxEvents are correlated. The container is taken from the event's field
+// path, or else every container of the pod is read. For events such as
+// BackOff, which follow a crash, the previous container instance is read
+// first. Only lines from before ahead of the event until after it are
+// returned, at most maxLines per container, keeping the latest.
+// Returns a map with the "correlations", each holding the "event" and its
+// "logs", and the number of "matchingEvents", or an error.
+func (c *Client) CorrelateEventLogs(ctx context.Context, namespace, eventName, podName, reason string, before, after time.Duration, maxEvents, maxLines int) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	events, err := c.listNormalizedEvents(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var warnings []map[string]interface{}
+	for _, event := range events {
+		involved, _ := event["involvedObject"].(map[string]interface{})
+		switch {
+		case event["type"] != corev1.EventTypeWarning, involved["kind"] != "Pod":
+			continue
+		case eventName != "" && event["name"] != eventName:
+			continue
+		case podName != "" && involved["name"] != podName:
+			continue
+		case reason != "" && event["reason"] != reason:
+			continue
+		}
+		warnings = append(warnings, event)
+	}
+	matching := len(warnings)
+	warnings = sortFilterLimitEvents(warnings, maxEvents, "lastTime", "")
+
+	correlations := []map[string]interface{}{}
+	for _, event := range warnings {
+		correlations = append(correlations, map[string]interface{}{
+			"event": event,
+			"logs":  c.eventLogWindows(ctx, namespace, event, before, after, maxLines),
+		})
+	}
+
+	return map[string]interface{}{
+		"correlations":   correlations,
+		"matchingEvents": matching,
+	}, nil
+}
+
+// eventLogWindows reads the log window around an event for each container the
+// event concerns. Containers whose log cannot be read carry an "error".
+func (c *Client) eventLogWindows(ctx context.Context, namespace string, event map[string]interface{}, before, after time.Duration, maxLines int) []map[string]interface{} {
+	involved, _ := event["involvedObject"].(map[string]interface{})
+	podName, _ := involved["name"].(string)
+	fieldPath, _ := involved["fieldPath"].(string)
+	eventReason, _ := event["reason"].(string)
+	eventTime, _ := event["lastTime"].(time.Time)
+	from, to := eventTime.Add(-before), eventTime.Add(after)
+
+	windows := []map[string]interface{}{}
+	containers := []string{}
+	if container := containerFromFieldPath(fieldPath); container != "" {
+		containers = append(containers, container)
+	} else {
+		pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return append(windows, map[string]interface{}{"pod": podName, "error": err.Error()})
+		}
+		for _, ctr := range pod.Spec.Containers {
+			containers = append(containers, ctr.Name)
+		}
+	}
+
+	for _, container := range containers {
+		window := map[string]interface{}{
+			"pod":       podName,
+			"container": container,
+			"from":      from,
+			"to":        to,
+		}
+		opts := LogOptions{Timestamps: true, Previous: previousInstanceReasons[eventReason]}
+		lines, err := c.fetchContainerLogSince(ctx, namespace, podName, container, opts, from)
+		if err != nil && opts.Previous {
+			// The previous instance is gone, e.g. the pod was recreated
+			opts.Previous = false
+			lines, err = c.fetchContainerLogSince(ctx, namespace, podName, container, opts, from)
+		}
+		if err != nil {
+			window["error"] = err.Error()
+			windows = append(windows, window)
+			continue
+		}
+		inWindow := logWindow(lines, from, to)
+		window["previous"] = opts.Previous
+		window["truncated"] = maxLines > 0 && len(inWindow) > maxLines
+		if maxLines > 0 && len(inWindow) > maxLines {
+			inWindow = inWindow[len(inWindow)-maxLines:]
+		}
+		text := make([]string, 0, len(inWindow))
+		for _, line := range inWindow {
+			text = append(text, line.text)
+		}
+		window["lines"] = text
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+// fetchContainerLogSince reads the log of a container from since onwards.
+func (c *Client) fetchContainerLogSince(ctx context.Context, namespace, pod, container string, opts LogOptions, since time.Time) ([]logLine, error) {
+	podLogOptions := opts.podLogOptions()
+	podLogOptions.Container = container
+	if !since.IsZero() {
+		sinceTime := metav1.NewTime(since)
+		podLogOptions.SinceTime = &sinceTime
+	}
+	stream, err := c.clientset.CoreV1().Pods(namespace).GetLogs(pod, podLogOptions).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+	defer stream.Close()
+	return parseLogLines(stream, opts.Timestamps)
+}
+
+// containerFromFieldPath returns the container name referenced by an event's
+// field path, or "" when the event concerns the pod as a whole.
+func containerFromFieldPath(fieldPath string) string {
+	if match := containerFieldPathPattern.FindStringSubmatch(strings.TrimSpace(fieldPath)); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// logWindow returns the timestamped lines written between from and to. Lines
+// without a timestamp of their own inherit that of the line before.
+func logWindow(lines []logLine, from, to time.Time) []logLine {
+	var window []logLine
+	for _, line := range lines {
+		if line.time.IsZero() || line.time.Before(from) || line.time.After(to) {
+			continue
+		}
+		window = append(window, line)
+	}
+	return window
+}
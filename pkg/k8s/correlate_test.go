@@ -0,0 +1,44 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestContainerFromFieldPath tests resolving the container an event refers to
+func TestContainerFromFieldPath(t *testing.T) {
+	tests := map[string]string{
+		"spec.containers{web}":         "web",
+		"spec.initContainers{migrate}": "migrate",
+		"":                             "",
+		"spec.volumes":                 "",
+	}
+	for fieldPath, want := range tests {
+		if got := containerFromFieldPath(fieldPath); got != want {
+			t.Errorf("containerFromFieldPath(%q) = %q, want %q", fieldPath, got, want)
+		}
+	}
+}
+
+// TestLogWindow tests keeping only the log lines written around an event
+func TestLogWindow(t *testing.T) {
+	log := "2026-01-01T10:00:00Z booting\n" +
+		"2026-01-01T10:01:00Z connecting to db\n" +
+		"  retrying\n" +
+		"2026-01-01T10:02:00Z panic: no db\n" +
+		"2026-01-01T10:05:00Z restarted\n"
+	lines, err := parseLogLines(strings.NewReader(log), true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	eventTime := time.Date(2026, 1, 1, 10, 2, 10, 0, time.UTC)
+	window := logWindow(lines, eventTime.Add(-90*time.Second), eventTime.Add(30*time.Second))
+	if len(window) != 3 {
+		t.Fatalf("Expected 3 lines in the window, got %d: %v", len(window), window)
+	}
+	if window[1].text != "  retrying" || !strings.HasSuffix(window[2].text, "panic: no db") {
+		t.Errorf("Unexpected window %v", window)
+	}
+}
@@ -0,0 +1,388 @@
+package k8s
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// maxDiagnosticPods bounds the number of pods whose manifests and logs
+	// are collected into a bundle.
+	maxDiagnosticPods = 50
+	// maxDiagnosticLogBytes bounds the size of each collected container log.
+	maxDiagnosticLogBytes = 1 << 20
+)
+
+// diagnosticKinds are the kinds whose manifests are collected into a bundle.
+// Secrets are deliberately left out.
+var diagnosticKinds = []string{
+	"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob", "Pod",
+	"Service", "PersistentVolumeClaim", "HorizontalPodAutoscaler", "Ingress", "ConfigMap",
+}
+
+// diagnosticsBundle accumulates the files of a diagnostic bundle in a gzipped
+// tar archive, recording the collection errors that did not stop it.
+type diagnosticsBundle struct {
+	buf    bytes.Buffer
+	gz     *gzip.Writer
+	tw     *tar.Writer
+	now    time.Time
+	files  []map[string]interface{}
+	errors []string
+}
+
+// newDiagnosticsBundle creates an empty bundle whose files are dated now.
+func newDiagnosticsBundle(now time.Time) *diagnosticsBundle {
+	b := &diagnosticsBundle{now: now}
+	b.gz = gzip.NewWriter(&b.buf)
+	b.tw = tar.NewWriter(b.gz)
+	return b
+}
+
+// add writes a file to the bundle.
+func (b *diagnosticsBundle) add(name string, data []byte) {
+	header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: b.now, Typeflag: tar.TypeReg}
+	if err := b.tw.WriteHeader(header); err != nil {
+		b.fail("failed to add %s: %v", name, err)
+		return
+	}
+	if _, err := b.tw.Write(data); err != nil {
+		b.fail("failed to add %s: %v", name, err)
+		return
+	}
+	b.files = append(b.files, map[string]interface{}{"name": name, "bytes": len(data)})
+}
+
+// addJSON writes value to the bundle as indented JSON.
+func (b *diagnosticsBundle) addJSON(name string, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		b.fail("failed to serialize %s: %v", name, err)
+		return
+	}
+	b.add(name, data)
+}
+
+// addYAML writes an object to the bundle as YAML.
+func (b *diagnosticsBundle) addYAML(name string, obj map[string]interface{}) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		b.fail("failed to serialize %s: %v", name, err)
+		return
+	}
+	b.add(name, data)
+}
+
+// fail records an error that left the bundle incomplete.
+func (b *diagnosticsBundle) fail(format string, args ...interface{}) {
+	b.errors = append(b.errors, fmt.Sprintf(format, args...))
+}
+
+// close finishes the archive and returns its bytes.
+func (b *diagnosticsBundle) close() ([]byte, error) {
+	if err := b.tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	if err := b.gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return b.buf.Bytes(), nil
+}
+
+// CollectDiagnostics gathers a support bundle for a namespace or, when kind and
+// name are set, for a single workload: the manifests of the workload and the
+// objects it owns or uses (or of everything in the namespace except Secrets),
+// events, the last tailLines lines of every container log including the
+// previous instance of restarted containers, and pod and node metrics
+// snapshots. Collection errors are recorded in the bundle's summary.json
+// rather than failing the whole bundle.
+// Returns the bundle as a gzipped tar archive together with a summary of its
+// contents, or an error.
+func (c *Client) CollectDiagnostics(ctx context.Context, namespace, kind, name string, tailLines int64) ([]byte, map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	if (kind == "") != (name == "") {
+		return nil, nil, fmt.Errorf("kind and name must be set together")
+	}
+
+	now := time.Now().UTC()
+	bundle := newDiagnosticsBundle(now)
+
+	// Resolve the objects owned by the target workload
+	var owned map[types.UID]bool
+	var root *unstructured.Unstructured
+	if kind != "" {
+		object, err := c.GetResource(ctx, kind, name, namespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get %s/%s: %w", kind, name, err)
+		}
+		root = &unstructured.Unstructured{Object: object}
+		if owned, err = c.ownedObjectUIDs(ctx, namespace, root.GetUID()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	podList, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	var pods []corev1.Pod
+	for _, pod := range podList.Items {
+		if owned == nil || owned[pod.UID] {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	totalPods := len(pods)
+	if len(pods) > maxDiagnosticPods {
+		pods = pods[:maxDiagnosticPods]
+	}
+
+	include := diagnosticFilter(root, owned, pods)
+	for _, listKind := range diagnosticKinds {
+		items, err := c.ListResources(ctx, listKind, namespace, "", "")
+		if err != nil {
+			bundle.fail("failed to list %s: %v", listKind, err)
+			continue
+		}
+		for _, item := range items {
+			obj := &unstructured.Unstructured{Object: item}
+			if !include(listKind, obj) {
+				continue
+			}
+			unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+			bundle.addYAML(fmt.Sprintf("manifests/%s/%s.yaml", strings.ToLower(listKind), obj.GetName()), obj.Object)
+		}
+	}
+
+	events, err := c.listNormalizedEvents(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		bundle.fail("failed to list events: %v", err)
+	} else {
+		var selected []map[string]interface{}
+		for _, event := range events {
+			involved, _ := event["involvedObject"].(map[string]interface{})
+			uid, _ := involved["uid"].(string)
+			if owned == nil || owned[types.UID(uid)] {
+				selected = append(selected, event)
+			}
+		}
+		bundle.addJSON("events.json", sortFilterLimitEvents(selected, 0, "lastTime", ""))
+	}
+
+	for _, pod := range pods {
+		c.collectPodLogs(ctx, bundle, &pod, tailLines)
+	}
+	c.collectMetrics(ctx, bundle, namespace, pods)
+
+	target := map[string]interface{}{"namespace": namespace}
+	if root != nil {
+		target["kind"] = root.GetKind()
+		target["name"] = root.GetName()
+	}
+	summary := map[string]interface{}{
+		"context":     c.ContextName(),
+		"server":      c.ServerHost(),
+		"target":      target,
+		"collectedAt": now,
+		"tailLines":   tailLines,
+		"pods":        len(pods),
+		"totalPods":   totalPods,
+		"truncated":   totalPods > len(pods),
+		"files":       bundle.files,
+		"errors":      bundle.errors,
+	}
+	bundle.addJSON("summary.json", summary)
+
+	data, err := bundle.close()
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, summary, nil
+}
+
+// diagnosticFilter returns a function reporting whether an object belongs to
+// a bundle. Without a root workload everything except the cluster CA ConfigMap
+// and pods beyond the collection limit is included. For a workload, the
+// bundle includes the objects it owns, Services selecting its pods,
+// autoscalers targeting it, and the claims and ConfigMaps its pods use.
+func diagnosticFilter(root *unstructured.Unstructured, owned map[types.UID]bool, pods []corev1.Pod) func(kind string, obj *unstructured.Unstructured) bool {
+	collected := map[string]bool{}
+	claims := map[string]bool{}
+	configMaps := map[string]bool{}
+	for _, pod := range pods {
+		collected[pod.Name] = true
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				claims[volume.PersistentVolumeClaim.ClaimName] = true
+			}
+			if volume.ConfigMap != nil {
+				configMaps[volume.ConfigMap.Name] = true
+			}
+			if volume.Projected != nil {
+				for _, source := range volume.Projected.Sources {
+					if source.ConfigMap != nil {
+						configMaps[source.ConfigMap.Name] = true
+					}
+				}
+			}
+		}
+		for _, ctr := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+			for _, envFrom := range ctr.EnvFrom {
+				if envFrom.ConfigMapRef != nil {
+					configMaps[envFrom.ConfigMapRef.Name] = true
+				}
+			}
+			for _, env := range ctr.Env {
+				if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+					configMaps[env.ValueFrom.ConfigMapKeyRef.Name] = true
+				}
+			}
+		}
+	}
+
+	return func(kind string, obj *unstructured.Unstructured) bool {
+		if kind == "Pod" {
+			return collected[obj.GetName()]
+		}
+		if root == nil {
+			return kind != "ConfigMap" || obj.GetName() != "kube-root-ca.crt"
+		}
+		if owned[obj.GetUID()] {
+			return true
+		}
+		switch kind {
+		case "Service":
+			selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+			if len(selector) == 0 {
+				return false
+			}
+			for _, pod := range pods {
+				if labels.SelectorFromSet(selector).Matches(labels.Set(pod.Labels)) {
+					return true
+				}
+			}
+		case "HorizontalPodAutoscaler":
+			targetKind, _, _ := unstructured.NestedString(obj.Object, "spec", "scaleTargetRef", "kind")
+			targetName, _, _ := unstructured.NestedString(obj.Object, "spec", "scaleTargetRef", "name")
+			return targetKind == root.GetKind() && targetName == root.GetName()
+		case "PersistentVolumeClaim":
+			return claims[obj.GetName()]
+		case "ConfigMap":
+			return configMaps[obj.GetName()]
+		}
+		return false
+	}
+}
+
+// collectPodLogs adds the logs of every container of a pod to the bundle,
+// including the previous instance of containers that restarted.
+func (c *Client) collectPodLogs(ctx context.Context, bundle *diagnosticsBundle, pod *corev1.Pod, tailLines int64) {
+	restarts := map[string]int32{}
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		restarts[status.Name] = status.RestartCount
+	}
+
+	for _, ctr := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		for _, previous := range []bool{false, true} {
+			if previous && restarts[ctr.Name] == 0 {
+				continue
+			}
+			name := fmt.Sprintf("logs/%s/%s.log", pod.Name, ctr.Name)
+			if previous {
+				name = fmt.Sprintf("logs/%s/%s.previous.log", pod.Name, ctr.Name)
+			}
+			limitBytes := int64(maxDiagnosticLogBytes)
+			options := &corev1.PodLogOptions{Container: ctr.Name, Previous: previous, LimitBytes: &limitBytes}
+			if tailLines > 0 {
+				options.TailLines = &tailLines
+			}
+			logs, err := c.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, options).Stream(ctx)
+			if err != nil {
+				bundle.fail("failed to get logs of %s/%s: %v", pod.Name, ctr.Name, err)
+				continue
+			}
+			data, err := io.ReadAll(logs)
+			logs.Close()
+			if err != nil {
+				bundle.fail("failed to read logs of %s/%s: %v", pod.Name, ctr.Name, err)
+				continue
+			}
+			bundle.add(name, data)
+		}
+	}
+}
+
+// collectMetrics adds metrics-server snapshots of the collected pods and of the
+// nodes they run on to the bundle.
+func (c *Client) collectMetrics(ctx context.Context, bundle *diagnosticsBundle, namespace string, pods []corev1.Pod) {
+	collected := map[string]bool{}
+	nodes := map[string]bool{}
+	for _, pod := range pods {
+		collected[pod.Name] = true
+		if pod.Spec.NodeName != "" {
+			nodes[pod.Spec.NodeName] = true
+		}
+	}
+
+	podMetrics, err := c.metricsClientset.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		bundle.fail("failed to get pod metrics: %v", err)
+	} else {
+		var snapshot []map[string]interface{}
+		for _, metrics := range podMetrics.Items {
+			if !collected[metrics.Name] {
+				continue
+			}
+			var containers []map[string]interface{}
+			for _, ctr := range metrics.Containers {
+				containers = append(containers, map[string]interface{}{
+					"name":   ctr.Name,
+					"cpu":    ctr.Usage.Cpu().String(),
+					"memory": ctr.Usage.Memory().String(),
+				})
+			}
+			snapshot = append(snapshot, map[string]interface{}{
+				"pod":        metrics.Name,
+				"timestamp":  metrics.Timestamp.Time,
+				"window":     metrics.Window.Duration.String(),
+				"containers": containers,
+			})
+		}
+		bundle.addJSON("metrics/pods.json", snapshot)
+	}
+
+	nodeNames := make([]string, 0, len(nodes))
+	for nodeName := range nodes {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	var nodeSnapshot []map[string]interface{}
+	for _, nodeName := range nodeNames {
+		metrics, err := c.GetNodeMetrics(ctx, nodeName)
+		if err != nil {
+			bundle.fail("failed to get metrics of node %s: %v", nodeName, err)
+			continue
+		}
+		nodeSnapshot = append(nodeSnapshot, metrics)
+	}
+	if len(nodeSnapshot) > 0 {
+		bundle.addJSON("metrics/nodes.json", nodeSnapshot)
+	}
+}
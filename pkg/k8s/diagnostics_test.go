@@ -0,0 +1,98 @@
+package k8s
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestDiagnosticFilter tests selecting the objects that belong to a workload's bundle
+func TestDiagnosticFilter(t *testing.T) {
+	root := &unstructured.Unstructured{}
+	root.SetKind("Deployment")
+	root.SetName("web")
+	root.SetUID("deploy")
+	owned := map[types.UID]bool{"deploy": true, "rs": true, "pod": true}
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", UID: "pod", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{
+			Volumes:    []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "web-data"}}}},
+			Containers: []corev1.Container{{Name: "web", EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}}}}}},
+		},
+	}}
+	include := diagnosticFilter(root, owned, pods)
+
+	object := func(uid, name string, fields map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": fields}}
+		obj.SetUID(types.UID(uid))
+		obj.SetName(name)
+		return obj
+	}
+	tests := []struct {
+		kind string
+		obj  *unstructured.Unstructured
+		want bool
+	}{
+		{"ReplicaSet", object("rs", "web-abc", nil), true},
+		{"ReplicaSet", object("other", "api-abc", nil), false},
+		{"Pod", object("pod", "web-1", nil), true},
+		{"Service", object("svc", "web", map[string]interface{}{"selector": map[string]interface{}{"app": "web"}}), true},
+		{"Service", object("svc2", "api", map[string]interface{}{"selector": map[string]interface{}{"app": "api"}}), false},
+		{"HorizontalPodAutoscaler", object("hpa", "web", map[string]interface{}{"scaleTargetRef": map[string]interface{}{"kind": "Deployment", "name": "web"}}), true},
+		{"PersistentVolumeClaim", object("pvc", "web-data", nil), true},
+		{"ConfigMap", object("cm", "web-config", nil), true},
+		{"ConfigMap", object("cm2", "api-config", nil), false},
+	}
+	for _, tt := range tests {
+		if got := include(tt.kind, tt.obj); got != tt.want {
+			t.Errorf("include(%s %s) = %v, want %v", tt.kind, tt.obj.GetName(), got, tt.want)
+		}
+	}
+
+	namespaceInclude := diagnosticFilter(nil, nil, pods)
+	if namespaceInclude("ConfigMap", object("ca", "kube-root-ca.crt", nil)) {
+		t.Error("Expected the cluster CA ConfigMap to be skipped")
+	}
+	if namespaceInclude("Pod", object("pod2", "web-2", nil)) {
+		t.Error("Expected pods beyond the collected ones to be skipped")
+	}
+}
+
+// TestDiagnosticsBundle tests that bundle files are written to a readable tar.gz archive
+func TestDiagnosticsBundle(t *testing.T) {
+	bundle := newDiagnosticsBundle(time.Now())
+	bundle.add("logs/web-1/web.log", []byte("started\n"))
+	bundle.addJSON("events.json", []string{})
+	data, err := bundle.close()
+	if err != nil {
+		t.Fatalf("Failed to close bundle: %v", err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a gzip archive: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read archive: %v", err)
+		}
+		names = append(names, header.Name)
+	}
+	if len(names) != 2 || names[0] != "logs/web-1/web.log" || names[1] != "events.json" {
+		t.Errorf("Unexpected archive entries %v", names)
+	}
+}
@@ -0,0 +1,32 @@
+package k8s
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// dryRunKey is the context key marking requests that must be sent as
+// server-side dry runs.
+type dryRunKey struct{}
+
+// WithDryRun returns a context under which mutating client methods submit
+// their requests as server-side dry runs: the API server validates and admits
+// them, but does not persist the result.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx was marked with WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// dryRunOption returns the DryRun request option for ctx.
+func dryRunOption(ctx context.Context) []string {
+	if IsDryRun(ctx) {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// maxEphemeralSampleSeconds bounds the interval between the two kubelet samples
+// used to measure writable layer growth.
+const maxEphemeralSampleSeconds = 60
+
+// evictionContainerUsage matches the per-container usage the kubelet appends to
+// node pressure eviction messages.
+var evictionContainerUsage = regexp.MustCompile(`Container (\S+) was using (\S+), request is (\S+)`)
+
+// AnalyzeEphemeralStorage explains ephemeral-storage evictions. It finds pods
+// evicted for ephemeral storage, classifies the cause (node pressure, pod or
+// container limit, emptyDir limit), and for each affected node, or nodeName if
+// given, reports filesystem usage, DiskPressure, and the containers with the
+// largest writable layers and logs from the kubelet summary. If sampleSeconds
+// is positive, the kubelet is sampled twice to measure writable layer growth.
+// Returns a map with "evictedPods", "nodes" and "findings", or an error.
+func (c *Client) AnalyzeEphemeralStorage(ctx context.Context, namespace, nodeName string, sampleSeconds, topN int) (map[string]interface{}, error) {
+	if topN <= 0 {
+		topN = 10
+	}
+	if sampleSeconds > maxEphemeralSampleSeconds {
+		sampleSeconds = maxEphemeralSampleSeconds
+	}
+
+	opts := metav1.ListOptions{}
+	if nodeName != "" {
+		opts.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var findings []string
+	var evicted []map[string]interface{}
+	nodeNames := map[string]bool{}
+	if nodeName != "" {
+		nodeNames[nodeName] = true
+	}
+	podSpecs := map[string]*corev1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		podSpecs[pod.Namespace+"/"+pod.Name] = pod
+		if pod.Status.Phase != corev1.PodFailed || pod.Status.Reason != "Evicted" {
+			continue
+		}
+		cause := ephemeralEvictionCause(pod.Status.Message)
+		if cause == "" {
+			continue
+		}
+		entry := map[string]interface{}{
+			"name":      pod.Name,
+			"namespace": pod.Namespace,
+			"node":      pod.Spec.NodeName,
+			"cause":     cause,
+			"message":   pod.Status.Message,
+			"startTime": pod.Status.StartTime,
+		}
+		var usages []map[string]interface{}
+		for _, match := range evictionContainerUsage.FindAllStringSubmatch(pod.Status.Message, -1) {
+			usages = append(usages, map[string]interface{}{"container": match[1], "using": match[2], "request": match[3]})
+		}
+		if usages != nil {
+			entry["containerUsage"] = usages
+		}
+		evicted = append(evicted, entry)
+		if pod.Spec.NodeName != "" {
+			nodeNames[pod.Spec.NodeName] = true
+		}
+		findings = append(findings, fmt.Sprintf("Pod %s/%s on node %s was evicted: %s", pod.Namespace, pod.Name, pod.Spec.NodeName, ephemeralCauseDescription(cause)))
+	}
+
+	// Nodes currently under disk pressure are inspected even without evictions
+	if nodeName == "" {
+		nodes, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err == nil {
+			for _, node := range nodes.Items {
+				if nodeConditionTrue(&node, corev1.NodeDiskPressure) {
+					nodeNames[node.Name] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(nodeNames))
+	for name := range nodeNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	first := map[string]*kubeletSummary{}
+	nodeErrors := map[string]string{}
+	for _, name := range names {
+		summary, err := c.getKubeletSummary(ctx, name)
+		if err != nil {
+			nodeErrors[name] = err.Error()
+			continue
+		}
+		first[name] = summary
+	}
+	second := map[string]*kubeletSummary{}
+	if sampleSeconds > 0 && len(first) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(sampleSeconds) * time.Second):
+		}
+		for name := range first {
+			if summary, err := c.getKubeletSummary(ctx, name); err == nil {
+				second[name] = summary
+			}
+		}
+	}
+
+	var nodeReports []map[string]interface{}
+	for _, name := range names {
+		report := map[string]interface{}{"name": name}
+		if node, err := c.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{}); err == nil {
+			report["diskPressure"] = nodeConditionTrue(node, corev1.NodeDiskPressure)
+			if allocatable, ok := node.Status.Allocatable[corev1.ResourceEphemeralStorage]; ok {
+				report["allocatableEphemeralStorage"] = allocatable.String()
+			}
+		}
+		if msg, failed := nodeErrors[name]; failed {
+			report["error"] = msg
+			nodeReports = append(nodeReports, report)
+			continue
+		}
+
+		summary := first[name]
+		report["nodeFs"] = summarizeFsStats(summary.Node.Fs)
+		if summary.Node.Runtime != nil {
+			report["imageFs"] = summarizeFsStats(summary.Node.Runtime.ImageFs)
+		}
+		containers, emptyDirs := ephemeralConsumers(summary, second[name], sampleSeconds, podSpecs)
+		report["topContainers"] = limitMaps(containers, topN)
+		report["topEmptyDirVolumes"] = limitMaps(emptyDirs, topN)
+		nodeReports = append(nodeReports, report)
+
+		if fs := summary.Node.Fs; fs != nil && fs.UsedBytes != nil && fs.CapacityBytes != nil && *fs.CapacityBytes > 0 {
+			percent := float64(*fs.UsedBytes) / float64(*fs.CapacityBytes) * 100
+			if percent >= 80 {
+				findings = append(findings, fmt.Sprintf("Node %s root filesystem is %.0f%% used", name, percent))
+			}
+		}
+		if len(containers) > 0 {
+			top := containers[0]
+			findings = append(findings, fmt.Sprintf("Largest ephemeral consumer on node %s is container %s in pod %s/%s (%s writable layer, %s logs)",
+				name, top["container"], top["namespace"], top["pod"], top["writableLayer"], top["logs"]))
+		}
+		for _, ctr := range limitMaps(containers, topN) {
+			if growth, ok := ctr["writableLayerGrowthPerMinute"].(string); ok && ctr["growing"] == true {
+				findings = append(findings, fmt.Sprintf("Writable layer of container %s in pod %s/%s is growing by %s per minute", ctr["container"], ctr["namespace"], ctr["pod"], growth))
+			}
+		}
+	}
+
+	if len(evicted) == 0 {
+		findings = append(findings, "No pods evicted for ephemeral storage were found")
+	}
+
+	return map[string]interface{}{
+		"evictedPods": evicted,
+		"nodes":       nodeReports,
+		"findings":    findings,
+	}, nil
+}
+
+// ephemeralEvictionCause classifies an eviction message, returning an empty
+// string for evictions unrelated to ephemeral storage.
+func ephemeralEvictionCause(message string) string {
+	switch {
+	case strings.Contains(message, "Usage of EmptyDir volume"):
+		return "emptyDirLimit"
+	case strings.Contains(message, "exceeded its local ephemeral storage limit"):
+		return "containerLimit"
+	case strings.Contains(message, "ephemeral local storage usage exceeds the total limit"):
+		return "podLimit"
+	case strings.Contains(message, "low on resource: ephemeral-storage"):
+		return "nodePressure"
+	}
+	return ""
+}
+
+// ephemeralCauseDescription returns a short explanation of an eviction cause.
+func ephemeralCauseDescription(cause string) string {
+	switch cause {
+	case "emptyDirLimit":
+		return "an emptyDir volume exceeded its sizeLimit"
+	case "containerLimit":
+		return "a container exceeded its ephemeral-storage limit (writable layer plus logs)"
+	case "podLimit":
+		return "the pod's total ephemeral storage exceeded the sum of its container limits"
+	}
+	return "the node was low on ephemeral storage and the pod was among the largest consumers relative to its request"
+}
+
+// ephemeralConsumers ranks the containers of a kubelet summary by writable
+// layer plus log usage, and its emptyDir volumes by usage. If a later sample is
+// given, the writable layer growth rate is also reported.
+func ephemeralConsumers(summary, later *kubeletSummary, sampleSeconds int, podSpecs map[string]*corev1.Pod) ([]map[string]interface{}, []map[string]interface{}) {
+	laterRootfs := map[string]uint64{}
+	if later != nil {
+		for _, pod := range later.Pods {
+			for _, ctr := range pod.Containers {
+				if ctr.Rootfs != nil && ctr.Rootfs.UsedBytes != nil {
+					laterRootfs[pod.PodRef.Namespace+"/"+pod.PodRef.Name+"/"+ctr.Name] = *ctr.Rootfs.UsedBytes
+				}
+			}
+		}
+	}
+
+	type consumer struct {
+		entry map[string]interface{}
+		total uint64
+	}
+	var containers, volumes []consumer
+	for _, pod := range summary.Pods {
+		podKey := pod.PodRef.Namespace + "/" + pod.PodRef.Name
+		for _, ctr := range pod.Containers {
+			var rootfs, logs uint64
+			if ctr.Rootfs != nil && ctr.Rootfs.UsedBytes != nil {
+				rootfs = *ctr.Rootfs.UsedBytes
+			}
+			if ctr.Logs != nil && ctr.Logs.UsedBytes != nil {
+				logs = *ctr.Logs.UsedBytes
+			}
+			entry := map[string]interface{}{
+				"pod":           pod.PodRef.Name,
+				"namespace":     pod.PodRef.Namespace,
+				"container":     ctr.Name,
+				"writableLayer": formatBytes(rootfs),
+				"logs":          formatBytes(logs),
+			}
+			if spec, ok := podSpecs[podKey]; ok {
+				for _, specCtr := range spec.Spec.Containers {
+					if specCtr.Name != ctr.Name {
+						continue
+					}
+					if q, ok := specCtr.Resources.Requests[corev1.ResourceEphemeralStorage]; ok {
+						entry["request"] = q.String()
+					}
+					if q, ok := specCtr.Resources.Limits[corev1.ResourceEphemeralStorage]; ok {
+						entry["limit"] = q.String()
+					}
+				}
+			}
+			if laterUsed, ok := laterRootfs[podKey+"/"+ctr.Name]; ok && sampleSeconds > 0 {
+				growth := (float64(laterUsed) - float64(rootfs)) / float64(sampleSeconds) * 60
+				entry["growing"] = growth > 0
+				if growth > 0 {
+					entry["writableLayerGrowthPerMinute"] = formatBytes(uint64(growth))
+				}
+			}
+			containers = append(containers, consumer{entry, rootfs + logs})
+		}
+		for _, volume := range pod.VolumeStats {
+			if volume.UsedBytes == nil || *volume.UsedBytes == 0 {
+				continue
+			}
+			if spec, ok := podSpecs[podKey]; ok && !isEmptyDirVolume(spec, volume.Name) {
+				continue
+			}
+			volumes = append(volumes, consumer{map[string]interface{}{
+				"pod":       pod.PodRef.Name,
+				"namespace": pod.PodRef.Namespace,
+				"volume":    volume.Name,
+				"used":      formatBytes(*volume.UsedBytes),
+			}, *volume.UsedBytes})
+		}
+	}
+
+	sortConsumers := func(list []consumer) []map[string]interface{} {
+		sort.Slice(list, func(i, j int) bool { return list[i].total > list[j].total })
+		result := make([]map[string]interface{}, 0, len(list))
+		for _, item := range list {
+			result = append(result, item.entry)
+		}
+		return result
+	}
+	return sortConsumers(containers), sortConsumers(volumes)
+}
+
+// isEmptyDirVolume reports whether the named pod volume is an emptyDir.
+func isEmptyDirVolume(pod *corev1.Pod, name string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == name {
+			return volume.EmptyDir != nil
+		}
+	}
+	return false
+}
+
+// nodeConditionTrue reports whether the node condition of the given type is True.
+func nodeConditionTrue(node *corev1.Node, conditionType corev1.NodeConditionType) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// formatBytes renders a byte count as a binary-SI quantity such as "1536Mi".
+func formatBytes(bytes uint64) string {
+	return resource.NewQuantity(int64(bytes), resource.BinarySI).String()
+}
+
+// limitMaps truncates a list to at most n entries.
+func limitMaps(list []map[string]interface{}, n int) []map[string]interface{} {
+	if len(list) > n {
+		return list[:n]
+	}
+	return list
+}
@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestEphemeralEvictionCause tests classifying kubelet eviction messages
+func TestEphemeralEvictionCause(t *testing.T) {
+	tests := map[string]string{
+		"Usage of EmptyDir volume \"cache\" exceeds the limit \"1Gi\".":                               "emptyDirLimit",
+		"Pod ephemeral local storage usage exceeds the total limit of containers 2Gi.":                "podLimit",
+		"Container app exceeded its local ephemeral storage limit \"1Gi\".":                           "containerLimit",
+		"The node was low on resource: ephemeral-storage. Container app was using 3Gi, request is 0.": "nodePressure",
+		"The node was low on resource: memory. Container app was using 1Gi, request is 512Mi.":        "",
+	}
+	for message, want := range tests {
+		if got := ephemeralEvictionCause(message); got != want {
+			t.Errorf("ephemeralEvictionCause(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
+
+// TestAnalyzeEphemeralStorage tests correlating ephemeral-storage evictions with the kubelet's filesystem usage
+func TestAnalyzeEphemeralStorage(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/web/pods":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"api-a","namespace":"web"},"spec":{"nodeName":"worker-1","containers":[{"name":"app"}]},` +
+				`"status":{"phase":"Failed","reason":"Evicted","message":"The node was low on resource: ephemeral-storage. Container app was using 3Gi, request is 0."}},` +
+				`{"metadata":{"name":"api-b","namespace":"web"},"spec":{"nodeName":"worker-1","containers":[{"name":"app","resources":{"limits":{"ephemeral-storage":"2Gi"}}}],` +
+				`"volumes":[{"name":"cache","emptyDir":{}},{"name":"config","configMap":{"name":"settings"}}]},"status":{"phase":"Running"}},` +
+				`{"metadata":{"name":"oom","namespace":"web"},"spec":{"nodeName":"worker-2"},` +
+				`"status":{"phase":"Failed","reason":"Evicted","message":"The node was low on resource: memory."}}]}`))
+		case "/api/v1/nodes":
+			w.Write([]byte(`{"kind":"NodeList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"worker-3"},"status":{"conditions":[{"type":"DiskPressure","status":"True"}]}}]}`))
+		case "/api/v1/nodes/worker-1":
+			w.Write([]byte(`{"kind":"Node","apiVersion":"v1","metadata":{"name":"worker-1"},"status":{"allocatable":{"ephemeral-storage":"90Gi"}}}`))
+		case "/api/v1/nodes/worker-1/proxy/stats/summary":
+			w.Write([]byte(`{"node":{"nodeName":"worker-1","fs":{"capacityBytes":100,"usedBytes":85}},"pods":[` +
+				`{"podRef":{"name":"api-b","namespace":"web"},"containers":[{"name":"app","rootfs":{"usedBytes":1073741824},"logs":{"usedBytes":1048576}}],` +
+				`"volume":[{"name":"cache","usedBytes":2048},{"name":"config","usedBytes":1024}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.AnalyzeEphemeralStorage(context.Background(), "web", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evicted := result["evictedPods"].([]map[string]interface{})
+	if len(evicted) != 1 || evicted[0]["name"] != "api-a" || evicted[0]["cause"] != "nodePressure" {
+		t.Fatalf("Expected only the ephemeral-storage eviction, got %v", evicted)
+	}
+	if usage := evicted[0]["containerUsage"].([]map[string]interface{}); len(usage) != 1 || usage[0]["using"] != "3Gi" {
+		t.Errorf("Expected the container usage from the eviction message, got %v", usage)
+	}
+
+	nodes := result["nodes"].([]map[string]interface{})
+	if len(nodes) != 2 || nodes[0]["name"] != "worker-1" || nodes[1]["name"] != "worker-3" {
+		t.Fatalf("Expected the node of the eviction and the node under disk pressure, got %v", nodes)
+	}
+	worker := nodes[0]
+	containers := worker["topContainers"].([]map[string]interface{})
+	if len(containers) != 1 || containers[0]["writableLayer"] != "1Gi" || containers[0]["limit"] != "2Gi" {
+		t.Errorf("Expected the container's writable layer and limit, got %v", containers)
+	}
+	if volumes := worker["topEmptyDirVolumes"].([]map[string]interface{}); len(volumes) != 1 || volumes[0]["volume"] != "cache" {
+		t.Errorf("Expected only emptyDir volumes to be ranked, got %v", volumes)
+	}
+	if _, ok := nodes[1]["error"]; !ok {
+		t.Errorf("Expected an unreachable kubelet to be reported, got %v", nodes[1])
+	}
+
+	findings := strings.Join(result["findings"].([]string), "\n")
+	if !strings.Contains(findings, "Node worker-1 root filesystem is 85% used") || !strings.Contains(findings, "container app in pod web/api-b") {
+		t.Errorf("Expected findings for the full filesystem and the largest consumer, got %s", findings)
+	}
+}
@@ -0,0 +1,231 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// GetWorkloadEvents retrieves the events of a workload together with those of
+// the objects it owns, resolved via ownerReferences: for a Deployment this
+// includes its ReplicaSets and their Pods, for a CronJob its Jobs and their
+// Pods. Events are filtered by message content, sorted, and limited as in GetEvents.
+// Returns a slice of maps, each representing an event, or an error.
+func (c *Client) GetWorkloadEvents(ctx context.Context, namespace, kind, name string, maxEvents int, sortBy string, messageFilter string) ([]map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	workload, err := c.GetResource(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workload %s/%s: %w", kind, name, err)
+	}
+	owned, err := c.ownedObjectUIDs(ctx, namespace, (&unstructured.Unstructured{Object: workload}).GetUID())
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := c.listNormalizedEvents(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var workloadEvents []map[string]interface{}
+	for _, event := range events {
+		involved, _ := event["involvedObject"].(map[string]interface{})
+		uid, _ := involved["uid"].(string)
+		if owned[types.UID(uid)] {
+			workloadEvents = append(workloadEvents, event)
+		}
+	}
+
+	return sortFilterLimitEvents(workloadEvents, maxEvents, sortBy, messageFilter), nil
+}
+
+// ownedObjectUIDs returns the UID of the root object and of every ReplicaSet,
+// Job and Pod in the namespace that it transitively owns.
+func (c *Client) ownedObjectUIDs(ctx context.Context, namespace string, root types.UID) (map[types.UID]bool, error) {
+	var objects []metav1.Object
+	replicaSets, err := c.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ReplicaSets: %w", err)
+	}
+	for i := range replicaSets.Items {
+		objects = append(objects, &replicaSets.Items[i])
+	}
+	jobs, err := c.clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		objects = append(objects, &jobs.Items[i])
+	}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range pods.Items {
+		objects = append(objects, &pods.Items[i])
+	}
+
+	owned := map[types.UID]bool{root: true}
+	// Repeat until no new objects are added, as ownership may be several levels deep
+	for changed := true; changed; {
+		changed = false
+		for _, obj := range objects {
+			if owned[obj.GetUID()] {
+				continue
+			}
+			for _, ref := range obj.GetOwnerReferences() {
+				if owned[ref.UID] {
+					owned[obj.GetUID()] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return owned, nil
+}
+
+// listNormalizedEvents lists events using the events.k8s.io/v1 API, falling
+// back to core/v1 when that API is unavailable or not permitted. Both shapes
+// are converted to the same normalized schema.
+func (c *Client) listNormalizedEvents(ctx context.Context, namespace string, opts metav1.ListOptions) ([]map[string]interface{}, error) {
+	var events []map[string]interface{}
+
+	eventList, err := c.clientset.EventsV1().Events(namespace).List(ctx, opts)
+	if err == nil {
+		for i := range eventList.Items {
+			events = append(events, normalizeEventsV1Event(&eventList.Items[i]))
+		}
+		return events, nil
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to retrieve events: %w", err)
+	}
+
+	coreList, err := c.clientset.CoreV1().Events(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve events: %w", err)
+	}
+	for i := range coreList.Items {
+		events = append(events, normalizeCoreEvent(&coreList.Items[i]))
+	}
+	return events, nil
+}
+
+// normalizeEventsV1Event converts an events.k8s.io/v1 event into the normalized
+// event schema.
+func normalizeEventsV1Event(event *eventsv1.Event) map[string]interface{} {
+	count := event.DeprecatedCount
+	firstTime := event.DeprecatedFirstTimestamp.Time
+	lastTime := event.DeprecatedLastTimestamp.Time
+	if event.Series != nil {
+		count = event.Series.Count
+		lastTime = event.Series.LastObservedTime.Time
+	}
+
+	source := event.ReportingController
+	if source == "" {
+		source = event.DeprecatedSource.Component
+	}
+
+	normalized := map[string]interface{}{
+		"apiVersion":          "events.k8s.io/v1",
+		"name":                event.Name,
+		"namespace":           event.Namespace,
+		"type":                event.Type,
+		"reason":              event.Reason,
+		"action":              event.Action,
+		"message":             event.Note,
+		"source":              source,
+		"reportingController": event.ReportingController,
+		"reportingInstance":   event.ReportingInstance,
+		"involvedObject":      summarizeObjectReference(event.Regarding),
+		"count":               normalizeEventCount(count),
+		"firstTime":           firstEventTime(firstTime, event.EventTime.Time, event.CreationTimestamp.Time),
+		"lastTime":            firstEventTime(lastTime, event.EventTime.Time, event.CreationTimestamp.Time),
+	}
+	if event.Related != nil {
+		normalized["related"] = summarizeObjectReference(*event.Related)
+	}
+	return normalized
+}
+
+// normalizeCoreEvent converts a core/v1 event into the normalized event schema.
+func normalizeCoreEvent(event *corev1.Event) map[string]interface{} {
+	count := event.Count
+	lastTime := event.LastTimestamp.Time
+	if event.Series != nil {
+		count = event.Series.Count
+		lastTime = event.Series.LastObservedTime.Time
+	}
+
+	source := event.ReportingController
+	if source == "" {
+		source = event.Source.Component
+	}
+
+	normalized := map[string]interface{}{
+		"apiVersion":          "v1",
+		"name":                event.Name,
+		"namespace":           event.Namespace,
+		"type":                event.Type,
+		"reason":              event.Reason,
+		"action":              event.Action,
+		"message":             event.Message,
+		"source":              source,
+		"reportingController": event.ReportingController,
+		"reportingInstance":   event.ReportingInstance,
+		"involvedObject":      summarizeObjectReference(event.InvolvedObject),
+		"count":               normalizeEventCount(count),
+		"firstTime":           firstEventTime(event.FirstTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
+		"lastTime":            firstEventTime(lastTime, event.EventTime.Time, event.CreationTimestamp.Time),
+	}
+	if event.Related != nil {
+		normalized["related"] = summarizeObjectReference(*event.Related)
+	}
+	return normalized
+}
+
+// summarizeObjectReference returns the identifying fields of an object reference.
+func summarizeObjectReference(ref corev1.ObjectReference) map[string]interface{} {
+	summary := map[string]interface{}{
+		"kind":      ref.Kind,
+		"name":      ref.Name,
+		"namespace": ref.Namespace,
+	}
+	if ref.UID != "" {
+		summary["uid"] = string(ref.UID)
+	}
+	if ref.FieldPath != "" {
+		summary["fieldPath"] = ref.FieldPath
+	}
+	return summary
+}
+
+// normalizeEventCount treats unset counts as a single occurrence.
+func normalizeEventCount(count int32) int32 {
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// firstEventTime returns the first non-zero time, as events populate different
+// timestamp fields depending on the API version and reporter.
+func firstEventTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,121 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestGetEventsNormalized tests reading events.k8s.io/v1 events and falling back to core/v1 events with the same schema
+func TestGetEventsNormalized(t *testing.T) {
+	eventsV1 := true
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/apis/events.k8s.io/v1/namespaces/web/events" && eventsV1:
+			w.Write([]byte(`{"kind":"EventList","apiVersion":"events.k8s.io/v1","items":[` +
+				`{"metadata":{"name":"api.1","namespace":"web"},"eventTime":"2024-01-01T10:00:00.000000Z","type":"Warning","reason":"BackOff",` +
+				`"note":"Back-off restarting failed container","reportingController":"kubelet","regarding":{"kind":"Pod","name":"api","uid":"p1"},` +
+				`"series":{"count":5,"lastObservedTime":"2024-01-01T10:05:00.000000Z"}},` +
+				`{"metadata":{"name":"api.2","namespace":"web"},"eventTime":"2024-01-01T10:01:00.000000Z","type":"Normal","reason":"Pulled",` +
+				`"note":"Image pulled","reportingController":"kubelet","regarding":{"kind":"Pod","name":"api","uid":"p1"}}]}`))
+		case r.URL.Path == "/api/v1/namespaces/web/events":
+			w.Write([]byte(`{"kind":"EventList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"api.3","namespace":"web"},"type":"Warning","reason":"Failed","message":"ErrImagePull",` +
+				`"source":{"component":"kubelet"},"involvedObject":{"kind":"Pod","name":"api"},` +
+				`"firstTimestamp":"2024-01-01T09:00:00Z","lastTimestamp":"2024-01-01T09:30:00Z"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	events, err := client.GetEvents(ctx, "web", 0, "lastTime", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected the events.k8s.io/v1 events, got %v", events)
+	}
+	backOff := events[0]
+	if backOff["apiVersion"] != "events.k8s.io/v1" || backOff["reason"] != "BackOff" || backOff["count"] != int32(5) ||
+		backOff["message"] != "Back-off restarting failed container" || backOff["source"] != "kubelet" {
+		t.Errorf("Expected the series count and note of the latest event, got %v", backOff)
+	}
+	if last := backOff["lastTime"].(time.Time); !last.Equal(time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC)) {
+		t.Errorf("Expected the last observed time of the series, got %v", last)
+	}
+	if events[1]["count"] != int32(1) {
+		t.Errorf("Expected an event without a series to count once, got %v", events[1])
+	}
+
+	filtered, err := client.GetEvents(ctx, "web", 1, "firstTime", "image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0]["reason"] != "Pulled" {
+		t.Errorf("Expected only the event matching the message filter, got %v", filtered)
+	}
+
+	eventsV1 = false
+	events, err = client.GetEvents(ctx, "web", 0, "lastTime", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0]["apiVersion"] != "v1" || events[0]["message"] != "ErrImagePull" || events[0]["source"] != "kubelet" {
+		t.Fatalf("Expected the core/v1 events in the normalized schema, got %v", events)
+	}
+	if involved := events[0]["involvedObject"].(map[string]interface{}); involved["kind"] != "Pod" || involved["name"] != "api" {
+		t.Errorf("Expected the involved object to be summarized, got %v", involved)
+	}
+}
+
+// TestGetWorkloadEvents tests rolling up the events of a Deployment's ReplicaSets and Pods
+func TestGetWorkloadEvents(t *testing.T) {
+	resources := []testResource{{"apps/v1", "deployments", "Deployment", true}}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveTestDiscovery(w, r, resources) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/web/deployments/api":
+			w.Write([]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api","namespace":"web","uid":"d1"}}`))
+		case "/apis/apps/v1/namespaces/web/replicasets":
+			w.Write([]byte(`{"kind":"ReplicaSetList","apiVersion":"apps/v1","items":[` +
+				`{"metadata":{"name":"api-1","uid":"rs1","ownerReferences":[{"apiVersion":"apps/v1","kind":"Deployment","name":"api","uid":"d1"}]}},` +
+				`{"metadata":{"name":"worker-1","uid":"rs2"}}]}`))
+		case "/apis/batch/v1/namespaces/web/jobs":
+			w.Write([]byte(`{"kind":"JobList","apiVersion":"batch/v1","items":[]}`))
+		case "/api/v1/namespaces/web/pods":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"api-1-a","uid":"p1","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"api-1","uid":"rs1"}]}},` +
+				`{"metadata":{"name":"worker-1-a","uid":"p2","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"worker-1","uid":"rs2"}]}}]}`))
+		case "/apis/events.k8s.io/v1/namespaces/web/events":
+			w.Write([]byte(`{"kind":"EventList","apiVersion":"events.k8s.io/v1","items":[` +
+				`{"metadata":{"name":"e1"},"eventTime":"2024-01-01T10:00:00.000000Z","reason":"ScalingReplicaSet","regarding":{"kind":"Deployment","name":"api","uid":"d1"}},` +
+				`{"metadata":{"name":"e2"},"eventTime":"2024-01-01T10:01:00.000000Z","reason":"SuccessfulCreate","regarding":{"kind":"ReplicaSet","name":"api-1","uid":"rs1"}},` +
+				`{"metadata":{"name":"e3"},"eventTime":"2024-01-01T10:02:00.000000Z","reason":"BackOff","regarding":{"kind":"Pod","name":"api-1-a","uid":"p1"}},` +
+				`{"metadata":{"name":"e4"},"eventTime":"2024-01-01T10:03:00.000000Z","reason":"BackOff","regarding":{"kind":"Pod","name":"worker-1-a","uid":"p2"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	events, err := client.GetWorkloadEvents(context.Background(), "web", "Deployment", "api", 0, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, event := range events {
+		names = append(names, event["name"].(string))
+	}
+	if len(names) != 3 || names[0] != "e3" || names[1] != "e2" || names[2] != "e1" {
+		t.Errorf("Expected the events of the Deployment, its ReplicaSet and its Pod, most recent first, got %v", names)
+	}
+
+	if _, err := client.GetWorkloadEvents(context.Background(), "web", "Deployment", "missing", 0, "", ""); err == nil {
+		t.Error("Expected an error for a missing workload")
+	}
+}
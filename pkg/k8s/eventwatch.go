@@ -0,0 +1,428 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// maxEventWatchesPerSession bounds the event watches one session may keep open.
+	maxEventWatchesPerSession = 5
+	// MaxEventWatchDuration bounds how long an event watch runs before it expires.
+	MaxEventWatchDuration = 24 * time.Hour
+	// maxPendingWatchedEvents bounds the events an event watch holds until they
+	// are collected; older ones are dropped first.
+	maxPendingWatchedEvents = 500
+	// eventWatchRetryInterval is how long an event watch waits before
+	// reconnecting after an error.
+	eventWatchRetryInterval = 5 * time.Second
+)
+
+// eventWatch is an active or closed watch on events started by a session.
+type eventWatch struct {
+	id        string
+	session   string
+	namespace string
+	types     []string
+	reasons   []string
+	startedAt time.Time
+	expiresAt time.Time
+	cancel    context.CancelFunc
+	notify    func(watchID string, event map[string]interface{})
+	mu        sync.Mutex
+	pending   []map[string]interface{}
+	matched   int
+	dropped   int
+	resyncs   int
+	closed    bool
+	err       error
+}
+
+// summary describes the watch for listing.
+func (w *eventWatch) summary() map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	namespace := w.namespace
+	if namespace == "" {
+		namespace = "all"
+	}
+	summary := map[string]interface{}{
+		"id":        w.id,
+		"namespace": namespace,
+		"types":     w.types,
+		"reasons":   w.reasons,
+		"startedAt": w.startedAt,
+		"expiresAt": w.expiresAt,
+		"active":    !w.closed,
+		"matched":   w.matched,
+		"pending":   len(w.pending),
+		"dropped":   w.dropped,
+		"resyncs":   w.resyncs,
+	}
+	if w.err != nil {
+		summary["error"] = w.err.Error()
+	}
+	return summary
+}
+
+// matches reports whether an event passes the watch's type and reason filters.
+func (w *eventWatch) matches(event map[string]interface{}) bool {
+	return matchesAny(w.types, event["type"]) && matchesAny(w.reasons, event["reason"])
+}
+
+// matchesAny reports whether value is one of values, or values is empty.
+func matchesAny(values []string, value interface{}) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver holds a matching event until it is collected, dropping the oldest
+// held event when the watch holds too many, and passes it to the watch's
+// notify callback.
+func (w *eventWatch) deliver(event map[string]interface{}) {
+	w.mu.Lock()
+	w.matched++
+	if len(w.pending) >= maxPendingWatchedEvents {
+		w.pending = w.pending[1:]
+		w.dropped++
+	}
+	w.pending = append(w.pending, event)
+	w.mu.Unlock()
+	if w.notify != nil {
+		w.notify(w.id, event)
+	}
+}
+
+// take returns the events held since the last call and forgets them.
+func (w *eventWatch) take() []map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	events := w.pending
+	w.pending = nil
+	if events == nil {
+		events = []map[string]interface{}{}
+	}
+	return events
+}
+
+// setError records the latest error of the watch.
+func (w *eventWatch) setError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.err = err
+}
+
+// resynced records that the watch had to start over from a new list, possibly
+// missing events in between.
+func (w *eventWatch) resynced() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.resyncs++
+}
+
+// isClosed reports whether the watch has stopped.
+func (w *eventWatch) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+// close stops the watch, recording err as the reason unless it was already
+// stopped.
+func (w *eventWatch) close(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	if err != nil {
+		w.err = err
+	}
+	w.cancel()
+}
+
+// eventWatches holds the event watches of every session.
+type eventWatches struct {
+	mu      sync.Mutex
+	watches map[string]*eventWatch
+	nextID  int
+}
+
+// add registers a watch, assigning its ID, unless the session already has
+// the maximum number of active watches.
+func (e *eventWatches) add(w *eventWatch) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.watches == nil {
+		e.watches = map[string]*eventWatch{}
+	}
+	active := 0
+	for _, existing := range e.watches {
+		if existing.session == w.session && !existing.isClosed() {
+			active++
+		}
+	}
+	if active >= maxEventWatchesPerSession {
+		return fmt.Errorf("this session already has %d active event watches; stop one first", active)
+	}
+	e.nextID++
+	w.id = fmt.Sprintf("ew-%d", e.nextID)
+	e.watches[w.id] = w
+	return nil
+}
+
+// get returns the session's watch with the given ID.
+func (e *eventWatches) get(session, id string) (*eventWatch, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	w, ok := e.watches[id]
+	if !ok || w.session != session {
+		return nil, false
+	}
+	return w, true
+}
+
+// remove unregisters the session's watch with the given ID.
+func (e *eventWatches) remove(session, id string) (*eventWatch, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	w, ok := e.watches[id]
+	if !ok || w.session != session {
+		return nil, false
+	}
+	delete(e.watches, id)
+	return w, true
+}
+
+// list returns the watches of a session, ordered by start time.
+func (e *eventWatches) list(session string) []*eventWatch {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var watches []*eventWatch
+	for _, w := range e.watches {
+		if w.session == session {
+			watches = append(watches, w)
+		}
+	}
+	sort.Slice(watches, func(i, j int) bool { return watches[i].startedAt.Before(watches[j].startedAt) })
+	return watches
+}
+
+// StartEventWatch watches the events of namespace, or of all namespaces if it
+// is empty, in the background, so transient failures are seen even between
+// polls. Only events created or updated after the watch starts are reported,
+// filtered to the given event types (Normal, Warning) and reasons when those
+// are not empty. Each matching event is normalized like GetEvents, passed to
+// notify if it is not nil, and held until collected with GetWatchedEvents.
+// The watch reconnects from the last resourceVersion it saw when its
+// connection drops, and starts over from a new list, counted in "resyncs",
+// when that version has expired. It belongs to the session of ctx and runs
+// until it is stopped, duration elapses, or the session ends.
+// Returns a summary of the watch, including its ID, or an error.
+func (c *Client) StartEventWatch(ctx context.Context, namespace string, types, reasons []string, duration time.Duration,
+	notify func(watchID string, event map[string]interface{})) (map[string]interface{}, error) {
+	if duration <= 0 || duration > MaxEventWatchDuration {
+		return nil, fmt.Errorf("duration must be between 1s and %s", MaxEventWatchDuration)
+	}
+
+	// Learn which events API the cluster serves, and the resourceVersion to
+	// watch from, so that only new events are reported
+	core := false
+	resourceVersion, err := c.eventsResourceVersion(ctx, namespace, core)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to list events: %w", err)
+		}
+		core = true
+		if resourceVersion, err = c.eventsResourceVersion(ctx, namespace, core); err != nil {
+			return nil, fmt.Errorf("failed to list events: %w", err)
+		}
+	}
+
+	watchCtx, cancel := context.WithTimeout(context.Background(), duration)
+	now := time.Now()
+	w := &eventWatch{
+		session:   sessionID(ctx),
+		namespace: namespace,
+		types:     types,
+		reasons:   reasons,
+		startedAt: now,
+		expiresAt: now.Add(duration),
+		cancel:    cancel,
+		notify:    notify,
+	}
+	if err := c.eventWatches.add(w); err != nil {
+		cancel()
+		return nil, err
+	}
+	go c.runEventWatch(watchCtx, w, resourceVersion, core)
+	return w.summary(), nil
+}
+
+// runEventWatch feeds the events of the watch until its context ends.
+func (c *Client) runEventWatch(ctx context.Context, w *eventWatch, resourceVersion string, core bool) {
+	defer func() {
+		if ctx.Err() == context.DeadlineExceeded {
+			w.close(fmt.Errorf("expired at %s", w.expiresAt.Format(time.RFC3339)))
+		} else {
+			w.close(nil)
+		}
+	}()
+
+	for ctx.Err() == nil {
+		if resourceVersion == "" {
+			var err error
+			if resourceVersion, err = c.eventsResourceVersion(ctx, w.namespace, core); err != nil {
+				w.setError(err)
+				sleepContext(ctx, eventWatchRetryInterval)
+				continue
+			}
+			w.resynced()
+		}
+
+		watcher, err := c.watchEventsFrom(ctx, w.namespace, resourceVersion, core)
+		if err != nil {
+			if apierrors.IsGone(err) || apierrors.IsResourceExpired(err) {
+				resourceVersion = ""
+				continue
+			}
+			w.setError(err)
+			sleepContext(ctx, eventWatchRetryInterval)
+			continue
+		}
+		resourceVersion = c.consumeEventWatch(w, watcher, resourceVersion)
+		watcher.Stop()
+	}
+}
+
+// consumeEventWatch delivers the events of one watch connection until it
+// closes. It returns the resourceVersion to resume from, or an empty string
+// if the watch has to start over from a new list.
+func (c *Client) consumeEventWatch(w *eventWatch, watcher watch.Interface, resourceVersion string) string {
+	for result := range watcher.ResultChan() {
+		var event map[string]interface{}
+		switch obj := result.Object.(type) {
+		case *eventsv1.Event:
+			resourceVersion = obj.ResourceVersion
+			event = normalizeEventsV1Event(obj)
+		case *corev1.Event:
+			resourceVersion = obj.ResourceVersion
+			event = normalizeCoreEvent(obj)
+		case *metav1.Status:
+			if result.Type == watch.Error {
+				err := apierrors.FromObject(obj)
+				if apierrors.IsGone(err) || apierrors.IsResourceExpired(err) {
+					return ""
+				}
+				w.setError(err)
+			}
+			continue
+		default:
+			continue
+		}
+		if (result.Type == watch.Added || result.Type == watch.Modified) && w.matches(event) {
+			w.deliver(event)
+		}
+	}
+	return resourceVersion
+}
+
+// eventsResourceVersion lists the events of namespace from the events.k8s.io
+// API, or from core/v1 if core is set, and returns the resourceVersion of the
+// list.
+func (c *Client) eventsResourceVersion(ctx context.Context, namespace string, core bool) (string, error) {
+	opts := metav1.ListOptions{Limit: 1}
+	if core {
+		list, err := c.clientset.CoreV1().Events(namespace).List(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		return list.ResourceVersion, nil
+	}
+	list, err := c.clientset.EventsV1().Events(namespace).List(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	return list.ResourceVersion, nil
+}
+
+// watchEventsFrom watches the events of namespace from resourceVersion on,
+// with the events.k8s.io API or, if core is set, with core/v1.
+func (c *Client) watchEventsFrom(ctx context.Context, namespace, resourceVersion string, core bool) (watch.Interface, error) {
+	opts := metav1.ListOptions{ResourceVersion: resourceVersion, AllowWatchBookmarks: true}
+	if core {
+		return c.clientset.CoreV1().Events(namespace).Watch(ctx, opts)
+	}
+	return c.clientset.EventsV1().Events(namespace).Watch(ctx, opts)
+}
+
+// sleepContext waits for d or until ctx ends.
+func sleepContext(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// GetWatchedEvents returns the events an event watch of the session of ctx
+// has seen since they were last collected, and forgets them.
+// Returns a map with the "watch" summary and the "events", oldest first, or
+// an error if the session has no watch with that ID.
+func (c *Client) GetWatchedEvents(ctx context.Context, id string) (map[string]interface{}, error) {
+	w, ok := c.eventWatches.get(sessionID(ctx), id)
+	if !ok {
+		return nil, fmt.Errorf("event watch %s not found in this session", id)
+	}
+	events := w.take()
+	return map[string]interface{}{
+		"watch":  w.summary(),
+		"events": events,
+	}, nil
+}
+
+// StopEventWatch stops an event watch of the session of ctx and forgets it.
+// Returns a map with the "watch" summary and the "events" not yet collected,
+// or an error if the session has no watch with that ID.
+func (c *Client) StopEventWatch(ctx context.Context, id string) (map[string]interface{}, error) {
+	w, ok := c.eventWatches.remove(sessionID(ctx), id)
+	if !ok {
+		return nil, fmt.Errorf("event watch %s not found in this session", id)
+	}
+	w.close(nil)
+	return map[string]interface{}{
+		"watch":  w.summary(),
+		"events": w.take(),
+	}, nil
+}
+
+// StopSessionEventWatches stops and forgets every event watch of a session,
+// for when the session ends.
+// Returns the number of watches stopped.
+func (c *Client) StopSessionEventWatches(session string) int {
+	if c.eventWatches == nil {
+		return 0
+	}
+	watches := c.eventWatches.list(session)
+	for _, w := range watches {
+		c.eventWatches.remove(session, w.id)
+		w.close(nil)
+	}
+	return len(watches)
+}
@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestEventWatch tests that new events matching the filters are delivered and held until collected
+func TestEventWatch(t *testing.T) {
+	var mu sync.Mutex
+	var watchVersions []string
+	versions := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), watchVersions...)
+	}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/apis/events.k8s.io/v1/namespaces/web/events" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("watch") != "true" {
+			w.Write([]byte(`{"kind":"EventList","apiVersion":"events.k8s.io/v1","metadata":{"resourceVersion":"10"},"items":[]}`))
+			return
+		}
+		mu.Lock()
+		watchVersions = append(watchVersions, r.URL.Query().Get("resourceVersion"))
+		watching := len(watchVersions)
+		mu.Unlock()
+		if watching > 1 {
+			<-r.Context().Done()
+			return
+		}
+		event := func(rv, eventType, reason string) string {
+			return `{"type":"ADDED","object":{"kind":"Event","apiVersion":"events.k8s.io/v1","metadata":{"name":"api.` + rv +
+				`","namespace":"web","resourceVersion":"` + rv + `"},"type":"` + eventType + `","reason":"` + reason +
+				`","note":"` + reason + `","regarding":{"kind":"Pod","name":"api-1","namespace":"web"}}}` + "\n"
+		}
+		w.Write([]byte(event("11", "Normal", "Pulled") + event("12", "Warning", "BackOff") + event("13", "Warning", "Unhealthy")))
+	}))
+	ctx := WithSessionID(context.Background(), "session-1")
+
+	notified := make(chan string, 10)
+	summary, err := client.StartEventWatch(ctx, "web", []string{"Warning"}, []string{"BackOff", "Unhealthy"}, time.Minute,
+		func(watchID string, event map[string]interface{}) { notified <- event["reason"].(string) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := summary["id"].(string)
+	for _, want := range []string{"BackOff", "Unhealthy"} {
+		select {
+		case reason := <-notified:
+			if reason != want {
+				t.Errorf("Expected a notification for %s, got %s", want, reason)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for a notification for %s", want)
+		}
+	}
+
+	if _, err := client.GetWatchedEvents(WithSessionID(context.Background(), "session-2"), id); err == nil {
+		t.Error("Expected the watch to be hidden from other sessions")
+	}
+	result, err := client.GetWatchedEvents(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events := result["events"].([]map[string]interface{}); len(events) != 2 {
+		t.Errorf("Expected the two matching events to be held, got %v", events)
+	}
+	if result, _ := client.GetWatchedEvents(ctx, id); len(result["events"].([]map[string]interface{})) != 0 {
+		t.Error("Expected collected events to be forgotten")
+	}
+
+	// The watch resumes from the last event it saw once its connection closes
+	deadline := time.Now().Add(5 * time.Second)
+	for len(versions()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if watchVersions := versions(); len(watchVersions) < 2 || watchVersions[0] != "10" || watchVersions[1] != "13" {
+		t.Errorf("Expected the watch to start at the list's version and resume at the last event's, got %v", watchVersions)
+	}
+
+	stopped, err := client.StopEventWatch(ctx, id)
+	if err != nil || stopped["watch"].(map[string]interface{})["active"] != false {
+		t.Errorf("Expected the watch to stop, got %v, %v", stopped, err)
+	}
+	if client.StopSessionEventWatches("session-1") != 0 {
+		t.Error("Expected the stopped watch to be forgotten")
+	}
+}
@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// maxExecOutputBytes bounds the stdout and stderr kept from a command run by
+// ExecInPod; output beyond it is discarded.
+const maxExecOutputBytes = 256 * 1024
+
+// ExecInPod runs a non-interactive command in a container of a pod through
+// the SPDY executor, like kubectl exec without a TTY or stdin. If container is
+// empty, the pod's only container, or its default container as named by the
+// kubectl.kubernetes.io/default-container annotation, is used. The command is
+// cancelled after timeout. A non-zero exit code is reported in the result
+// rather than as an error.
+// Returns a map with "stdout", "stderr", "exitCode", the "container" and
+// whether output was "truncated", or an error.
+func (c *Client) ExecInPod(ctx context.Context, namespace, podName, container string, command []string, timeout time.Duration) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	if container == "" {
+		if container, err = defaultContainer(pod); err != nil {
+			return nil, err
+		}
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, fmt.Errorf("pod %s is %s; commands can only run in running pods", podName, pod.Status.Phase)
+	}
+
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(c.restConfig, "POST", req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	stdout := &limitedBuffer{limit: maxExecOutputBytes}
+	stderr := &limitedBuffer{limit: maxExecOutputBytes}
+	start := time.Now()
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: stdout, Stderr: stderr})
+
+	exitCode := 0
+	var exitErr utilexec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		exitCode = exitErr.ExitStatus()
+	case ctx.Err() != nil:
+		return nil, fmt.Errorf("command did not finish within %s: %w", timeout, ctx.Err())
+	default:
+		return nil, fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return map[string]interface{}{
+		"pod":       podName,
+		"namespace": namespace,
+		"container": container,
+		"command":   command,
+		"exitCode":  exitCode,
+		"stdout":    stdout.String(),
+		"stderr":    stderr.String(),
+		"truncated": stdout.truncated || stderr.truncated,
+		"duration":  time.Since(start).Round(time.Millisecond).String(),
+	}, nil
+}
+
+// defaultContainer returns the container commands run in when none is named:
+// the one named by the default-container annotation, or the only container.
+func defaultContainer(pod *corev1.Pod) (string, error) {
+	if name := pod.Annotations["kubectl.kubernetes.io/default-container"]; name != "" {
+		return name, nil
+	}
+	if len(pod.Spec.Containers) == 1 {
+		return pod.Spec.Containers[0].Name, nil
+	}
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, ctr := range pod.Spec.Containers {
+		names = append(names, ctr.Name)
+	}
+	return "", fmt.Errorf("pod %s has %d containers %v; a container is required", pod.Name, len(names), names)
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, recording that it did.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write implements io.Writer. It never fails, so that a command producing
+// more output than is kept still runs to completion.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,36 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestLimitedBuffer tests keeping command output up to the limit
+func TestLimitedBuffer(t *testing.T) {
+	buf := &limitedBuffer{limit: 8}
+	for _, chunk := range []string{"hello", " world", "!"} {
+		if n, err := buf.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Expected the whole chunk to be accepted, got %d, %v", n, err)
+		}
+	}
+	if buf.String() != "hello wo" || !buf.truncated {
+		t.Errorf("Expected truncated output 'hello wo', got %q (truncated %v)", buf.String(), buf.truncated)
+	}
+}
+
+// TestDefaultContainer tests choosing the container to exec into when none is named
+func TestDefaultContainer(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "proxy"}}},
+	}
+	if _, err := defaultContainer(pod); err == nil {
+		t.Error("Expected an error for a pod with several containers")
+	}
+	pod.Annotations = map[string]string{"kubectl.kubernetes.io/default-container": "app"}
+	if name, err := defaultContainer(pod); err != nil || name != "app" {
+		t.Errorf("Expected the annotated default container, got %q, %v", name, err)
+	}
+}
@@ -0,0 +1,276 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	// DefaultFailuresLimit is the number of failing pods ListFailures returns
+	// per page unless told otherwise.
+	DefaultFailuresLimit = 100
+	// MaxFailuresLimit bounds the page size of ListFailures.
+	MaxFailuresLimit = 500
+	// maxFailureReasonLength bounds the one-line reason reported per pod.
+	maxFailureReasonLength = 200
+)
+
+// podStatus returns the status kubectl get pods prints for a pod, such as
+// Running, Pending, CrashLoopBackOff, Init:ImagePullBackOff, Evicted,
+// ExitCode:1 or Terminating.
+func podStatus(pod *corev1.Pod) string {
+	status := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		status = pod.Status.Reason
+	}
+
+	if _, stuck := failingInitContainer(pod); stuck {
+		status = initStatus(pod)
+	} else {
+		hasRunning := false
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			container := pod.Status.ContainerStatuses[i]
+			switch {
+			case container.State.Waiting != nil && container.State.Waiting.Reason != "":
+				status = container.State.Waiting.Reason
+			case container.State.Terminated != nil && container.State.Terminated.Reason != "":
+				status = container.State.Terminated.Reason
+			case container.State.Terminated != nil && container.State.Terminated.Signal != 0:
+				status = fmt.Sprintf("Signal:%d", container.State.Terminated.Signal)
+			case container.State.Terminated != nil:
+				status = fmt.Sprintf("ExitCode:%d", container.State.Terminated.ExitCode)
+			case container.Ready && container.State.Running != nil:
+				hasRunning = true
+			}
+		}
+		if status == "Completed" && hasRunning {
+			status = "Running"
+			if !isPodReady(pod) {
+				status = "NotReady"
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		if pod.Status.Reason == "NodeLost" {
+			return "Unknown"
+		}
+		return "Terminating"
+	}
+	return status
+}
+
+// podFailing reports whether a pod with the given kubectl status is failing:
+// it is neither running nor done, or it is stuck terminating past its grace
+// period.
+func podFailing(pod *corev1.Pod, status string, now time.Time) bool {
+	switch status {
+	case "Running", "Succeeded", "Completed":
+		return false
+	case "Terminating":
+		return now.After(pod.DeletionTimestamp.Time)
+	}
+	return true
+}
+
+// podFailureReason explains in one line why a pod is failing: the message of
+// its failing container or init container, why it cannot be scheduled, or
+// the pod's own status message.
+func podFailureReason(pod *corev1.Pod, status string) string {
+	reason := ""
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, container := range statuses {
+		switch {
+		case container.State.Waiting != nil && container.State.Waiting.Reason != "" && container.State.Waiting.Reason != "PodInitializing":
+			reason = fmt.Sprintf("container %s is waiting: %s", container.Name, container.State.Waiting.Reason)
+			if message := container.State.Waiting.Message; message != "" {
+				reason += ": " + message
+			} else if last := container.LastTerminationState.Terminated; last != nil {
+				reason += fmt.Sprintf(" after exiting with code %d (%s)", last.ExitCode, last.Reason)
+			}
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode != 0:
+			reason = fmt.Sprintf("container %s exited with code %d", container.Name, container.State.Terminated.ExitCode)
+			if container.State.Terminated.Reason != "" {
+				reason += " (" + container.State.Terminated.Reason + ")"
+			}
+			if message := container.State.Terminated.Message; message != "" {
+				reason += ": " + message
+			}
+		default:
+			continue
+		}
+		break
+	}
+
+	if reason == "" {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				reason = "not scheduled: " + condition.Message
+				if condition.Message == "" {
+					reason = "not scheduled: " + condition.Reason
+				}
+			}
+		}
+	}
+	switch {
+	case reason != "":
+	case status == "Terminating":
+		reason = fmt.Sprintf("still terminating %s after its grace period ended", duration.HumanDuration(time.Since(pod.DeletionTimestamp.Time)))
+		if len(pod.Finalizers) > 0 {
+			reason += ", waiting for finalizers " + strings.Join(pod.Finalizers, ", ")
+		}
+	case pod.Status.Message != "":
+		reason = pod.Status.Message
+	default:
+		reason = status
+	}
+
+	// Keep the first line, short enough to scan
+	reason, _, _ = strings.Cut(strings.TrimSpace(reason), "\n")
+	if runes := []rune(reason); len(runes) > maxFailureReasonLength {
+		reason = string(runes[:maxFailureReasonLength-3]) + "..."
+	}
+	return reason
+}
+
+// ListFailures lists every pod across the cluster, or in namespace, that is
+// not running or done, like kubectl get pods -A | grep -v Running: pods
+// pending or failing to start, crash looping, failed, evicted, or stuck
+// terminating past their grace period. Pods that are running but not ready
+// are left out, as kubectl prints them as Running. Each pod has the status
+// kubectl prints and a one-line reason, and the pods are grouped by
+// namespace and by the controller owning them, resolving ReplicaSets to
+// their Deployments. The failing pods are sorted by namespace, owner and
+// name, and a page of at most limit of them starting at offset is returned;
+// "nextOffset" gives the offset of the next page while pods remain.
+// Returns a map with the "total" number of failing pods, counts "byStatus"
+// and "byNamespace" across all pages, and the page's "namespaces", or an
+// error.
+func (c *Client) ListFailures(ctx context.Context, namespace string, limit, offset int) (map[string]interface{}, error) {
+	if limit <= 0 {
+		limit = DefaultFailuresLimit
+	}
+	if limit > MaxFailuresLimit {
+		limit = MaxFailuresLimit
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("offset must be zero or greater")
+	}
+
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	type failure struct {
+		pod    *corev1.Pod
+		status string
+		owner  map[string]interface{}
+	}
+	now := time.Now()
+	owners := map[string]map[string]interface{}{}
+	byStatus := map[string]int{}
+	byNamespace := map[string]int{}
+	var failures []failure
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		status := podStatus(pod)
+		if !podFailing(pod, status, now) {
+			continue
+		}
+		byStatus[status]++
+		byNamespace[pod.Namespace]++
+		failures = append(failures, failure{pod: pod, status: status, owner: c.podController(ctx, pod, owners)})
+	}
+
+	ownerKey := func(owner map[string]interface{}) string {
+		if owner == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v/%v", owner["kind"], owner["name"])
+	}
+	sort.SliceStable(failures, func(i, j int) bool {
+		a, b := failures[i], failures[j]
+		if a.pod.Namespace != b.pod.Namespace {
+			return a.pod.Namespace < b.pod.Namespace
+		}
+		if ka, kb := ownerKey(a.owner), ownerKey(b.owner); ka != kb {
+			return ka < kb
+		}
+		return a.pod.Name < b.pod.Name
+	})
+
+	page := failures
+	if offset >= len(page) {
+		page = nil
+	} else {
+		page = page[offset:]
+	}
+	if len(page) > limit {
+		page = page[:limit]
+	}
+
+	// Group the page by namespace, then by owner, keeping the sort order
+	namespaces := []map[string]interface{}{}
+	var groups []map[string]interface{}
+	lastNamespace, lastOwner := "", ""
+	for i, f := range page {
+		if i == 0 || f.pod.Namespace != lastNamespace {
+			groups = []map[string]interface{}{}
+			namespaces = append(namespaces, map[string]interface{}{
+				"namespace": f.pod.Namespace,
+				"failing":   byNamespace[f.pod.Namespace],
+				"owners":    groups,
+			})
+			lastNamespace, lastOwner = f.pod.Namespace, "\x00"
+		}
+		if key := ownerKey(f.owner); key != lastOwner {
+			group := map[string]interface{}{"pods": []map[string]interface{}{}}
+			if f.owner != nil {
+				group["kind"] = f.owner["kind"]
+				group["name"] = f.owner["name"]
+			}
+			groups = append(groups, group)
+			namespaces[len(namespaces)-1]["owners"] = groups
+			lastOwner = key
+		}
+
+		var restarts int32
+		for _, container := range f.pod.Status.ContainerStatuses {
+			restarts += container.RestartCount
+		}
+		entry := map[string]interface{}{
+			"name":     f.pod.Name,
+			"status":   f.status,
+			"reason":   podFailureReason(f.pod, f.status),
+			"restarts": restarts,
+			"age":      duration.HumanDuration(now.Sub(f.pod.CreationTimestamp.Time)),
+		}
+		if f.pod.Spec.NodeName != "" {
+			entry["node"] = f.pod.Spec.NodeName
+		}
+		group := groups[len(groups)-1]
+		group["pods"] = append(group["pods"].([]map[string]interface{}), entry)
+	}
+
+	result := map[string]interface{}{
+		"total":       len(failures),
+		"scannedPods": len(pods.Items),
+		"byStatus":    byStatus,
+		"byNamespace": byNamespace,
+		"offset":      offset,
+		"limit":       limit,
+		"namespaces":  namespaces,
+	}
+	if next := offset + len(page); next < len(failures) {
+		result["nextOffset"] = next
+	}
+	return result, nil
+}
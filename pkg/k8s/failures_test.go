@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestListFailures tests listing the failing pods grouped by namespace and owner, with paging
+func TestListFailures(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/pods":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"web-abc-1","namespace":"shop","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"web-abc","uid":"r1","controller":true}]},"spec":{"nodeName":"node-1"},` +
+				`"status":{"phase":"Running","containerStatuses":[{"name":"app","restartCount":7,"state":{"waiting":{"reason":"CrashLoopBackOff","message":"back-off 5m0s restarting failed container"}},"lastState":{"terminated":{"exitCode":1,"reason":"Error"}}}]}},` +
+				`{"metadata":{"name":"web-abc-2","namespace":"shop","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"web-abc","uid":"r1","controller":true}]},` +
+				`"status":{"phase":"Pending","conditions":[{"type":"PodScheduled","status":"False","reason":"Unschedulable","message":"0/3 nodes are available: 3 Insufficient cpu."}]}},` +
+				`{"metadata":{"name":"web-abc-3","namespace":"shop","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"web-abc","uid":"r1","controller":true}]},` +
+				`"status":{"phase":"Running","conditions":[{"type":"Ready","status":"True"}],"containerStatuses":[{"name":"app","ready":true,"state":{"running":{}}}]}},` +
+				`{"metadata":{"name":"worker","namespace":"batch"},"status":{"phase":"Failed","reason":"Evicted","message":"The node was low on resource: memory.\nContainer app was using 2Gi."}},` +
+				`{"metadata":{"name":"migrate","namespace":"batch","ownerReferences":[{"apiVersion":"batch/v1","kind":"Job","name":"migrate","uid":"j1","controller":true}]},` +
+				`"spec":{"initContainers":[{"name":"wait-db"}]},"status":{"phase":"Pending","initContainerStatuses":[{"name":"wait-db","state":{"waiting":{"reason":"ImagePullBackOff","message":"Back-off pulling image \"db:missing\""}}}],` +
+				`"containerStatuses":[{"name":"app","state":{"waiting":{"reason":"PodInitializing"}}}]}},` +
+				`{"metadata":{"name":"report","namespace":"batch"},"status":{"phase":"Succeeded","containerStatuses":[{"name":"app","state":{"terminated":{"exitCode":0,"reason":"Completed"}}}]}},` +
+				`{"metadata":{"name":"stuck","namespace":"batch","deletionTimestamp":"2024-05-01T10:00:00Z","finalizers":["example.com/cleanup"]},"status":{"phase":"Running"}}]}`))
+		case "/apis/apps/v1/namespaces/shop/replicasets/web-abc":
+			w.Write([]byte(`{"kind":"ReplicaSet","apiVersion":"apps/v1","metadata":{"name":"web-abc","namespace":"shop",` +
+				`"ownerReferences":[{"apiVersion":"apps/v1","kind":"Deployment","name":"web","uid":"d1","controller":true}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.ListFailures(context.Background(), "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["total"] != 5 || result["scannedPods"] != 7 || result["limit"] != DefaultFailuresLimit {
+		t.Fatalf("Expected 5 failing pods of 7, got %v", result)
+	}
+	if _, ok := result["nextOffset"]; ok {
+		t.Errorf("Expected no next page, got %v", result["nextOffset"])
+	}
+	byStatus := result["byStatus"].(map[string]int)
+	for _, status := range []string{"CrashLoopBackOff", "Pending", "Evicted", "Init:ImagePullBackOff", "Terminating"} {
+		if byStatus[status] != 1 {
+			t.Errorf("Expected one %s pod, got %v", status, byStatus)
+		}
+	}
+
+	namespaces := result["namespaces"].([]map[string]interface{})
+	if len(namespaces) != 2 || namespaces[0]["namespace"] != "batch" || namespaces[1]["namespace"] != "shop" {
+		t.Fatalf("Expected batch and shop, got %v", namespaces)
+	}
+	batch := namespaces[0]["owners"].([]map[string]interface{})
+	if len(batch) != 2 || batch[0]["kind"] != nil || batch[1]["kind"] != "Job" {
+		t.Fatalf("Expected unowned pods before the Job, got %v", batch)
+	}
+	unowned := batch[0]["pods"].([]map[string]interface{})
+	if len(unowned) != 2 || unowned[0]["name"] != "stuck" || unowned[1]["reason"] != "The node was low on resource: memory." {
+		t.Errorf("Expected the stuck and evicted pods with the first line of the message, got %v", unowned)
+	}
+	if reason := unowned[0]["reason"].(string); !strings.Contains(reason, "example.com/cleanup") {
+		t.Errorf("Expected the finalizer holding the pod, got %q", reason)
+	}
+	if reason := batch[1]["pods"].([]map[string]interface{})[0]["reason"].(string); !strings.Contains(reason, "wait-db is waiting: ImagePullBackOff") {
+		t.Errorf("Expected the failing init container, got %q", reason)
+	}
+
+	shop := namespaces[1]["owners"].([]map[string]interface{})
+	if len(shop) != 1 || shop[0]["kind"] != "Deployment" || shop[0]["name"] != "web" {
+		t.Fatalf("Expected the pods grouped under Deployment web, got %v", shop)
+	}
+	web := shop[0]["pods"].([]map[string]interface{})
+	if len(web) != 2 || web[0]["restarts"] != int32(7) || web[0]["node"] != "node-1" ||
+		!strings.Contains(web[0]["reason"].(string), "CrashLoopBackOff: back-off 5m0s") ||
+		web[1]["reason"] != "not scheduled: 0/3 nodes are available: 3 Insufficient cpu." {
+		t.Errorf("Expected the crash looping and unschedulable pods, got %v", web)
+	}
+
+	page, err := client.ListFailures(context.Background(), "", 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page["nextOffset"] != 4 || page["total"] != 5 {
+		t.Errorf("Expected a next page at 4, got %v", page)
+	}
+	namespaces = page["namespaces"].([]map[string]interface{})
+	if len(namespaces) != 2 || namespaces[0]["owners"].([]map[string]interface{})[0]["name"] != "migrate" {
+		t.Errorf("Expected the page to start at the Job, got %v", namespaces)
+	}
+}
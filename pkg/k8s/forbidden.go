@@ -0,0 +1,296 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// forbiddenMessagePattern matches the request the API server names in the
+// message of a 403 response, e.g. `User "jane" cannot list resource "pods" in
+// API group "" in the namespace "web"` or `User "jane" cannot get path "/metrics"`.
+var forbiddenMessagePattern = regexp.MustCompile(`User "([^"]*)" cannot (\S+) (?:resource "([^"]*)" in API group "([^"]*)"(?: in the namespace "([^"]*)")?|path "([^"]*)")`)
+
+// builtinRoles are the default ClusterRoles, which are reconciled by the API
+// server and should be bound rather than amended.
+var builtinRoles = map[string]bool{"cluster-admin": true, "admin": true, "edit": true, "view": true}
+
+// ForbiddenRequest is the request a 403 response refused, as named in its
+// message.
+type ForbiddenRequest struct {
+	User        string
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	Name        string
+	Namespace   string
+	Path        string
+}
+
+// boundRole is a role granted to a user through a binding.
+type boundRole struct {
+	kind             string
+	name             string
+	namespace        string
+	bindingKind      string
+	bindingName      string
+	bindingNamespace string
+}
+
+// ParseForbidden extracts the refused request from a 403 error of the API
+// server. It reports false for other errors and for messages that do not name
+// the request, such as those of admission webhooks.
+func ParseForbidden(err error) (ForbiddenRequest, bool) {
+	if !apierrors.IsForbidden(err) {
+		return ForbiddenRequest{}, false
+	}
+	match := forbiddenMessagePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return ForbiddenRequest{}, false
+	}
+
+	request := ForbiddenRequest{User: match[1], Verb: match[2], Group: match[4], Namespace: match[5], Path: match[6]}
+	request.Resource, request.Subresource, _ = strings.Cut(match[3], "/")
+	var statusErr apierrors.APIStatus
+	if errors.As(err, &statusErr) && statusErr.Status().Details != nil {
+		request.Name = statusErr.Status().Details.Name
+	}
+	return request, true
+}
+
+// DiagnoseForbidden explains a refused request. It re-checks the permission
+// with a SelfSubjectAccessReview, states the rule that would grant it, and
+// looks up the roles bound to the user to name the one to amend, or suggests
+// a new role when none fits. Bindings the client may not read are reported in
+// "errors".
+// Returns a map with the "request", whether it is "allowed" now, the
+// "missingRule", the "boundRoles", a "suggestion" and "errors".
+func (c *Client) DiagnoseForbidden(ctx context.Context, request ForbiddenRequest) map[string]interface{} {
+	errs := []string{}
+	diagnosis := map[string]interface{}{
+		"request":     forbiddenRequestSummary(request),
+		"missingRule": missingRule(request),
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{}
+	if request.Path != "" {
+		review.Spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{Path: request.Path, Verb: request.Verb}
+	} else {
+		review.Spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace:   request.Namespace,
+			Verb:        request.Verb,
+			Group:       request.Group,
+			Resource:    request.Resource,
+			Subresource: request.Subresource,
+			Name:        request.Name,
+		}
+	}
+	allowed := false
+	if result, err := c.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{}); err != nil {
+		errs = append(errs, fmt.Sprintf("selfSubjectAccessReview: %v", err))
+	} else {
+		allowed = result.Status.Allowed
+		diagnosis["allowed"] = allowed
+		if result.Status.Reason != "" {
+			diagnosis["reason"] = result.Status.Reason
+		}
+		if result.Status.EvaluationError != "" {
+			diagnosis["evaluationError"] = result.Status.EvaluationError
+		}
+	}
+
+	groups := serviceAccountGroups(request.User)
+	if self, err := c.clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{}); err == nil && self.Status.UserInfo.Username == request.User {
+		groups = self.Status.UserInfo.Groups
+	}
+	diagnosis["user"] = request.User
+	diagnosis["groups"] = groups
+
+	var roles []boundRole
+	if request.Namespace != "" {
+		bindings, err := c.clientset.RbacV1().RoleBindings(request.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("roleBindings: %v", err))
+		} else {
+			for _, binding := range bindings.Items {
+				if subjectsMatch(binding.Subjects, binding.Namespace, request.User, groups) {
+					roles = append(roles, boundRole{
+						kind: binding.RoleRef.Kind, name: binding.RoleRef.Name, namespace: roleNamespace(binding.RoleRef, binding.Namespace),
+						bindingKind: "RoleBinding", bindingName: binding.Name, bindingNamespace: binding.Namespace,
+					})
+				}
+			}
+		}
+	}
+	clusterBindings, err := c.clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("clusterRoleBindings: %v", err))
+	} else {
+		for _, binding := range clusterBindings.Items {
+			if subjectsMatch(binding.Subjects, "", request.User, groups) {
+				roles = append(roles, boundRole{
+					kind: binding.RoleRef.Kind, name: binding.RoleRef.Name,
+					bindingKind: "ClusterRoleBinding", bindingName: binding.Name,
+				})
+			}
+		}
+	}
+
+	boundRoles := []map[string]interface{}{}
+	for _, role := range roles {
+		summary := map[string]interface{}{
+			"kind":    role.kind,
+			"name":    role.name,
+			"binding": map[string]interface{}{"kind": role.bindingKind, "name": role.bindingName},
+		}
+		if role.namespace != "" {
+			summary["namespace"] = role.namespace
+		}
+		if role.bindingNamespace != "" {
+			summary["binding"].(map[string]interface{})["namespace"] = role.bindingNamespace
+		}
+		boundRoles = append(boundRoles, summary)
+	}
+	diagnosis["boundRoles"] = boundRoles
+	diagnosis["suggestion"] = forbiddenSuggestion(request, allowed, roles)
+	diagnosis["errors"] = errs
+	return diagnosis
+}
+
+// forbiddenRequestSummary describes a refused request.
+func forbiddenRequestSummary(request ForbiddenRequest) map[string]interface{} {
+	if request.Path != "" {
+		return map[string]interface{}{"verb": request.Verb, "path": request.Path}
+	}
+	summary := map[string]interface{}{"verb": request.Verb, "apiGroup": request.Group, "resource": request.Resource}
+	if request.Subresource != "" {
+		summary["subresource"] = request.Subresource
+	}
+	if request.Name != "" {
+		summary["name"] = request.Name
+	}
+	if request.Namespace != "" {
+		summary["namespace"] = request.Namespace
+	}
+	return summary
+}
+
+// missingRule returns the PolicyRule that would grant a refused request.
+func missingRule(request ForbiddenRequest) rbacv1.PolicyRule {
+	if request.Path != "" {
+		return rbacv1.PolicyRule{NonResourceURLs: []string{request.Path}, Verbs: []string{request.Verb}}
+	}
+	resource := request.Resource
+	if request.Subresource != "" {
+		resource += "/" + request.Subresource
+	}
+	return rbacv1.PolicyRule{APIGroups: []string{request.Group}, Resources: []string{resource}, Verbs: []string{request.Verb}}
+}
+
+// serviceAccountGroups returns the groups the API server puts a service
+// account user in, or nil for other users.
+func serviceAccountGroups(user string) []string {
+	rest, ok := strings.CutPrefix(user, "system:serviceaccount:")
+	if !ok {
+		return nil
+	}
+	namespace, _, _ := strings.Cut(rest, ":")
+	return []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"}
+}
+
+// subjectsMatch reports whether the subjects of a binding in bindingNamespace
+// include the user or one of its groups.
+func subjectsMatch(subjects []rbacv1.Subject, bindingNamespace, user string, groups []string) bool {
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case rbacv1.UserKind:
+			if subject.Name == user {
+				return true
+			}
+		case rbacv1.GroupKind:
+			for _, group := range groups {
+				if subject.Name == group {
+					return true
+				}
+			}
+		case rbacv1.ServiceAccountKind:
+			namespace := subject.Namespace
+			if namespace == "" {
+				namespace = bindingNamespace
+			}
+			if user == "system:serviceaccount:"+namespace+":"+subject.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// roleNamespace returns the namespace of the role a RoleBinding refers to,
+// which is empty for a ClusterRole.
+func roleNamespace(ref rbacv1.RoleRef, bindingNamespace string) string {
+	if ref.Kind == "Role" {
+		return bindingNamespace
+	}
+	return ""
+}
+
+// forbiddenSuggestion names the role to amend for a refused request: a Role
+// bound in the namespace first, then a ClusterRole bound in the namespace,
+// then one bound cluster-wide. Built-in and system roles are never suggested,
+// as they are reconciled by the API server.
+func forbiddenSuggestion(request ForbiddenRequest, allowed bool, roles []boundRole) string {
+	if allowed {
+		return "The permission is granted now; the bindings may have changed since the call failed, so retry it."
+	}
+	namespaced := request.Namespace != "" && request.Path == ""
+
+	var candidates []boundRole
+	for _, role := range roles {
+		if builtinRoles[role.name] || strings.HasPrefix(role.name, "system:") {
+			continue
+		}
+		if !namespaced && role.bindingKind != "ClusterRoleBinding" {
+			continue
+		}
+		candidates = append(candidates, role)
+	}
+	rank := func(role boundRole) int {
+		switch {
+		case role.kind == "Role":
+			return 0
+		case role.bindingKind == "RoleBinding":
+			return 1
+		}
+		return 2
+	}
+	var best *boundRole
+	for i := range candidates {
+		if best == nil || rank(candidates[i]) < rank(*best) {
+			best = &candidates[i]
+		}
+	}
+
+	switch {
+	case best != nil && best.kind == "Role":
+		return fmt.Sprintf("Add the missing rule to Role %s/%s, bound to %s by RoleBinding %s.", best.namespace, best.name, request.User, best.bindingName)
+	case best != nil && best.bindingKind == "RoleBinding":
+		return fmt.Sprintf("Add the missing rule to ClusterRole %s, bound to %s in namespace %s by RoleBinding %s. The ClusterRole may be bound elsewhere too.",
+			best.name, request.User, best.bindingNamespace, best.bindingName)
+	case best != nil:
+		return fmt.Sprintf("Add the missing rule to ClusterRole %s, bound to %s cluster-wide by ClusterRoleBinding %s. This grants it in every namespace.",
+			best.name, request.User, best.bindingName)
+	case namespaced:
+		return fmt.Sprintf("No amendable role is bound to %s; create a Role with the missing rule in namespace %s and bind it with a RoleBinding.", request.User, request.Namespace)
+	}
+	return fmt.Sprintf("No amendable role is bound to %s cluster-wide; create a ClusterRole with the missing rule and bind it with a ClusterRoleBinding.", request.User)
+}
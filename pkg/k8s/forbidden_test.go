@@ -0,0 +1,120 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestParseForbidden tests extracting the refused request from 403 errors
+func TestParseForbidden(t *testing.T) {
+	user := "system:serviceaccount:mcp:k8s-mcp-server"
+	tests := []struct {
+		name string
+		err  error
+		want ForbiddenRequest
+		ok   bool
+	}{
+		{
+			name: "namespaced list",
+			err: apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "",
+				fmt.Errorf(`User "%s" cannot list resource "deployments" in API group "apps" in the namespace "web"`, user)),
+			want: ForbiddenRequest{User: user, Verb: "list", Group: "apps", Resource: "deployments", Namespace: "web"},
+			ok:   true,
+		},
+		{
+			name: "named subresource",
+			err: fmt.Errorf("failed to get logs: %w", apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "api-0",
+				fmt.Errorf(`User "%s" cannot get resource "pods/log" in API group "" in the namespace "web"`, user))),
+			want: ForbiddenRequest{User: user, Verb: "get", Resource: "pods", Subresource: "log", Name: "api-0", Namespace: "web"},
+			ok:   true,
+		},
+		{
+			name: "cluster scope",
+			err: apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "",
+				fmt.Errorf(`User "jane" cannot list resource "nodes" in API group "" at the cluster scope`)),
+			want: ForbiddenRequest{User: "jane", Verb: "list", Resource: "nodes"},
+			ok:   true,
+		},
+		{
+			name: "non-resource path",
+			err:  apierrors.NewForbidden(schema.GroupResource{}, "", fmt.Errorf(`User "jane" cannot get path "/metrics"`)),
+			want: ForbiddenRequest{User: "jane", Verb: "get", Path: "/metrics"},
+			ok:   true,
+		},
+		{
+			name: "admission webhook",
+			err:  apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "web", fmt.Errorf("admission webhook denied the request")),
+			ok:   false,
+		},
+		{
+			name: "not forbidden",
+			err:  apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web"),
+			ok:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseForbidden(tt.err)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ParseForbidden() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// TestSubjectsMatch tests matching binding subjects against a user and its groups
+func TestSubjectsMatch(t *testing.T) {
+	user := "system:serviceaccount:mcp:k8s-mcp-server"
+	groups := serviceAccountGroups(user)
+	if len(groups) != 3 || groups[1] != "system:serviceaccounts:mcp" {
+		t.Fatalf("Unexpected service account groups %v", groups)
+	}
+
+	serviceAccount := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "k8s-mcp-server"}}
+	if !subjectsMatch(serviceAccount, "mcp", user, groups) {
+		t.Error("Expected a service account in the binding's namespace to match")
+	}
+	if subjectsMatch(serviceAccount, "web", user, groups) {
+		t.Error("Expected a service account of another namespace not to match")
+	}
+	if !subjectsMatch([]rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:mcp"}}, "web", user, groups) {
+		t.Error("Expected the service account group to match")
+	}
+	if subjectsMatch([]rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "jane"}}, "", user, groups) {
+		t.Error("Expected another user not to match")
+	}
+}
+
+// TestForbiddenSuggestion tests choosing the role to amend
+func TestForbiddenSuggestion(t *testing.T) {
+	request := ForbiddenRequest{User: "jane", Verb: "list", Resource: "pods", Namespace: "web"}
+	view := boundRole{kind: "ClusterRole", name: "view", bindingKind: "RoleBinding", bindingName: "jane-view", bindingNamespace: "web"}
+	reader := boundRole{kind: "ClusterRole", name: "reader", bindingKind: "ClusterRoleBinding", bindingName: "jane-reader"}
+	deployer := boundRole{kind: "Role", name: "deployer", namespace: "web", bindingKind: "RoleBinding", bindingName: "jane-deployer", bindingNamespace: "web"}
+
+	tests := []struct {
+		name    string
+		request ForbiddenRequest
+		allowed bool
+		roles   []boundRole
+		want    string
+	}{
+		{"allowed now", request, true, nil, "granted now"},
+		{"namespaced role preferred", request, false, []boundRole{reader, view, deployer}, "Role web/deployer"},
+		{"built-in roles skipped", request, false, []boundRole{view, reader}, "ClusterRole reader"},
+		{"nothing bound", request, false, []boundRole{view}, "create a Role"},
+		{"cluster scope", ForbiddenRequest{User: "jane", Verb: "list", Resource: "nodes"}, false, []boundRole{deployer}, "create a ClusterRole"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forbiddenSuggestion(tt.request, tt.allowed, tt.roles); !strings.Contains(got, tt.want) {
+				t.Errorf("forbiddenSuggestion() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// newTestClient returns a client of a test API server serving handler, which
+// is closed when the test ends.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := newClientForConfig(&rest.Config{Host: server.URL, ContentConfig: rest.ContentConfig{ContentType: "application/json"}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// testResource is an API resource served by serveTestDiscovery.
+type testResource struct {
+	groupVersion string
+	name         string
+	kind         string
+	namespaced   bool
+}
+
+// serveTestDiscovery answers r if it is a discovery request, describing the
+// group versions of resources, and reports whether it did.
+func serveTestDiscovery(w http.ResponseWriter, r *http.Request, resources []testResource) bool {
+	var response interface{}
+	switch r.URL.Path {
+	case "/api":
+		response = metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}}
+	case "/apis":
+		groups := metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList"}}
+		seen := map[string]bool{}
+		for _, resource := range resources {
+			group, version, found := strings.Cut(resource.groupVersion, "/")
+			if !found || seen[resource.groupVersion] {
+				continue
+			}
+			seen[resource.groupVersion] = true
+			groupVersion := metav1.GroupVersionForDiscovery{GroupVersion: resource.groupVersion, Version: version}
+			groups.Groups = append(groups.Groups, metav1.APIGroup{Name: group, Versions: []metav1.GroupVersionForDiscovery{groupVersion}, PreferredVersion: groupVersion})
+		}
+		response = groups
+	default:
+		var groupVersion string
+		switch {
+		case r.URL.Path == "/api/v1":
+			groupVersion = "v1"
+		case strings.HasPrefix(r.URL.Path, "/apis/") && strings.Count(r.URL.Path, "/") == 3:
+			groupVersion = strings.TrimPrefix(r.URL.Path, "/apis/")
+		default:
+			return false
+		}
+		list := metav1.APIResourceList{TypeMeta: metav1.TypeMeta{Kind: "APIResourceList"}, GroupVersion: groupVersion}
+		for _, resource := range resources {
+			if resource.groupVersion == groupVersion {
+				list.APIResources = append(list.APIResources, metav1.APIResource{
+					Name: resource.name, Kind: resource.kind, Namespaced: resource.namespaced,
+					Verbs: metav1.Verbs{"get", "list", "watch", "create", "update", "patch", "delete"},
+				})
+			}
+		}
+		response = list
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return true
+}
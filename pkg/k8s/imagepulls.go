@@ -0,0 +1,276 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// slowPullSeconds is the median pull duration above which an image is
+// reported as slow to pull.
+const slowPullSeconds = 60
+
+// imageEventPatterns match the image named by the kubelet's Pulling, Failed
+// and BackOff events.
+var (
+	pullingImagePattern = regexp.MustCompile(`^Pulling image "([^"]*)"`)
+	failedPullPattern   = regexp.MustCompile(`^Failed to pull image "([^"]*)": (.*)`)
+	backOffPullPattern  = regexp.MustCompile(`^Back-off pulling image "([^"]*)"`)
+)
+
+// pullFailureKinds classify the error of a failed pull by the phrases its
+// message contains, checked in order.
+var pullFailureKinds = []struct {
+	kind    string
+	phrases []string
+}{
+	{"rateLimited", []string{"429", "toomanyrequests", "too many requests", "rate limit"}},
+	{"unauthorized", []string{"401", "403", "unauthorized", "denied", "authentication required", "forbidden"}},
+	{"notFound", []string{"not found", "manifest unknown", "404"}},
+	{"timeout", []string{"timeout", "deadline exceeded", "context canceled"}},
+	{"network", []string{"no such host", "connection refused", "connection reset", "tls:", "x509"}},
+	{"disk", []string{"no space left"}},
+}
+
+// pullStats aggregates the pull events of an image, registry or node.
+type pullStats struct {
+	attempts  int32
+	pulls     int32
+	cached    int32
+	failures  int32
+	backOffs  int32
+	durations []time.Duration
+	reasons   map[string]int32
+}
+
+// GetImagePullReport aggregates the kubelet's image pull events of the last
+// windowMinutes, in namespace or in all namespaces if it is empty. Pulled
+// events give the pull durations, Failed events the failures, classified as
+// rateLimited, unauthorized, notFound, timeout, network, disk or other, and
+// BackOff events the retries waiting on a failed pull. Statistics are kept
+// per image, per registry and per node, taken from the event's reporting
+// instance, so registry throttling can be told apart from node-local
+// problems. Events are kept only for an hour by default, so older pulls are
+// not seen.
+// Returns a map with the "images", "registries" and "nodes" with their pull
+// counts, failure rates and median and maximum pull seconds, and "findings"
+// naming throttling registries, failing nodes and slow images, or an error.
+func (c *Client) GetImagePullReport(ctx context.Context, namespace string, windowMinutes int) (map[string]interface{}, error) {
+	if windowMinutes <= 0 {
+		windowMinutes = 60
+	}
+	events, err := c.listNormalizedEvents(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	windowStart := time.Now().Add(-time.Duration(windowMinutes) * time.Minute)
+	images := map[string]*pullStats{}
+	registries := map[string]*pullStats{}
+	nodes := map[string]*pullStats{}
+	stats := func(group map[string]*pullStats, key string) *pullStats {
+		if group[key] == nil {
+			group[key] = &pullStats{reasons: map[string]int32{}}
+		}
+		return group[key]
+	}
+
+	for _, event := range events {
+		involved, _ := event["involvedObject"].(map[string]interface{})
+		if involved["kind"] != "Pod" {
+			continue
+		}
+		if lastTime, ok := event["lastTime"].(time.Time); ok && lastTime.Before(windowStart) {
+			continue
+		}
+		message, _ := event["message"].(string)
+		count, _ := event["count"].(int32)
+		image, record := imagePullEvent(event["reason"], message, count)
+		if record == nil {
+			continue
+		}
+		registry, _, _ := parseImageReference(image)
+		node, _ := event["reportingInstance"].(string)
+		if node == "" {
+			node = "unknown"
+		}
+		for _, s := range []*pullStats{stats(images, image), stats(registries, registry), stats(nodes, node)} {
+			record(s)
+		}
+	}
+
+	findings := []string{}
+	registrySummaries := pullSummaries(registries, "registry")
+	for _, summary := range registrySummaries {
+		reasons := summary["failureReasons"].(map[string]int32)
+		if reasons["rateLimited"] > 0 {
+			findings = append(findings, fmt.Sprintf("Registry %s is throttling pulls (%d rate-limited failures); authenticate pulls with imagePullSecrets for higher limits or use a mirror",
+				summary["registry"], reasons["rateLimited"]))
+		}
+		if reasons["unauthorized"] > 0 {
+			findings = append(findings, fmt.Sprintf("Registry %s refused %d pulls as unauthorized; check the imagePullSecrets of the pods and their service accounts",
+				summary["registry"], reasons["unauthorized"]))
+		}
+	}
+	findings = append(findings, nodePullFindings(nodes)...)
+	imageSummaries := pullSummaries(images, "image")
+	for _, summary := range imageSummaries {
+		if median, ok := summary["medianSeconds"].(float64); ok && median > slowPullSeconds {
+			findings = append(findings, fmt.Sprintf("Image %s takes a median of %.0fs to pull; consider a smaller image, a closer mirror or pre-pulling it",
+				summary["image"], median))
+		}
+	}
+
+	if namespace == "" {
+		namespace = "all"
+	}
+	return map[string]interface{}{
+		"namespace":     namespace,
+		"windowMinutes": windowMinutes,
+		"images":        imageSummaries,
+		"registries":    registrySummaries,
+		"nodes":         pullSummaries(nodes, "node"),
+		"findings":      findings,
+	}, nil
+}
+
+// imagePullEvent returns the image an image pull event concerns and how to
+// record it, or a nil record for other events.
+func imagePullEvent(reason interface{}, message string, count int32) (string, func(*pullStats)) {
+	switch reason {
+	case "Pulling":
+		if match := pullingImagePattern.FindStringSubmatch(message); match != nil {
+			return match[1], func(s *pullStats) { s.attempts += count }
+		}
+	case "Pulled":
+		if match := pulledImagePattern.FindStringSubmatch(message); match != nil {
+			duration, err := time.ParseDuration(match[2])
+			if err != nil {
+				return "", nil
+			}
+			return match[1], func(s *pullStats) {
+				s.pulls += count
+				s.durations = append(s.durations, duration)
+			}
+		}
+		if image, _, ok := strings.Cut(strings.TrimPrefix(message, `Container image "`), `"`); ok && strings.Contains(message, "already present on machine") {
+			return image, func(s *pullStats) { s.cached += count }
+		}
+	case "Failed":
+		if match := failedPullPattern.FindStringSubmatch(message); match != nil {
+			kind := pullFailureKind(match[2])
+			return match[1], func(s *pullStats) {
+				s.failures += count
+				s.reasons[kind] += count
+			}
+		}
+	case "BackOff":
+		if match := backOffPullPattern.FindStringSubmatch(message); match != nil {
+			return match[1], func(s *pullStats) { s.backOffs += count }
+		}
+	}
+	return "", nil
+}
+
+// pullFailureKind classifies the error message of a failed pull.
+func pullFailureKind(message string) string {
+	lower := strings.ToLower(message)
+	for _, failure := range pullFailureKinds {
+		for _, phrase := range failure.phrases {
+			if strings.Contains(lower, phrase) {
+				return failure.kind
+			}
+		}
+	}
+	return "other"
+}
+
+// failureRate returns the share of pull attempts that failed, excluding
+// images that were already present.
+func (s *pullStats) failureRate() float64 {
+	if s.pulls+s.failures == 0 {
+		return 0
+	}
+	return float64(s.failures) / float64(s.pulls+s.failures)
+}
+
+// pullSummaries describes pull statistics keyed by key, the most failures
+// first, then the slowest.
+func pullSummaries(group map[string]*pullStats, key string) []map[string]interface{} {
+	names := make([]string, 0, len(group))
+	for name := range group {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		sort.Slice(group[name].durations, func(i, j int) bool { return group[name].durations[i] < group[name].durations[j] })
+	}
+	slowest := func(s *pullStats) time.Duration {
+		if len(s.durations) == 0 {
+			return 0
+		}
+		return s.durations[len(s.durations)-1]
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := group[names[i]], group[names[j]]
+		if a.failures != b.failures {
+			return a.failures > b.failures
+		}
+		if slowest(a) != slowest(b) {
+			return slowest(a) > slowest(b)
+		}
+		return names[i] < names[j]
+	})
+
+	summaries := []map[string]interface{}{}
+	for _, name := range names {
+		s := group[name]
+		summary := map[string]interface{}{
+			key:              name,
+			"attempts":       s.attempts,
+			"pulls":          s.pulls,
+			"cached":         s.cached,
+			"failures":       s.failures,
+			"backOffs":       s.backOffs,
+			"failureRate":    math.Round(s.failureRate()*100) / 100,
+			"failureReasons": s.reasons,
+		}
+		if len(s.durations) > 0 {
+			summary["medianSeconds"] = startupSeconds(s.durations[len(s.durations)/2])
+			summary["maxSeconds"] = startupSeconds(slowest(s))
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// nodePullFindings names nodes that fail at least half of their pulls, at
+// least twice, while the other nodes fail less than half as often, which
+// points at a problem local to the node rather than at the registry.
+func nodePullFindings(nodes map[string]*pullStats) []string {
+	findings := []string{}
+	for node, s := range nodes {
+		if node == "unknown" || s.failures < 2 || s.failureRate() < 0.5 {
+			continue
+		}
+		others := pullStats{}
+		for other, o := range nodes {
+			if other != node {
+				others.pulls += o.pulls
+				others.failures += o.failures
+			}
+		}
+		if others.pulls+others.failures == 0 || others.failureRate() >= s.failureRate()/2 {
+			continue
+		}
+		findings = append(findings, fmt.Sprintf("Node %s fails %.0f%% of its pulls while the other nodes fail %.0f%%; check its disk space, DNS and network path to the registries",
+			node, s.failureRate()*100, others.failureRate()*100))
+	}
+	sort.Strings(findings)
+	return findings
+}
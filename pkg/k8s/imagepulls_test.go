@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+)
+
+// TestImagePullEvent tests recording the kubelet's image pull events
+func TestImagePullEvent(t *testing.T) {
+	s := &pullStats{reasons: map[string]int32{}}
+	events := []struct {
+		reason  string
+		message string
+		count   int32
+		image   string
+	}{
+		{"Pulling", `Pulling image "ghcr.io/acme/api:2.1"`, 3, "ghcr.io/acme/api:2.1"},
+		{"Pulled", `Successfully pulled image "ghcr.io/acme/api:2.1" in 41.2s (41.2s including waiting). Image size: 1 bytes.`, 1, "ghcr.io/acme/api:2.1"},
+		{"Pulled", `Container image "ghcr.io/acme/api:2.1" already present on machine`, 2, "ghcr.io/acme/api:2.1"},
+		{"Failed", `Failed to pull image "ghcr.io/acme/api:2.1": rpc error: code = Unknown desc = 429 Too Many Requests`, 2, "ghcr.io/acme/api:2.1"},
+		{"BackOff", `Back-off pulling image "ghcr.io/acme/api:2.1"`, 4, "ghcr.io/acme/api:2.1"},
+	}
+	for _, event := range events {
+		image, record := imagePullEvent(event.reason, event.message, event.count)
+		if record == nil || image != event.image {
+			t.Fatalf("Expected %s event to be recorded for %s, got %q", event.reason, event.image, image)
+		}
+		record(s)
+	}
+	if s.attempts != 3 || s.pulls != 1 || s.cached != 2 || s.failures != 2 || s.backOffs != 4 || s.reasons["rateLimited"] != 2 {
+		t.Errorf("Unexpected statistics %+v", s)
+	}
+	if len(s.durations) != 1 || s.durations[0] != 41200*time.Millisecond {
+		t.Errorf("Expected the pull duration to be recorded, got %v", s.durations)
+	}
+	if _, record := imagePullEvent("Failed", "Error: ErrImagePull", 1); record != nil {
+		t.Error("Expected failures that do not name an image to be skipped")
+	}
+}
+
+// TestPullFailureKind tests classifying the errors of failed pulls
+func TestPullFailureKind(t *testing.T) {
+	tests := map[string]string{
+		"toomanyrequests: You have reached your pull rate limit":                 "rateLimited",
+		"failed to authorize: failed to fetch anonymous token: 401 Unauthorized": "unauthorized",
+		`manifest for acme/api:9 not found: manifest unknown`:                    "notFound",
+		"dial tcp: lookup registry.local: i/o timeout":                           "timeout",
+		"dial tcp 10.0.0.1:443: connect: connection refused":                     "network",
+		"write /var/lib/containerd: no space left on device":                     "disk",
+		"unexpected EOF": "other",
+	}
+	for message, want := range tests {
+		if kind := pullFailureKind(message); kind != want {
+			t.Errorf("pullFailureKind(%q) = %s, want %s", message, kind, want)
+		}
+	}
+}
+
+// TestNodePullFindings tests naming nodes with pull failures local to them
+func TestNodePullFindings(t *testing.T) {
+	nodes := map[string]*pullStats{
+		"node-a": {pulls: 1, failures: 3},
+		"node-b": {pulls: 10, failures: 1},
+		"node-c": {pulls: 8},
+	}
+	if findings := nodePullFindings(nodes); len(findings) != 1 || findings[0][:11] != "Node node-a" {
+		t.Errorf("Expected node-a to be named, got %v", findings)
+	}
+	nodes["node-b"].failures = 10
+	nodes["node-c"].failures = 8
+	if findings := nodePullFindings(nodes); len(findings) != 0 {
+		t.Errorf("Expected no node-local finding when other nodes fail as well, got %v", findings)
+	}
+}
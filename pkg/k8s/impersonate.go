@@ -0,0 +1,75 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// impersonationKey identifies the impersonating client of a client in a
+// ClientPool.
+type impersonationKey struct {
+	client *Client
+	user   string
+	groups string
+}
+
+// ServiceAccountUser returns the user name a service account authenticates
+// as, e.g. "system:serviceaccount:web:deployer", from a "namespace/name"
+// reference.
+func ServiceAccountUser(ref string) (string, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("service account %q must be given as namespace/name", ref)
+	}
+	return "system:serviceaccount:" + namespace + ":" + name, nil
+}
+
+// Impersonate returns a copy of the client whose requests carry Kubernetes
+// impersonation headers, so the API server authorizes them as user and groups
+// rather than as the client's own credentials. The client's credentials must
+// be allowed to impersonate them.
+func (c *Client) Impersonate(user string, groups []string) (*Client, error) {
+	if user == "" {
+		return nil, fmt.Errorf("a user is required to impersonate groups")
+	}
+	client, err := c.withConfig(func(config *rest.Config) {
+		config.Impersonate = rest.ImpersonationConfig{UserName: user, Groups: groups}
+	})
+	if err != nil {
+		return nil, err
+	}
+	client.impersonating = user
+	client.impersonatedAs = groups
+	client.impersonator = c
+	return client, nil
+}
+
+// Impersonating returns the user the client impersonates, or an empty string
+// if it acts with its own credentials.
+func (c *Client) Impersonating() string {
+	return c.impersonating
+}
+
+// Impersonate returns the client impersonating user and groups on behalf of
+// client, which must belong to the pool, creating it on first use and keeping
+// it for the lifetime of the server.
+func (p *ClientPool) Impersonate(client *Client, user string, groups []string) (*Client, error) {
+	sorted := append([]string{}, groups...)
+	sort.Strings(sorted)
+	key := impersonationKey{client: client, user: user, groups: strings.Join(sorted, "\x00")}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if impersonating, ok := p.impersonating[key]; ok {
+		return impersonating, nil
+	}
+	impersonating, err := client.Impersonate(user, sorted)
+	if err != nil {
+		return nil, err
+	}
+	p.impersonating[key] = impersonating
+	return impersonating, nil
+}
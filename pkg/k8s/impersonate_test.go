@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// TestServiceAccountUser tests converting service account references to user names
+func TestServiceAccountUser(t *testing.T) {
+	if user, err := ServiceAccountUser("web/deployer"); err != nil || user != "system:serviceaccount:web:deployer" {
+		t.Errorf("ServiceAccountUser(web/deployer) = %q, %v", user, err)
+	}
+	for _, ref := range []string{"deployer", "web/", "/deployer", "web/deployer/x"} {
+		if _, err := ServiceAccountUser(ref); err == nil {
+			t.Errorf("Expected an error for %q", ref)
+		}
+	}
+}
+
+// TestImpersonate tests that impersonating clients send impersonation headers and are cached per identity
+func TestImpersonate(t *testing.T) {
+	var users, groups []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		users = append(users, r.Header.Get("Impersonate-User"))
+		groups = append(groups, r.Header.Values("Impersonate-Group")...)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"web"}}`))
+	}))
+	pool := NewClientPool("", client)
+	jane, err := pool.Impersonate(client, "jane", []string{"devs", "admins"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := pool.Impersonate(client, "jane", []string{"admins", "devs"}); again != jane {
+		t.Error("Expected the impersonating client to be reused for the same identity")
+	}
+	if other, _ := pool.Impersonate(client, "jane", nil); other == jane {
+		t.Error("Expected a separate client for different groups")
+	}
+	if jane.Impersonating() != "jane" || client.Impersonating() != "" || jane.ContextName() != "test" {
+		t.Errorf("Unexpected impersonation or context of the clients")
+	}
+
+	ctx := context.Background()
+	if _, err := jane.clientset.CoreV1().Namespaces().Get(ctx, "web", metav1.GetOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.clientset.CoreV1().Namespaces().Get(ctx, "web", metav1.GetOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0] != "jane" || users[1] != "" || len(groups) != 2 {
+		t.Errorf("Expected only the impersonating client to send impersonation headers, got users %v and groups %v", users, groups)
+	}
+	if _, err := client.Impersonate("", []string{"devs"}); err == nil {
+		t.Error("Expected an error impersonating groups without a user")
+	}
+}
+
+// TestImpersonatedPortForwardsEndWithSession tests that the port forwards of impersonating clients are stopped with their session
+func TestImpersonatedPortForwardsEndWithSession(t *testing.T) {
+	client, err := newClientForConfig(&rest.Config{Host: "http://127.0.0.1:1"}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewClientPool("", client)
+	jane, err := pool.Impersonate(client, "jane", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forward := &portForward{session: "s", stop: make(chan struct{})}
+	if err := jane.portForwards.add(forward); err != nil {
+		t.Fatal(err)
+	}
+	if forwards := client.ListPortForwards(WithSessionID(context.Background(), "s")); len(forwards) != 1 {
+		t.Errorf("Expected the impersonated forward to be listed by the session, got %v", forwards)
+	}
+
+	stopped := 0
+	for _, c := range pool.Clients() {
+		stopped += c.StopSessionPortForwards("s")
+	}
+	if stopped != 1 || !forward.isClosed() {
+		t.Errorf("Expected the impersonated forward to be stopped with the session, stopped %d", stopped)
+	}
+}
@@ -0,0 +1,254 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ingressHostMatches reports whether an Ingress rule for ruleHost serves
+// host. A rule without a host serves every host, and a wildcard such as
+// *.example.com serves exactly one more label.
+func ingressHostMatches(ruleHost, host string) bool {
+	if host == "" || ruleHost == "" || ruleHost == host {
+		return true
+	}
+	if suffix, ok := strings.CutPrefix(ruleHost, "*."); ok {
+		label, rest, found := strings.Cut(host, ".")
+		return found && label != "" && rest == suffix
+	}
+	return false
+}
+
+// ingressPathMatches reports whether an Ingress path serves path according
+// to its pathType. Prefix paths match whole path elements; implementation
+// specific paths are treated as plain string prefixes, as most controllers do.
+func ingressPathMatches(ingressPath networkingv1.HTTPIngressPath, path string) bool {
+	if path == "" {
+		return true
+	}
+	rulePath := ingressPath.Path
+	if rulePath == "" {
+		rulePath = "/"
+	}
+	pathType := networkingv1.PathTypeImplementationSpecific
+	if ingressPath.PathType != nil {
+		pathType = *ingressPath.PathType
+	}
+	switch pathType {
+	case networkingv1.PathTypeExact:
+		return path == rulePath
+	case networkingv1.PathTypePrefix:
+		prefix := strings.TrimSuffix(rulePath, "/")
+		return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	default:
+		return strings.HasPrefix(path, rulePath)
+	}
+}
+
+// CheckIngressPorts validates the chain from Ingress backend port to Service
+// port to targetPort to containerPort for the Ingress paths serving host and
+// path, the usual cause of 502 and 503 responses. For each backend it
+// reports a missing Service, a backend port the Service does not expose, a
+// Service selecting no pods or no ready pods, a named targetPort no container
+// of the selected pods declares, and, as a warning, a numeric targetPort no
+// container declares, which still works if the process listens on it. An
+// empty host or path checks every rule or path; an empty namespace checks
+// Ingresses in all namespaces.
+// Returns a map with the checked "chains", each with its "problems" and
+// "warnings", and the number of chains with "mismatches", or an error.
+func (c *Client) CheckIngressPorts(ctx context.Context, namespace, host, path string) (map[string]interface{}, error) {
+	ingresses, err := c.clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	chains := []map[string]interface{}{}
+	mismatches := 0
+	for _, ingress := range ingresses.Items {
+		check := func(rule, rulePath, pathType string, backend networkingv1.IngressBackend) {
+			chain := c.checkIngressBackend(ctx, ingress.Namespace, backend)
+			chain["ingress"] = ingress.Name
+			chain["namespace"] = ingress.Namespace
+			chain["host"] = rule
+			chain["path"] = rulePath
+			if pathType != "" {
+				chain["pathType"] = pathType
+			}
+			if len(chain["problems"].([]string)) > 0 {
+				mismatches++
+			}
+			chains = append(chains, chain)
+		}
+
+		matched := false
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil || !ingressHostMatches(rule.Host, host) {
+				continue
+			}
+			for _, ingressPath := range rule.HTTP.Paths {
+				if !ingressPathMatches(ingressPath, path) {
+					continue
+				}
+				matched = true
+				pathType := ""
+				if ingressPath.PathType != nil {
+					pathType = string(*ingressPath.PathType)
+				}
+				check(rule.Host, ingressPath.Path, pathType, ingressPath.Backend)
+			}
+		}
+		// The default backend serves requests no rule matches
+		if !matched && ingress.Spec.DefaultBackend != nil && (host == "" || len(ingress.Spec.Rules) == 0) {
+			check("", "(default backend)", "", *ingress.Spec.DefaultBackend)
+		}
+	}
+
+	result := map[string]interface{}{
+		"chains":     chains,
+		"mismatches": mismatches,
+	}
+	if len(chains) == 0 {
+		result["message"] = "no Ingress path serves the given host and path"
+	}
+	return result, nil
+}
+
+// checkIngressBackend follows one Ingress backend to its Service and the
+// containers of the pods the Service selects.
+func (c *Client) checkIngressBackend(ctx context.Context, namespace string, backend networkingv1.IngressBackend) map[string]interface{} {
+	problems := []string{}
+	warnings := []string{}
+	chain := map[string]interface{}{}
+	defer func() {
+		chain["problems"] = problems
+		chain["warnings"] = warnings
+	}()
+
+	if backend.Service == nil {
+		if backend.Resource != nil {
+			chain["resource"] = fmt.Sprintf("%s/%s", backend.Resource.Kind, backend.Resource.Name)
+			warnings = append(warnings, "resource backends are not checked")
+		}
+		return chain
+	}
+	chain["service"] = backend.Service.Name
+	backendPort := backend.Service.Port.Name
+	if backendPort == "" {
+		backendPort = fmt.Sprint(backend.Service.Port.Number)
+	}
+	chain["backendPort"] = backendPort
+
+	service, err := c.clientset.CoreV1().Services(namespace).Get(ctx, backend.Service.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		problems = append(problems, fmt.Sprintf("service %s not found", backend.Service.Name))
+		return chain
+	}
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("failed to get service %s: %v", backend.Service.Name, err))
+		return chain
+	}
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		chain["externalName"] = service.Spec.ExternalName
+		warnings = append(warnings, "ExternalName services have no ports or pods to check")
+		return chain
+	}
+
+	var servicePort *corev1.ServicePort
+	var exposed []string
+	for i, port := range service.Spec.Ports {
+		exposed = append(exposed, fmt.Sprintf("%d/%s", port.Port, port.Name))
+		if (backend.Service.Port.Name != "" && port.Name == backend.Service.Port.Name) ||
+			(backend.Service.Port.Name == "" && port.Port == backend.Service.Port.Number) {
+			servicePort = &service.Spec.Ports[i]
+		}
+	}
+	if servicePort == nil {
+		problems = append(problems, fmt.Sprintf("service %s has no port %s (ports: %s)", service.Name, backendPort, strings.Join(exposed, ", ")))
+		return chain
+	}
+	targetPort := servicePort.TargetPort
+	if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+		targetPort = intstr.FromInt32(servicePort.Port)
+	}
+	chain["servicePort"] = servicePort.Port
+	chain["targetPort"] = targetPort.String()
+
+	if len(service.Spec.Selector) == 0 {
+		warnings = append(warnings, fmt.Sprintf("service %s has no selector; its endpoints are managed outside Kubernetes", service.Name))
+		return chain
+	}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+	})
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("failed to list the pods of service %s: %v", service.Name, err))
+		return chain
+	}
+	chain["pods"] = len(pods.Items)
+	if len(pods.Items) == 0 {
+		problems = append(problems, fmt.Sprintf("service %s selects no pods (selector %s)", service.Name, labels.SelectorFromSet(service.Spec.Selector)))
+		return chain
+	}
+
+	ready := 0
+	containerPorts := map[string]bool{}
+	var unresolved []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if isPodReady(pod) {
+			ready++
+		}
+		found := false
+		for _, ctr := range pod.Spec.Containers {
+			for _, port := range ctr.Ports {
+				if (targetPort.Type == intstr.String && port.Name == targetPort.StrVal) ||
+					(targetPort.Type == intstr.Int && port.ContainerPort == targetPort.IntVal) {
+					found = true
+					containerPorts[fmt.Sprintf("%s:%d", ctr.Name, port.ContainerPort)] = true
+				}
+			}
+		}
+		if !found {
+			unresolved = append(unresolved, pod.Name)
+		}
+	}
+	chain["readyPods"] = ready
+	resolved := make([]string, 0, len(containerPorts))
+	for port := range containerPorts {
+		resolved = append(resolved, port)
+	}
+	sort.Strings(resolved)
+	chain["containerPorts"] = resolved
+
+	if ready == 0 {
+		problems = append(problems, fmt.Sprintf("none of the %d pods of service %s is ready", len(pods.Items), service.Name))
+	}
+	if len(unresolved) > 0 {
+		if targetPort.Type == intstr.String {
+			problems = append(problems, fmt.Sprintf("named targetPort %s is not declared by any container of %d pods, e.g. %s", targetPort.StrVal, len(unresolved), unresolved[0]))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("targetPort %d is not declared as a containerPort in %d pods, e.g. %s; check the process listens on it", targetPort.IntVal, len(unresolved), unresolved[0]))
+		}
+	}
+	return chain
+}
+
+// isPodReady reports whether the pod's Ready condition is true, so that
+// Services route to it.
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
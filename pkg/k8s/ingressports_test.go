@@ -0,0 +1,92 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// TestIngressMatches tests matching request hosts and paths against Ingress rules
+func TestIngressMatches(t *testing.T) {
+	hosts := []struct {
+		rule, host string
+		want       bool
+	}{
+		{"shop.example.com", "shop.example.com", true},
+		{"", "shop.example.com", true},
+		{"*.example.com", "shop.example.com", true},
+		{"*.example.com", "a.shop.example.com", false},
+		{"*.example.com", "example.com", false},
+		{"api.example.com", "shop.example.com", false},
+	}
+	for _, tt := range hosts {
+		if got := ingressHostMatches(tt.rule, tt.host); got != tt.want {
+			t.Errorf("ingressHostMatches(%q, %q) = %v, want %v", tt.rule, tt.host, got, tt.want)
+		}
+	}
+
+	prefix, exact := networkingv1.PathTypePrefix, networkingv1.PathTypeExact
+	paths := []struct {
+		path    networkingv1.HTTPIngressPath
+		request string
+		want    bool
+	}{
+		{networkingv1.HTTPIngressPath{Path: "/api", PathType: &prefix}, "/api/v1", true},
+		{networkingv1.HTTPIngressPath{Path: "/api", PathType: &prefix}, "/apis", false},
+		{networkingv1.HTTPIngressPath{Path: "/", PathType: &prefix}, "/anything", true},
+		{networkingv1.HTTPIngressPath{Path: "/api", PathType: &exact}, "/api/v1", false},
+		{networkingv1.HTTPIngressPath{Path: "/api"}, "/apis", true},
+	}
+	for _, tt := range paths {
+		if got := ingressPathMatches(tt.path, tt.request); got != tt.want {
+			t.Errorf("ingressPathMatches(%q, %q) = %v, want %v", tt.path.Path, tt.request, got, tt.want)
+		}
+	}
+}
+
+// TestCheckIngressPorts tests following Ingress backends to the container ports of their pods
+func TestCheckIngressPorts(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/networking.k8s.io/v1/namespaces/shop/ingresses":
+			w.Write([]byte(`{"kind":"IngressList","apiVersion":"networking.k8s.io/v1","items":[{"metadata":{"name":"shop","namespace":"shop"},"spec":{"rules":[` +
+				`{"host":"shop.example.com","http":{"paths":[` +
+				`{"path":"/api","pathType":"Prefix","backend":{"service":{"name":"api","port":{"number":8080}}}},` +
+				`{"path":"/","pathType":"Prefix","backend":{"service":{"name":"web","port":{"name":"http"}}}}]}},` +
+				`{"host":"admin.example.com","http":{"paths":[{"path":"/","pathType":"Prefix","backend":{"service":{"name":"admin","port":{"number":80}}}}]}}]}}]}`))
+		case "/api/v1/namespaces/shop/services/api":
+			w.Write([]byte(`{"kind":"Service","apiVersion":"v1","metadata":{"name":"api","namespace":"shop"},"spec":{"selector":{"app":"api"},"ports":[{"name":"http","port":80,"targetPort":8080}]}}`))
+		case "/api/v1/namespaces/shop/services/web":
+			w.Write([]byte(`{"kind":"Service","apiVersion":"v1","metadata":{"name":"web","namespace":"shop"},"spec":{"selector":{"app":"web"},"ports":[{"name":"http","port":80,"targetPort":"web"}]}}`))
+		case "/api/v1/namespaces/shop/pods":
+			if r.URL.Query().Get("labelSelector") != "app=web" {
+				t.Errorf("Unexpected pod selector %q", r.URL.Query().Get("labelSelector"))
+			}
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[{"metadata":{"name":"web-1","namespace":"shop"},` +
+				`"spec":{"containers":[{"name":"nginx","image":"nginx","ports":[{"name":"web","containerPort":8000}]}]},` +
+				`"status":{"conditions":[{"type":"Ready","status":"True"}]}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.CheckIngressPorts(context.Background(), "shop", "shop.example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chains := result["chains"].([]map[string]interface{})
+	if len(chains) != 2 || result["mismatches"] != 1 {
+		t.Fatalf("Expected 2 chains with 1 mismatch, got %v", result)
+	}
+	if problems := chains[0]["problems"].([]string); len(problems) != 1 || !strings.Contains(problems[0], "has no port 8080 (ports: 80/http)") {
+		t.Errorf("Expected the api backend port to be reported, got %v", problems)
+	}
+	web := chains[1]
+	if len(web["problems"].([]string)) != 0 || web["readyPods"] != 1 || web["containerPorts"].([]string)[0] != "nginx:8000" {
+		t.Errorf("Expected the web chain to resolve to nginx:8000, got %v", web)
+	}
+}
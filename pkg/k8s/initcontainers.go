@@ -0,0 +1,430 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxInitEvents bounds the events reported per pod by DiagnoseInitContainers.
+const maxInitEvents = 10
+
+// DiagnoseInitContainers analyzes pods stuck initializing: the named pod, or
+// the pods of the namespace matching labelSelector whose init containers have
+// not all completed. For each pod it reports the kubectl-style init status,
+// the state of every init container, and the failing one: the first init
+// container in order that has not completed, or, for native sidecars, not
+// started. For the failing container it reports its exit codes, the tail of
+// its logs (of the previous attempt while it waits to restart), and whether
+// the ConfigMaps, Secrets and PersistentVolumeClaims it references through
+// env, envFrom and mounted volumes exist and hold the referenced keys.
+// Secret values are never read into the result.
+// Returns a map with the "pods" stuck initializing, or an error.
+func (c *Client) DiagnoseInitContainers(ctx context.Context, namespace, podName, labelSelector string, tailLines int64) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	var pods []corev1.Pod
+	if podName != "" {
+		pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod: %w", err)
+		}
+		pods = append(pods, *pod)
+	} else {
+		list, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		for _, pod := range list.Items {
+			if _, stuck := failingInitContainer(&pod); stuck {
+				pods = append(pods, pod)
+			}
+		}
+	}
+
+	var events []map[string]interface{}
+	eventsErr := ""
+	if len(pods) > 0 {
+		var err error
+		if events, err = c.listNormalizedEvents(ctx, namespace, metav1.ListOptions{}); err != nil {
+			eventsErr = err.Error()
+		}
+	}
+
+	sources := newPodConfigSources(ctx, c, namespace)
+	results := []map[string]interface{}{}
+	for i := range pods {
+		result := c.diagnoseInitPod(ctx, &pods[i], sources, tailLines)
+		if eventsErr != "" {
+			result["errors"] = append(result["errors"].([]string), "events: "+eventsErr)
+		} else {
+			result["events"] = podEvents(events, pods[i].Name)
+		}
+		results = append(results, result)
+	}
+
+	return map[string]interface{}{
+		"namespace": namespace,
+		"pods":      results,
+	}, nil
+}
+
+// diagnoseInitPod analyzes the init containers of one pod.
+func (c *Client) diagnoseInitPod(ctx context.Context, pod *corev1.Pod, sources *podConfigSources, tailLines int64) map[string]interface{} {
+	errs := []string{}
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, status := range pod.Status.InitContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	containers := []map[string]interface{}{}
+	for _, ctr := range pod.Spec.InitContainers {
+		containers = append(containers, summarizeInitContainer(ctr, statuses[ctr.Name]))
+	}
+
+	result := map[string]interface{}{
+		"pod":            pod.Name,
+		"phase":          string(pod.Status.Phase),
+		"status":         initStatus(pod),
+		"initContainers": containers,
+	}
+
+	index, stuck := failingInitContainer(pod)
+	if !stuck {
+		result["message"] = "All init containers have completed"
+		result["errors"] = errs
+		return result
+	}
+
+	ctr := pod.Spec.InitContainers[index]
+	status := statuses[ctr.Name]
+	failing := summarizeInitContainer(ctr, status)
+	failing["image"] = ctr.Image
+	if len(ctr.Command) > 0 || len(ctr.Args) > 0 {
+		failing["command"] = append(append([]string{}, ctr.Command...), ctr.Args...)
+	}
+
+	// While a failed init container waits to restart, its output is that of
+	// the previous attempt
+	previous := status.State.Waiting != nil && status.LastTerminationState.Terminated != nil
+	if status.State.Running != nil || status.State.Terminated != nil || previous {
+		logs, err := c.initContainerLogs(ctx, pod, ctr.Name, previous, tailLines)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("logs: %v", err))
+		} else {
+			failing["logs"] = logs
+			failing["logsFromPreviousAttempt"] = previous
+		}
+	}
+
+	references := containerReferences(pod, ctr, sources)
+	missing := []map[string]interface{}{}
+	for _, ref := range references {
+		if problem, _ := ref["problem"].(string); problem != "" && !ref["optional"].(bool) {
+			missing = append(missing, ref)
+		}
+	}
+	failing["references"] = references
+
+	result["failing"] = failing
+	result["missingReferences"] = missing
+	result["hint"] = initHint(ctr.Name, status, missing)
+	result["errors"] = errs
+	return result
+}
+
+// failingInitContainer returns the index of the first init container of a
+// pending pod that has not completed, or for native sidecars, not started.
+// It reports false if the pod is not stuck initializing.
+func failingInitContainer(pod *corev1.Pod) (int, bool) {
+	if pod.Status.Phase != corev1.PodPending || len(pod.Spec.InitContainers) == 0 {
+		return 0, false
+	}
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, status := range pod.Status.InitContainerStatuses {
+		statuses[status.Name] = status
+	}
+	for i, ctr := range pod.Spec.InitContainers {
+		status, ok := statuses[ctr.Name]
+		if !ok {
+			return i, true
+		}
+		if isNativeSidecar(ctr) {
+			if status.Started == nil || !*status.Started {
+				return i, true
+			}
+			continue
+		}
+		if status.State.Terminated == nil || status.State.Terminated.ExitCode != 0 {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// isNativeSidecar reports whether an init container is a native sidecar,
+// which keeps running alongside the main containers.
+func isNativeSidecar(ctr corev1.Container) bool {
+	return ctr.RestartPolicy != nil && *ctr.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
+
+// initStatus returns the status kubectl prints for a pod that is
+// initializing, e.g. "Init:1/3", "Init:CrashLoopBackOff" or "Init:Error".
+func initStatus(pod *corev1.Pod) string {
+	index, stuck := failingInitContainer(pod)
+	if !stuck {
+		return string(pod.Status.Phase)
+	}
+	name := pod.Spec.InitContainers[index].Name
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name != name {
+			continue
+		}
+		switch {
+		case status.State.Terminated != nil && status.State.Terminated.Reason != "":
+			return "Init:" + status.State.Terminated.Reason
+		case status.State.Terminated != nil && status.State.Terminated.Signal != 0:
+			return fmt.Sprintf("Init:Signal:%d", status.State.Terminated.Signal)
+		case status.State.Terminated != nil:
+			return fmt.Sprintf("Init:ExitCode:%d", status.State.Terminated.ExitCode)
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing":
+			return "Init:" + status.State.Waiting.Reason
+		}
+	}
+	return fmt.Sprintf("Init:%d/%d", index, len(pod.Spec.InitContainers))
+}
+
+// summarizeInitContainer describes the state of an init container.
+func summarizeInitContainer(ctr corev1.Container, status corev1.ContainerStatus) map[string]interface{} {
+	summary := map[string]interface{}{
+		"name":         ctr.Name,
+		"restartCount": status.RestartCount,
+		"state":        "pending",
+	}
+	if isNativeSidecar(ctr) {
+		summary["sidecar"] = true
+	}
+	switch {
+	case status.State.Running != nil:
+		summary["state"] = "running"
+	case status.State.Terminated != nil:
+		summary["state"] = "terminated"
+		summary["exitCode"] = status.State.Terminated.ExitCode
+		summary["reason"] = status.State.Terminated.Reason
+		if status.State.Terminated.Message != "" {
+			summary["message"] = status.State.Terminated.Message
+		}
+	case status.State.Waiting != nil:
+		summary["state"] = "waiting"
+		summary["reason"] = status.State.Waiting.Reason
+		if status.State.Waiting.Message != "" {
+			summary["message"] = status.State.Waiting.Message
+		}
+	}
+	if last := status.LastTerminationState.Terminated; last != nil {
+		summary["lastExitCode"] = last.ExitCode
+		summary["lastReason"] = last.Reason
+		if !last.FinishedAt.IsZero() {
+			summary["lastFinishedAt"] = last.FinishedAt.Time
+		}
+	}
+	return summary
+}
+
+// initContainerLogs returns the tail of an init container's logs.
+func (c *Client) initContainerLogs(ctx context.Context, pod *corev1.Pod, container string, previous bool, tailLines int64) (string, error) {
+	limitBytes := int64(maxDiagnosticLogBytes)
+	options := &corev1.PodLogOptions{Container: container, Previous: previous, LimitBytes: &limitBytes}
+	if tailLines > 0 {
+		options.TailLines = &tailLines
+	}
+	stream, err := c.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, options).Stream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// containerReferences lists the ConfigMaps, Secrets and PersistentVolumeClaims
+// a container references through env, envFrom and its mounted volumes, with
+// whether each exists and holds the referenced key. A reference that cannot
+// be satisfied carries a "problem".
+func containerReferences(pod *corev1.Pod, ctr corev1.Container, sources *podConfigSources) []map[string]interface{} {
+	var references []map[string]interface{}
+	add := func(kind, name, key, usedBy string, optional *bool) {
+		ref := map[string]interface{}{"kind": kind, "name": name, "usedBy": usedBy, "optional": optional != nil && *optional}
+		if key != "" {
+			ref["key"] = key
+		}
+		var keys map[string]bool
+		var err error
+		switch kind {
+		case "ConfigMap":
+			var cm *corev1.ConfigMap
+			if cm, err = sources.configMap(name); err == nil {
+				keys = map[string]bool{}
+				for k := range cm.Data {
+					keys[k] = true
+				}
+				for k := range cm.BinaryData {
+					keys[k] = true
+				}
+			}
+		case "Secret":
+			var secret *corev1.Secret
+			if secret, err = sources.secret(name); err == nil {
+				keys = map[string]bool{}
+				for k := range secret.Data {
+					keys[k] = true
+				}
+				for k := range secret.StringData {
+					keys[k] = true
+				}
+			}
+		case "PersistentVolumeClaim":
+			var claim *corev1.PersistentVolumeClaim
+			if claim, err = sources.client.clientset.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(sources.ctx, name, metav1.GetOptions{}); err == nil && claim.Status.Phase != corev1.ClaimBound {
+				ref["problem"] = fmt.Sprintf("claim is %s, not Bound", claim.Status.Phase)
+			}
+		}
+		switch {
+		case apierrors.IsNotFound(err):
+			ref["exists"] = false
+			ref["problem"] = kind + " not found"
+		case err != nil:
+			ref["error"] = err.Error()
+		default:
+			ref["exists"] = true
+			if key != "" && keys != nil && !keys[key] {
+				ref["problem"] = fmt.Sprintf("key %s not found", key)
+			}
+		}
+		references = append(references, ref)
+	}
+
+	for _, env := range ctr.Env {
+		if env.ValueFrom == nil {
+			continue
+		}
+		if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+			add("ConfigMap", ref.Name, ref.Key, "env "+env.Name, ref.Optional)
+		}
+		if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+			add("Secret", ref.Name, ref.Key, "env "+env.Name, ref.Optional)
+		}
+	}
+	for _, envFrom := range ctr.EnvFrom {
+		if ref := envFrom.ConfigMapRef; ref != nil {
+			add("ConfigMap", ref.Name, "", "envFrom", ref.Optional)
+		}
+		if ref := envFrom.SecretRef; ref != nil {
+			add("Secret", ref.Name, "", "envFrom", ref.Optional)
+		}
+	}
+
+	mounted := map[string]bool{}
+	for _, mount := range ctr.VolumeMounts {
+		mounted[mount.Name] = true
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if !mounted[volume.Name] {
+			continue
+		}
+		usedBy := "volume " + volume.Name
+		switch {
+		case volume.ConfigMap != nil:
+			for _, item := range itemsOrNone(volume.ConfigMap.Items) {
+				add("ConfigMap", volume.ConfigMap.Name, item, usedBy, volume.ConfigMap.Optional)
+			}
+		case volume.Secret != nil:
+			for _, item := range itemsOrNone(volume.Secret.Items) {
+				add("Secret", volume.Secret.SecretName, item, usedBy, volume.Secret.Optional)
+			}
+		case volume.PersistentVolumeClaim != nil:
+			add("PersistentVolumeClaim", volume.PersistentVolumeClaim.ClaimName, "", usedBy, nil)
+		case volume.Projected != nil:
+			for _, projection := range volume.Projected.Sources {
+				if projection.ConfigMap != nil {
+					for _, item := range itemsOrNone(projection.ConfigMap.Items) {
+						add("ConfigMap", projection.ConfigMap.Name, item, usedBy, projection.ConfigMap.Optional)
+					}
+				}
+				if projection.Secret != nil {
+					for _, item := range itemsOrNone(projection.Secret.Items) {
+						add("Secret", projection.Secret.Name, item, usedBy, projection.Secret.Optional)
+					}
+				}
+			}
+		}
+	}
+	return references
+}
+
+// itemsOrNone returns the keys a volume projects, or a single empty key when
+// it projects the whole object.
+func itemsOrNone(items []corev1.KeyToPath) []string {
+	if len(items) == 0 {
+		return []string{""}
+	}
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+// podEvents returns the most recent events of the named pod, newest first.
+func podEvents(events []map[string]interface{}, podName string) []map[string]interface{} {
+	var matched []map[string]interface{}
+	for _, event := range events {
+		involved, _ := event["involvedObject"].(map[string]interface{})
+		if involved["kind"] == "Pod" && involved["name"] == podName {
+			matched = append(matched, event)
+		}
+	}
+	return sortFilterLimitEvents(matched, maxInitEvents, "lastTime", "")
+}
+
+// initHint suggests what to look at for a failing init container.
+func initHint(name string, status corev1.ContainerStatus, missing []map[string]interface{}) string {
+	if len(missing) > 0 {
+		problems := make([]string, 0, len(missing))
+		for _, ref := range missing {
+			problems = append(problems, fmt.Sprintf("%s %s (%s: %s)", ref["kind"], ref["name"], ref["usedBy"], ref["problem"]))
+		}
+		sort.Strings(problems)
+		return fmt.Sprintf("Init container %s references objects that are missing or unusable: %s. Create or fix them; the kubelet retries automatically.",
+			name, strings.Join(problems, "; "))
+	}
+	terminated := status.State.Terminated
+	if terminated == nil {
+		terminated = status.LastTerminationState.Terminated
+	}
+	switch {
+	case status.State.Waiting != nil && (status.State.Waiting.Reason == "ErrImagePull" || status.State.Waiting.Reason == "ImagePullBackOff"):
+		return fmt.Sprintf("The image of init container %s cannot be pulled; check the image name, tag and pull secrets.", name)
+	case status.State.Waiting != nil && status.State.Waiting.Reason == "CreateContainerConfigError":
+		return fmt.Sprintf("Init container %s cannot be created from its configuration: %s", name, status.State.Waiting.Message)
+	case terminated != nil && terminated.Reason == "OOMKilled":
+		return fmt.Sprintf("Init container %s was killed for exceeding its memory limit; raise the limit.", name)
+	case terminated != nil && terminated.ExitCode != 0:
+		return fmt.Sprintf("Init container %s exits with code %d; its logs show why. Init containers often wait for a dependency such as a database or Service that is not reachable.",
+			name, terminated.ExitCode)
+	case status.State.Running != nil:
+		return fmt.Sprintf("Init container %s is still running; if it never finishes, its logs show what it waits for.", name)
+	}
+	return fmt.Sprintf("Init container %s has not started; the pod's events show why.", name)
+}
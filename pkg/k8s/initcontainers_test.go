@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestInitStatus tests finding the failing init container and the status kubectl prints for it
+func TestInitStatus(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	started := true
+	completed := corev1.ContainerStatus{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}}
+	pod := func(statuses ...corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{InitContainers: []corev1.Container{
+				{Name: "migrate"},
+				{Name: "proxy", RestartPolicy: &always},
+				{Name: "wait-for-db"},
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: statuses},
+		}
+	}
+	proxy := corev1.ContainerStatus{Name: "proxy", Started: &started, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+
+	tests := []struct {
+		name   string
+		pod    *corev1.Pod
+		index  int
+		stuck  bool
+		status string
+	}{
+		{"not started", pod(), 0, true, "Init:0/3"},
+		{"crash looping", pod(corev1.ContainerStatus{Name: "migrate",
+			State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}},
+		}), 0, true, "Init:CrashLoopBackOff"},
+		{"failed", pod(corev1.ContainerStatus{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2, Reason: "Error"}}}), 0, true, "Init:Error"},
+		{"sidecar not started", pod(completed, corev1.ContainerStatus{Name: "proxy"}), 1, true, "Init:1/3"},
+		{"waiting for dependency", pod(completed, proxy, corev1.ContainerStatus{Name: "wait-for-db", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}), 2, true, "Init:2/3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, stuck := failingInitContainer(tt.pod)
+			if index != tt.index || stuck != tt.stuck {
+				t.Errorf("failingInitContainer() = %d, %v, want %d, %v", index, stuck, tt.index, tt.stuck)
+			}
+			if got := initStatus(tt.pod); got != tt.status {
+				t.Errorf("initStatus() = %q, want %q", got, tt.status)
+			}
+		})
+	}
+
+	done := pod(completed, proxy, corev1.ContainerStatus{Name: "wait-for-db", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}})
+	if _, stuck := failingInitContainer(done); stuck {
+		t.Error("Expected a pod whose init containers completed not to be stuck")
+	}
+}
+
+// TestInitHint tests the suggestions for failing init containers
+func TestInitHint(t *testing.T) {
+	missing := []map[string]interface{}{{"kind": "Secret", "name": "db", "usedBy": "env PASSWORD", "problem": "Secret not found"}}
+	if hint := initHint("migrate", corev1.ContainerStatus{}, missing); !strings.Contains(hint, "Secret db (env PASSWORD: Secret not found)") {
+		t.Errorf("Expected the missing Secret to be named, got %q", hint)
+	}
+	crashed := corev1.ContainerStatus{
+		State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 3}},
+	}
+	if hint := initHint("migrate", crashed, nil); !strings.Contains(hint, "exits with code 3") {
+		t.Errorf("Expected the exit code of the last attempt, got %q", hint)
+	}
+}
@@ -0,0 +1,192 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// nodeRoleLabelPrefix prefixes the labels that mark the roles of a node, e.g.
+// node-role.kubernetes.io/control-plane.
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// ClusterInventory summarizes a cluster for orientation: the server version,
+// nodes counted by role, instance type, zone and readiness, the number of
+// namespaces, workloads counted by kind with pods counted by phase, and the
+// CustomResourceDefinitions installed, grouped by API group. Sections that
+// cannot be read, e.g. for lack of permission, are reported in "errors" and
+// left out.
+// Returns a map with "version", "nodes", "namespaces", "workloads", "crds" and
+// "errors", or an error.
+func (c *Client) ClusterInventory(ctx context.Context) (map[string]interface{}, error) {
+	inventory := map[string]interface{}{}
+	errors := []string{}
+
+	if version, err := c.discoveryClient.ServerVersion(); err != nil {
+		errors = append(errors, fmt.Sprintf("version: %v", err))
+	} else {
+		inventory["version"] = map[string]interface{}{
+			"gitVersion": version.GitVersion,
+			"platform":   version.Platform,
+		}
+	}
+
+	if nodes, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{}); err != nil {
+		errors = append(errors, fmt.Sprintf("nodes: %v", err))
+	} else {
+		inventory["nodes"] = summarizeNodeInventory(nodes.Items)
+	}
+
+	if namespaces, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err != nil {
+		errors = append(errors, fmt.Sprintf("namespaces: %v", err))
+	} else {
+		inventory["namespaces"] = len(namespaces.Items)
+	}
+
+	workloads := map[string]interface{}{}
+	counters := []struct {
+		kind  string
+		count func() (int, error)
+	}{
+		{"Deployment", func() (int, error) {
+			list, err := c.clientset.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"StatefulSet", func() (int, error) {
+			list, err := c.clientset.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"DaemonSet", func() (int, error) {
+			list, err := c.clientset.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"Job", func() (int, error) {
+			list, err := c.clientset.BatchV1().Jobs("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"CronJob", func() (int, error) {
+			list, err := c.clientset.BatchV1().CronJobs("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+	}
+	for _, counter := range counters {
+		count, err := counter.count()
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", counter.kind, err))
+			continue
+		}
+		workloads[counter.kind] = count
+	}
+	if pods, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{}); err != nil {
+		errors = append(errors, fmt.Sprintf("Pod: %v", err))
+	} else {
+		byPhase := map[string]int{}
+		for _, pod := range pods.Items {
+			byPhase[string(pod.Status.Phase)]++
+		}
+		workloads["Pod"] = map[string]interface{}{"total": len(pods.Items), "byPhase": byPhase}
+	}
+	inventory["workloads"] = workloads
+
+	if crds, err := c.dynamicClient.Resource(crdGVR).List(ctx, metav1.ListOptions{}); err != nil {
+		errors = append(errors, fmt.Sprintf("crds: %v", err))
+	} else {
+		inventory["crds"] = summarizeCRDInventory(crds.Items)
+	}
+
+	inventory["errors"] = errors
+	return inventory, nil
+}
+
+// summarizeNodeInventory counts nodes in total, by readiness, by role, by
+// instance type and by zone. Nodes without a role label are counted as
+// "<none>", and those without an instance type or zone label as "unknown".
+func summarizeNodeInventory(nodes []corev1.Node) map[string]interface{} {
+	byRole := map[string]int{}
+	byInstanceType := map[string]int{}
+	byZone := map[string]int{}
+	ready := 0
+	for i := range nodes {
+		node := &nodes[i]
+		if nodeConditionTrue(node, corev1.NodeReady) {
+			ready++
+		}
+		for _, role := range nodeRoles(node) {
+			byRole[role]++
+		}
+		byInstanceType[firstLabel(node.Labels, "unknown", corev1.LabelInstanceTypeStable, corev1.LabelInstanceType)]++
+		byZone[firstLabel(node.Labels, "unknown", corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone)]++
+	}
+	return map[string]interface{}{
+		"total":          len(nodes),
+		"ready":          ready,
+		"byRole":         byRole,
+		"byInstanceType": byInstanceType,
+		"byZone":         byZone,
+	}
+}
+
+// nodeRoles returns the roles of a node from its node-role.kubernetes.io
+// labels, like the ROLES column of kubectl get nodes.
+func nodeRoles(node *corev1.Node) []string {
+	var roles []string
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, nodeRoleLabelPrefix); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return []string{"<none>"}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// firstLabel returns the value of the first of keys set in labels, or
+// fallback when none is.
+func firstLabel(labels map[string]string, fallback string, keys ...string) string {
+	for _, key := range keys {
+		if value := labels[key]; value != "" {
+			return value
+		}
+	}
+	return fallback
+}
+
+// summarizeCRDInventory counts CustomResourceDefinitions and lists the kinds
+// they define per API group.
+func summarizeCRDInventory(crds []unstructured.Unstructured) map[string]interface{} {
+	byGroup := map[string][]string{}
+	for _, crd := range crds {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		byGroup[group] = append(byGroup[group], kind)
+	}
+	for _, kinds := range byGroup {
+		sort.Strings(kinds)
+	}
+	return map[string]interface{}{
+		"total":   len(crds),
+		"byGroup": byGroup,
+	}
+}
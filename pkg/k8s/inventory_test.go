@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestSummarizeNodeInventory tests counting nodes by role, instance type, zone and readiness
+func TestSummarizeNodeInventory(t *testing.T) {
+	node := func(name string, labels map[string]string, ready corev1.ConditionStatus) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}}},
+		}
+	}
+	nodes := []corev1.Node{
+		node("cp-1", map[string]string{"node-role.kubernetes.io/control-plane": "", corev1.LabelTopologyZone: "eu-1a"}, corev1.ConditionTrue),
+		node("worker-1", map[string]string{corev1.LabelInstanceTypeStable: "m5.large", corev1.LabelFailureDomainBetaZone: "eu-1b"}, corev1.ConditionTrue),
+		node("worker-2", map[string]string{corev1.LabelInstanceTypeStable: "m5.large", corev1.LabelTopologyZone: "eu-1b"}, corev1.ConditionFalse),
+	}
+
+	summary := summarizeNodeInventory(nodes)
+	if summary["total"] != 3 || summary["ready"] != 2 {
+		t.Errorf("Unexpected totals %v", summary)
+	}
+	byRole := summary["byRole"].(map[string]int)
+	if byRole["control-plane"] != 1 || byRole["<none>"] != 2 {
+		t.Errorf("Unexpected roles %v", byRole)
+	}
+	if byType := summary["byInstanceType"].(map[string]int); byType["m5.large"] != 2 || byType["unknown"] != 1 {
+		t.Errorf("Unexpected instance types %v", byType)
+	}
+	if byZone := summary["byZone"].(map[string]int); byZone["eu-1b"] != 2 || byZone["eu-1a"] != 1 {
+		t.Errorf("Unexpected zones %v", byZone)
+	}
+}
+
+// TestSummarizeCRDInventory tests grouping CustomResourceDefinitions by API group
+func TestSummarizeCRDInventory(t *testing.T) {
+	crd := func(group, kind string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"group": group, "names": map[string]interface{}{"kind": kind}},
+		}}
+	}
+	summary := summarizeCRDInventory([]unstructured.Unstructured{
+		crd("cert-manager.io", "Issuer"), crd("cert-manager.io", "Certificate"), crd("keda.sh", "ScaledObject"),
+	})
+	byGroup := summary["byGroup"].(map[string][]string)
+	if summary["total"] != 3 || len(byGroup) != 2 || byGroup["cert-manager.io"][0] != "Certificate" {
+		t.Errorf("Unexpected CRD summary %v", summary)
+	}
+}
@@ -0,0 +1,375 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// istioRootNamespace is the namespace holding mesh-wide Istio configuration.
+const istioRootNamespace = "istio-system"
+
+// istioGatewayGVR is resolved explicitly because the "Gateway" kind is also
+// served by the Kubernetes Gateway API.
+var istioGatewayGVR = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}
+
+// GetIstioTrafficConfig summarizes the Istio VirtualServices, DestinationRules,
+// Gateways and PeerAuthentications affecting a host or workload.
+// If workload is set, the hosts of the Services selecting the workload's pods
+// are added to the lookup. Conflicting definitions (several VirtualServices or
+// DestinationRules claiming the same host) are reported under "conflicts".
+// Returns the summary as a map, or an error if Istio is not installed.
+func (c *Client) GetIstioTrafficConfig(ctx context.Context, namespace, host, workload string) (map[string]interface{}, error) {
+	if host == "" && workload == "" {
+		return nil, fmt.Errorf("either host or workload is required")
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	var hosts []string
+	if host != "" {
+		hosts = append(hosts, istioFQDN(host, namespace))
+	}
+
+	var workloadLabels map[string]string
+	if workload != "" {
+		podLabels, err := c.workloadPodLabels(ctx, namespace, workload)
+		if err != nil {
+			return nil, err
+		}
+		workloadLabels = podLabels
+
+		services, err := c.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		for _, svc := range services.Items {
+			if len(svc.Spec.Selector) == 0 {
+				continue
+			}
+			if labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(podLabels)) {
+				hosts = append(hosts, istioFQDN(svc.Name, namespace))
+			}
+		}
+	}
+
+	virtualServices, err := c.ListResources(ctx, "VirtualService", "", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VirtualServices (is Istio installed?): %w", err)
+	}
+	destinationRules, err := c.ListResources(ctx, "DestinationRule", "", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DestinationRules: %w", err)
+	}
+
+	var conflicts []string
+	var vsSummaries []map[string]interface{}
+	gatewayRefs := map[string]bool{}
+	hostBindings := map[string][]string{}
+
+	for _, vs := range virtualServices {
+		obj := unstructured.Unstructured{Object: vs}
+		vsHosts, _, _ := unstructured.NestedStringSlice(vs, "spec", "hosts")
+		matched := matchIstioHosts(vsHosts, obj.GetNamespace(), hosts)
+		if len(matched) == 0 {
+			continue
+		}
+
+		gateways, _, _ := unstructured.NestedStringSlice(vs, "spec", "gateways")
+		bindings := gateways
+		if len(bindings) == 0 {
+			bindings = []string{"mesh"}
+		}
+		for _, gw := range bindings {
+			if gw != "mesh" {
+				gatewayRefs[qualifyIstioRef(gw, obj.GetNamespace())] = true
+			}
+			for _, h := range matched {
+				key := gw + "|" + h
+				hostBindings[key] = append(hostBindings[key], obj.GetNamespace()+"/"+obj.GetName())
+			}
+		}
+
+		vsSummaries = append(vsSummaries, map[string]interface{}{
+			"name":      obj.GetName(),
+			"namespace": obj.GetNamespace(),
+			"hosts":     vsHosts,
+			"gateways":  gateways,
+			"http":      summarizeIstioRoutes(vs, "http"),
+			"tcp":       summarizeIstioRoutes(vs, "tcp"),
+			"tls":       summarizeIstioRoutes(vs, "tls"),
+		})
+	}
+
+	bindingKeys := make([]string, 0, len(hostBindings))
+	for key := range hostBindings {
+		bindingKeys = append(bindingKeys, key)
+	}
+	sort.Strings(bindingKeys)
+	for _, key := range bindingKeys {
+		if owners := hostBindings[key]; len(owners) > 1 {
+			parts := strings.SplitN(key, "|", 2)
+			conflicts = append(conflicts, fmt.Sprintf("host %s is routed by multiple VirtualServices on %s: %s",
+				parts[1], parts[0], strings.Join(owners, ", ")))
+		}
+	}
+
+	var drSummaries []map[string]interface{}
+	drOwners := map[string][]string{}
+	for _, dr := range destinationRules {
+		obj := unstructured.Unstructured{Object: dr}
+		drHost, _, _ := unstructured.NestedString(dr, "spec", "host")
+		matched := matchIstioHosts([]string{drHost}, obj.GetNamespace(), hosts)
+		if len(matched) == 0 {
+			continue
+		}
+		for _, h := range matched {
+			drOwners[h] = append(drOwners[h], obj.GetNamespace()+"/"+obj.GetName())
+		}
+
+		tlsMode, _, _ := unstructured.NestedString(dr, "spec", "trafficPolicy", "tls", "mode")
+		var subsets []string
+		rawSubsets, _, _ := unstructured.NestedSlice(dr, "spec", "subsets")
+		for _, s := range rawSubsets {
+			if subset, ok := s.(map[string]interface{}); ok {
+				if name, ok := subset["name"].(string); ok {
+					subsets = append(subsets, name)
+				}
+			}
+		}
+		drSummaries = append(drSummaries, map[string]interface{}{
+			"name":      obj.GetName(),
+			"namespace": obj.GetNamespace(),
+			"host":      drHost,
+			"tlsMode":   tlsMode,
+			"subsets":   subsets,
+		})
+	}
+	drHosts := make([]string, 0, len(drOwners))
+	for h := range drOwners {
+		drHosts = append(drHosts, h)
+	}
+	sort.Strings(drHosts)
+	for _, h := range drHosts {
+		if owners := drOwners[h]; len(owners) > 1 {
+			sort.Strings(owners)
+			conflicts = append(conflicts, fmt.Sprintf("host %s has multiple DestinationRules: %s", h, strings.Join(owners, ", ")))
+		}
+	}
+
+	refs := make([]string, 0, len(gatewayRefs))
+	for ref := range gatewayRefs {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	var gwSummaries []map[string]interface{}
+	for _, ref := range refs {
+		parts := strings.SplitN(ref, "/", 2)
+		gwObj, err := c.dynamicClient.Resource(istioGatewayGVR).Namespace(parts[0]).Get(ctx, parts[1], metav1.GetOptions{})
+		if err != nil {
+			conflicts = append(conflicts, fmt.Sprintf("VirtualService references gateway %s which could not be retrieved: %v", ref, err))
+			continue
+		}
+		gw := gwObj.UnstructuredContent()
+		var servers []map[string]interface{}
+		rawServers, _, _ := unstructured.NestedSlice(gw, "spec", "servers")
+		for _, s := range rawServers {
+			server, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			port, _, _ := unstructured.NestedFieldNoCopy(server, "port", "number")
+			protocol, _, _ := unstructured.NestedString(server, "port", "protocol")
+			tlsMode, _, _ := unstructured.NestedString(server, "tls", "mode")
+			serverHosts, _, _ := unstructured.NestedStringSlice(server, "hosts")
+			servers = append(servers, map[string]interface{}{
+				"port":     port,
+				"protocol": protocol,
+				"hosts":    serverHosts,
+				"tlsMode":  tlsMode,
+			})
+		}
+		selector, _, _ := unstructured.NestedStringMap(gw, "spec", "selector")
+		gwSummaries = append(gwSummaries, map[string]interface{}{
+			"name":      parts[1],
+			"namespace": parts[0],
+			"selector":  selector,
+			"servers":   servers,
+		})
+	}
+
+	mtls := c.effectivePeerAuthentication(ctx, namespace, workloadLabels)
+
+	return map[string]interface{}{
+		"namespace":          namespace,
+		"hosts":              hosts,
+		"virtualServices":    vsSummaries,
+		"destinationRules":   drSummaries,
+		"gateways":           gwSummaries,
+		"peerAuthentication": mtls,
+		"conflicts":          conflicts,
+	}, nil
+}
+
+// effectivePeerAuthentication resolves the mTLS mode applying to a workload.
+// Workload-specific policies take precedence over namespace-wide policies,
+// which take precedence over the mesh-wide policy in the root namespace.
+func (c *Client) effectivePeerAuthentication(ctx context.Context, namespace string, workloadLabels map[string]string) map[string]interface{} {
+	policies, err := c.ListResources(ctx, "PeerAuthentication", "", "", "")
+	if err != nil {
+		return map[string]interface{}{"mode": "UNSET", "error": err.Error()}
+	}
+
+	var meshPolicy, namespacePolicy, workloadPolicy map[string]interface{}
+	for _, pa := range policies {
+		obj := unstructured.Unstructured{Object: pa}
+		selector, hasSelector, _ := unstructured.NestedStringMap(pa, "spec", "selector", "matchLabels")
+		switch {
+		case obj.GetNamespace() == istioRootNamespace && !hasSelector:
+			meshPolicy = pa
+		case obj.GetNamespace() == namespace && !hasSelector:
+			namespacePolicy = pa
+		case obj.GetNamespace() == namespace && workloadLabels != nil &&
+			labels.SelectorFromSet(selector).Matches(labels.Set(workloadLabels)):
+			workloadPolicy = pa
+		}
+	}
+
+	result := map[string]interface{}{"mode": "PERMISSIVE", "source": "default"}
+	for _, candidate := range []struct {
+		scope  string
+		policy map[string]interface{}
+	}{
+		{"mesh", meshPolicy},
+		{"namespace", namespacePolicy},
+		{"workload", workloadPolicy},
+	} {
+		if candidate.policy == nil {
+			continue
+		}
+		obj := unstructured.Unstructured{Object: candidate.policy}
+		mode, found, _ := unstructured.NestedString(candidate.policy, "spec", "mtls", "mode")
+		if !found || mode == "" || mode == "UNSET" {
+			continue
+		}
+		portLevel, _, _ := unstructured.NestedMap(candidate.policy, "spec", "portLevelMtls")
+		result = map[string]interface{}{
+			"mode":          mode,
+			"source":        candidate.scope,
+			"policy":        obj.GetNamespace() + "/" + obj.GetName(),
+			"portLevelMtls": portLevel,
+		}
+	}
+	return result
+}
+
+// workloadPodLabels returns the pod template labels of a Deployment, StatefulSet
+// or DaemonSet with the given name.
+func (c *Client) workloadPodLabels(ctx context.Context, namespace, name string) (map[string]string, error) {
+	if deploy, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+		return deploy.Spec.Template.Labels, nil
+	}
+	if sts, err := c.clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+		return sts.Spec.Template.Labels, nil
+	}
+	if ds, err := c.clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+		return ds.Spec.Template.Labels, nil
+	}
+	return nil, fmt.Errorf("workload %s/%s not found as Deployment, StatefulSet or DaemonSet", namespace, name)
+}
+
+// summarizeIstioRoutes flattens the routes of a VirtualService section
+// (http, tcp or tls) into their match conditions and weighted destinations.
+func summarizeIstioRoutes(vs map[string]interface{}, section string) []map[string]interface{} {
+	rawRoutes, _, _ := unstructured.NestedSlice(vs, "spec", section)
+	var routes []map[string]interface{}
+	for _, r := range rawRoutes {
+		route, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var destinations []map[string]interface{}
+		rawDest, _, _ := unstructured.NestedSlice(route, "route")
+		for _, d := range rawDest {
+			dest, ok := d.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			destHost, _, _ := unstructured.NestedString(dest, "destination", "host")
+			subset, _, _ := unstructured.NestedString(dest, "destination", "subset")
+			port, _, _ := unstructured.NestedFieldNoCopy(dest, "destination", "port", "number")
+			destinations = append(destinations, map[string]interface{}{
+				"host":   destHost,
+				"subset": subset,
+				"port":   port,
+				"weight": dest["weight"],
+			})
+		}
+		summary := map[string]interface{}{
+			"match":        route["match"],
+			"destinations": destinations,
+		}
+		if name, ok := route["name"]; ok {
+			summary["name"] = name
+		}
+		if redirect, ok := route["redirect"]; ok {
+			summary["redirect"] = redirect
+		}
+		routes = append(routes, summary)
+	}
+	return routes
+}
+
+// matchIstioHosts returns the target hosts matched by any of the given Istio
+// host patterns, interpreting short names relative to the owning namespace.
+func matchIstioHosts(patterns []string, namespace string, targets []string) []string {
+	var matched []string
+	for _, target := range targets {
+		for _, pattern := range patterns {
+			if istioHostMatches(istioFQDN(pattern, namespace), target) {
+				matched = append(matched, target)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// istioHostMatches reports whether host matches pattern, honouring a leading
+// "*" wildcard as Istio does.
+func istioHostMatches(pattern, host string) bool {
+	if pattern == "*" || pattern == host {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return false
+}
+
+// istioFQDN expands a short service name such as "reviews" to its
+// fully qualified cluster-local form. Wildcards and external hosts are kept as is.
+func istioFQDN(host, namespace string) string {
+	if strings.HasPrefix(host, "*") || strings.HasSuffix(host, ".svc.cluster.local") {
+		return host
+	}
+	if !strings.Contains(host, ".") {
+		return fmt.Sprintf("%s.%s.svc.cluster.local", host, namespace)
+	}
+	return host
+}
+
+// qualifyIstioRef turns a gateway reference into "namespace/name" form.
+func qualifyIstioRef(ref, namespace string) string {
+	if strings.Contains(ref, "/") {
+		return ref
+	}
+	return namespace + "/" + ref
+}
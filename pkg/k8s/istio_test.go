@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestGetIstioTrafficConfigOrder tests that DestinationRule conflicts and gateways are reported in a stable order
+func TestGetIstioTrafficConfigOrder(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case r.URL.Path == "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[{"name":"networking.istio.io","versions":[{"groupVersion":"networking.istio.io/v1beta1","version":"v1beta1"}],"preferredVersion":{"groupVersion":"networking.istio.io/v1beta1","version":"v1beta1"}}]}`))
+		case r.URL.Path == "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[{"name":"services","namespaced":true,"kind":"Service","verbs":["list"]}]}`))
+		case r.URL.Path == "/apis/networking.istio.io/v1beta1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"networking.istio.io/v1beta1","resources":[` +
+				`{"name":"virtualservices","namespaced":true,"kind":"VirtualService","verbs":["list"]},` +
+				`{"name":"destinationrules","namespaced":true,"kind":"DestinationRule","verbs":["list"]},` +
+				`{"name":"gateways","namespaced":true,"kind":"Gateway","verbs":["get"]}]}`))
+		case r.URL.Path == "/apis/apps/v1/namespaces/default/deployments/app":
+			w.Write([]byte(`{"kind":"Deployment","apiVersion":"apps/v1","metadata":{"name":"app"},"spec":{"template":{"metadata":{"labels":{"app":"web"}}}}}`))
+		case r.URL.Path == "/api/v1/namespaces/default/services":
+			w.Write([]byte(`{"kind":"ServiceList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"web"},"spec":{"selector":{"app":"web"}}},` +
+				`{"metadata":{"name":"api"},"spec":{"selector":{"app":"web"}}},` +
+				`{"metadata":{"name":"db"},"spec":{"selector":{"app":"db"}}}]}`))
+		case r.URL.Path == "/apis/networking.istio.io/v1beta1/virtualservices":
+			w.Write([]byte(`{"kind":"VirtualServiceList","apiVersion":"networking.istio.io/v1beta1","items":[` +
+				`{"apiVersion":"networking.istio.io/v1beta1","kind":"VirtualService","metadata":{"name":"web","namespace":"default"},` +
+				`"spec":{"hosts":["web"],"gateways":["z-gw","istio-system/a-gw","m-gw"]}}]}`))
+		case r.URL.Path == "/apis/networking.istio.io/v1beta1/destinationrules":
+			w.Write([]byte(`{"kind":"DestinationRuleList","apiVersion":"networking.istio.io/v1beta1","items":[` +
+				`{"apiVersion":"networking.istio.io/v1beta1","kind":"DestinationRule","metadata":{"name":"b","namespace":"default"},"spec":{"host":"*.default.svc.cluster.local"}},` +
+				`{"apiVersion":"networking.istio.io/v1beta1","kind":"DestinationRule","metadata":{"name":"a","namespace":"default"},"spec":{"host":"*.default.svc.cluster.local"}}]}`))
+		case strings.HasPrefix(r.URL.Path, "/apis/networking.istio.io/v1beta1/namespaces/"):
+			parts := strings.Split(r.URL.Path, "/")
+			fmt.Fprintf(w, `{"apiVersion":"networking.istio.io/v1beta1","kind":"Gateway","metadata":{"name":%q,"namespace":%q}}`, parts[7], parts[5])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	config, err := client.GetIstioTrafficConfig(context.Background(), "default", "", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflicts := config["conflicts"].([]string)
+	want := []string{
+		"host api.default.svc.cluster.local has multiple DestinationRules: default/a, default/b",
+		"host web.default.svc.cluster.local has multiple DestinationRules: default/a, default/b",
+	}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("Expected conflicts sorted by host, got %v", conflicts)
+	}
+	var gateways []string
+	for _, gw := range config["gateways"].([]map[string]interface{}) {
+		gateways = append(gateways, gw["namespace"].(string)+"/"+gw["name"].(string))
+	}
+	if want := []string{"default/m-gw", "default/z-gw", "istio-system/a-gw"}; !reflect.DeepEqual(gateways, want) {
+		t.Errorf("Expected gateways sorted by reference, got %v", gateways)
+	}
+}
@@ -0,0 +1,172 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// KEDA annotations used to pause scaling.
+const (
+	kedaPausedAnnotation         = "autoscaling.keda.sh/paused"
+	kedaPausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+)
+
+// GetKedaScalers reports KEDA ScaledObjects and ScaledJobs with their triggers,
+// paused state and conditions. For ScaledObjects it also includes the current
+// values served by the external metrics API and the status of the driven HPA.
+// If name is set, only objects with that name are returned.
+// Returns a map with "scaledObjects" and "scaledJobs", or an error if KEDA is not installed.
+func (c *Client) GetKedaScalers(ctx context.Context, namespace, name string) (map[string]interface{}, error) {
+	scaledObjects, err := c.ListResources(ctx, "ScaledObject", namespace, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ScaledObjects (is KEDA installed?): %w", err)
+	}
+
+	var objectSummaries []map[string]interface{}
+	for _, so := range scaledObjects {
+		obj := unstructured.Unstructured{Object: so}
+		if name != "" && obj.GetName() != name {
+			continue
+		}
+
+		summary := map[string]interface{}{
+			"name":            obj.GetName(),
+			"namespace":       obj.GetNamespace(),
+			"paused":          kedaPaused(obj.GetAnnotations()),
+			"active":          conditionStatus(so, "Active") == "True",
+			"triggers":        summarizeKedaTriggers(so),
+			"conditions":      summarizeConditions(so),
+			"minReplicaCount": nestedValue(so, "spec", "minReplicaCount"),
+			"maxReplicaCount": nestedValue(so, "spec", "maxReplicaCount"),
+			"scaleTargetRef":  nestedValue(so, "spec", "scaleTargetRef"),
+			"health":          nestedValue(so, "status", "health"),
+		}
+		if replicas, ok := obj.GetAnnotations()[kedaPausedReplicasAnnotation]; ok {
+			summary["pausedReplicas"] = replicas
+		}
+
+		metricNames, _, _ := unstructured.NestedStringSlice(so, "status", "externalMetricNames")
+		metricValues := map[string]interface{}{}
+		for _, metric := range metricNames {
+			value, err := c.getExternalMetricValue(ctx, obj.GetNamespace(), metric, "scaledobject.keda.sh/name="+obj.GetName())
+			if err != nil {
+				metricValues[metric] = map[string]interface{}{"error": err.Error()}
+				continue
+			}
+			metricValues[metric] = value
+		}
+		summary["metrics"] = metricValues
+
+		hpaName, _, _ := unstructured.NestedString(so, "status", "hpaName")
+		if hpaName == "" {
+			hpaName = "keda-hpa-" + obj.GetName()
+		}
+		hpa, err := c.clientset.AutoscalingV2().HorizontalPodAutoscalers(obj.GetNamespace()).Get(ctx, hpaName, metav1.GetOptions{})
+		if err != nil {
+			summary["hpa"] = map[string]interface{}{"name": hpaName, "error": err.Error()}
+		} else {
+			summary["hpa"] = map[string]interface{}{
+				"name":            hpa.Name,
+				"minReplicas":     hpa.Spec.MinReplicas,
+				"maxReplicas":     hpa.Spec.MaxReplicas,
+				"currentReplicas": hpa.Status.CurrentReplicas,
+				"desiredReplicas": hpa.Status.DesiredReplicas,
+				"currentMetrics":  hpa.Status.CurrentMetrics,
+				"lastScaleTime":   hpa.Status.LastScaleTime,
+			}
+		}
+
+		objectSummaries = append(objectSummaries, summary)
+	}
+
+	var jobSummaries []map[string]interface{}
+	scaledJobs, err := c.ListResources(ctx, "ScaledJob", namespace, "", "")
+	if err == nil {
+		for _, sj := range scaledJobs {
+			obj := unstructured.Unstructured{Object: sj}
+			if name != "" && obj.GetName() != name {
+				continue
+			}
+			jobSummaries = append(jobSummaries, map[string]interface{}{
+				"name":            obj.GetName(),
+				"namespace":       obj.GetNamespace(),
+				"paused":          kedaPaused(obj.GetAnnotations()),
+				"active":          conditionStatus(sj, "Active") == "True",
+				"triggers":        summarizeKedaTriggers(sj),
+				"conditions":      summarizeConditions(sj),
+				"maxReplicaCount": nestedValue(sj, "spec", "maxReplicaCount"),
+				"pollingInterval": nestedValue(sj, "spec", "pollingInterval"),
+			})
+		}
+	}
+
+	return map[string]interface{}{
+		"scaledObjects": objectSummaries,
+		"scaledJobs":    jobSummaries,
+	}, nil
+}
+
+// getExternalMetricValue queries the external.metrics.k8s.io API for a metric
+// and returns the served values.
+func (c *Client) getExternalMetricValue(ctx context.Context, namespace, metric, labelSelector string) ([]map[string]interface{}, error) {
+	raw, err := c.clientset.CoreV1().RESTClient().Get().
+		AbsPath("/apis/external.metrics.k8s.io/v1beta1/namespaces", namespace, metric).
+		Param("labelSelector", labelSelector).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query external metric %s: %w", metric, err)
+	}
+
+	var list struct {
+		Items []struct {
+			MetricName string `json:"metricName"`
+			Value      string `json:"value"`
+			Timestamp  string `json:"timestamp"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse external metric %s: %w", metric, err)
+	}
+
+	var values []map[string]interface{}
+	for _, item := range list.Items {
+		values = append(values, map[string]interface{}{
+			"metricName": item.MetricName,
+			"value":      item.Value,
+			"timestamp":  item.Timestamp,
+		})
+	}
+	return values, nil
+}
+
+// summarizeKedaTriggers returns the type, name and metric type of each trigger.
+// Trigger metadata is omitted as it may reference connection details.
+func summarizeKedaTriggers(obj map[string]interface{}) []map[string]interface{} {
+	rawTriggers, _, _ := unstructured.NestedSlice(obj, "spec", "triggers")
+	var triggers []map[string]interface{}
+	for _, t := range rawTriggers {
+		trigger, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		triggers = append(triggers, map[string]interface{}{
+			"type":             trigger["type"],
+			"name":             trigger["name"],
+			"metricType":       trigger["metricType"],
+			"useCachedMetrics": trigger["useCachedMetrics"],
+		})
+	}
+	return triggers
+}
+
+// kedaPaused reports whether a KEDA object is paused via annotations.
+func kedaPaused(annotations map[string]string) bool {
+	if _, ok := annotations[kedaPausedReplicasAnnotation]; ok {
+		return true
+	}
+	return annotations[kedaPausedAnnotation] == "true"
+}
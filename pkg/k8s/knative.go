@@ -0,0 +1,120 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Knative Serving resources are resolved explicitly because the "Service"
+// kind is also served by the core API group.
+var (
+	knativeServiceGVR  = schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"}
+	knativeRevisionGVR = schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "revisions"}
+)
+
+// knativeAutoscalingAnnotations are the revision annotations reported as autoscaling bounds.
+var knativeAutoscalingAnnotations = map[string]string{
+	"autoscaling.knative.dev/min-scale":     "minScale",
+	"autoscaling.knative.dev/max-scale":     "maxScale",
+	"autoscaling.knative.dev/initial-scale": "initialScale",
+	"autoscaling.knative.dev/target":        "target",
+	"autoscaling.knative.dev/metric":        "metric",
+	"autoscaling.knative.dev/class":         "class",
+}
+
+// GetKnativeServices reports Knative Serving Services with their readiness,
+// traffic split per revision, per-revision readiness and autoscaling bounds,
+// and the error of the latest created revision if it failed to become ready.
+// If name is set, only the Service with that name is returned.
+// Returns a slice of maps, one per Service, or an error if Knative Serving is not installed.
+func (c *Client) GetKnativeServices(ctx context.Context, namespace, name string) ([]map[string]interface{}, error) {
+	var services []unstructured.Unstructured
+	if name != "" {
+		if namespace == "" {
+			return nil, fmt.Errorf("namespace is required when name is set")
+		}
+		svc, err := c.dynamicClient.Resource(knativeServiceGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Knative Service: %w", err)
+		}
+		services = append(services, *svc)
+	} else {
+		list, err := c.dynamicClient.Resource(knativeServiceGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Knative Services (is Knative Serving installed?): %w", err)
+		}
+		services = list.Items
+	}
+
+	var results []map[string]interface{}
+	for _, svc := range services {
+		revisions, err := c.dynamicClient.Resource(knativeRevisionGVR).Namespace(svc.GetNamespace()).List(ctx, metav1.ListOptions{
+			LabelSelector: "serving.knative.dev/service=" + svc.GetName(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list revisions of %s/%s: %w", svc.GetNamespace(), svc.GetName(), err)
+		}
+
+		revisionsByName := map[string]map[string]interface{}{}
+		var revisionSummaries []map[string]interface{}
+		for _, rev := range revisions.Items {
+			content := rev.UnstructuredContent()
+			revisionsByName[rev.GetName()] = content
+
+			autoscaling := map[string]string{}
+			for annotation, key := range knativeAutoscalingAnnotations {
+				if value, ok := rev.GetAnnotations()[annotation]; ok {
+					autoscaling[key] = value
+				}
+			}
+			revisionSummaries = append(revisionSummaries, map[string]interface{}{
+				"name":                 rev.GetName(),
+				"creationTimestamp":    rev.GetCreationTimestamp().Time,
+				"ready":                conditionStatus(content, "Ready"),
+				"conditions":           summarizeConditions(content),
+				"autoscaling":          autoscaling,
+				"containerConcurrency": nestedValue(content, "spec", "containerConcurrency"),
+				"actualReplicas":       nestedValue(content, "status", "actualReplicas"),
+				"desiredReplicas":      nestedValue(content, "status", "desiredReplicas"),
+			})
+		}
+
+		content := svc.UnstructuredContent()
+		latestCreated, _, _ := unstructured.NestedString(content, "status", "latestCreatedRevisionName")
+		latestReady, _, _ := unstructured.NestedString(content, "status", "latestReadyRevisionName")
+
+		result := map[string]interface{}{
+			"name":                      svc.GetName(),
+			"namespace":                 svc.GetNamespace(),
+			"url":                       nestedValue(content, "status", "url"),
+			"ready":                     conditionStatus(content, "Ready"),
+			"conditions":                summarizeConditions(content),
+			"latestCreatedRevisionName": latestCreated,
+			"latestReadyRevisionName":   latestReady,
+			"traffic":                   nestedValue(content, "status", "traffic"),
+			"revisions":                 revisionSummaries,
+		}
+
+		if latestCreated != "" && latestCreated != latestReady {
+			if rev, ok := revisionsByName[latestCreated]; ok && conditionStatus(rev, "Ready") == "False" {
+				for _, condition := range summarizeConditions(rev) {
+					if condition["type"] == "Ready" {
+						result["latestFailedRevision"] = map[string]interface{}{
+							"name":    latestCreated,
+							"reason":  condition["reason"],
+							"message": condition["message"],
+						}
+					}
+				}
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// TestGetKnativeServices tests summarizing Knative Services with their revisions and the failed latest revision
+func TestGetKnativeServices(t *testing.T) {
+	var revisionSelector string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/serving.knative.dev/v1/namespaces/web/services/hello":
+			w.Write([]byte(`{"apiVersion":"serving.knative.dev/v1","kind":"Service","metadata":{"name":"hello","namespace":"web"},` +
+				`"status":{"url":"https://hello.web.example.com","latestCreatedRevisionName":"hello-00002","latestReadyRevisionName":"hello-00001",` +
+				`"traffic":[{"revisionName":"hello-00001","percent":100}],"conditions":[{"type":"Ready","status":"False","reason":"RevisionMissing"}]}}`))
+		case "/apis/serving.knative.dev/v1/namespaces/web/revisions":
+			revisionSelector = r.URL.Query().Get("labelSelector")
+			w.Write([]byte(`{"apiVersion":"serving.knative.dev/v1","kind":"RevisionList","items":[` +
+				`{"apiVersion":"serving.knative.dev/v1","kind":"Revision","metadata":{"name":"hello-00001","annotations":{"autoscaling.knative.dev/min-scale":"1"}},` +
+				`"status":{"conditions":[{"type":"Ready","status":"True"}]}},` +
+				`{"apiVersion":"serving.knative.dev/v1","kind":"Revision","metadata":{"name":"hello-00002"},` +
+				`"status":{"conditions":[{"type":"Ready","status":"False","reason":"ContainerMissing","message":"image not found"}]}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	if _, err := client.GetKnativeServices(ctx, "", "hello"); err == nil {
+		t.Error("Expected an error for a name without a namespace")
+	}
+
+	services, err := client.GetKnativeServices(ctx, "web", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0]["ready"] != "False" || services[0]["url"] != "https://hello.web.example.com" {
+		t.Fatalf("Unexpected services %v", services)
+	}
+	if revisionSelector != "serving.knative.dev/service=hello" {
+		t.Errorf("Expected the revisions of the service to be listed, got selector %q", revisionSelector)
+	}
+	revisions := services[0]["revisions"].([]map[string]interface{})
+	if len(revisions) != 2 || revisions[0]["autoscaling"].(map[string]string)["minScale"] != "1" {
+		t.Errorf("Expected the revisions with their autoscaling bounds, got %v", revisions)
+	}
+	failed, _ := services[0]["latestFailedRevision"].(map[string]interface{})
+	if failed["name"] != "hello-00002" || failed["reason"] != "ContainerMissing" || failed["message"] != "image not found" {
+		t.Errorf("Expected the failed latest revision to be reported, got %v", failed)
+	}
+
+	if _, err := client.GetKnativeServices(ctx, "other", ""); err == nil {
+		t.Error("Expected an error when Knative Serving is not installed")
+	}
+}
@@ -0,0 +1,300 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// kubeletSummary is the subset of the kubelet /stats/summary response used to
+// report node key figures.
+type kubeletSummary struct {
+	Node struct {
+		NodeName string              `json:"nodeName"`
+		Fs       *kubeletFsStats     `json:"fs"`
+		Runtime  *kubeletRuntime     `json:"runtime"`
+		Network  *kubeletNetwork     `json:"network"`
+		Memory   *kubeletMemoryStats `json:"memory"`
+		CPU      *struct {
+			UsageNanoCores *uint64 `json:"usageNanoCores"`
+		} `json:"cpu"`
+	} `json:"node"`
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		EphemeralStorage *kubeletFsStats `json:"ephemeral-storage"`
+		Network          *kubeletNetwork `json:"network"`
+		Containers       []struct {
+			Name   string          `json:"name"`
+			Rootfs *kubeletFsStats `json:"rootfs"`
+			Logs   *kubeletFsStats `json:"logs"`
+		} `json:"containers"`
+		VolumeStats []struct {
+			Name      string  `json:"name"`
+			UsedBytes *uint64 `json:"usedBytes"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// kubeletFsStats holds filesystem usage from the kubelet summary API.
+type kubeletFsStats struct {
+	AvailableBytes *uint64 `json:"availableBytes"`
+	CapacityBytes  *uint64 `json:"capacityBytes"`
+	UsedBytes      *uint64 `json:"usedBytes"`
+	InodesFree     *uint64 `json:"inodesFree"`
+	Inodes         *uint64 `json:"inodes"`
+}
+
+// kubeletRuntime holds container runtime filesystem usage.
+type kubeletRuntime struct {
+	ImageFs     *kubeletFsStats `json:"imageFs"`
+	ContainerFs *kubeletFsStats `json:"containerFs"`
+}
+
+// kubeletMemoryStats holds node memory usage.
+type kubeletMemoryStats struct {
+	WorkingSetBytes *uint64 `json:"workingSetBytes"`
+	AvailableBytes  *uint64 `json:"availableBytes"`
+}
+
+// kubeletNetwork holds per-interface network counters.
+type kubeletNetwork struct {
+	Interfaces []struct {
+		Name     string  `json:"name"`
+		RxBytes  *uint64 `json:"rxBytes"`
+		RxErrors *uint64 `json:"rxErrors"`
+		TxBytes  *uint64 `json:"txBytes"`
+		TxErrors *uint64 `json:"txErrors"`
+	} `json:"interfaces"`
+}
+
+// kubeletMetricFamilies lists the Prometheus metrics summed from the kubelet's
+// /metrics and /metrics/cadvisor endpoints.
+var kubeletMetricFamilies = map[string][]string{
+	"metrics/cadvisor": {
+		"container_network_receive_errors_total",
+		"container_network_transmit_errors_total",
+		"container_network_receive_packets_dropped_total",
+		"container_network_transmit_packets_dropped_total",
+		"container_oom_events_total",
+	},
+	"metrics": {
+		"kubelet_running_pods",
+		"kubelet_running_containers",
+		"kubelet_evictions",
+		"kubelet_started_pods_errors_total",
+		"kubelet_pleg_last_seen_seconds",
+	},
+}
+
+// GetKubeletStats fetches the kubelet's /stats/summary, /metrics and
+// /metrics/cadvisor endpoints through the node proxy subresource and returns
+// key figures: node and image filesystem usage, memory and CPU, network errors
+// per interface, the pods using the most ephemeral storage (up to topPods),
+// and selected kubelet and cAdvisor counters. Endpoints that fail are reported
+// under "errors" rather than failing the whole call.
+// Returns a map of figures, or an error if no endpoint could be read.
+func (c *Client) GetKubeletStats(ctx context.Context, nodeName string, topPods int) (map[string]interface{}, error) {
+	if topPods <= 0 {
+		topPods = 10
+	}
+	result := map[string]interface{}{"node": nodeName}
+	errs := map[string]string{}
+
+	summary, err := c.getKubeletSummary(ctx, nodeName)
+	if err != nil {
+		errs["stats/summary"] = err.Error()
+	} else {
+		for key, value := range summarizeKubeletStats(summary, topPods) {
+			result[key] = value
+		}
+	}
+
+	metrics := map[string]interface{}{}
+	for endpoint, families := range kubeletMetricFamilies {
+		raw, err := c.kubeletProxyGet(ctx, nodeName, endpoint)
+		if err != nil {
+			errs[endpoint] = err.Error()
+			continue
+		}
+		for name, value := range sumPrometheusMetrics(raw, families) {
+			metrics[name] = value
+		}
+	}
+	result["metrics"] = metrics
+
+	if len(errs) > 0 {
+		result["errors"] = errs
+		if len(errs) == len(kubeletMetricFamilies)+1 {
+			return nil, fmt.Errorf("failed to read kubelet endpoints on node %s: %s", nodeName, errs["stats/summary"])
+		}
+	}
+	return result, nil
+}
+
+// getKubeletSummary reads and parses the kubelet /stats/summary of a node.
+func (c *Client) getKubeletSummary(ctx context.Context, nodeName string) (*kubeletSummary, error) {
+	raw, err := c.kubeletProxyGet(ctx, nodeName, "stats/summary")
+	if err != nil {
+		return nil, err
+	}
+	var summary kubeletSummary
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse summary: %w", err)
+	}
+	return &summary, nil
+}
+
+// kubeletProxyGet reads a kubelet endpoint through the node proxy subresource.
+func (c *Client) kubeletProxyGet(ctx context.Context, nodeName, path string) ([]byte, error) {
+	segments := append([]string{"/api/v1/nodes", nodeName, "proxy"}, strings.Split(path, "/")...)
+	return c.clientset.CoreV1().RESTClient().Get().AbsPath(segments...).DoRaw(ctx)
+}
+
+// summarizeKubeletStats extracts key figures from a kubelet summary.
+func summarizeKubeletStats(summary *kubeletSummary, topPods int) map[string]interface{} {
+	result := map[string]interface{}{
+		"nodeFs": summarizeFsStats(summary.Node.Fs),
+	}
+	if summary.Node.Runtime != nil {
+		result["imageFs"] = summarizeFsStats(summary.Node.Runtime.ImageFs)
+		if summary.Node.Runtime.ContainerFs != nil {
+			result["containerFs"] = summarizeFsStats(summary.Node.Runtime.ContainerFs)
+		}
+	}
+	if summary.Node.Memory != nil {
+		result["memory"] = map[string]interface{}{
+			"workingSetBytes": summary.Node.Memory.WorkingSetBytes,
+			"availableBytes":  summary.Node.Memory.AvailableBytes,
+		}
+	}
+	if summary.Node.CPU != nil && summary.Node.CPU.UsageNanoCores != nil {
+		result["cpuUsageMillicores"] = *summary.Node.CPU.UsageNanoCores / 1e6
+	}
+	result["network"] = summarizeNetwork(summary.Node.Network)
+
+	type podUsage struct {
+		name      string
+		namespace string
+		used      uint64
+	}
+	var usages []podUsage
+	var networkErrors []map[string]interface{}
+	for _, pod := range summary.Pods {
+		if pod.EphemeralStorage != nil && pod.EphemeralStorage.UsedBytes != nil {
+			usages = append(usages, podUsage{pod.PodRef.Name, pod.PodRef.Namespace, *pod.EphemeralStorage.UsedBytes})
+		}
+		for _, iface := range summarizeNetwork(pod.Network) {
+			if iface["rxErrors"].(uint64) > 0 || iface["txErrors"].(uint64) > 0 {
+				iface["pod"] = pod.PodRef.Name
+				iface["namespace"] = pod.PodRef.Namespace
+				networkErrors = append(networkErrors, iface)
+			}
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].used > usages[j].used })
+	if len(usages) > topPods {
+		usages = usages[:topPods]
+	}
+	var topEphemeral []map[string]interface{}
+	for _, usage := range usages {
+		topEphemeral = append(topEphemeral, map[string]interface{}{
+			"name":      usage.name,
+			"namespace": usage.namespace,
+			"usedBytes": usage.used,
+		})
+	}
+	result["podEphemeralStorage"] = topEphemeral
+	result["podNetworkErrors"] = networkErrors
+	return result
+}
+
+// summarizeFsStats reports filesystem usage with a used percentage.
+func summarizeFsStats(fs *kubeletFsStats) map[string]interface{} {
+	if fs == nil {
+		return nil
+	}
+	result := map[string]interface{}{
+		"usedBytes":      fs.UsedBytes,
+		"availableBytes": fs.AvailableBytes,
+		"capacityBytes":  fs.CapacityBytes,
+		"inodesFree":     fs.InodesFree,
+	}
+	if fs.UsedBytes != nil && fs.CapacityBytes != nil && *fs.CapacityBytes > 0 {
+		percent := float64(*fs.UsedBytes) / float64(*fs.CapacityBytes) * 100
+		result["usedPercent"] = float64(int(percent*10+0.5)) / 10
+	}
+	return result
+}
+
+// summarizeNetwork reports byte and error counters per network interface.
+func summarizeNetwork(network *kubeletNetwork) []map[string]interface{} {
+	if network == nil {
+		return nil
+	}
+	value := func(v *uint64) uint64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	var interfaces []map[string]interface{}
+	for _, iface := range network.Interfaces {
+		interfaces = append(interfaces, map[string]interface{}{
+			"interface": iface.Name,
+			"rxBytes":   value(iface.RxBytes),
+			"txBytes":   value(iface.TxBytes),
+			"rxErrors":  value(iface.RxErrors),
+			"txErrors":  value(iface.TxErrors),
+		})
+	}
+	return interfaces
+}
+
+// sumPrometheusMetrics sums the samples of the given metric families in a
+// Prometheus text exposition, across all label sets.
+func sumPrometheusMetrics(raw []byte, families []string) map[string]float64 {
+	wanted := map[string]bool{}
+	for _, family := range families {
+		wanted[family] = true
+	}
+
+	sums := map[string]float64{}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name = line[:i]
+		}
+		if !wanted[name] {
+			continue
+		}
+		// The value follows the closing brace of the labels, if any
+		rest := line[len(name):]
+		if i := strings.LastIndex(rest, "}"); i >= 0 {
+			rest = rest[i+1:]
+		}
+		fieldsAfter := strings.Fields(rest)
+		if len(fieldsAfter) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fieldsAfter[0], 64)
+		if err != nil {
+			continue
+		}
+		sums[name] += value
+	}
+	return sums
+}
@@ -0,0 +1,31 @@
+package k8s
+
+import "testing"
+
+// TestSumPrometheusMetrics tests summing metric samples from a text exposition
+func TestSumPrometheusMetrics(t *testing.T) {
+	raw := []byte(`# HELP container_network_receive_errors_total Cumulative count of errors encountered while receiving
+# TYPE container_network_receive_errors_total counter
+container_network_receive_errors_total{container="",interface="eth0",pod="a"} 3 1700000000000
+container_network_receive_errors_total{container="",interface="eth0",pod="b"} 2
+kubelet_running_pods 17
+container_oom_events_total{container="app",name="x {y}"} 1
+`)
+
+	sums := sumPrometheusMetrics(raw, []string{
+		"container_network_receive_errors_total",
+		"kubelet_running_pods",
+		"container_oom_events_total",
+	})
+
+	expected := map[string]float64{
+		"container_network_receive_errors_total": 5,
+		"kubelet_running_pods":                   17,
+		"container_oom_events_total":             1,
+	}
+	for name, want := range expected {
+		if sums[name] != want {
+			t.Errorf("Expected %s = %v, got %v", name, want, sums[name])
+		}
+	}
+}
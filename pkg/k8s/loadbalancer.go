@@ -0,0 +1,265 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxLoadBalancerEvents bounds the events reported per Service by
+// DiagnoseLoadBalancers.
+const maxLoadBalancerEvents = 10
+
+// loadBalancerAnnotationPrefixes are the annotation prefixes of cloud
+// providers and load balancer implementations that configure the load
+// balancer of a Service.
+var loadBalancerAnnotationPrefixes = []string{
+	"service.beta.kubernetes.io/",
+	"service.kubernetes.io/",
+	"cloud.google.com/",
+	"networking.gke.io/",
+	"metallb.universe.tf/",
+	"metallb.io/",
+	"lbipam.cilium.io/",
+	"io.cilium/",
+	"kube-vip.io/",
+	"load-balancer.hetzner.cloud/",
+}
+
+// loadBalancerHints maps keywords of cloud controller event messages,
+// matched case-insensitively, to what they usually mean.
+var loadBalancerHints = []struct {
+	keywords []string
+	hint     string
+}{
+	{[]string{"quota", "limitexceeded", "limit exceeded"},
+		"The cloud account ran out of load balancer or address quota; raise the quota or remove unused load balancers."},
+	{[]string{"firewall", "security group", "securitygroup"},
+		"Creating the firewall rules or security groups for the load balancer failed; check the cloud controller's permissions and the rule limits of the network."},
+	{[]string{"health check", "healthcheck", "unhealthy"},
+		"The load balancer's health checks fail; with externalTrafficPolicy Local only nodes running ready endpoints pass the check on healthCheckNodePort, and firewalls must allow the provider's health check ranges."},
+	{[]string{"subnet"},
+		"No usable subnet was found for the load balancer; on AWS, subnets must be tagged kubernetes.io/role/elb (public) or kubernetes.io/role/internal-elb (internal)."},
+	{[]string{"accessdenied", "access denied", "unauthorized", "forbidden", "permission"},
+		"The cloud controller lacks permissions to manage load balancers; check its IAM role or service account."},
+	{[]string{"no available ip", "no available address", "allocationfailed", "address pool", "pool exhausted"},
+		"The load balancer address pool is exhausted or no pool matches the Service; add addresses to the pool or check its selectors."},
+	{[]string{"certificate"},
+		"The TLS certificate named in the Service's annotations could not be used; check that it exists in the provider and the annotation value is correct."},
+	{[]string{"loadbalancerip", "requested ip", "reserved", "static ip"},
+		"The requested load balancer IP could not be used; it must be a reserved address in the same region and not in use by another load balancer."},
+}
+
+// DiagnoseLoadBalancers explains why Services of type LoadBalancer are stuck
+// in Pending, or describes their provisioned load balancer. For each Service
+// it reports the assigned ingress IPs and hostnames, the load balancer
+// annotations and spec fields in use, the recent events of the cloud
+// controller, the number of ready endpoints, and hints extracted from
+// warning events, such as exhausted quotas, firewall or health check
+// failures, missing subnets or permissions. A pending Service without any
+// events suggests that no controller handles it. An empty name diagnoses
+// every LoadBalancer Service in namespace, or in all namespaces if namespace
+// is empty too.
+// Returns a map with the diagnosed "services" and the number still
+// "pending", or an error.
+func (c *Client) DiagnoseLoadBalancers(ctx context.Context, namespace, name string) (map[string]interface{}, error) {
+	var services []corev1.Service
+	if name != "" {
+		if namespace == "" {
+			namespace = "default"
+		}
+		service, err := c.clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get service %s/%s: %w", namespace, name, err)
+		}
+		if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			return nil, fmt.Errorf("service %s/%s is of type %s, not LoadBalancer", namespace, name, service.Spec.Type)
+		}
+		services = append(services, *service)
+	} else {
+		list, err := c.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		for _, service := range list.Items {
+			if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+				services = append(services, service)
+			}
+		}
+	}
+
+	result := map[string]interface{}{}
+	var errors []string
+	var events []map[string]interface{}
+	haveEvents := false
+	if len(services) > 0 {
+		var err error
+		if events, err = c.listNormalizedEvents(ctx, namespace, metav1.ListOptions{}); err != nil {
+			errors = append(errors, err.Error())
+		} else {
+			haveEvents = true
+		}
+	}
+
+	diagnosed := []map[string]interface{}{}
+	pending := 0
+	for i := range services {
+		diagnosis := c.diagnoseLoadBalancer(ctx, &services[i], events, haveEvents)
+		if diagnosis["pending"] == true {
+			pending++
+		}
+		diagnosed = append(diagnosed, diagnosis)
+	}
+	result["services"] = diagnosed
+	result["pending"] = pending
+	if len(errors) > 0 {
+		result["errors"] = errors
+	}
+	return result, nil
+}
+
+// diagnoseLoadBalancer diagnoses one LoadBalancer Service from its spec,
+// status, endpoints and the events of its namespace.
+func (c *Client) diagnoseLoadBalancer(ctx context.Context, service *corev1.Service, events []map[string]interface{}, haveEvents bool) map[string]interface{} {
+	ingress := []map[string]interface{}{}
+	for _, lb := range service.Status.LoadBalancer.Ingress {
+		entry := map[string]interface{}{}
+		if lb.IP != "" {
+			entry["ip"] = lb.IP
+		}
+		if lb.Hostname != "" {
+			entry["hostname"] = lb.Hostname
+		}
+		if lb.IPMode != nil {
+			entry["ipMode"] = string(*lb.IPMode)
+		}
+		ingress = append(ingress, entry)
+	}
+	pending := len(ingress) == 0
+
+	annotations := map[string]string{}
+	for key, value := range service.Annotations {
+		for _, prefix := range loadBalancerAnnotationPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				annotations[key] = value
+				break
+			}
+		}
+	}
+
+	spec := map[string]interface{}{
+		"externalTrafficPolicy": string(service.Spec.ExternalTrafficPolicy),
+	}
+	if service.Spec.LoadBalancerClass != nil {
+		spec["loadBalancerClass"] = *service.Spec.LoadBalancerClass
+	}
+	if service.Spec.LoadBalancerIP != "" {
+		spec["loadBalancerIP"] = service.Spec.LoadBalancerIP
+	}
+	if len(service.Spec.LoadBalancerSourceRanges) > 0 {
+		spec["loadBalancerSourceRanges"] = service.Spec.LoadBalancerSourceRanges
+	}
+	if service.Spec.HealthCheckNodePort != 0 {
+		spec["healthCheckNodePort"] = service.Spec.HealthCheckNodePort
+	}
+	if service.Spec.AllocateLoadBalancerNodePorts != nil {
+		spec["allocateLoadBalancerNodePorts"] = *service.Spec.AllocateLoadBalancerNodePorts
+	}
+	var ports []string
+	for _, port := range service.Spec.Ports {
+		ports = append(ports, fmt.Sprintf("%d/%s->%d", port.Port, port.Protocol, port.NodePort))
+	}
+	spec["ports"] = ports
+
+	var matched []map[string]interface{}
+	for _, event := range events {
+		involved, _ := event["involvedObject"].(map[string]interface{})
+		if involved["kind"] == "Service" && involved["name"] == service.Name && event["namespace"] == service.Namespace {
+			matched = append(matched, event)
+		}
+	}
+	matched = sortFilterLimitEvents(matched, 0, "lastTime", "")
+
+	var hints []string
+	seen := map[string]bool{}
+	addHint := func(hint string) {
+		if !seen[hint] {
+			seen[hint] = true
+			hints = append(hints, hint)
+		}
+	}
+	for _, event := range matched {
+		if event["type"] != corev1.EventTypeWarning {
+			continue
+		}
+		message := strings.ToLower(fmt.Sprintf("%v %v", event["reason"], event["message"]))
+		for _, known := range loadBalancerHints {
+			for _, keyword := range known.keywords {
+				if strings.Contains(message, keyword) {
+					addHint(known.hint)
+					break
+				}
+			}
+		}
+	}
+
+	diagnosis := map[string]interface{}{
+		"name":        service.Name,
+		"namespace":   service.Namespace,
+		"pending":     pending,
+		"ingress":     ingress,
+		"annotations": annotations,
+		"spec":        spec,
+	}
+
+	readyEndpoints := -1
+	slices, err := c.clientset.DiscoveryV1().EndpointSlices(service.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + service.Name,
+	})
+	if err == nil {
+		readyEndpoints = 0
+		for _, slice := range slices.Items {
+			for _, endpoint := range slice.Endpoints {
+				if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+					readyEndpoints++
+				}
+			}
+		}
+		diagnosis["readyEndpoints"] = readyEndpoints
+	}
+	if readyEndpoints == 0 && len(service.Spec.Selector) > 0 {
+		if service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal {
+			addHint("The Service has no ready endpoints, so with externalTrafficPolicy Local every node fails the load balancer's health check.")
+		} else {
+			addHint("The Service has no ready endpoints, so the load balancer has nothing to send traffic to.")
+		}
+	}
+
+	if pending && len(matched) == 0 && haveEvents {
+		if class, ok := spec["loadBalancerClass"]; ok {
+			addHint(fmt.Sprintf("No controller has reported events for this Service; check that a controller for loadBalancerClass %s is running, as the cloud provider ignores Services with a class.", class))
+		} else {
+			addHint("No controller has reported events for this Service; the cluster may lack a cloud controller manager or a load balancer implementation such as MetalLB, kube-vip or Cilium LB IPAM.")
+		}
+	}
+	if pending && service.Spec.LoadBalancerIP != "" {
+		addHint("spec.loadBalancerIP is deprecated and ignored by some providers; use the provider's annotation to request an address.")
+	}
+
+	if len(matched) > maxLoadBalancerEvents {
+		matched = matched[:maxLoadBalancerEvents]
+	}
+	if matched == nil {
+		matched = []map[string]interface{}{}
+	}
+	if hints == nil {
+		hints = []string{}
+	}
+	diagnosis["events"] = matched
+	diagnosis["hints"] = hints
+	return diagnosis
+}
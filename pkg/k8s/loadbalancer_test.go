@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestDiagnoseLoadBalancers tests explaining pending LoadBalancer Services from their events and endpoints
+func TestDiagnoseLoadBalancers(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/web/services":
+			w.Write([]byte(`{"kind":"ServiceList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"shop","namespace":"web","annotations":{"service.beta.kubernetes.io/aws-load-balancer-type":"nlb","team":"payments"}},` +
+				`"spec":{"type":"LoadBalancer","selector":{"app":"shop"},"externalTrafficPolicy":"Local","healthCheckNodePort":31000,"ports":[{"port":443,"protocol":"TCP","nodePort":30443}]}},` +
+				`{"metadata":{"name":"api","namespace":"web"},"spec":{"type":"LoadBalancer","externalTrafficPolicy":"Cluster","ports":[{"port":80,"protocol":"TCP","nodePort":30080}]},` +
+				`"status":{"loadBalancer":{"ingress":[{"hostname":"api.elb.example.com"}]}}},` +
+				`{"metadata":{"name":"admin","namespace":"web"},"spec":{"type":"LoadBalancer","loadBalancerClass":"example.com/internal","ports":[{"port":80,"protocol":"TCP","nodePort":30081}]}},` +
+				`{"metadata":{"name":"db","namespace":"web"},"spec":{"type":"ClusterIP","ports":[{"port":5432}]}}]}`))
+		case "/apis/events.k8s.io/v1/namespaces/web/events":
+			w.Write([]byte(`{"kind":"EventList","apiVersion":"events.k8s.io/v1","items":[` +
+				`{"metadata":{"name":"shop.1","namespace":"web"},"eventTime":"2024-05-01T10:00:00.000000Z","reportingController":"service-controller","reason":"EnsuringLoadBalancer","type":"Normal",` +
+				`"note":"Ensuring load balancer","regarding":{"kind":"Service","name":"shop","namespace":"web"}},` +
+				`{"metadata":{"name":"shop.2","namespace":"web"},"eventTime":"2024-05-01T10:00:05.000000Z","reportingController":"service-controller","reason":"SyncLoadBalancerFailed","type":"Warning",` +
+				`"note":"Error syncing load balancer: failed to ensure load balancer: TooManyLoadBalancers: Exceeded quota of account","regarding":{"kind":"Service","name":"shop","namespace":"web"}}]}`))
+		case "/apis/discovery.k8s.io/v1/namespaces/web/endpointslices":
+			endpoints := ""
+			if r.URL.Query().Get("labelSelector") == "kubernetes.io/service-name=shop" {
+				endpoints = `{"addresses":["10.0.0.1"],"conditions":{"ready":false}}`
+			}
+			w.Write([]byte(`{"kind":"EndpointSliceList","apiVersion":"discovery.k8s.io/v1","items":[{"metadata":{"name":"slice","namespace":"web"},"addressType":"IPv4","endpoints":[` + endpoints + `]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.DiagnoseLoadBalancers(context.Background(), "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	services := result["services"].([]map[string]interface{})
+	if len(services) != 3 || result["pending"] != 2 {
+		t.Fatalf("Expected 3 LoadBalancer services with 2 pending, got %v", result)
+	}
+
+	shop := services[0]
+	if annotations := shop["annotations"].(map[string]string); len(annotations) != 1 || annotations["service.beta.kubernetes.io/aws-load-balancer-type"] != "nlb" {
+		t.Errorf("Expected only the load balancer annotation, got %v", annotations)
+	}
+	if events := shop["events"].([]map[string]interface{}); len(events) != 2 || events[0]["reason"] != "SyncLoadBalancerFailed" {
+		t.Errorf("Expected the newest event first, got %v", events)
+	}
+	hints := strings.Join(shop["hints"].([]string), "\n")
+	if !strings.Contains(hints, "quota") || !strings.Contains(hints, "externalTrafficPolicy Local every node fails") {
+		t.Errorf("Expected quota and health check hints, got %v", hints)
+	}
+
+	if api := services[1]; api["pending"] != false || api["ingress"].([]map[string]interface{})[0]["hostname"] != "api.elb.example.com" {
+		t.Errorf("Expected api to be provisioned, got %v", api)
+	}
+	if hints := services[2]["hints"].([]string); len(hints) != 1 || !strings.Contains(hints[0], "loadBalancerClass example.com/internal") {
+		t.Errorf("Expected a hint about the missing class controller, got %v", hints)
+	}
+
+	if _, err := client.DiagnoseLoadBalancers(context.Background(), "web", "db"); err == nil {
+		t.Error("Expected a ClusterIP service to be rejected")
+	}
+}
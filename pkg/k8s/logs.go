@@ -0,0 +1,173 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// logFetchConcurrency bounds the number of container logs fetched at once by
+// GetLogsBySelector.
+const logFetchConcurrency = 8
+
+// containerLog is the log of one container of a pod matched by a selector.
+type containerLog struct {
+	pod       string
+	container string
+	lines     []logLine
+	err       error
+}
+
+// logLine is a line of a container log with its parsed timestamp.
+type logLine struct {
+	time time.Time
+	text string
+}
+
+// GetLogsBySelector retrieves the logs of every container of the pods matching
+// labelSelector in a namespace, like kubectl logs -l or stern. Each line is
+// prefixed with the pod and container name. Logs are grouped per container, or,
+// when interleave is set, merged into one stream ordered by timestamp. At most
+// maxPods pods, sorted by name, are read. If container is set, only containers
+// with that name are read.
+// Returns a map with the combined "logs" text, the number of "pods" read and
+// "totalPods" matched, and per-container "errors", or an error.
+func (c *Client) GetLogsBySelector(ctx context.Context, namespace, labelSelector, container string, opts LogOptions, interleave bool, maxPods int) (map[string]interface{}, error) {
+	if labelSelector == "" {
+		return nil, fmt.Errorf("a label selector is required")
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	podList, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	totalPods := len(pods)
+	if maxPods > 0 && len(pods) > maxPods {
+		pods = pods[:maxPods]
+	}
+
+	var logs []*containerLog
+	for _, pod := range pods {
+		for _, ctr := range pod.Spec.Containers {
+			if container == "" || ctr.Name == container {
+				logs = append(logs, &containerLog{pod: pod.Name, container: ctr.Name})
+			}
+		}
+	}
+
+	// Interleaving orders lines by the timestamps the API server prefixes them with
+	fetchOpts := opts
+	fetchOpts.Timestamps = opts.Timestamps || interleave
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, logFetchConcurrency)
+	for _, log := range logs {
+		wg.Add(1)
+		go func(log *containerLog) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			log.lines, log.err = c.fetchContainerLog(ctx, namespace, log.pod, log.container, fetchOpts)
+		}(log)
+	}
+	wg.Wait()
+
+	var text strings.Builder
+	errors := []string{}
+	var merged []logLine
+	for _, log := range logs {
+		prefix := fmt.Sprintf("[%s/%s] ", log.pod, log.container)
+		if log.err != nil {
+			errors = append(errors, prefix+log.err.Error())
+			continue
+		}
+		for _, line := range log.lines {
+			if interleave && !opts.Timestamps {
+				line.text = stripLogTimestamp(line.text)
+			}
+			line.text = prefix + line.text
+			if interleave {
+				merged = append(merged, line)
+			} else {
+				text.WriteString(line.text + "\n")
+			}
+		}
+	}
+	if interleave {
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].time.Before(merged[j].time) })
+		for _, line := range merged {
+			text.WriteString(line.text + "\n")
+		}
+	}
+
+	return map[string]interface{}{
+		"logs":       text.String(),
+		"pods":       len(pods),
+		"totalPods":  totalPods,
+		"containers": len(logs),
+		"errors":     errors,
+	}, nil
+}
+
+// fetchContainerLog reads the log of a container as lines. When opts requests
+// timestamps, each line's timestamp is parsed; lines without one, such as
+// continuations, inherit the timestamp of the line before.
+func (c *Client) fetchContainerLog(ctx context.Context, namespace, pod, container string, opts LogOptions) ([]logLine, error) {
+	podLogOptions := opts.podLogOptions()
+	podLogOptions.Container = container
+	stream, err := c.clientset.CoreV1().Pods(namespace).GetLogs(pod, podLogOptions).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+	defer stream.Close()
+	return parseLogLines(stream, opts.Timestamps)
+}
+
+// parseLogLines splits a log into lines, parsing the RFC3339 timestamp that
+// prefixes each line when timestamps is set.
+func parseLogLines(r io.Reader, timestamps bool) ([]logLine, error) {
+	var lines []logLine
+	var last time.Time
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := logLine{time: last, text: scanner.Text()}
+		if timestamps {
+			if stamp, _, found := strings.Cut(line.text, " "); found {
+				if parsed, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+					line.time = parsed
+					last = parsed
+				}
+			}
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return lines, fmt.Errorf("failed to read logs: %w", err)
+	}
+	return lines, nil
+}
+
+// stripLogTimestamp removes the RFC3339 timestamp the API server prefixes a
+// log line with.
+func stripLogTimestamp(line string) string {
+	stamp, rest, found := strings.Cut(line, " ")
+	if !found {
+		return line
+	}
+	if _, err := time.Parse(time.RFC3339Nano, stamp); err != nil {
+		return line
+	}
+	return rest
+}
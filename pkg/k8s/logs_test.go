@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestParseLogLines tests parsing timestamped log lines, including continuation lines
+func TestParseLogLines(t *testing.T) {
+	log := "2026-01-01T10:00:00.5Z started\n" +
+		"  at main.go:12\n" +
+		"2026-01-01T10:00:02Z ready\n"
+	lines, err := parseLogLines(strings.NewReader(log), true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	if !lines[1].time.Equal(lines[0].time) || lines[0].time.IsZero() {
+		t.Errorf("Expected the continuation line to inherit the timestamp, got %v and %v", lines[0].time, lines[1].time)
+	}
+	if !lines[2].time.After(lines[1].time) {
+		t.Errorf("Expected increasing timestamps, got %v", lines[2].time)
+	}
+
+	if got := stripLogTimestamp(lines[0].text); got != "started" {
+		t.Errorf("Expected the timestamp to be stripped, got %q", got)
+	}
+	if got := stripLogTimestamp(lines[1].text); got != lines[1].text {
+		t.Errorf("Expected lines without a timestamp to be unchanged, got %q", got)
+	}
+}
+
+// TestGetPodsLogs tests passing the log options and reading the logs of one or all containers
+func TestGetPodsLogs(t *testing.T) {
+	var queries []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/web/pods/api":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"kind":"Pod","apiVersion":"v1","metadata":{"name":"api"},"spec":{"containers":[{"name":"app"},{"name":"proxy"}]}}`))
+		case "/api/v1/namespaces/web/pods/api/log":
+			queries = append(queries, r.URL.RawQuery)
+			if r.URL.Query().Get("container") == "proxy" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"previous terminated container \"proxy\" not found","code":400}`))
+				return
+			}
+			w.Write([]byte("log of " + r.URL.Query().Get("container") + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	logs, err := client.GetPodsLogs(ctx, "web", "app", "api", LogOptions{TailLines: 50, SinceSeconds: 300, Previous: true, Timestamps: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logs != "log of app\n" || queries[0] != "container=app&previous=true&sinceSeconds=300&tailLines=50&timestamps=true" {
+		t.Errorf("Expected the container's log with all options passed, got %q for %q", logs, queries[0])
+	}
+
+	logs, err = client.GetPodsLogs(ctx, "web", "", "api", LogOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs, "--- Logs for container app ---\nlog of app") || !strings.Contains(logs, "--- Error getting logs for container proxy") {
+		t.Errorf("Expected the logs of every container with per-container errors, got %q", logs)
+	}
+
+	if _, err := client.GetPodsLogs(ctx, "web", "proxy", "api", LogOptions{}); err == nil || !strings.Contains(err.Error(), "failed to get logs for container 'proxy'") {
+		t.Errorf("Expected the error of the container's log, got %v", err)
+	}
+	if _, err := client.GetPodsLogs(ctx, "web", "", "missing", LogOptions{}); err == nil {
+		t.Error("Expected an error for a missing pod")
+	}
+}
@@ -0,0 +1,131 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/discovery"
+)
+
+// namespaceTransport refuses requests in namespaces a namespace policy
+// excludes, and requests for namespaced resources across all namespaces,
+// whose responses would include the excluded ones.
+type namespaceTransport struct {
+	next      http.RoundTripper
+	check     func(namespace string) error
+	resources *namespacedResources
+}
+
+// RoundTrip checks the namespace of API requests before passing them through.
+// Requests for cluster-scoped resources, the namespaces themselves and
+// non-resource paths such as /version pass unchecked. The node proxy is
+// refused, because the kubelet serves logs and stats of pods in every
+// namespace through it.
+func (t *namespaceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	groupVersion, resource, namespace, ok := parseResourcePath(req.URL.Path)
+	switch {
+	case ok && isNodeProxyPath(req.URL.Path):
+		return nil, fmt.Errorf("refused by namespace policy: the node proxy exposes logs and stats of pods in all namespaces")
+	case !ok || (resource == "namespaces" && namespace == ""):
+	case namespace != "":
+		if err := t.check(namespace); err != nil {
+			return nil, fmt.Errorf("refused by namespace policy: %w", err)
+		}
+	default:
+		namespaced, err := t.resources.namespaced(groupVersion, resource)
+		if err != nil {
+			return nil, fmt.Errorf("refused by namespace policy: cannot tell whether %s is namespaced: %w", resource, err)
+		}
+		if namespaced {
+			return nil, fmt.Errorf("refused by namespace policy: %s across all namespaces may include excluded namespaces; name a namespace", resource)
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// parseResourcePath splits the path of an API request, e.g.
+// /apis/apps/v1/namespaces/web/deployments/api, into its group version,
+// resource and namespace. A namespace object, e.g. /api/v1/namespaces/web,
+// is reported as the namespaces resource in its own namespace. It reports
+// false for non-resource paths.
+func parseResourcePath(path string) (groupVersion, resource, namespace string, ok bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		groupVersion, parts = parts[1], parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		groupVersion, parts = parts[1]+"/"+parts[2], parts[3:]
+	default:
+		return "", "", "", false
+	}
+	if parts[0] == "watch" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 || parts[0] == "" {
+		return "", "", "", false
+	}
+	if parts[0] == "namespaces" && len(parts) >= 2 {
+		resource = "namespaces"
+		if len(parts) >= 3 {
+			resource = parts[2]
+		}
+		return groupVersion, resource, parts[1], true
+	}
+	return groupVersion, parts[0], "", true
+}
+
+// isNodeProxyPath reports whether path is a request through the proxy
+// subresource of a node, e.g. /api/v1/nodes/worker-1/proxy/stats/summary.
+func isNodeProxyPath(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	return len(parts) >= 6 && parts[0] == "api" && parts[2] == "nodes" && parts[4] == "proxy"
+}
+
+// namespacedResources records which resources of each group version are
+// namespaced, discovering a group version on first use.
+type namespacedResources struct {
+	discovery     discovery.DiscoveryInterface
+	mu            sync.Mutex
+	groupVersions map[string]map[string]bool
+}
+
+// namespaced reports whether resource of groupVersion is namespaced.
+func (r *namespacedResources) namespaced(groupVersion, resource string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resources, ok := r.groupVersions[groupVersion]
+	if !ok {
+		list, err := r.discovery.ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			return false, err
+		}
+		resources = map[string]bool{}
+		for _, apiResource := range list.APIResources {
+			resources[apiResource.Name] = apiResource.Namespaced
+		}
+		r.groupVersions[groupVersion] = resources
+	}
+	namespaced, ok := resources[resource]
+	if !ok {
+		return false, fmt.Errorf("resource %s not found in %s", resource, groupVersion)
+	}
+	return namespaced, nil
+}
+
+// WithNamespacePolicy returns a copy of the client whose requests are refused
+// before they leave the server if check refuses their namespace, or if they
+// span all namespaces of a namespaced resource. Clients created by a
+// ClientPool whose default client has a namespace policy share it.
+func (c *Client) WithNamespacePolicy(check func(namespace string) error) (*Client, error) {
+	resources := &namespacedResources{discovery: c.discoveryClient, groupVersions: map[string]map[string]bool{}}
+	client, err := c.withTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &namespaceTransport{next: rt, check: check, resources: resources}
+	})
+	if err != nil {
+		return nil, err
+	}
+	client.namespaceCheck = check
+	return client, nil
+}
@@ -0,0 +1,150 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestParseResourcePath tests extracting the resource and namespace of API request paths
+func TestParseResourcePath(t *testing.T) {
+	tests := []struct {
+		path         string
+		groupVersion string
+		resource     string
+		namespace    string
+		ok           bool
+	}{
+		{"/api/v1/namespaces/web/pods/api-0/log", "v1", "pods", "web", true},
+		{"/apis/apps/v1/namespaces/web/deployments", "apps/v1", "deployments", "web", true},
+		{"/api/v1/watch/namespaces/web/events", "v1", "events", "web", true},
+		{"/api/v1/namespaces/web", "v1", "namespaces", "web", true},
+		{"/api/v1/namespaces", "v1", "namespaces", "", true},
+		{"/apis/apps/v1/deployments", "apps/v1", "deployments", "", true},
+		{"/api/v1/nodes/node-1", "v1", "nodes", "", true},
+		{"/api/v1", "", "", "", false},
+		{"/apis/apps/v1", "", "", "", false},
+		{"/version", "", "", "", false},
+	}
+	for _, tt := range tests {
+		groupVersion, resource, namespace, ok := parseResourcePath(tt.path)
+		if groupVersion != tt.groupVersion || resource != tt.resource || namespace != tt.namespace || ok != tt.ok {
+			t.Errorf("parseResourcePath(%q) = %q, %q, %q, %v, want %q, %q, %q, %v",
+				tt.path, groupVersion, resource, namespace, ok, tt.groupVersion, tt.resource, tt.namespace, tt.ok)
+		}
+	}
+}
+
+// TestWithNamespacePolicy tests that requests outside the allowed namespaces are refused before they are sent
+func TestWithNamespacePolicy(t *testing.T) {
+	var paths []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1" {
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[` +
+				`{"name":"pods","namespaced":true,"kind":"Pod","verbs":["list"]},` +
+				`{"name":"nodes","namespaced":false,"kind":"Node","verbs":["list"]}]}`))
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"kind":"List","apiVersion":"v1","metadata":{},"items":[]}`))
+	}))
+	guarded, err := client.WithNamespacePolicy(func(namespace string) error {
+		if namespace != "web" {
+			return fmt.Errorf("namespace %q is not in the namespace allowlist", namespace)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readOnly, err := guarded.WithReadOnly()
+	if err != nil || readOnly.namespaceCheck == nil {
+		t.Fatalf("Expected the read-only copy to keep the namespace policy, got %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := guarded.clientset.CoreV1().Pods("web").List(ctx, metav1.ListOptions{}); err != nil {
+		t.Errorf("Expected pods in an allowed namespace to be listed, got %v", err)
+	}
+	if _, err := guarded.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{}); err != nil {
+		t.Errorf("Expected cluster-scoped resources to be listed, got %v", err)
+	}
+	if _, err := guarded.clientset.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{}); err == nil || !strings.Contains(err.Error(), "namespace policy") {
+		t.Errorf("Expected pods in an excluded namespace to be refused, got %v", err)
+	}
+	if _, err := guarded.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{}); err == nil || !strings.Contains(err.Error(), "all namespaces") {
+		t.Errorf("Expected pods across all namespaces to be refused, got %v", err)
+	}
+	if _, err := guarded.GetKubeletStats(ctx, "worker-1", 0); err == nil || !strings.Contains(err.Error(), "node proxy") {
+		t.Errorf("Expected the node proxy to be refused, got %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/api/v1/namespaces/web/pods" || paths[1] != "/api/v1/nodes" {
+		t.Errorf("Expected only the allowed requests to reach the server, got %v", paths)
+	}
+}
+
+// TestListResourcesAllNamespaces tests listing the namespaces a namespace policy allows one at a time
+func TestListResourcesAllNamespaces(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		pods := func(namespace string, names ...string) string {
+			var items []string
+			for _, name := range names {
+				items = append(items, `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"`+name+`","namespace":"`+namespace+`"}}`)
+			}
+			return `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[` + strings.Join(items, ",") + `]}`
+		}
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[` +
+				`{"name":"pods","namespaced":true,"kind":"Pod","verbs":["list"]},` +
+				`{"name":"namespaces","namespaced":false,"kind":"Namespace","verbs":["list"]}]}`))
+		case "/api/v1/namespaces":
+			w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[` +
+				`{"metadata":{"name":"team-b"}},{"metadata":{"name":"kube-system"}},{"metadata":{"name":"team-a"}},{"metadata":{"name":"team-c"}}]}`))
+		case "/api/v1/namespaces/team-a/pods":
+			w.Write([]byte(pods("team-a", "api-1", "api-2")))
+		case "/api/v1/namespaces/team-b/pods":
+			w.Write([]byte(pods("team-b", "web-1")))
+		case "/api/v1/namespaces/team-c/pods":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	guarded, err := client.WithNamespacePolicy(func(namespace string) error {
+		if !strings.HasPrefix(namespace, "team-") {
+			return fmt.Errorf("namespace %q is not in the namespace allowlist", namespace)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pods, skipped, err := guarded.ListResourcesAllNamespaces(context.Background(), "Pod", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod["metadata"].(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "api-1,api-2,web-1" {
+		t.Errorf("Expected the pods of team-a and team-b in namespace order, got %v", names)
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "namespace team-c:") {
+		t.Errorf("Expected the forbidden team-c to be skipped, got %v", skipped)
+	}
+}
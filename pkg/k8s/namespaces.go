@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// ListNamespaces lists the namespaces matching labelSelector, each with its
+// phase, age, labels, the number of pods it holds broken down by phase, and
+// the ResourceQuotas and LimitRanges that constrain it. Pods, quotas and limit
+// ranges are listed once across all namespaces rather than per namespace.
+// Returns a map with the "namespaces" sorted by name, or an error.
+func (c *Client) ListNamespaces(ctx context.Context, labelSelector string) (map[string]interface{}, error) {
+	namespaces, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	pods, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	quotas, err := c.clientset.CoreV1().ResourceQuotas("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	limitRanges, err := c.clientset.CoreV1().LimitRanges("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limit ranges: %w", err)
+	}
+
+	quotaNames := map[string][]string{}
+	for _, quota := range quotas.Items {
+		quotaNames[quota.Namespace] = append(quotaNames[quota.Namespace], quota.Name)
+	}
+	limitRangeNames := map[string][]string{}
+	for _, limitRange := range limitRanges.Items {
+		limitRangeNames[limitRange.Namespace] = append(limitRangeNames[limitRange.Namespace], limitRange.Name)
+	}
+
+	return map[string]interface{}{
+		"namespaces": summarizeNamespaces(namespaces.Items, pods.Items, quotaNames, limitRangeNames, time.Now()),
+	}, nil
+}
+
+// summarizeNamespaces reports each namespace with its pod counts by phase and
+// the names of its quotas and limit ranges, sorted by namespace name.
+func summarizeNamespaces(namespaces []corev1.Namespace, pods []corev1.Pod, quotaNames, limitRangeNames map[string][]string, now time.Time) []map[string]interface{} {
+	podPhases := map[string]map[string]int{}
+	for _, pod := range pods {
+		if podPhases[pod.Namespace] == nil {
+			podPhases[pod.Namespace] = map[string]int{}
+		}
+		podPhases[pod.Namespace][string(pod.Status.Phase)]++
+	}
+
+	summaries := make([]map[string]interface{}, 0, len(namespaces))
+	for _, ns := range namespaces {
+		byPhase := podPhases[ns.Name]
+		if byPhase == nil {
+			byPhase = map[string]int{}
+		}
+		total := 0
+		for _, count := range byPhase {
+			total += count
+		}
+		summary := map[string]interface{}{
+			"name":              ns.Name,
+			"phase":             string(ns.Status.Phase),
+			"creationTimestamp": ns.CreationTimestamp.Time,
+			"age":               duration.HumanDuration(now.Sub(ns.CreationTimestamp.Time)),
+			"pods":              total,
+			"podsByPhase":       byPhase,
+			"resourceQuotas":    nonNilStrings(quotaNames[ns.Name]),
+			"limitRanges":       nonNilStrings(limitRangeNames[ns.Name]),
+			"hasResourceQuota":  len(quotaNames[ns.Name]) > 0,
+		}
+		if len(ns.Labels) > 0 {
+			summary["labels"] = ns.Labels
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i]["name"].(string) < summaries[j]["name"].(string) })
+	return summaries
+}
+
+// nonNilStrings returns list, or an empty list when it is nil, so that it is
+// serialized as [] rather than null.
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
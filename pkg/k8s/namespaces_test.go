@@ -0,0 +1,44 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestSummarizeNamespaces tests counting pods by phase and reporting quotas per namespace
+func TestSummarizeNamespaces(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	namespace := func(name string) corev1.Namespace {
+		return corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-72 * time.Hour))},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		}
+	}
+	pod := func(namespace string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}, Status: corev1.PodStatus{Phase: phase}}
+	}
+
+	summaries := summarizeNamespaces(
+		[]corev1.Namespace{namespace("prod"), namespace("empty")},
+		[]corev1.Pod{pod("prod", corev1.PodRunning), pod("prod", corev1.PodRunning), pod("prod", corev1.PodFailed)},
+		map[string][]string{"prod": {"compute"}},
+		nil,
+		now,
+	)
+	if len(summaries) != 2 || summaries[0]["name"] != "empty" {
+		t.Fatalf("Expected namespaces sorted by name, got %v", summaries)
+	}
+	prod := summaries[1]
+	if prod["pods"] != 3 || prod["podsByPhase"].(map[string]int)["Running"] != 2 {
+		t.Errorf("Unexpected pod counts %v", prod)
+	}
+	if prod["hasResourceQuota"] != true || summaries[0]["hasResourceQuota"] != false {
+		t.Errorf("Unexpected quota flags %v and %v", prod, summaries[0])
+	}
+	if prod["age"] != "3d" || summaries[0]["pods"] != 0 {
+		t.Errorf("Unexpected age or empty pod count: %v, %v", prod["age"], summaries[0]["pods"])
+	}
+}
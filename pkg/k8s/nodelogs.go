@@ -0,0 +1,143 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxNodeLogLines bounds the lines GetNodeLogs returns from a node log.
+const maxNodeLogLines = 5000
+
+// nodeLogLinkPattern matches the links of the directory listing the kubelet
+// serves at /logs/.
+var nodeLogLinkPattern = regexp.MustCompile(`<a href="([^"]+)">`)
+
+// NodeLogOptions selects the node log GetNodeLogs reads and the part of it
+// returned. Query names services such as kubelet or containerd, read through
+// the kubelet's node log query (the NodeLogQuery feature); Path names a file
+// or directory under /var/log on the node, read directly. Without either, the
+// files in /var/log are listed.
+type NodeLogOptions struct {
+	Query        string
+	Path         string
+	SinceSeconds int64
+	TailLines    int
+	Pattern      string
+}
+
+// GetNodeLogs reads the logs of a node through the node proxy subresource's
+// /logs/ endpoint, which the kubelet serves when its system log handler is
+// enabled. Service logs from the journal, or the Windows event log, are read
+// through the node log query; files are read from /var/log and tailed and
+// filtered by pattern here, as the kubelet serves them whole. Directories are
+// listed instead.
+// Returns a map with the "node", "source", and either the "lines" read or the
+// "files" of a directory, or an error.
+func (c *Client) GetNodeLogs(ctx context.Context, nodeName string, opts NodeLogOptions) (map[string]interface{}, error) {
+	if opts.Query != "" && opts.Path != "" {
+		return nil, fmt.Errorf("query and path are mutually exclusive")
+	}
+	tailLines := opts.TailLines
+	if tailLines <= 0 || tailLines > maxNodeLogLines {
+		tailLines = maxNodeLogLines
+	}
+	var pattern *regexp.Regexp
+	if opts.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(opts.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	// A single AbsPath segment keeps its trailing slash, which the kubelet requires for directories
+	logPath := ""
+	source := "/var/log/"
+	if opts.Path != "" {
+		logPath = strings.TrimPrefix(strings.TrimPrefix(opts.Path, "/var/log"), "/")
+		if strings.Contains(logPath, "..") {
+			return nil, fmt.Errorf("invalid path: must not contain '..'")
+		}
+		source = "/var/log/" + logPath
+	}
+	req := c.clientset.CoreV1().RESTClient().Get().AbsPath(fmt.Sprintf("/api/v1/nodes/%s/proxy/logs/%s", nodeName, logPath))
+	if opts.Query != "" {
+		source = "query:" + opts.Query
+		req = req.Param("query", opts.Query).Param("tailLines", strconv.Itoa(tailLines))
+		if opts.SinceSeconds > 0 {
+			req = req.Param("sinceTime", time.Now().Add(-time.Duration(opts.SinceSeconds)*time.Second).UTC().Format(time.RFC3339))
+		}
+		if opts.Pattern != "" {
+			req = req.Param("pattern", opts.Pattern)
+		}
+	}
+
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node logs from %s: %w", source, err)
+	}
+	defer stream.Close()
+
+	result := map[string]interface{}{"node": nodeName, "source": source}
+	// Directories, including /var/log itself, are served as an HTML listing
+	if opts.Query == "" && (logPath == "" || strings.HasSuffix(logPath, "/")) {
+		raw, err := io.ReadAll(io.LimitReader(stream, 1024*1024))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read node log listing: %w", err)
+		}
+		result["files"] = parseNodeLogListing(string(raw))
+		return result, nil
+	}
+
+	lines, total, err := tailMatchingLines(stream, pattern, tailLines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node logs from %s: %w", source, err)
+	}
+	result["lines"] = lines
+	result["truncated"] = total > len(lines)
+	return result, nil
+}
+
+// parseNodeLogListing extracts the entries of the directory listing served at
+// /logs/, sorted by name. Subdirectories keep their trailing slash.
+func parseNodeLogListing(listing string) []string {
+	files := []string{}
+	for _, match := range nodeLogLinkPattern.FindAllStringSubmatch(listing, -1) {
+		if name := match[1]; name != "" && name != "../" {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// tailMatchingLines keeps the last n lines of r that match pattern, or all
+// lines when pattern is nil.
+// Returns the lines kept and the number of matching lines read, or an error.
+func tailMatchingLines(r io.Reader, pattern *regexp.Regexp, n int) ([]string, int, error) {
+	ring := make([]string, 0, n)
+	next, total := 0, 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if pattern != nil && !pattern.MatchString(line) {
+			continue
+		}
+		total++
+		if len(ring) < n {
+			ring = append(ring, line)
+			continue
+		}
+		ring[next] = line
+		next = (next + 1) % n
+	}
+	lines := append(ring[next:len(ring):len(ring)], ring[:next]...)
+	return lines, total, scanner.Err()
+}
@@ -0,0 +1,39 @@
+package k8s
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestParseNodeLogListing tests extracting file names from the kubelet's /logs/ listing
+func TestParseNodeLogListing(t *testing.T) {
+	listing := `<pre>
+<a href="pods/">pods/</a>
+<a href="syslog">syslog</a>
+<a href="containers/">containers/</a>
+<a href="kube-proxy.log">kube-proxy.log</a>
+</pre>`
+	files := parseNodeLogListing(listing)
+	want := []string{"containers/", "kube-proxy.log", "pods/", "syslog"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+}
+
+// TestTailMatchingLines tests keeping the last matching lines of a log
+func TestTailMatchingLines(t *testing.T) {
+	log := "I0101 started\nE0101 failed to pull\nI0101 retry\nE0101 failed again\nE0101 giving up\n"
+	lines, total, err := tailMatchingLines(strings.NewReader(log), regexp.MustCompile(`^E`), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 3 || len(lines) != 2 || lines[0] != "E0101 failed again" || lines[1] != "E0101 giving up" {
+		t.Errorf("Unexpected tail %v of %d lines", lines, total)
+	}
+
+	lines, total, _ = tailMatchingLines(strings.NewReader(log), nil, 10)
+	if total != 5 || len(lines) != 5 || lines[0] != "I0101 started" {
+		t.Errorf("Expected every line in order, got %v", lines)
+	}
+}
@@ -0,0 +1,405 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// mirrorPodAnnotation marks static pods mirrored from a kubelet manifest.
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// GetPodsOnNode lists the pods scheduled on a node using the spec.nodeName field
+// selector. Each pod is reported with its phase, effective resource requests and
+// limits, QoS class and controller, resolving ReplicaSets to their Deployment.
+// Drain-relevant properties (DaemonSet-managed, mirror pod, local storage, no
+// controller) are flagged, and the node's allocatable resources are compared
+// with the total requests.
+// Returns a map with "node", "pods" and "totals", or an error.
+func (c *Client) GetPodsOnNode(ctx context.Context, nodeName string) (map[string]interface{}, error) {
+	node, err := c.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	pods, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node %s: %w", nodeName, err)
+	}
+
+	owners := map[string]map[string]interface{}{}
+	totalRequests := corev1.ResourceList{}
+	totalLimits := corev1.ResourceList{}
+	var podSummaries []map[string]interface{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		requests, limits := podEffectiveResources(pod)
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			addResourceList(totalRequests, requests)
+			addResourceList(totalLimits, limits)
+		}
+
+		controller := c.podController(ctx, pod, owners)
+		_, mirror := pod.Annotations[mirrorPodAnnotation]
+		podSummaries = append(podSummaries, map[string]interface{}{
+			"name":            pod.Name,
+			"namespace":       pod.Namespace,
+			"phase":           string(pod.Status.Phase),
+			"qosClass":        string(pod.Status.QOSClass),
+			"requests":        resourceListStrings(requests),
+			"limits":          resourceListStrings(limits),
+			"controller":      controller,
+			"daemonSet":       controller != nil && controller["kind"] == "DaemonSet",
+			"mirrorPod":       mirror,
+			"hasLocalStorage": podHasLocalStorage(pod),
+			"priorityClass":   pod.Spec.PriorityClassName,
+		})
+	}
+
+	return map[string]interface{}{
+		"node": map[string]interface{}{
+			"name":          node.Name,
+			"unschedulable": node.Spec.Unschedulable,
+			"allocatable":   resourceListStrings(node.Status.Allocatable),
+			"capacity":      resourceListStrings(node.Status.Capacity),
+		},
+		"pods": podSummaries,
+		"totals": map[string]interface{}{
+			"pods":     len(podSummaries),
+			"requests": resourceListStrings(totalRequests),
+			"limits":   resourceListStrings(totalLimits),
+			"requestedPercent": map[string]interface{}{
+				"cpu":    resourcePercent(totalRequests, node.Status.Allocatable, corev1.ResourceCPU),
+				"memory": resourcePercent(totalRequests, node.Status.Allocatable, corev1.ResourceMemory),
+			},
+		},
+	}, nil
+}
+
+// podController returns the kind and name of the pod's controller, resolving
+// ReplicaSets owned by a Deployment to the Deployment. Lookups are cached in
+// owners. Returns nil for pods without a controller.
+func (c *Client) podController(ctx context.Context, pod *corev1.Pod, owners map[string]map[string]interface{}) map[string]interface{} {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil
+	}
+	controller := map[string]interface{}{"kind": ref.Kind, "name": ref.Name}
+	if ref.Kind != "ReplicaSet" {
+		return controller
+	}
+
+	key := pod.Namespace + "/" + ref.Name
+	if owner, ok := owners[key]; ok {
+		return owner
+	}
+	rs, err := c.clientset.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err == nil {
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil {
+			controller = map[string]interface{}{"kind": rsOwner.Kind, "name": rsOwner.Name, "replicaSet": ref.Name}
+		}
+	}
+	owners[key] = controller
+	return controller
+}
+
+// podEffectiveResources returns the pod's effective requests and limits as the
+// scheduler computes them: the larger of the sum of app containers and the
+// largest init container, plus pod overhead.
+func podEffectiveResources(pod *corev1.Pod) (corev1.ResourceList, corev1.ResourceList) {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	for _, ctr := range pod.Spec.Containers {
+		addResourceList(requests, ctr.Resources.Requests)
+		addResourceList(limits, ctr.Resources.Limits)
+	}
+	for _, ctr := range pod.Spec.InitContainers {
+		maxResourceList(requests, ctr.Resources.Requests)
+		maxResourceList(limits, ctr.Resources.Limits)
+	}
+	addResourceList(requests, pod.Spec.Overhead)
+	addResourceList(limits, pod.Spec.Overhead)
+	return requests, limits
+}
+
+// podHasLocalStorage reports whether the pod uses emptyDir volumes, whose data
+// is lost when the pod is evicted.
+func podHasLocalStorage(pod *corev1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// addResourceList adds the quantities in add to total.
+func addResourceList(total, add corev1.ResourceList) {
+	for name, quantity := range add {
+		current := total[name]
+		current.Add(quantity)
+		total[name] = current
+	}
+}
+
+// maxResourceList raises the quantities in total to those in other where larger.
+func maxResourceList(total, other corev1.ResourceList) {
+	for name, quantity := range other {
+		if current, ok := total[name]; !ok || quantity.Cmp(current) > 0 {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// resourceListStrings converts a resource list to a map of quantity strings.
+func resourceListStrings(list corev1.ResourceList) map[string]string {
+	result := map[string]string{}
+	for name, quantity := range list {
+		result[string(name)] = quantity.String()
+	}
+	return result
+}
+
+// resourcePercent returns used as a percentage of available for a resource, or
+// nil when the resource is not available.
+func resourcePercent(used, available corev1.ResourceList, name corev1.ResourceName) interface{} {
+	total, ok := available[name]
+	if !ok || total.IsZero() {
+		return nil
+	}
+	value := used[name]
+	return quantityPercent(value, total)
+}
+
+// quantityPercent returns used as a percentage of total, rounded to one decimal.
+func quantityPercent(used, total resource.Quantity) float64 {
+	percent := float64(used.MilliValue()) / float64(total.MilliValue()) * 100
+	return float64(int(percent*10+0.5)) / 10
+}
+
+// ListNodeMetrics retrieves the CPU and memory usage of every node matching
+// labelSelector from the metrics API and compares it with the node's
+// allocatable resources, like kubectl top nodes. Nodes are sorted by sortBy,
+// "cpu" or "memory" utilization in descending order, or by name. Nodes without
+// metrics, e.g. while their kubelet is unreachable, are listed in "missingMetrics".
+// Returns a map with "nodes" and "missingMetrics", or an error.
+func (c *Client) ListNodeMetrics(ctx context.Context, labelSelector, sortBy string) (map[string]interface{}, error) {
+	nodeList, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	metricsList, err := c.metricsClientset.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node metrics: %w", err)
+	}
+	usage := map[string]corev1.ResourceList{}
+	for _, metrics := range metricsList.Items {
+		usage[metrics.Name] = metrics.Usage
+	}
+
+	nodes := []map[string]interface{}{}
+	missing := []string{}
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		nodeUsage, ok := usage[node.Name]
+		if !ok {
+			missing = append(missing, node.Name)
+			continue
+		}
+		nodes = append(nodes, summarizeNodeUsage(node, nodeUsage))
+	}
+	sortNodeUsage(nodes, sortBy)
+
+	return map[string]interface{}{
+		"nodes":          nodes,
+		"missingMetrics": missing,
+	}, nil
+}
+
+// summarizeNodeUsage reports the usage of a node next to its allocatable and
+// capacity resources, with the usage as a percentage of allocatable.
+func summarizeNodeUsage(node *corev1.Node, usage corev1.ResourceList) map[string]interface{} {
+	return map[string]interface{}{
+		"nodeName": node.Name,
+		"usage": map[string]string{
+			"cpu":    usage.Cpu().String(),
+			"memory": usage.Memory().String(),
+		},
+		"allocatable": map[string]string{
+			"cpu":    node.Status.Allocatable.Cpu().String(),
+			"memory": node.Status.Allocatable.Memory().String(),
+		},
+		"capacity": map[string]string{
+			"cpu":    node.Status.Capacity.Cpu().String(),
+			"memory": node.Status.Capacity.Memory().String(),
+		},
+		"utilizationPercent": map[string]interface{}{
+			"cpu":    resourcePercent(usage, node.Status.Allocatable, corev1.ResourceCPU),
+			"memory": resourcePercent(usage, node.Status.Allocatable, corev1.ResourceMemory),
+		},
+	}
+}
+
+// sortNodeUsage sorts node usage summaries by the utilization of the resource
+// named by sortBy in descending order, or by node name when sortBy is empty.
+func sortNodeUsage(nodes []map[string]interface{}, sortBy string) {
+	utilization := func(node map[string]interface{}) float64 {
+		percents, _ := node["utilizationPercent"].(map[string]interface{})
+		percent, _ := percents[sortBy].(float64)
+		return percent
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if sortBy == "cpu" || sortBy == "memory" {
+			if a, b := utilization(nodes[i]), utilization(nodes[j]); a != b {
+				return a > b
+			}
+		}
+		return nodes[i]["nodeName"].(string) < nodes[j]["nodeName"].(string)
+	})
+}
+
+// GetEvictionRisk classifies running pods by QoS class per node and ranks them
+// in the order the kubelet would evict them under memory pressure: pods whose
+// memory usage exceeds their request first, then by ascending priority, then
+// by how far usage exceeds the request. Usage comes from the metrics API; when
+// it is unavailable, pods are ranked by QoS class and priority instead.
+// If nodeName is set, only that node is reported; topN limits the ranking per node.
+// Returns a map with "nodes" and "usageSource", or an error.
+func (c *Client) GetEvictionRisk(ctx context.Context, nodeName string, topN int) (map[string]interface{}, error) {
+	if topN <= 0 {
+		topN = 10
+	}
+
+	opts := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("status.phase", string(corev1.PodRunning)).String()}
+	if nodeName != "" {
+		opts.FieldSelector = fields.AndSelectors(
+			fields.OneTermEqualSelector("status.phase", string(corev1.PodRunning)),
+			fields.OneTermEqualSelector("spec.nodeName", nodeName),
+		).String()
+	}
+	pods, err := c.clientset.CoreV1().Pods("").List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	usageSource := "metrics.k8s.io"
+	usage := map[string]resource.Quantity{}
+	podMetrics, err := c.metricsClientset.MetricsV1beta1().PodMetricses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		usageSource = "unavailable"
+	} else {
+		for _, pm := range podMetrics.Items {
+			total := resource.Quantity{}
+			for _, ctr := range pm.Containers {
+				total.Add(ctr.Usage[corev1.ResourceMemory])
+			}
+			usage[pm.Namespace+"/"+pm.Name] = total
+		}
+	}
+
+	type candidate struct {
+		entry       map[string]interface{}
+		exceeds     bool
+		priority    int32
+		overRequest int64
+		qosRank     int
+	}
+	qosRanks := map[corev1.PodQOSClass]int{corev1.PodQOSBestEffort: 0, corev1.PodQOSBurstable: 1, corev1.PodQOSGuaranteed: 2}
+
+	byNode := map[string][]candidate{}
+	qosCounts := map[string]map[string]int{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		qos := pod.Status.QOSClass
+		if qosCounts[pod.Spec.NodeName] == nil {
+			qosCounts[pod.Spec.NodeName] = map[string]int{}
+		}
+		qosCounts[pod.Spec.NodeName][string(qos)]++
+
+		requests, _ := podEffectiveResources(pod)
+		request := requests[corev1.ResourceMemory]
+		var priority int32
+		if pod.Spec.Priority != nil {
+			priority = *pod.Spec.Priority
+		}
+		entry := map[string]interface{}{
+			"pod":           pod.Name,
+			"namespace":     pod.Namespace,
+			"qosClass":      string(qos),
+			"priority":      priority,
+			"memoryRequest": request.String(),
+		}
+		cand := candidate{entry: entry, priority: priority, qosRank: qosRanks[qos]}
+		if used, ok := usage[pod.Namespace+"/"+pod.Name]; ok {
+			cand.overRequest = used.Value() - request.Value()
+			cand.exceeds = cand.overRequest > 0
+			entry["memoryUsage"] = used.String()
+			entry["exceedsRequest"] = cand.exceeds
+		}
+		byNode[pod.Spec.NodeName] = append(byNode[pod.Spec.NodeName], cand)
+	}
+
+	nodeNames := make([]string, 0, len(byNode))
+	for name := range byNode {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+
+	var nodes []map[string]interface{}
+	for _, name := range nodeNames {
+		candidates := byNode[name]
+		sort.SliceStable(candidates, func(i, j int) bool {
+			a, b := candidates[i], candidates[j]
+			if usageSource == "unavailable" {
+				if a.qosRank != b.qosRank {
+					return a.qosRank < b.qosRank
+				}
+				return a.priority < b.priority
+			}
+			if a.exceeds != b.exceeds {
+				return a.exceeds
+			}
+			if a.priority != b.priority {
+				return a.priority < b.priority
+			}
+			return a.overRequest > b.overRequest
+		})
+
+		var ranking []map[string]interface{}
+		for i, cand := range candidates {
+			if i >= topN {
+				break
+			}
+			cand.entry["rank"] = i + 1
+			ranking = append(ranking, cand.entry)
+		}
+
+		report := map[string]interface{}{
+			"name":      name,
+			"qosCounts": qosCounts[name],
+			"ranking":   ranking,
+		}
+		if node, err := c.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{}); err == nil {
+			report["memoryPressure"] = nodeConditionTrue(node, corev1.NodeMemoryPressure)
+			if allocatable, ok := node.Status.Allocatable[corev1.ResourceMemory]; ok {
+				report["allocatableMemory"] = allocatable.String()
+			}
+		}
+		nodes = append(nodes, report)
+	}
+
+	return map[string]interface{}{
+		"usageSource": usageSource,
+		"nodes":       nodes,
+	}, nil
+}
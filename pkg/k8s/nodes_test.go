@@ -0,0 +1,180 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestSummarizeNodeUsage tests computing node utilization against allocatable resources and sorting by it
+func TestSummarizeNodeUsage(t *testing.T) {
+	newNode := func(name, cpu, memory string) *corev1.Node {
+		allocatable := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{Allocatable: allocatable, Capacity: allocatable},
+		}
+	}
+	usage := func(cpu, memory string) corev1.ResourceList {
+		return corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}
+	}
+
+	nodes := []map[string]interface{}{
+		summarizeNodeUsage(newNode("node-a", "4", "8Gi"), usage("1", "6Gi")),
+		summarizeNodeUsage(newNode("node-b", "2", "8Gi"), usage("1500m", "2Gi")),
+	}
+	percents := nodes[0]["utilizationPercent"].(map[string]interface{})
+	if percents["cpu"] != 25.0 || percents["memory"] != 75.0 {
+		t.Errorf("Unexpected utilization %v", percents)
+	}
+
+	sortNodeUsage(nodes, "cpu")
+	if nodes[0]["nodeName"] != "node-b" {
+		t.Errorf("Expected node-b first by CPU, got %v", nodes[0]["nodeName"])
+	}
+	sortNodeUsage(nodes, "memory")
+	if nodes[0]["nodeName"] != "node-a" {
+		t.Errorf("Expected node-a first by memory, got %v", nodes[0]["nodeName"])
+	}
+	sortNodeUsage(nodes, "")
+	if nodes[0]["nodeName"] != "node-a" {
+		t.Errorf("Expected node-a first by name, got %v", nodes[0]["nodeName"])
+	}
+}
+
+// TestGetPodsOnNode tests summarizing the pods on a node with their effective requests, controllers and drain flags
+func TestGetPodsOnNode(t *testing.T) {
+	var fieldSelector string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/nodes/worker-1":
+			w.Write([]byte(`{"kind":"Node","apiVersion":"v1","metadata":{"name":"worker-1"},"spec":{"unschedulable":true},` +
+				`"status":{"allocatable":{"cpu":"2","memory":"4Gi"},"capacity":{"cpu":"2","memory":"4Gi"}}}`))
+		case "/api/v1/pods":
+			fieldSelector = r.URL.Query().Get("fieldSelector")
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"api-1-a","namespace":"web","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"api-1","uid":"rs1","controller":true}]},` +
+				`"spec":{"initContainers":[{"name":"init","resources":{"requests":{"cpu":"1"}}}],` +
+				`"containers":[{"name":"app","resources":{"requests":{"cpu":"250m","memory":"1Gi"}}},{"name":"proxy","resources":{"requests":{"cpu":"250m"}}}],` +
+				`"volumes":[{"name":"cache","emptyDir":{}}]},"status":{"phase":"Running","qosClass":"Burstable"}},` +
+				`{"metadata":{"name":"logs-x","namespace":"kube-system","ownerReferences":[{"apiVersion":"apps/v1","kind":"DaemonSet","name":"logs","uid":"ds1","controller":true}]},` +
+				`"spec":{"containers":[{"name":"agent","resources":{"requests":{"cpu":"500m"}}}]},"status":{"phase":"Running"}},` +
+				`{"metadata":{"name":"done","namespace":"web"},"spec":{"containers":[{"name":"job","resources":{"requests":{"cpu":"2"}}}]},"status":{"phase":"Succeeded"}}]}`))
+		case "/apis/apps/v1/namespaces/web/replicasets/api-1":
+			w.Write([]byte(`{"kind":"ReplicaSet","apiVersion":"apps/v1","metadata":{"name":"api-1","namespace":"web",` +
+				`"ownerReferences":[{"apiVersion":"apps/v1","kind":"Deployment","name":"api","uid":"d1","controller":true}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.GetPodsOnNode(context.Background(), "worker-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fieldSelector != "spec.nodeName=worker-1" {
+		t.Errorf("Expected the pods to be selected by node name, got %q", fieldSelector)
+	}
+	pods := result["pods"].([]map[string]interface{})
+	if len(pods) != 3 {
+		t.Fatalf("Expected all pods on the node, got %v", pods)
+	}
+	api := pods[0]
+	if controller := api["controller"].(map[string]interface{}); controller["kind"] != "Deployment" || controller["name"] != "api" || controller["replicaSet"] != "api-1" {
+		t.Errorf("Expected the ReplicaSet to be resolved to its Deployment, got %v", controller)
+	}
+	if requests := api["requests"].(map[string]string); requests["cpu"] != "1" || requests["memory"] != "1Gi" || api["hasLocalStorage"] != true {
+		t.Errorf("Expected the init container to raise the effective CPU request and local storage to be flagged, got %v", api)
+	}
+	if pods[1]["daemonSet"] != true || pods[2]["controller"].(map[string]interface{}) != nil {
+		t.Errorf("Expected the DaemonSet pod and the pod without a controller to be flagged, got %v", pods)
+	}
+
+	totals := result["totals"].(map[string]interface{})
+	if requests := totals["requests"].(map[string]string); requests["cpu"] != "1500m" {
+		t.Errorf("Expected completed pods to be excluded from the totals, got %v", requests)
+	}
+	if percent := totals["requestedPercent"].(map[string]interface{}); percent["cpu"] != 75.0 || percent["memory"] != 25.0 {
+		t.Errorf("Expected the requests as a percentage of allocatable, got %v", percent)
+	}
+	if node := result["node"].(map[string]interface{}); node["unschedulable"] != true {
+		t.Errorf("Expected the node to be reported as cordoned, got %v", node)
+	}
+
+	if _, err := client.GetPodsOnNode(context.Background(), "missing"); err == nil {
+		t.Error("Expected an error for a missing node")
+	}
+}
+
+// TestGetEvictionRisk tests ranking pods by the kubelet's memory-pressure eviction order
+func TestGetEvictionRisk(t *testing.T) {
+	metricsAvailable := true
+	var fieldSelector string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/pods":
+			fieldSelector = r.URL.Query().Get("fieldSelector")
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"guaranteed","namespace":"web"},"spec":{"nodeName":"worker-1","containers":[{"name":"app","resources":{"requests":{"memory":"1Gi"}}}]},` +
+				`"status":{"qosClass":"Guaranteed"}},` +
+				`{"metadata":{"name":"besteffort","namespace":"web"},"spec":{"nodeName":"worker-1","containers":[{"name":"app"}]},"status":{"qosClass":"BestEffort"}},` +
+				`{"metadata":{"name":"critical","namespace":"kube-system"},"spec":{"nodeName":"worker-1","priority":2000000000,"containers":[{"name":"app"}]},` +
+				`"status":{"qosClass":"BestEffort"}}]}`))
+		case r.URL.Path == "/apis/metrics.k8s.io/v1beta1/pods" && metricsAvailable:
+			w.Write([]byte(`{"kind":"PodMetricsList","apiVersion":"metrics.k8s.io/v1beta1","items":[` +
+				`{"metadata":{"name":"guaranteed","namespace":"web"},"containers":[{"name":"app","usage":{"memory":"2Gi"}}]},` +
+				`{"metadata":{"name":"besteffort","namespace":"web"},"containers":[{"name":"app","usage":{"memory":"100Mi"}}]},` +
+				`{"metadata":{"name":"critical","namespace":"kube-system"},"containers":[{"name":"app","usage":{"memory":"50Mi"}}]}]}`))
+		case r.URL.Path == "/api/v1/nodes/worker-1":
+			w.Write([]byte(`{"kind":"Node","apiVersion":"v1","metadata":{"name":"worker-1"},` +
+				`"status":{"allocatable":{"memory":"8Gi"},"conditions":[{"type":"MemoryPressure","status":"True"}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	ranking := func(result map[string]interface{}) []interface{} {
+		nodes := result["nodes"].([]map[string]interface{})
+		if len(nodes) != 1 || nodes[0]["memoryPressure"] != true {
+			t.Fatalf("Expected the node under memory pressure, got %v", nodes)
+		}
+		var pods []interface{}
+		for _, entry := range nodes[0]["ranking"].([]map[string]interface{}) {
+			pods = append(pods, entry["pod"])
+		}
+		return pods
+	}
+
+	result, err := client.GetEvictionRisk(ctx, "worker-1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fieldSelector != "status.phase=Running,spec.nodeName=worker-1" {
+		t.Errorf("Expected running pods on the node to be selected, got %q", fieldSelector)
+	}
+	if pods := ranking(result); len(pods) != 3 || pods[0] != "guaranteed" || pods[1] != "besteffort" || pods[2] != "critical" {
+		t.Errorf("Expected pods exceeding their request first, then by priority, got %v", pods)
+	}
+
+	metricsAvailable = false
+	result, err = client.GetEvictionRisk(ctx, "worker-1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pods := ranking(result); result["usageSource"] != "unavailable" || len(pods) != 2 || pods[0] != "besteffort" || pods[1] != "critical" {
+		t.Errorf("Expected pods ranked by QoS class and priority without metrics, got %v", pods)
+	}
+}
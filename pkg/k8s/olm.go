@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Operator Lifecycle Manager resources.
+var (
+	olmSubscriptionGVR = schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "subscriptions"}
+	olmInstallPlanGVR  = schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "installplans"}
+	olmCSVGVR          = schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "clusterserviceversions"}
+)
+
+// GetOLMSubscriptions reports Operator Lifecycle Manager Subscriptions with the
+// InstallPlans and ClusterServiceVersions they reference, flagging InstallPlans
+// pending manual approval and CSVs or InstallPlans that failed.
+// Returns a map with "subscriptions", "pendingApprovals" and "failures", or an
+// error if OLM is not installed.
+func (c *Client) GetOLMSubscriptions(ctx context.Context, namespace string) (map[string]interface{}, error) {
+	subscriptions, err := c.dynamicClient.Resource(olmSubscriptionGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OLM subscriptions (is OLM installed?): %w", err)
+	}
+	installPlans, err := c.dynamicClient.Resource(olmInstallPlanGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OLM install plans: %w", err)
+	}
+	csvs, err := c.dynamicClient.Resource(olmCSVGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterServiceVersions: %w", err)
+	}
+
+	plansByKey := map[string]map[string]interface{}{}
+	var pendingApprovals, failures []map[string]interface{}
+	for _, plan := range installPlans.Items {
+		content := plan.UnstructuredContent()
+		plansByKey[plan.GetNamespace()+"/"+plan.GetName()] = content
+
+		phase, _, _ := unstructured.NestedString(content, "status", "phase")
+		approval, _, _ := unstructured.NestedString(content, "spec", "approval")
+		approved, _, _ := unstructured.NestedBool(content, "spec", "approved")
+		csvNames, _, _ := unstructured.NestedStringSlice(content, "spec", "clusterServiceVersionNames")
+
+		if phase == "RequiresApproval" || (approval == "Manual" && !approved && phase != "Complete") {
+			pendingApprovals = append(pendingApprovals, map[string]interface{}{
+				"installPlan": plan.GetName(),
+				"namespace":   plan.GetNamespace(),
+				"csvs":        csvNames,
+				"phase":       phase,
+			})
+		}
+		if phase == "Failed" {
+			failures = append(failures, map[string]interface{}{
+				"kind":       "InstallPlan",
+				"name":       plan.GetName(),
+				"namespace":  plan.GetNamespace(),
+				"csvs":       csvNames,
+				"conditions": unhealthyConditions(content),
+				"message":    nestedValue(content, "status", "message"),
+			})
+		}
+	}
+
+	csvsByKey := map[string]map[string]interface{}{}
+	for _, csv := range csvs.Items {
+		content := csv.UnstructuredContent()
+		// Copied CSVs are projected into every target namespace; report the originals only.
+		if csv.GetLabels()["olm.copiedFrom"] != "" {
+			continue
+		}
+		csvsByKey[csv.GetNamespace()+"/"+csv.GetName()] = content
+
+		phase, _, _ := unstructured.NestedString(content, "status", "phase")
+		if phase == "Failed" || phase == "Unknown" {
+			failures = append(failures, map[string]interface{}{
+				"kind":      "ClusterServiceVersion",
+				"name":      csv.GetName(),
+				"namespace": csv.GetNamespace(),
+				"phase":     phase,
+				"reason":    nestedValue(content, "status", "reason"),
+				"message":   nestedValue(content, "status", "message"),
+			})
+		}
+	}
+
+	var subscriptionSummaries []map[string]interface{}
+	for _, sub := range subscriptions.Items {
+		content := sub.UnstructuredContent()
+		installedCSV, _, _ := unstructured.NestedString(content, "status", "installedCSV")
+		currentCSV, _, _ := unstructured.NestedString(content, "status", "currentCSV")
+		planName, _, _ := unstructured.NestedString(content, "status", "installPlanRef", "name")
+
+		summary := map[string]interface{}{
+			"name":                sub.GetName(),
+			"namespace":           sub.GetNamespace(),
+			"package":             nestedValue(content, "spec", "name"),
+			"channel":             nestedValue(content, "spec", "channel"),
+			"source":              nestedValue(content, "spec", "source"),
+			"installPlanApproval": nestedValue(content, "spec", "installPlanApproval"),
+			"state":               nestedValue(content, "status", "state"),
+			"installedCSV":        installedCSV,
+			"currentCSV":          currentCSV,
+			"upgradePending":      currentCSV != "" && installedCSV != "" && currentCSV != installedCSV,
+			"unhealthyConditions": unhealthyConditions(content),
+		}
+
+		if csv, ok := csvsByKey[sub.GetNamespace()+"/"+installedCSV]; ok {
+			summary["installedCSVPhase"] = nestedValue(csv, "status", "phase")
+		}
+		if csv, ok := csvsByKey[sub.GetNamespace()+"/"+currentCSV]; ok && currentCSV != installedCSV {
+			summary["currentCSVPhase"] = nestedValue(csv, "status", "phase")
+			summary["currentCSVMessage"] = nestedValue(csv, "status", "message")
+		}
+		if plan, ok := plansByKey[sub.GetNamespace()+"/"+planName]; ok {
+			summary["installPlan"] = map[string]interface{}{
+				"name":     planName,
+				"phase":    nestedValue(plan, "status", "phase"),
+				"approval": nestedValue(plan, "spec", "approval"),
+				"approved": nestedValue(plan, "spec", "approved"),
+			}
+		}
+
+		subscriptionSummaries = append(subscriptionSummaries, summary)
+	}
+
+	return map[string]interface{}{
+		"subscriptions":    subscriptionSummaries,
+		"pendingApprovals": pendingApprovals,
+		"failures":         failures,
+	}, nil
+}
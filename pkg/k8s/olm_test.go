@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// TestGetOLMSubscriptions tests joining Subscriptions with their InstallPlans and CSVs and flagging pending approvals and failures
+func TestGetOLMSubscriptions(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/operators.coreos.com/v1alpha1/namespaces/operators/subscriptions":
+			w.Write([]byte(`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"SubscriptionList","items":[` +
+				`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"Subscription","metadata":{"name":"etcd","namespace":"operators"},` +
+				`"spec":{"name":"etcd","channel":"stable","source":"community","installPlanApproval":"Manual"},` +
+				`"status":{"state":"UpgradePending","installedCSV":"etcd.v1","currentCSV":"etcd.v2","installPlanRef":{"name":"install-2"}}}]}`))
+		case "/apis/operators.coreos.com/v1alpha1/namespaces/operators/installplans":
+			w.Write([]byte(`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"InstallPlanList","items":[` +
+				`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"InstallPlan","metadata":{"name":"install-1","namespace":"operators"},` +
+				`"spec":{"approval":"Manual","approved":true,"clusterServiceVersionNames":["etcd.v1"]},"status":{"phase":"Complete"}},` +
+				`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"InstallPlan","metadata":{"name":"install-2","namespace":"operators"},` +
+				`"spec":{"approval":"Manual","approved":false,"clusterServiceVersionNames":["etcd.v2"]},"status":{"phase":"RequiresApproval"}}]}`))
+		case "/apis/operators.coreos.com/v1alpha1/namespaces/operators/clusterserviceversions":
+			w.Write([]byte(`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"ClusterServiceVersionList","items":[` +
+				`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"ClusterServiceVersion","metadata":{"name":"etcd.v1","namespace":"operators"},"status":{"phase":"Succeeded"}},` +
+				`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"ClusterServiceVersion","metadata":{"name":"etcd.v2","namespace":"operators"},` +
+				`"status":{"phase":"Failed","reason":"InstallCheckFailed","message":"install timeout"}},` +
+				`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"ClusterServiceVersion","metadata":{"name":"cert.v1","namespace":"operators","labels":{"olm.copiedFrom":"cert"}},` +
+				`"status":{"phase":"Failed"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.GetOLMSubscriptions(context.Background(), "operators")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscriptions := result["subscriptions"].([]map[string]interface{})
+	if len(subscriptions) != 1 {
+		t.Fatalf("Expected one subscription, got %v", subscriptions)
+	}
+	etcd := subscriptions[0]
+	if etcd["upgradePending"] != true || etcd["installedCSVPhase"] != "Succeeded" || etcd["currentCSVPhase"] != "Failed" || etcd["currentCSVMessage"] != "install timeout" {
+		t.Errorf("Expected the pending upgrade with the phases of both CSVs, got %v", etcd)
+	}
+	if plan, _ := etcd["installPlan"].(map[string]interface{}); plan["name"] != "install-2" || plan["approved"] != false {
+		t.Errorf("Expected the referenced install plan, got %v", etcd["installPlan"])
+	}
+	if pending := result["pendingApprovals"].([]map[string]interface{}); len(pending) != 1 || pending[0]["installPlan"] != "install-2" {
+		t.Errorf("Expected only the unapproved install plan to be pending, got %v", pending)
+	}
+	failures := result["failures"].([]map[string]interface{})
+	if len(failures) != 1 || failures[0]["name"] != "etcd.v2" || failures[0]["reason"] != "InstallCheckFailed" {
+		t.Errorf("Expected only the original failed CSV to be reported, got %v", failures)
+	}
+
+	if _, err := client.GetOLMSubscriptions(context.Background(), "other"); err == nil {
+		t.Error("Expected an error when OLM is not installed")
+	}
+}
@@ -0,0 +1,147 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Operations supported by ExecutePlan.
+const (
+	PlanOperationScale         = "scale"
+	PlanOperationSetImage      = "setImage"
+	PlanOperationApplyManifest = "applyManifest"
+)
+
+// PlanStep is a single mutating operation of a plan run by ExecutePlan.
+type PlanStep struct {
+	Operation string `json:"operation"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// Replicas is the target replica count of a scale step.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Container and Image are the target of a setImage step.
+	Container string `json:"container,omitempty"`
+	Image     string `json:"image,omitempty"`
+	// Manifest is the YAML or JSON manifest of an applyManifest step.
+	Manifest string `json:"manifest,omitempty"`
+	// DryRun submits this step as a server-side dry run.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// validate checks that the step names a supported operation and carries the
+// parameters it requires.
+func (s PlanStep) validate() error {
+	switch s.Operation {
+	case PlanOperationScale:
+		if s.Kind == "" || s.Name == "" || s.Replicas == nil {
+			return fmt.Errorf("scale requires kind, name and replicas")
+		}
+	case PlanOperationSetImage:
+		if s.Kind == "" || s.Name == "" || s.Image == "" {
+			return fmt.Errorf("setImage requires kind, name and image")
+		}
+	case PlanOperationApplyManifest:
+		if s.Manifest == "" {
+			return fmt.Errorf("applyManifest requires manifest")
+		}
+	default:
+		return fmt.Errorf("unsupported operation %q; must be one of %s, %s, %s",
+			s.Operation, PlanOperationScale, PlanOperationSetImage, PlanOperationApplyManifest)
+	}
+	return nil
+}
+
+// ExecutePlan runs an ordered list of mutating operations and records a
+// transcript of each step's outcome. All steps are validated before any of
+// them runs. Steps run as server-side dry runs if dryRun is set or the step
+// requests it. If stopOnError is set, the first failing step stops the plan
+// and the remaining steps are reported as skipped; otherwise every step runs.
+// Returns a map with the "transcript" and a "summary" of the run, or an error
+// if a step is invalid.
+func (c *Client) ExecutePlan(ctx context.Context, steps []PlanStep, dryRun, stopOnError bool) (map[string]interface{}, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("plan has no steps")
+	}
+	for i, step := range steps {
+		if err := step.validate(); err != nil {
+			return nil, fmt.Errorf("invalid step %d: %w", i+1, err)
+		}
+	}
+
+	transcript := make([]map[string]interface{}, 0, len(steps))
+	succeeded, failed, skipped := 0, 0, 0
+	stopped := false
+	for i, step := range steps {
+		entry := map[string]interface{}{
+			"step":      i + 1,
+			"operation": step.Operation,
+			"dryRun":    dryRun || step.DryRun,
+		}
+		if step.Name != "" {
+			entry["target"] = fmt.Sprintf("%s/%s", step.Kind, step.Name)
+		}
+		if stopped {
+			entry["status"] = "skipped"
+			skipped++
+			transcript = append(transcript, entry)
+			continue
+		}
+
+		stepCtx := ctx
+		if dryRun || step.DryRun {
+			stepCtx = WithDryRun(ctx)
+		}
+		start := time.Now()
+		result, err := c.executePlanStep(stepCtx, step)
+		entry["elapsedMs"] = time.Since(start).Milliseconds()
+		if err != nil {
+			entry["status"] = "failed"
+			entry["error"] = err.Error()
+			failed++
+			stopped = stopOnError
+		} else {
+			entry["status"] = "succeeded"
+			entry["result"] = result
+			succeeded++
+		}
+		transcript = append(transcript, entry)
+	}
+
+	return map[string]interface{}{
+		"transcript": transcript,
+		"summary": map[string]interface{}{
+			"steps":     len(steps),
+			"succeeded": succeeded,
+			"failed":    failed,
+			"skipped":   skipped,
+			"completed": failed == 0,
+		},
+	}, nil
+}
+
+// executePlanStep runs a single validated plan step.
+func (c *Client) executePlanStep(ctx context.Context, step PlanStep) (map[string]interface{}, error) {
+	switch step.Operation {
+	case PlanOperationScale:
+		return c.ScaleResource(ctx, step.Namespace, step.Kind, step.Name, *step.Replicas)
+	case PlanOperationSetImage:
+		return c.SetImage(ctx, step.Namespace, step.Kind, step.Name, step.Container, step.Image)
+	default:
+		applied, err := c.CreateOrUpdateResourceYAML(ctx, step.Namespace, step.Manifest, step.Kind)
+		if err != nil {
+			return nil, err
+		}
+		obj := &unstructured.Unstructured{Object: applied}
+		return map[string]interface{}{
+			"kind":            obj.GetKind(),
+			"name":            obj.GetName(),
+			"namespace":       obj.GetNamespace(),
+			"resourceVersion": obj.GetResourceVersion(),
+			"dryRun":          IsDryRun(ctx),
+		}, nil
+	}
+}
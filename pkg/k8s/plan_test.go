@@ -0,0 +1,122 @@
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestExecutePlanManifestNamespace tests that a step's namespace overrides the namespace of its manifest
+func TestExecutePlanManifestNamespace(t *testing.T) {
+	var patched, body string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case r.URL.Path == "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+		case r.URL.Path == "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[{"name":"configmaps","namespaced":true,"kind":"ConfigMap","verbs":["get","patch","create"]}]}`))
+		case r.Method == http.MethodPatch:
+			data, _ := io.ReadAll(r.Body)
+			patched, body = r.URL.Path, string(data)
+			w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c","namespace":"team-a"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	steps := []PlanStep{{
+		Operation: PlanOperationApplyManifest,
+		Namespace: "team-a",
+		Manifest:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n  namespace: kube-system\n",
+	}}
+	result, err := client.ExecutePlan(context.Background(), steps, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary := result["summary"].(map[string]interface{}); summary["succeeded"] != 1 {
+		t.Fatalf("Expected the step to succeed, got %v", result)
+	}
+	if patched != "/api/v1/namespaces/team-a/configmaps/c" {
+		t.Errorf("Expected the manifest to be applied in the step's namespace, got %s", patched)
+	}
+	if strings.Contains(body, "kube-system") {
+		t.Errorf("Expected the patch to carry the step's namespace, got %s", body)
+	}
+}
+
+// TestExecutePlan tests validation, the read-only gate, dry runs and the handling of failing steps
+func TestExecutePlan(t *testing.T) {
+	scale := "/apis/apps/v1/namespaces/web/deployments/api/scale"
+	objects := map[string]string{
+		"/apis/apps/v1/namespaces/web/deployments/api": testDeployment,
+		scale: `{"apiVersion":"autoscaling/v1","kind":"Scale","metadata":{"name":"api","namespace":"web"},"spec":{"replicas":2}}`,
+	}
+	client, mutations := newWorkloadTestClient(t, objects)
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	replicas := int32(3)
+	steps := []PlanStep{
+		{Operation: PlanOperationScale, Kind: "Deployment", Name: "api", Namespace: "web", Replicas: &replicas},
+		{Operation: PlanOperationSetImage, Kind: "Deployment", Name: "api", Namespace: "web", Container: "app", Image: "api:2"},
+	}
+	statuses := func(result map[string]interface{}) []interface{} {
+		var statuses []interface{}
+		for _, entry := range result["transcript"].([]map[string]interface{}) {
+			statuses = append(statuses, entry["status"])
+		}
+		return statuses
+	}
+
+	invalid := append(steps[:1:1], PlanStep{Operation: PlanOperationSetImage, Kind: "Deployment", Name: "api"})
+	if _, err := client.ExecutePlan(ctx, invalid, false, true); err == nil || !strings.Contains(err.Error(), "invalid step 2") {
+		t.Errorf("Expected the invalid step to be reported, got %v", err)
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("Expected no step to run when one is invalid, got %v", *mutations)
+	}
+
+	result, err := readOnly.ExecutePlan(ctx, steps, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transcript := result["transcript"].([]map[string]interface{})
+	if s := statuses(result); s[0] != "failed" || s[1] != "skipped" || !strings.Contains(transcript[0]["error"].(string), "read-only mode") {
+		t.Errorf("Expected the read-only client to refuse the first step and skip the rest, got %v", transcript)
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("Expected refused steps not to reach the server, got %v", *mutations)
+	}
+
+	result, err = readOnly.ExecutePlan(ctx, steps, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary := result["summary"].(map[string]interface{}); summary["succeeded"] != 2 || summary["completed"] != true {
+		t.Errorf("Expected a dry run of the plan to pass in read-only mode, got %v", result)
+	}
+	if len(*mutations) != 2 || (*mutations)[0].path != scale || (*mutations)[0].dryRun != "All" || (*mutations)[1].dryRun != "All" {
+		t.Errorf("Expected both steps to be submitted as dry runs, got %v", *mutations)
+	}
+
+	failing := []PlanStep{
+		{Operation: PlanOperationSetImage, Kind: "Deployment", Name: "api", Namespace: "web", Container: "sidecar", Image: "api:2"},
+		steps[0],
+	}
+	result, err = client.ExecutePlan(ctx, failing, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := statuses(result); s[0] != "failed" || s[1] != "succeeded" || result["summary"].(map[string]interface{})["completed"] != false {
+		t.Errorf("Expected the plan to continue past the failing step, got %v", result)
+	}
+	if len(*mutations) != 3 || (*mutations)[2].path != scale || (*mutations)[2].dryRun != "" {
+		t.Errorf("Expected the second step to be applied, got %v", *mutations)
+	}
+}
@@ -0,0 +1,406 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Well-known node labels describing the node platform.
+const (
+	nodeArchLabel = "kubernetes.io/arch"
+	nodeOSLabel   = "kubernetes.io/os"
+)
+
+// registryHTTPClient is used to fetch image manifests when image platforms are inspected.
+var registryHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// CheckPlatformCompatibility cross-references the OS/architecture of
+// schedulable nodes against the nodeSelector and required node affinity of
+// pods, and optionally against the platforms published for their images, to
+// flag pods that can never schedule or run on the available architectures.
+// If inspectImages is set, image manifests are fetched anonymously from their
+// registries; images that cannot be inspected are reported as unknown.
+// Returns a map with the available node platforms and the findings, or an error.
+func (c *Client) CheckPlatformCompatibility(ctx context.Context, namespace, labelSelector string, inspectImages bool) (map[string]interface{}, error) {
+	nodes, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodePlatforms := map[string]int{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		nodePlatforms[nodePlatform(&node)]++
+	}
+
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	imagePlatforms := map[string][]string{}
+	imageErrors := map[string]string{}
+	var findings []map[string]interface{}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		var allowed []string
+		for platform := range nodePlatforms {
+			if podAllowsPlatform(&pod, platform) {
+				allowed = append(allowed, platform)
+			}
+		}
+		sort.Strings(allowed)
+
+		owner := ""
+		if ref := metav1.GetControllerOf(&pod); ref != nil {
+			owner = ref.Kind + "/" + ref.Name
+		}
+
+		if len(allowed) == 0 {
+			findings = append(findings, map[string]interface{}{
+				"pod":       pod.Name,
+				"namespace": pod.Namespace,
+				"owner":     owner,
+				"phase":     pod.Status.Phase,
+				"reason":    "no schedulable node matches the pod's OS/architecture nodeSelector or affinity",
+			})
+			continue
+		}
+
+		if !inspectImages {
+			continue
+		}
+
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			if _, seen := imagePlatforms[container.Image]; !seen {
+				if _, failed := imageErrors[container.Image]; !failed {
+					platforms, err := fetchImagePlatforms(ctx, container.Image)
+					if err != nil {
+						imageErrors[container.Image] = err.Error()
+					} else {
+						imagePlatforms[container.Image] = platforms
+					}
+				}
+			}
+			platforms, ok := imagePlatforms[container.Image]
+			if !ok {
+				continue
+			}
+			if !platformsIntersect(platforms, allowed) {
+				findings = append(findings, map[string]interface{}{
+					"pod":              pod.Name,
+					"namespace":        pod.Namespace,
+					"owner":            owner,
+					"phase":            pod.Status.Phase,
+					"container":        container.Name,
+					"image":            container.Image,
+					"imagePlatforms":   platforms,
+					"allowedPlatforms": allowed,
+					"reason":           "image is not published for any platform the pod can schedule on",
+				})
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"nodePlatforms": nodePlatforms,
+		"findings":      findings,
+	}
+	if inspectImages {
+		result["imagePlatforms"] = imagePlatforms
+		result["uninspectableImages"] = imageErrors
+	}
+	return result, nil
+}
+
+// nodePlatform returns the "os/arch" platform of a node from its labels,
+// falling back to the kubelet-reported node info.
+func nodePlatform(node *corev1.Node) string {
+	os := node.Labels[nodeOSLabel]
+	if os == "" {
+		os = node.Status.NodeInfo.OperatingSystem
+	}
+	arch := node.Labels[nodeArchLabel]
+	if arch == "" {
+		arch = node.Status.NodeInfo.Architecture
+	}
+	return os + "/" + arch
+}
+
+// podAllowsPlatform reports whether the pod's nodeSelector and required node
+// affinity permit an "os/arch" platform. Only the platform labels are considered.
+func podAllowsPlatform(pod *corev1.Pod, platform string) bool {
+	os, arch, _ := strings.Cut(platform, "/")
+	values := map[string]string{nodeOSLabel: os, nodeArchLabel: arch}
+
+	for key, value := range pod.Spec.NodeSelector {
+		if actual, ok := values[key]; ok && actual != value {
+			return false
+		}
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) == 0 {
+		return true
+	}
+	// Terms are ORed; expressions within a term are ANDed.
+	for _, term := range terms {
+		termAllows := true
+		for _, expr := range term.MatchExpressions {
+			actual, ok := values[expr.Key]
+			if !ok {
+				continue
+			}
+			if !platformExpressionMatches(expr, actual) {
+				termAllows = false
+				break
+			}
+		}
+		if termAllows {
+			return true
+		}
+	}
+	return false
+}
+
+// platformExpressionMatches evaluates a node selector requirement against a label value.
+func platformExpressionMatches(expr corev1.NodeSelectorRequirement, value string) bool {
+	contains := false
+	for _, v := range expr.Values {
+		if v == value {
+			contains = true
+			break
+		}
+	}
+	switch expr.Operator {
+	case corev1.NodeSelectorOpIn:
+		return contains
+	case corev1.NodeSelectorOpNotIn:
+		return !contains
+	case corev1.NodeSelectorOpDoesNotExist:
+		return false
+	default:
+		return true
+	}
+}
+
+// platformsIntersect reports whether any image platform (which may include a
+// variant, e.g. "linux/arm/v7") matches one of the allowed node platforms.
+func platformsIntersect(imagePlatforms, allowed []string) bool {
+	for _, image := range imagePlatforms {
+		parts := strings.SplitN(image, "/", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		for _, platform := range allowed {
+			if parts[0]+"/"+parts[1] == platform {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// imageManifestAccept lists the manifest media types accepted from registries.
+var imageManifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+// fetchImagePlatforms returns the "os/arch[/variant]" platforms published for
+// an image, using the registry's manifest list or the image config.
+func fetchImagePlatforms(ctx context.Context, image string) ([]string, error) {
+	registry, repository, reference := parseImageReference(image)
+
+	var manifest struct {
+		MediaType string `json:"mediaType"`
+		Manifests []struct {
+			Platform struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+				Variant      string `json:"variant"`
+			} `json:"platform"`
+		} `json:"manifests"`
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registry, repository, reference)
+	if err := registryGetJSON(ctx, manifestURL, imageManifestAccept, &manifest); err != nil {
+		return nil, err
+	}
+
+	var platforms []string
+	if len(manifest.Manifests) > 0 {
+		for _, m := range manifest.Manifests {
+			p := m.Platform
+			if p.OS == "" || p.OS == "unknown" {
+				// Attestation manifests carry an "unknown" platform.
+				continue
+			}
+			platform := p.OS + "/" + p.Architecture
+			if p.Variant != "" {
+				platform += "/" + p.Variant
+			}
+			platforms = append(platforms, platform)
+		}
+		return platforms, nil
+	}
+
+	if manifest.Config.Digest == "" {
+		return nil, fmt.Errorf("manifest for %s has neither platforms nor config", image)
+	}
+	var config struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		Variant      string `json:"variant"`
+	}
+	blobURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s", registry, repository, manifest.Config.Digest)
+	if err := registryGetJSON(ctx, blobURL, "*/*", &config); err != nil {
+		return nil, err
+	}
+	platform := config.OS + "/" + config.Architecture
+	if config.Variant != "" {
+		platform += "/" + config.Variant
+	}
+	return []string{platform}, nil
+}
+
+// registryGetJSON performs a GET against a registry endpoint, negotiating an
+// anonymous bearer token if the registry requests one, and decodes the JSON body.
+func registryGetJSON(ctx context.Context, target, accept string, out interface{}) error {
+	resp, err := registryGet(ctx, target, accept, "")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		token, err := fetchRegistryToken(ctx, challenge)
+		if err != nil {
+			return err
+		}
+		resp, err = registryGet(ctx, target, accept, token)
+		if err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry returned %s for %s", resp.Status, target)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func registryGet(ctx context.Context, target, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := registryHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry: %w", err)
+	}
+	return resp, nil
+}
+
+// fetchRegistryToken obtains an anonymous token for a Bearer challenge such as
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="..."`.
+func fetchRegistryToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("registry requires unsupported authentication: %q", challenge)
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			params[key] = strings.Trim(value, `"`)
+		}
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid registry auth realm in %q", challenge)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	resp, err := registryGet(ctx, realm.String(), "application/json", "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token endpoint returned %s (private image?)", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse registry token: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// parseImageReference splits an image reference into registry host,
+// repository and tag or digest, applying Docker Hub defaults.
+func parseImageReference(image string) (registry, repository, reference string) {
+	registry = "registry-1.docker.io"
+	name := image
+
+	if first, rest, ok := strings.Cut(image, "/"); ok &&
+		(strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry = first
+		name = rest
+	}
+	if registry == "docker.io" || registry == "index.docker.io" {
+		registry = "registry-1.docker.io"
+	}
+
+	reference = "latest"
+	if n, digest, ok := strings.Cut(name, "@"); ok {
+		name, reference = n, digest
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, reference = name[:i], name[i+1:]
+	}
+
+	if registry == "registry-1.docker.io" && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	return registry, name, reference
+}
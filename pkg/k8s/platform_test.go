@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestPodAllowsPlatform tests matching pod nodeSelectors and node affinity against node platforms
+func TestPodAllowsPlatform(t *testing.T) {
+	amd64Only := &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{nodeArchLabel: "amd64", "disk": "ssd"}}}
+	notArm := &corev1.Pod{Spec: corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: nodeArchLabel, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"arm64"}},
+				{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}},
+			},
+		}}},
+	}}}}
+
+	tests := []struct {
+		pod      *corev1.Pod
+		platform string
+		want     bool
+	}{
+		{amd64Only, "linux/amd64", true},
+		{amd64Only, "linux/arm64", false},
+		{notArm, "linux/amd64", true},
+		{notArm, "linux/arm64", false},
+		{&corev1.Pod{}, "windows/amd64", true},
+	}
+	for _, tt := range tests {
+		if got := podAllowsPlatform(tt.pod, tt.platform); got != tt.want {
+			t.Errorf("podAllowsPlatform(%v, %s) = %v, want %v", tt.pod.Spec, tt.platform, got, tt.want)
+		}
+	}
+
+	if !platformsIntersect([]string{"linux/arm/v7", "linux/amd64"}, []string{"linux/amd64"}) || platformsIntersect([]string{"linux/arm64"}, []string{"linux/amd64"}) {
+		t.Error("Unexpected intersection of image and node platforms")
+	}
+}
+
+// TestParseImageReference tests splitting image references with Docker Hub defaults
+func TestParseImageReference(t *testing.T) {
+	tests := map[string][3]string{
+		"nginx":                          {"registry-1.docker.io", "library/nginx", "latest"},
+		"docker.io/bitnami/redis:7.2":    {"registry-1.docker.io", "bitnami/redis", "7.2"},
+		"localhost:5000/app@sha256:abcd": {"localhost:5000", "app", "sha256:abcd"},
+		"ghcr.io/org/tool:v1":            {"ghcr.io", "org/tool", "v1"},
+	}
+	for image, want := range tests {
+		if registry, repository, reference := parseImageReference(image); [3]string{registry, repository, reference} != want {
+			t.Errorf("parseImageReference(%q) = %s, %s, %s; want %v", image, registry, repository, reference, want)
+		}
+	}
+}
+
+// TestCheckPlatformCompatibility tests flagging pods that no schedulable node's platform allows
+func TestCheckPlatformCompatibility(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/nodes":
+			w.Write([]byte(`{"kind":"NodeList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"a","labels":{"kubernetes.io/os":"linux","kubernetes.io/arch":"amd64"}}},` +
+				`{"metadata":{"name":"b"},"status":{"nodeInfo":{"operatingSystem":"linux","architecture":"amd64"}}},` +
+				`{"metadata":{"name":"c","labels":{"kubernetes.io/os":"linux","kubernetes.io/arch":"arm64"}},"spec":{"unschedulable":true}}]}`))
+		case "/api/v1/namespaces/web/pods":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"api"},"spec":{"containers":[{"name":"app","image":"api"}]},"status":{"phase":"Running"}},` +
+				`{"metadata":{"name":"arm-job","ownerReferences":[{"apiVersion":"batch/v1","kind":"Job","name":"arm","uid":"1","controller":true}]},` +
+				`"spec":{"nodeSelector":{"kubernetes.io/arch":"arm64"},"containers":[{"name":"app","image":"tool"}]},"status":{"phase":"Pending"}},` +
+				`{"metadata":{"name":"done"},"spec":{"nodeSelector":{"kubernetes.io/arch":"arm64"},"containers":[{"name":"app","image":"tool"}]},"status":{"phase":"Succeeded"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.CheckPlatformCompatibility(context.Background(), "web", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if platforms := result["nodePlatforms"].(map[string]int); len(platforms) != 1 || platforms["linux/amd64"] != 2 {
+		t.Errorf("Expected only the platforms of schedulable nodes, got %v", platforms)
+	}
+	findings := result["findings"].([]map[string]interface{})
+	if len(findings) != 1 || findings[0]["pod"] != "arm-job" || findings[0]["owner"] != "Job/arm" {
+		t.Errorf("Expected only the running arm64 pod to be flagged, got %v", findings)
+	}
+	if _, ok := result["imagePlatforms"]; ok {
+		t.Error("Expected no image platforms without inspecting images")
+	}
+}
@@ -0,0 +1,705 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// redactedValue replaces values that must not be returned, such as Secret data.
+const redactedValue = "<redacted>"
+
+// sensitiveEnvName matches names of literal environment variables whose values
+// are redacted because they likely hold credentials.
+var sensitiveEnvName = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|CREDENTIAL)`)
+
+// envVarRef matches $(VAR) references expanded by the kubelet.
+var envVarRef = regexp.MustCompile(`\$\$|\$\(([A-Za-z_][A-Za-z0-9_.-]*)\)`)
+
+// podConfigSources caches the ConfigMaps and Secrets referenced by a pod so each
+// is fetched at most once.
+type podConfigSources struct {
+	client     *Client
+	ctx        context.Context
+	namespace  string
+	configMaps map[string]*corev1.ConfigMap
+	secrets    map[string]*corev1.Secret
+	errors     map[string]error
+}
+
+// newPodConfigSources returns an empty source cache for the namespace.
+func newPodConfigSources(ctx context.Context, c *Client, namespace string) *podConfigSources {
+	return &podConfigSources{
+		client:     c,
+		ctx:        ctx,
+		namespace:  namespace,
+		configMaps: map[string]*corev1.ConfigMap{},
+		secrets:    map[string]*corev1.Secret{},
+		errors:     map[string]error{},
+	}
+}
+
+// configMap returns the named ConfigMap, or the error encountered fetching it.
+func (s *podConfigSources) configMap(name string) (*corev1.ConfigMap, error) {
+	key := "configmap/" + name
+	if err, ok := s.errors[key]; ok {
+		return nil, err
+	}
+	if cm, ok := s.configMaps[name]; ok {
+		return cm, nil
+	}
+	cm, err := s.client.clientset.CoreV1().ConfigMaps(s.namespace).Get(s.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		s.errors[key] = err
+		return nil, err
+	}
+	s.configMaps[name] = cm
+	return cm, nil
+}
+
+// secret returns the named Secret, or the error encountered fetching it.
+func (s *podConfigSources) secret(name string) (*corev1.Secret, error) {
+	key := "secret/" + name
+	if err, ok := s.errors[key]; ok {
+		return nil, err
+	}
+	if secret, ok := s.secrets[name]; ok {
+		return secret, nil
+	}
+	secret, err := s.client.clientset.CoreV1().Secrets(s.namespace).Get(s.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		s.errors[key] = err
+		return nil, err
+	}
+	s.secrets[name] = secret
+	return secret, nil
+}
+
+// GetPodEnvironment resolves the effective environment of a pod's containers:
+// literal values, configMap and secret key references, envFrom sources, and
+// downward API fields. Secret values and literal values with credential-like
+// names are redacted. If container is set, only that container is reported.
+// Returns a slice of maps, one per container, or an error.
+func (c *Client) GetPodEnvironment(ctx context.Context, namespace, podName, container string) ([]map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+
+	sources := newPodConfigSources(ctx, c, namespace)
+	var containers []map[string]interface{}
+	add := func(ctr corev1.Container, initContainer bool) {
+		if container != "" && ctr.Name != container {
+			return
+		}
+		containers = append(containers, map[string]interface{}{
+			"container":     ctr.Name,
+			"initContainer": initContainer,
+			"env":           resolveContainerEnv(pod, ctr, sources),
+		})
+	}
+	for _, ctr := range pod.Spec.InitContainers {
+		add(ctr, true)
+	}
+	for _, ctr := range pod.Spec.Containers {
+		add(ctr, false)
+	}
+
+	if container != "" && len(containers) == 0 {
+		return nil, fmt.Errorf("container %s not found in pod %s", container, podName)
+	}
+	return containers, nil
+}
+
+// resolveContainerEnv resolves a container's envFrom and env entries in the
+// order the kubelet applies them. Entries replaced by a later definition of the
+// same name are marked as overridden.
+func resolveContainerEnv(pod *corev1.Pod, ctr corev1.Container, sources *podConfigSources) []map[string]interface{} {
+	var entries []map[string]interface{}
+
+	for _, envFrom := range ctr.EnvFrom {
+		entries = append(entries, resolveEnvFrom(envFrom, sources)...)
+	}
+
+	// Values known so far, used to expand $(VAR) references in literal values
+	known := map[string]string{}
+	for _, entry := range entries {
+		if value, ok := entry["value"].(string); ok && entry["redacted"] != true {
+			known[entry["name"].(string)] = value
+		}
+	}
+
+	for _, env := range ctr.Env {
+		entry := resolveEnvVar(pod, ctr, env, known, sources)
+		if value, ok := entry["value"].(string); ok && entry["redacted"] != true {
+			known[env.Name] = value
+		} else {
+			delete(known, env.Name)
+		}
+		entries = append(entries, entry)
+	}
+
+	lastIndex := map[string]int{}
+	for i, entry := range entries {
+		lastIndex[entry["name"].(string)] = i
+	}
+	for i, entry := range entries {
+		if lastIndex[entry["name"].(string)] != i {
+			entry["overridden"] = true
+		}
+	}
+	return entries
+}
+
+// resolveEnvFrom expands an envFrom source into one entry per key.
+func resolveEnvFrom(envFrom corev1.EnvFromSource, sources *podConfigSources) []map[string]interface{} {
+	var entries []map[string]interface{}
+	switch {
+	case envFrom.ConfigMapRef != nil:
+		ref := envFrom.ConfigMapRef
+		cm, err := sources.configMap(ref.Name)
+		if err != nil {
+			return []map[string]interface{}{{
+				"name":      envFrom.Prefix + "*",
+				"source":    "envFrom.configMapRef",
+				"configMap": ref.Name,
+				"optional":  ref.Optional != nil && *ref.Optional,
+				"error":     err.Error(),
+			}}
+		}
+		for _, key := range sortedKeys(cm.Data) {
+			entries = append(entries, map[string]interface{}{
+				"name":      envFrom.Prefix + key,
+				"source":    "envFrom.configMapRef",
+				"configMap": ref.Name,
+				"key":       key,
+				"value":     cm.Data[key],
+			})
+		}
+	case envFrom.SecretRef != nil:
+		ref := envFrom.SecretRef
+		secret, err := sources.secret(ref.Name)
+		if err != nil {
+			return []map[string]interface{}{{
+				"name":     envFrom.Prefix + "*",
+				"source":   "envFrom.secretRef",
+				"secret":   ref.Name,
+				"optional": ref.Optional != nil && *ref.Optional,
+				"error":    err.Error(),
+			}}
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			entries = append(entries, map[string]interface{}{
+				"name":     envFrom.Prefix + key,
+				"source":   "envFrom.secretRef",
+				"secret":   ref.Name,
+				"key":      key,
+				"value":    redactedValue,
+				"redacted": true,
+			})
+		}
+	}
+	return entries
+}
+
+// resolveEnvVar resolves a single env entry to its value and source.
+func resolveEnvVar(pod *corev1.Pod, ctr corev1.Container, env corev1.EnvVar, known map[string]string, sources *podConfigSources) map[string]interface{} {
+	entry := map[string]interface{}{"name": env.Name}
+
+	if env.ValueFrom == nil {
+		entry["source"] = "literal"
+		if sensitiveEnvName.MatchString(env.Name) {
+			entry["value"] = redactedValue
+			entry["redacted"] = true
+			return entry
+		}
+		entry["value"] = expandEnvReferences(env.Value, known)
+		if entry["value"] != env.Value {
+			entry["rawValue"] = env.Value
+		}
+		return entry
+	}
+
+	from := env.ValueFrom
+	switch {
+	case from.ConfigMapKeyRef != nil:
+		ref := from.ConfigMapKeyRef
+		entry["source"] = "configMapKeyRef"
+		entry["configMap"] = ref.Name
+		entry["key"] = ref.Key
+		entry["optional"] = ref.Optional != nil && *ref.Optional
+		cm, err := sources.configMap(ref.Name)
+		if err != nil {
+			entry["error"] = err.Error()
+		} else if value, ok := cm.Data[ref.Key]; ok {
+			entry["value"] = value
+		} else {
+			entry["error"] = fmt.Sprintf("key %s not found in ConfigMap %s", ref.Key, ref.Name)
+		}
+	case from.SecretKeyRef != nil:
+		ref := from.SecretKeyRef
+		entry["source"] = "secretKeyRef"
+		entry["secret"] = ref.Name
+		entry["key"] = ref.Key
+		entry["optional"] = ref.Optional != nil && *ref.Optional
+		secret, err := sources.secret(ref.Name)
+		if err != nil {
+			entry["error"] = err.Error()
+		} else if _, ok := secret.Data[ref.Key]; ok {
+			entry["value"] = redactedValue
+			entry["redacted"] = true
+		} else {
+			entry["error"] = fmt.Sprintf("key %s not found in Secret %s", ref.Key, ref.Name)
+		}
+	case from.FieldRef != nil:
+		entry["source"] = "fieldRef"
+		entry["fieldPath"] = from.FieldRef.FieldPath
+		if value, ok := downwardAPIField(pod, from.FieldRef.FieldPath); ok {
+			entry["value"] = value
+		} else {
+			entry["error"] = fmt.Sprintf("unsupported field path %s", from.FieldRef.FieldPath)
+		}
+	case from.ResourceFieldRef != nil:
+		entry["source"] = "resourceFieldRef"
+		entry["resource"] = from.ResourceFieldRef.Resource
+		value, err := downwardAPIResource(ctr, pod, from.ResourceFieldRef)
+		if err != nil {
+			entry["error"] = err.Error()
+		} else {
+			entry["value"] = value
+		}
+	}
+	return entry
+}
+
+// downwardAPIField resolves a downward API field path against the pod.
+func downwardAPIField(pod *corev1.Pod, fieldPath string) (string, bool) {
+	if key, ok := subscriptKey(fieldPath, "metadata.labels"); ok {
+		return pod.Labels[key], true
+	}
+	if key, ok := subscriptKey(fieldPath, "metadata.annotations"); ok {
+		return pod.Annotations[key], true
+	}
+
+	switch fieldPath {
+	case "metadata.name":
+		return pod.Name, true
+	case "metadata.namespace":
+		return pod.Namespace, true
+	case "metadata.uid":
+		return string(pod.UID), true
+	case "spec.nodeName":
+		return pod.Spec.NodeName, true
+	case "spec.serviceAccountName":
+		return pod.Spec.ServiceAccountName, true
+	case "status.hostIP":
+		return pod.Status.HostIP, true
+	case "status.podIP":
+		return pod.Status.PodIP, true
+	case "status.hostIPs":
+		var ips []string
+		for _, ip := range pod.Status.HostIPs {
+			ips = append(ips, ip.IP)
+		}
+		return strings.Join(ips, ","), true
+	case "status.podIPs":
+		var ips []string
+		for _, ip := range pod.Status.PodIPs {
+			ips = append(ips, ip.IP)
+		}
+		return strings.Join(ips, ","), true
+	}
+	return "", false
+}
+
+// subscriptKey extracts the key from a field path of the form prefix['key'].
+func subscriptKey(fieldPath, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(fieldPath, prefix+"[")
+	if !ok || !strings.HasSuffix(rest, "]") {
+		return "", false
+	}
+	return strings.Trim(strings.TrimSuffix(rest, "]"), `'"`), true
+}
+
+// downwardAPIResource resolves a resourceFieldRef to the container's request or
+// limit divided by the divisor and rounded up, as the kubelet does.
+func downwardAPIResource(ctr corev1.Container, pod *corev1.Pod, ref *corev1.ResourceFieldSelector) (string, error) {
+	target := ctr
+	if ref.ContainerName != "" && ref.ContainerName != ctr.Name {
+		found := false
+		for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+			for _, other := range containers {
+				if other.Name == ref.ContainerName {
+					target, found = other, true
+				}
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("container %s not found", ref.ContainerName)
+		}
+	}
+
+	kind, name, ok := strings.Cut(ref.Resource, ".")
+	if !ok {
+		return "", fmt.Errorf("unsupported resource %s", ref.Resource)
+	}
+	var quantities corev1.ResourceList
+	switch kind {
+	case "limits":
+		quantities = target.Resources.Limits
+	case "requests":
+		quantities = target.Resources.Requests
+	default:
+		return "", fmt.Errorf("unsupported resource %s", ref.Resource)
+	}
+	quantity, ok := quantities[corev1.ResourceName(name)]
+	if !ok {
+		if kind == "limits" {
+			return "", fmt.Errorf("no %s set; the kubelet uses the node's allocatable value", ref.Resource)
+		}
+		return "0", nil
+	}
+
+	divisor := resource.MustParse("1")
+	if !ref.Divisor.IsZero() {
+		divisor = ref.Divisor
+	}
+	value := math.Ceil(float64(quantity.MilliValue()) / float64(divisor.MilliValue()))
+	return fmt.Sprintf("%d", int64(value)), nil
+}
+
+// expandEnvReferences expands $(VAR) references to previously defined
+// variables. Unknown references are left unchanged and $$ escapes a dollar sign.
+func expandEnvReferences(value string, known map[string]string) string {
+	return envVarRef.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		name := match[2 : len(match)-1]
+		if resolved, ok := known[name]; ok {
+			return resolved
+		}
+		return match
+	})
+}
+
+// sortedKeys returns the keys of a string map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetPodVolumeMounts resolves each volumeMount of a pod's containers to its
+// volume source (ConfigMap, Secret, PVC, projected, emptyDir, ...), reporting
+// whether the referenced objects exist and which keys are projected to which
+// paths. Secret data is never returned, only key names.
+// Returns a map with "mounts" and "unmountedVolumes", or an error.
+func (c *Client) GetPodVolumeMounts(ctx context.Context, namespace, podName string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+
+	sources := newPodConfigSources(ctx, c, namespace)
+	volumes := map[string]corev1.Volume{}
+	for _, volume := range pod.Spec.Volumes {
+		volumes[volume.Name] = volume
+	}
+
+	mounted := map[string]bool{}
+	var mounts []map[string]interface{}
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, ctr := range containers {
+			for _, mount := range ctr.VolumeMounts {
+				mounted[mount.Name] = true
+				entry := map[string]interface{}{
+					"container": ctr.Name,
+					"mountPath": mount.MountPath,
+					"readOnly":  mount.ReadOnly,
+					"volume":    mount.Name,
+				}
+				if mount.SubPath != "" {
+					entry["subPath"] = mount.SubPath
+				}
+				if mount.SubPathExpr != "" {
+					entry["subPathExpr"] = mount.SubPathExpr
+				}
+				volume, ok := volumes[mount.Name]
+				if !ok {
+					entry["error"] = fmt.Sprintf("volume %s is not defined in the pod spec", mount.Name)
+					mounts = append(mounts, entry)
+					continue
+				}
+				for key, value := range c.resolveVolumeSource(ctx, pod, volume, mount.MountPath, sources) {
+					entry[key] = value
+				}
+				mounts = append(mounts, entry)
+			}
+		}
+	}
+
+	var unmounted []string
+	for _, volume := range pod.Spec.Volumes {
+		if !mounted[volume.Name] {
+			unmounted = append(unmounted, volume.Name)
+		}
+	}
+
+	return map[string]interface{}{
+		"pod":              pod.Name,
+		"namespace":        pod.Namespace,
+		"mounts":           mounts,
+		"unmountedVolumes": unmounted,
+	}, nil
+}
+
+// resolveVolumeSource describes a volume's source, whether the objects it
+// references exist, and the files it projects under mountPath.
+func (c *Client) resolveVolumeSource(ctx context.Context, pod *corev1.Pod, volume corev1.Volume, mountPath string, sources *podConfigSources) map[string]interface{} {
+	result := map[string]interface{}{}
+	switch {
+	case volume.ConfigMap != nil:
+		result["type"] = "configMap"
+		result["source"] = map[string]interface{}{"name": volume.ConfigMap.Name, "optional": volume.ConfigMap.Optional != nil && *volume.ConfigMap.Optional}
+		files, err := configMapFiles(sources, volume.ConfigMap.Name, volume.ConfigMap.Items, mountPath)
+		setVolumeFiles(result, files, err)
+	case volume.Secret != nil:
+		result["type"] = "secret"
+		result["source"] = map[string]interface{}{"name": volume.Secret.SecretName, "optional": volume.Secret.Optional != nil && *volume.Secret.Optional}
+		files, err := secretFiles(sources, volume.Secret.SecretName, volume.Secret.Items, mountPath)
+		setVolumeFiles(result, files, err)
+	case volume.PersistentVolumeClaim != nil:
+		result["type"] = "persistentVolumeClaim"
+		for key, value := range c.describeClaim(ctx, pod.Namespace, volume.PersistentVolumeClaim.ClaimName) {
+			result[key] = value
+		}
+	case volume.Ephemeral != nil:
+		result["type"] = "ephemeral"
+		// Generic ephemeral volumes are backed by a PVC named <pod>-<volume>
+		for key, value := range c.describeClaim(ctx, pod.Namespace, pod.Name+"-"+volume.Name) {
+			result[key] = value
+		}
+	case volume.Projected != nil:
+		result["type"] = "projected"
+		var files []map[string]interface{}
+		var projectionErrors []string
+		for _, projection := range volume.Projected.Sources {
+			var projected []map[string]interface{}
+			var err error
+			switch {
+			case projection.ConfigMap != nil:
+				projected, err = configMapFiles(sources, projection.ConfigMap.Name, projection.ConfigMap.Items, mountPath)
+			case projection.Secret != nil:
+				projected, err = secretFiles(sources, projection.Secret.Name, projection.Secret.Items, mountPath)
+			case projection.DownwardAPI != nil:
+				projected = downwardAPIFiles(projection.DownwardAPI.Items, mountPath)
+			case projection.ServiceAccountToken != nil:
+				projected = []map[string]interface{}{{
+					"source":            "serviceAccountToken",
+					"path":              joinMountPath(mountPath, projection.ServiceAccountToken.Path),
+					"audience":          projection.ServiceAccountToken.Audience,
+					"expirationSeconds": projection.ServiceAccountToken.ExpirationSeconds,
+				}}
+			case projection.ClusterTrustBundle != nil:
+				projected = []map[string]interface{}{{
+					"source": "clusterTrustBundle",
+					"path":   joinMountPath(mountPath, projection.ClusterTrustBundle.Path),
+				}}
+			}
+			if err != nil {
+				projectionErrors = append(projectionErrors, err.Error())
+			}
+			files = append(files, projected...)
+		}
+		result["files"] = files
+		result["exists"] = len(projectionErrors) == 0
+		if len(projectionErrors) > 0 {
+			result["error"] = strings.Join(projectionErrors, "; ")
+		}
+	case volume.DownwardAPI != nil:
+		result["type"] = "downwardAPI"
+		result["files"] = downwardAPIFiles(volume.DownwardAPI.Items, mountPath)
+	case volume.EmptyDir != nil:
+		result["type"] = "emptyDir"
+		source := map[string]interface{}{"medium": string(volume.EmptyDir.Medium)}
+		if volume.EmptyDir.SizeLimit != nil {
+			source["sizeLimit"] = volume.EmptyDir.SizeLimit.String()
+		}
+		result["source"] = source
+	case volume.HostPath != nil:
+		result["type"] = "hostPath"
+		source := map[string]interface{}{"path": volume.HostPath.Path}
+		if volume.HostPath.Type != nil {
+			source["hostPathType"] = string(*volume.HostPath.Type)
+		}
+		result["source"] = source
+	case volume.CSI != nil:
+		result["type"] = "csi"
+		result["source"] = map[string]interface{}{"driver": volume.CSI.Driver, "volumeAttributes": volume.CSI.VolumeAttributes}
+	case volume.NFS != nil:
+		result["type"] = "nfs"
+		result["source"] = map[string]interface{}{"server": volume.NFS.Server, "path": volume.NFS.Path}
+	case volume.Image != nil:
+		result["type"] = "image"
+		result["source"] = map[string]interface{}{"reference": volume.Image.Reference}
+	default:
+		result["type"] = "other"
+	}
+	return result
+}
+
+// setVolumeFiles records the projected files of a volume and any error, such as
+// a missing object or keys listed in items that the object does not contain.
+func setVolumeFiles(result map[string]interface{}, files []map[string]interface{}, err error) {
+	result["exists"] = files != nil || err == nil
+	if err != nil {
+		result["error"] = err.Error()
+	}
+	if files != nil {
+		result["files"] = files
+	}
+}
+
+// describeClaim reports the state of a PersistentVolumeClaim and its bound volume.
+func (c *Client) describeClaim(ctx context.Context, namespace, claimName string) map[string]interface{} {
+	result := map[string]interface{}{"source": map[string]interface{}{"claimName": claimName}}
+	pvc, err := c.clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, claimName, metav1.GetOptions{})
+	if err != nil {
+		result["exists"] = false
+		result["error"] = err.Error()
+		return result
+	}
+	source := map[string]interface{}{
+		"claimName":   claimName,
+		"phase":       string(pvc.Status.Phase),
+		"volumeName":  pvc.Spec.VolumeName,
+		"accessModes": pvc.Status.AccessModes,
+	}
+	if pvc.Spec.StorageClassName != nil {
+		source["storageClass"] = *pvc.Spec.StorageClassName
+	}
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		source["capacity"] = capacity.String()
+	}
+	result["source"] = source
+	result["exists"] = true
+	if pvc.Status.Phase != corev1.ClaimBound {
+		result["error"] = fmt.Sprintf("PersistentVolumeClaim %s is %s", claimName, pvc.Status.Phase)
+	}
+	return result
+}
+
+// configMapFiles lists the files a ConfigMap projects under mountPath: every
+// key when items is empty, otherwise only the listed keys.
+func configMapFiles(sources *podConfigSources, name string, items []corev1.KeyToPath, mountPath string) ([]map[string]interface{}, error) {
+	cm, err := sources.configMap(name)
+	if err != nil {
+		return nil, err
+	}
+	keys := sortedKeys(cm.Data)
+	for key := range cm.BinaryData {
+		keys = append(keys, key)
+	}
+	return projectKeys("configMap", name, keys, items, mountPath)
+}
+
+// secretFiles lists the files a Secret projects under mountPath. Only key names
+// are reported; values are never read into the result.
+func secretFiles(sources *podConfigSources, name string, items []corev1.KeyToPath, mountPath string) ([]map[string]interface{}, error) {
+	secret, err := sources.secret(name)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return projectKeys("secret", name, keys, items, mountPath)
+}
+
+// projectKeys maps object keys to file paths, flagging listed items whose key
+// does not exist in the object.
+func projectKeys(source, name string, keys []string, items []corev1.KeyToPath, mountPath string) ([]map[string]interface{}, error) {
+	var files []map[string]interface{}
+	if len(items) == 0 {
+		for _, key := range keys {
+			files = append(files, map[string]interface{}{
+				"source": source,
+				"name":   name,
+				"key":    key,
+				"path":   joinMountPath(mountPath, key),
+			})
+		}
+		return files, nil
+	}
+
+	present := map[string]bool{}
+	for _, key := range keys {
+		present[key] = true
+	}
+	var missing []string
+	for _, item := range items {
+		file := map[string]interface{}{
+			"source": source,
+			"name":   name,
+			"key":    item.Key,
+			"path":   joinMountPath(mountPath, item.Path),
+		}
+		if !present[item.Key] {
+			file["missing"] = true
+			missing = append(missing, item.Key)
+		}
+		files = append(files, file)
+	}
+	if len(missing) > 0 {
+		return files, fmt.Errorf("keys %s not found in %s %s", strings.Join(missing, ", "), source, name)
+	}
+	return files, nil
+}
+
+// downwardAPIFiles lists the files projected by downward API items.
+func downwardAPIFiles(items []corev1.DownwardAPIVolumeFile, mountPath string) []map[string]interface{} {
+	var files []map[string]interface{}
+	for _, item := range items {
+		file := map[string]interface{}{
+			"source": "downwardAPI",
+			"path":   joinMountPath(mountPath, item.Path),
+		}
+		if item.FieldRef != nil {
+			file["fieldPath"] = item.FieldRef.FieldPath
+		}
+		if item.ResourceFieldRef != nil {
+			file["resource"] = item.ResourceFieldRef.Resource
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// joinMountPath joins a mount path and a relative file path.
+func joinMountPath(mountPath, path string) string {
+	return strings.TrimSuffix(mountPath, "/") + "/" + path
+}
@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newPodConfigTestClient returns a client whose API server serves the given
+// objects by path and 404s for everything else.
+func newPodConfigTestClient(t *testing.T, objects map[string]string) *Client {
+	t.Helper()
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		object, ok := objects[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(object))
+	}))
+}
+
+// TestGetPodEnvironment tests resolving literal, ConfigMap, Secret and downward API environment variables
+func TestGetPodEnvironment(t *testing.T) {
+	client := newPodConfigTestClient(t, map[string]string{
+		"/api/v1/namespaces/web/pods/api": `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"api","namespace":"web","labels":{"app":"api"}},` +
+			`"spec":{"nodeName":"worker-1","containers":[{"name":"app","image":"api:1",` +
+			`"resources":{"requests":{"memory":"64Mi"}},` +
+			`"envFrom":[{"configMapRef":{"name":"settings"}}],` +
+			`"env":[{"name":"LOG_LEVEL","value":"debug"},` +
+			`{"name":"URL","value":"http://$(HOST):8080/$$x"},` +
+			`{"name":"DB_PASSWORD","value":"hunter2"},` +
+			`{"name":"API_KEY","valueFrom":{"secretKeyRef":{"name":"creds","key":"key"}}},` +
+			`{"name":"FEATURE","valueFrom":{"configMapKeyRef":{"name":"settings","key":"missing"}}},` +
+			`{"name":"APP","valueFrom":{"fieldRef":{"fieldPath":"metadata.labels['app']"}}},` +
+			`{"name":"NODE","valueFrom":{"fieldRef":{"fieldPath":"spec.nodeName"}}},` +
+			`{"name":"MEMORY_MB","valueFrom":{"resourceFieldRef":{"resource":"requests.memory","divisor":"1Mi"}}}]}]}}`,
+		"/api/v1/namespaces/web/configmaps/settings": `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"settings"},"data":{"HOST":"db","LOG_LEVEL":"info"}}`,
+		"/api/v1/namespaces/web/secrets/creds":       `{"kind":"Secret","apiVersion":"v1","metadata":{"name":"creds"},"data":{"key":"c2VjcmV0"}}`,
+	})
+	ctx := context.Background()
+
+	containers, err := client.GetPodEnvironment(ctx, "web", "api", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 1 || containers[0]["container"] != "app" {
+		t.Fatalf("Expected the app container, got %v", containers)
+	}
+	env := map[string]map[string]interface{}{}
+	for _, entry := range containers[0]["env"].([]map[string]interface{}) {
+		if entry["overridden"] == true {
+			if entry["name"] != "LOG_LEVEL" || entry["value"] != "info" {
+				t.Errorf("Expected only the envFrom LOG_LEVEL to be overridden, got %v", entry)
+			}
+			continue
+		}
+		env[entry["name"].(string)] = entry
+	}
+
+	expected := map[string]string{
+		"HOST":        "db",
+		"LOG_LEVEL":   "debug",
+		"URL":         "http://db:8080/$x",
+		"DB_PASSWORD": redactedValue,
+		"API_KEY":     redactedValue,
+		"APP":         "api",
+		"NODE":        "worker-1",
+		"MEMORY_MB":   "64",
+	}
+	for name, value := range expected {
+		if env[name]["value"] != value {
+			t.Errorf("Expected %s to be %q, got %v", name, value, env[name])
+		}
+	}
+	if env["API_KEY"]["source"] != "secretKeyRef" || env["API_KEY"]["redacted"] != true {
+		t.Errorf("Expected the Secret value to be redacted, got %v", env["API_KEY"])
+	}
+	if errMsg, _ := env["FEATURE"]["error"].(string); !strings.Contains(errMsg, "key missing not found in ConfigMap settings") {
+		t.Errorf("Expected a missing ConfigMap key to be reported, got %v", env["FEATURE"])
+	}
+
+	if _, err := client.GetPodEnvironment(ctx, "web", "api", "sidecar"); err == nil || !strings.Contains(err.Error(), "container sidecar not found") {
+		t.Errorf("Expected an error for an unknown container, got %v", err)
+	}
+	if _, err := client.GetPodEnvironment(ctx, "web", "missing", ""); err == nil {
+		t.Error("Expected an error for a missing pod")
+	}
+}
+
+// TestGetPodVolumeMounts tests resolving volume mounts to their sources and projected keys
+func TestGetPodVolumeMounts(t *testing.T) {
+	client := newPodConfigTestClient(t, map[string]string{
+		"/api/v1/namespaces/web/pods/api": `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"api","namespace":"web"},` +
+			`"spec":{"containers":[{"name":"app","image":"api:1","volumeMounts":[` +
+			`{"name":"config","mountPath":"/etc/app"},{"name":"tls","mountPath":"/tls/","readOnly":true},` +
+			`{"name":"data","mountPath":"/data"},{"name":"scratch","mountPath":"/tmp"},{"name":"ghost","mountPath":"/ghost"}]}],` +
+			`"volumes":[{"name":"config","configMap":{"name":"settings"}},` +
+			`{"name":"tls","secret":{"secretName":"certs","items":[{"key":"tls.crt","path":"cert.pem"},{"key":"ca.crt","path":"ca.pem"}]}},` +
+			`{"name":"data","persistentVolumeClaim":{"claimName":"api-data"}},` +
+			`{"name":"scratch","emptyDir":{"sizeLimit":"1Gi"}},` +
+			`{"name":"unused","emptyDir":{}}]}}`,
+		"/api/v1/namespaces/web/configmaps/settings": `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"settings"},"data":{"app.yaml":"x","log.yaml":"y"}}`,
+		"/api/v1/namespaces/web/secrets/certs":       `{"kind":"Secret","apiVersion":"v1","metadata":{"name":"certs"},"data":{"tls.crt":"Y2VydA==","tls.key":"a2V5"}}`,
+		"/api/v1/namespaces/web/persistentvolumeclaims/api-data": `{"kind":"PersistentVolumeClaim","apiVersion":"v1","metadata":{"name":"api-data"},` +
+			`"spec":{"storageClassName":"standard"},"status":{"phase":"Pending"}}`,
+	})
+
+	result, err := client.GetPodVolumeMounts(context.Background(), "web", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mounts := map[string]map[string]interface{}{}
+	for _, mount := range result["mounts"].([]map[string]interface{}) {
+		mounts[mount["volume"].(string)] = mount
+	}
+
+	config := mounts["config"]
+	if files := config["files"].([]map[string]interface{}); config["type"] != "configMap" || config["exists"] != true ||
+		len(files) != 2 || files[0]["path"] != "/etc/app/app.yaml" {
+		t.Errorf("Expected every ConfigMap key to be projected, got %v", config)
+	}
+	tls := mounts["tls"]
+	files := tls["files"].([]map[string]interface{})
+	if tls["type"] != "secret" || len(files) != 2 || files[0]["path"] != "/tls/cert.pem" || files[1]["missing"] != true ||
+		!strings.Contains(tls["error"].(string), "keys ca.crt not found in secret certs") {
+		t.Errorf("Expected the listed Secret keys with the missing one flagged, got %v", tls)
+	}
+	for _, file := range files {
+		if _, ok := file["value"]; ok {
+			t.Errorf("Expected no Secret values to be returned, got %v", file)
+		}
+	}
+	if data := mounts["data"]; data["type"] != "persistentVolumeClaim" || data["exists"] != true || data["error"] != "PersistentVolumeClaim api-data is Pending" {
+		t.Errorf("Expected the unbound claim to be reported, got %v", data)
+	}
+	if scratch := mounts["scratch"]; scratch["type"] != "emptyDir" || scratch["source"].(map[string]interface{})["sizeLimit"] != "1Gi" {
+		t.Errorf("Expected the emptyDir size limit, got %v", scratch)
+	}
+	if ghost := mounts["ghost"]; ghost["error"] != "volume ghost is not defined in the pod spec" {
+		t.Errorf("Expected an undefined volume to be reported, got %v", ghost)
+	}
+	if unmounted := result["unmountedVolumes"].([]string); len(unmounted) != 1 || unmounted[0] != "unused" {
+		t.Errorf("Expected the unused volume to be reported, got %v", unmounted)
+	}
+
+	if _, err := client.GetPodVolumeMounts(context.Background(), "web", "missing"); err == nil {
+		t.Error("Expected an error for a missing pod")
+	}
+}
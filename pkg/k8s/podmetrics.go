@@ -0,0 +1,83 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// ListPodMetrics retrieves the CPU and memory usage of the pods matching
+// labelSelector in a namespace, or in all namespaces when it is empty, like
+// kubectl top pods. Each pod is reported with its total usage and a
+// per-container breakdown. Pods are sorted by sortBy, "cpu" or "memory" usage
+// in descending order, or by namespace and name, and at most limit pods are
+// returned when limit is positive.
+// Returns a map with "pods" and the number of "totalPods" with metrics, or an error.
+func (c *Client) ListPodMetrics(ctx context.Context, namespace, labelSelector, sortBy string, limit int) (map[string]interface{}, error) {
+	metricsList, err := c.metricsClientset.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod metrics: %w", err)
+	}
+
+	pods := make([]map[string]interface{}, 0, len(metricsList.Items))
+	for i := range metricsList.Items {
+		pods = append(pods, summarizePodUsage(&metricsList.Items[i]))
+	}
+	sortPodUsage(pods, sortBy)
+	total := len(pods)
+	if limit > 0 && len(pods) > limit {
+		pods = pods[:limit]
+	}
+
+	return map[string]interface{}{
+		"pods":      pods,
+		"totalPods": total,
+	}, nil
+}
+
+// summarizePodUsage reports the total usage of a pod and the usage of each of
+// its containers, as quantities and as millicores and bytes for comparison.
+func summarizePodUsage(metrics *metricsv1beta1.PodMetrics) map[string]interface{} {
+	total := corev1.ResourceList{}
+	containers := make([]map[string]interface{}, 0, len(metrics.Containers))
+	for _, container := range metrics.Containers {
+		addResourceList(total, container.Usage)
+		containers = append(containers, map[string]interface{}{
+			"name":   container.Name,
+			"cpu":    container.Usage.Cpu().String(),
+			"memory": container.Usage.Memory().String(),
+		})
+	}
+	return map[string]interface{}{
+		"podName":       metrics.Name,
+		"namespace":     metrics.Namespace,
+		"timestamp":     metrics.Timestamp.Time,
+		"window":        metrics.Window.Duration.String(),
+		"cpu":           total.Cpu().String(),
+		"memory":        total.Memory().String(),
+		"cpuMillicores": total.Cpu().MilliValue(),
+		"memoryBytes":   total.Memory().Value(),
+		"containers":    containers,
+	}
+}
+
+// sortPodUsage sorts pod usage summaries by "cpu" or "memory" usage in
+// descending order, or by namespace and name for any other sortBy.
+func sortPodUsage(pods []map[string]interface{}, sortBy string) {
+	key := map[string]string{"cpu": "cpuMillicores", "memory": "memoryBytes"}[sortBy]
+	sort.SliceStable(pods, func(i, j int) bool {
+		if key != "" {
+			if a, b := pods[i][key].(int64), pods[j][key].(int64); a != b {
+				return a > b
+			}
+		}
+		if pods[i]["namespace"] != pods[j]["namespace"] {
+			return pods[i]["namespace"].(string) < pods[j]["namespace"].(string)
+		}
+		return pods[i]["podName"].(string) < pods[j]["podName"].(string)
+	})
+}
@@ -0,0 +1,46 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// TestSummarizePodUsage tests summing container usage per pod and sorting pods by it
+func TestSummarizePodUsage(t *testing.T) {
+	container := func(name, cpu, memory string) metricsv1beta1.ContainerMetrics {
+		return metricsv1beta1.ContainerMetrics{Name: name, Usage: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}
+	}
+	pod := func(name string, containers ...metricsv1beta1.ContainerMetrics) map[string]interface{} {
+		return summarizePodUsage(&metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+			Containers: containers,
+		})
+	}
+
+	pods := []map[string]interface{}{
+		pod("api", container("app", "250m", "300Mi"), container("proxy", "50m", "20Mi")),
+		pod("worker", container("app", "100m", "1Gi")),
+	}
+	if pods[0]["cpuMillicores"] != int64(300) || pods[0]["cpu"] != "300m" {
+		t.Errorf("Expected the container usage to be summed, got %v", pods[0])
+	}
+	if containers := pods[0]["containers"].([]map[string]interface{}); len(containers) != 2 || containers[1]["cpu"] != "50m" {
+		t.Errorf("Unexpected container breakdown %v", containers)
+	}
+
+	sortPodUsage(pods, "memory")
+	if pods[0]["podName"] != "worker" {
+		t.Errorf("Expected worker first by memory, got %v", pods[0]["podName"])
+	}
+	sortPodUsage(pods, "cpu")
+	if pods[0]["podName"] != "api" {
+		t.Errorf("Expected api first by CPU, got %v", pods[0]["podName"])
+	}
+}
@@ -0,0 +1,386 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// maxPortForwardsPerSession bounds the port forwards one session may keep open.
+const maxPortForwardsPerSession = 10
+
+// portForwardReadyTimeout bounds how long StartPortForward waits for the
+// forward to start listening.
+const portForwardReadyTimeout = 30 * time.Second
+
+// portForward is an active or closed port forward started by a session.
+type portForward struct {
+	id         string
+	session    string
+	namespace  string
+	target     string
+	pod        string
+	localPort  uint16
+	remotePort int
+	startedAt  time.Time
+	stop       chan struct{}
+	mu         sync.Mutex
+	closed     bool
+	err        error
+}
+
+// summary describes the forward for listing.
+func (f *portForward) summary() map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	summary := map[string]interface{}{
+		"id":         f.id,
+		"namespace":  f.namespace,
+		"target":     f.target,
+		"pod":        f.pod,
+		"localPort":  f.localPort,
+		"remotePort": f.remotePort,
+		"address":    fmt.Sprintf("127.0.0.1:%d", f.localPort),
+		"startedAt":  f.startedAt,
+		"active":     !f.closed,
+	}
+	if f.err != nil {
+		summary["error"] = f.err.Error()
+	}
+	return summary
+}
+
+// portForwards holds the port forwards of every session.
+type portForwards struct {
+	mu       sync.Mutex
+	forwards map[string]*portForward
+	nextID   int
+}
+
+// add registers a forward, assigning its ID, unless the session already has
+// the maximum number of active forwards.
+func (p *portForwards) add(forward *portForward) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.forwards == nil {
+		p.forwards = map[string]*portForward{}
+	}
+	active := 0
+	for _, existing := range p.forwards {
+		if existing.session == forward.session && !existing.isClosed() {
+			active++
+		}
+	}
+	if active >= maxPortForwardsPerSession {
+		return fmt.Errorf("this session already has %d active port forwards; stop one first", active)
+	}
+	p.nextID++
+	forward.id = fmt.Sprintf("pf-%d", p.nextID)
+	p.forwards[forward.id] = forward
+	return nil
+}
+
+// remove unregisters the session's forward with the given ID.
+func (p *portForwards) remove(session, id string) (*portForward, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	forward, ok := p.forwards[id]
+	if !ok || forward.session != session {
+		return nil, false
+	}
+	delete(p.forwards, id)
+	return forward, true
+}
+
+// list returns the forwards of a session, ordered by start time.
+func (p *portForwards) list(session string) []*portForward {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var forwards []*portForward
+	for _, forward := range p.forwards {
+		if forward.session == session {
+			forwards = append(forwards, forward)
+		}
+	}
+	sort.Slice(forwards, func(i, j int) bool { return forwards[i].startedAt.Before(forwards[j].startedAt) })
+	return forwards
+}
+
+// isClosed reports whether the forward has stopped.
+func (f *portForward) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// close stops the forward, recording err as the reason unless it was already
+// stopped.
+func (f *portForward) close(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	f.closed = true
+	f.err = err
+	close(f.stop)
+}
+
+// StartPortForward forwards a local port on 127.0.0.1 to a port of a pod, like
+// kubectl port-forward. The target is a Pod, a Service, or a workload with a
+// label selector such as a Deployment, in which case a running pod it selects
+// is used. For a Service, remotePort is the service port and is translated to
+// the target port of the pod. A localPort of 0 picks a free port. The forward
+// belongs to the session of ctx and runs until it is stopped, the pod goes
+// away, or the session ends.
+// Returns a summary of the forward, including its ID and local address, or an error.
+func (c *Client) StartPortForward(ctx context.Context, namespace, kind, name string, remotePort, localPort int) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	if remotePort <= 0 || remotePort > 65535 || localPort < 0 || localPort > 65535 {
+		return nil, fmt.Errorf("ports must be between 1 and 65535")
+	}
+
+	pod, podPort, err := c.resolvePortForwardTarget(ctx, namespace, kind, name, remotePort)
+	if err != nil {
+		return nil, err
+	}
+
+	forward := &portForward{
+		session:    sessionID(ctx),
+		namespace:  namespace,
+		target:     kind + "/" + name,
+		pod:        pod.Name,
+		remotePort: podPort,
+		startedAt:  time.Now(),
+		stop:       make(chan struct{}),
+	}
+	if err := c.portForwards.add(forward); err != nil {
+		return nil, err
+	}
+
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	transport, upgrader, err := spdy.RoundTripperFor(c.restConfig)
+	if err != nil {
+		c.portForwards.remove(forward.session, forward.id)
+		return nil, fmt.Errorf("failed to create port forward transport: %w", err)
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+	ready := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"},
+		[]string{fmt.Sprintf("%d:%d", localPort, podPort)}, forward.stop, ready, io.Discard, io.Discard)
+	if err != nil {
+		c.portForwards.remove(forward.session, forward.id)
+		return nil, fmt.Errorf("failed to create port forward: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := forwarder.ForwardPorts()
+		if err == nil {
+			err = fmt.Errorf("port forward closed")
+		}
+		forward.close(err)
+		done <- err
+	}()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		c.portForwards.remove(forward.session, forward.id)
+		return nil, fmt.Errorf("failed to start port forward: %w", err)
+	case <-ctx.Done():
+		forward.close(ctx.Err())
+		c.portForwards.remove(forward.session, forward.id)
+		return nil, fmt.Errorf("port forward to pod %s was cancelled: %w", pod.Name, ctx.Err())
+	case <-time.After(portForwardReadyTimeout):
+		forward.close(fmt.Errorf("timed out"))
+		c.portForwards.remove(forward.session, forward.id)
+		return nil, fmt.Errorf("port forward to pod %s did not become ready within %s", pod.Name, portForwardReadyTimeout)
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err == nil && len(ports) == 0 {
+		err = fmt.Errorf("no ports are forwarded")
+	}
+	if err != nil {
+		forward.close(err)
+		c.portForwards.remove(forward.session, forward.id)
+		return nil, fmt.Errorf("failed to read forwarded port: %w", err)
+	}
+	forward.mu.Lock()
+	forward.localPort = ports[0].Local
+	forward.mu.Unlock()
+	return forward.summary(), nil
+}
+
+// ListPortForwards lists the port forwards of the session of ctx, including
+// those that closed on their own, e.g. because their pod was deleted.
+// Returns a slice of forward summaries.
+func (c *Client) ListPortForwards(ctx context.Context) []map[string]interface{} {
+	summaries := []map[string]interface{}{}
+	for _, forward := range c.portForwards.list(sessionID(ctx)) {
+		summaries = append(summaries, forward.summary())
+	}
+	return summaries
+}
+
+// StopPortForward stops a port forward of the session of ctx and forgets it.
+// Returns the summary of the stopped forward, or an error if the session has
+// no forward with that ID.
+func (c *Client) StopPortForward(ctx context.Context, id string) (map[string]interface{}, error) {
+	forward, ok := c.portForwards.remove(sessionID(ctx), id)
+	if !ok {
+		return nil, fmt.Errorf("port forward %s not found in this session", id)
+	}
+	forward.close(nil)
+	return forward.summary(), nil
+}
+
+// StopSessionPortForwards stops and forgets every port forward of a session,
+// for when the session ends.
+// Returns the number of forwards stopped.
+func (c *Client) StopSessionPortForwards(session string) int {
+	if c.portForwards == nil {
+		return 0
+	}
+	forwards := c.portForwards.list(session)
+	for _, forward := range forwards {
+		c.portForwards.remove(session, forward.id)
+		forward.close(nil)
+	}
+	return len(forwards)
+}
+
+// resolvePortForwardTarget finds the pod to forward to and the port on it.
+func (c *Client) resolvePortForwardTarget(ctx context.Context, namespace, kind, name string, remotePort int) (*corev1.Pod, int, error) {
+	if kind == "" || kind == "Pod" || kind == "pod" || kind == "pods" {
+		pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get pod: %w", err)
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			return nil, 0, fmt.Errorf("pod %s is %s; only running pods can be forwarded to", name, pod.Status.Phase)
+		}
+		return pod, remotePort, nil
+	}
+
+	object, err := c.GetResource(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+	selector, err := objectPodSelector(&unstructured.Unstructured{Object: object})
+	if err != nil {
+		return nil, 0, err
+	}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list pods: %w", err)
+	}
+	pod := firstRunningPod(pods.Items)
+	if pod == nil {
+		return nil, 0, fmt.Errorf("no running pod matches %s %s", kind, name)
+	}
+
+	if (&unstructured.Unstructured{Object: object}).GetKind() != "Service" {
+		return pod, remotePort, nil
+	}
+	var service corev1.Service
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, &service); err != nil {
+		return nil, 0, fmt.Errorf("failed to read service: %w", err)
+	}
+	podPort, err := serviceTargetPort(&service, pod, remotePort)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pod, podPort, nil
+}
+
+// objectPodSelector returns the label selector with which a Service or a
+// workload selects its pods.
+func objectPodSelector(object *unstructured.Unstructured) (string, error) {
+	if object.GetKind() == "Service" {
+		selector, _, _ := unstructured.NestedStringMap(object.Object, "spec", "selector")
+		if len(selector) == 0 {
+			return "", fmt.Errorf("service %s has no selector", object.GetName())
+		}
+		return labels.SelectorFromSet(selector).String(), nil
+	}
+	raw, found, _ := unstructured.NestedMap(object.Object, "spec", "selector")
+	if !found {
+		return "", fmt.Errorf("%s %s has no pod selector", object.GetKind(), object.GetName())
+	}
+	var labelSelector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &labelSelector); err != nil {
+		return "", fmt.Errorf("failed to read selector: %w", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector: %w", err)
+	}
+	return selector.String(), nil
+}
+
+// firstRunningPod returns the running pod that was created first, or nil.
+func firstRunningPod(pods []corev1.Pod) *corev1.Pod {
+	var first *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if first == nil || pod.CreationTimestamp.Before(&first.CreationTimestamp) {
+			first = pod
+		}
+	}
+	return first
+}
+
+// serviceTargetPort translates a service port into the port of pod that it
+// targets, resolving named target ports against the pod's container ports.
+func serviceTargetPort(service *corev1.Service, pod *corev1.Pod, port int) (int, error) {
+	for _, servicePort := range service.Spec.Ports {
+		if int(servicePort.Port) != port {
+			continue
+		}
+		target := servicePort.TargetPort
+		switch {
+		case target.Type == intstr.Int && target.IntVal == 0:
+			return port, nil
+		case target.Type == intstr.Int:
+			return int(target.IntVal), nil
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == target.StrVal {
+					return int(containerPort.ContainerPort), nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("pod %s has no container port named %s", pod.Name, target.StrVal)
+	}
+	ports := make([]string, 0, len(service.Spec.Ports))
+	for _, servicePort := range service.Spec.Ports {
+		ports = append(ports, strconv.Itoa(int(servicePort.Port)))
+	}
+	return 0, fmt.Errorf("service %s has no port %d (ports: %v)", service.Name, port, ports)
+}
@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// TestServiceTargetPort tests translating service ports to the ports of a selected pod
+func TestServiceTargetPort(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Port: 80, TargetPort: intstr.FromString("http")},
+			{Port: 443, TargetPort: intstr.FromInt32(8443)},
+			{Port: 9090},
+		}},
+	}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "app", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+	}}}
+
+	for port, want := range map[int]int{80: 8080, 443: 8443, 9090: 9090} {
+		if got, err := serviceTargetPort(service, pod, port); err != nil || got != want {
+			t.Errorf("serviceTargetPort(%d) = %d, %v; want %d", port, got, err, want)
+		}
+	}
+	if _, err := serviceTargetPort(service, pod, 8080); err == nil {
+		t.Error("Expected an error for a port the service does not expose")
+	}
+}
+
+// TestObjectPodSelector tests reading the pod selector of services and workloads
+func TestObjectPodSelector(t *testing.T) {
+	service := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Service",
+		"spec": map[string]interface{}{"selector": map[string]interface{}{"app": "web"}},
+	}}
+	if selector, err := objectPodSelector(service); err != nil || selector != "app=web" {
+		t.Errorf("Unexpected service selector %q, %v", selector, err)
+	}
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": "api"},
+		}},
+	}}
+	if selector, err := objectPodSelector(deployment); err != nil || selector != "app=api" {
+		t.Errorf("Unexpected deployment selector %q, %v", selector, err)
+	}
+}
+
+// TestPortForwardsPerSession tests that sessions only see and stop their own forwards
+func TestPortForwardsPerSession(t *testing.T) {
+	var forwards portForwards
+	a := &portForward{session: "a", stop: make(chan struct{})}
+	b := &portForward{session: "b", stop: make(chan struct{})}
+	if err := forwards.add(a); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := forwards.add(b); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if list := forwards.list("a"); len(list) != 1 || list[0] != a {
+		t.Errorf("Expected only session a's forward, got %v", list)
+	}
+	if _, ok := forwards.remove("a", b.id); ok {
+		t.Error("Expected session a not to remove session b's forward")
+	}
+	if _, ok := forwards.remove("b", b.id); !ok {
+		t.Error("Expected session b to remove its forward")
+	}
+}
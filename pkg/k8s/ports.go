@@ -0,0 +1,295 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultNodePortRange is the API server's default --service-node-port-range.
+const defaultNodePortRange = "30000-32767"
+
+// nodePortExhaustionPercent is the share of the NodePort range in use above
+// which the report warns that the range is nearly exhausted.
+const nodePortExhaustionPercent = 90
+
+// parsePortRange parses a port range such as 30000-32767.
+func parsePortRange(portRange string) (int32, int32, error) {
+	low, high, found := strings.Cut(portRange, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid port range %q: expected min-max", portRange)
+	}
+	first, err := strconv.ParseInt(strings.TrimSpace(low), 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: %w", portRange, err)
+	}
+	last, err := strconv.ParseInt(strings.TrimSpace(high), 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: %w", portRange, err)
+	}
+	if first < 1 || last > 65535 || first > last {
+		return 0, 0, fmt.Errorf("invalid port range %q: must be within 1-65535 with min <= max", portRange)
+	}
+	return int32(first), int32(last), nil
+}
+
+// GetPortAllocations reports the NodePorts allocated to Services and the
+// hostPorts declared by pods across the cluster, with their owners. It flags
+// NodePorts held by more than one Service, hostPorts two pods on the same
+// node bind on overlapping addresses, and hostPorts that kube-proxy shadows
+// because a Service holds the same NodePort. NodePort usage is measured
+// against nodePortRange, the API server's --service-node-port-range, which
+// defaults to 30000-32767; a nearly exhausted range is reported in
+// "warnings", as are NodePorts outside it, which suggest a different range.
+// Pods that have finished are skipped, and pods not yet scheduled are listed
+// without a node.
+// Returns a map with the "nodePorts", "nodePortUsage", "hostPorts",
+// "conflicts" and "warnings", or an error.
+func (c *Client) GetPortAllocations(ctx context.Context, nodePortRange string) (map[string]interface{}, error) {
+	if nodePortRange == "" {
+		nodePortRange = defaultNodePortRange
+	}
+	first, last, err := parsePortRange(nodePortRange)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := c.clientset.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	pods, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	conflicts := []map[string]interface{}{}
+	warnings := []string{}
+
+	// The API server allocates NodePorts by number, whatever the protocol
+	nodePorts := []map[string]interface{}{}
+	holders := map[int32][]string{}
+	protocols := map[string][]string{}
+	for _, svc := range services.Items {
+		service := svc.Namespace + "/" + svc.Name
+		add := func(port int32, protocol corev1.Protocol, usedBy string) {
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			nodePorts = append(nodePorts, map[string]interface{}{
+				"port":      port,
+				"protocol":  string(protocol),
+				"service":   svc.Name,
+				"namespace": svc.Namespace,
+				"type":      string(svc.Spec.Type),
+				"usedBy":    usedBy,
+			})
+			if !slices.Contains(holders[port], service) {
+				holders[port] = append(holders[port], service)
+			}
+			key := fmt.Sprintf("%d/%s", port, protocol)
+			if !slices.Contains(protocols[key], service) {
+				protocols[key] = append(protocols[key], service)
+			}
+		}
+		for _, port := range svc.Spec.Ports {
+			if port.NodePort != 0 {
+				add(port.NodePort, port.Protocol, "port "+portLabel(port))
+			}
+		}
+		if svc.Spec.HealthCheckNodePort != 0 {
+			add(svc.Spec.HealthCheckNodePort, corev1.ProtocolTCP, "healthCheckNodePort")
+		}
+	}
+	sort.SliceStable(nodePorts, func(i, j int) bool { return nodePorts[i]["port"].(int32) < nodePorts[j]["port"].(int32) })
+
+	allocated := make([]int32, 0, len(holders))
+	for port := range holders {
+		allocated = append(allocated, port)
+	}
+	slices.Sort(allocated)
+	used := 0
+	var outside []int32
+	for _, port := range allocated {
+		services := holders[port]
+		if port >= first && port <= last {
+			used++
+		} else {
+			outside = append(outside, port)
+		}
+		if len(services) > 1 {
+			sort.Strings(services)
+			conflicts = append(conflicts, map[string]interface{}{
+				"kind":     "nodePort",
+				"port":     port,
+				"services": services,
+				"message":  fmt.Sprintf("NodePort %d is held by %d services", port, len(services)),
+			})
+		}
+	}
+	capacity := int(last-first) + 1
+	percent := float64(used) * 100 / float64(capacity)
+	result := map[string]interface{}{
+		"nodePortRange": nodePortRange,
+		"nodePortUsage": map[string]interface{}{
+			"used":      used,
+			"capacity":  capacity,
+			"free":      capacity - used,
+			"percent":   float64(int(percent*10)) / 10,
+			"exhausted": used >= capacity,
+		},
+	}
+	if percent >= nodePortExhaustionPercent {
+		warnings = append(warnings, fmt.Sprintf("the NodePort range %s is %.1f%% allocated (%d free); new NodePort and LoadBalancer services will fail to allocate a port", nodePortRange, percent, capacity-used))
+	}
+	if len(outside) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d NodePorts, e.g. %d, are outside %s; pass the API server's --service-node-port-range as nodePortRange", len(outside), outside[0], nodePortRange))
+	}
+
+	type hostPortUse struct {
+		entry map[string]interface{}
+		pod   string
+		ip    string
+	}
+	hostPorts := []map[string]interface{}{}
+	byNodePort := map[string][]hostPortUse{}
+	shadowed := map[string][]string{}
+	owners := map[string]map[string]interface{}{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		var controller map[string]interface{}
+		for _, ctr := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+			for _, port := range ctr.Ports {
+				if port.HostPort == 0 {
+					continue
+				}
+				if controller == nil {
+					controller = c.podController(ctx, pod, owners)
+				}
+				protocol := port.Protocol
+				if protocol == "" {
+					protocol = corev1.ProtocolTCP
+				}
+				entry := map[string]interface{}{
+					"node":      pod.Spec.NodeName,
+					"hostPort":  port.HostPort,
+					"protocol":  string(protocol),
+					"pod":       pod.Name,
+					"namespace": pod.Namespace,
+					"container": ctr.Name,
+				}
+				if port.HostIP != "" {
+					entry["hostIP"] = port.HostIP
+				}
+				if pod.Spec.HostNetwork {
+					entry["hostNetwork"] = true
+				}
+				if controller != nil {
+					entry["controller"] = controller
+				}
+				hostPorts = append(hostPorts, entry)
+
+				key := fmt.Sprintf("%d/%s", port.HostPort, protocol)
+				if len(protocols[key]) > 0 && !slices.Contains(shadowed[key], pod.Namespace+"/"+pod.Name) {
+					shadowed[key] = append(shadowed[key], pod.Namespace+"/"+pod.Name)
+				}
+				if pod.Spec.NodeName != "" {
+					nodeKey := pod.Spec.NodeName + "/" + key
+					byNodePort[nodeKey] = append(byNodePort[nodeKey], hostPortUse{entry, pod.Namespace + "/" + pod.Name, port.HostIP})
+				}
+			}
+		}
+	}
+	sort.SliceStable(hostPorts, func(i, j int) bool {
+		if hostPorts[i]["node"] != hostPorts[j]["node"] {
+			return hostPorts[i]["node"].(string) < hostPorts[j]["node"].(string)
+		}
+		return hostPorts[i]["hostPort"].(int32) < hostPorts[j]["hostPort"].(int32)
+	})
+
+	// kube-proxy serves NodePorts on every node, ahead of hostPorts
+	shadowedKeys := make([]string, 0, len(shadowed))
+	for key := range shadowed {
+		shadowedKeys = append(shadowedKeys, key)
+	}
+	sort.Strings(shadowedKeys)
+	for _, key := range shadowedKeys {
+		port, protocol, _ := strings.Cut(key, "/")
+		number, _ := strconv.ParseInt(port, 10, 32)
+		services := protocols[key]
+		conflicts = append(conflicts, map[string]interface{}{
+			"kind":     "hostPortShadowedByNodePort",
+			"port":     int32(number),
+			"protocol": protocol,
+			"pods":     shadowed[key],
+			"services": services,
+			"message":  fmt.Sprintf("hostPort %s of %d pods is also a NodePort of service %s; kube-proxy captures its traffic on every node", key, len(shadowed[key]), services[0]),
+		})
+	}
+
+	// Two pods conflict when one binds all addresses or both bind the same one
+	keys := make([]string, 0, len(byNodePort))
+	for key := range byNodePort {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		uses := byNodePort[key]
+		var clashing []string
+		for i, a := range uses {
+			for _, b := range uses[i+1:] {
+				if a.pod == b.pod {
+					continue
+				}
+				if wildcardIP(a.ip) || wildcardIP(b.ip) || a.ip == b.ip {
+					if !slices.Contains(clashing, a.pod) {
+						clashing = append(clashing, a.pod)
+					}
+					if !slices.Contains(clashing, b.pod) {
+						clashing = append(clashing, b.pod)
+					}
+				}
+			}
+		}
+		if len(clashing) == 0 {
+			continue
+		}
+		first := uses[0].entry
+		conflicts = append(conflicts, map[string]interface{}{
+			"kind":     "hostPort",
+			"node":     first["node"],
+			"port":     first["hostPort"],
+			"protocol": first["protocol"],
+			"pods":     clashing,
+			"message":  fmt.Sprintf("hostPort %d/%s on node %s is bound by %d pods", first["hostPort"], first["protocol"], first["node"], len(clashing)),
+		})
+	}
+
+	result["nodePorts"] = nodePorts
+	result["hostPorts"] = hostPorts
+	result["conflicts"] = conflicts
+	result["warnings"] = warnings
+	return result, nil
+}
+
+// portLabel names a Service port by its name, or its number if unnamed.
+func portLabel(port corev1.ServicePort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return strconv.Itoa(int(port.Port))
+}
+
+// wildcardIP reports whether a hostIP binds all addresses.
+func wildcardIP(ip string) bool {
+	return ip == "" || ip == "0.0.0.0" || ip == "::"
+}
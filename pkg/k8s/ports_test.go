@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestGetPortAllocations tests reporting NodePorts and hostPorts and their conflicts
+func TestGetPortAllocations(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/services":
+			w.Write([]byte(`{"kind":"ServiceList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"web","namespace":"shop"},"spec":{"type":"NodePort","ports":[{"name":"http","port":80,"nodePort":30080}]}},` +
+				`{"metadata":{"name":"lb","namespace":"shop"},"spec":{"type":"LoadBalancer","healthCheckNodePort":30500,"ports":[{"port":443,"nodePort":30443}]}},` +
+				`{"metadata":{"name":"legacy","namespace":"old"},"spec":{"type":"NodePort","ports":[{"port":80,"nodePort":30080}]}},` +
+				`{"metadata":{"name":"api","namespace":"shop"},"spec":{"type":"ClusterIP","ports":[{"port":80}]}}]}`))
+		case "/api/v1/pods":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"proxy-a","namespace":"edge"},"spec":{"nodeName":"node-1","containers":[{"name":"proxy","image":"envoy","ports":[{"containerPort":8080,"hostPort":8080}]}]},"status":{"phase":"Running"}},` +
+				`{"metadata":{"name":"proxy-b","namespace":"edge"},"spec":{"nodeName":"node-1","containers":[{"name":"proxy","image":"envoy","ports":[{"containerPort":8080,"hostPort":8080,"hostIP":"10.0.0.5"}]}]},"status":{"phase":"Running"}},` +
+				`{"metadata":{"name":"proxy-c","namespace":"edge"},"spec":{"nodeName":"node-2","containers":[{"name":"proxy","image":"envoy","ports":[{"containerPort":8080,"hostPort":8080}]}]},"status":{"phase":"Running"}},` +
+				`{"metadata":{"name":"agent","namespace":"edge"},"spec":{"nodeName":"node-2","containers":[{"name":"agent","image":"agent","ports":[{"containerPort":30443,"hostPort":30443}]}]},"status":{"phase":"Running"}},` +
+				`{"metadata":{"name":"done","namespace":"edge"},"spec":{"nodeName":"node-2","containers":[{"name":"job","image":"job","ports":[{"containerPort":8080,"hostPort":8080}]}]},"status":{"phase":"Succeeded"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	if _, err := client.GetPortAllocations(context.Background(), "32767-30000"); err == nil {
+		t.Error("Expected an inverted range to be rejected")
+	}
+	result, err := client.GetPortAllocations(context.Background(), "30000-30009")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nodePorts := result["nodePorts"].([]map[string]interface{}); len(nodePorts) != 4 || nodePorts[0]["port"] != int32(30080) {
+		t.Errorf("Expected 4 NodePorts sorted by port, got %v", nodePorts)
+	}
+	if usage := result["nodePortUsage"].(map[string]interface{}); usage["used"] != 0 || usage["capacity"] != 10 {
+		t.Errorf("Expected no NodePort within the range, got %v", usage)
+	}
+	if warnings := result["warnings"].([]string); len(warnings) != 1 || !strings.Contains(warnings[0], "3 NodePorts, e.g. 30080, are outside") {
+		t.Errorf("Expected a warning about NodePorts outside the range, got %v", warnings)
+	}
+	if hostPorts := result["hostPorts"].([]map[string]interface{}); len(hostPorts) != 4 {
+		t.Errorf("Expected the hostPorts of 4 running pods, got %v", hostPorts)
+	}
+
+	conflicts := result["conflicts"].([]map[string]interface{})
+	kinds := map[string]map[string]interface{}{}
+	for _, conflict := range conflicts {
+		kinds[conflict["kind"].(string)] = conflict
+	}
+	if len(conflicts) != 3 {
+		t.Fatalf("Expected 3 conflicts, got %v", conflicts)
+	}
+	if services := kinds["nodePort"]["services"].([]string); len(services) != 2 || services[0] != "old/legacy" {
+		t.Errorf("Expected NodePort 30080 to be held by two services, got %v", kinds["nodePort"])
+	}
+	if conflict := kinds["hostPort"]; conflict["node"] != "node-1" || len(conflict["pods"].([]string)) != 2 {
+		t.Errorf("Expected proxy-a and proxy-b to conflict on node-1, got %v", conflict)
+	}
+	if conflict := kinds["hostPortShadowedByNodePort"]; conflict["port"] != int32(30443) || conflict["pods"].([]string)[0] != "edge/agent" {
+		t.Errorf("Expected hostPort 30443 to be shadowed by the lb NodePort, got %v", conflict)
+	}
+}
@@ -0,0 +1,138 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// crdGVR is the resource of CustomResourceDefinitions.
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// PrinterColumn is an additionalPrinterColumn of a CustomResourceDefinition
+// version: a named column whose value is read from each object by a JSONPath.
+type PrinterColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	JSONPath string `json:"jsonPath"`
+	Priority int64  `json:"priority,omitempty"`
+}
+
+// GetPrinterColumns returns the additionalPrinterColumns that the
+// CustomResourceDefinition of kind declares for the served version. Built-in
+// kinds, which have no CustomResourceDefinition, have no printer columns.
+// Returns the columns, or an error.
+func (c *Client) GetPrinterColumns(ctx context.Context, kind string) ([]PrinterColumn, error) {
+	info, err := c.getCachedResource(kind)
+	if err != nil {
+		return nil, err
+	}
+	if info.gvr.Group == "" || !strings.Contains(info.gvr.Group, ".") {
+		return nil, nil
+	}
+
+	crd, err := c.dynamicClient.Resource(crdGVR).Get(ctx, info.gvr.Resource+"."+info.gvr.Group, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CustomResourceDefinition: %w", err)
+	}
+	return crdPrinterColumns(crd, info.gvr.Version), nil
+}
+
+// crdPrinterColumns reads the additionalPrinterColumns of a version of a
+// CustomResourceDefinition.
+func crdPrinterColumns(crd *unstructured.Unstructured, version string) []PrinterColumn {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, raw := range versions {
+		v, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(v, "name"); name != version {
+			continue
+		}
+		rawColumns, _, _ := unstructured.NestedSlice(v, "additionalPrinterColumns")
+		var columns []PrinterColumn
+		for _, rawColumn := range rawColumns {
+			column, ok := rawColumn.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(column, "name")
+			columnType, _, _ := unstructured.NestedString(column, "type")
+			path, _, _ := unstructured.NestedString(column, "jsonPath")
+			priority, _, _ := unstructured.NestedInt64(column, "priority")
+			if name != "" && path != "" {
+				columns = append(columns, PrinterColumn{Name: name, Type: columnType, JSONPath: path, Priority: priority})
+			}
+		}
+		return columns
+	}
+	return nil
+}
+
+// PrinterColumnView projects objects onto their name, namespace, creation
+// timestamp and the values of the given printer columns, like kubectl get
+// prints custom resources. Columns with a priority, which kubectl only prints
+// with -o wide, are left out. Values missing from an object are omitted.
+// Returns one row per object.
+func PrinterColumnView(objects []map[string]interface{}, columns []PrinterColumn) []map[string]interface{} {
+	type compiledColumn struct {
+		name string
+		path *jsonpath.JSONPath
+	}
+	var compiled []compiledColumn
+	for _, column := range columns {
+		if column.Priority > 0 {
+			continue
+		}
+		path := jsonpath.New(column.Name).AllowMissingKeys(true)
+		if err := path.Parse("{" + column.JSONPath + "}"); err != nil {
+			continue
+		}
+		compiled = append(compiled, compiledColumn{name: column.Name, path: path})
+	}
+
+	rows := make([]map[string]interface{}, 0, len(objects))
+	for _, object := range objects {
+		obj := &unstructured.Unstructured{Object: object}
+		row := map[string]interface{}{"name": obj.GetName(), "creationTimestamp": obj.GetCreationTimestamp()}
+		if obj.GetNamespace() != "" {
+			row["namespace"] = obj.GetNamespace()
+		}
+		for _, column := range compiled {
+			if value, ok := printerColumnValue(column.path, object); ok {
+				row[column.name] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// printerColumnValue evaluates a printer column against an object. A single
+// match keeps its JSON type; several matches are joined with commas, as
+// kubectl prints them.
+func printerColumnValue(path *jsonpath.JSONPath, object map[string]interface{}) (interface{}, bool) {
+	results, err := path.FindResults(object)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return nil, false
+	}
+	values := results[0]
+	if len(values) == 1 {
+		return values[0].Interface(), true
+	}
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, fmt.Sprint(value.Interface()))
+	}
+	return strings.Join(parts, ","), true
+}
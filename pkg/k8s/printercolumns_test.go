@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestPrinterColumnView tests summarizing custom resources by their CRD's printer columns
+func TestPrinterColumnView(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1alpha1"},
+				map[string]interface{}{
+					"name": "v1",
+					"additionalPrinterColumns": []interface{}{
+						map[string]interface{}{"name": "Ready", "type": "string", "jsonPath": `.status.conditions[?(@.type=="Ready")].status`},
+						map[string]interface{}{"name": "Secret", "type": "string", "jsonPath": ".spec.secretName"},
+						map[string]interface{}{"name": "Hosts", "type": "string", "jsonPath": ".spec.dnsNames[*]"},
+						map[string]interface{}{"name": "Issuer", "type": "string", "jsonPath": ".spec.issuerRef.name", "priority": int64(1)},
+					},
+				},
+			},
+		},
+	}}
+	columns := crdPrinterColumns(crd, "v1")
+	if len(columns) != 4 || columns[3].Priority != 1 {
+		t.Fatalf("Unexpected columns %+v", columns)
+	}
+	if crdPrinterColumns(crd, "v1alpha1") != nil {
+		t.Error("Expected no columns for a version without printer columns")
+	}
+
+	certificate := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "api-tls", "namespace": "prod"},
+		"spec": map[string]interface{}{
+			"secretName": "api-tls",
+			"dnsNames":   []interface{}{"api.example.com", "www.example.com"},
+			"issuerRef":  map[string]interface{}{"name": "letsencrypt"},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		},
+	}
+	pending := map[string]interface{}{"metadata": map[string]interface{}{"name": "new-tls", "namespace": "prod"}}
+
+	rows := PrinterColumnView([]map[string]interface{}{certificate, pending}, columns)
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	row := rows[0]
+	if row["name"] != "api-tls" || row["namespace"] != "prod" || row["Ready"] != "True" || row["Secret"] != "api-tls" {
+		t.Errorf("Unexpected row %v", row)
+	}
+	if row["Hosts"] != "api.example.com,www.example.com" {
+		t.Errorf("Expected joined hosts, got %v", row["Hosts"])
+	}
+	if _, ok := row["Issuer"]; ok {
+		t.Error("Expected wide columns to be left out")
+	}
+	if _, ok := rows[1]["Ready"]; ok {
+		t.Errorf("Expected missing values to be omitted, got %v", rows[1])
+	}
+}
@@ -0,0 +1,189 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// lastAppliedAnnotation holds the configuration last applied with kubectl apply.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// serverSetAnnotations are annotations maintained by controllers or kubectl
+// that are stripped from cleaned manifests.
+var serverSetAnnotations = []string{
+	lastAppliedAnnotation,
+	"deployment.kubernetes.io/revision",
+	"kubectl.kubernetes.io/restartedAt",
+}
+
+// GetWorkloadManifest returns the cleaned manifest of a resource together with
+// metadata describing where it is managed from: the Helm release, Argo CD
+// application, Flux Kustomization or HelmRelease that owns it, the
+// configuration last applied with kubectl, and the field managers recorded in
+// managedFields. The manifest has status and server-populated metadata removed
+// so that it can be compared with the source in a repository.
+// Returns a map with "manifest", "provenance" and "recommendation", or an error.
+func (c *Client) GetWorkloadManifest(ctx context.Context, kind, name, namespace string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	object, err := c.GetResource(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s/%s: %w", kind, name, err)
+	}
+	obj := &unstructured.Unstructured{Object: object}
+
+	provenance := workloadProvenance(obj)
+	return map[string]interface{}{
+		"manifest":       cleanManifest(obj),
+		"provenance":     provenance,
+		"recommendation": provenanceRecommendation(provenance),
+	}, nil
+}
+
+// cleanManifest returns a copy of obj without status, managedFields and other
+// metadata populated by the API server or controllers.
+func cleanManifest(obj *unstructured.Unstructured) map[string]interface{} {
+	cleaned := obj.DeepCopy()
+	unstructured.RemoveNestedField(cleaned.Object, "status")
+	for _, field := range []string{"managedFields", "uid", "resourceVersion", "generation", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(cleaned.Object, "metadata", field)
+	}
+
+	annotations := cleaned.GetAnnotations()
+	for _, annotation := range serverSetAnnotations {
+		delete(annotations, annotation)
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(cleaned.Object, "metadata", "annotations")
+	} else {
+		cleaned.SetAnnotations(annotations)
+	}
+	return cleaned.Object
+}
+
+// workloadProvenance collects the Helm, Argo CD, Flux and kubectl metadata of
+// an object from its labels, annotations and managedFields.
+func workloadProvenance(obj *unstructured.Unstructured) map[string]interface{} {
+	labels := obj.GetLabels()
+	annotations := obj.GetAnnotations()
+	provenance := map[string]interface{}{}
+
+	if release := annotations["meta.helm.sh/release-name"]; release != "" || labels["app.kubernetes.io/managed-by"] == "Helm" {
+		helm := map[string]interface{}{
+			"releaseName":      release,
+			"releaseNamespace": annotations["meta.helm.sh/release-namespace"],
+		}
+		if chart := labels["helm.sh/chart"]; chart != "" {
+			helm["chart"] = chart
+		}
+		provenance["helm"] = helm
+	}
+
+	if trackingID := annotations["argocd.argoproj.io/tracking-id"]; trackingID != "" {
+		provenance["argocd"] = map[string]interface{}{
+			"application": strings.SplitN(trackingID, ":", 2)[0],
+			"trackingId":  trackingID,
+		}
+	} else if instance := labels["argocd.argoproj.io/instance"]; instance != "" {
+		provenance["argocd"] = map[string]interface{}{"application": instance}
+	}
+
+	for _, source := range []struct{ key, prefix string }{
+		{"fluxKustomization", "kustomize.toolkit.fluxcd.io"},
+		{"fluxHelmRelease", "helm.toolkit.fluxcd.io"},
+	} {
+		if fluxName := labels[source.prefix+"/name"]; fluxName != "" {
+			provenance[source.key] = map[string]interface{}{
+				"name":      fluxName,
+				"namespace": labels[source.prefix+"/namespace"],
+			}
+		}
+	}
+
+	if lastApplied := annotations[lastAppliedAnnotation]; lastApplied != "" {
+		var applied map[string]interface{}
+		if err := json.Unmarshal([]byte(lastApplied), &applied); err == nil {
+			provenance["kubectlLastApplied"] = applied
+		} else {
+			provenance["kubectlLastApplied"] = lastApplied
+		}
+	}
+
+	var managers []map[string]interface{}
+	for _, entry := range obj.GetManagedFields() {
+		manager := map[string]interface{}{
+			"manager":   entry.Manager,
+			"operation": string(entry.Operation),
+		}
+		if entry.Subresource != "" {
+			manager["subresource"] = entry.Subresource
+		}
+		if entry.Time != nil {
+			manager["time"] = entry.Time
+		}
+		managers = append(managers, manager)
+	}
+	if managers != nil {
+		provenance["fieldManagers"] = managers
+	}
+
+	var owners []map[string]interface{}
+	for _, ref := range obj.GetOwnerReferences() {
+		owners = append(owners, map[string]interface{}{"kind": ref.Kind, "name": ref.Name})
+	}
+	if owners != nil {
+		provenance["ownerReferences"] = owners
+	}
+	return provenance
+}
+
+// provenanceRecommendation describes where changes to the object should be
+// made, based on its provenance.
+func provenanceRecommendation(provenance map[string]interface{}) string {
+	var sources []string
+	if argocd, ok := provenance["argocd"].(map[string]interface{}); ok {
+		sources = append(sources, fmt.Sprintf("Argo CD application %q", argocd["application"]))
+	}
+	for _, key := range []string{"fluxKustomization", "fluxHelmRelease"} {
+		if flux, ok := provenance[key].(map[string]interface{}); ok {
+			kind := "Kustomization"
+			if key == "fluxHelmRelease" {
+				kind = "HelmRelease"
+			}
+			sources = append(sources, fmt.Sprintf("Flux %s %s/%s", kind, flux["namespace"], flux["name"]))
+		}
+	}
+	if helm, ok := provenance["helm"].(map[string]interface{}); ok {
+		sources = append(sources, fmt.Sprintf("Helm release %s/%s", helm["releaseNamespace"], helm["releaseName"]))
+	}
+
+	if len(sources) > 0 {
+		return "Managed by " + strings.Join(sources, ", ") + ". Change the source in that repository or its values; live edits will be reverted or drift from the source."
+	}
+	if owners, ok := provenance["ownerReferences"].([]map[string]interface{}); ok {
+		return fmt.Sprintf("Owned by %s %s; change the owner instead of this object.", owners[0]["kind"], owners[0]["name"])
+	}
+	if _, ok := provenance["kubectlLastApplied"]; ok {
+		return "Last applied with kubectl apply; update the manifest it was applied from and re-apply it."
+	}
+	if managers, ok := provenance["fieldManagers"].([]map[string]interface{}); ok {
+		names := map[string]bool{}
+		for _, manager := range managers {
+			names[fmt.Sprint(manager["manager"])] = true
+		}
+		var list []string
+		for name := range names {
+			list = append(list, name)
+		}
+		sort.Strings(list)
+		return "No GitOps or Helm metadata found; fields are managed by " + strings.Join(list, ", ") + "."
+	}
+	return "No provenance metadata found."
+}
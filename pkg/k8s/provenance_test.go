@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestWorkloadProvenance tests cleaning a manifest and detecting its Helm and Argo CD provenance
+func TestWorkloadProvenance(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"namespace":       "prod",
+			"uid":             "1234",
+			"resourceVersion": "42",
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "Helm",
+				"helm.sh/chart":                "web-1.2.3",
+			},
+			"annotations": map[string]interface{}{
+				"meta.helm.sh/release-name":         "web",
+				"meta.helm.sh/release-namespace":    "prod",
+				"argocd.argoproj.io/tracking-id":    "web-prod:apps/Deployment:prod/web",
+				"deployment.kubernetes.io/revision": "3",
+			},
+		},
+		"spec":   map[string]interface{}{"replicas": int64(2)},
+		"status": map[string]interface{}{"readyReplicas": int64(2)},
+	}}
+
+	manifest := cleanManifest(obj)
+	if _, ok := manifest["status"]; ok {
+		t.Error("Expected status to be removed from the manifest")
+	}
+	metadata := manifest["metadata"].(map[string]interface{})
+	if _, ok := metadata["uid"]; ok {
+		t.Error("Expected uid to be removed from the manifest")
+	}
+	annotations := metadata["annotations"].(map[string]interface{})
+	if _, ok := annotations["deployment.kubernetes.io/revision"]; ok {
+		t.Error("Expected the revision annotation to be removed from the manifest")
+	}
+	if _, ok := obj.Object["status"]; !ok {
+		t.Error("Expected the original object to be left unchanged")
+	}
+
+	provenance := workloadProvenance(obj)
+	helm, ok := provenance["helm"].(map[string]interface{})
+	if !ok || helm["releaseName"] != "web" || helm["chart"] != "web-1.2.3" {
+		t.Errorf("Expected Helm release web with chart web-1.2.3, got %v", provenance["helm"])
+	}
+	argocd, ok := provenance["argocd"].(map[string]interface{})
+	if !ok || argocd["application"] != "web-prod" {
+		t.Errorf("Expected Argo CD application web-prod, got %v", provenance["argocd"])
+	}
+
+	recommendation := provenanceRecommendation(provenance)
+	if !strings.Contains(recommendation, `Argo CD application "web-prod"`) || !strings.Contains(recommendation, "Helm release prod/web") {
+		t.Errorf("Expected recommendation to name Argo CD and Helm, got %q", recommendation)
+	}
+}
@@ -0,0 +1,258 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RoleReference names a Role or ClusterRole. Kind may be empty, in which case
+// a Role is meant if Namespace is set and a ClusterRole otherwise.
+type RoleReference struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// rbacPermission is one verb on one resource, resource name or non-resource
+// URL, as granted by a PolicyRule. An empty resourceName stands for all names.
+type rbacPermission struct {
+	apiGroup       string
+	resource       string
+	resourceName   string
+	nonResourceURL string
+	verb           string
+}
+
+// CompareRoles diffs the rules of a Role or ClusterRole against those of
+// another role, or against a requested rule set when other is nil. Rules are
+// expanded into single permissions and compared the way the RBAC authorizer
+// evaluates them, so wildcards, subresources, resourceNames and
+// nonResourceURL prefixes are honoured: a permission counts as granted if
+// any rule of the other side allows it.
+// Returns a map with the "missing" permissions the reference has but the role
+// lacks, the "extra" permissions the role grants beyond the reference, and
+// whether the two are "equivalent", or an error.
+func (c *Client) CompareRoles(ctx context.Context, role RoleReference, other *RoleReference, requested []rbacv1.PolicyRule) (map[string]interface{}, error) {
+	roleRules, role, err := c.getRoleRules(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := map[string]interface{}{"rules": len(requested)}
+	referenceRules := requested
+	if other != nil {
+		var resolved RoleReference
+		if referenceRules, resolved, err = c.getRoleRules(ctx, *other); err != nil {
+			return nil, err
+		}
+		reference = roleReferenceSummary(resolved)
+	}
+
+	missing, extra := diffPolicyRules(roleRules, referenceRules)
+	return map[string]interface{}{
+		"role":       roleReferenceSummary(role),
+		"comparedTo": reference,
+		"missing":    missing,
+		"extra":      extra,
+		"equivalent": len(missing) == 0 && len(extra) == 0,
+	}, nil
+}
+
+// getRoleRules reads the rules of a Role or ClusterRole, returning the
+// reference with its kind resolved.
+func (c *Client) getRoleRules(ctx context.Context, ref RoleReference) ([]rbacv1.PolicyRule, RoleReference, error) {
+	switch strings.ToLower(ref.Kind) {
+	case "":
+		ref.Kind = "ClusterRole"
+		if ref.Namespace != "" {
+			ref.Kind = "Role"
+		}
+	case "role":
+		ref.Kind = "Role"
+	case "clusterrole":
+		ref.Kind = "ClusterRole"
+	default:
+		return nil, ref, fmt.Errorf("unsupported role kind %q: must be Role or ClusterRole", ref.Kind)
+	}
+
+	if ref.Kind == "ClusterRole" {
+		clusterRole, err := c.clientset.RbacV1().ClusterRoles().Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, ref, fmt.Errorf("failed to get ClusterRole %s: %w", ref.Name, err)
+		}
+		ref.Namespace = ""
+		return clusterRole.Rules, ref, nil
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = metav1.NamespaceDefault
+	}
+	role, err := c.clientset.RbacV1().Roles(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, ref, fmt.Errorf("failed to get Role %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	return role.Rules, ref, nil
+}
+
+// roleReferenceSummary describes a resolved role reference.
+func roleReferenceSummary(ref RoleReference) map[string]interface{} {
+	summary := map[string]interface{}{"kind": ref.Kind, "name": ref.Name}
+	if ref.Namespace != "" {
+		summary["namespace"] = ref.Namespace
+	}
+	return summary
+}
+
+// diffPolicyRules returns the permissions of reference that rules do not
+// grant, and the permissions of rules that reference does not grant, each
+// grouped into rules.
+func diffPolicyRules(rules, reference []rbacv1.PolicyRule) (missing, extra []map[string]interface{}) {
+	var missingPermissions, extraPermissions []rbacPermission
+	for _, permission := range expandPolicyRules(reference) {
+		if !rulesAllow(rules, permission) {
+			missingPermissions = append(missingPermissions, permission)
+		}
+	}
+	for _, permission := range expandPolicyRules(rules) {
+		if !rulesAllow(reference, permission) {
+			extraPermissions = append(extraPermissions, permission)
+		}
+	}
+	return groupPermissions(missingPermissions), groupPermissions(extraPermissions)
+}
+
+// expandPolicyRules expands rules into single permissions, without duplicates.
+func expandPolicyRules(rules []rbacv1.PolicyRule) []rbacPermission {
+	seen := map[rbacPermission]bool{}
+	var permissions []rbacPermission
+	add := func(permission rbacPermission) {
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			for _, url := range rule.NonResourceURLs {
+				add(rbacPermission{nonResourceURL: url, verb: verb})
+			}
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					if len(rule.ResourceNames) == 0 {
+						add(rbacPermission{apiGroup: group, resource: resource, verb: verb})
+					}
+					for _, name := range rule.ResourceNames {
+						add(rbacPermission{apiGroup: group, resource: resource, resourceName: name, verb: verb})
+					}
+				}
+			}
+		}
+	}
+	return permissions
+}
+
+// rulesAllow reports whether any of rules grants permission, following the
+// matching rules of the RBAC authorizer. A permission holding a wildcard is
+// only granted by a rule with the same wildcard.
+func rulesAllow(rules []rbacv1.PolicyRule, permission rbacPermission) bool {
+	for _, rule := range rules {
+		if !matchesRBAC(rule.Verbs, permission.verb) {
+			continue
+		}
+		if permission.nonResourceURL != "" {
+			for _, url := range rule.NonResourceURLs {
+				if url == rbacv1.NonResourceAll || url == permission.nonResourceURL ||
+					(strings.HasSuffix(url, "*") && strings.HasPrefix(permission.nonResourceURL, strings.TrimSuffix(url, "*"))) {
+					return true
+				}
+			}
+			continue
+		}
+		if !matchesRBAC(rule.APIGroups, permission.apiGroup) || !resourceMatches(rule.Resources, permission.resource) {
+			continue
+		}
+		if len(rule.ResourceNames) == 0 {
+			return true
+		}
+		if permission.resourceName != "" && matchesRBAC(rule.ResourceNames, permission.resourceName) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRBAC reports whether values contain value or the "*" wildcard.
+func matchesRBAC(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceMatches reports whether resources match resource, which may carry
+// a subresource, e.g. "pods/log". "*/log" matches the log subresource of any
+// resource.
+func resourceMatches(resources []string, resource string) bool {
+	if matchesRBAC(resources, resource) {
+		return true
+	}
+	if i := strings.Index(resource, "/"); i >= 0 {
+		for _, r := range resources {
+			if r == "*"+resource[i:] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// groupPermissions groups single permissions back into rules, one per API
+// group, resource and resource name or non-resource URL, with their verbs.
+func groupPermissions(permissions []rbacPermission) []map[string]interface{} {
+	type target struct{ apiGroup, resource, resourceName, nonResourceURL string }
+	verbs := map[target][]string{}
+	var order []target
+	for _, permission := range permissions {
+		key := target{permission.apiGroup, permission.resource, permission.resourceName, permission.nonResourceURL}
+		if _, ok := verbs[key]; !ok {
+			order = append(order, key)
+		}
+		verbs[key] = append(verbs[key], permission.verb)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.nonResourceURL != b.nonResourceURL {
+			return a.nonResourceURL < b.nonResourceURL
+		}
+		if a.apiGroup != b.apiGroup {
+			return a.apiGroup < b.apiGroup
+		}
+		if a.resource != b.resource {
+			return a.resource < b.resource
+		}
+		return a.resourceName < b.resourceName
+	})
+
+	grouped := []map[string]interface{}{}
+	for _, key := range order {
+		sort.Strings(verbs[key])
+		rule := map[string]interface{}{"verbs": verbs[key]}
+		if key.nonResourceURL != "" {
+			rule["nonResourceURL"] = key.nonResourceURL
+		} else {
+			rule["apiGroup"] = key.apiGroup
+			rule["resource"] = key.resource
+			if key.resourceName != "" {
+				rule["resourceName"] = key.resourceName
+			}
+		}
+		grouped = append(grouped, rule)
+	}
+	return grouped
+}
@@ -0,0 +1,67 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// TestRulesAllow tests matching permissions the way the RBAC authorizer does
+func TestRulesAllow(t *testing.T) {
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods", "*/log"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"web"}, Verbs: []string{"*"}},
+		{NonResourceURLs: []string{"/healthz/*"}, Verbs: []string{"get"}},
+	}
+	tests := []struct {
+		permission rbacPermission
+		allowed    bool
+	}{
+		{rbacPermission{resource: "pods", verb: "get"}, true},
+		{rbacPermission{resource: "pods", verb: "delete"}, false},
+		{rbacPermission{resource: "pods/log", verb: "get"}, true},
+		{rbacPermission{resource: "pods/exec", verb: "get"}, false},
+		{rbacPermission{apiGroup: "apps", resource: "deployments", resourceName: "web", verb: "patch"}, true},
+		{rbacPermission{apiGroup: "apps", resource: "deployments", verb: "patch"}, false},
+		{rbacPermission{apiGroup: "apps", resource: "deployments", resourceName: "api", verb: "get"}, false},
+		{rbacPermission{nonResourceURL: "/healthz/etcd", verb: "get"}, true},
+		{rbacPermission{nonResourceURL: "/metrics", verb: "get"}, false},
+		{rbacPermission{resource: "*", verb: "get"}, false},
+	}
+	for _, test := range tests {
+		if allowed := rulesAllow(rules, test.permission); allowed != test.allowed {
+			t.Errorf("rulesAllow(%+v) = %v, want %v", test.permission, allowed, test.allowed)
+		}
+	}
+}
+
+// TestDiffPolicyRules tests reporting missing and extra permissions
+func TestDiffPolicyRules(t *testing.T) {
+	role := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "delete"}},
+	}
+	requested := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}},
+	}
+
+	missing, extra := diffPolicyRules(role, requested)
+	wantMissing := []map[string]interface{}{
+		{"apiGroup": "", "resource": "pods", "verbs": []string{"watch"}},
+		{"apiGroup": "apps", "resource": "deployments", "verbs": []string{"get"}},
+	}
+	if !reflect.DeepEqual(missing, wantMissing) {
+		t.Errorf("missing = %v, want %v", missing, wantMissing)
+	}
+	wantExtra := []map[string]interface{}{{"apiGroup": "", "resource": "pods", "verbs": []string{"delete"}}}
+	if !reflect.DeepEqual(extra, wantExtra) {
+		t.Errorf("extra = %v, want %v", extra, wantExtra)
+	}
+
+	// A wildcard role grants everything requested, but is itself broader
+	missing, extra = diffPolicyRules([]rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}, requested)
+	if len(missing) != 0 || len(extra) != 1 {
+		t.Errorf("Unexpected diff against a wildcard role: missing %v, extra %v", missing, extra)
+	}
+}
@@ -0,0 +1,135 @@
+package k8s
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// readOnlyPostResources are the resources a read-only client may still POST
+// to: reviews, which ask the API server a question without storing anything,
+// and port forwarding, which opens a stream to a pod without changing it.
+var readOnlyPostResources = []string{
+	"/selfsubjectaccessreviews",
+	"/selfsubjectrulesreviews",
+	"/selfsubjectreviews",
+	"/subjectaccessreviews",
+	"/localsubjectaccessreviews",
+	"/portforward",
+}
+
+// readOnlyTransport refuses requests that could change the cluster.
+type readOnlyTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip passes reads, server-side dry runs and the POSTs of
+// readOnlyPostResources through and refuses every other request.
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dryRun := req.URL.Query().Get("dryRun")
+	if dryRun == "" && req.Method == http.MethodDelete && req.Body != nil {
+		var err error
+		if req, dryRun, err = deleteOptionsDryRun(req); err != nil {
+			return nil, err
+		}
+	}
+	if !readOnlyAllows(req.Method, req.URL.Path, dryRun) {
+		return nil, fmt.Errorf("refused by read-only mode: %s %s would change the cluster", req.Method, req.URL.Path)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// deleteOptionsDryRun returns the dry-run mode of a DELETE request that sends
+// its DeleteOptions in the body, as the dynamic client does, together with a
+// copy of the request whose body has not been read yet.
+func deleteOptionsDryRun(req *http.Request) (*http.Request, string, error) {
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read delete options: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(data))
+
+	var options metav1.DeleteOptions
+	if err := json.Unmarshal(data, &options); err != nil || len(options.DryRun) != 1 {
+		return clone, "", nil
+	}
+	return clone, options.DryRun[0], nil
+}
+
+// readOnlyAllows reports whether a read-only client may send a request.
+// Requests with dryRun=All are admitted by the API server but not persisted.
+func readOnlyAllows(method, path, dryRun string) bool {
+	if dryRun == metav1.DryRunAll {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		for _, suffix := range readOnlyPostResources {
+			if strings.HasSuffix(path, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WithReadOnly returns a copy of the client whose requests are refused before
+// they leave the server if they could change the cluster, as a safeguard
+// behind the server's read-only mode. Clients created by a ClientPool whose
+// default client is read-only are read-only as well.
+func (c *Client) WithReadOnly() (*Client, error) {
+	client, err := c.withTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &readOnlyTransport{next: rt}
+	})
+	if err != nil {
+		return nil, err
+	}
+	client.readOnly = true
+	return client, nil
+}
+
+// withTransport returns a copy of the client whose requests pass through the
+// transport wrap returns, keeping the safeguards of the client.
+func (c *Client) withTransport(wrap func(rt http.RoundTripper) http.RoundTripper) (*Client, error) {
+	return c.withConfig(func(config *rest.Config) {
+		config.Wrap(wrap)
+	})
+}
+
+// withConfig returns a copy of the client whose configuration configure has
+// modified, keeping the safeguards of the client.
+func (c *Client) withConfig(configure func(config *rest.Config)) (*Client, error) {
+	config := rest.CopyConfig(c.restConfig)
+	configure(config)
+
+	client, err := newClientForConfig(config, c.contextName)
+	if err != nil {
+		return nil, err
+	}
+	client.inCluster = c.inCluster
+	client.readOnly = c.readOnly
+	client.namespaceCheck = c.namespaceCheck
+	client.impersonating = c.impersonating
+	client.impersonatedAs = c.impersonatedAs
+	client.impersonator = c.impersonator
+	client.scanThreshold = c.scanThreshold
+	client.portForwards = c.portForwards
+	client.eventWatches = c.eventWatches
+	return client, nil
+}
+
+// ReadOnly reports whether the client refuses requests that could change the
+// cluster.
+func (c *Client) ReadOnly() bool {
+	return c.readOnly
+}
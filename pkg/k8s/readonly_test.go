@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestReadOnlyAllows tests which requests a read-only client may send
+func TestReadOnlyAllows(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		dryRun string
+		want   bool
+	}{
+		{http.MethodGet, "/api/v1/namespaces/web/pods", "", true},
+		{http.MethodPost, "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", "", true},
+		{http.MethodPost, "/api/v1/namespaces/web/pods/api-0/portforward", "", true},
+		{http.MethodPost, "/api/v1/namespaces/web/pods", "", false},
+		{http.MethodPost, "/api/v1/namespaces/web/pods/api-0/exec", "", false},
+		{http.MethodPatch, "/apis/apps/v1/namespaces/web/deployments/api", "", false},
+		{http.MethodPut, "/apis/apps/v1/namespaces/web/deployments/api/scale", "", false},
+		{http.MethodDelete, "/api/v1/namespaces/web/pods/api-0", "", false},
+		{http.MethodPatch, "/apis/apps/v1/namespaces/web/deployments/api", metav1.DryRunAll, true},
+	}
+	for _, tt := range tests {
+		if got := readOnlyAllows(tt.method, tt.path, tt.dryRun); got != tt.want {
+			t.Errorf("readOnlyAllows(%s %s, dryRun=%q) = %v, want %v", tt.method, tt.path, tt.dryRun, got, tt.want)
+		}
+	}
+}
+
+// TestWithReadOnly tests that a read-only client refuses writes before sending them
+func TestWithReadOnly(t *testing.T) {
+	var methods []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"web"}}`))
+	}))
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !readOnly.ReadOnly() || readOnly.ContextName() != "test" || client.ReadOnly() {
+		t.Errorf("Unexpected read-only flags or context")
+	}
+
+	ctx := context.Background()
+	if _, err := readOnly.clientset.CoreV1().Namespaces().Get(ctx, "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected reads to pass, got %v", err)
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	if _, err := readOnly.clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected the create to be refused, got %v", err)
+	}
+	namespaces := readOnly.dynamicClient.Resource(corev1.SchemeGroupVersion.WithResource("namespaces"))
+	if err := namespaces.Delete(ctx, "web", metav1.DeleteOptions{}); err == nil {
+		t.Error("Expected the delete to be refused")
+	}
+	if len(methods) != 1 || methods[0] != http.MethodGet {
+		t.Errorf("Expected only the read to reach the server, got %v", methods)
+	}
+	if err := namespaces.Delete(ctx, "web", metav1.DeleteOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+		t.Errorf("Expected a delete with dry-run options in its body to pass, got %v", err)
+	}
+}
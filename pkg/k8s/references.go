@@ -0,0 +1,195 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResolveSuffix marks a projected field path whose references to other objects
+// are fetched and summarized, e.g. "spec.serviceAccountName@resolve".
+const ResolveSuffix = "@resolve"
+
+// objectReference identifies an object referenced from another object's spec.
+// An empty namespace means the namespace of the referencing object.
+type objectReference struct {
+	kind      string
+	name      string
+	namespace string
+}
+
+// referenceNameKinds maps fields holding the name of another object to the
+// kind of that object.
+var referenceNameKinds = map[string]string{
+	"serviceAccountName": "ServiceAccount",
+	"nodeName":           "Node",
+	"priorityClassName":  "PriorityClass",
+	"runtimeClassName":   "RuntimeClass",
+	"storageClassName":   "StorageClass",
+	"volumeName":         "PersistentVolume",
+	"claimName":          "PersistentVolumeClaim",
+	"secretName":         "Secret",
+}
+
+// referenceObjectKinds maps fields holding a {name: ...} reference, such as a
+// configMap volume or a secretKeyRef, to the kind of the referenced object.
+var referenceObjectKinds = map[string]string{
+	"configMap":        "ConfigMap",
+	"configMapRef":     "ConfigMap",
+	"configMapKeyRef":  "ConfigMap",
+	"secret":           "Secret",
+	"secretRef":        "Secret",
+	"secretKeyRef":     "Secret",
+	"imagePullSecrets": "Secret",
+}
+
+// findReferences collects the object references in value, which was found in a
+// field named key. It recognizes name fields such as serviceAccountName,
+// {name: ...} references such as configMapKeyRef, and {kind, name} references
+// such as ownerReferences and scaleTargetRef, descending into maps and lists.
+func findReferences(key string, value interface{}, refs map[objectReference]bool) {
+	switch v := value.(type) {
+	case string:
+		if kind, ok := referenceNameKinds[key]; ok && v != "" {
+			refs[objectReference{kind: kind, name: v}] = true
+		}
+	case []interface{}:
+		for _, item := range v {
+			findReferences(key, item, refs)
+		}
+	case map[string]interface{}:
+		name, _ := v["name"].(string)
+		if kind, ok := referenceObjectKinds[key]; ok && name != "" {
+			refs[objectReference{kind: kind, name: name}] = true
+		}
+		if kind, ok := v["kind"].(string); ok && name != "" {
+			namespace, _ := v["namespace"].(string)
+			refs[objectReference{kind: kind, name: name, namespace: namespace}] = true
+		}
+		for childKey, child := range v {
+			findReferences(childKey, child, refs)
+		}
+	}
+}
+
+// referenceSummary summarizes a referenced object: whether it exists and the
+// few fields that matter when checking a reference. Secret values are never
+// included, only their keys.
+func referenceSummary(obj map[string]interface{}) map[string]interface{} {
+	u := unstructured.Unstructured{Object: obj}
+	summary := map[string]interface{}{"exists": true}
+	switch u.GetKind() {
+	case "ConfigMap", "Secret":
+		var keys []string
+		for _, field := range []string{"data", "binaryData", "stringData"} {
+			data, _, _ := unstructured.NestedMap(obj, field)
+			for key := range data {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		summary["keys"] = keys
+		if secretType, found, _ := unstructured.NestedString(obj, "type"); found {
+			summary["type"] = secretType
+		}
+	case "PersistentVolumeClaim":
+		phase, _, _ := unstructured.NestedString(obj, "status", "phase")
+		summary["phase"] = phase
+		if volume, found, _ := unstructured.NestedString(obj, "spec", "volumeName"); found {
+			summary["volumeName"] = volume
+		}
+		if capacity, found, _ := unstructured.NestedString(obj, "status", "capacity", "storage"); found {
+			summary["capacity"] = capacity
+		}
+	case "ServiceAccount":
+		pullSecrets, _, _ := unstructured.NestedSlice(obj, "imagePullSecrets")
+		summary["imagePullSecrets"] = len(pullSecrets)
+		if automount, found, _ := unstructured.NestedBool(obj, "automountServiceAccountToken"); found {
+			summary["automountServiceAccountToken"] = automount
+		}
+	case "Node":
+		unschedulable, _, _ := unstructured.NestedBool(obj, "spec", "unschedulable")
+		summary["unschedulable"] = unschedulable
+	}
+	if phase, found, _ := unstructured.NestedString(obj, "status", "phase"); found {
+		summary["phase"] = phase
+	}
+	for _, condition := range summarizeConditions(obj) {
+		if condition["type"] == "Ready" || condition["type"] == "Available" {
+			summary[strings.ToLower(condition["type"].(string))] = condition["status"]
+		}
+	}
+	return summary
+}
+
+// ResolveReferences finds the references to other objects in the given paths
+// of each object, such as a ServiceAccount, ConfigMap and Secret volumes or a
+// PersistentVolumeClaim, fetches each referenced object once and summarizes it.
+// Referenced objects are looked up in the namespace of the referencing object
+// unless the reference names one.
+// Returns, for each object, a map from path to the summaries of the objects
+// referenced there; a missing object has exists set to false and an object
+// that cannot be read has an error.
+func (c *Client) ResolveReferences(ctx context.Context, objects []map[string]interface{}, paths []string) []map[string]interface{} {
+	cache := map[objectReference]map[string]interface{}{}
+
+	resolved := make([]map[string]interface{}, len(objects))
+	for i, obj := range objects {
+		namespace := (&unstructured.Unstructured{Object: obj}).GetNamespace()
+		resolved[i] = map[string]interface{}{}
+		for _, path := range paths {
+			parts := strings.Split(path, ".")
+			value, found, _ := unstructured.NestedFieldNoCopy(obj, parts...)
+			if !found {
+				continue
+			}
+			refs := map[objectReference]bool{}
+			findReferences(parts[len(parts)-1], value, refs)
+
+			summaries := []map[string]interface{}{}
+			for ref := range refs {
+				if ref.namespace == "" {
+					ref.namespace = namespace
+				}
+				summary, ok := cache[ref]
+				if !ok {
+					summary = c.resolveReference(ctx, ref)
+					cache[ref] = summary
+				}
+				entry := map[string]interface{}{"kind": ref.kind, "name": ref.name}
+				for k, v := range summary {
+					entry[k] = v
+				}
+				summaries = append(summaries, entry)
+			}
+			sort.Slice(summaries, func(a, b int) bool {
+				if summaries[a]["kind"] != summaries[b]["kind"] {
+					return summaries[a]["kind"].(string) < summaries[b]["kind"].(string)
+				}
+				return summaries[a]["name"].(string) < summaries[b]["name"].(string)
+			})
+			resolved[i][path] = summaries
+		}
+	}
+	return resolved
+}
+
+// resolveReference fetches and summarizes a single referenced object.
+func (c *Client) resolveReference(ctx context.Context, ref objectReference) map[string]interface{} {
+	resource, err := c.resourceClient(ref.kind, ref.namespace, true)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+	obj, err := resource.Get(ctx, ref.name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		return map[string]interface{}{"exists": false}
+	case err != nil:
+		return map[string]interface{}{"error": err.Error()}
+	}
+	return referenceSummary(obj.Object)
+}
@@ -0,0 +1,68 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFindReferences tests collecting object references from pod spec fields
+func TestFindReferences(t *testing.T) {
+	spec := map[string]interface{}{
+		"serviceAccountName": "web",
+		"imagePullSecrets":   []interface{}{map[string]interface{}{"name": "registry"}},
+		"volumes": []interface{}{
+			map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "web-config"}},
+			map[string]interface{}{"name": "tls", "secret": map[string]interface{}{"secretName": "web-tls"}},
+			map[string]interface{}{"name": "data", "persistentVolumeClaim": map[string]interface{}{"claimName": "web-data"}},
+		},
+		"containers": []interface{}{map[string]interface{}{
+			"name": "web",
+			"env": []interface{}{map[string]interface{}{
+				"name":      "TOKEN",
+				"valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "web-token", "key": "token"}},
+			}},
+		}},
+	}
+	refs := map[objectReference]bool{}
+	findReferences("spec", spec, refs)
+	want := map[objectReference]bool{
+		{kind: "ServiceAccount", name: "web"}:             true,
+		{kind: "Secret", name: "registry"}:                true,
+		{kind: "ConfigMap", name: "web-config"}:           true,
+		{kind: "Secret", name: "web-tls"}:                 true,
+		{kind: "PersistentVolumeClaim", name: "web-data"}: true,
+		{kind: "Secret", name: "web-token"}:               true,
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("findReferences() = %v, want %v", refs, want)
+	}
+
+	refs = map[objectReference]bool{}
+	findReferences("claimRef", map[string]interface{}{"kind": "PersistentVolumeClaim", "name": "data", "namespace": "prod"}, refs)
+	if !refs[objectReference{kind: "PersistentVolumeClaim", name: "data", namespace: "prod"}] || len(refs) != 1 {
+		t.Errorf("Unexpected references for claimRef: %v", refs)
+	}
+}
+
+// TestReferenceSummary tests that summaries of referenced Secrets list keys but not values
+func TestReferenceSummary(t *testing.T) {
+	summary := referenceSummary(map[string]interface{}{
+		"kind": "Secret",
+		"type": "Opaque",
+		"data": map[string]interface{}{"password": "c2VjcmV0", "user": "YWRtaW4="},
+	})
+	want := map[string]interface{}{"exists": true, "type": "Opaque", "keys": []string{"password", "user"}}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("referenceSummary() = %v, want %v", summary, want)
+	}
+
+	summary = referenceSummary(map[string]interface{}{
+		"kind":   "PersistentVolumeClaim",
+		"spec":   map[string]interface{}{"volumeName": "pv-1"},
+		"status": map[string]interface{}{"phase": "Bound", "capacity": map[string]interface{}{"storage": "10Gi"}},
+	})
+	want = map[string]interface{}{"exists": true, "phase": "Bound", "volumeName": "pv-1", "capacity": "10Gi"}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("referenceSummary() = %v, want %v", summary, want)
+	}
+}
@@ -0,0 +1,180 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// kindCandidate is an API resource that matched a requested kind.
+type kindCandidate struct {
+	info  resourceInfo
+	kind  string
+	exact bool
+}
+
+// resolveKind finds the API resource for a kind using discovery data. Besides the
+// exact Kind ("Deployment") it accepts case-insensitive kinds ("deployment"),
+// plural and singular resource names ("deployments"), kubectl short names
+// ("deploy", "svc", "po", "cm") and group-qualified names ("deployments.apps")
+// to disambiguate kinds served by more than one group.
+// Exact Kind matches take precedence over other forms, and the core group wins
+// when several groups serve the same name. Returns the resolved resource, or an
+// error with suggestions when the kind is ambiguous or unknown.
+func resolveKind(resourceLists []*metav1.APIResourceList, kind string) (*resourceInfo, error) {
+	name, group, qualified := strings.Cut(kind, ".")
+	lowerName := strings.ToLower(name)
+
+	var candidates []kindCandidate
+	var known []string
+	for _, resourceList := range resourceLists {
+		if resourceList == nil {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			// Skip subresources such as "deployments/scale" which share the parent kind
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			known = append(known, resource.Kind)
+			if qualified && !strings.EqualFold(gv.Group, group) {
+				continue
+			}
+			exact := !qualified && resource.Kind == name
+			if !exact && !resourceNameMatches(resource, lowerName) {
+				continue
+			}
+			candidates = append(candidates, kindCandidate{
+				info: resourceInfo{
+					gvr: schema.GroupVersionResource{
+						Group:    gv.Group,
+						Version:  gv.Version,
+						Resource: resource.Name,
+					},
+					namespaced: resource.Namespaced,
+				},
+				kind:  resource.Kind,
+				exact: exact,
+			})
+		}
+	}
+
+	candidates = preferKindCandidates(candidates)
+	switch len(candidates) {
+	case 0:
+		if suggestions := suggestKinds(known, name); len(suggestions) > 0 {
+			return nil, fmt.Errorf("resource type %s not found; did you mean %s?", kind, strings.Join(suggestions, ", "))
+		}
+		return nil, fmt.Errorf("resource type %s not found", kind)
+	case 1:
+		info := candidates[0].info
+		return &info, nil
+	}
+
+	var options []string
+	for _, candidate := range candidates {
+		options = append(options, fmt.Sprintf("%s (%s)", candidate.kind, qualifiedResourceName(candidate.info.gvr)))
+	}
+	sort.Strings(options)
+	return nil, fmt.Errorf("resource type %s is ambiguous; did you mean one of: %s?", kind, strings.Join(options, ", "))
+}
+
+// resourceNameMatches reports whether a lowercase name matches the resource's
+// kind, plural name, singular name or one of its short names.
+func resourceNameMatches(resource metav1.APIResource, lowerName string) bool {
+	if strings.ToLower(resource.Kind) == lowerName || resource.Name == lowerName || resource.SingularName == lowerName {
+		return true
+	}
+	for _, shortName := range resource.ShortNames {
+		if shortName == lowerName {
+			return true
+		}
+	}
+	return false
+}
+
+// preferKindCandidates narrows the matches to exact Kind matches if there are
+// any, then to the core group if it is among several remaining groups.
+func preferKindCandidates(candidates []kindCandidate) []kindCandidate {
+	var exact []kindCandidate
+	for _, candidate := range candidates {
+		if candidate.exact {
+			exact = append(exact, candidate)
+		}
+	}
+	if len(exact) > 0 {
+		candidates = exact
+	}
+	if len(candidates) < 2 {
+		return candidates
+	}
+	for _, candidate := range candidates {
+		if candidate.info.gvr.Group == "" {
+			return []kindCandidate{candidate}
+		}
+	}
+	return candidates
+}
+
+// qualifiedResourceName returns the resource name with its group, as accepted
+// by resolveKind, e.g. "gateways.networking.istio.io".
+func qualifiedResourceName(gvr schema.GroupVersionResource) string {
+	if gvr.Group == "" {
+		return gvr.Resource
+	}
+	return gvr.Resource + "." + gvr.Group
+}
+
+// suggestKinds returns up to three known kinds that are close to the requested
+// name, either by prefix or by a small edit distance.
+func suggestKinds(known []string, name string) []string {
+	lowerName := strings.ToLower(name)
+	if lowerName == "" {
+		return nil
+	}
+	seen := map[string]bool{}
+	var suggestions []string
+	for _, kind := range known {
+		if seen[kind] {
+			continue
+		}
+		lowerKind := strings.ToLower(kind)
+		if strings.HasPrefix(lowerKind, lowerName) || strings.HasPrefix(lowerName, lowerKind) || editDistance(lowerKind, lowerName) <= 2 {
+			seen[kind] = true
+			suggestions = append(suggestions, kind)
+		}
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > 3 {
+		suggestions = suggestions[:3]
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,104 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestResolveKind tests kind resolution against a fixed set of discovery data
+func TestResolveKind(t *testing.T) {
+	resourceLists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true, ShortNames: []string{"po"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true},
+				{Name: "services", SingularName: "service", Kind: "Service", Namespaced: true, ShortNames: []string{"svc"}},
+				{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", Namespaced: true, ShortNames: []string{"cm"}},
+				{Name: "events", SingularName: "event", Kind: "Event", Namespaced: true, ShortNames: []string{"ev"}},
+				{Name: "nodes", SingularName: "node", Kind: "Node", ShortNames: []string{"no"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true, ShortNames: []string{"deploy"}},
+			},
+		},
+		{
+			GroupVersion: "events.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "events", SingularName: "event", Kind: "Event", Namespaced: true, ShortNames: []string{"ev"}},
+			},
+		},
+		{
+			GroupVersion: "networking.istio.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "gateways", SingularName: "gateway", Kind: "Gateway", Namespaced: true, ShortNames: []string{"gw"}},
+			},
+		},
+		{
+			GroupVersion: "gateway.networking.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "gateways", SingularName: "gateway", Kind: "Gateway", Namespaced: true, ShortNames: []string{"gtw"}},
+			},
+		},
+	}
+
+	resolved := map[string]string{
+		"Pod":                          "pods",
+		"pod":                          "pods",
+		"pods":                         "pods",
+		"po":                           "pods",
+		"svc":                          "services",
+		"cm":                           "configmaps",
+		"deploy":                       "deployments.apps",
+		"Deployments":                  "deployments.apps",
+		"deployments.apps":             "deployments.apps",
+		"Event":                        "events",
+		"events.events.k8s.io":         "events.events.k8s.io",
+		"gw":                           "gateways.networking.istio.io",
+		"gateways.networking.istio.io": "gateways.networking.istio.io",
+		"no":                           "nodes",
+	}
+	for kind, want := range resolved {
+		info, err := resolveKind(resourceLists, kind)
+		if err != nil {
+			t.Errorf("resolveKind(%q) returned error: %v", kind, err)
+			continue
+		}
+		if got := qualifiedResourceName(info.gvr); got != want {
+			t.Errorf("resolveKind(%q) = %s, want %s", kind, got, want)
+		}
+	}
+
+	if info, _ := resolveKind(resourceLists, "node"); info == nil || info.namespaced {
+		t.Errorf("Expected Node to resolve as cluster-scoped")
+	}
+
+	t.Run("Ambiguous kind lists candidates", func(t *testing.T) {
+		_, err := resolveKind(resourceLists, "Gateway")
+		if err == nil {
+			t.Fatal("Expected error for ambiguous kind")
+		}
+		if !strings.Contains(err.Error(), "did you mean") ||
+			!strings.Contains(err.Error(), "gateways.networking.istio.io") ||
+			!strings.Contains(err.Error(), "gateways.gateway.networking.k8s.io") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Unknown kind suggests close matches", func(t *testing.T) {
+		_, err := resolveKind(resourceLists, "Deploymnet")
+		if err == nil || !strings.Contains(err.Error(), "did you mean Deployment?") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		_, err = resolveKind(resourceLists, "Widget")
+		if err == nil || strings.Contains(err.Error(), "did you mean") {
+			t.Errorf("Expected plain not found error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,304 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// resourceCacheJanitorInterval is how often idle informers are stopped and
+// old ones restarted.
+const resourceCacheJanitorInterval = 30 * time.Second
+
+// cachedFields are the fields a field selector may name for a list to be
+// served from the cache; others need the API server to evaluate them.
+var cachedFields = map[string]bool{
+	"metadata.name":      true,
+	"metadata.namespace": true,
+}
+
+// resourceCache serves the lists of chosen kinds from informers, which list
+// a kind across all namespaces once and then keep up with changes by
+// watching, instead of listing from the API server on every call. An
+// informer starts on the first list of its kind and is stopped once the kind
+// has not been listed for ttl; if resync is set, informers older than that
+// are restarted from a fresh list.
+type resourceCache struct {
+	client dynamic.Interface
+	ttl    time.Duration
+	resync time.Duration
+	kinds  map[schema.GroupVersionResource]string
+
+	mu        sync.Mutex
+	informers map[schema.GroupVersionResource]*cachedKind
+	hits      int64
+	misses    int64
+	bypassed  int64
+}
+
+// cachedKind is the informer of a cached kind with its statistics.
+type cachedKind struct {
+	informer  cache.SharedIndexInformer
+	stop      chan struct{}
+	startedAt time.Time
+	lastUsed  time.Time
+	hits      int64
+	misses    int64
+	err       error
+}
+
+// EnableCache makes ListResources serve the given kinds from informers, so
+// repeated lists of hot kinds such as Pods, Events and Nodes do not reach the
+// API server every time. Informers idle for ttl are stopped, and if resync
+// is positive, informers are restarted from a fresh list after that long.
+// Lists with field selectors on other fields than metadata.name and
+// metadata.namespace still go to the API server. Only this client uses the
+// cache; copies of it, e.g. impersonating ones, list from the API server.
+// Returns an error if a kind cannot be resolved, or if the client has a
+// namespace policy, which refuses the lists across all namespaces informers
+// need.
+func (c *Client) EnableCache(kinds []string, ttl, resync time.Duration) error {
+	if c.namespaceCheck != nil {
+		return fmt.Errorf("the resource cache lists kinds across all namespaces, which the namespace policy refuses")
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("the cache TTL must be positive")
+	}
+	resolved := map[schema.GroupVersionResource]string{}
+	for _, kind := range kinds {
+		info, err := c.getCachedResource(kind)
+		if err != nil {
+			return err
+		}
+		resolved[info.gvr] = kind
+	}
+
+	c.cache = &resourceCache{
+		client:    c.dynamicClient,
+		ttl:       ttl,
+		resync:    resync,
+		kinds:     resolved,
+		informers: map[schema.GroupVersionResource]*cachedKind{},
+	}
+	go func(rc *resourceCache) {
+		for now := range time.Tick(resourceCacheJanitorInterval) {
+			rc.expire(now)
+		}
+	}(c.cache)
+	return nil
+}
+
+// listCached returns the objects of kind from the cache, or false if the
+// kind is not cached, its informer has not synced yet, or the selectors need
+// the API server.
+func (c *Client) listCached(kind, namespace, labelSelector, fieldSelector string) ([]map[string]interface{}, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	info, err := c.getCachedResource(kind)
+	if err != nil {
+		return nil, false
+	}
+	if !info.namespaced {
+		namespace = ""
+	}
+	return c.cache.list(info.gvr, namespace, labelSelector, fieldSelector)
+}
+
+// list returns deep copies of the cached objects of gvr in namespace, or in
+// all namespaces if it is empty, that match the selectors. On the first list
+// of a cached kind its informer is started and false is returned, so the
+// caller lists from the API server until the informer has synced.
+func (rc *resourceCache) list(gvr schema.GroupVersionResource, namespace, labelSelector, fieldSelector string) ([]map[string]interface{}, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, ok := rc.kinds[gvr]; !ok {
+		return nil, false
+	}
+	labelMatcher, err := labels.Parse(labelSelector)
+	if err != nil {
+		rc.bypassed++
+		return nil, false
+	}
+	fieldMatcher, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		rc.bypassed++
+		return nil, false
+	}
+	for _, requirement := range fieldMatcher.Requirements() {
+		if !cachedFields[requirement.Field] {
+			rc.bypassed++
+			return nil, false
+		}
+	}
+
+	entry := rc.informers[gvr]
+	if entry == nil {
+		entry = rc.start(gvr)
+	}
+	entry.lastUsed = time.Now()
+	if !entry.informer.HasSynced() || entry.closed() {
+		entry.misses++
+		rc.misses++
+		return nil, false
+	}
+
+	var objects []interface{}
+	if namespace != "" {
+		objects, err = entry.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+		if err != nil {
+			entry.misses++
+			rc.misses++
+			return nil, false
+		}
+	} else {
+		objects = entry.informer.GetStore().List()
+	}
+
+	var items []*unstructured.Unstructured
+	for _, object := range objects {
+		obj, ok := object.(*unstructured.Unstructured)
+		if !ok || !labelMatcher.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		if !fieldMatcher.Matches(fields.Set{"metadata.name": obj.GetName(), "metadata.namespace": obj.GetNamespace()}) {
+			continue
+		}
+		items = append(items, obj)
+	}
+	// Keep the order of the API server, which lists by namespace and name
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+	var resources []map[string]interface{}
+	for _, item := range items {
+		resources = append(resources, item.DeepCopy().UnstructuredContent())
+	}
+	entry.hits++
+	rc.hits++
+	return resources, true
+}
+
+// start runs an informer for gvr across all namespaces. Watch errors are
+// recorded, and an informer refused for lack of permission is stopped, so
+// the kind is listed from the API server until the informer expires and is
+// tried again. The caller must hold rc.mu.
+func (rc *resourceCache) start(gvr schema.GroupVersionResource) *cachedKind {
+	entry := &cachedKind{
+		informer:  dynamicinformer.NewFilteredDynamicInformer(rc.client, gvr, "", 0, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, nil).Informer(),
+		stop:      make(chan struct{}),
+		startedAt: time.Now(),
+	}
+	_ = entry.informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		entry.err = err
+		if (apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err)) && rc.informers[gvr] == entry {
+			entry.close()
+		}
+	})
+	go entry.informer.Run(entry.stop)
+	rc.informers[gvr] = entry
+	return entry
+}
+
+// closed reports whether the informer of a cached kind was stopped.
+func (k *cachedKind) closed() bool {
+	select {
+	case <-k.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops the informer of a cached kind, if it is still running.
+func (k *cachedKind) close() {
+	if !k.closed() {
+		close(k.stop)
+	}
+}
+
+// expire stops the informers not used for the TTL, forgets refused ones
+// after the TTL so they are tried again, and restarts those older than the
+// resync interval.
+func (rc *resourceCache) expire(now time.Time) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for gvr, entry := range rc.informers {
+		switch {
+		case now.Sub(entry.lastUsed) >= rc.ttl, entry.closed() && now.Sub(entry.startedAt) >= rc.ttl:
+			entry.close()
+			delete(rc.informers, gvr)
+		case rc.resync > 0 && now.Sub(entry.startedAt) >= rc.resync:
+			entry.close()
+			restarted := rc.start(gvr)
+			restarted.lastUsed = entry.lastUsed
+			restarted.hits, restarted.misses = entry.hits, entry.misses
+		}
+	}
+}
+
+// CacheStats reports the resource cache of the client: the cached kinds
+// with, for those whose informer runs, the number of objects held, whether
+// the informer has synced, when it started and was last used, its hits and
+// misses and its last watch error. Lists served from the cache count as
+// hits, lists of cached kinds sent to the API server because the informer
+// had not synced as misses, and lists whose selectors the cache cannot
+// evaluate as bypassed.
+// Returns a map with "enabled", the "ttl" and "resync" settings, "kinds" and
+// the overall "hits", "misses" and "bypassed".
+func (c *Client) CacheStats() map[string]interface{} {
+	if c.cache == nil {
+		return map[string]interface{}{"enabled": false}
+	}
+	rc := c.cache
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	kinds := []map[string]interface{}{}
+	for gvr, kind := range rc.kinds {
+		summary := map[string]interface{}{
+			"kind":     kind,
+			"resource": gvr.String(),
+			"running":  false,
+		}
+		if entry := rc.informers[gvr]; entry != nil {
+			summary["running"] = true
+			summary["synced"] = entry.informer.HasSynced()
+			summary["objects"] = len(entry.informer.GetStore().ListKeys())
+			summary["startedAt"] = entry.startedAt
+			summary["lastUsed"] = entry.lastUsed
+			summary["hits"] = entry.hits
+			summary["misses"] = entry.misses
+			if entry.err != nil {
+				summary["lastError"] = entry.err.Error()
+			}
+		}
+		kinds = append(kinds, summary)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i]["kind"].(string) < kinds[j]["kind"].(string) })
+
+	return map[string]interface{}{
+		"enabled":  true,
+		"ttl":      rc.ttl.String(),
+		"resync":   rc.resync.String(),
+		"kinds":    kinds,
+		"hits":     rc.hits,
+		"misses":   rc.misses,
+		"bypassed": rc.bypassed,
+	}
+}
@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestResourceCache tests serving lists of cached kinds from an informer once it has synced
+func TestResourceCache(t *testing.T) {
+	var lists atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		pod := func(namespace, name, app string) string {
+			return `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"` + name + `","namespace":"` + namespace +
+				`","resourceVersion":"5","labels":{"app":"` + app + `"}}}`
+		}
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[` +
+				`{"name":"pods","namespaced":true,"kind":"Pod","verbs":["get","list","watch"]}]}`))
+		case "/api/v1/pods", "/api/v1/namespaces/web/pods":
+			if r.URL.Query().Get("watch") == "true" {
+				<-r.Context().Done()
+				return
+			}
+			lists.Add(1)
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"resourceVersion":"5"},"items":[` +
+				pod("web", "web-2", "web") + `,` + pod("web", "web-1", "web") + `,` + pod("web", "db-1", "db") + `,` + pod("batch", "job-1", "web") + `]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	if err := client.EnableCache([]string{"pods"}, time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The first list starts the informer and is served by the API server
+	if _, err := client.ListResources(ctx, "Pod", "web", "app=web", ""); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for client.CacheStats()["kinds"].([]map[string]interface{})[0]["synced"] != true && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	before := lists.Load()
+	pods, err := client.ListResources(ctx, "Pod", "web", "app=web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 2 || pods[0]["metadata"].(map[string]interface{})["name"] != "web-1" {
+		t.Errorf("Expected web-1 and web-2 from the cache, got %v", pods)
+	}
+	if lists.Load() != before {
+		t.Error("Expected the list to be served without the API server")
+	}
+	pods[0]["metadata"].(map[string]interface{})["name"] = "changed"
+	if pods, _ := client.ListResources(ctx, "Pod", "", "", "metadata.name=web-1"); len(pods) != 1 || pods[0]["metadata"].(map[string]interface{})["name"] != "web-1" {
+		t.Errorf("Expected a copy of the cached web-1, got %v", pods)
+	}
+	if _, err := client.ListResources(ctx, "Pod", "web", "", "spec.nodeName=node-1"); err != nil || lists.Load() != before+1 {
+		t.Error("Expected a field selector on spec to be sent to the API server")
+	}
+
+	stats := client.CacheStats()
+	if stats["hits"] != int64(2) || stats["misses"] != int64(1) || stats["bypassed"] != int64(1) {
+		t.Errorf("Expected 2 hits, 1 miss and 1 bypass, got %v", stats)
+	}
+	if kind := stats["kinds"].([]map[string]interface{})[0]; kind["objects"] != 4 {
+		t.Errorf("Expected the informer to hold the pods of all namespaces, got %v", kind)
+	}
+
+	client.cache.expire(time.Now().Add(2 * time.Minute))
+	if kind := client.CacheStats()["kinds"].([]map[string]interface{})[0]; kind["running"] != false {
+		t.Errorf("Expected the idle informer to be stopped, got %v", kind)
+	}
+}
@@ -0,0 +1,165 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxRestartSampleSeconds bounds the interval between the two pod listings
+// used to measure restart count increases.
+const maxRestartSampleSeconds = 120
+
+// containerRestartSample is the restart state of a container at one point in time.
+type containerRestartSample struct {
+	pod           *corev1.Pod
+	status        corev1.ContainerStatus
+	initContainer bool
+}
+
+// FindRestartStorms finds containers whose restart count increased recently and
+// ranks the worst offenders. A container counts as restarting when its last
+// termination finished within windowMinutes of now, or, if sampleSeconds is
+// positive, when its restart count increased between two pod listings taken
+// sampleSeconds apart. Offenders are ranked by the increase observed during
+// sampling, then by restart rate since the pod started.
+// Returns a map with "offenders", "window" and "scannedContainers", or an error.
+func (c *Client) FindRestartStorms(ctx context.Context, namespace string, windowMinutes, sampleSeconds, topN int) (map[string]interface{}, error) {
+	if windowMinutes <= 0 {
+		windowMinutes = 60
+	}
+	if topN <= 0 {
+		topN = 10
+	}
+	if sampleSeconds > maxRestartSampleSeconds {
+		sampleSeconds = maxRestartSampleSeconds
+	}
+
+	first, err := c.sampleContainerRestarts(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	current := first
+	if sampleSeconds > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(sampleSeconds) * time.Second):
+		}
+		if current, err = c.sampleContainerRestarts(ctx, namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-time.Duration(windowMinutes) * time.Minute)
+	var offenders []map[string]interface{}
+	for key, sample := range current {
+		status := sample.status
+		var increase int32
+		if before, ok := first[key]; ok {
+			increase = status.RestartCount - before.status.RestartCount
+		}
+
+		terminated := status.LastTerminationState.Terminated
+		restartedInWindow := terminated != nil && terminated.FinishedAt.Time.After(windowStart)
+		if increase <= 0 && !restartedInWindow {
+			continue
+		}
+
+		entry := map[string]interface{}{
+			"namespace":         sample.pod.Namespace,
+			"pod":               sample.pod.Name,
+			"container":         status.Name,
+			"node":              sample.pod.Spec.NodeName,
+			"restartCount":      status.RestartCount,
+			"restartedInWindow": restartedInWindow,
+			"restartsPerHour":   restartRate(sample.pod, status.RestartCount, now),
+		}
+		if sample.initContainer {
+			entry["initContainer"] = true
+		}
+		if sampleSeconds > 0 {
+			entry["increaseDuringSample"] = increase
+		}
+		if ref := metav1.GetControllerOf(sample.pod); ref != nil {
+			entry["controller"] = map[string]interface{}{"kind": ref.Kind, "name": ref.Name}
+		}
+		if terminated != nil {
+			entry["lastTermination"] = map[string]interface{}{
+				"reason":     terminated.Reason,
+				"exitCode":   terminated.ExitCode,
+				"finishedAt": terminated.FinishedAt,
+			}
+		}
+		if waiting := status.State.Waiting; waiting != nil {
+			entry["waitingReason"] = waiting.Reason
+		}
+		offenders = append(offenders, entry)
+	}
+
+	sort.Slice(offenders, func(i, j int) bool {
+		a, b := offenders[i], offenders[j]
+		ai, _ := a["increaseDuringSample"].(int32)
+		bi, _ := b["increaseDuringSample"].(int32)
+		if ai != bi {
+			return ai > bi
+		}
+		if a["restartsPerHour"].(float64) != b["restartsPerHour"].(float64) {
+			return a["restartsPerHour"].(float64) > b["restartsPerHour"].(float64)
+		}
+		return a["restartCount"].(int32) > b["restartCount"].(int32)
+	})
+
+	report := map[string]interface{}{
+		"window": map[string]interface{}{
+			"minutes":       windowMinutes,
+			"sampleSeconds": sampleSeconds,
+		},
+		"scannedContainers": len(current),
+		"totalOffenders":    len(offenders),
+		"offenders":         limitMaps(offenders, topN),
+	}
+	if len(offenders) == 0 {
+		report["offenders"] = []map[string]interface{}{}
+	}
+	return report, nil
+}
+
+// sampleContainerRestarts lists pods and returns the restart state of every
+// container, keyed by pod UID and container name.
+func (c *Client) sampleContainerRestarts(ctx context.Context, namespace string) (map[string]containerRestartSample, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	samples := map[string]containerRestartSample{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, status := range pod.Status.InitContainerStatuses {
+			samples[string(pod.UID)+"/"+status.Name] = containerRestartSample{pod: pod, status: status, initContainer: true}
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			samples[string(pod.UID)+"/"+status.Name] = containerRestartSample{pod: pod, status: status}
+		}
+	}
+	return samples, nil
+}
+
+// restartRate returns the average number of restarts per hour since the pod
+// started, rounded to two decimals.
+func restartRate(pod *corev1.Pod, restarts int32, now time.Time) float64 {
+	if pod.Status.StartTime == nil || restarts == 0 {
+		return 0
+	}
+	hours := now.Sub(pod.Status.StartTime.Time).Hours()
+	if hours < 1.0/60 {
+		hours = 1.0 / 60
+	}
+	return float64(int(float64(restarts)/hours*100+0.5)) / 100
+}
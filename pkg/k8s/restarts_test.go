@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestRestartRate tests averaging restarts per hour since the pod started
+func TestRestartRate(t *testing.T) {
+	now := time.Now()
+	started := func(ago time.Duration) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{StartTime: &metav1.Time{Time: now.Add(-ago)}}}
+	}
+
+	if rate := restartRate(started(2*time.Hour), 5, now); rate != 2.5 {
+		t.Errorf("Expected 2.5 restarts per hour, got %v", rate)
+	}
+	if rate := restartRate(started(time.Second), 1, now); rate != 60 {
+		t.Errorf("Expected pods younger than a minute to count as a minute old, got %v", rate)
+	}
+	if rate := restartRate(&corev1.Pod{}, 3, now); rate != 0 {
+		t.Errorf("Expected no rate without a start time, got %v", rate)
+	}
+}
+
+// TestFindRestartStorms tests finding and ranking containers that restarted within the window
+func TestFindRestartStorms(t *testing.T) {
+	now := time.Now().UTC()
+	timestamp := func(ago time.Duration) string {
+		return now.Add(-ago).Format(time.RFC3339)
+	}
+	pods := fmt.Sprintf(`{"kind":"PodList","apiVersion":"v1","items":[`+
+		`{"metadata":{"name":"api-a","namespace":"web","uid":"1","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"api-1","uid":"rs1","controller":true}]},`+
+		`"spec":{"nodeName":"worker-1"},"status":{"startTime":%q,"containerStatuses":[`+
+		`{"name":"app","restartCount":10,"lastState":{"terminated":{"reason":"OOMKilled","exitCode":137,"finishedAt":%q}},"state":{"waiting":{"reason":"CrashLoopBackOff"}}},`+
+		`{"name":"proxy","restartCount":1,"lastState":{"terminated":{"reason":"Error","exitCode":1,"finishedAt":%q}}}]}},`+
+		`{"metadata":{"name":"worker-a","namespace":"web","uid":"2"},"spec":{"nodeName":"worker-2"},"status":{"startTime":%q,`+
+		`"initContainerStatuses":[{"name":"migrate","restartCount":4,"lastState":{"terminated":{"reason":"Error","exitCode":1,"finishedAt":%q}}}],`+
+		`"containerStatuses":[{"name":"app","restartCount":0}]}}]}`,
+		timestamp(2*time.Hour), timestamp(5*time.Minute), timestamp(3*time.Hour), timestamp(4*time.Hour), timestamp(10*time.Minute))
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/web/pods" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(pods))
+	}))
+
+	result, err := client.FindRestartStorms(context.Background(), "web", 30, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["scannedContainers"] != 4 || result["totalOffenders"] != 2 {
+		t.Fatalf("Expected two of four containers to have restarted in the window, got %v", result)
+	}
+	offenders := result["offenders"].([]map[string]interface{})
+	app, migrate := offenders[0], offenders[1]
+	if app["pod"] != "api-a" || app["container"] != "app" || app["restartsPerHour"] != 5.0 || app["waitingReason"] != "CrashLoopBackOff" {
+		t.Errorf("Expected the fastest restarting container first, got %v", app)
+	}
+	if termination := app["lastTermination"].(map[string]interface{}); termination["reason"] != "OOMKilled" {
+		t.Errorf("Expected the last termination reason, got %v", termination)
+	}
+	if controller := app["controller"].(map[string]interface{}); controller["name"] != "api-1" {
+		t.Errorf("Expected the pod's controller, got %v", controller)
+	}
+	if migrate["container"] != "migrate" || migrate["initContainer"] != true {
+		t.Errorf("Expected the restarting init container to be flagged, got %v", migrate)
+	}
+
+	result, err = client.FindRestartStorms(context.Background(), "web", 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offenders := result["offenders"].([]map[string]interface{}); len(offenders) != 0 {
+		t.Errorf("Expected no offenders outside the window, got %v", offenders)
+	}
+}
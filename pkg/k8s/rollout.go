@@ -0,0 +1,528 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Rollout states reported by WaitForRollout.
+const (
+	RolloutHealthy     = "healthy"
+	RolloutProgressing = "progressing"
+	RolloutFailed      = "failed"
+	RolloutUnsupported = "unsupported"
+)
+
+// rolloutFailureReasons are container waiting reasons that a rollout will not
+// recover from without another change.
+var rolloutFailureReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// rolloutStatus evaluates the rollout of a Deployment, StatefulSet or
+// DaemonSet from its status, following kubectl rollout status.
+// Returns one of the Rollout states and a message describing it.
+func rolloutStatus(obj map[string]interface{}) (string, string) {
+	u := unstructured.Unstructured{Object: obj}
+	switch u.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet":
+	default:
+		return RolloutUnsupported, fmt.Sprintf("rollout status is not available for kind %s", u.GetKind())
+	}
+	if observed, _, _ := unstructured.NestedInt64(obj, "status", "observedGeneration"); observed < u.GetGeneration() {
+		return RolloutProgressing, "waiting for the controller to observe the change"
+	}
+	status := func(field string) int64 {
+		value, _, _ := unstructured.NestedInt64(obj, "status", field)
+		return value
+	}
+	desired, found, _ := unstructured.NestedInt64(obj, "spec", "replicas")
+	if !found {
+		desired = 1
+	}
+
+	switch u.GetKind() {
+	case "Deployment":
+		for _, condition := range summarizeConditions(obj) {
+			if condition["type"] == "Progressing" && condition["reason"] == "ProgressDeadlineExceeded" {
+				return RolloutFailed, fmt.Sprintf("deployment %s exceeded its progress deadline", u.GetName())
+			}
+		}
+		updated, replicas, available := status("updatedReplicas"), status("replicas"), status("availableReplicas")
+		switch {
+		case updated < desired:
+			return RolloutProgressing, fmt.Sprintf("%d of %d new replicas have been updated", updated, desired)
+		case replicas > updated:
+			return RolloutProgressing, fmt.Sprintf("%d old replicas are pending termination", replicas-updated)
+		case available < updated:
+			return RolloutProgressing, fmt.Sprintf("%d of %d updated replicas are available", available, updated)
+		}
+		return RolloutHealthy, fmt.Sprintf("deployment %s successfully rolled out", u.GetName())
+
+	case "StatefulSet":
+		if strategy, _, _ := unstructured.NestedString(obj, "spec", "updateStrategy", "type"); strategy == "OnDelete" {
+			return RolloutUnsupported, "rollout status is only available for the RollingUpdate strategy"
+		}
+		if ready := status("readyReplicas"); ready < desired {
+			return RolloutProgressing, fmt.Sprintf("%d of %d pods are ready", ready, desired)
+		}
+		partition, _, _ := unstructured.NestedInt64(obj, "spec", "updateStrategy", "rollingUpdate", "partition")
+		if partition > 0 {
+			if updated := status("updatedReplicas"); updated < desired-partition {
+				return RolloutProgressing, fmt.Sprintf("%d of %d pods above the partition have been updated", updated, desired-partition)
+			}
+			return RolloutHealthy, fmt.Sprintf("partitioned rollout complete: %d new pods have been updated", desired-partition)
+		}
+		current, _, _ := unstructured.NestedString(obj, "status", "currentRevision")
+		update, _, _ := unstructured.NestedString(obj, "status", "updateRevision")
+		if current != update {
+			return RolloutProgressing, fmt.Sprintf("%d of %d pods have been updated to revision %s", status("updatedReplicas"), desired, update)
+		}
+		return RolloutHealthy, fmt.Sprintf("statefulset %s rolled out at revision %s", u.GetName(), update)
+
+	default: // DaemonSet
+		if strategy, _, _ := unstructured.NestedString(obj, "spec", "updateStrategy", "type"); strategy == "OnDelete" {
+			return RolloutUnsupported, "rollout status is only available for the RollingUpdate strategy"
+		}
+		scheduled := status("desiredNumberScheduled")
+		if updated := status("updatedNumberScheduled"); updated < scheduled {
+			return RolloutProgressing, fmt.Sprintf("%d of %d updated pods have been scheduled", updated, scheduled)
+		}
+		if available := status("numberAvailable"); available < scheduled {
+			return RolloutProgressing, fmt.Sprintf("%d of %d updated pods are available", available, scheduled)
+		}
+		return RolloutHealthy, fmt.Sprintf("daemon set %s successfully rolled out", u.GetName())
+	}
+}
+
+// failingRolloutPods returns the pods selected by a workload that have a
+// container stuck in one of the rolloutFailureReasons.
+func (c *Client) failingRolloutPods(ctx context.Context, obj *unstructured.Unstructured) []map[string]interface{} {
+	selectorMap, found, _ := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if !found {
+		return nil
+	}
+	var labelSelector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, &labelSelector); err != nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil || selector.Empty() {
+		return nil
+	}
+	pods, err := c.clientset.CoreV1().Pods(obj.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil
+	}
+
+	var failing []map[string]interface{}
+	for _, pod := range pods.Items {
+		statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if status.State.Waiting != nil && rolloutFailureReasons[status.State.Waiting.Reason] {
+				failing = append(failing, map[string]interface{}{
+					"pod":       pod.Name,
+					"container": status.Name,
+					"reason":    status.State.Waiting.Reason,
+					"message":   status.State.Waiting.Message,
+				})
+				break
+			}
+		}
+	}
+	return failing
+}
+
+// rolloutCheck evaluates the rollout of a workload once, marking a
+// progressing rollout as failed if pods of the workload are stuck.
+func (c *Client) rolloutCheck(ctx context.Context, obj *unstructured.Unstructured) map[string]interface{} {
+	state, message := rolloutStatus(obj.Object)
+	result := map[string]interface{}{
+		"state":    state,
+		"message":  message,
+		"revision": workloadRevision(obj.Object),
+	}
+	if state == RolloutProgressing {
+		if failing := c.failingRolloutPods(ctx, obj); len(failing) > 0 {
+			result["state"] = RolloutFailed
+			result["message"] = fmt.Sprintf("%d pods are failing: %s", len(failing), message)
+			result["failingPods"] = failing
+		}
+	}
+	return result
+}
+
+// WaitForRollout waits until the rollout of a Deployment, StatefulSet or
+// DaemonSet is healthy or has failed, or timeout elapses. A rollout fails when
+// a Deployment exceeds its progress deadline or when pods of the workload are
+// stuck in states like CrashLoopBackOff or ImagePullBackOff. Other kinds are
+// reported as unsupported without waiting. A zero timeout checks the rollout
+// once without waiting, like kubectl rollout status --watch=false.
+// Returns the final state, its message, the time waited and any failing pods,
+// or an error if the workload cannot be read.
+func (c *Client) WaitForRollout(ctx context.Context, kind, name, namespace string, timeout time.Duration) (map[string]interface{}, error) {
+	if timeout < 0 || timeout > MaxWaitTimeout {
+		timeout = MaxWaitTimeout
+	}
+	resource, err := c.resourceClient(kind, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	if timeout == 0 {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+		}
+		return c.rolloutCheck(ctx, obj), nil
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	result := map[string]interface{}{
+		"state":   RolloutProgressing,
+		"message": "timed out before the workload could be read",
+	}
+	for {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			result = c.rolloutCheck(ctx, obj)
+		case ctx.Err() == nil:
+			return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+		}
+
+		if result["state"] != RolloutProgressing || ctx.Err() != nil {
+			result["timedOut"] = result["state"] == RolloutProgressing
+			result["elapsedSeconds"] = int(time.Since(start).Seconds())
+			return result, nil
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// changeCauseAnnotation records the reason for a rollout, as set by
+// kubectl annotate or the --record flag of older kubectl versions.
+const changeCauseAnnotation = "kubernetes.io/change-cause"
+
+// deploymentRevision is a revision of a Deployment: one of its ReplicaSets.
+type deploymentRevision struct {
+	revision   int64
+	replicaSet *appsv1.ReplicaSet
+}
+
+// deploymentRevisions returns the revisions of a Deployment, oldest first,
+// from the ReplicaSets it controls. ReplicaSets without a revision annotation
+// are skipped.
+func deploymentRevisions(deployment *appsv1.Deployment, replicaSets []appsv1.ReplicaSet) []deploymentRevision {
+	var revisions []deploymentRevision
+	for i := range replicaSets {
+		rs := &replicaSets[i]
+		owner := metav1.GetControllerOf(rs)
+		if owner == nil || owner.UID != deployment.UID {
+			continue
+		}
+		revision, err := strconv.ParseInt(rs.Annotations[deploymentRevisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, deploymentRevision{revision: revision, replicaSet: rs})
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].revision < revisions[j].revision })
+	return revisions
+}
+
+// rollbackTarget returns the revision to roll back to: the given revision,
+// or the one before the newest if toRevision is 0.
+func rollbackTarget(revisions []deploymentRevision, toRevision int64) (deploymentRevision, error) {
+	if toRevision == 0 {
+		if len(revisions) < 2 {
+			return deploymentRevision{}, fmt.Errorf("no previous revision to roll back to")
+		}
+		return revisions[len(revisions)-2], nil
+	}
+	for _, revision := range revisions {
+		if revision.revision == toRevision {
+			return revision, nil
+		}
+	}
+	return deploymentRevision{}, fmt.Errorf("revision %d not found", toRevision)
+}
+
+// templateWithoutHash returns a copy of a ReplicaSet's pod template without
+// the pod-template-hash label the Deployment controller adds to it.
+func templateWithoutHash(rs *appsv1.ReplicaSet) corev1.PodTemplateSpec {
+	template := *rs.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return template
+}
+
+// templateImages returns the container images of a pod template by container name.
+func templateImages(template corev1.PodTemplateSpec) map[string]string {
+	images := map[string]string{}
+	for _, container := range template.Spec.Containers {
+		images[container.Name] = container.Image
+	}
+	return images
+}
+
+// getDeploymentRevisions fetches a Deployment and its revisions.
+func (c *Client) getDeploymentRevisions(ctx context.Context, namespace, name string) (*appsv1.Deployment, []deploymentRevision, error) {
+	deployment, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get Deployment %s/%s: %w", namespace, name, err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid selector of Deployment %s/%s: %w", namespace, name, err)
+	}
+	replicaSets, err := c.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list ReplicaSets of Deployment %s/%s: %w", namespace, name, err)
+	}
+	return deployment, deploymentRevisions(deployment, replicaSets.Items), nil
+}
+
+// RolloutHistory lists the revisions of a Deployment, like kubectl rollout
+// history: for each ReplicaSet it controls, the revision number, change
+// cause, container images, replica counts and creation time. Only as many
+// revisions as spec.revisionHistoryLimit keeps are available.
+// Returns the Deployment's current revision and its revisions, oldest first,
+// or an error.
+func (c *Client) RolloutHistory(ctx context.Context, namespace, name string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	deployment, revisions, err := c.getDeploymentRevisions(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	current := deployment.Annotations[deploymentRevisionAnnotation]
+	entries := []map[string]interface{}{}
+	for _, revision := range revisions {
+		rs := revision.replicaSet
+		entry := map[string]interface{}{
+			"revision":          revision.revision,
+			"replicaSet":        rs.Name,
+			"images":            templateImages(rs.Spec.Template),
+			"replicas":          rs.Status.Replicas,
+			"availableReplicas": rs.Status.AvailableReplicas,
+			"created":           rs.CreationTimestamp.UTC().Format(time.RFC3339),
+			"current":           strconv.FormatInt(revision.revision, 10) == current,
+		}
+		if cause := rs.Annotations[changeCauseAnnotation]; cause != "" {
+			entry["changeCause"] = cause
+		}
+		entries = append(entries, entry)
+	}
+
+	result := map[string]interface{}{
+		"name":            deployment.Name,
+		"namespace":       deployment.Namespace,
+		"currentRevision": current,
+		"revisions":       entries,
+	}
+	if deployment.Spec.RevisionHistoryLimit != nil {
+		result["revisionHistoryLimit"] = *deployment.Spec.RevisionHistoryLimit
+	}
+	return result, nil
+}
+
+// revisionHistoryAnnotation lists, comma-separated, the earlier revision
+// numbers of a ReplicaSet whose template a rollback brought back.
+const revisionHistoryAnnotation = "deployment.kubernetes.io/revision-history"
+
+// GetPodRevision maps a pod back to the Deployment revision it was created
+// from. The ReplicaSet controlling the pod, which the Deployment named after
+// the pod-template-hash label, carries the revision number and the change
+// cause recorded for it. It also reports the Deployment's current revision,
+// whether the pod runs it, and the change causes of any newer revisions, so
+// the rollout that introduced the pod, and those since, can be read off
+// directly. Earlier numbers of a revision that a rollback reused are listed
+// as "previousRevisions".
+// Returns a map describing the pod, its ReplicaSet, Deployment and revision,
+// or an error if the pod is not managed by a Deployment.
+func (c *Client) GetPodRevision(ctx context.Context, namespace, name string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s: %w", namespace, name, err)
+	}
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil, fmt.Errorf("pod %s/%s has no controller, so it was not created by a Deployment", namespace, name)
+	}
+	if ref.Kind != "ReplicaSet" {
+		return nil, fmt.Errorf("pod %s/%s is controlled by %s %s, not by a Deployment", namespace, name, ref.Kind, ref.Name)
+	}
+	rs, err := c.clientset.AppsV1().ReplicaSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ReplicaSet %s/%s of pod %s: %w", namespace, ref.Name, name, err)
+	}
+	owner := metav1.GetControllerOf(rs)
+	if owner == nil || owner.Kind != "Deployment" {
+		return nil, fmt.Errorf("pod %s/%s is controlled by ReplicaSet %s, which is not managed by a Deployment", namespace, name, rs.Name)
+	}
+	deployment, revisions, err := c.getDeploymentRevisions(ctx, namespace, owner.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	current := deployment.Annotations[deploymentRevisionAnnotation]
+	result := map[string]interface{}{
+		"pod":             pod.Name,
+		"namespace":       pod.Namespace,
+		"podCreated":      pod.CreationTimestamp.UTC().Format(time.RFC3339),
+		"podTemplateHash": pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey],
+		"replicaSet":      rs.Name,
+		"deployment":      deployment.Name,
+		"currentRevision": current,
+		"images":          templateImages(rs.Spec.Template),
+	}
+
+	index := -1
+	for i, revision := range revisions {
+		if revision.replicaSet.Name == rs.Name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		result["message"] = fmt.Sprintf("ReplicaSet %s has no revision annotation, so its revision is unknown", rs.Name)
+		return result, nil
+	}
+
+	revision := revisions[index].revision
+	result["revision"] = revision
+	result["revisionCreated"] = rs.CreationTimestamp.UTC().Format(time.RFC3339)
+	result["current"] = strconv.FormatInt(revision, 10) == current
+	if cause := rs.Annotations[changeCauseAnnotation]; cause != "" {
+		result["changeCause"] = cause
+	}
+	if history := rs.Annotations[revisionHistoryAnnotation]; history != "" {
+		var previous []int64
+		for _, number := range strings.Split(history, ",") {
+			if value, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64); err == nil {
+				previous = append(previous, value)
+			}
+		}
+		result["previousRevisions"] = previous
+	}
+
+	newer := []map[string]interface{}{}
+	for _, later := range revisions[index+1:] {
+		entry := map[string]interface{}{
+			"revision":   later.revision,
+			"replicaSet": later.replicaSet.Name,
+			"created":    later.replicaSet.CreationTimestamp.UTC().Format(time.RFC3339),
+		}
+		if cause := later.replicaSet.Annotations[changeCauseAnnotation]; cause != "" {
+			entry["changeCause"] = cause
+		}
+		newer = append(newer, entry)
+	}
+	result["newerRevisions"] = newer
+	return result, nil
+}
+
+// RolloutUndo rolls a Deployment back to a previous revision, like kubectl
+// rollout undo: the pod template of the revision's ReplicaSet replaces the
+// Deployment's template, which starts a rollout to it, and the revision's
+// change cause is carried over. If toRevision is 0, the Deployment is rolled
+// back to the revision before the newest. A paused Deployment cannot be
+// rolled back.
+// Returns the revision rolled back from and to and whether the template
+// changed, or an error.
+func (c *Client) RolloutUndo(ctx context.Context, namespace, name string, toRevision int64) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	if toRevision < 0 {
+		return nil, fmt.Errorf("revision must be zero or greater")
+	}
+	deployment, revisions, err := c.getDeploymentRevisions(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if deployment.Spec.Paused {
+		return nil, fmt.Errorf("cannot roll back paused Deployment %s/%s; resume it first", namespace, name)
+	}
+	target, err := rollbackTarget(revisions, toRevision)
+	if err != nil {
+		return nil, fmt.Errorf("cannot roll back Deployment %s/%s: %w", namespace, name, err)
+	}
+
+	template := templateWithoutHash(target.replicaSet)
+	result := map[string]interface{}{
+		"name":         deployment.Name,
+		"namespace":    deployment.Namespace,
+		"fromRevision": deployment.Annotations[deploymentRevisionAnnotation],
+		"toRevision":   target.revision,
+		"replicaSet":   target.replicaSet.Name,
+		"images":       templateImages(template),
+	}
+	if equality.Semantic.DeepEqual(template, deployment.Spec.Template) {
+		result["changed"] = false
+		result["message"] = fmt.Sprintf("Deployment is already running the template of revision %d", target.revision)
+		return result, nil
+	}
+
+	annotations := map[string]string{}
+	for key, value := range deployment.Annotations {
+		annotations[key] = value
+	}
+	delete(annotations, changeCauseAnnotation)
+	if cause := target.replicaSet.Annotations[changeCauseAnnotation]; cause != "" {
+		annotations[changeCauseAnnotation] = cause
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+		{"op": "add", "path": "/metadata/annotations", "value": annotations},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build patch: %w", err)
+	}
+
+	resource, err := c.resourceClient("Deployment", namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	recordUndo := c.recordUndo(ctx, "rolloutUndo", resource, "Deployment", name, namespace)
+	if _, err := c.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{DryRun: dryRunOption(ctx)}); err != nil {
+		return nil, fmt.Errorf("failed to roll back Deployment %s/%s: %w", namespace, name, err)
+	}
+	recordUndo()
+
+	result["changed"] = true
+	if IsDryRun(ctx) {
+		result["dryRun"] = true
+	}
+	return result, nil
+}
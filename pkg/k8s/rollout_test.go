@@ -0,0 +1,170 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestRolloutStatus tests evaluating the rollout state of workloads from their status
+func TestRolloutStatus(t *testing.T) {
+	workload := func(kind string, spec, status map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"kind":     kind,
+			"metadata": map[string]interface{}{"name": "web", "generation": int64(2)},
+			"spec":     spec,
+			"status":   status,
+		}
+	}
+	tests := []struct {
+		name  string
+		obj   map[string]interface{}
+		state string
+	}{
+		{"unobserved", workload("Deployment", map[string]interface{}{"replicas": int64(3)},
+			map[string]interface{}{"observedGeneration": int64(1)}), RolloutProgressing},
+		{"deployment updating", workload("Deployment", map[string]interface{}{"replicas": int64(3)},
+			map[string]interface{}{"observedGeneration": int64(2), "updatedReplicas": int64(1), "replicas": int64(4)}), RolloutProgressing},
+		{"deployment old replicas", workload("Deployment", map[string]interface{}{"replicas": int64(3)},
+			map[string]interface{}{"observedGeneration": int64(2), "updatedReplicas": int64(3), "replicas": int64(4), "availableReplicas": int64(3)}), RolloutProgressing},
+		{"deployment done", workload("Deployment", map[string]interface{}{"replicas": int64(3)},
+			map[string]interface{}{"observedGeneration": int64(2), "updatedReplicas": int64(3), "replicas": int64(3), "availableReplicas": int64(3)}), RolloutHealthy},
+		{"deployment deadline", workload("Deployment", map[string]interface{}{"replicas": int64(3)},
+			map[string]interface{}{"observedGeneration": int64(2), "conditions": []interface{}{
+				map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+			}}), RolloutFailed},
+		{"statefulset revision", workload("StatefulSet", map[string]interface{}{"replicas": int64(2)},
+			map[string]interface{}{"observedGeneration": int64(2), "readyReplicas": int64(2), "currentRevision": "web-1", "updateRevision": "web-2"}), RolloutProgressing},
+		{"statefulset partition", workload("StatefulSet", map[string]interface{}{"replicas": int64(3),
+			"updateStrategy": map[string]interface{}{"rollingUpdate": map[string]interface{}{"partition": int64(2)}}},
+			map[string]interface{}{"observedGeneration": int64(2), "readyReplicas": int64(3), "updatedReplicas": int64(1)}), RolloutHealthy},
+		{"daemonset unavailable", workload("DaemonSet", map[string]interface{}{},
+			map[string]interface{}{"observedGeneration": int64(2), "desiredNumberScheduled": int64(3), "updatedNumberScheduled": int64(3), "numberAvailable": int64(2)}), RolloutProgressing},
+		{"daemonset on delete", workload("DaemonSet", map[string]interface{}{"updateStrategy": map[string]interface{}{"type": "OnDelete"}},
+			map[string]interface{}{"observedGeneration": int64(2)}), RolloutUnsupported},
+		{"cronjob", workload("CronJob", map[string]interface{}{}, map[string]interface{}{}), RolloutUnsupported},
+	}
+	for _, tt := range tests {
+		if state, message := rolloutStatus(tt.obj); state != tt.state {
+			t.Errorf("%s: rolloutStatus() = %s (%s), want %s", tt.name, state, message, tt.state)
+		}
+	}
+}
+
+// TestDeploymentRevisions tests ordering a Deployment's ReplicaSets by revision
+// and choosing the revision to roll back to
+func TestDeploymentRevisions(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", UID: "d1"}}
+	isController := true
+	replicaSet := func(name, uid, revision string) appsv1.ReplicaSet {
+		rs := appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{{UID: types.UID(uid), Controller: &isController}},
+		}}
+		if revision != "" {
+			rs.Annotations = map[string]string{deploymentRevisionAnnotation: revision}
+		}
+		return rs
+	}
+	revisions := deploymentRevisions(deployment, []appsv1.ReplicaSet{
+		replicaSet("web-c", "d1", "10"),
+		replicaSet("web-a", "d1", "2"),
+		replicaSet("other", "d2", "5"),
+		replicaSet("web-b", "d1", ""),
+		replicaSet("web-d", "d1", "7"),
+	})
+	var names []string
+	for _, revision := range revisions {
+		names = append(names, revision.replicaSet.Name)
+	}
+	if len(names) != 3 || names[0] != "web-a" || names[1] != "web-d" || names[2] != "web-c" {
+		t.Fatalf("Unexpected revisions: %v", names)
+	}
+
+	if target, err := rollbackTarget(revisions, 0); err != nil || target.revision != 7 {
+		t.Errorf("Expected previous revision 7, got %d (%v)", target.revision, err)
+	}
+	if target, err := rollbackTarget(revisions, 2); err != nil || target.replicaSet.Name != "web-a" {
+		t.Errorf("Expected revision 2, got %v (%v)", target.replicaSet, err)
+	}
+	if _, err := rollbackTarget(revisions, 3); err == nil {
+		t.Error("Expected an error for a missing revision")
+	}
+	if _, err := rollbackTarget(revisions[:1], 0); err == nil {
+		t.Error("Expected an error without a previous revision")
+	}
+}
+
+// TestTemplateWithoutHash tests that the pod-template-hash label is dropped
+// without modifying the ReplicaSet
+func TestTemplateWithoutHash(t *testing.T) {
+	rs := &appsv1.ReplicaSet{}
+	rs.Spec.Template.Labels = map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: "abc"}
+	template := templateWithoutHash(rs)
+	if _, ok := template.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok || template.Labels["app"] != "web" {
+		t.Errorf("Unexpected template labels: %v", template.Labels)
+	}
+	if rs.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] != "abc" {
+		t.Error("Expected the ReplicaSet's labels to be left unchanged")
+	}
+}
+
+// TestGetPodRevision tests mapping a pod through its ReplicaSet to the Deployment revision that created it
+func TestGetPodRevision(t *testing.T) {
+	replicaSet := func(name, revision, cause, extra string) string {
+		return `{"metadata":{"name":"` + name + `","namespace":"prod","creationTimestamp":"2024-05-0` + revision + `T10:00:00Z",` +
+			`"labels":{"app":"web"},"annotations":{"deployment.kubernetes.io/revision":"` + revision + `","kubernetes.io/change-cause":"` + cause + `"` + extra + `},` +
+			`"ownerReferences":[{"apiVersion":"apps/v1","kind":"Deployment","name":"web","uid":"d1","controller":true}]},` +
+			`"spec":{"selector":{"matchLabels":{"app":"web"}},"template":{"metadata":{"labels":{"app":"web"}},"spec":{"containers":[{"name":"app","image":"web:` + revision + `"}]}}}}`
+	}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/prod/pods/web-abc-1":
+			w.Write([]byte(`{"kind":"Pod","apiVersion":"v1","metadata":{"name":"web-abc-1","namespace":"prod","creationTimestamp":"2024-05-04T12:00:00Z",` +
+				`"labels":{"app":"web","pod-template-hash":"abc"},"ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"web-abc","uid":"r2","controller":true}]}}`))
+		case "/api/v1/namespaces/prod/pods/db-0":
+			w.Write([]byte(`{"kind":"Pod","apiVersion":"v1","metadata":{"name":"db-0","namespace":"prod",` +
+				`"ownerReferences":[{"apiVersion":"apps/v1","kind":"StatefulSet","name":"db","uid":"s1","controller":true}]}}`))
+		case "/apis/apps/v1/namespaces/prod/replicasets/web-abc":
+			w.Write([]byte(replicaSet("web-abc", "4", "kubectl set image web app=web:4", `,"deployment.kubernetes.io/revision-history":"1,2"`)))
+		case "/apis/apps/v1/namespaces/prod/replicasets":
+			w.Write([]byte(`{"kind":"ReplicaSetList","apiVersion":"apps/v1","items":[` +
+				replicaSet("web-def", "5", "bump to 5", "") + `,` +
+				replicaSet("web-abc", "4", "kubectl set image web app=web:4", `,"deployment.kubernetes.io/revision-history":"1,2"`) + `,` +
+				replicaSet("web-xyz", "3", "", "") + `,` +
+				replicaSet("web-ghi", "6", "bump to 6", "") + `]}`))
+		case "/apis/apps/v1/namespaces/prod/deployments/web":
+			w.Write([]byte(`{"kind":"Deployment","apiVersion":"apps/v1","metadata":{"name":"web","namespace":"prod","uid":"d1",` +
+				`"annotations":{"deployment.kubernetes.io/revision":"6"}},"spec":{"selector":{"matchLabels":{"app":"web"}},"template":{"metadata":{"labels":{"app":"web"}}}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.GetPodRevision(context.Background(), "prod", "web-abc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["revision"] != int64(4) || result["changeCause"] != "kubectl set image web app=web:4" || result["podTemplateHash"] != "abc" {
+		t.Errorf("Expected revision 4 with its change cause, got %v", result)
+	}
+	if result["deployment"] != "web" || result["currentRevision"] != "6" || result["current"] != false {
+		t.Errorf("Expected an outdated pod of Deployment web, got %v", result)
+	}
+	if previous := result["previousRevisions"].([]int64); len(previous) != 2 || previous[1] != 2 {
+		t.Errorf("Expected previous revisions 1 and 2, got %v", previous)
+	}
+	newer := result["newerRevisions"].([]map[string]interface{})
+	if len(newer) != 2 || newer[0]["revision"] != int64(5) || newer[1]["changeCause"] != "bump to 6" {
+		t.Errorf("Expected revisions 5 and 6 to be newer, got %v", newer)
+	}
+
+	if _, err := client.GetPodRevision(context.Background(), "prod", "db-0"); err == nil {
+		t.Error("Expected a StatefulSet pod to be rejected")
+	}
+}
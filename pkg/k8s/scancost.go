@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultLargeScanThreshold is the number of objects above which a scan
+// across namespaces needs confirmation unless the server configures another
+// threshold.
+const DefaultLargeScanThreshold = 5000
+
+// ScanEstimate is the estimated cost of listing a kind: the API calls the
+// list takes and the objects it returns.
+type ScanEstimate struct {
+	Kind       string `json:"kind"`
+	Namespaces int    `json:"namespaces,omitempty"` // Namespaces listed one at a time under a namespace policy
+	APICalls   int    `json:"apiCalls"`
+	Objects    int64  `json:"objects"`
+	Exact      bool   `json:"exact"`                // Objects is a count rather than an estimate
+	UpperBound bool   `json:"upperBound,omitempty"` // Objects counts the kind without the selectors, which the API server cannot estimate
+	Threshold  int    `json:"threshold"`
+}
+
+// SetLargeScanThreshold sets the number of objects above which LargeScan
+// reports a scan as large; zero or less disables the check. Copies of the
+// client and the clients of a ClientPool created from it keep the threshold.
+func (c *Client) SetLargeScanThreshold(objects int) {
+	c.scanThreshold = objects
+}
+
+// LargeScan estimates the cost of listing kind across all namespaces, or in
+// namespace, with the given selectors, before the list is made. Only lists
+// spanning namespaces are estimated; a single namespace or a cluster-scoped
+// kind is never large. Returns the estimate if the scan would return more
+// objects than the client's threshold, nil otherwise, or an error.
+func (c *Client) LargeScan(ctx context.Context, kind, namespace, labelSelector, fieldSelector string) (*ScanEstimate, error) {
+	if c.scanThreshold <= 0 || namespace != "" {
+		return nil, nil
+	}
+	info, err := c.getCachedResource(kind)
+	if err != nil {
+		return nil, err
+	}
+	if !info.namespaced {
+		return nil, nil
+	}
+	estimate, err := c.EstimateScan(ctx, kind, labelSelector, fieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	if estimate.Objects <= int64(c.scanThreshold) {
+		return nil, nil
+	}
+	return estimate, nil
+}
+
+// EstimateScan estimates the cost of listing kind across all namespaces with
+// the given selectors, from lists of a single object: the API server returns
+// the number of objects remaining after it. Under a namespace policy, each
+// allowed namespace is listed, and estimated, separately. When selectors
+// keep the API server from estimating, the objects of the kind without
+// selectors are counted instead, as an upper bound.
+// Returns the estimate, or an error.
+func (c *Client) EstimateScan(ctx context.Context, kind, labelSelector, fieldSelector string) (*ScanEstimate, error) {
+	estimate := &ScanEstimate{Kind: kind, Exact: true, Threshold: c.scanThreshold}
+	namespaces := []string{metav1.NamespaceAll}
+	if c.namespaceCheck != nil {
+		list, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		namespaces = nil
+		for _, namespace := range list.Items {
+			if c.namespaceCheck(namespace.Name) == nil {
+				namespaces = append(namespaces, namespace.Name)
+			}
+		}
+		estimate.Namespaces = len(namespaces)
+	}
+	estimate.APICalls = len(namespaces)
+
+	for _, namespace := range namespaces {
+		objects, exact, err := c.countObjects(ctx, kind, namespace, labelSelector, fieldSelector)
+		if err != nil {
+			return nil, err
+		}
+		if objects < 0 {
+			objects, exact, err = c.countObjects(ctx, kind, namespace, "", "")
+			if err != nil {
+				return nil, err
+			}
+			estimate.UpperBound = true
+		}
+		if objects < 0 {
+			// At least two, as the list of one object continues
+			objects = 2
+		}
+		estimate.Objects += objects
+		estimate.Exact = estimate.Exact && exact && !estimate.UpperBound
+	}
+	return estimate, nil
+}
+
+// countObjects counts the objects of kind in namespace from a list of a
+// single object. Returns the count and whether it is exact rather than the
+// API server's estimate, -1 if the API server gave no estimate, or an error.
+func (c *Client) countObjects(ctx context.Context, kind, namespace, labelSelector, fieldSelector string) (int64, bool, error) {
+	resource, err := c.resourceClient(kind, namespace, false)
+	if err != nil {
+		return 0, false, err
+	}
+	list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector, Limit: 1})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to estimate %s: %w", kind, err)
+	}
+	if list.GetContinue() == "" {
+		return int64(len(list.Items)), true, nil
+	}
+	if remaining := list.GetRemainingItemCount(); remaining != nil {
+		return int64(len(list.Items)) + *remaining, false, nil
+	}
+	return -1, false, nil
+}
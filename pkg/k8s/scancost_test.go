@@ -0,0 +1,100 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// TestLargeScan tests estimating lists across namespaces from single-object pages
+func TestLargeScan(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+			return
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+			return
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[` +
+				`{"name":"pods","namespaced":true,"kind":"Pod","verbs":["list"]},` +
+				`{"name":"nodes","namespaced":false,"kind":"Node","verbs":["list"]}]}`))
+			return
+		}
+		requests++
+		if r.URL.Query().Get("limit") != "1" && r.URL.Path != "/api/v1/namespaces" {
+			t.Errorf("Expected single-object pages, got %s", r.URL)
+		}
+		switch r.URL.Path {
+		case "/api/v1/pods":
+			if r.URL.Query().Get("labelSelector") != "" {
+				// The API server gives no estimate for filtered lists
+				w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"next"},"items":[{"metadata":{"name":"a"}}]}`))
+				return
+			}
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"next","remainingItemCount":7999},"items":[{"metadata":{"name":"a"}}]}`))
+		case "/api/v1/namespaces":
+			w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","items":[{"metadata":{"name":"web"}},{"metadata":{"name":"kube-system"}}]}`))
+		case "/api/v1/namespaces/web/pods":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"a"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	if estimate, err := client.LargeScan(ctx, "Pod", "", "", ""); err != nil || estimate != nil || requests != 0 {
+		t.Fatalf("Expected no estimate without a threshold, got %+v, %v", estimate, err)
+	}
+
+	client.SetLargeScanThreshold(5000)
+	estimate, err := client.LargeScan(ctx, "Pod", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate == nil || estimate.Objects != 8000 || estimate.APICalls != 1 || estimate.Exact || estimate.Threshold != 5000 {
+		t.Errorf("Expected an estimate of 8000 pods in one call, got %+v", estimate)
+	}
+
+	estimate, err = client.LargeScan(ctx, "Pod", "", "app=web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate == nil || estimate.Objects != 8000 || !estimate.UpperBound {
+		t.Errorf("Expected the unfiltered count as an upper bound, got %+v", estimate)
+	}
+
+	requests = 0
+	for _, scan := range [][2]string{{"Pod", "web"}, {"Node", ""}} {
+		if estimate, err := client.LargeScan(ctx, scan[0], scan[1], "", ""); err != nil || estimate != nil {
+			t.Errorf("Expected %s in namespace %q not to be estimated, got %+v, %v", scan[0], scan[1], estimate, err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests for scans within a namespace, got %d", requests)
+	}
+
+	guarded, err := client.WithNamespacePolicy(func(namespace string) error {
+		if namespace != "web" {
+			return fmt.Errorf("namespace %q is not in the namespace allowlist", namespace)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if guarded.scanThreshold != 5000 {
+		t.Errorf("Expected the copy to keep the threshold, got %d", guarded.scanThreshold)
+	}
+	estimate, err = guarded.EstimateScan(ctx, "Pod", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.Namespaces != 1 || estimate.APICalls != 1 || estimate.Objects != 1 || !estimate.Exact {
+		t.Errorf("Expected an exact count in the allowed namespace, got %+v", estimate)
+	}
+}
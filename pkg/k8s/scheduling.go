@@ -0,0 +1,294 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// schedulingNotSimulated lists the scheduler filters ExplainScheduling does not
+// evaluate, so a node it reports as fitting may still be rejected by them.
+var schedulingNotSimulated = []string{"InterPodAffinity", "PodTopologySpread", "VolumeBinding", "VolumeZone", "NodeVolumeLimits"}
+
+// schedulingNode is a node with the resources and host ports already taken by
+// the pods bound to it.
+type schedulingNode struct {
+	node      *corev1.Node
+	requested corev1.ResourceList
+	pods      int
+	hostPorts map[string]bool
+}
+
+// schedulingFailure is a filter that rejected a node.
+type schedulingFailure struct {
+	Filter string `json:"filter"`
+	Reason string `json:"reason"`
+}
+
+// hostPortKey identifies a host port as used by the NodePorts filter.
+func hostPortKey(port corev1.ContainerPort) string {
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	return fmt.Sprintf("%s/%d", protocol, port.HostPort)
+}
+
+// toleratesTaint reports whether any of the tolerations tolerates the taint.
+func toleratesTaint(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeSelectorTermMatches reports whether a node matches a required node
+// affinity term: all of its matchExpressions against the node's labels and all
+// of its matchFields against the node's name.
+func nodeSelectorTermMatches(term corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	operators := map[corev1.NodeSelectorOperator]selection.Operator{
+		corev1.NodeSelectorOpIn:           selection.In,
+		corev1.NodeSelectorOpNotIn:        selection.NotIn,
+		corev1.NodeSelectorOpExists:       selection.Exists,
+		corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+		corev1.NodeSelectorOpGt:           selection.GreaterThan,
+		corev1.NodeSelectorOpLt:           selection.LessThan,
+	}
+	match := func(requirements []corev1.NodeSelectorRequirement, set labels.Set) bool {
+		for _, requirement := range requirements {
+			operator, ok := operators[requirement.Operator]
+			if !ok {
+				return false
+			}
+			r, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+			if err != nil || !r.Matches(set) {
+				return false
+			}
+		}
+		return true
+	}
+	return match(term.MatchExpressions, node.Labels) &&
+		match(term.MatchFields, labels.Set{"metadata.name": node.Name})
+}
+
+// schedulingFailures runs the simulated scheduler filters for a pod against a
+// node: NodeName, NodeUnschedulable, NodeSelector, NodeAffinity,
+// TaintToleration, NodePorts and NodeResourcesFit.
+// Returns the filters that reject the node; none if the pod fits.
+func schedulingFailures(spec *corev1.PodSpec, requests corev1.ResourceList, n *schedulingNode) []schedulingFailure {
+	var failures []schedulingFailure
+	node := n.node
+
+	if spec.NodeName != "" && spec.NodeName != node.Name {
+		failures = append(failures, schedulingFailure{"NodeName", fmt.Sprintf("pod requires node %s", spec.NodeName)})
+	}
+	unschedulableTaint := &corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}
+	if node.Spec.Unschedulable && !toleratesTaint(spec.Tolerations, unschedulableTaint) {
+		failures = append(failures, schedulingFailure{"NodeUnschedulable", "node(s) were unschedulable"})
+	}
+
+	var mismatched []string
+	for key, value := range spec.NodeSelector {
+		if actual, ok := node.Labels[key]; !ok || actual != value {
+			mismatched = append(mismatched, key+"="+value)
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		failures = append(failures, schedulingFailure{"NodeSelector", "node(s) didn't match Pod's node selector: " + strings.Join(mismatched, ", ")})
+	}
+	if affinity := spec.Affinity; affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		matched := false
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			if nodeSelectorTermMatches(term, node) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			failures = append(failures, schedulingFailure{"NodeAffinity", "node(s) didn't match Pod's node affinity"})
+		}
+	}
+
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule || toleratesTaint(spec.Tolerations, taint) {
+			continue
+		}
+		description := taint.Key
+		if taint.Value != "" {
+			description += ": " + taint.Value
+		}
+		failures = append(failures, schedulingFailure{"TaintToleration", fmt.Sprintf("node(s) had untolerated taint {%s}", description)})
+	}
+
+	var conflicts []string
+	for _, container := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		for _, port := range container.Ports {
+			if port.HostPort > 0 && n.hostPorts[hostPortKey(port)] {
+				conflicts = append(conflicts, hostPortKey(port))
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		failures = append(failures, schedulingFailure{"NodePorts", "node(s) didn't have free ports for the requested pod ports: " + strings.Join(conflicts, ", ")})
+	}
+
+	allocatable := node.Status.Allocatable
+	if maxPods, ok := allocatable[corev1.ResourcePods]; ok && int64(n.pods+1) > maxPods.Value() {
+		failures = append(failures, schedulingFailure{"NodeResourcesFit", "Too many pods"})
+	}
+	names := make([]string, 0, len(requests))
+	for name := range requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		request := requests[corev1.ResourceName(name)]
+		if request.IsZero() {
+			continue
+		}
+		free := allocatable[corev1.ResourceName(name)].DeepCopy()
+		free.Sub(n.requested[corev1.ResourceName(name)])
+		if request.Cmp(free) > 0 {
+			failures = append(failures, schedulingFailure{"NodeResourcesFit", fmt.Sprintf("Insufficient %s (requested %s, free %s)", name, request.String(), free.String())})
+		}
+	}
+	return failures
+}
+
+// schedulingPodSpec returns the pod spec to simulate: the spec of a Pod, or the
+// pod template of a workload.
+func (c *Client) schedulingPodSpec(ctx context.Context, namespace, kind, name string) (*corev1.PodSpec, string, error) {
+	obj, err := c.GetResource(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+	u := unstructured.Unstructured{Object: obj}
+	specPath := []string{"spec"}
+	if u.GetKind() != "Pod" {
+		specPath = podSpecPath(u.GetKind())
+	}
+	rawSpec, found, _ := unstructured.NestedMap(obj, specPath...)
+	if !found {
+		return nil, "", fmt.Errorf("%s %s has no pod spec at %s", kind, name, strings.Join(specPath, "."))
+	}
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSpec, spec); err != nil {
+		return nil, "", fmt.Errorf("failed to parse pod spec: %w", err)
+	}
+	return spec, string(u.GetUID()), nil
+}
+
+// ExplainScheduling simulates the main scheduler filters for a Pod, or the pod
+// template of a workload, against every node: the node name, unschedulable
+// nodes, node selector, required node affinity, taints and tolerations, host
+// ports and resource requests against the node's allocatable capacity minus
+// the requests of the pods already bound to it. Filters that depend on other
+// pods' placement or on volumes are not simulated.
+// Returns the fitting nodes, the failing filters of each other node and a
+// summary in the style of the scheduler's FailedScheduling message, or an error.
+func (c *Client) ExplainScheduling(ctx context.Context, namespace, kind, name string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	spec, uid, err := c.schedulingPodSpec(ctx, namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	requests, _ := podEffectiveResources(&corev1.Pod{Spec: *spec})
+
+	nodes, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: "spec.nodeName!=,status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	infos := map[string]*schedulingNode{}
+	for i := range nodes.Items {
+		infos[nodes.Items[i].Name] = &schedulingNode{node: &nodes.Items[i], requested: corev1.ResourceList{}, hostPorts: map[string]bool{}}
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		info, ok := infos[pod.Spec.NodeName]
+		if !ok || string(pod.UID) == uid {
+			continue
+		}
+		podRequests, _ := podEffectiveResources(pod)
+		addResourceList(info.requested, podRequests)
+		info.pods++
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.HostPort > 0 {
+					info.hostPorts[hostPortKey(port)] = true
+				}
+			}
+		}
+	}
+
+	fitting := []string{}
+	rejected := []map[string]interface{}{}
+	reasonCounts := map[string]int{}
+	for _, node := range nodes.Items {
+		failures := schedulingFailures(spec, requests, infos[node.Name])
+		if len(failures) == 0 {
+			fitting = append(fitting, node.Name)
+			continue
+		}
+		rejected = append(rejected, map[string]interface{}{"node": node.Name, "failures": failures})
+		for _, failure := range failures {
+			// Count reasons without the per-node quantities, as the scheduler does
+			reason, _, _ := strings.Cut(failure.Reason, " (")
+			reasonCounts[reason]++
+		}
+	}
+	sort.Strings(fitting)
+
+	reasons := make([]string, 0, len(reasonCounts))
+	for reason := range reasonCounts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasonCounts[reasons[i]] != reasonCounts[reasons[j]] {
+			return reasonCounts[reasons[i]] > reasonCounts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	summary := fmt.Sprintf("%d/%d nodes are available", len(fitting), len(nodes.Items))
+	if len(reasons) > 0 {
+		parts := make([]string, len(reasons))
+		for i, reason := range reasons {
+			parts[i] = fmt.Sprintf("%d %s", reasonCounts[reason], reason)
+		}
+		summary += ": " + strings.Join(parts, ", ")
+	}
+
+	return map[string]interface{}{
+		"kind":          kind,
+		"name":          name,
+		"namespace":     namespace,
+		"requests":      resourceListStrings(requests),
+		"summary":       summary,
+		"fittingNodes":  fitting,
+		"rejectedNodes": rejected,
+		"notSimulated":  schedulingNotSimulated,
+	}, nil
+}
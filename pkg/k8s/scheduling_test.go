@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestSchedulingFailures tests the simulated scheduler filters against a node
+func TestSchedulingFailures(t *testing.T) {
+	newNode := func() *schedulingNode {
+		return &schedulingNode{
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "a", "disk": "ssd"}},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}}},
+				Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				}},
+			},
+			requested: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1500m")},
+			hostPorts: map[string]bool{"TCP/8080": true},
+		}
+	}
+	requests := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	gpuToleration := []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+
+	filters := func(failures []schedulingFailure) []string {
+		var names []string
+		for _, failure := range failures {
+			names = append(names, failure.Filter)
+		}
+		return names
+	}
+
+	spec := &corev1.PodSpec{Tolerations: gpuToleration}
+	if failures := schedulingFailures(spec, corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}, newNode()); len(failures) != 0 {
+		t.Errorf("Expected the pod to fit, got %v", failures)
+	}
+
+	spec = &corev1.PodSpec{
+		NodeSelector: map[string]string{"disk": "hdd"},
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b", "c"}}},
+			}}},
+		}},
+		Containers: []corev1.Container{{Name: "web", Ports: []corev1.ContainerPort{{ContainerPort: 80, HostPort: 8080}}}},
+	}
+	got := filters(schedulingFailures(spec, requests, newNode()))
+	want := []string{"NodeSelector", "NodeAffinity", "TaintToleration", "NodePorts", "NodeResourcesFit"}
+	if len(got) != len(want) {
+		t.Fatalf("schedulingFailures() filters = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("schedulingFailures() filters = %v, want %v", got, want)
+		}
+	}
+
+	node := newNode()
+	node.node.Spec.Unschedulable = true
+	spec = &corev1.PodSpec{Tolerations: gpuToleration, Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}}},
+		}}},
+	}}}
+	if got := filters(schedulingFailures(spec, nil, node)); len(got) != 1 || got[0] != "NodeUnschedulable" {
+		t.Errorf("Expected only NodeUnschedulable, got %v", got)
+	}
+}
@@ -0,0 +1,297 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podContainers returns all containers of a pod, including init and
+// ephemeral containers.
+func podContainers(pod *corev1.Pod) []corev1.Container {
+	containers := append([]corev1.Container{}, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, ephemeral := range pod.Spec.EphemeralContainers {
+		containers = append(containers, corev1.Container(ephemeral.EphemeralContainerCommon))
+	}
+	return containers
+}
+
+// podObjectReferences returns how a pod references the ConfigMap or Secret
+// name: through environment variables, envFrom sources and volumes, including
+// projected ones, and for Secrets also through image pull secrets and the
+// nodePublishSecretRef of inline CSI volumes. Each reference reports whether
+// a change only takes effect when the pod restarts: environment variables
+// are read when a container starts, volumes mounted with a subPath are never
+// updated and CSI drivers read their secret when mounting, while other
+// volumes pick up changes once the kubelet syncs them.
+func podObjectReferences(pod *corev1.Pod, kind, name string) []map[string]interface{} {
+	var references []map[string]interface{}
+	optional := func(value *bool) bool { return value != nil && *value }
+
+	for _, ctr := range podContainers(pod) {
+		for _, env := range ctr.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; kind == "ConfigMap" && ref != nil && ref.Name == name {
+				references = append(references, map[string]interface{}{"usedBy": "env " + env.Name, "container": ctr.Name, "key": ref.Key, "optional": optional(ref.Optional), "restartRequired": true})
+			}
+			if ref := env.ValueFrom.SecretKeyRef; kind == "Secret" && ref != nil && ref.Name == name {
+				references = append(references, map[string]interface{}{"usedBy": "env " + env.Name, "container": ctr.Name, "key": ref.Key, "optional": optional(ref.Optional), "restartRequired": true})
+			}
+		}
+		for _, envFrom := range ctr.EnvFrom {
+			if ref := envFrom.ConfigMapRef; kind == "ConfigMap" && ref != nil && ref.Name == name {
+				references = append(references, map[string]interface{}{"usedBy": "envFrom", "container": ctr.Name, "optional": optional(ref.Optional), "restartRequired": true})
+			}
+			if ref := envFrom.SecretRef; kind == "Secret" && ref != nil && ref.Name == name {
+				references = append(references, map[string]interface{}{"usedBy": "envFrom", "container": ctr.Name, "optional": optional(ref.Optional), "restartRequired": true})
+			}
+		}
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		var keys []string
+		var isOptional, csi bool
+		found := false
+		collect := func(items []corev1.KeyToPath, opt *bool) {
+			found = true
+			isOptional = isOptional || optional(opt)
+			for _, item := range items {
+				keys = append(keys, item.Key)
+			}
+		}
+		switch {
+		case kind == "ConfigMap" && volume.ConfigMap != nil && volume.ConfigMap.Name == name:
+			collect(volume.ConfigMap.Items, volume.ConfigMap.Optional)
+		case kind == "Secret" && volume.Secret != nil && volume.Secret.SecretName == name:
+			collect(volume.Secret.Items, volume.Secret.Optional)
+		case kind == "Secret" && volume.CSI != nil && volume.CSI.NodePublishSecretRef != nil && volume.CSI.NodePublishSecretRef.Name == name:
+			found, csi = true, true
+		case volume.Projected != nil:
+			for _, projection := range volume.Projected.Sources {
+				if kind == "ConfigMap" && projection.ConfigMap != nil && projection.ConfigMap.Name == name {
+					collect(projection.ConfigMap.Items, projection.ConfigMap.Optional)
+				}
+				if kind == "Secret" && projection.Secret != nil && projection.Secret.Name == name {
+					collect(projection.Secret.Items, projection.Secret.Optional)
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+
+		containers := []string{}
+		restartRequired := csi
+		for _, ctr := range podContainers(pod) {
+			for _, mount := range ctr.VolumeMounts {
+				if mount.Name != volume.Name {
+					continue
+				}
+				containers = append(containers, ctr.Name)
+				if mount.SubPath != "" || mount.SubPathExpr != "" {
+					restartRequired = true
+				}
+			}
+		}
+		ref := map[string]interface{}{"usedBy": "volume " + volume.Name, "containers": containers, "optional": isOptional, "restartRequired": restartRequired}
+		if len(keys) > 0 {
+			ref["keys"] = keys
+		}
+		if csi {
+			ref["usedBy"] = "csi volume " + volume.Name
+			ref["driver"] = volume.CSI.Driver
+		}
+		references = append(references, ref)
+	}
+
+	if kind == "Secret" {
+		for _, pullSecret := range pod.Spec.ImagePullSecrets {
+			if pullSecret.Name == name {
+				references = append(references, map[string]interface{}{"usedBy": "imagePullSecrets", "restartRequired": false})
+			}
+		}
+	}
+	return references
+}
+
+// csiSecretRefs returns the secret references of a CSI persistent volume,
+// keyed by the field that holds them.
+func csiSecretRefs(csi *corev1.CSIPersistentVolumeSource) map[string]*corev1.SecretReference {
+	return map[string]*corev1.SecretReference{
+		"controllerPublishSecretRef": csi.ControllerPublishSecretRef,
+		"nodeStageSecretRef":         csi.NodeStageSecretRef,
+		"nodePublishSecretRef":       csi.NodePublishSecretRef,
+		"controllerExpandSecretRef":  csi.ControllerExpandSecretRef,
+		"nodeExpandSecretRef":        csi.NodeExpandSecretRef,
+	}
+}
+
+// GetSecretUsage maps everything that references a Secret, to show what
+// breaks if it is rotated or deleted: pods that consume it through
+// environment variables, volumes or image pull secrets, grouped by their
+// workload; Ingresses terminating TLS with it or naming it in annotations,
+// such as basic auth secrets; ServiceAccounts listing it; and CSI persistent
+// volumes whose driver authenticates with it. Pods that only see a new value
+// after a restart are flagged, and their workloads listed. Secret values are
+// never returned. Lookups that fail, e.g. for lack of permission, are
+// reported in "errors".
+// Returns a map with the "secret", its "namespace", whether it "exists", its
+// "type" and "keys", and the referencing "pods", "workloadsToRestart",
+// "ingresses", "serviceAccounts" and "persistentVolumes", or an error.
+func (c *Client) GetSecretUsage(ctx context.Context, namespace, name string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	result := map[string]interface{}{"secret": name, "namespace": namespace}
+	var errors []string
+
+	secret, err := c.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		result["exists"] = false
+	case err != nil:
+		errors = append(errors, fmt.Sprintf("failed to get secret: %v", err))
+	default:
+		result["exists"] = true
+		result["type"] = string(secret.Type)
+		keys := []string{}
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result["keys"] = keys
+		if account := secret.Annotations[corev1.ServiceAccountNameKey]; account != "" && secret.Type == corev1.SecretTypeServiceAccountToken {
+			result["tokenFor"] = account
+		}
+	}
+
+	pods := []map[string]interface{}{}
+	restart := map[string]bool{}
+	podList, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("failed to list pods: %v", err))
+	} else {
+		owners := map[string]map[string]interface{}{}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			references := podObjectReferences(pod, "Secret", name)
+			if len(references) == 0 {
+				continue
+			}
+			restartRequired := false
+			for _, ref := range references {
+				restartRequired = restartRequired || ref["restartRequired"].(bool)
+			}
+			entry := map[string]interface{}{
+				"name":            pod.Name,
+				"phase":           string(pod.Status.Phase),
+				"references":      references,
+				"restartRequired": restartRequired,
+			}
+			workload := "Pod/" + pod.Name
+			if controller := c.podController(ctx, pod, owners); controller != nil {
+				entry["controller"] = controller
+				workload = fmt.Sprintf("%s/%s", controller["kind"], controller["name"])
+			}
+			if restartRequired {
+				restart[workload] = true
+			}
+			pods = append(pods, entry)
+		}
+	}
+	workloads := []string{}
+	for workload := range restart {
+		workloads = append(workloads, workload)
+	}
+	sort.Strings(workloads)
+	result["pods"] = pods
+	result["workloadsToRestart"] = workloads
+
+	ingresses := []map[string]interface{}{}
+	ingressList, err := c.clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("failed to list ingresses: %v", err))
+	} else {
+		for _, ingress := range ingressList.Items {
+			for _, tls := range ingress.Spec.TLS {
+				if tls.SecretName == name {
+					ingresses = append(ingresses, map[string]interface{}{"name": ingress.Name, "usedBy": "tls", "hosts": tls.Hosts})
+				}
+			}
+			// Controllers such as ingress-nginx name secrets in annotations,
+			// either by name or as namespace/name
+			var annotations []string
+			for key, value := range ingress.Annotations {
+				if strings.Contains(strings.ToLower(key), "secret") && (value == name || value == namespace+"/"+name) {
+					annotations = append(annotations, key)
+				}
+			}
+			sort.Strings(annotations)
+			for _, key := range annotations {
+				ingresses = append(ingresses, map[string]interface{}{"name": ingress.Name, "usedBy": "annotation " + key})
+			}
+		}
+	}
+	result["ingresses"] = ingresses
+
+	serviceAccounts := []map[string]interface{}{}
+	accountList, err := c.clientset.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("failed to list service accounts: %v", err))
+	} else {
+		for _, account := range accountList.Items {
+			for _, ref := range account.Secrets {
+				if ref.Name == name {
+					serviceAccounts = append(serviceAccounts, map[string]interface{}{"name": account.Name, "usedBy": "secrets"})
+				}
+			}
+			for _, ref := range account.ImagePullSecrets {
+				if ref.Name == name {
+					serviceAccounts = append(serviceAccounts, map[string]interface{}{"name": account.Name, "usedBy": "imagePullSecrets"})
+				}
+			}
+		}
+	}
+	result["serviceAccounts"] = serviceAccounts
+
+	volumes := []map[string]interface{}{}
+	volumeList, err := c.clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("failed to list persistent volumes: %v", err))
+	} else {
+		for _, volume := range volumeList.Items {
+			if volume.Spec.CSI == nil {
+				continue
+			}
+			var fields []string
+			for field, ref := range csiSecretRefs(volume.Spec.CSI) {
+				if ref != nil && ref.Name == name && ref.Namespace == namespace {
+					fields = append(fields, field)
+				}
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			sort.Strings(fields)
+			entry := map[string]interface{}{"name": volume.Name, "driver": volume.Spec.CSI.Driver, "usedBy": fields}
+			if claim := volume.Spec.ClaimRef; claim != nil {
+				entry["claim"] = claim.Namespace + "/" + claim.Name
+			}
+			volumes = append(volumes, entry)
+		}
+	}
+	result["persistentVolumes"] = volumes
+
+	if len(errors) > 0 {
+		result["errors"] = errors
+	}
+	return result, nil
+}
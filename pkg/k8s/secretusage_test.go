@@ -0,0 +1,51 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestPodObjectReferences tests finding a pod's references to a Secret and which need a restart
+func TestPodObjectReferences(t *testing.T) {
+	optional := true
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "app",
+			Env: []corev1.EnvVar{
+				{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}}},
+				{Name: "OTHER", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "other"}, Key: "token"}}},
+			},
+			VolumeMounts: []corev1.VolumeMount{{Name: "certs", MountPath: "/certs"}, {Name: "config", MountPath: "/etc/app.conf", SubPath: "app.conf"}},
+		}},
+		Volumes: []corev1.Volume{
+			{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "db", Optional: &optional}}},
+			{Name: "config", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Items: []corev1.KeyToPath{{Key: "app.conf", Path: "app.conf"}}}},
+			}}}},
+		},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "db"}},
+	}}
+
+	references := podObjectReferences(pod, "Secret", "db")
+	if len(references) != 4 {
+		t.Fatalf("Expected 4 references, got %v", references)
+	}
+	if ref := references[0]; ref["usedBy"] != "env DB_PASSWORD" || ref["key"] != "password" || ref["restartRequired"] != true {
+		t.Errorf("Expected the env reference to need a restart, got %v", ref)
+	}
+	if ref := references[1]; ref["usedBy"] != "volume certs" || ref["optional"] != true || ref["restartRequired"] != false {
+		t.Errorf("Expected the optional volume to update in place, got %v", ref)
+	}
+	if ref := references[2]; ref["usedBy"] != "volume config" || ref["restartRequired"] != true || ref["keys"].([]string)[0] != "app.conf" {
+		t.Errorf("Expected the subPath mount to need a restart, got %v", ref)
+	}
+	if ref := references[3]; ref["usedBy"] != "imagePullSecrets" {
+		t.Errorf("Expected the image pull secret reference, got %v", ref)
+	}
+	if references := podObjectReferences(pod, "ConfigMap", "db"); len(references) != 0 {
+		t.Errorf("Expected no ConfigMap references, got %v", references)
+	}
+}
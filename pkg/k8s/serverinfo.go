@@ -0,0 +1,60 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// serviceAccountNamespaceFile holds the namespace of the pod's service
+// account when the server runs in a cluster.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// GetServerInfo reports how the client connects to the cluster: whether it
+// was configured "in-cluster" from the pod's service account or from a
+// "kubeconfig", the context and API server address, the Kubernetes version,
+// and the user the API server authenticates the client as, which is the
+// impersonated user for impersonating clients. In-cluster, the
+// namespace of the service account is included. Details that cannot be read
+// are reported in "errors".
+// Returns a map with the connection details and "errors".
+func (c *Client) GetServerInfo(ctx context.Context) map[string]interface{} {
+	errors := []string{}
+	info := map[string]interface{}{
+		"configMode": "kubeconfig",
+		"context":    c.ContextName(),
+		"server":     c.ServerHost(),
+	}
+	if c.impersonating != "" {
+		info["impersonating"] = c.impersonating
+	}
+	if c.InCluster() {
+		info["configMode"] = "in-cluster"
+		if namespace, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+			info["namespace"] = strings.TrimSpace(string(namespace))
+		}
+	}
+
+	if version, err := c.discoveryClient.ServerVersion(); err != nil {
+		errors = append(errors, fmt.Sprintf("version: %v", err))
+	} else {
+		info["kubernetesVersion"] = version.GitVersion
+	}
+
+	review, err := c.clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("user: %v", err))
+	} else {
+		info["user"] = map[string]interface{}{
+			"username": review.Status.UserInfo.Username,
+			"groups":   review.Status.UserInfo.Groups,
+		}
+	}
+
+	info["errors"] = errors
+	return info
+}
@@ -0,0 +1,366 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// istioProxyContainer and linkerdProxyContainer are the names of the
+	// proxy containers the meshes inject.
+	istioProxyContainer   = "istio-proxy"
+	linkerdProxyContainer = "linkerd-proxy"
+	// linkerdNamespace is the namespace of the Linkerd control plane.
+	linkerdNamespace = "linkerd"
+)
+
+// meshWorkload is a workload whose pods a mesh may inject a proxy into.
+type meshWorkload struct {
+	kind     string
+	name     string
+	template corev1.PodTemplateSpec
+	selector *metav1.LabelSelector
+}
+
+// meshInjection is whether a mesh injects its proxy into a pod, and why.
+type meshInjection struct {
+	enabled  bool
+	revision string
+	reason   string
+}
+
+// GetSidecarInjectionStatus reports, for the Deployments, StatefulSets and
+// DaemonSets of a namespace, or a single one of them, whether Istio and
+// Linkerd sidecar injection is enabled by the namespace and pod template
+// labels and annotations, and whether their running pods contain the proxy
+// container at the version of the control plane. Each workload gets a status
+// per mesh: "injected", "missingProxy" (pods predating injection, which need a
+// restart), "outdatedProxy" (pods running another proxy version than the
+// control plane), "unexpectedProxy" (pods keeping a proxy after injection was
+// disabled), "notInjected" or "noPods". Meshes whose control plane is not found
+// and that no workload uses are left out. Lookups that fail are reported in
+// "errors".
+// Returns a map with the "namespaceInjection", the "controlPlanes", the
+// "workloads", a "summary" of their statuses and "errors", or an error.
+func (c *Client) GetSidecarInjectionStatus(ctx context.Context, namespace, workload string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	ns, err := c.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	errs := []string{}
+	workloads, err := c.meshWorkloads(ctx, namespace, workload, &errs)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	istioVersions := c.istioControlPlaneVersions(ctx, &errs)
+	linkerdVersion, linkerdInstalled := c.linkerdControlPlaneVersion(ctx, &errs)
+
+	summary := map[string]int{}
+	workloadSummaries := []map[string]interface{}{}
+	for _, w := range workloads {
+		selector, err := metav1.LabelSelectorAsSelector(w.selector)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s: invalid selector: %v", w.kind, w.name, err))
+			continue
+		}
+		var workloadPods []corev1.Pod
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodRunning && selector.Matches(labels.Set(pod.Labels)) {
+				workloadPods = append(workloadPods, pod)
+			}
+		}
+
+		meshes := []map[string]interface{}{}
+		istio := istioInjection(ns.Labels, w.template.Labels, w.template.Annotations)
+		revision := istio.revision
+		if revision == "" {
+			revision = "default"
+		}
+		if mesh := meshProxyStatus("istio", istio, istioProxyContainer, istioVersions[revision], workloadPods); len(istioVersions) > 0 || mesh["podsWithProxy"].(int) > 0 {
+			if istio.enabled {
+				mesh["revision"] = revision
+				if _, ok := istioVersions[revision]; !ok {
+					errs = append(errs, fmt.Sprintf("%s %s: no istiod found for revision %s", w.kind, w.name, revision))
+				}
+			}
+			meshes = append(meshes, mesh)
+		}
+		linkerd := linkerdInjection(ns.Annotations, w.template.Annotations)
+		if mesh := meshProxyStatus("linkerd", linkerd, linkerdProxyContainer, linkerdVersion, workloadPods); linkerdInstalled || mesh["podsWithProxy"].(int) > 0 {
+			meshes = append(meshes, mesh)
+		}
+
+		for _, mesh := range meshes {
+			summary[mesh["status"].(string)]++
+		}
+		workloadSummaries = append(workloadSummaries, map[string]interface{}{
+			"kind":   w.kind,
+			"name":   w.name,
+			"pods":   len(workloadPods),
+			"meshes": meshes,
+		})
+	}
+
+	istioNamespace := istioInjection(ns.Labels, nil, nil)
+	linkerdNamespaceInjection := linkerdInjection(ns.Annotations, nil)
+	controlPlanes := map[string]interface{}{}
+	if len(istioVersions) > 0 {
+		controlPlanes["istio"] = istioVersions
+	}
+	if linkerdInstalled {
+		controlPlanes["linkerd"] = map[string]interface{}{"version": linkerdVersion}
+	}
+
+	return map[string]interface{}{
+		"namespace": namespace,
+		"namespaceInjection": map[string]interface{}{
+			"istio":   map[string]interface{}{"enabled": istioNamespace.enabled, "reason": istioNamespace.reason},
+			"linkerd": map[string]interface{}{"enabled": linkerdNamespaceInjection.enabled, "reason": linkerdNamespaceInjection.reason},
+		},
+		"controlPlanes": controlPlanes,
+		"workloads":     workloadSummaries,
+		"summary":       summary,
+		"errors":        errs,
+	}, nil
+}
+
+// meshWorkloads returns the named Deployment, StatefulSet or DaemonSet, or
+// all of them in the namespace when name is empty. Kinds that cannot be
+// listed are reported in errs.
+func (c *Client) meshWorkloads(ctx context.Context, namespace, name string, errs *[]string) ([]meshWorkload, error) {
+	apps := c.clientset.AppsV1()
+	if name != "" {
+		if d, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			return []meshWorkload{{"Deployment", d.Name, d.Spec.Template, d.Spec.Selector}}, nil
+		}
+		if s, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			return []meshWorkload{{"StatefulSet", s.Name, s.Spec.Template, s.Spec.Selector}}, nil
+		}
+		if d, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			return []meshWorkload{{"DaemonSet", d.Name, d.Spec.Template, d.Spec.Selector}}, nil
+		}
+		return nil, fmt.Errorf("workload %s/%s not found as Deployment, StatefulSet or DaemonSet", namespace, name)
+	}
+
+	var workloads []meshWorkload
+	if list, err := apps.Deployments(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		*errs = append(*errs, fmt.Sprintf("deployments: %v", err))
+	} else {
+		for _, d := range list.Items {
+			workloads = append(workloads, meshWorkload{"Deployment", d.Name, d.Spec.Template, d.Spec.Selector})
+		}
+	}
+	if list, err := apps.StatefulSets(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		*errs = append(*errs, fmt.Sprintf("statefulsets: %v", err))
+	} else {
+		for _, s := range list.Items {
+			workloads = append(workloads, meshWorkload{"StatefulSet", s.Name, s.Spec.Template, s.Spec.Selector})
+		}
+	}
+	if list, err := apps.DaemonSets(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		*errs = append(*errs, fmt.Sprintf("daemonsets: %v", err))
+	} else {
+		for _, d := range list.Items {
+			workloads = append(workloads, meshWorkload{"DaemonSet", d.Name, d.Spec.Template, d.Spec.Selector})
+		}
+	}
+	return workloads, nil
+}
+
+// istioControlPlaneVersions returns the proxy version of each istiod revision
+// in the Istio root namespace, taken from the istiod image tag, which Istio
+// releases share with the proxy.
+func (c *Client) istioControlPlaneVersions(ctx context.Context, errs *[]string) map[string]string {
+	versions := map[string]string{}
+	deployments, err := c.clientset.AppsV1().Deployments(istioRootNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			*errs = append(*errs, fmt.Sprintf("istiod: %v", err))
+		}
+		return versions
+	}
+	for _, d := range deployments.Items {
+		revision := d.Labels["istio.io/rev"]
+		if revision == "" {
+			revision = "default"
+		}
+		versions[revision] = ""
+		if image, ok := deploymentImage(&d, "discovery"); ok {
+			versions[revision] = proxyVersion(image)
+		}
+	}
+	return versions
+}
+
+// linkerdControlPlaneVersion returns the proxy version of the Linkerd control
+// plane, taken from the proxy of its destination component, and whether the
+// control plane was found.
+func (c *Client) linkerdControlPlaneVersion(ctx context.Context, errs *[]string) (string, bool) {
+	deployments, err := c.clientset.AppsV1().Deployments(linkerdNamespace).List(ctx, metav1.ListOptions{LabelSelector: "linkerd.io/control-plane-component=destination"})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			*errs = append(*errs, fmt.Sprintf("linkerd: %v", err))
+		}
+		return "", false
+	}
+	if len(deployments.Items) == 0 {
+		return "", false
+	}
+	if image, ok := deploymentImage(&deployments.Items[0], linkerdProxyContainer); ok {
+		return proxyVersion(image), true
+	}
+	return "", true
+}
+
+// deploymentImage returns the image of the named container of a Deployment.
+func deploymentImage(d *appsv1.Deployment, container string) (string, bool) {
+	for _, ctr := range append(append([]corev1.Container{}, d.Spec.Template.Spec.InitContainers...), d.Spec.Template.Spec.Containers...) {
+		if ctr.Name == container {
+			return ctr.Image, true
+		}
+	}
+	return "", false
+}
+
+// istioInjection decides whether Istio injects its proxy into pods of a
+// template, following the injection webhook: the namespace label
+// istio-injection=disabled and the pod label or annotation
+// sidecar.istio.io/inject=false opt out; istio-injection=enabled, an
+// istio.io/rev revision label, or sidecar.istio.io/inject=true opt in.
+func istioInjection(namespaceLabels, podLabels, podAnnotations map[string]string) meshInjection {
+	if namespaceLabels["istio-injection"] == "disabled" {
+		return meshInjection{reason: "namespace label istio-injection=disabled"}
+	}
+	inject, ok := podLabels["sidecar.istio.io/inject"]
+	if !ok {
+		inject = podAnnotations["sidecar.istio.io/inject"]
+	}
+	if inject == "false" {
+		return meshInjection{reason: "pod template sidecar.istio.io/inject=false"}
+	}
+	switch {
+	case namespaceLabels["istio-injection"] == "enabled":
+		return meshInjection{enabled: true, reason: "namespace label istio-injection=enabled"}
+	case podLabels["istio.io/rev"] != "":
+		return meshInjection{enabled: true, revision: podLabels["istio.io/rev"], reason: "pod template label istio.io/rev=" + podLabels["istio.io/rev"]}
+	case namespaceLabels["istio.io/rev"] != "":
+		return meshInjection{enabled: true, revision: namespaceLabels["istio.io/rev"], reason: "namespace label istio.io/rev=" + namespaceLabels["istio.io/rev"]}
+	case inject == "true":
+		return meshInjection{enabled: true, reason: "pod template sidecar.istio.io/inject=true"}
+	}
+	return meshInjection{reason: "no Istio injection label on the namespace or pod template"}
+}
+
+// linkerdInjection decides whether Linkerd injects its proxy into pods of a
+// template from the linkerd.io/inject annotation of the pod template, or else
+// of the namespace. "enabled" and "ingress" inject; "disabled" does not.
+func linkerdInjection(namespaceAnnotations, podAnnotations map[string]string) meshInjection {
+	for _, source := range []struct {
+		name        string
+		annotations map[string]string
+	}{{"pod template", podAnnotations}, {"namespace", namespaceAnnotations}} {
+		switch value := source.annotations["linkerd.io/inject"]; value {
+		case "enabled", "ingress":
+			return meshInjection{enabled: true, reason: source.name + " annotation linkerd.io/inject=" + value}
+		case "disabled":
+			return meshInjection{reason: source.name + " annotation linkerd.io/inject=disabled"}
+		}
+	}
+	return meshInjection{reason: "no linkerd.io/inject annotation on the namespace or pod template"}
+}
+
+// meshProxyStatus checks the running pods of a workload for the proxy
+// container of a mesh and compares its version to expectedVersion, which may
+// be empty if the control plane version is unknown.
+func meshProxyStatus(mesh string, injection meshInjection, container, expectedVersion string, pods []corev1.Pod) map[string]interface{} {
+	withProxy := 0
+	versions := map[string]int{}
+	withoutProxy := []string{}
+	outdated := []string{}
+	for _, pod := range pods {
+		image, ok := podProxyImage(&pod, container)
+		if !ok {
+			withoutProxy = append(withoutProxy, pod.Name)
+			continue
+		}
+		withProxy++
+		version := proxyVersion(image)
+		versions[version]++
+		if expectedVersion != "" && version != expectedVersion {
+			outdated = append(outdated, pod.Name)
+		}
+	}
+	sort.Strings(withoutProxy)
+	sort.Strings(outdated)
+
+	status := "injected"
+	switch {
+	case len(pods) == 0:
+		status = "noPods"
+	case !injection.enabled && withProxy > 0:
+		status = "unexpectedProxy"
+	case !injection.enabled:
+		status = "notInjected"
+	case len(withoutProxy) > 0:
+		status = "missingProxy"
+	case len(outdated) > 0:
+		status = "outdatedProxy"
+	}
+
+	result := map[string]interface{}{
+		"mesh":          mesh,
+		"injection":     injection.enabled,
+		"reason":        injection.reason,
+		"status":        status,
+		"podsWithProxy": withProxy,
+		"proxyVersions": versions,
+	}
+	if expectedVersion != "" {
+		result["expectedVersion"] = expectedVersion
+	}
+	if injection.enabled && len(withoutProxy) > 0 {
+		result["podsWithoutProxy"] = withoutProxy
+	}
+	if len(outdated) > 0 {
+		result["podsOutdated"] = outdated
+	}
+	return result
+}
+
+// podProxyImage returns the image of the named proxy container of a pod,
+// which is an init container when injected as a native sidecar.
+func podProxyImage(pod *corev1.Pod, container string) (string, bool) {
+	for _, ctr := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		if ctr.Name == container {
+			return ctr.Image, true
+		}
+	}
+	return "", false
+}
+
+// proxyVersion returns the version of a proxy image, its tag without the
+// variant suffixes Istio publishes, e.g. "1.22.1" for "istio/proxyv2:1.22.1-distroless".
+func proxyVersion(image string) string {
+	_, _, reference := parseImageReference(image)
+	for _, variant := range []string{"-distroless", "-debug"} {
+		reference = strings.TrimSuffix(reference, variant)
+	}
+	return reference
+}
@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestIstioInjection tests deciding Istio injection from namespace and pod template labels
+func TestIstioInjection(t *testing.T) {
+	tests := []struct {
+		name            string
+		namespaceLabels map[string]string
+		podLabels       map[string]string
+		podAnnotations  map[string]string
+		enabled         bool
+		revision        string
+	}{
+		{"namespace enabled", map[string]string{"istio-injection": "enabled"}, nil, nil, true, ""},
+		{"namespace revision", map[string]string{"istio.io/rev": "1-22"}, nil, nil, true, "1-22"},
+		{"pod opts out", map[string]string{"istio-injection": "enabled"}, map[string]string{"sidecar.istio.io/inject": "false"}, nil, false, ""},
+		{"pod opts out by annotation", map[string]string{"istio.io/rev": "1-22"}, nil, map[string]string{"sidecar.istio.io/inject": "false"}, false, ""},
+		{"pod opts in", nil, map[string]string{"sidecar.istio.io/inject": "true"}, nil, true, ""},
+		{"namespace disabled wins", map[string]string{"istio-injection": "disabled"}, map[string]string{"sidecar.istio.io/inject": "true"}, nil, false, ""},
+		{"pod revision", nil, map[string]string{"istio.io/rev": "canary"}, nil, true, "canary"},
+		{"unlabeled", nil, nil, nil, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := istioInjection(tt.namespaceLabels, tt.podLabels, tt.podAnnotations)
+			if got.enabled != tt.enabled || got.revision != tt.revision || got.reason == "" {
+				t.Errorf("istioInjection() = %+v, want enabled %v revision %q", got, tt.enabled, tt.revision)
+			}
+		})
+	}
+}
+
+// TestLinkerdInjection tests that the pod template annotation takes precedence over the namespace
+func TestLinkerdInjection(t *testing.T) {
+	enabled := map[string]string{"linkerd.io/inject": "enabled"}
+	disabled := map[string]string{"linkerd.io/inject": "disabled"}
+	if !linkerdInjection(enabled, nil).enabled || linkerdInjection(enabled, disabled).enabled || !linkerdInjection(nil, map[string]string{"linkerd.io/inject": "ingress"}).enabled {
+		t.Error("Unexpected Linkerd injection decision")
+	}
+	if got := linkerdInjection(nil, nil); got.enabled || got.reason == "" {
+		t.Errorf("Expected no injection without annotations, got %+v", got)
+	}
+}
+
+// TestMeshProxyStatus tests classifying the proxies of a workload's pods
+func TestMeshProxyStatus(t *testing.T) {
+	pod := func(name string, sidecar *corev1.Container) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:1"}}}}
+		if sidecar != nil {
+			p.Spec.InitContainers = append(p.Spec.InitContainers, *sidecar)
+		}
+		return p
+	}
+	current := &corev1.Container{Name: istioProxyContainer, Image: "docker.io/istio/proxyv2:1.22.1-distroless"}
+	old := &corev1.Container{Name: istioProxyContainer, Image: "docker.io/istio/proxyv2:1.21.0"}
+	enabled := meshInjection{enabled: true, reason: "namespace label istio-injection=enabled"}
+
+	tests := []struct {
+		name      string
+		injection meshInjection
+		pods      []corev1.Pod
+		status    string
+	}{
+		{"injected", enabled, []corev1.Pod{pod("a", current)}, "injected"},
+		{"missing proxy", enabled, []corev1.Pod{pod("a", current), pod("b", nil)}, "missingProxy"},
+		{"outdated proxy", enabled, []corev1.Pod{pod("a", old)}, "outdatedProxy"},
+		{"unexpected proxy", meshInjection{}, []corev1.Pod{pod("a", current)}, "unexpectedProxy"},
+		{"not injected", meshInjection{}, []corev1.Pod{pod("a", nil)}, "notInjected"},
+		{"no pods", enabled, nil, "noPods"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := meshProxyStatus("istio", tt.injection, istioProxyContainer, "1.22.1", tt.pods)
+			if got["status"] != tt.status {
+				t.Errorf("status = %v, want %s (%v)", got["status"], tt.status, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// startupPhases are the phases of a pod's start, in order.
+var startupPhases = []string{"scheduling", "imagePull", "containerStart", "probeWarmup"}
+
+// pulledImagePattern matches the duration the kubelet reports in Pulled
+// events, e.g. `Successfully pulled image "nginx:1.27" in 3.2s (3.9s
+// including waiting)`.
+var pulledImagePattern = regexp.MustCompile(`Successfully pulled image "([^"]*)" in ([0-9.]+[a-zµ]+)`)
+
+// GetPodStartupBreakdown measures how long the most recent pods of a
+// workload, or the pods matching labelSelector, took to start, and splits the
+// time into phases so a slow start can be attributed:
+//   - scheduling: from creation until the PodScheduled condition.
+//   - imagePull: the pull durations the kubelet reports in Pulled events,
+//     which it pulls one at a time by default.
+//   - containerStart: the rest of the time until the last container started,
+//     i.e. volume setup, init containers and container creation.
+//   - probeWarmup: from the last container start until the Ready condition,
+//     the time spent waiting for startup and readiness probes.
+//
+// Pods are taken newest first, up to maxPods. Phases of pods that have not
+// reached them are omitted, and pulls whose events have expired are not
+// counted, which the pod's "notes" point out.
+// Returns a map with the "pods", the median and maximum seconds of each phase
+// in "summary", and the "dominantPhase" with the largest median, or an error.
+func (c *Client) GetPodStartupBreakdown(ctx context.Context, namespace, kind, name, labelSelector string, maxPods int) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	if maxPods <= 0 {
+		maxPods = 10
+	}
+	if name != "" {
+		if kind == "" {
+			kind = "Deployment"
+		}
+		object, err := c.GetResource(ctx, kind, name, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+		}
+		if labelSelector, err = objectPodSelector(&unstructured.Unstructured{Object: object}); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	pods := list.Items
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+	if len(pods) > maxPods {
+		pods = pods[:maxPods]
+	}
+
+	errs := []string{}
+	var events []map[string]interface{}
+	if len(pods) > 0 {
+		if events, err = c.listNormalizedEvents(ctx, namespace, metav1.ListOptions{}); err != nil {
+			errs = append(errs, fmt.Sprintf("events: %v", err))
+		}
+	}
+
+	results := []map[string]interface{}{}
+	durations := map[string][]time.Duration{}
+	for i := range pods {
+		result, phases := podStartupBreakdown(&pods[i], podPullEvents(events, &pods[i]))
+		for phase, duration := range phases {
+			durations[phase] = append(durations[phase], duration)
+		}
+		results = append(results, result)
+	}
+
+	summary := map[string]interface{}{}
+	dominant := ""
+	var dominantMedian time.Duration
+	for _, phase := range append(startupPhases, "timeToReady") {
+		values := durations[phase]
+		if len(values) == 0 {
+			continue
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		median := values[len(values)/2]
+		summary[phase] = map[string]interface{}{
+			"medianSeconds": startupSeconds(median),
+			"maxSeconds":    startupSeconds(values[len(values)-1]),
+			"pods":          len(values),
+		}
+		if phase != "timeToReady" && median > dominantMedian {
+			dominant, dominantMedian = phase, median
+		}
+	}
+
+	result := map[string]interface{}{
+		"namespace":     namespace,
+		"labelSelector": labelSelector,
+		"pods":          results,
+		"summary":       summary,
+		"errors":        errs,
+	}
+	if dominant != "" {
+		result["dominantPhase"] = dominant
+	}
+	return result, nil
+}
+
+// imagePull is an image pull the kubelet reported for a container of a pod.
+type imagePull struct {
+	container string
+	image     string
+	duration  time.Duration
+	cached    bool
+}
+
+// podPullEvents extracts the image pulls of a pod from its Pulled events.
+// Events of an earlier pod of the same name are told apart by UID where the
+// event records it.
+func podPullEvents(events []map[string]interface{}, pod *corev1.Pod) []imagePull {
+	var pulls []imagePull
+	for _, event := range events {
+		involved, _ := event["involvedObject"].(map[string]interface{})
+		if involved["kind"] != "Pod" || involved["name"] != pod.Name || event["reason"] != "Pulled" {
+			continue
+		}
+		if uid, ok := involved["uid"].(string); ok && uid != string(pod.UID) {
+			continue
+		}
+		pull := imagePull{}
+		if fieldPath, _ := involved["fieldPath"].(string); fieldPath != "" {
+			if match := containerFieldPathPattern.FindStringSubmatch(fieldPath); match != nil {
+				pull.container = match[1]
+			}
+		}
+		message, _ := event["message"].(string)
+		if match := pulledImagePattern.FindStringSubmatch(message); match != nil {
+			duration, err := time.ParseDuration(match[2])
+			if err != nil {
+				continue
+			}
+			pull.image, pull.duration = match[1], duration
+		} else if strings.Contains(message, "already present on machine") {
+			pull.cached = true
+			if image, _, ok := strings.Cut(strings.TrimPrefix(message, `Container image "`), `"`); ok {
+				pull.image = image
+			}
+		} else {
+			continue
+		}
+		pulls = append(pulls, pull)
+	}
+	sort.Slice(pulls, func(i, j int) bool { return pulls[i].container < pulls[j].container })
+	return pulls
+}
+
+// podStartupBreakdown splits the start of a pod into phases. It returns the
+// pod's report and the duration of each phase and of timeToReady it reached.
+func podStartupBreakdown(pod *corev1.Pod, pulls []imagePull) (map[string]interface{}, map[string]time.Duration) {
+	created := pod.CreationTimestamp.Time
+	scheduled := podConditionTime(pod, corev1.PodScheduled)
+	ready := podConditionTime(pod, corev1.PodReady)
+
+	var lastStarted time.Time
+	restarts := int32(0)
+	started := len(pod.Status.ContainerStatuses) > 0
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+		switch {
+		case status.State.Running != nil:
+			if status.State.Running.StartedAt.After(lastStarted) {
+				lastStarted = status.State.Running.StartedAt.Time
+			}
+		case status.State.Terminated != nil:
+			if status.State.Terminated.StartedAt.After(lastStarted) {
+				lastStarted = status.State.Terminated.StartedAt.Time
+			}
+		default:
+			started = false
+		}
+	}
+
+	pullSummaries := []map[string]interface{}{}
+	var pulled time.Duration
+	for _, pull := range pulls {
+		pulled += pull.duration
+		summary := map[string]interface{}{"container": pull.container, "image": pull.image, "cached": pull.cached}
+		if !pull.cached {
+			summary["seconds"] = startupSeconds(pull.duration)
+		}
+		pullSummaries = append(pullSummaries, summary)
+	}
+
+	phases := map[string]time.Duration{}
+	notes := []string{}
+	if !scheduled.IsZero() {
+		phases["scheduling"] = scheduled.Sub(created)
+	} else {
+		notes = append(notes, "The pod has not been scheduled")
+	}
+	if len(pulls) > 0 {
+		phases["imagePull"] = pulled
+	} else if !scheduled.IsZero() {
+		notes = append(notes, "No Pulled events were found, possibly because they expired; pull time is included in containerStart")
+	}
+	if started && !scheduled.IsZero() && !lastStarted.IsZero() {
+		phases["containerStart"] = max(lastStarted.Sub(scheduled)-pulled, 0)
+		if !ready.IsZero() {
+			phases["probeWarmup"] = max(ready.Sub(lastStarted), 0)
+		}
+	}
+	if !ready.IsZero() {
+		phases["timeToReady"] = ready.Sub(created)
+	} else if !scheduled.IsZero() && !started {
+		notes = append(notes, "Not all containers have started")
+	} else if !scheduled.IsZero() {
+		notes = append(notes, "The pod is not ready")
+	}
+	if restarts > 0 {
+		notes = append(notes, fmt.Sprintf("Containers restarted %d times; start and ready times are those of the latest restart", restarts))
+	}
+
+	seconds := map[string]interface{}{}
+	for phase, duration := range phases {
+		seconds[phase] = startupSeconds(duration)
+	}
+	result := map[string]interface{}{
+		"pod":     pod.Name,
+		"created": pod.CreationTimestamp.Time,
+		"phase":   string(pod.Status.Phase),
+		"seconds": seconds,
+		"pulls":   pullSummaries,
+		"notes":   notes,
+	}
+	if pod.Spec.NodeName != "" {
+		result["node"] = pod.Spec.NodeName
+	}
+	return result, phases
+}
+
+// podConditionTime returns when a condition of the pod last became true, or
+// the zero time if it is not true.
+func podConditionTime(pod *corev1.Pod, conditionType corev1.PodConditionType) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// startupSeconds converts a duration into seconds rounded to a tenth.
+func startupSeconds(duration time.Duration) float64 {
+	return duration.Round(100 * time.Millisecond).Seconds()
+}
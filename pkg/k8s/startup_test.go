@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestPodPullEvents tests extracting image pull durations from Pulled events
+func TestPodPullEvents(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1", UID: "uid-1"}}
+	event := func(uid, fieldPath, message string) map[string]interface{} {
+		return map[string]interface{}{
+			"reason":         "Pulled",
+			"message":        message,
+			"involvedObject": map[string]interface{}{"kind": "Pod", "name": "api-1", "uid": uid, "fieldPath": fieldPath},
+		}
+	}
+	events := []map[string]interface{}{
+		event("uid-1", "spec.containers{api}", `Successfully pulled image "api:2.1" in 12.5s (14.1s including waiting). Image size: 123 bytes.`),
+		event("uid-1", "spec.initContainers{migrate}", `Container image "migrate:1" already present on machine`),
+		event("uid-0", "spec.containers{api}", `Successfully pulled image "api:2.0" in 30s`),
+		{"reason": "Pulling", "message": `Pulling image "api:2.1"`, "involvedObject": map[string]interface{}{"kind": "Pod", "name": "api-1", "uid": "uid-1"}},
+	}
+
+	pulls := podPullEvents(events, pod)
+	if len(pulls) != 2 {
+		t.Fatalf("Expected the pulls of the current pod only, got %+v", pulls)
+	}
+	if pulls[0].container != "api" || pulls[0].image != "api:2.1" || pulls[0].duration != 12500*time.Millisecond || pulls[0].cached {
+		t.Errorf("Unexpected pull %+v", pulls[0])
+	}
+	if pulls[1].container != "migrate" || pulls[1].image != "migrate:1" || !pulls[1].cached || pulls[1].duration != 0 {
+		t.Errorf("Expected a cached pull, got %+v", pulls[1])
+	}
+}
+
+// TestPodStartupBreakdown tests splitting a pod's start into phases
+func TestPodStartupBreakdown(t *testing.T) {
+	created := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(seconds int) metav1.Time {
+		return metav1.NewTime(created.Add(time.Duration(seconds) * time.Second))
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-1", CreationTimestamp: metav1.NewTime(created)},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: at(4)},
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: at(60)},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "api", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: at(30)}}},
+				{Name: "proxy", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: at(20)}}},
+			},
+		},
+	}
+
+	_, phases := podStartupBreakdown(pod, []imagePull{{container: "api", image: "api:2.1", duration: 20 * time.Second}})
+	want := map[string]time.Duration{
+		"scheduling":     4 * time.Second,
+		"imagePull":      20 * time.Second,
+		"containerStart": 6 * time.Second,
+		"probeWarmup":    30 * time.Second,
+		"timeToReady":    60 * time.Second,
+	}
+	for phase, duration := range want {
+		if phases[phase] != duration {
+			t.Errorf("Expected %s to take %v, got %v", phase, duration, phases[phase])
+		}
+	}
+
+	pod.Status.Conditions = pod.Status.Conditions[:1]
+	pod.Status.ContainerStatuses[0].State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+	result, phases := podStartupBreakdown(pod, nil)
+	if _, ok := phases["containerStart"]; ok || phases["scheduling"] != 4*time.Second {
+		t.Errorf("Expected only the scheduling phase of a starting pod, got %v", phases)
+	}
+	if notes := result["notes"].([]string); len(notes) != 2 {
+		t.Errorf("Expected notes on the missing pulls and unstarted containers, got %v", notes)
+	}
+}
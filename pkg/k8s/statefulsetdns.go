@@ -0,0 +1,194 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// dnsLookupTimeout bounds each lookup of a per-pod DNS record.
+const dnsLookupTimeout = 2 * time.Second
+
+// CheckStatefulSetDNS verifies the wiring that gives the pods of a
+// StatefulSet stable DNS names, a frequent cause of clustered stores failing
+// to bootstrap: the Service named by spec.serviceName must exist, be
+// headless and select the pods; each pod must have its hostname and
+// subdomain set; and each pod must be published in the Service's
+// EndpointSlices with its hostname, which is what the cluster DNS serves
+// <pod>.<service>.<namespace>.svc.<clusterDomain> from. Pods that are not
+// ready are only published if the Service sets publishNotReadyAddresses,
+// which stores that discover their peers before becoming ready need. When
+// the server runs in the cluster, each record is also resolved. An empty
+// clusterDomain defaults to cluster.local.
+// Returns a map with the "statefulSet", its "serviceName", the "service"
+// checks, the per-pod "pods" records and their "problems", and overall
+// "problems" and "warnings", or an error if the StatefulSet cannot be read.
+func (c *Client) CheckStatefulSetDNS(ctx context.Context, namespace, name, clusterDomain string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	if clusterDomain == "" {
+		clusterDomain = "cluster.local"
+	}
+	sts, err := c.clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statefulset %s/%s: %w", namespace, name, err)
+	}
+
+	problems := []string{}
+	warnings := []string{}
+	result := map[string]interface{}{
+		"statefulSet":   name,
+		"namespace":     namespace,
+		"serviceName":   sts.Spec.ServiceName,
+		"clusterDomain": clusterDomain,
+	}
+	defer func() {
+		result["problems"] = problems
+		result["warnings"] = warnings
+	}()
+
+	if sts.Spec.ServiceName == "" {
+		problems = append(problems, "spec.serviceName is empty; the pods get no per-pod DNS records")
+		return result, nil
+	}
+
+	service, err := c.clientset.CoreV1().Services(namespace).Get(ctx, sts.Spec.ServiceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		result["service"] = map[string]interface{}{"exists": false}
+		problems = append(problems, fmt.Sprintf("headless service %s named by spec.serviceName does not exist", sts.Spec.ServiceName))
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service %s/%s: %w", namespace, sts.Spec.ServiceName, err)
+	}
+	headless := service.Spec.ClusterIP == corev1.ClusterIPNone
+	selects := len(service.Spec.Selector) > 0 &&
+		labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(sts.Spec.Template.Labels))
+	result["service"] = map[string]interface{}{
+		"exists":                   true,
+		"headless":                 headless,
+		"clusterIP":                service.Spec.ClusterIP,
+		"selectsPods":              selects,
+		"publishNotReadyAddresses": service.Spec.PublishNotReadyAddresses,
+	}
+	if !headless {
+		problems = append(problems, fmt.Sprintf("service %s is not headless (clusterIP %s); set clusterIP: None to get per-pod DNS records", service.Name, service.Spec.ClusterIP))
+	}
+	switch {
+	case len(service.Spec.Selector) == 0:
+		warnings = append(warnings, fmt.Sprintf("service %s has no selector; its endpoints are managed outside Kubernetes", service.Name))
+	case !selects:
+		problems = append(problems, fmt.Sprintf("service %s selector %s does not match the pod template labels", service.Name, labels.SelectorFromSet(service.Spec.Selector)))
+	}
+
+	// The cluster DNS publishes a per-pod record for each endpoint with a
+	// hostname that is ready, which publishNotReadyAddresses makes all of them
+	endpoints := map[string]discoveryv1.Endpoint{}
+	slices, err := c.clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + service.Name,
+	})
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("failed to list the endpointslices of service %s: %v", service.Name, err))
+	} else {
+		for _, slice := range slices.Items {
+			for _, endpoint := range slice.Endpoints {
+				if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+					endpoints[endpoint.TargetRef.Name] = endpoint
+				}
+			}
+		}
+	}
+
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	start := int32(0)
+	if sts.Spec.Ordinals != nil {
+		start = sts.Spec.Ordinals.Start
+	}
+	resolve := c.inCluster
+	notReady := 0
+	pods := []map[string]interface{}{}
+	for ordinal := start; ordinal < start+replicas; ordinal++ {
+		podName := fmt.Sprintf("%s-%d", name, ordinal)
+		record := fmt.Sprintf("%s.%s.%s.svc.%s", podName, service.Name, namespace, clusterDomain)
+		entry := map[string]interface{}{"name": podName, "ordinal": ordinal, "dnsName": record}
+		podProblems := []string{}
+
+		pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			entry["exists"] = false
+			podProblems = append(podProblems, "pod does not exist")
+		case err != nil:
+			podProblems = append(podProblems, fmt.Sprintf("failed to get pod: %v", err))
+		default:
+			ready := isPodReady(pod)
+			entry["exists"] = true
+			entry["ready"] = ready
+			entry["hostname"] = pod.Spec.Hostname
+			entry["subdomain"] = pod.Spec.Subdomain
+			if !ready {
+				notReady++
+			}
+			if pod.Spec.Hostname != podName {
+				podProblems = append(podProblems, fmt.Sprintf("spec.hostname is %q, not %q", pod.Spec.Hostname, podName))
+			}
+			if pod.Spec.Subdomain != service.Name {
+				podProblems = append(podProblems, fmt.Sprintf("spec.subdomain is %q, not the service %q", pod.Spec.Subdomain, service.Name))
+			}
+		}
+
+		endpoint, found := endpoints[podName]
+		published := found && endpoint.Hostname != nil && *endpoint.Hostname == podName &&
+			(endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready)
+		entry["published"] = published
+		switch {
+		case published:
+		case !found:
+			podProblems = append(podProblems, "pod is not an endpoint of the service, so no DNS record is published")
+		case endpoint.Hostname == nil || *endpoint.Hostname != podName:
+			podProblems = append(podProblems, "endpoint has no hostname, so no DNS record is published")
+		default:
+			podProblems = append(podProblems, "endpoint is not ready and the service does not set publishNotReadyAddresses, so no DNS record is published")
+		}
+
+		if resolve {
+			lookupCtx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+			addresses, err := net.DefaultResolver.LookupHost(lookupCtx, record)
+			cancel()
+			if err != nil {
+				entry["resolveError"] = err.Error()
+				if published {
+					podProblems = append(podProblems, fmt.Sprintf("record does not resolve: %v", err))
+				}
+			} else {
+				entry["addresses"] = addresses
+			}
+		}
+		entry["problems"] = podProblems
+		if len(podProblems) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: %s", podName, podProblems[0]))
+		}
+		pods = append(pods, entry)
+	}
+	result["pods"] = pods
+	result["resolved"] = resolve
+	if !resolve {
+		warnings = append(warnings, "records were not resolved because the server runs outside the cluster; publication was checked from the endpointslices")
+	}
+
+	if !service.Spec.PublishNotReadyAddresses && notReady > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d pods are not ready and have no DNS record; stores that must reach their peers before becoming ready need publishNotReadyAddresses: true", notReady))
+	}
+	return result, nil
+}
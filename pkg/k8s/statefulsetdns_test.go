@@ -0,0 +1,67 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestCheckStatefulSetDNS tests checking the headless Service and per-pod DNS records of a StatefulSet
+func TestCheckStatefulSetDNS(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		pod := func(name, ready string) string {
+			return `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"` + name + `","namespace":"data"},` +
+				`"spec":{"hostname":"` + name + `","subdomain":"etcd","containers":[{"name":"etcd","image":"etcd"}]},` +
+				`"status":{"conditions":[{"type":"Ready","status":"` + ready + `"}]}}`
+		}
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/data/statefulsets/etcd":
+			w.Write([]byte(`{"kind":"StatefulSet","apiVersion":"apps/v1","metadata":{"name":"etcd","namespace":"data"},` +
+				`"spec":{"serviceName":"etcd","replicas":3,"selector":{"matchLabels":{"app":"etcd"}},"template":{"metadata":{"labels":{"app":"etcd"}}}}}`))
+		case "/api/v1/namespaces/data/services/etcd":
+			w.Write([]byte(`{"kind":"Service","apiVersion":"v1","metadata":{"name":"etcd","namespace":"data"},"spec":{"clusterIP":"None","selector":{"app":"etcd"}}}`))
+		case "/apis/discovery.k8s.io/v1/namespaces/data/endpointslices":
+			if r.URL.Query().Get("labelSelector") != "kubernetes.io/service-name=etcd" {
+				t.Errorf("Unexpected endpointslice selector %q", r.URL.Query().Get("labelSelector"))
+			}
+			w.Write([]byte(`{"kind":"EndpointSliceList","apiVersion":"discovery.k8s.io/v1","items":[{"metadata":{"name":"etcd-abc","namespace":"data"},"addressType":"IPv4","endpoints":[` +
+				`{"addresses":["10.0.0.1"],"hostname":"etcd-0","conditions":{"ready":true},"targetRef":{"kind":"Pod","name":"etcd-0"}},` +
+				`{"addresses":["10.0.0.2"],"hostname":"etcd-1","conditions":{"ready":false},"targetRef":{"kind":"Pod","name":"etcd-1"}}]}]}`))
+		case "/api/v1/namespaces/data/pods/etcd-0":
+			w.Write([]byte(pod("etcd-0", "True")))
+		case "/api/v1/namespaces/data/pods/etcd-1":
+			w.Write([]byte(pod("etcd-1", "False")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.CheckStatefulSetDNS(context.Background(), "data", "etcd", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service := result["service"].(map[string]interface{}); service["headless"] != true || service["selectsPods"] != true {
+		t.Errorf("Expected a headless service selecting the pods, got %v", service)
+	}
+	pods := result["pods"].([]map[string]interface{})
+	if len(pods) != 3 {
+		t.Fatalf("Expected 3 pods, got %v", pods)
+	}
+	if pods[0]["published"] != true || pods[0]["dnsName"] != "etcd-0.etcd.data.svc.cluster.local" || len(pods[0]["problems"].([]string)) != 0 {
+		t.Errorf("Expected etcd-0 to be published, got %v", pods[0])
+	}
+	if problems := pods[1]["problems"].([]string); pods[1]["published"] != false || len(problems) != 1 || !strings.Contains(problems[0], "publishNotReadyAddresses") {
+		t.Errorf("Expected the not ready etcd-1 to be unpublished, got %v", pods[1])
+	}
+	if pods[2]["exists"] != false {
+		t.Errorf("Expected etcd-2 to be missing, got %v", pods[2])
+	}
+	if problems := result["problems"].([]string); len(problems) != 2 {
+		t.Errorf("Expected problems for etcd-1 and etcd-2, got %v", problems)
+	}
+	if warnings := result["warnings"].([]string); len(warnings) != 2 || !strings.Contains(warnings[1], "1 pods are not ready") {
+		t.Errorf("Expected warnings about resolution and not ready pods, got %v", warnings)
+	}
+}
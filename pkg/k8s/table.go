@@ -0,0 +1,202 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// tableAcceptHeader requests the server-side Table rendering of a list, as
+// kubectl get does.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json;as=Table;v=v1beta1;g=meta.k8s.io"
+
+// ListResourcesTable lists the instances of a resource type in the API
+// server's Table representation, which carries the columns kubectl get prints,
+// including the additionalPrinterColumns of custom resources. It supports the
+// same namespace, labelSelector and fieldSelector filtering as ListResources.
+// Returns a map with the "columns" of the table and its "rows", each keyed by
+// column name, or an error.
+func (c *Client) ListResourcesTable(ctx context.Context, kind, namespace, labelSelector, fieldSelector string) (map[string]interface{}, error) {
+	info, err := c.getCachedResource(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	request := c.discoveryClient.RESTClient().Get().
+		AbsPath(resourcePath(info, namespace)).
+		Param("includeObject", string(metav1.IncludeMetadata)).
+		SetHeader("Accept", tableAcceptHeader)
+	if labelSelector != "" {
+		request = request.Param("labelSelector", labelSelector)
+	}
+	if fieldSelector != "" {
+		request = request.Param("fieldSelector", fieldSelector)
+	}
+	raw, err := request.DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources as table: %w", err)
+	}
+
+	var table metav1.Table
+	if err := json.Unmarshal(raw, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse table: %w", err)
+	}
+	if table.Kind != "Table" {
+		return nil, fmt.Errorf("the API server did not return a Table for %s", kind)
+	}
+	return summarizeTable(&table, info.namespaced && namespace == ""), nil
+}
+
+// resourcePath returns the API path listing a resource, scoped to namespace
+// for namespaced resources when it is set.
+func resourcePath(info *resourceInfo, namespace string) string {
+	base := path.Join("/apis", info.gvr.Group, info.gvr.Version)
+	if info.gvr.Group == "" {
+		base = path.Join("/api", info.gvr.Version)
+	}
+	if info.namespaced && namespace != "" {
+		base = path.Join(base, "namespaces", namespace)
+	}
+	return path.Join(base, info.gvr.Resource)
+}
+
+// summarizeTable converts a Table into its column definitions and rows keyed
+// by column name. When withNamespace is set, each row also reports the
+// namespace of its object, as kubectl get --all-namespaces does.
+func summarizeTable(table *metav1.Table, withNamespace bool) map[string]interface{} {
+	columns := make([]map[string]interface{}, 0, len(table.ColumnDefinitions))
+	for _, column := range table.ColumnDefinitions {
+		definition := map[string]interface{}{"name": column.Name, "type": column.Type}
+		if column.Format != "" {
+			definition["format"] = column.Format
+		}
+		if column.Priority > 0 {
+			// kubectl only prints these columns with -o wide
+			definition["priority"] = column.Priority
+		}
+		columns = append(columns, definition)
+	}
+
+	rows := make([]map[string]interface{}, 0, len(table.Rows))
+	for _, tableRow := range table.Rows {
+		row := map[string]interface{}{}
+		if withNamespace && len(tableRow.Object.Raw) > 0 {
+			var object metav1.PartialObjectMetadata
+			if err := json.Unmarshal(tableRow.Object.Raw, &object); err == nil && object.Namespace != "" {
+				row["namespace"] = object.Namespace
+			}
+		}
+		for i, cell := range tableRow.Cells {
+			if i < len(table.ColumnDefinitions) {
+				row[table.ColumnDefinitions[i].Name] = cell
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	result := map[string]interface{}{
+		"columns": columns,
+		"rows":    rows,
+	}
+	if table.ResourceVersion != "" {
+		result["resourceVersion"] = table.ResourceVersion
+	}
+	return result
+}
+
+// SynthesizeTable builds a table of the given objects in the shape
+// ListResourcesTable returns, for when the API server cannot render one: the
+// Name, Ready, Status and Age columns kubectl get prints for most kinds. Ready
+// counts the ready containers of pods and the ready replicas of workloads,
+// and is the status of the Ready condition of other objects; Status is the
+// status kubectl prints for pods, and the phase or readiness of other
+// objects. When withNamespace is set, each row also reports the namespace of
+// its object.
+func SynthesizeTable(resources []map[string]interface{}, withNamespace bool) map[string]interface{} {
+	columns := []map[string]interface{}{
+		{"name": "Name", "type": "string", "format": "name"},
+		{"name": "Ready", "type": "string"},
+		{"name": "Status", "type": "string"},
+		{"name": "Age", "type": "string"},
+	}
+	now := time.Now()
+	rows := make([]map[string]interface{}, 0, len(resources))
+	for _, resource := range resources {
+		obj := &unstructured.Unstructured{Object: resource}
+		ready, status := objectReadiness(obj)
+		row := map[string]interface{}{
+			"Name":   obj.GetName(),
+			"Ready":  ready,
+			"Status": status,
+			"Age":    "<unknown>",
+		}
+		if created := obj.GetCreationTimestamp(); !created.IsZero() {
+			row["Age"] = duration.HumanDuration(now.Sub(created.Time))
+		}
+		if withNamespace && obj.GetNamespace() != "" {
+			row["namespace"] = obj.GetNamespace()
+		}
+		rows = append(rows, row)
+	}
+	return map[string]interface{}{"columns": columns, "rows": rows}
+}
+
+// objectReadiness returns the Ready and Status columns SynthesizeTable prints
+// for an object.
+func objectReadiness(obj *unstructured.Unstructured) (string, string) {
+	if obj.GetKind() == "Pod" {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err == nil {
+			ready := 0
+			for _, container := range pod.Status.ContainerStatuses {
+				if container.Ready {
+					ready++
+				}
+			}
+			return fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)), podStatus(&pod)
+		}
+	}
+
+	ready := ""
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet", "ReplicationController":
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+		readyReplicas, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		ready = fmt.Sprintf("%d/%d", readyReplicas, replicas)
+	case "DaemonSet":
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		readyPods, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+		ready = fmt.Sprintf("%d/%d", readyPods, desired)
+	}
+
+	status, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		conditionStatus, _ := condition["status"].(string)
+		if ready == "" {
+			ready = conditionStatus
+		}
+		if status == "" {
+			status = "NotReady"
+			if conditionStatus == string(metav1.ConditionTrue) {
+				status = "Ready"
+			}
+		}
+	}
+	return ready, status
+}
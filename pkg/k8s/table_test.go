@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestSummarizeTable tests converting a server-side Table into columns and keyed rows
+func TestSummarizeTable(t *testing.T) {
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Ready", Type: "string"},
+			{Name: "Node", Type: "string", Priority: 1},
+		},
+		Rows: []metav1.TableRow{{
+			Cells:  []interface{}{"web-1", "1/1", "node-a"},
+			Object: runtime.RawExtension{Raw: []byte(`{"kind":"PartialObjectMetadata","metadata":{"name":"web-1","namespace":"prod"}}`)},
+		}},
+	}
+	table.ResourceVersion = "42"
+
+	result := summarizeTable(table, true)
+	columns := result["columns"].([]map[string]interface{})
+	if len(columns) != 3 || columns[0]["format"] != "name" || columns[2]["priority"] != int32(1) {
+		t.Errorf("Unexpected columns %v", columns)
+	}
+	row := result["rows"].([]map[string]interface{})[0]
+	if row["Name"] != "web-1" || row["Ready"] != "1/1" || row["namespace"] != "prod" {
+		t.Errorf("Unexpected row %v", row)
+	}
+	if result["resourceVersion"] != "42" {
+		t.Errorf("Expected the resourceVersion, got %v", result["resourceVersion"])
+	}
+
+	if _, ok := summarizeTable(table, false)["rows"].([]map[string]interface{})[0]["namespace"]; ok {
+		t.Error("Expected no namespace when listing a single namespace")
+	}
+}
+
+// TestResourcePath tests building list paths for core, grouped and cluster-scoped resources
+func TestResourcePath(t *testing.T) {
+	tests := []struct {
+		info      resourceInfo
+		namespace string
+		want      string
+	}{
+		{resourceInfo{gvr: schema.GroupVersionResource{Version: "v1", Resource: "pods"}, namespaced: true}, "prod", "/api/v1/namespaces/prod/pods"},
+		{resourceInfo{gvr: schema.GroupVersionResource{Version: "v1", Resource: "pods"}, namespaced: true}, "", "/api/v1/pods"},
+		{resourceInfo{gvr: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}, namespaced: true}, "prod", "/apis/cert-manager.io/v1/namespaces/prod/certificates"},
+		{resourceInfo{gvr: schema.GroupVersionResource{Version: "v1", Resource: "nodes"}}, "prod", "/api/v1/nodes"},
+	}
+	for _, tt := range tests {
+		if got := resourcePath(&tt.info, tt.namespace); got != tt.want {
+			t.Errorf("resourcePath(%v, %q) = %s, want %s", tt.info.gvr, tt.namespace, got, tt.want)
+		}
+	}
+}
+
+// TestSynthesizeTable tests building kubectl's common columns for kinds the API server cannot print
+func TestSynthesizeTable(t *testing.T) {
+	resources := []map[string]interface{}{
+		{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{"name": "web-1", "namespace": "prod", "creationTimestamp": "2024-05-01T10:00:00Z"},
+			"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "app"}, map[string]interface{}{"name": "proxy"}}},
+			"status": map[string]interface{}{"phase": "Running", "containerStatuses": []interface{}{
+				map[string]interface{}{"name": "app", "ready": true, "state": map[string]interface{}{"running": map[string]interface{}{}}},
+				map[string]interface{}{"name": "proxy", "state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}},
+			}}},
+		{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+			"spec": map[string]interface{}{"replicas": int64(3)}, "status": map[string]interface{}{"readyReplicas": int64(2)}},
+		{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": map[string]interface{}{"name": "w"},
+			"status": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}}}},
+	}
+
+	result := SynthesizeTable(resources, true)
+	if columns := result["columns"].([]map[string]interface{}); len(columns) != 4 || columns[3]["name"] != "Age" {
+		t.Errorf("Unexpected columns %v", columns)
+	}
+	rows := result["rows"].([]map[string]interface{})
+	if rows[0]["Ready"] != "1/2" || rows[0]["Status"] != "CrashLoopBackOff" || rows[0]["namespace"] != "prod" || rows[0]["Age"] == "<unknown>" {
+		t.Errorf("Unexpected pod row %v", rows[0])
+	}
+	if rows[1]["Ready"] != "2/3" || rows[1]["Status"] != "" {
+		t.Errorf("Unexpected deployment row %v", rows[1])
+	}
+	if rows[2]["Ready"] != "True" || rows[2]["Status"] != "Ready" || rows[2]["Age"] != "<unknown>" {
+		t.Errorf("Unexpected custom resource row %v", rows[2])
+	}
+	if _, ok := rows[2]["namespace"]; ok {
+		t.Errorf("Expected no namespace for a cluster-scoped object, got %v", rows[2])
+	}
+}
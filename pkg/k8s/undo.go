@@ -0,0 +1,328 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// maxUndoEntries bounds the number of changes remembered per session; the
+	// oldest entries are dropped first.
+	maxUndoEntries = 50
+	// undoBucket is the bucket of the state store holding the undo logs,
+	// keyed by session.
+	undoBucket = "undo"
+)
+
+// StateStore persists state across restarts as JSON values under string
+// keys in named buckets.
+type StateStore interface {
+	Put(bucket, key string, value interface{}) error
+	Delete(bucket, key string) error
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+}
+
+// sessionIDKey is the context key for the MCP session a request belongs to.
+type sessionIDKey struct{}
+
+// WithSessionID returns a context that attributes the changes made with it to
+// the given session, so that each session has its own undo log.
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, sessionID)
+}
+
+// sessionID returns the session of ctx, or an empty string for requests made
+// outside of a session.
+func sessionID(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	return id
+}
+
+// callerKey is the context key for the authenticated client a request is
+// made on behalf of.
+type callerKey struct{}
+
+// WithCaller returns a context that attributes the changes made with it to
+// the named authenticated client as well as to its session. Session IDs are
+// chosen by clients in stateless transports, so the undo log is kept per
+// caller and session to keep clients from undoing each other's changes.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// undoKey returns the key of the undo log of ctx: its session, qualified by
+// its authenticated caller if any, or an empty string for requests made
+// outside of a session on behalf of no caller.
+func undoKey(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	if caller == "" {
+		return sessionID(ctx)
+	}
+	return caller + "/" + sessionID(ctx)
+}
+
+// undoEntry records the state of an object before the server mutated it.
+type undoEntry struct {
+	operation  string
+	kind       string
+	name       string
+	namespace  string
+	resource   dynamic.ResourceInterface
+	previous   *unstructured.Unstructured // nil if the operation created the object
+	recordedAt time.Time
+}
+
+// persistedUndoEntry is an undo entry as it is persisted. The resource is
+// resolved again from the kind when the entry is restored.
+type persistedUndoEntry struct {
+	Operation  string                 `json:"operation"`
+	Kind       string                 `json:"kind"`
+	Name       string                 `json:"name"`
+	Namespace  string                 `json:"namespace,omitempty"`
+	Previous   map[string]interface{} `json:"previous,omitempty"`
+	RecordedAt time.Time              `json:"recordedAt"`
+}
+
+// undoLog holds the undo entries of each session, most recent last, and
+// persists them to store if it is set.
+type undoLog struct {
+	mu       sync.Mutex
+	sessions map[string][]undoEntry
+	store    StateStore
+}
+
+// persist writes the session's entries to the store, if any. Failures are
+// reported but do not fail the change being recorded or undone. The caller
+// must hold l.mu.
+func (l *undoLog) persist(session string) {
+	if l.store == nil {
+		return
+	}
+	entries := l.sessions[session]
+	var err error
+	if len(entries) == 0 {
+		err = l.store.Delete(undoBucket, session)
+	} else {
+		persisted := make([]persistedUndoEntry, 0, len(entries))
+		for _, entry := range entries {
+			p := persistedUndoEntry{
+				Operation:  entry.operation,
+				Kind:       entry.kind,
+				Name:       entry.name,
+				Namespace:  entry.namespace,
+				RecordedAt: entry.recordedAt,
+			}
+			if entry.previous != nil {
+				p.Previous = entry.previous.Object
+			}
+			persisted = append(persisted, p)
+		}
+		err = l.store.Put(undoBucket, session, persisted)
+	}
+	if err != nil {
+		fmt.Printf("Failed to persist the undo log of session %s: %v\n", session, err)
+	}
+}
+
+// push appends an entry to the session's log, dropping the oldest entry once
+// the log is full.
+func (l *undoLog) push(session string, entry undoEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sessions == nil {
+		l.sessions = map[string][]undoEntry{}
+	}
+	entries := append(l.sessions[session], entry)
+	if len(entries) > maxUndoEntries {
+		entries = entries[len(entries)-maxUndoEntries:]
+	}
+	l.sessions[session] = entries
+	l.persist(session)
+}
+
+// pop removes and returns the session's most recent entry.
+func (l *undoLog) pop(session string) (undoEntry, int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := l.sessions[session]
+	if len(entries) == 0 {
+		return undoEntry{}, 0, false
+	}
+	entry := entries[len(entries)-1]
+	l.sessions[session] = entries[:len(entries)-1]
+	l.persist(session)
+	return entry, len(entries) - 1, true
+}
+
+// PersistUndo keeps the undo logs of the client's sessions in store and
+// restores the logs a previous run of the server persisted there, so changes
+// can still be undone after a restart by sessions that keep their ID, such
+// as stdio clients or streamable-http clients that reconnect with their
+// session ID. Copies of the client, e.g. impersonating ones, keep their undo
+// logs in memory.
+// Returns the number of changes restored, or an error.
+func (c *Client) PersistUndo(store StateStore) (int, error) {
+	sessions := map[string][]undoEntry{}
+	restored := 0
+	err := store.ForEach(undoBucket, func(session string, value []byte) error {
+		var persisted []persistedUndoEntry
+		if err := json.Unmarshal(value, &persisted); err != nil {
+			return fmt.Errorf("failed to parse the undo log of session %s: %w", session, err)
+		}
+		for _, p := range persisted {
+			entry := undoEntry{
+				operation:  p.Operation,
+				kind:       p.Kind,
+				name:       p.Name,
+				namespace:  p.Namespace,
+				recordedAt: p.RecordedAt,
+			}
+			if p.Previous != nil {
+				entry.previous = &unstructured.Unstructured{Object: p.Previous}
+			}
+			sessions[session] = append(sessions[session], entry)
+		}
+		restored += len(persisted)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	c.undo.mu.Lock()
+	defer c.undo.mu.Unlock()
+	c.undo.sessions = sessions
+	c.undo.store = store
+	return restored, nil
+}
+
+// recordUndo reads the current state of an object before it is mutated and
+// returns a function recording it in the session's undo log, which callers
+// call once the mutation succeeded, so that failed mutations leave nothing to
+// undo. Dry runs and changes made outside of a session or on behalf of no
+// caller are not recorded. If the object does not exist yet, undoing the
+// change deletes it. The object is not recorded if it cannot be read, as the
+// mutation itself is then likely to fail as well.
+func (c *Client) recordUndo(ctx context.Context, operation string, resource dynamic.ResourceInterface, kind, name, namespace string) func() {
+	key := undoKey(ctx)
+	if IsDryRun(ctx) || key == "" {
+		return func() {}
+	}
+	entry := undoEntry{
+		operation: operation,
+		kind:      kind,
+		name:      name,
+		namespace: namespace,
+		resource:  resource,
+	}
+	current, err := resource.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return func() {}
+	default:
+		entry.previous = current
+	}
+	return func() {
+		entry.recordedAt = time.Now()
+		c.undo.push(key, entry)
+	}
+}
+
+// UndoLastChange reverts the most recent change made by the server in the
+// current session. An object that existed before the change is restored to
+// its recorded state, recreating it if it was deleted; an object created by
+// the change is deleted. If authorize is set, it is called with the
+// namespace of the change before it is reverted; a change it refuses stays
+// in the log.
+// Returns a summary of the restored change and the number of changes left to
+// undo, or an error.
+func (c *Client) UndoLastChange(ctx context.Context, authorize func(namespace string) error) (map[string]interface{}, error) {
+	key := undoKey(ctx)
+	if key == "" {
+		return nil, fmt.Errorf("changes can only be undone within an MCP session or by an authenticated client")
+	}
+	entry, remaining, ok := c.undo.pop(key)
+	if !ok {
+		return nil, fmt.Errorf("no changes to undo in this session")
+	}
+	if authorize != nil {
+		if err := authorize(entry.namespace); err != nil {
+			c.undo.push(key, entry)
+			return nil, err
+		}
+	}
+
+	result := map[string]interface{}{
+		"operation":  entry.operation,
+		"kind":       entry.kind,
+		"name":       entry.name,
+		"namespace":  entry.namespace,
+		"recordedAt": entry.recordedAt,
+		"remaining":  remaining,
+	}
+
+	if entry.resource == nil {
+		// Entries restored from the state store are resolved on first use
+		resource, err := c.resourceClient(entry.kind, entry.namespace, false)
+		if err != nil {
+			c.undo.push(key, entry)
+			return nil, err
+		}
+		entry.resource = resource
+	}
+	action, err := restoreUndoEntry(ctx, entry)
+	if err != nil {
+		// Keep the entry so that the undo can be retried
+		c.undo.push(key, entry)
+		return nil, err
+	}
+	result["action"] = action
+	return result, nil
+}
+
+// restoreUndoEntry reverts a single recorded change. Returns the action taken:
+// "deleted", "recreated" or "restored", or an error.
+func restoreUndoEntry(ctx context.Context, entry undoEntry) (string, error) {
+	if entry.previous == nil {
+		err := entry.resource.Delete(ctx, entry.name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to delete %s %s created by %s: %w", entry.kind, entry.name, entry.operation, err)
+		}
+		return "deleted", nil
+	}
+
+	restored := entry.previous.DeepCopy()
+	unstructured.RemoveNestedField(restored.Object, "status")
+	restored.SetManagedFields(nil)
+
+	current, err := entry.resource.Get(ctx, entry.name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		restored.SetResourceVersion("")
+		restored.SetUID("")
+		restored.SetCreationTimestamp(metav1.Time{})
+		restored.SetGeneration(0)
+		if _, err := entry.resource.Create(ctx, restored, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to recreate %s %s: %w", entry.kind, entry.name, err)
+		}
+		return "recreated", nil
+	case err != nil:
+		return "", fmt.Errorf("failed to get %s %s: %w", entry.kind, entry.name, err)
+	}
+
+	// Replace the object with its recorded state, on top of the current version
+	restored.SetResourceVersion(current.GetResourceVersion())
+	if _, err := entry.resource.Update(ctx, restored, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to restore %s %s: %w", entry.kind, entry.name, err)
+	}
+	return "restored", nil
+}
@@ -0,0 +1,158 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestUndoLog tests that undo entries are kept per session, popped in reverse order and bounded
+func TestUndoLog(t *testing.T) {
+	var log undoLog
+	if _, _, ok := log.pop("a"); ok {
+		t.Fatal("Expected an empty log to have nothing to pop")
+	}
+
+	log.push("a", undoEntry{name: "first"})
+	log.push("a", undoEntry{name: "second"})
+	log.push("b", undoEntry{name: "other"})
+
+	entry, remaining, ok := log.pop("a")
+	if !ok || entry.name != "second" || remaining != 1 {
+		t.Errorf("Expected to pop second with 1 remaining, got %q with %d remaining", entry.name, remaining)
+	}
+	if entry, _, _ := log.pop("b"); entry.name != "other" {
+		t.Errorf("Expected session b to hold its own entry, got %q", entry.name)
+	}
+
+	for i := 0; i < maxUndoEntries+5; i++ {
+		log.push("c", undoEntry{name: "entry"})
+	}
+	if got := len(log.sessions["c"]); got != maxUndoEntries {
+		t.Errorf("Expected the log to be bounded to %d entries, got %d", maxUndoEntries, got)
+	}
+}
+
+// memoryStore is a StateStore keeping JSON values in memory.
+type memoryStore map[string]map[string][]byte
+
+func (m memoryStore) Put(bucket, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if m[bucket] == nil {
+		m[bucket] = map[string][]byte{}
+	}
+	m[bucket][key] = data
+	return nil
+}
+
+func (m memoryStore) Delete(bucket, key string) error {
+	delete(m[bucket], key)
+	return nil
+}
+
+func (m memoryStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	var keys []string
+	for key := range m[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn(key, m[bucket][key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestPersistUndo tests that undo logs persisted by one client are restored by the next
+func TestPersistUndo(t *testing.T) {
+	store := memoryStore{}
+	first := &Client{}
+	if _, err := first.PersistUndo(store); err != nil {
+		t.Fatal(err)
+	}
+	first.undo.push("stdio", undoEntry{operation: "create", kind: "ConfigMap", name: "created", namespace: "default"})
+	first.undo.push("stdio", undoEntry{operation: "delete", kind: "ConfigMap", name: "deleted", namespace: "default"})
+	first.undo.pop("stdio")
+
+	second := &Client{}
+	restored, err := second.PersistUndo(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored != 1 {
+		t.Fatalf("Expected 1 restored change, got %d", restored)
+	}
+	entry, remaining, ok := second.undo.pop("stdio")
+	if !ok || entry.name != "created" || entry.kind != "ConfigMap" || remaining != 0 {
+		t.Errorf("Expected to restore the creation of created, got %+v", entry)
+	}
+	if len(store[undoBucket]) != 0 {
+		t.Errorf("Expected the emptied log to be deleted from the store, got %v", store[undoBucket])
+	}
+}
+
+// TestRecordUndo tests that only successful mutations made in a session are recorded, per caller
+func TestRecordUndo(t *testing.T) {
+	failPatch := true
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case r.URL.Path == "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}],"preferredVersion":{"groupVersion":"apps/v1","version":"v1"}}]}`))
+		case r.URL.Path == "/apis/apps/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[{"name":"deployments","namespaced":true,"kind":"Deployment","verbs":["get","patch"]},{"name":"deployments/scale","namespaced":true,"kind":"Scale","verbs":["get","patch"]}]}`))
+		case r.Method == http.MethodPatch && failPatch:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Conflict","code":409}`))
+		case strings.HasSuffix(r.URL.Path, "/scale"):
+			w.Write([]byte(`{"apiVersion":"autoscaling/v1","kind":"Scale","metadata":{"name":"api","namespace":"web"},"spec":{"replicas":2}}`))
+		case r.URL.Path == "/apis/apps/v1/namespaces/web/deployments/api":
+			w.Write([]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api","namespace":"web","resourceVersion":"1"},"spec":{"replicas":2}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	session := WithSessionID(context.Background(), "")
+	alice, bob := WithCaller(session, "alice"), WithCaller(session, "bob")
+
+	if _, err := client.ScaleResource(alice, "web", "Deployment", "api", 3); err == nil {
+		t.Fatal("Expected the scale to fail")
+	}
+	if len(client.undo.sessions["alice/"]) != 0 {
+		t.Errorf("Expected a failed mutation not to be recorded, got %v", client.undo.sessions)
+	}
+
+	failPatch = false
+	for _, ctx := range []context.Context{context.Background(), alice} {
+		if _, err := client.ScaleResource(ctx, "web", "Deployment", "api", 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(client.undo.sessions) != 1 || len(client.undo.sessions["alice/"]) != 1 {
+		t.Errorf("Expected only alice's change to be recorded, got %v", client.undo.sessions)
+	}
+	if _, err := client.UndoLastChange(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "MCP session") {
+		t.Errorf("Expected undo outside of a session to be refused, got %v", err)
+	}
+	if _, err := client.UndoLastChange(bob, nil); err == nil {
+		t.Error("Expected bob to have no changes to undo")
+	}
+
+	refuse := func(namespace string) error { return fmt.Errorf("may not write in namespace %s", namespace) }
+	if _, err := client.UndoLastChange(alice, refuse); err == nil || !strings.Contains(err.Error(), "namespace web") {
+		t.Errorf("Expected the undo to be refused, got %v", err)
+	}
+	if len(client.undo.sessions["alice/"]) != 1 {
+		t.Errorf("Expected a refused undo to keep its entry, got %v", client.undo.sessions)
+	}
+}
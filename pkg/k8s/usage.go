@@ -0,0 +1,321 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// usagePoint is the CPU and memory usage of a pod or node at one sample.
+type usagePoint struct {
+	CPUMillicores int64 `json:"cpuMillicores"`
+	MemoryBytes   int64 `json:"memoryBytes"`
+}
+
+// usageSample holds the usage of every node and pod at one point in time.
+// Pods are keyed by namespace/name.
+type usageSample struct {
+	time  time.Time
+	nodes map[string]usagePoint
+	pods  map[string]usagePoint
+}
+
+// usageRing is a fixed-capacity ring buffer of samples, oldest first.
+type usageRing struct {
+	samples []usageSample
+	next    int
+	full    bool
+}
+
+// push adds a sample, overwriting the oldest one once the ring is full.
+// Returns the overwritten sample, if any.
+func (r *usageRing) push(sample usageSample) (usageSample, bool) {
+	overwritten, ok := r.samples[r.next], r.full
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+	return overwritten, ok
+}
+
+// since returns the samples taken at or after t, oldest first.
+func (r *usageRing) since(t time.Time) []usageSample {
+	var ordered []usageSample
+	if r.full {
+		ordered = append(ordered, r.samples[r.next:]...)
+	}
+	ordered = append(ordered, r.samples[:r.next]...)
+	for i, sample := range ordered {
+		if !sample.time.Before(t) {
+			return ordered[i:]
+		}
+	}
+	return nil
+}
+
+// UsageSampler periodically samples pod and node usage from the metrics API
+// into an in-memory ring buffer, giving a short usage history without an
+// external time series database.
+type UsageSampler struct {
+	client    *Client
+	interval  time.Duration
+	retention time.Duration
+
+	mu        sync.RWMutex
+	ring      usageRing
+	lastError error
+	store     StateStore
+}
+
+// usageBucket is the bucket of the state store holding the usage samples,
+// keyed by the zero-padded Unix time in nanoseconds so they sort by time.
+const usageBucket = "usage"
+
+// persistedUsageSample is a usage sample as it is persisted.
+type persistedUsageSample struct {
+	Time  time.Time             `json:"time"`
+	Nodes map[string]usagePoint `json:"nodes"`
+	Pods  map[string]usagePoint `json:"pods"`
+}
+
+// usageKey returns the state store key of a sample taken at t.
+func usageKey(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+// Persist keeps the samples in store and restores the samples of the
+// retention period persisted there by a previous run of the server, so the
+// usage history survives restarts. Older samples are deleted from the store.
+// Returns the number of samples restored, or an error.
+func (s *UsageSampler) Persist(store StateStore) (int, error) {
+	cutoff := time.Now().Add(-s.retention)
+	var samples []usageSample
+	var stale []string
+	err := store.ForEach(usageBucket, func(key string, value []byte) error {
+		var persisted persistedUsageSample
+		if err := json.Unmarshal(value, &persisted); err != nil {
+			return fmt.Errorf("failed to parse usage sample %s: %w", key, err)
+		}
+		if persisted.Time.Before(cutoff) {
+			stale = append(stale, key)
+			return nil
+		}
+		samples = append(samples, usageSample{time: persisted.Time, nodes: persisted.Nodes, pods: persisted.Pods})
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	// Keys sort by time, so the samples beyond the capacity are the oldest
+	if excess := len(samples) - len(s.ring.samples); excess > 0 {
+		for _, sample := range samples[:excess] {
+			stale = append(stale, usageKey(sample.time))
+		}
+		samples = samples[excess:]
+	}
+	for _, key := range stale {
+		if err := store.Delete(usageBucket, key); err != nil {
+			return 0, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sample := range samples {
+		s.ring.push(sample)
+	}
+	s.store = store
+	return len(samples), nil
+}
+
+// NewUsageSampler creates a sampler that samples every interval and keeps the
+// samples of the last retention period. Sampling starts with Start.
+func NewUsageSampler(client *Client, interval, retention time.Duration) *UsageSampler {
+	capacity := int(retention / interval)
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &UsageSampler{
+		client:    client,
+		interval:  interval,
+		retention: retention,
+		ring:      usageRing{samples: make([]usageSample, capacity)},
+	}
+}
+
+// Start samples usage in the background until ctx is done.
+func (s *UsageSampler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.sample(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sample records the current usage of all nodes and pods. Failures are kept
+// so that queries can report why no history is available.
+func (s *UsageSampler) sample(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+	metrics := s.client.metricsClientset.MetricsV1beta1()
+
+	sample := usageSample{time: time.Now(), nodes: map[string]usagePoint{}, pods: map[string]usagePoint{}}
+	err := func() error {
+		nodeMetrics, err := metrics.NodeMetricses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, node := range nodeMetrics.Items {
+			sample.nodes[node.Name] = usagePoint{CPUMillicores: node.Usage.Cpu().MilliValue(), MemoryBytes: node.Usage.Memory().Value()}
+		}
+		podMetrics, err := metrics.PodMetricses("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, pod := range podMetrics.Items {
+			var point usagePoint
+			for _, container := range pod.Containers {
+				point.CPUMillicores += container.Usage.Cpu().MilliValue()
+				point.MemoryBytes += container.Usage.Memory().Value()
+			}
+			sample.pods[pod.Namespace+"/"+pod.Name] = point
+		}
+		return nil
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err
+	if err != nil {
+		return
+	}
+	overwritten, ok := s.ring.push(sample)
+	if s.store == nil {
+		return
+	}
+	// Persisting is best effort; the in-memory history stays complete
+	if ok {
+		if err := s.store.Delete(usageBucket, usageKey(overwritten.time)); err != nil {
+			fmt.Printf("Failed to delete persisted usage sample: %v\n", err)
+		}
+	}
+	persisted := persistedUsageSample{Time: sample.time, Nodes: sample.nodes, Pods: sample.pods}
+	if err := s.store.Put(usageBucket, usageKey(sample.time), persisted); err != nil {
+		fmt.Printf("Failed to persist usage sample: %v\n", err)
+	}
+}
+
+// usageStats summarizes a series of usage points.
+func usageStats(points []usagePoint) map[string]interface{} {
+	if len(points) == 0 {
+		return nil
+	}
+	stats := func(value func(usagePoint) int64) map[string]int64 {
+		min, max, sum := value(points[0]), value(points[0]), int64(0)
+		for _, point := range points {
+			v := value(point)
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+			sum += v
+		}
+		last := value(points[len(points)-1])
+		return map[string]int64{
+			"min":    min,
+			"max":    max,
+			"avg":    sum / int64(len(points)),
+			"latest": last,
+			"change": last - value(points[0]),
+		}
+	}
+	return map[string]interface{}{
+		"cpuMillicores": stats(func(p usagePoint) int64 { return p.CPUMillicores }),
+		"memoryBytes":   stats(func(p usagePoint) int64 { return p.MemoryBytes }),
+	}
+}
+
+// History returns the sampled usage of a pod or node over the last window:
+// each sample and the minimum, maximum, average, latest value and change of
+// CPU and memory. Samples in which the pod or node was missing are skipped.
+// Returns the history, or an error if kind is not Pod or Node or no samples
+// have been taken yet.
+func (s *UsageSampler) History(kind, namespace, name string, window time.Duration) (map[string]interface{}, error) {
+	if kind != "Pod" && kind != "Node" {
+		return nil, fmt.Errorf("usage history is only kept for kind Pod or Node")
+	}
+	if window <= 0 || window > s.retention {
+		window = s.retention
+	}
+	key := name
+	if kind == "Pod" {
+		if namespace == "" {
+			namespace = "default"
+		}
+		key = namespace + "/" + name
+	}
+
+	s.mu.RLock()
+	samples := s.ring.since(time.Now().Add(-window))
+	lastError := s.lastError
+	s.mu.RUnlock()
+	if len(samples) == 0 {
+		if lastError != nil {
+			return nil, fmt.Errorf("no usage samples yet; the metrics API failed: %w", lastError)
+		}
+		return nil, fmt.Errorf("no usage samples yet; the first sample is taken at startup and then every %s", s.interval)
+	}
+
+	var points []usagePoint
+	series := []map[string]interface{}{}
+	for _, sample := range samples {
+		values := sample.pods
+		if kind == "Node" {
+			values = sample.nodes
+		}
+		point, ok := values[key]
+		if !ok {
+			continue
+		}
+		points = append(points, point)
+		series = append(series, map[string]interface{}{
+			"time":          sample.time.UTC().Format(time.RFC3339),
+			"cpuMillicores": point.CPUMillicores,
+			"memoryBytes":   point.MemoryBytes,
+		})
+	}
+
+	result := map[string]interface{}{
+		"kind":            kind,
+		"name":            name,
+		"intervalSeconds": int(s.interval.Seconds()),
+		"windowSeconds":   int(window.Seconds()),
+		"samples":         series,
+	}
+	if kind == "Pod" {
+		result["namespace"] = namespace
+	}
+	if stats := usageStats(points); stats != nil {
+		result["stats"] = stats
+	} else {
+		result["message"] = fmt.Sprintf("no samples of %s %s in the last %s", kind, key, window)
+	}
+	if lastError != nil {
+		result["lastSampleError"] = lastError.Error()
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+)
+
+// TestUsageRing tests that the ring buffer keeps the newest samples in order
+func TestUsageRing(t *testing.T) {
+	ring := usageRing{samples: make([]usageSample, 3)}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		ring.push(usageSample{time: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	samples := ring.since(start)
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 samples, got %d", len(samples))
+	}
+	for i, sample := range samples {
+		if want := start.Add(time.Duration(i+2) * time.Minute); !sample.time.Equal(want) {
+			t.Errorf("Sample %d at %v, want %v", i, sample.time, want)
+		}
+	}
+	if samples := ring.since(start.Add(4 * time.Minute)); len(samples) != 1 {
+		t.Errorf("Expected 1 recent sample, got %d", len(samples))
+	}
+	if samples := ring.since(start.Add(time.Hour)); len(samples) != 0 {
+		t.Errorf("Expected no samples, got %d", len(samples))
+	}
+}
+
+// TestUsagePersist tests restoring the samples of the retention period from the state store
+func TestUsagePersist(t *testing.T) {
+	store := memoryStore{}
+	now := time.Now()
+	for _, age := range []time.Duration{3 * time.Hour, 40 * time.Minute, 20 * time.Minute, 10 * time.Minute} {
+		at := now.Add(-age)
+		sample := persistedUsageSample{Time: at, Nodes: map[string]usagePoint{"node-1": {CPUMillicores: 100}}}
+		if err := store.Put(usageBucket, usageKey(at), sample); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two samples fit in an hour sampled every 30 minutes
+	sampler := NewUsageSampler(&Client{}, 30*time.Minute, time.Hour)
+	restored, err := sampler.Persist(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored != 2 {
+		t.Errorf("Expected 2 restored samples, got %d", restored)
+	}
+	samples := sampler.ring.since(time.Time{})
+	if len(samples) != 2 || !samples[0].time.Equal(now.Add(-20*time.Minute)) || samples[1].nodes["node-1"].CPUMillicores != 100 {
+		t.Errorf("Expected the two newest samples, got %v", samples)
+	}
+	if len(store[usageBucket]) != 2 {
+		t.Errorf("Expected the older samples to be deleted from the store, got %d", len(store[usageBucket]))
+	}
+}
+
+// TestUsageStats tests summarizing a usage series
+func TestUsageStats(t *testing.T) {
+	stats := usageStats([]usagePoint{{100, 1000}, {300, 500}, {200, 3000}})
+	cpu := stats["cpuMillicores"].(map[string]int64)
+	if cpu["min"] != 100 || cpu["max"] != 300 || cpu["avg"] != 200 || cpu["latest"] != 200 || cpu["change"] != 100 {
+		t.Errorf("Unexpected CPU stats: %v", cpu)
+	}
+	memory := stats["memoryBytes"].(map[string]int64)
+	if memory["min"] != 500 || memory["max"] != 3000 || memory["change"] != 2000 {
+		t.Errorf("Unexpected memory stats: %v", memory)
+	}
+	if usageStats(nil) != nil {
+		t.Error("Expected no stats for an empty series")
+	}
+}
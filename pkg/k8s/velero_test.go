@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestCreateVeleroBackup tests the read-only gate, validation, dry runs and errors of creating Velero backups
+func TestCreateVeleroBackup(t *testing.T) {
+	var requests []string
+	var body map[string]interface{}
+	exists := false
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		if exists {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"backups.velero.io \"web-backup\" already exists","reason":"AlreadyExists","code":409}`))
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(data)
+	}))
+	ctx := context.Background()
+
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readOnly.CreateVeleroBackup(ctx, "", "web", "web-backup", "", ""); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected a read-only client to refuse the backup, got %v", err)
+	}
+	if _, err := client.CreateVeleroBackup(ctx, "", "", "web-backup", "", ""); err == nil {
+		t.Error("Expected an error without a namespace to back up")
+	}
+	if _, err := client.CreateVeleroBackup(ctx, "", "web", "web-backup", "3 days", ""); err == nil || !strings.Contains(err.Error(), "invalid ttl") {
+		t.Errorf("Expected an error for an invalid ttl, got %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("Expected refused backups not to reach the server, got %v", requests)
+	}
+
+	backup, err := readOnly.CreateVeleroBackup(WithDryRun(ctx), "", "web", "web-backup", "72h", "aws")
+	if err != nil {
+		t.Fatalf("Expected a dry run to pass in read-only mode, got %v", err)
+	}
+	if len(requests) != 1 || requests[0] != "POST /apis/velero.io/v1/namespaces/velero/backups?dryRun=All" {
+		t.Errorf("Expected a dry-run create in the Velero namespace, got %v", requests)
+	}
+	spec, _ := body["spec"].(map[string]interface{})
+	if namespaces, _ := spec["includedNamespaces"].([]interface{}); len(namespaces) != 1 || namespaces[0] != "web" || spec["ttl"] != "72h" || spec["storageLocation"] != "aws" {
+		t.Errorf("Unexpected backup spec %v", spec)
+	}
+	if metadata, _ := backup["metadata"].(map[string]interface{}); metadata["name"] != "web-backup" {
+		t.Errorf("Expected the created backup to be returned, got %v", backup)
+	}
+
+	exists = true
+	if _, err := client.CreateVeleroBackup(ctx, "", "web", "web-backup", "", ""); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected the API server's error to be returned, got %v", err)
+	}
+}
+
+// TestGetVeleroBackups tests summarizing Velero backups and restores
+func TestGetVeleroBackups(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/velero.io/v1/namespaces/backup-system/backups":
+			w.Write([]byte(`{"apiVersion":"velero.io/v1","kind":"BackupList","items":[{"apiVersion":"velero.io/v1","kind":"Backup",` +
+				`"metadata":{"name":"nightly-1","labels":{"velero.io/schedule-name":"nightly"}},` +
+				`"spec":{"includedNamespaces":["web"]},"status":{"phase":"PartiallyFailed","errors":2}}]}`))
+		case "/apis/velero.io/v1/namespaces/backup-system/restores":
+			w.Write([]byte(`{"apiVersion":"velero.io/v1","kind":"RestoreList","items":[{"apiVersion":"velero.io/v1","kind":"Restore",` +
+				`"metadata":{"name":"restore-1"},"spec":{"backupName":"nightly-1"},"status":{"phase":"Completed"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := client.GetVeleroBackups(context.Background(), "backup-system", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups := result["backups"].([]map[string]interface{})
+	if len(backups) != 1 || backups[0]["phase"] != "PartiallyFailed" || backups[0]["schedule"] != "nightly" {
+		t.Errorf("Unexpected backups %v", backups)
+	}
+	restores := result["restores"].([]map[string]interface{})
+	if len(restores) != 1 || restores[0]["backupName"] != "nightly-1" || restores[0]["phase"] != "Completed" {
+		t.Errorf("Unexpected restores %v", restores)
+	}
+
+	if _, err := client.GetVeleroBackups(context.Background(), "", false); err == nil || !strings.Contains(err.Error(), "is Velero installed") {
+		t.Errorf("Expected an error when Velero is not installed, got %v", err)
+	}
+}
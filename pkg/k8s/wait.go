@@ -0,0 +1,179 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// waitPollInterval is how often WaitFor re-reads the objects it waits for.
+	waitPollInterval = 2 * time.Second
+	// MaxWaitTimeout bounds how long WaitFor may wait.
+	MaxWaitTimeout = 10 * time.Minute
+)
+
+// WaitCondition is a condition to wait for, in the syntax of kubectl wait --for:
+// "delete", "condition=Ready", "condition=Ready=False" or
+// "jsonpath={.status.phase}=Running".
+type WaitCondition struct {
+	expression      string
+	delete          bool
+	conditionType   string
+	conditionStatus string
+	path            *jsonpath.JSONPath
+	value           string
+}
+
+// ParseWaitCondition parses a kubectl wait --for expression.
+// Returns the condition, or an error if the expression is invalid.
+func ParseWaitCondition(expression string) (*WaitCondition, error) {
+	cond := &WaitCondition{expression: expression}
+	switch {
+	case expression == "delete":
+		cond.delete = true
+	case strings.HasPrefix(expression, "condition="):
+		conditionType, status, found := strings.Cut(strings.TrimPrefix(expression, "condition="), "=")
+		if conditionType == "" {
+			return nil, fmt.Errorf("invalid condition %q: expected condition=<type>[=<status>]", expression)
+		}
+		cond.conditionType = conditionType
+		cond.conditionStatus = "True"
+		if found {
+			cond.conditionStatus = status
+		}
+	case strings.HasPrefix(expression, "jsonpath="):
+		rest := strings.TrimPrefix(expression, "jsonpath=")
+		end := strings.LastIndex(rest, "}=")
+		if !strings.HasPrefix(rest, "{") || end < 0 {
+			return nil, fmt.Errorf("invalid condition %q: expected jsonpath={<path>}=<value>", expression)
+		}
+		cond.path = jsonpath.New("waitFor").AllowMissingKeys(true)
+		if err := cond.path.Parse(rest[:end+1]); err != nil {
+			return nil, fmt.Errorf("invalid condition %q: %w", expression, err)
+		}
+		cond.value = rest[end+2:]
+	default:
+		return nil, fmt.Errorf("invalid condition %q: expected delete, condition=<type>[=<status>] or jsonpath={<path>}=<value>", expression)
+	}
+	return cond, nil
+}
+
+// check reports whether obj satisfies the condition, together with the value
+// observed for it.
+func (w *WaitCondition) check(obj map[string]interface{}) (bool, string) {
+	if w.delete {
+		return false, "exists"
+	}
+	if w.path != nil {
+		value, ok := printerColumnValue(w.path, obj)
+		if !ok {
+			return false, "<none>"
+		}
+		observed := fmt.Sprint(value)
+		return observed == w.value, observed
+	}
+
+	for _, condition := range summarizeConditions(obj) {
+		conditionType, _ := condition["type"].(string)
+		if strings.EqualFold(conditionType, w.conditionType) {
+			status, _ := condition["status"].(string)
+			observed := fmt.Sprintf("%s=%s", conditionType, status)
+			if reason, _ := condition["reason"].(string); reason != "" {
+				observed += " (" + reason + ")"
+			}
+			return strings.EqualFold(status, w.conditionStatus), observed
+		}
+	}
+	return false, w.conditionType + " not reported"
+}
+
+// WaitFor polls the named object, or the objects matching labelSelector, until
+// all of them satisfy cond or timeout elapses, like kubectl wait. Waiting for
+// deletion is satisfied once no object is left. A missing named object counts
+// as not yet satisfying other conditions, since it may still be created. After
+// each poll that leaves objects pending, progress is called with the time
+// elapsed and a description of the pending objects.
+// Returns a map reporting whether the condition was "met", the time waited and
+// the state of each object, or an error if the objects cannot be read.
+func (c *Client) WaitFor(ctx context.Context, kind, name, namespace, labelSelector string, cond *WaitCondition, timeout time.Duration, progress func(elapsed time.Duration, pending string)) (map[string]interface{}, error) {
+	if (name == "") == (labelSelector == "") {
+		return nil, fmt.Errorf("exactly one of name and labelSelector must be set")
+	}
+	if timeout <= 0 || timeout > MaxWaitTimeout {
+		timeout = MaxWaitTimeout
+	}
+	resource, err := c.resourceClient(kind, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var objects []unstructured.Unstructured
+		if name != "" {
+			obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+			switch {
+			case err == nil:
+				objects = append(objects, *obj)
+			case !errors.IsNotFound(err) && ctx.Err() == nil:
+				return nil, fmt.Errorf("failed to get %s/%s: %w", kind, name, err)
+			}
+		} else {
+			list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+			if err == nil {
+				objects = list.Items
+			} else if ctx.Err() == nil {
+				return nil, fmt.Errorf("failed to list %s: %w", kind, err)
+			}
+		}
+
+		states := []map[string]interface{}{}
+		var pending []string
+		for _, obj := range objects {
+			met, observed := cond.check(obj.Object)
+			states = append(states, map[string]interface{}{"name": obj.GetName(), "met": met, "observed": observed})
+			if !met {
+				pending = append(pending, fmt.Sprintf("%s (%s)", obj.GetName(), observed))
+			}
+		}
+		if name != "" && len(objects) == 0 && !cond.delete {
+			pending = append(pending, name+" (not found)")
+		}
+		// A selector matching nothing only satisfies a deletion
+		if name == "" && len(objects) == 0 && !cond.delete {
+			pending = append(pending, "no objects match "+labelSelector)
+		}
+
+		elapsed := time.Since(start)
+		if len(pending) == 0 || ctx.Err() != nil {
+			return map[string]interface{}{
+				"met":            len(pending) == 0,
+				"condition":      cond.expression,
+				"timedOut":       len(pending) > 0,
+				"elapsedSeconds": int(elapsed.Seconds()),
+				"objects":        states,
+				"pending":        pending,
+			}, nil
+		}
+		if progress != nil {
+			progress(elapsed, strings.Join(pending, ", "))
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"testing"
+)
+
+// TestWaitCondition tests parsing kubectl wait expressions and checking objects against them
+func TestWaitCondition(t *testing.T) {
+	pod := map[string]interface{}{
+		"status": map[string]interface{}{
+			"phase":      "Running",
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False", "reason": "ContainersNotReady"}},
+		},
+	}
+	tests := []struct {
+		expression string
+		met        bool
+		observed   string
+	}{
+		{"condition=Ready", false, "Ready=False (ContainersNotReady)"},
+		{"condition=ready=false", true, "Ready=False (ContainersNotReady)"},
+		{"condition=Initialized", false, "Initialized not reported"},
+		{"jsonpath={.status.phase}=Running", true, "Running"},
+		{"jsonpath={.status.podIP}=10.0.0.1", false, "<none>"},
+		{"delete", false, "exists"},
+	}
+	for _, tt := range tests {
+		cond, err := ParseWaitCondition(tt.expression)
+		if err != nil {
+			t.Errorf("ParseWaitCondition(%q) failed: %v", tt.expression, err)
+			continue
+		}
+		if met, observed := cond.check(pod); met != tt.met || observed != tt.observed {
+			t.Errorf("check(%q) = %v, %q; want %v, %q", tt.expression, met, observed, tt.met, tt.observed)
+		}
+	}
+
+	for _, expression := range []string{"", "Ready", "condition=", "jsonpath=.status.phase", "jsonpath={.status.phase"} {
+		if _, err := ParseWaitCondition(expression); err == nil {
+			t.Errorf("Expected an error for %q", expression)
+		}
+	}
+}
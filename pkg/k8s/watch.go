@@ -0,0 +1,244 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// MaxWatchDuration bounds how long WatchResources may watch.
+	MaxWatchDuration = 5 * time.Minute
+	// watchReconnectInterval is how long WatchResources waits before
+	// reconnecting a watch the server closed.
+	watchReconnectInterval = time.Second
+	// maxChangedPaths bounds the changed field paths reported per MODIFIED delta.
+	maxChangedPaths = 20
+)
+
+// changedPathsIgnored are bookkeeping fields that change on every update and
+// say nothing about what a controller did.
+var changedPathsIgnored = map[string]bool{
+	"metadata.resourceVersion": true,
+	"metadata.managedFields":   true,
+}
+
+// changedPaths returns the sorted dotted paths of the fields that differ
+// between two versions of an object, descending into maps but comparing lists
+// as a whole.
+func changedPaths(previous, current map[string]interface{}, prefix string) []string {
+	var paths []string
+	keys := map[string]bool{}
+	for key := range previous {
+		keys[key] = true
+	}
+	for key := range current {
+		keys[key] = true
+	}
+	for key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if changedPathsIgnored[path] {
+			continue
+		}
+		before, after := previous[key], current[key]
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		beforeMap, beforeIsMap := before.(map[string]interface{})
+		afterMap, afterIsMap := after.(map[string]interface{})
+		if beforeIsMap && afterIsMap {
+			paths = append(paths, changedPaths(beforeMap, afterMap, path)...)
+		} else {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// watchDelta summarizes a watch event for an object. For MODIFIED events the
+// fields that changed since the previous version seen are listed.
+func watchDelta(eventType watch.EventType, obj *unstructured.Unstructured, previous *unstructured.Unstructured, offset time.Duration) map[string]interface{} {
+	delta := map[string]interface{}{
+		"type":            string(eventType),
+		"afterSeconds":    offset.Round(100 * time.Millisecond).Seconds(),
+		"kind":            obj.GetKind(),
+		"name":            obj.GetName(),
+		"resourceVersion": obj.GetResourceVersion(),
+	}
+	if obj.GetNamespace() != "" {
+		delta["namespace"] = obj.GetNamespace()
+	}
+	if eventType == watch.Modified && previous != nil {
+		paths := changedPaths(previous.Object, obj.Object, "")
+		if len(paths) > maxChangedPaths {
+			delta["changedPathsTruncated"] = true
+			paths = paths[:maxChangedPaths]
+		}
+		delta["changedPaths"] = paths
+	}
+	if phase, found, _ := unstructured.NestedString(obj.Object, "status", "phase"); found {
+		delta["phase"] = phase
+	}
+	if conditions := summarizeConditions(obj.Object); len(conditions) > 0 {
+		var summary []string
+		for _, condition := range conditions {
+			summary = append(summary, fmt.Sprintf("%v=%v", condition["type"], condition["status"]))
+		}
+		delta["conditions"] = strings.Join(summary, ",")
+	}
+	return delta
+}
+
+// WatchResources watches the objects of a kind matching the selectors for the
+// given duration and records the ADDED, MODIFIED and DELETED deltas observed,
+// passing each to notify if it is not nil. The watch starts from the state at
+// the time of the call or, if resourceVersion is set, resumes from that
+// version, e.g. the one a previous watch returned, so no change in between is
+// missed. If that version has expired, the objects are listed again and
+// "expired" is set, since changes may have been missed. When the server
+// closes the watch before the duration elapses, it reconnects from the last
+// version seen. Recording stops early once maxEvents deltas have been seen.
+// Returns the deltas with the number of objects present at the start, the
+// "resourceVersion" to resume from and whether the watch was cut short, or
+// an error if the watch cannot be started.
+func (c *Client) WatchResources(ctx context.Context, kind, namespace, labelSelector, fieldSelector, resourceVersion string, duration time.Duration, maxEvents int,
+	notify func(delta map[string]interface{})) (map[string]interface{}, error) {
+	if duration <= 0 || duration > MaxWatchDuration {
+		duration = MaxWatchDuration
+	}
+	resource, err := c.resourceClient(kind, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector}
+	result := map[string]interface{}{"kind": kind}
+	seen := map[types.UID]*unstructured.Unstructured{}
+	list := func() error {
+		initial, err := resource.List(ctx, listOptions)
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", kind, err)
+		}
+		seen = map[types.UID]*unstructured.Unstructured{}
+		for i := range initial.Items {
+			seen[initial.Items[i].GetUID()] = &initial.Items[i]
+		}
+		result["initialCount"] = len(initial.Items)
+		resourceVersion = initial.GetResourceVersion()
+		return nil
+	}
+	if resourceVersion != "" {
+		result["resumedFrom"] = resourceVersion
+	} else if err := list(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	start := time.Now()
+	deltas := []map[string]interface{}{}
+	reconnects := 0
+	finish := func() (map[string]interface{}, error) {
+		result["deltas"] = deltas
+		result["resourceVersion"] = resourceVersion
+		result["watchedSeconds"] = int(time.Since(start).Seconds())
+		if reconnects > 0 {
+			result["reconnects"] = reconnects
+		}
+		return result, nil
+	}
+
+	// consume records the deltas of one watch connection. It returns whether
+	// the watch is done, and whether the resourceVersion has expired.
+	consume := func(watcher watch.Interface) (done, expired bool, err error) {
+		defer watcher.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return true, false, nil
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					return false, false, nil
+				}
+				if event.Type == watch.Error {
+					err := apierrors.FromObject(event.Object)
+					if apierrors.IsGone(err) || apierrors.IsResourceExpired(err) {
+						return false, true, nil
+					}
+					return true, false, fmt.Errorf("watch of %s failed: %v", kind, err)
+				}
+				obj, ok := event.Object.(*unstructured.Unstructured)
+				if !ok {
+					continue
+				}
+				resourceVersion = obj.GetResourceVersion()
+				if event.Type == watch.Bookmark {
+					continue
+				}
+
+				delta := watchDelta(event.Type, obj, seen[obj.GetUID()], time.Since(start))
+				deltas = append(deltas, delta)
+				if notify != nil {
+					notify(delta)
+				}
+				if event.Type == watch.Deleted {
+					delete(seen, obj.GetUID())
+				} else {
+					seen[obj.GetUID()] = obj
+				}
+				if len(deltas) >= maxEvents {
+					result["truncated"] = true
+					return true, false, nil
+				}
+			}
+		}
+	}
+
+	for {
+		watchOptions := listOptions
+		watchOptions.ResourceVersion = resourceVersion
+		watchOptions.AllowWatchBookmarks = true
+		watcher, err := resource.Watch(ctx, watchOptions)
+		expired := apierrors.IsGone(err) || apierrors.IsResourceExpired(err)
+		if err != nil && !expired {
+			if ctx.Err() != nil {
+				return finish()
+			}
+			return nil, fmt.Errorf("failed to watch %s: %w", kind, err)
+		}
+		if err == nil {
+			var done bool
+			if done, expired, err = consume(watcher); err != nil {
+				return nil, err
+			}
+			if done || ctx.Err() != nil {
+				return finish()
+			}
+		}
+
+		if expired {
+			// Changes since the version are gone; start over from the current state
+			result["expired"] = true
+			if err := list(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// The server closed the watch before the duration elapsed
+		reconnects++
+		sleepContext(ctx, watchReconnectInterval)
+	}
+}
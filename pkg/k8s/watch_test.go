@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestChangedPaths tests listing the fields that differ between two versions of an object
+func TestChangedPaths(t *testing.T) {
+	previous := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "resourceVersion": "1", "labels": map[string]interface{}{"app": "web"}},
+		"spec":     map[string]interface{}{"replicas": int64(2), "ports": []interface{}{int64(80)}},
+		"status":   map[string]interface{}{"readyReplicas": int64(1)},
+	}
+	current := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "resourceVersion": "2", "labels": map[string]interface{}{"app": "web", "tier": "frontend"}},
+		"spec":     map[string]interface{}{"replicas": int64(3), "ports": []interface{}{int64(80), int64(443)}},
+		"status":   map[string]interface{}{"readyReplicas": int64(1), "phase": "Running"},
+	}
+	want := []string{"metadata.labels.tier", "spec.ports", "spec.replicas", "status.phase"}
+	if got := changedPaths(previous, current, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("changedPaths() = %v, want %v", got, want)
+	}
+	if got := changedPaths(previous, previous, ""); len(got) != 0 {
+		t.Errorf("Expected no changes for identical objects, got %v", got)
+	}
+}
+
+// TestWatchResourcesResume tests resuming a watch from a resourceVersion, starting over when it
+// has expired and reconnecting from the last version seen when the server closes the watch
+func TestWatchResourcesResume(t *testing.T) {
+	var mu sync.Mutex
+	var watchVersions []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		pod := func(rv, phase string) string {
+			return `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"web-1","namespace":"web","uid":"u1","resourceVersion":"` + rv +
+				`"},"status":{"phase":"` + phase + `"}}`
+		}
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[` +
+				`{"name":"pods","namespaced":true,"kind":"Pod","verbs":["get","list","watch"]}]}`))
+		case "/api/v1/namespaces/web/pods":
+			if r.URL.Query().Get("watch") != "true" {
+				w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"resourceVersion":"10"},"items":[` + pod("9", "Pending") + `]}`))
+				return
+			}
+			mu.Lock()
+			watchVersions = append(watchVersions, r.URL.Query().Get("resourceVersion"))
+			mu.Unlock()
+			switch r.URL.Query().Get("resourceVersion") {
+			case "5":
+				w.Write([]byte(`{"type":"ERROR","object":{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Expired","code":410,"message":"too old resource version"}}` + "\n"))
+			case "10":
+				w.Write([]byte(`{"type":"MODIFIED","object":` + pod("11", "Running") + `}` + "\n"))
+			default:
+				w.Write([]byte(`{"type":"DELETED","object":` + pod("12", "Running") + `}` + "\n"))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	notified := 0
+	result, err := client.WatchResources(context.Background(), "Pod", "web", "", "", "5", 30*time.Second, 2,
+		func(delta map[string]interface{}) { notified++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	deltas := result["deltas"].([]map[string]interface{})
+	if len(deltas) != 2 || deltas[0]["type"] != "MODIFIED" || deltas[1]["type"] != "DELETED" || notified != 2 {
+		t.Fatalf("Expected a MODIFIED and a DELETED delta, both notified, got %v", deltas)
+	}
+	if paths := deltas[0]["changedPaths"].([]string); !reflect.DeepEqual(paths, []string{"status.phase"}) {
+		t.Errorf("Expected the phase change against the relisted pod, got %v", paths)
+	}
+	if result["expired"] != true || result["initialCount"] != 1 || result["reconnects"] != 1 || result["resourceVersion"] != "12" {
+		t.Errorf("Expected an expired resume, one reconnect and resourceVersion 12, got %v", result)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(watchVersions, []string{"5", "10", "11"}) {
+		t.Errorf("Expected watches from 5, the relisted 10 and the last seen 11, got %v", watchVersions)
+	}
+}
@@ -0,0 +1,157 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WhoCan finds the subjects RBAC allows to perform an action, the inverse of
+// CanI. It walks the ClusterRoles and ClusterRoleBindings, and, when the
+// request names a namespace, the Roles and RoleBindings of that namespace,
+// matching rules the way the RBAC authorizer evaluates them. Without a
+// namespace only cluster-wide bindings count, as for cluster-scoped resources
+// and requests across all namespaces. A resource given without a group is
+// resolved through discovery. Members of the system:masters group are allowed
+// everything without RBAC and are not listed. RBAC objects the client may not
+// list are reported in "errors".
+// Returns a map with the "request", the "grants" pairing each granting role
+// with a binding and its subjects, the "subjects" allowed across all grants,
+// the "unboundRoles" that grant the action but are not bound, and "errors",
+// or an error.
+func (c *Client) WhoCan(ctx context.Context, request AccessRequest) (map[string]interface{}, error) {
+	request = c.resolveAccessRequest(request)
+	permission := request.permission()
+	if request.Path != "" && request.Namespace != "" {
+		return nil, fmt.Errorf("non-resource URLs are not namespaced")
+	}
+
+	errs := []string{}
+	granting := map[RoleReference]bool{}
+	clusterRoles, err := c.clientset.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("clusterRoles: %v", err))
+	} else {
+		for _, role := range clusterRoles.Items {
+			if rulesAllow(role.Rules, permission) {
+				granting[RoleReference{Kind: "ClusterRole", Name: role.Name}] = true
+			}
+		}
+	}
+	if request.Namespace != "" {
+		roles, err := c.clientset.RbacV1().Roles(request.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("roles: %v", err))
+		} else {
+			for _, role := range roles.Items {
+				if rulesAllow(role.Rules, permission) {
+					granting[RoleReference{Kind: "Role", Namespace: role.Namespace, Name: role.Name}] = true
+				}
+			}
+		}
+	}
+
+	grants := []map[string]interface{}{}
+	bound := map[RoleReference]bool{}
+	subjects := map[rbacv1.Subject]bool{}
+	addGrant := func(roleRef rbacv1.RoleRef, bindingKind, bindingName, bindingNamespace string, bindingSubjects []rbacv1.Subject) {
+		role := RoleReference{Kind: roleRef.Kind, Namespace: roleNamespace(roleRef, bindingNamespace), Name: roleRef.Name}
+		if roleRef.APIGroup != rbacv1.GroupName || !granting[role] {
+			return
+		}
+		bound[role] = true
+		summaries := []map[string]interface{}{}
+		for _, subject := range bindingSubjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == "" {
+				subject.Namespace = bindingNamespace
+			}
+			subject.APIGroup = ""
+			subjects[subject] = true
+			summaries = append(summaries, rbacSubjectSummary(subject))
+		}
+		binding := map[string]interface{}{"kind": bindingKind, "name": bindingName}
+		if bindingNamespace != "" {
+			binding["namespace"] = bindingNamespace
+		}
+		grants = append(grants, map[string]interface{}{
+			"role":     roleReferenceSummary(role),
+			"binding":  binding,
+			"subjects": summaries,
+		})
+	}
+
+	clusterBindings, err := c.clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("clusterRoleBindings: %v", err))
+	} else {
+		for _, binding := range clusterBindings.Items {
+			addGrant(binding.RoleRef, "ClusterRoleBinding", binding.Name, "", binding.Subjects)
+		}
+	}
+	if request.Namespace != "" {
+		bindings, err := c.clientset.RbacV1().RoleBindings(request.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("roleBindings: %v", err))
+		} else {
+			for _, binding := range bindings.Items {
+				addGrant(binding.RoleRef, "RoleBinding", binding.Name, binding.Namespace, binding.Subjects)
+			}
+		}
+	}
+
+	unbound := []RoleReference{}
+	for role := range granting {
+		if !bound[role] {
+			unbound = append(unbound, role)
+		}
+	}
+	sort.Slice(unbound, func(i, j int) bool {
+		if unbound[i].Kind != unbound[j].Kind {
+			return unbound[i].Kind < unbound[j].Kind
+		}
+		return unbound[i].Name < unbound[j].Name
+	})
+	unboundRoles := []map[string]interface{}{}
+	for _, role := range unbound {
+		unboundRoles = append(unboundRoles, roleReferenceSummary(role))
+	}
+
+	allowed := make([]rbacv1.Subject, 0, len(subjects))
+	for subject := range subjects {
+		allowed = append(allowed, subject)
+	}
+	sort.Slice(allowed, func(i, j int) bool {
+		a, b := allowed[i], allowed[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	subjectSummaries := []map[string]interface{}{}
+	for _, subject := range allowed {
+		subjectSummaries = append(subjectSummaries, rbacSubjectSummary(subject))
+	}
+
+	return map[string]interface{}{
+		"request":      forbiddenRequestSummary(request.forbiddenRequest()),
+		"grants":       grants,
+		"subjects":     subjectSummaries,
+		"unboundRoles": unboundRoles,
+		"errors":       errs,
+	}, nil
+}
+
+// rbacSubjectSummary describes the subject of a binding.
+func rbacSubjectSummary(subject rbacv1.Subject) map[string]interface{} {
+	summary := map[string]interface{}{"kind": subject.Kind, "name": subject.Name}
+	if subject.Namespace != "" {
+		summary["namespace"] = subject.Namespace
+	}
+	return summary
+}
@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// TestWhoCan tests finding the roles, bindings and subjects that grant an action
+func TestWhoCan(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/rbac.authorization.k8s.io/v1/clusterroles":
+			w.Write([]byte(`{"kind":"ClusterRoleList","apiVersion":"rbac.authorization.k8s.io/v1","items":[` +
+				`{"metadata":{"name":"cluster-admin"},"rules":[{"apiGroups":["*"],"resources":["*"],"verbs":["*"]}]},` +
+				`{"metadata":{"name":"view"},"rules":[{"apiGroups":["apps"],"resources":["deployments"],"verbs":["get","list"]}]},` +
+				`{"metadata":{"name":"scaler"},"rules":[{"apiGroups":["apps"],"resources":["deployments/scale"],"verbs":["patch"]}]}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/namespaces/web/roles":
+			w.Write([]byte(`{"kind":"RoleList","apiVersion":"rbac.authorization.k8s.io/v1","items":[` +
+				`{"metadata":{"name":"deployer","namespace":"web"},"rules":[{"apiGroups":["apps"],"resources":["deployments"],"verbs":["patch"]}]},` +
+				`{"metadata":{"name":"reader","namespace":"web"},"rules":[{"apiGroups":[""],"resources":["pods"],"verbs":["get"]}]}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/clusterrolebindings":
+			w.Write([]byte(`{"kind":"ClusterRoleBindingList","apiVersion":"rbac.authorization.k8s.io/v1","items":[` +
+				`{"metadata":{"name":"admins"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"ClusterRole","name":"cluster-admin"},` +
+				`"subjects":[{"kind":"Group","apiGroup":"rbac.authorization.k8s.io","name":"platform"}]},` +
+				`{"metadata":{"name":"viewers"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"ClusterRole","name":"view"},` +
+				`"subjects":[{"kind":"User","apiGroup":"rbac.authorization.k8s.io","name":"jane"}]}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/namespaces/web/rolebindings":
+			w.Write([]byte(`{"kind":"RoleBindingList","apiVersion":"rbac.authorization.k8s.io/v1","items":[` +
+				`{"metadata":{"name":"deployer","namespace":"web"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"Role","name":"deployer"},` +
+				`"subjects":[{"kind":"ServiceAccount","name":"ci"},{"kind":"Group","apiGroup":"rbac.authorization.k8s.io","name":"platform"}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	result, err := client.WhoCan(ctx, AccessRequest{Verb: "patch", Group: "apps", Resource: "deployments", Namespace: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grants := result["grants"].([]map[string]interface{})
+	if len(grants) != 2 || grants[0]["role"].(map[string]interface{})["name"] != "cluster-admin" ||
+		grants[1]["binding"].(map[string]interface{})["name"] != "deployer" {
+		t.Errorf("Expected grants through cluster-admin and the deployer role, got %v", grants)
+	}
+	subjects := result["subjects"].([]map[string]interface{})
+	if len(subjects) != 2 || subjects[0]["name"] != "platform" || subjects[1]["name"] != "ci" || subjects[1]["namespace"] != "web" {
+		t.Errorf("Expected the platform group and the ci service account of web, got %v", subjects)
+	}
+	if unbound := result["unboundRoles"].([]map[string]interface{}); len(unbound) != 0 {
+		t.Errorf("Expected no unbound roles, got %v", unbound)
+	}
+
+	result, err = client.WhoCan(ctx, AccessRequest{Verb: "patch", Group: "apps", Resource: "deployments/scale"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unbound := result["unboundRoles"].([]map[string]interface{})
+	if len(result["grants"].([]map[string]interface{})) != 1 || len(unbound) != 1 || unbound[0]["name"] != "scaler" {
+		t.Errorf("Expected cluster-admin to grant the subresource cluster-wide and scaler to be unbound, got %v", result)
+	}
+}
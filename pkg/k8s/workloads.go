@@ -0,0 +1,475 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// defaultCPUUtilization is the CPU target used when no target is given,
+// matching kubectl autoscale.
+const defaultCPUUtilization = 80
+
+// SetAutoscaling creates or updates an autoscaling/v2 HorizontalPodAutoscaler
+// named after the target workload. The HPA scales between minReplicas and
+// maxReplicas to hold the given average CPU and/or memory utilization
+// (percent of requests); a zero target is omitted, and if both are zero a CPU
+// target of 80% is used. An existing HPA of that name must target the same
+// workload.
+// Returns a summary of the HPA including whether it was created, or an error.
+func (c *Client) SetAutoscaling(ctx context.Context, namespace, kind, name string, minReplicas, maxReplicas, cpuUtilization, memoryUtilization int32) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+	if maxReplicas < minReplicas {
+		return nil, fmt.Errorf("maxReplicas (%d) must be at least minReplicas (%d)", maxReplicas, minReplicas)
+	}
+	if cpuUtilization < 0 || memoryUtilization < 0 {
+		return nil, fmt.Errorf("utilization targets must be positive")
+	}
+	if cpuUtilization == 0 && memoryUtilization == 0 {
+		cpuUtilization = defaultCPUUtilization
+	}
+
+	workload, err := c.GetResource(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scale target %s/%s: %w", kind, name, err)
+	}
+	target := unstructured.Unstructured{Object: workload}
+
+	var metrics []autoscalingv2.MetricSpec
+	if cpuUtilization > 0 {
+		metrics = append(metrics, utilizationMetric(corev1.ResourceCPU, cpuUtilization))
+	}
+	if memoryUtilization > 0 {
+		metrics = append(metrics, utilizationMetric(corev1.ResourceMemory, memoryUtilization))
+	}
+
+	spec := autoscalingv2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+			APIVersion: target.GetAPIVersion(),
+			Kind:       target.GetKind(),
+			Name:       target.GetName(),
+		},
+		MinReplicas: &minReplicas,
+		MaxReplicas: maxReplicas,
+		Metrics:     metrics,
+	}
+
+	hpaResource, err := c.resourceClient("HorizontalPodAutoscaler", namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	recordUndo := c.recordUndo(ctx, "setAutoscaling", hpaResource, "HorizontalPodAutoscaler", name, namespace)
+
+	hpas := c.clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace)
+	hpa, err := hpas.Get(ctx, name, metav1.GetOptions{})
+	created := false
+	switch {
+	case errors.IsNotFound(err):
+		hpa, err = hpas.Create(ctx, &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "k8s-mcp-server"},
+			},
+			Spec: spec,
+		}, metav1.CreateOptions{DryRun: dryRunOption(ctx)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HorizontalPodAutoscaler: %w", err)
+		}
+		created = true
+	case err != nil:
+		return nil, fmt.Errorf("failed to get HorizontalPodAutoscaler: %w", err)
+	default:
+		if existing := hpa.Spec.ScaleTargetRef; existing.Kind != spec.ScaleTargetRef.Kind || existing.Name != spec.ScaleTargetRef.Name {
+			return nil, fmt.Errorf("HorizontalPodAutoscaler %s/%s already targets %s %s, not %s %s",
+				namespace, name, existing.Kind, existing.Name, spec.ScaleTargetRef.Kind, spec.ScaleTargetRef.Name)
+		}
+		// Preserve behavior and other fields; only the scaling bounds, target and metrics are managed here
+		hpa.Spec.ScaleTargetRef = spec.ScaleTargetRef
+		hpa.Spec.MinReplicas = spec.MinReplicas
+		hpa.Spec.MaxReplicas = spec.MaxReplicas
+		hpa.Spec.Metrics = spec.Metrics
+		hpa, err = hpas.Update(ctx, hpa, metav1.UpdateOptions{DryRun: dryRunOption(ctx)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update HorizontalPodAutoscaler: %w", err)
+		}
+	}
+	recordUndo()
+
+	result := map[string]interface{}{
+		"name":           hpa.Name,
+		"namespace":      hpa.Namespace,
+		"created":        created,
+		"scaleTargetRef": hpa.Spec.ScaleTargetRef,
+		"minReplicas":    hpa.Spec.MinReplicas,
+		"maxReplicas":    hpa.Spec.MaxReplicas,
+		"metrics":        hpa.Spec.Metrics,
+	}
+	if IsDryRun(ctx) {
+		result["dryRun"] = true
+	}
+	return result, nil
+}
+
+// utilizationMetric returns a resource metric targeting an average utilization.
+func utilizationMetric(resourceName corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: resourceName,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
+
+// deploymentRevisionAnnotation holds the revision number of a Deployment's rollout.
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// podSpecPath returns the field path of the pod spec within a workload of the
+// given kind.
+func podSpecPath(kind string) []string {
+	if kind == "CronJob" {
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	}
+	return []string{"spec", "template", "spec"}
+}
+
+// SetImage updates the image of a single container in a workload's pod template
+// using a strategic merge patch, leaving the rest of the spec untouched. If
+// container is empty, the workload must have exactly one container. After the
+// patch it waits briefly for the controller to observe the change and reports
+// the resulting revision: the revision annotation for Deployments, or
+// status.updateRevision for StatefulSets and DaemonSets.
+// Returns a summary of the change, or an error.
+func (c *Client) SetImage(ctx context.Context, namespace, kind, name, container, image string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	workload, err := c.GetResource(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+	obj := unstructured.Unstructured{Object: workload}
+	specPath := podSpecPath(obj.GetKind())
+
+	container, field, previousImage, err := findContainerImage(workload, specPath, container)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"kind":          obj.GetKind(),
+		"name":          name,
+		"namespace":     namespace,
+		"container":     container,
+		"previousImage": previousImage,
+		"image":         image,
+		"changed":       previousImage != image,
+	}
+	if previousImage == image {
+		result["revision"] = workloadRevision(workload)
+		return result, nil
+	}
+
+	patch := map[string]interface{}{}
+	containers := []interface{}{map[string]interface{}{"name": container, "image": image}}
+	if err := unstructured.SetNestedSlice(patch, containers, append(specPath, field)...); err != nil {
+		return nil, fmt.Errorf("failed to build patch: %w", err)
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build patch: %w", err)
+	}
+
+	resource, err := c.resourceClient(kind, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	recordUndo := c.recordUndo(ctx, "setImage", resource, obj.GetKind(), name, namespace)
+	patched, err := resource.Patch(ctx, name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{DryRun: dryRunOption(ctx)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set image on %s %s/%s: %w", kind, namespace, name, err)
+	}
+	recordUndo()
+	result["generation"] = patched.GetGeneration()
+	if IsDryRun(ctx) {
+		result["dryRun"] = true
+		return result, nil
+	}
+
+	observed := c.waitForObservedGeneration(ctx, resource, name, patched.GetGeneration())
+	if observed != nil {
+		patched = observed
+	}
+	result["observed"] = observed != nil
+	result["revision"] = workloadRevision(patched.UnstructuredContent())
+	return result, nil
+}
+
+// findContainerImage locates a container in the pod spec at specPath, searching
+// containers and then initContainers. If name is empty, the pod spec must have
+// exactly one container. Returns the container's name, the list field holding
+// it and its current image, or an error listing the available containers.
+func findContainerImage(obj map[string]interface{}, specPath []string, name string) (string, string, string, error) {
+	var names []string
+	for _, field := range []string{"containers", "initContainers"} {
+		containers, _, _ := unstructured.NestedSlice(obj, append(specPath, field)...)
+		for _, raw := range containers {
+			ctr, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ctrName, _ := ctr["name"].(string)
+			image, _ := ctr["image"].(string)
+			if ctrName == name || (name == "" && field == "containers" && len(containers) == 1) {
+				return ctrName, field, image, nil
+			}
+			names = append(names, ctrName)
+		}
+	}
+
+	if len(names) == 0 {
+		return "", "", "", fmt.Errorf("resource has no containers at %s", strings.Join(specPath, "."))
+	}
+	if name == "" {
+		return "", "", "", fmt.Errorf("container is required when the workload has several containers: %s", strings.Join(names, ", "))
+	}
+	return "", "", "", fmt.Errorf("container %s not found; available containers: %s", name, strings.Join(names, ", "))
+}
+
+// waitForObservedGeneration polls a workload until its controller has observed
+// the given generation, for up to about five seconds. Returns the observed
+// object, or nil if the controller did not catch up in time.
+func (c *Client) waitForObservedGeneration(ctx context.Context, resource dynamic.ResourceInterface, name string, generation int64) *unstructured.Unstructured {
+	for attempt := 0; attempt < 10; attempt++ {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+			if found && observed >= generation {
+				return obj
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+	return nil
+}
+
+// workloadRevision returns the current rollout revision of a workload: the
+// revision annotation of a Deployment or status.updateRevision of a
+// StatefulSet or DaemonSet.
+func workloadRevision(obj map[string]interface{}) string {
+	if revision, found, _ := unstructured.NestedString(obj, "metadata", "annotations", deploymentRevisionAnnotation); found {
+		return revision
+	}
+	revision, _, _ := unstructured.NestedString(obj, "status", "updateRevision")
+	return revision
+}
+
+// SetRolloutPaused pauses or resumes the rollout of a Deployment by setting
+// spec.paused. While paused, changes to the pod template do not trigger a new
+// rollout, so several changes can be batched or a bad rollout halted.
+// Returns a summary of the Deployment's rollout state, or an error.
+func (c *Client) SetRolloutPaused(ctx context.Context, namespace, name string, paused bool) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	resource, err := c.resourceClient("Deployment", namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	recordUndo := c.recordUndo(ctx, "setRolloutPaused", resource, "Deployment", name, namespace)
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"paused":%t}}`, paused))
+	deployment, err := c.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{DryRun: dryRunOption(ctx)})
+	if err != nil {
+		action := "resume"
+		if paused {
+			action = "pause"
+		}
+		return nil, fmt.Errorf("failed to %s rollout of Deployment %s/%s: %w", action, namespace, name, err)
+	}
+	recordUndo()
+
+	result := map[string]interface{}{
+		"name":              deployment.Name,
+		"namespace":         deployment.Namespace,
+		"paused":            deployment.Spec.Paused,
+		"revision":          deployment.Annotations[deploymentRevisionAnnotation],
+		"replicas":          deployment.Status.Replicas,
+		"updatedReplicas":   deployment.Status.UpdatedReplicas,
+		"availableReplicas": deployment.Status.AvailableReplicas,
+	}
+	if IsDryRun(ctx) {
+		result["dryRun"] = true
+	}
+	return result, nil
+}
+
+// suspendableKinds lists kinds whose reconciliation is suspended via spec.suspend.
+var suspendableKinds = map[string]bool{
+	"CronJob":               true,
+	"Job":                   true,
+	"CronWorkflow":          true, // Argo Workflows
+	"Kustomization":         true, // Flux
+	"HelmRelease":           true,
+	"GitRepository":         true,
+	"OCIRepository":         true,
+	"HelmRepository":        true,
+	"Bucket":                true,
+	"ImageRepository":       true,
+	"ImageUpdateAutomation": true,
+}
+
+// Flux labels and annotations used to keep Flux from reverting a suspension of
+// an object it manages.
+const (
+	fluxKustomizeNameLabel      = "kustomize.toolkit.fluxcd.io/name"
+	fluxKustomizeReconcileAnnot = "kustomize.toolkit.fluxcd.io/reconcile"
+	fluxHelmNameLabel           = "helm.toolkit.fluxcd.io/name"
+	fluxHelmDriftDetectionAnnot = "helm.toolkit.fluxcd.io/driftDetection"
+	fluxReconcileDisabledValue  = "disabled"
+)
+
+// SetSuspended suspends or resumes a CronJob, Job, Argo CronWorkflow or Flux
+// object by setting spec.suspend. If the object is itself managed by Flux, the
+// Flux annotation that disables reconciliation (or drift detection) is set
+// while suspended and removed on resume, so Flux does not revert the change.
+// Returns a summary of the resulting state, or an error.
+func (c *Client) SetSuspended(ctx context.Context, namespace, kind, name string, suspend bool) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	current, err := c.GetResource(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+	obj := unstructured.Unstructured{Object: current}
+	if !suspendableKinds[obj.GetKind()] {
+		return nil, fmt.Errorf("kind %s does not support spec.suspend", obj.GetKind())
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"suspend": suspend},
+	}
+
+	annotations := map[string]interface{}{}
+	labels := obj.GetLabels()
+	for label, annotation := range map[string]string{
+		fluxKustomizeNameLabel: fluxKustomizeReconcileAnnot,
+		fluxHelmNameLabel:      fluxHelmDriftDetectionAnnot,
+	} {
+		if _, managed := labels[label]; !managed {
+			continue
+		}
+		if suspend {
+			annotations[annotation] = fluxReconcileDisabledValue
+		} else if obj.GetAnnotations()[annotation] == fluxReconcileDisabledValue {
+			annotations[annotation] = nil
+		}
+	}
+	if len(annotations) > 0 {
+		patch["metadata"] = map[string]interface{}{"annotations": annotations}
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build patch: %w", err)
+	}
+	resource, err := c.resourceClient(kind, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	recordUndo := c.recordUndo(ctx, "setSuspended", resource, kind, name, namespace)
+	patched, err := resource.Patch(ctx, name, types.MergePatchType, patchBytes, metav1.PatchOptions{DryRun: dryRunOption(ctx)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set suspend on %s %s/%s: %w", kind, namespace, name, err)
+	}
+	recordUndo()
+
+	suspended, _, _ := unstructured.NestedBool(patched.Object, "spec", "suspend")
+	result := map[string]interface{}{
+		"kind":      patched.GetKind(),
+		"name":      patched.GetName(),
+		"namespace": patched.GetNamespace(),
+		"suspended": suspended,
+	}
+	if len(annotations) > 0 {
+		result["fluxAnnotations"] = annotations
+	}
+	if lastSchedule, found, _ := unstructured.NestedString(patched.Object, "status", "lastScheduleTime"); found {
+		result["lastScheduleTime"] = lastSchedule
+	}
+	if IsDryRun(ctx) {
+		result["dryRun"] = true
+	}
+	return result, nil
+}
+
+// ScaleResource sets the replica count of a Deployment, StatefulSet,
+// ReplicaSet or any other resource that exposes the scale subresource.
+// Returns the previous and requested replica counts, or an error.
+func (c *Client) ScaleResource(ctx context.Context, namespace, kind, name string, replicas int32) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	if replicas < 0 {
+		return nil, fmt.Errorf("replicas must be zero or greater")
+	}
+
+	resource, err := c.resourceClient(kind, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	current, err := resource.Get(ctx, name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scale of %s %s/%s: %w", kind, namespace, name, err)
+	}
+	previous, _, _ := unstructured.NestedInt64(current.Object, "spec", "replicas")
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	recordUndo := c.recordUndo(ctx, "scale", resource, kind, name, namespace)
+	scaled, err := resource.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{DryRun: dryRunOption(ctx)}, "scale")
+	if err != nil {
+		return nil, fmt.Errorf("failed to scale %s %s/%s: %w", kind, namespace, name, err)
+	}
+	recordUndo()
+	updated, _, _ := unstructured.NestedInt64(scaled.Object, "spec", "replicas")
+
+	result := map[string]interface{}{
+		"kind":             kind,
+		"name":             name,
+		"namespace":        namespace,
+		"previousReplicas": previous,
+		"replicas":         updated,
+		"changed":          previous != updated,
+	}
+	if IsDryRun(ctx) {
+		result["dryRun"] = true
+	}
+	return result, nil
+}
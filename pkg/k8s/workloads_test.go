@@ -0,0 +1,362 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// conflictObject marks a path whose mutations the test API server answers
+// with a conflict.
+const conflictObject = "conflict"
+
+// mutation is a mutating request received by the test API server.
+type mutation struct {
+	method string
+	path   string
+	dryRun string
+	body   string
+}
+
+// newWorkloadTestClient returns a client of a test API server that serves
+// discovery of the workload kinds and objects by path, and records the
+// mutating requests it receives. Mutations are answered with the object at
+// their path, or a conflict if it is conflictObject.
+func newWorkloadTestClient(t *testing.T, objects map[string]string) (*Client, *[]mutation) {
+	var mutations []mutation
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+			return
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[` +
+				`{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}],"preferredVersion":{"groupVersion":"apps/v1","version":"v1"}},` +
+				`{"name":"autoscaling","versions":[{"groupVersion":"autoscaling/v2","version":"v2"}],"preferredVersion":{"groupVersion":"autoscaling/v2","version":"v2"}},` +
+				`{"name":"batch","versions":[{"groupVersion":"batch/v1","version":"v1"}],"preferredVersion":{"groupVersion":"batch/v1","version":"v1"}}]}`))
+			return
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[` +
+				`{"name":"pods","namespaced":true,"kind":"Pod","verbs":["get","list","delete"]},` +
+				`{"name":"configmaps","namespaced":true,"kind":"ConfigMap","verbs":["get","list","create","patch","delete"]}]}`))
+			return
+		case "/apis/apps/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[` +
+				`{"name":"deployments","namespaced":true,"kind":"Deployment","verbs":["get","list","patch","delete"]},` +
+				`{"name":"deployments/scale","namespaced":true,"kind":"Scale","verbs":["get","patch"]},` +
+				`{"name":"statefulsets","namespaced":true,"kind":"StatefulSet","verbs":["get","list","patch"]}]}`))
+			return
+		case "/apis/autoscaling/v2":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"autoscaling/v2","resources":[` +
+				`{"name":"horizontalpodautoscalers","namespaced":true,"kind":"HorizontalPodAutoscaler","verbs":["get","create","update"]}]}`))
+			return
+		case "/apis/batch/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"batch/v1","resources":[` +
+				`{"name":"cronjobs","namespaced":true,"kind":"CronJob","verbs":["get","patch"]}]}`))
+			return
+		}
+
+		object, ok := objects[r.URL.Path]
+		if r.Method != http.MethodGet {
+			body, _ := io.ReadAll(r.Body)
+			dryRun := r.URL.Query().Get("dryRun")
+			// The dynamic client sends the options of a delete in its body
+			var options metav1.DeleteOptions
+			if r.Method == http.MethodDelete && json.Unmarshal(body, &options) == nil && len(options.DryRun) == 1 {
+				dryRun = options.DryRun[0]
+			}
+			mutations = append(mutations, mutation{r.Method, r.URL.Path, dryRun, string(body)})
+			if object == conflictObject {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"the object has been modified","reason":"Conflict","code":409}`))
+				return
+			}
+		}
+		if !ok || object == conflictObject {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+			return
+		}
+		w.Write([]byte(object))
+	}))
+	return client, &mutations
+}
+
+const (
+	testDeployment = `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api","namespace":"web","resourceVersion":"1"},` +
+		`"spec":{"replicas":2,"template":{"spec":{"containers":[{"name":"app","image":"api:1"}]}}}}`
+	testCronJob = `{"apiVersion":"batch/v1","kind":"CronJob","metadata":{"name":"report","namespace":"web","resourceVersion":"1"},"spec":{"suspend":false}}`
+)
+
+// TestSetAutoscalingOtherTarget tests that an HPA of the workload's name targeting another workload is left alone
+func TestSetAutoscalingOtherTarget(t *testing.T) {
+	client, mutations := newWorkloadTestClient(t, map[string]string{
+		"/apis/apps/v1/namespaces/web/deployments/api": testDeployment,
+		"/apis/autoscaling/v2/namespaces/web/horizontalpodautoscalers/api": `{"apiVersion":"autoscaling/v2","kind":"HorizontalPodAutoscaler",` +
+			`"metadata":{"name":"api","namespace":"web"},"spec":{"scaleTargetRef":{"apiVersion":"apps/v1","kind":"StatefulSet","name":"api"},"maxReplicas":3}}`,
+	})
+
+	_, err := client.SetAutoscaling(context.Background(), "web", "Deployment", "api", 1, 5, 70, 0)
+	if err == nil || !strings.Contains(err.Error(), "already targets StatefulSet api") {
+		t.Errorf("Expected an error for an HPA targeting another workload, got %v", err)
+	}
+	if len(*mutations) != 0 {
+		t.Errorf("Expected the HPA to be left unchanged, got %v", *mutations)
+	}
+}
+
+// TestSetRolloutPausedDryRun tests that pausing a rollout in a dry run is submitted as a server-side dry run
+func TestSetRolloutPausedDryRun(t *testing.T) {
+	client, mutations := newWorkloadTestClient(t, map[string]string{
+		"/apis/apps/v1/namespaces/web/deployments/api": testDeployment,
+	})
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithDryRun(WithSessionID(context.Background(), "s"))
+
+	result, err := readOnly.SetRolloutPaused(ctx, "web", "api", true)
+	if err != nil {
+		t.Fatalf("Expected a dry run to pass in read-only mode, got %v", err)
+	}
+	if result["dryRun"] != true || len(*mutations) != 1 || (*mutations)[0].dryRun != "All" {
+		t.Errorf("Expected a single dry-run patch, got %v and %v", result, *mutations)
+	}
+	if len(readOnly.undo.sessions["s"]) != 0 {
+		t.Errorf("Expected a dry run not to be recorded for undo, got %v", readOnly.undo.sessions)
+	}
+}
+
+// TestSetSuspendedDryRun tests that suspending in a dry run is submitted as a server-side dry run
+func TestSetSuspendedDryRun(t *testing.T) {
+	client, mutations := newWorkloadTestClient(t, map[string]string{
+		"/apis/batch/v1/namespaces/web/cronjobs/report": testCronJob,
+	})
+
+	result, err := client.SetSuspended(WithDryRun(context.Background()), "web", "CronJob", "report", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["dryRun"] != true || len(*mutations) != 1 || (*mutations)[0].dryRun != "All" {
+		t.Errorf("Expected a single dry-run patch, got %v and %v", result, *mutations)
+	}
+}
+
+// TestSetAutoscaling tests the read-only gate, dry runs and errors of creating an HPA for a workload
+func TestSetAutoscaling(t *testing.T) {
+	hpas := "/apis/autoscaling/v2/namespaces/web/horizontalpodautoscalers"
+	objects := map[string]string{
+		"/apis/apps/v1/namespaces/web/deployments/api": testDeployment,
+		hpas: `{"apiVersion":"autoscaling/v2","kind":"HorizontalPodAutoscaler","metadata":{"name":"api","namespace":"web"},` +
+			`"spec":{"scaleTargetRef":{"apiVersion":"apps/v1","kind":"Deployment","name":"api"},"minReplicas":2,"maxReplicas":5}}`,
+	}
+	client, mutations := newWorkloadTestClient(t, objects)
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.SetAutoscaling(ctx, "web", "Deployment", "api", 3, 2, 0, 0); err == nil || !strings.Contains(err.Error(), "must be at least minReplicas") {
+		t.Errorf("Expected an error for maxReplicas below minReplicas, got %v", err)
+	}
+	if _, err := readOnly.SetAutoscaling(ctx, "web", "Deployment", "api", 2, 5, 0, 0); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected a read-only client to refuse creating the HPA, got %v", err)
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("Expected refused changes not to reach the server, got %v", *mutations)
+	}
+
+	result, err := readOnly.SetAutoscaling(WithDryRun(ctx), "web", "Deployment", "api", 2, 5, 0, 0)
+	if err != nil {
+		t.Fatalf("Expected a dry run to pass in read-only mode, got %v", err)
+	}
+	if result["created"] != true || result["dryRun"] != true {
+		t.Errorf("Expected a dry-run creation, got %v", result)
+	}
+	if len(*mutations) != 1 || (*mutations)[0].method != http.MethodPost || (*mutations)[0].dryRun != "All" ||
+		!strings.Contains((*mutations)[0].body, `"averageUtilization":80`) {
+		t.Errorf("Expected a dry-run create with the default CPU target, got %v", *mutations)
+	}
+
+	objects[hpas] = conflictObject
+	if _, err := client.SetAutoscaling(ctx, "web", "Deployment", "api", 2, 5, 0, 0); err == nil || !strings.Contains(err.Error(), "failed to create HorizontalPodAutoscaler") {
+		t.Errorf("Expected the API server's error to be returned, got %v", err)
+	}
+}
+
+// TestSetImage tests the read-only gate, dry runs and errors of setting a container image
+func TestSetImage(t *testing.T) {
+	deployment := "/apis/apps/v1/namespaces/web/deployments/api"
+	objects := map[string]string{deployment: testDeployment}
+	client, mutations := newWorkloadTestClient(t, objects)
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.SetImage(ctx, "web", "Deployment", "api", "sidecar", "api:2"); err == nil || !strings.Contains(err.Error(), "available containers: app") {
+		t.Errorf("Expected an error listing the containers, got %v", err)
+	}
+	if result, err := client.SetImage(ctx, "web", "Deployment", "api", "", "api:1"); err != nil || result["changed"] != false {
+		t.Errorf("Expected an unchanged image not to be patched, got %v, %v", result, err)
+	}
+	if _, err := readOnly.SetImage(ctx, "web", "Deployment", "api", "app", "api:2"); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected a read-only client to refuse the patch, got %v", err)
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("Expected refused changes not to reach the server, got %v", *mutations)
+	}
+
+	result, err := readOnly.SetImage(WithDryRun(ctx), "web", "Deployment", "api", "app", "api:2")
+	if err != nil {
+		t.Fatalf("Expected a dry run to pass in read-only mode, got %v", err)
+	}
+	if result["dryRun"] != true || result["previousImage"] != "api:1" || result["changed"] != true {
+		t.Errorf("Expected a dry-run image change, got %v", result)
+	}
+	if len(*mutations) != 1 || (*mutations)[0].dryRun != "All" ||
+		(*mutations)[0].body != `{"spec":{"template":{"spec":{"containers":[{"image":"api:2","name":"app"}]}}}}` {
+		t.Errorf("Expected a dry-run patch of only the container image, got %v", *mutations)
+	}
+
+	delete(objects, deployment)
+	if _, err := client.SetImage(ctx, "web", "Deployment", "api", "app", "api:2"); err == nil || !strings.Contains(err.Error(), "failed to get Deployment api") {
+		t.Errorf("Expected an error for a missing Deployment, got %v", err)
+	}
+}
+
+// TestSetRolloutPaused tests the read-only gate, undo recording and errors of pausing and resuming a rollout
+func TestSetRolloutPaused(t *testing.T) {
+	deployment := "/apis/apps/v1/namespaces/web/deployments/api"
+	objects := map[string]string{deployment: testDeployment}
+	client, mutations := newWorkloadTestClient(t, objects)
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithSessionID(context.Background(), "s")
+
+	if _, err := readOnly.SetRolloutPaused(ctx, "web", "api", true); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected a read-only client to refuse pausing the rollout, got %v", err)
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("Expected refused changes not to reach the server, got %v", *mutations)
+	}
+
+	if _, err := client.SetRolloutPaused(ctx, "web", "api", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(*mutations) != 1 || (*mutations)[0].dryRun != "" || (*mutations)[0].body != `{"spec":{"paused":false}}` {
+		t.Errorf("Expected a single patch resuming the rollout, got %v", *mutations)
+	}
+	if len(client.undo.sessions["s"]) != 1 {
+		t.Errorf("Expected the change to be recorded for undo, got %v", client.undo.sessions)
+	}
+
+	objects[deployment] = conflictObject
+	if _, err := client.SetRolloutPaused(ctx, "web", "api", true); err == nil || !strings.Contains(err.Error(), "failed to pause rollout of Deployment web/api") {
+		t.Errorf("Expected the API server's error to be returned, got %v", err)
+	}
+	if len(client.undo.sessions["s"]) != 1 {
+		t.Errorf("Expected a failed change not to be recorded for undo, got %v", client.undo.sessions)
+	}
+}
+
+// TestSetSuspended tests the read-only gate, unsupported kinds and the Flux annotations of suspending
+func TestSetSuspended(t *testing.T) {
+	cronJob := "/apis/batch/v1/namespaces/web/cronjobs/report"
+	objects := map[string]string{
+		"/apis/apps/v1/namespaces/web/deployments/api": testDeployment,
+		cronJob: `{"apiVersion":"batch/v1","kind":"CronJob","metadata":{"name":"report","namespace":"web","resourceVersion":"1",` +
+			`"labels":{"kustomize.toolkit.fluxcd.io/name":"apps"},"annotations":{"kustomize.toolkit.fluxcd.io/reconcile":"disabled"}},"spec":{"suspend":true}}`,
+	}
+	client, mutations := newWorkloadTestClient(t, objects)
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.SetSuspended(ctx, "web", "Deployment", "api", true); err == nil || !strings.Contains(err.Error(), "kind Deployment does not support spec.suspend") {
+		t.Errorf("Expected an error for a kind without spec.suspend, got %v", err)
+	}
+	if _, err := readOnly.SetSuspended(ctx, "web", "CronJob", "report", false); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected a read-only client to refuse resuming, got %v", err)
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("Expected refused changes not to reach the server, got %v", *mutations)
+	}
+
+	if _, err := client.SetSuspended(ctx, "web", "CronJob", "report", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(*mutations) != 1 || (*mutations)[0].body != `{"metadata":{"annotations":{"kustomize.toolkit.fluxcd.io/reconcile":null}},"spec":{"suspend":false}}` {
+		t.Errorf("Expected resuming to re-enable Flux reconciliation, got %v", *mutations)
+	}
+
+	result, err := client.SetSuspended(ctx, "web", "CronJob", "report", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annotations := result["fluxAnnotations"].(map[string]interface{}); annotations[fluxKustomizeReconcileAnnot] != fluxReconcileDisabledValue {
+		t.Errorf("Expected suspending to disable Flux reconciliation, got %v", result)
+	}
+
+	delete(objects, cronJob)
+	if _, err := client.SetSuspended(ctx, "web", "CronJob", "report", true); err == nil || !strings.Contains(err.Error(), "failed to get CronJob report") {
+		t.Errorf("Expected an error for a missing CronJob, got %v", err)
+	}
+}
+
+// TestScaleResource tests validation, the read-only gate, dry runs and errors of scaling a workload
+func TestScaleResource(t *testing.T) {
+	scale := "/apis/apps/v1/namespaces/web/deployments/api/scale"
+	objects := map[string]string{
+		"/apis/apps/v1/namespaces/web/deployments/api": testDeployment,
+		scale: `{"apiVersion":"autoscaling/v1","kind":"Scale","metadata":{"name":"api","namespace":"web"},"spec":{"replicas":2}}`,
+	}
+	client, mutations := newWorkloadTestClient(t, objects)
+	readOnly, err := client.WithReadOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.ScaleResource(ctx, "web", "Deployment", "api", -1); err == nil || !strings.Contains(err.Error(), "replicas must be zero or greater") {
+		t.Errorf("Expected an error for negative replicas, got %v", err)
+	}
+	if _, err := readOnly.ScaleResource(ctx, "web", "Deployment", "api", 3); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected a read-only client to refuse scaling, got %v", err)
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("Expected refused changes not to reach the server, got %v", *mutations)
+	}
+
+	result, err := readOnly.ScaleResource(WithDryRun(ctx), "web", "Deployment", "api", 3)
+	if err != nil {
+		t.Fatalf("Expected a dry run to pass in read-only mode, got %v", err)
+	}
+	if result["dryRun"] != true || result["previousReplicas"] != int64(2) {
+		t.Errorf("Expected a dry run reporting the previous replicas, got %v", result)
+	}
+	if len(*mutations) != 1 || (*mutations)[0].path != scale || (*mutations)[0].dryRun != "All" || (*mutations)[0].body != `{"spec":{"replicas":3}}` {
+		t.Errorf("Expected a dry-run patch of the scale subresource, got %v", *mutations)
+	}
+
+	objects[scale] = conflictObject
+	if _, err := client.ScaleResource(ctx, "web", "Deployment", "api", 3); err == nil || !strings.Contains(err.Error(), "failed to get scale of Deployment web/api") {
+		t.Errorf("Expected the API server's error to be returned, got %v", err)
+	}
+	if _, err := client.ScaleResource(ctx, "web", "Widget", "api", 3); err == nil {
+		t.Error("Expected an error for an unknown kind")
+	}
+}
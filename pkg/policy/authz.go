@@ -0,0 +1,165 @@
+package policy
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"path"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AuthConfig maps the bearer tokens of MCP clients to their identity and
+// permissions.
+type AuthConfig struct {
+	Clients []ClientPolicy `json:"clients"`
+}
+
+// ClientPolicy describes an MCP client: the token it authenticates with, the
+// roles it holds, the tools it may call and the namespaces it may act in.
+// Tools and namespaces are glob patterns as understood by path.Match; an
+// empty list allows everything. WriteNamespaces restricts the namespaces of
+// mutating tools and defaults to Namespaces. ImpersonateUsers and
+// ImpersonateGroups list the users, with service accounts given as
+// system:serviceaccount:<namespace>:<name>, and the groups the client may
+// impersonate; unlike the other lists, an empty one allows none.
+type ClientPolicy struct {
+	Name              string   `json:"name"`
+	Token             string   `json:"token,omitempty"`
+	TokenEnv          string   `json:"tokenEnv,omitempty"`
+	Roles             []string `json:"roles,omitempty"`
+	Tools             []string `json:"tools,omitempty"`
+	Namespaces        []string `json:"namespaces,omitempty"`
+	WriteNamespaces   []string `json:"writeNamespaces,omitempty"`
+	ImpersonateUsers  []string `json:"impersonateUsers,omitempty"`
+	ImpersonateGroups []string `json:"impersonateGroups,omitempty"`
+}
+
+// LoadAuthConfig reads client policies from a YAML or JSON file. Tokens may be
+// given inline or read from the environment variable named by tokenEnv.
+// Returns the parsed configuration, or an error.
+func LoadAuthConfig(file string) (*AuthConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %w", err)
+	}
+	config := &AuthConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config: %w", err)
+	}
+
+	for i := range config.Clients {
+		client := &config.Clients[i]
+		if client.Name == "" {
+			return nil, fmt.Errorf("invalid client %d: name is required", i+1)
+		}
+		if client.TokenEnv != "" {
+			client.Token = os.Getenv(client.TokenEnv)
+		}
+		if client.Token == "" {
+			return nil, fmt.Errorf("invalid client %s: token or tokenEnv is required", client.Name)
+		}
+		patterns := append(append(append([]string{}, client.Tools...), client.Namespaces...), client.WriteNamespaces...)
+		for _, pattern := range append(append(patterns, client.ImpersonateUsers...), client.ImpersonateGroups...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid client %s: bad pattern %q: %w", client.Name, pattern, err)
+			}
+		}
+	}
+	return config, nil
+}
+
+// Authenticate returns the client holding token, or false if no client does.
+func (c *AuthConfig) Authenticate(token string) (*ClientPolicy, bool) {
+	if c == nil || token == "" {
+		return nil, false
+	}
+	for i := range c.Clients {
+		if subtle.ConstantTimeCompare([]byte(c.Clients[i].Token), []byte(token)) == 1 {
+			return &c.Clients[i], true
+		}
+	}
+	return nil, false
+}
+
+// AllowsTool reports whether the client may call the named tool.
+func (p *ClientPolicy) AllowsTool(name string) bool {
+	return matchesAny(p.Tools, name)
+}
+
+// AllowsNamespace reports whether the client may act in namespace, using the
+// write scope for mutating tools.
+func (p *ClientPolicy) AllowsNamespace(namespace string, write bool) bool {
+	return matchesAny(p.namespaceScope(write), namespace)
+}
+
+// NamespaceRestricted reports whether the client is limited to a subset of
+// namespaces, and so must name the namespace of each call explicitly.
+func (p *ClientPolicy) NamespaceRestricted(write bool) bool {
+	scope := p.namespaceScope(write)
+	return len(scope) > 0 && !containsPattern(scope, "*")
+}
+
+// AllowsImpersonating reports whether the client may impersonate user along
+// with groups.
+func (p *ClientPolicy) AllowsImpersonating(user string, groups []string) bool {
+	if len(p.ImpersonateUsers) == 0 || !matchesAny(p.ImpersonateUsers, user) {
+		return false
+	}
+	for _, group := range groups {
+		if len(p.ImpersonateGroups) == 0 || !matchesAny(p.ImpersonateGroups, group) {
+			return false
+		}
+	}
+	return true
+}
+
+// namespaceScope returns the namespace patterns for reads or writes.
+func (p *ClientPolicy) namespaceScope(write bool) []string {
+	if write && len(p.WriteNamespaces) > 0 {
+		return p.WriteNamespaces
+	}
+	return p.Namespaces
+}
+
+// matchesAny reports whether value matches one of patterns; an empty list
+// matches everything.
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPattern reports whether patterns includes pattern literally.
+func containsPattern(patterns []string, pattern string) bool {
+	for _, p := range patterns {
+		if p == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// clientKey is the context key for the authenticated MCP client.
+type clientKey struct{}
+
+// WithClient returns a context identifying the caller as client and granting
+// it the client's roles.
+func WithClient(ctx context.Context, client *ClientPolicy) context.Context {
+	ctx = context.WithValue(ctx, clientKey{}, client)
+	return WithRoles(ctx, client.Roles...)
+}
+
+// ClientFromContext returns the authenticated client of ctx, or false if the
+// caller did not authenticate.
+func ClientFromContext(ctx context.Context) (*ClientPolicy, bool) {
+	client, ok := ctx.Value(clientKey{}).(*ClientPolicy)
+	return client, ok && client != nil
+}
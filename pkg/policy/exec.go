@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ExecPolicy restricts the commands execInPod may run. Patterns are globs in
+// which "*" matches any text, including slashes, and "?" any one character.
+// The first word of a pattern matches the executable, the base name of the
+// first word of the command, e.g. "cat" or "ls". The rest of a pattern with
+// spaces matches the arguments: an allow pattern must match all of them, e.g.
+// "curl -s http://localhost*", while a deny pattern refuses a command when it
+// matches any run of consecutive arguments, so "cat /var/run/secrets/*" also
+// refuses "cat -- /etc/hostname /var/run/secrets/token". Deny patterns take
+// precedence; when no allow patterns are given, every command that is not
+// denied may run.
+type ExecPolicy struct {
+	allow []execPattern
+	deny  []execPattern
+}
+
+// execPattern is a compiled exec glob. arguments is nil for a pattern that
+// only names the executable.
+type execPattern struct {
+	glob       string
+	executable *regexp.Regexp
+	arguments  *regexp.Regexp
+}
+
+// NewExecPolicy compiles allow and deny patterns.
+// Returns the policy, or an error naming the first malformed pattern.
+func NewExecPolicy(allow, deny []string) (*ExecPolicy, error) {
+	policy := &ExecPolicy{}
+	for _, list := range []struct {
+		globs []string
+		into  *[]execPattern
+	}{{allow, &policy.allow}, {deny, &policy.deny}} {
+		for _, glob := range list.globs {
+			pattern, err := compileExecPattern(glob)
+			if err != nil {
+				return nil, err
+			}
+			*list.into = append(*list.into, pattern)
+		}
+	}
+	return policy, nil
+}
+
+// compileExecPattern converts a glob into anchored regular expressions for
+// the executable and the arguments.
+func compileExecPattern(glob string) (execPattern, error) {
+	glob = strings.TrimSpace(glob)
+	if glob == "" {
+		return execPattern{}, fmt.Errorf("invalid exec pattern: must not be empty")
+	}
+	executable, arguments, hasArguments := strings.Cut(glob, " ")
+	pattern := execPattern{glob: glob}
+	var err error
+	if pattern.executable, err = compileGlob(executable); err != nil {
+		return execPattern{}, fmt.Errorf("invalid exec pattern %q: %w", glob, err)
+	}
+	if hasArguments {
+		if pattern.arguments, err = compileGlob(strings.TrimSpace(arguments)); err != nil {
+			return execPattern{}, fmt.Errorf("invalid exec pattern %q: %w", glob, err)
+		}
+	}
+	return pattern, nil
+}
+
+// compileGlob converts a glob into an anchored regular expression.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	expr := regexp.QuoteMeta(glob)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return regexp.Compile("^" + expr + "$")
+}
+
+// Check reports whether command may run under the policy.
+// Returns nil when it may, or an error naming the rule that refuses it.
+func (p *ExecPolicy) Check(command []string) error {
+	if len(command) == 0 || command[0] == "" {
+		return fmt.Errorf("a command is required")
+	}
+	if p == nil {
+		return nil
+	}
+	if glob, ok := matchCommand(p.deny, command, true); ok {
+		return fmt.Errorf("command %q is denied by exec pattern %q", strings.Join(command, " "), glob)
+	}
+	if len(p.allow) == 0 {
+		return nil
+	}
+	if _, ok := matchCommand(p.allow, command, false); !ok {
+		return fmt.Errorf("command %q is not in the exec allowlist", strings.Join(command, " "))
+	}
+	return nil
+}
+
+// matchCommand returns the glob of the first pattern that matches command.
+// With anyArguments, the arguments of a pattern may match any run of
+// consecutive arguments of the command rather than all of them.
+func matchCommand(patterns []execPattern, command []string, anyArguments bool) (string, bool) {
+	executable := path.Base(command[0])
+	arguments := command[1:]
+	for _, pattern := range patterns {
+		if !pattern.executable.MatchString(executable) {
+			continue
+		}
+		if pattern.arguments == nil || pattern.arguments.MatchString(strings.Join(arguments, " ")) {
+			return pattern.glob, true
+		}
+		if !anyArguments {
+			continue
+		}
+		for start := range arguments {
+			for end := start + 1; end <= len(arguments); end++ {
+				if pattern.arguments.MatchString(strings.Join(arguments[start:end], " ")) {
+					return pattern.glob, true
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,42 @@
+package policy
+
+import "testing"
+
+// TestExecPolicyCheck tests allowing and denying commands by executable and command line patterns
+func TestExecPolicyCheck(t *testing.T) {
+	policy, err := NewExecPolicy([]string{"ls", "cat", "env", "curl -s http://localhost*"}, []string{"cat /var/run/secrets/*"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		command []string
+		allowed bool
+	}{
+		{[]string{"ls", "-la", "/tmp"}, true},
+		{[]string{"/bin/cat", "/etc/hosts"}, true},
+		{[]string{"cat", "/var/run/secrets/kubernetes.io/serviceaccount/token"}, false},
+		{[]string{"/bin/cat", "/var/run/secrets/kubernetes.io/serviceaccount/token"}, false},
+		{[]string{"cat", "/etc/hostname", "/var/run/secrets/kubernetes.io/serviceaccount/token"}, false},
+		{[]string{"cat", "--", "/var/run/secrets/kubernetes.io/serviceaccount/token"}, false},
+		{[]string{"cat", "/etc/hostname", "/etc/hosts"}, true},
+		{[]string{"curl", "-s", "http://localhost:8080/healthz"}, true},
+		{[]string{"curl", "http://example.com"}, false},
+		{[]string{"curl", "-v", "-s", "http://localhost:8080/healthz"}, false},
+		{[]string{"sh", "-c", "ls"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if err := policy.Check(tt.command); (err == nil) != tt.allowed {
+			t.Errorf("Check(%q) = %v, want allowed %v", tt.command, err, tt.allowed)
+		}
+	}
+
+	var open *ExecPolicy
+	if err := open.Check([]string{"sh"}); err != nil {
+		t.Errorf("Expected a nil policy to allow any command, got %v", err)
+	}
+	if _, err := NewExecPolicy([]string{" "}, nil); err == nil {
+		t.Error("Expected an error for an empty pattern")
+	}
+}
@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// maxCronWindowDuration bounds the duration of recurring freeze windows, and
+// with it how far back the schedule is searched for the window's start.
+const maxCronWindowDuration = 7 * 24 * time.Hour
+
+// FreezeConfig lists the change freeze windows during which mutating tools
+// refuse to run. Callers holding OverrideRole may still run them.
+type FreezeConfig struct {
+	Windows      []FreezeWindow `json:"windows"`
+	OverrideRole string         `json:"overrideRole,omitempty"`
+}
+
+// FreezeWindow is a single change freeze: either an explicit interval from
+// Start to End, or a recurring window that begins whenever Cron fires and lasts
+// for Duration, evaluated in Timezone (default UTC).
+type FreezeWindow struct {
+	Name     string     `json:"name"`
+	Start    *time.Time `json:"start,omitempty"`
+	End      *time.Time `json:"end,omitempty"`
+	Cron     string     `json:"cron,omitempty"`
+	Duration string     `json:"duration,omitempty"`
+	Timezone string     `json:"timezone,omitempty"`
+
+	schedule *cronSchedule
+	duration time.Duration
+	location *time.Location
+}
+
+// LoadFreezeConfig reads a freeze configuration from a YAML or JSON file and
+// validates its windows.
+// Returns the parsed configuration, or an error.
+func LoadFreezeConfig(path string) (*FreezeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read freeze config: %w", err)
+	}
+	config := &FreezeConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse freeze config: %w", err)
+	}
+	for i := range config.Windows {
+		if err := config.Windows[i].init(); err != nil {
+			return nil, fmt.Errorf("invalid freeze window %d: %w", i+1, err)
+		}
+	}
+	return config, nil
+}
+
+// init validates the window and prepares its schedule.
+func (w *FreezeWindow) init() error {
+	if w.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if w.Cron == "" {
+		if w.Start == nil || w.End == nil {
+			return fmt.Errorf("window %s requires either start and end, or cron and duration", w.Name)
+		}
+		if !w.End.After(*w.Start) {
+			return fmt.Errorf("window %s must end after it starts", w.Name)
+		}
+		return nil
+	}
+
+	schedule, err := parseCron(w.Cron)
+	if err != nil {
+		return fmt.Errorf("window %s: %w", w.Name, err)
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil || duration <= 0 || duration > maxCronWindowDuration {
+		return fmt.Errorf("window %s: duration must be a positive duration of at most %s, e.g. 2h or 90m", w.Name, maxCronWindowDuration)
+	}
+	location := time.UTC
+	if w.Timezone != "" {
+		if location, err = time.LoadLocation(w.Timezone); err != nil {
+			return fmt.Errorf("window %s: invalid timezone: %w", w.Name, err)
+		}
+	}
+	w.schedule, w.duration, w.location = schedule, duration, location
+	return nil
+}
+
+// ActiveWindow returns the freeze window in effect at now and the time it
+// ends, or false if no window is active.
+func (c *FreezeConfig) ActiveWindow(now time.Time) (*FreezeWindow, time.Time, bool) {
+	if c == nil {
+		return nil, time.Time{}, false
+	}
+	for i := range c.Windows {
+		window := &c.Windows[i]
+		if window.schedule == nil {
+			if window.Start != nil && window.End != nil && !now.Before(*window.Start) && now.Before(*window.End) {
+				return window, *window.End, true
+			}
+			continue
+		}
+		// A recurring window is active if the schedule fired within its duration
+		if start, ok := window.schedule.lastFire(now.In(window.location), window.duration); ok {
+			if end := start.Add(window.duration); now.Before(end) {
+				return window, end, true
+			}
+		}
+	}
+	return nil, time.Time{}, false
+}
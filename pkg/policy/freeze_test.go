@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFreezeConfigActiveWindow tests explicit and recurring freeze windows
+func TestFreezeConfigActiveWindow(t *testing.T) {
+	start := time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2027, 1, 5, 0, 0, 0, 0, time.UTC)
+	config := &FreezeConfig{Windows: []FreezeWindow{
+		{Name: "holidays", Start: &start, End: &end},
+		// Fridays from 18:00 for the weekend
+		{Name: "weekend", Cron: "0 18 * * 5", Duration: "62h"},
+	}}
+	for i := range config.Windows {
+		if err := config.Windows[i].init(); err != nil {
+			t.Fatalf("Unexpected error initializing window: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		window string
+	}{
+		{"inside interval", time.Date(2026, 12, 24, 12, 0, 0, 0, time.UTC), "holidays"},
+		{"interval end is exclusive", end, ""},
+		{"friday evening", time.Date(2026, 10, 16, 18, 30, 0, 0, time.UTC), "weekend"},
+		{"sunday", time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC), "weekend"},
+		{"monday morning", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), ""},
+		{"friday afternoon", time.Date(2026, 10, 16, 17, 59, 0, 0, time.UTC), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, _, ok := config.ActiveWindow(tt.now)
+			switch {
+			case tt.window == "" && ok:
+				t.Errorf("Expected no active window, got %s", window.Name)
+			case tt.window != "" && (!ok || window.Name != tt.window):
+				t.Errorf("Expected window %s to be active, got %v", tt.window, window)
+			}
+		})
+	}
+}
+
+// TestParseCron tests parsing of cron fields and rejection of invalid expressions
+func TestParseCron(t *testing.T) {
+	schedule, err := parseCron("*/15 9-17 * * 1-5")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !schedule.matches(time.Date(2026, 10, 14, 9, 45, 0, 0, time.UTC)) {
+		t.Error("Expected Wednesday 09:45 to match")
+	}
+	if schedule.matches(time.Date(2026, 10, 14, 9, 50, 0, 0, time.UTC)) {
+		t.Error("Expected 09:50 not to match a 15 minute step")
+	}
+	if schedule.matches(time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)) {
+		t.Error("Expected Saturday not to match")
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * * * mon", "*/0 * * * *"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
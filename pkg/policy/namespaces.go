@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// NamespacePolicy restricts the namespaces tools may act in. Patterns are
+// globs as understood by path.Match, e.g. "team-*". Deny patterns take
+// precedence; when no allow patterns are given, every namespace that is not
+// denied may be used.
+type NamespacePolicy struct {
+	allow []string
+	deny  []string
+}
+
+// NewNamespacePolicy validates allow and deny patterns.
+// Returns the policy, or an error naming the first malformed pattern.
+func NewNamespacePolicy(allow, deny []string) (*NamespacePolicy, error) {
+	for _, pattern := range append(append([]string{}, allow...), deny...) {
+		if strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("invalid namespace pattern: must not be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern %q: %w", pattern, err)
+		}
+	}
+	return &NamespacePolicy{allow: allow, deny: deny}, nil
+}
+
+// Check reports whether namespace may be used under the policy.
+// Returns nil when it may, or an error naming the rule that refuses it.
+func (p *NamespacePolicy) Check(namespace string) error {
+	if p == nil {
+		return nil
+	}
+	for _, pattern := range p.deny {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return fmt.Errorf("namespace %q is denied by namespace pattern %q", namespace, pattern)
+		}
+	}
+	if !matchesAny(p.allow, namespace) {
+		return fmt.Errorf("namespace %q is not in the namespace allowlist", namespace)
+	}
+	return nil
+}
+
+// Restricted reports whether the policy excludes any namespace, in which case
+// calls spanning all namespaces must be refused.
+func (p *NamespacePolicy) Restricted() bool {
+	if p == nil {
+		return false
+	}
+	return len(p.deny) > 0 || (len(p.allow) > 0 && !containsPattern(p.allow, "*"))
+}
@@ -0,0 +1,49 @@
+package policy
+
+import "testing"
+
+// TestNamespacePolicyCheck tests allowing and denying namespaces by glob patterns
+func TestNamespacePolicyCheck(t *testing.T) {
+	policy, err := NewNamespacePolicy([]string{"team-*", "shared"}, []string{"team-*-prod"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		namespace string
+		allowed   bool
+	}{
+		{"team-a", true},
+		{"shared", true},
+		{"team-a-prod", false},
+		{"kube-system", false},
+		{"default", false},
+	}
+	for _, tt := range tests {
+		if err := policy.Check(tt.namespace); (err == nil) != tt.allowed {
+			t.Errorf("Check(%q) = %v, want allowed %v", tt.namespace, err, tt.allowed)
+		}
+	}
+	if !policy.Restricted() {
+		t.Error("Expected an allowlist to restrict namespaces")
+	}
+
+	denyOnly, err := NewNamespacePolicy(nil, []string{"kube-*"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if denyOnly.Check("web") != nil || denyOnly.Check("kube-system") == nil || !denyOnly.Restricted() {
+		t.Error("Expected a denylist to allow every other namespace and restrict namespaces")
+	}
+
+	var open *NamespacePolicy
+	if open.Check("kube-system") != nil || open.Restricted() {
+		t.Error("Expected a nil policy to allow any namespace")
+	}
+	if all, _ := NewNamespacePolicy([]string{"*"}, nil); all.Restricted() {
+		t.Error("Expected an allowlist of \"*\" not to restrict namespaces")
+	}
+	if _, err := NewNamespacePolicy([]string{"team-["}, nil); err == nil {
+		t.Error("Expected an error for a malformed pattern")
+	}
+}
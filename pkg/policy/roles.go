@@ -0,0 +1,32 @@
+package policy
+
+import "context"
+
+// rolesKey is the context key for the roles granted to the caller of a request.
+type rolesKey struct{}
+
+// WithRoles returns a context granting the given roles to the caller, in
+// addition to any roles already granted.
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	if len(roles) == 0 {
+		return ctx
+	}
+	granted := append(append([]string{}, Roles(ctx)...), roles...)
+	return context.WithValue(ctx, rolesKey{}, granted)
+}
+
+// Roles returns the roles granted to the caller.
+func Roles(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey{}).([]string)
+	return roles
+}
+
+// HasRole reports whether the caller was granted role.
+func HasRole(ctx context.Context, role string) bool {
+	for _, granted := range Roles(ctx) {
+		if granted == role {
+			return true
+		}
+	}
+	return false
+}
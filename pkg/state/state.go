@@ -0,0 +1,94 @@
+// Package state persists server-side state, such as saved queries, undo logs
+// and usage history, to a local bbolt file, so that restarting the server
+// does not wipe what it accumulated during an incident.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTimeout bounds how long Open waits for the file lock, which another
+// server using the same file holds.
+const openTimeout = 5 * time.Second
+
+// Store holds JSON values under string keys in named buckets of a bbolt
+// file. It is safe for concurrent use.
+type Store struct {
+	db   *bolt.DB
+	path string
+}
+
+// Open opens the state file at path, creating it if it does not exist.
+// Returns the store, or an error if the file cannot be opened or is locked
+// by another process.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file %s: %w", path, err)
+	}
+	return &Store{db: db, path: path}, nil
+}
+
+// Path returns the path of the state file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Close closes the state file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put stores value, serialized to JSON, under key in bucket, creating the
+// bucket if needed.
+func (s *Store) Put(bucket, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s/%s: %w", bucket, key, err)
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to persist %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Delete removes key from bucket. Deleting a missing key is not an error.
+func (s *Store) Delete(bucket, key string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// ForEach calls fn with each key of bucket and its JSON value, in key order,
+// stopping at the first error fn returns. The value is only valid during the
+// call. A missing bucket has no keys.
+func (s *Store) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(key, value []byte) error {
+			return fn(string(key), value)
+		})
+	})
+}
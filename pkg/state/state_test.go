@@ -0,0 +1,54 @@
+package state
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// TestStore tests that values survive reopening the state file and are listed in key order
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"b", "a", "c"} {
+		if err := store.Put("queries", key, map[string]string{"name": key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Delete("queries", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("missing", "c"); err != nil {
+		t.Errorf("Expected deleting from a missing bucket to succeed, got %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	var names []string
+	err = store.ForEach("queries", func(key string, value []byte) error {
+		var query map[string]string
+		if err := json.Unmarshal(value, &query); err != nil {
+			return err
+		}
+		names = append(names, query["name"])
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("Expected a and b after reopening, got %v", names)
+	}
+	if err := store.ForEach("missing", func(string, []byte) error { return nil }); err != nil {
+		t.Errorf("Expected a missing bucket to have no keys, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetFlowControlTool creates a tool for inspecting API Priority and Fairness.
+// It defines the tool's name and description; the tool takes no parameters.
+func GetFlowControlTool() mcp.Tool {
+	return mcp.NewTool(
+		"getFlowControl",
+		mcp.WithDescription("Report API Priority and Fairness (APF) on the API server: the PriorityLevelConfigurations with their concurrency shares and queuing, "+
+			"the FlowSchemas in matching order with the priority level each routes to, and the requests currently queued and executing and those rejected so far "+
+			"per priority level and flow schema. Use this when clients receive 429 Too Many Requests responses. "+
+			"The metrics are those of the API server instance that answered and require access to /metrics."),
+	)
+}
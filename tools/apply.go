@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ApplyResourceTool creates a tool for applying manifests with server-side apply.
+// It defines the tool's name, description, and parameters for the manifest,
+// the field manager and the apply options.
+func ApplyResourceTool() mcp.Tool {
+	return mcp.NewTool(
+		"applyResource",
+		mcp.WithDescription("Apply a YAML or JSON manifest with server-side apply, like kubectl apply --server-side. "+
+			"The manifest may hold several YAML documents or a List; objects are applied in order. "+
+			"Fields owned by another field manager cause a conflict error unless force is set. "+
+			"Use dryRun to validate the manifest and preview the result without changing the cluster."),
+		mcp.WithString("manifest", mcp.Required(), mcp.Description("The YAML or JSON manifest; each object needs apiVersion, kind and metadata.name")),
+		mcp.WithString("namespace", mcp.Description("Overrides the namespace of namespaced objects (default: the manifest's namespace, else 'default')")),
+		mcp.WithString("fieldManager", mcp.Description("The field manager that owns the applied fields (default: 'k8s-mcp-server')")),
+		mcp.WithBoolean("force", mcp.Description("Take ownership of fields that conflict with other field managers (default: false)")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the apply as a server-side dry run without persisting it (default: false)")),
+		withRolloutWait(),
+	)
+}
+
+// PreviewAdmissionTool creates a tool for previewing what admission does to a manifest.
+// It defines the tool's name, description, and parameters for the manifest
+// and whether the admitted objects are returned.
+func PreviewAdmissionTool() mcp.Tool {
+	return mcp.NewTool(
+		"previewAdmission",
+		mcp.WithDescription("Submit a YAML or JSON manifest as a server-side dry run and show exactly what admission would make of it, without changing the cluster: "+
+			"the fully defaulted and mutated objects, the fields that were added, changed or removed compared to the manifest, injected sidecar containers, "+
+			"and the mutating and validating webhooks that match. For workloads, a pod built from the pod template is previewed too, since sidecar injectors act on pods. "+
+			"Objects admission would reject report the rejection instead."),
+		mcp.WithString("manifest", mcp.Required(), mcp.Description("The YAML or JSON manifest; each object needs apiVersion, kind and metadata.name")),
+		mcp.WithString("namespace", mcp.Description("Overrides the namespace of namespaced objects (default: the manifest's namespace, else 'default')")),
+		mcp.WithBoolean("includeObject", mcp.Description("Return the full admitted objects in addition to the changes (default: true)")),
+		withOutputFormat(),
+	)
+}
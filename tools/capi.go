@@ -0,0 +1,17 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetCapiInventoryTool creates a tool for summarizing Cluster API machine inventory.
+// It defines the tool's name, description, and parameters for the namespace and cluster name.
+func GetCapiInventoryTool() mcp.Tool {
+	return mcp.NewTool(
+		"getCapiInventory",
+		mcp.WithDescription("Summarize Cluster API (CAPI) Clusters, MachineDeployments and Machines on a management cluster, "+
+			"with phases, failure messages and unhealthy conditions. Requires Cluster API to be installed."),
+		mcp.WithString("namespace", mcp.Description("The namespace to inspect. If empty, all namespaces are inspected.")),
+		mcp.WithString("clusterName", mcp.Description("Only report this Cluster and its MachineDeployments and Machines")),
+	)
+}
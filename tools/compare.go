@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CompareNamespacesTool creates a tool for comparing the workloads of two namespaces.
+// It defines the tool's name, description, and parameters for the two
+// namespaces and whether configuration is compared.
+func CompareNamespacesTool() mcp.Tool {
+	return mcp.NewTool(
+		"compareNamespaces",
+		mcp.WithDescription("Compare two namespaces for parity, e.g. staging vs prod. Matches Deployments, StatefulSets and DaemonSets by kind and name and reports "+
+			"workloads that exist in only one namespace, plus replica, container image and environment variable differences for those in both. "+
+			"With includeConfig, ConfigMaps and Secrets are compared by key; Secret values are never returned."),
+		mcp.WithString("firstNamespace", mcp.Required(), mcp.Description("The first namespace, e.g. staging")),
+		mcp.WithString("secondNamespace", mcp.Required(), mcp.Description("The second namespace, e.g. prod")),
+		mcp.WithBoolean("includeConfig", mcp.Description("Also compare ConfigMaps and Secrets by key (default: true)")),
+		withExport(),
+	)
+}
@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetConditionsTool creates a tool for getting the normalized status conditions of resources.
+// It defines the tool's name, description, and parameters for the kinds,
+// scope and filters of the conditions to return.
+func GetConditionsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getConditions",
+		mcp.WithDescription("Get the status.conditions of resources of one or more kinds, normalized to kind, name, namespace, type, status, reason, message, "+
+			"lastTransitionTime and age. Each condition is flagged abnormal when it is Unknown, False for types like Ready or Available, "+
+			"or True for problem types like MemoryPressure or ReplicaFailure; abnormal conditions are listed first. "+
+			"Use abnormalOnly to answer 'what is unhealthy' directly."),
+		mcp.WithString("kinds", mcp.Required(), mcp.Description("Comma-separated kinds to inspect, e.g. 'Deployment,Pod' or 'Node'")),
+		mcp.WithString("namespace", mcp.Description("The namespace to inspect; if empty, namespaced kinds are inspected across all namespaces")),
+		mcp.WithString("name", mcp.Description("Only inspect the object with this name of each kind")),
+		mcp.WithString("labelSelector", mcp.Description("A label selector to filter the objects (ignored when name is set)")),
+		mcp.WithString("status", mcp.Description("Only return conditions with this status"), mcp.Enum("True", "False", "Unknown")),
+		mcp.WithBoolean("abnormalOnly", mcp.Description("Only return abnormal conditions (default: false)")),
+	)
+}
@@ -0,0 +1,15 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ListContextsTool creates a tool for listing the kubeconfig contexts the server can query.
+// It defines the tool's name and description; the tool takes no parameters.
+func ListContextsTool() mcp.Tool {
+	return mcp.NewTool(
+		"listContexts",
+		mcp.WithDescription("List the kubeconfig contexts this server can query, with the cluster, API server, user and namespace of each. "+
+			"Every Kubernetes tool accepts a context argument naming one of them; calls without it use the session's pinned context or the server's default context."),
+	)
+}
@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CollectDiagnosticsTool creates a tool for gathering a support bundle for a namespace or workload.
+// It defines the tool's name, description, and parameters for the target,
+// log depth and destination of the bundle.
+func CollectDiagnosticsTool() mcp.Tool {
+	return mcp.NewTool(
+		"collectDiagnostics",
+		mcp.WithDescription("Gather a support bundle for a namespace, or for one workload when kind and name are set, into a tar.gz: "+
+			"manifests (without Secrets), events, recent container logs including previous instances of restarted containers, "+
+			"and pod and node metrics snapshots. The bundle is written to the server's export directory or bucket, "+
+			"and a reference with a summary of its contents is returned. Collection errors are listed in the summary."),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace to collect diagnostics for")),
+		mcp.WithString("kind", mcp.Description("The kind of a workload to limit the bundle to, e.g. Deployment; requires name")),
+		mcp.WithString("name", mcp.Description("The name of the workload; requires kind")),
+		mcp.WithNumber("tailLines", mcp.Description("Number of log lines to collect per container (default: 500; 0 for the full log, capped at 1 MiB)")),
+		mcp.WithString("destination", mcp.Description("Export target for the bundle ('file' or 's3'); defaults to the configured export directory, then bucket"),
+			mcp.Enum("file", "s3")),
+	)
+}
@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// AnalyzeEphemeralStorageTool creates a tool for explaining ephemeral-storage evictions.
+// It defines the tool's name, description, and parameters for the namespace,
+// node, sampling interval, and number of consumers to report.
+func AnalyzeEphemeralStorageTool() mcp.Tool {
+	return mcp.NewTool(
+		"analyzeEphemeralStorage",
+		mcp.WithDescription("Explain 'pod evicted: ephemeral storage' incidents in one call. Finds pods evicted for ephemeral storage and classifies the cause "+
+			"(node pressure, container or pod limit, emptyDir sizeLimit), then reports node filesystem usage, DiskPressure and the containers with the largest "+
+			"writable layers and logs from kubelet stats. Set sampleSeconds to measure writable layer growth between two samples. Requires get permission on nodes/proxy."),
+		mcp.WithString("namespace", mcp.Description("Only look for evicted pods in this namespace. If empty, all namespaces are searched.")),
+		mcp.WithString("nodeName", mcp.Description("Only analyze this node. If empty, nodes with ephemeral-storage evictions or DiskPressure are analyzed.")),
+		mcp.WithNumber("sampleSeconds", mcp.Description("Seconds between two kubelet samples used to measure writable layer growth (default: 0, single sample; max: 60)")),
+		mcp.WithNumber("topN", mcp.Description("Number of containers and emptyDir volumes to report per node (default: 10)")),
+	)
+}
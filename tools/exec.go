@@ -0,0 +1,23 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ExecInPodTool creates a tool for running a command in a pod container.
+// It defines the tool's name, description, and parameters for the pod,
+// container, command and timeout.
+func ExecInPodTool() mcp.Tool {
+	return mcp.NewTool(
+		"execInPod",
+		mcp.WithDescription("Run a non-interactive command in a container of a running pod, like 'kubectl exec' without a TTY or stdin, "+
+			"and return its stdout, stderr and exit code. The command is not run through a shell unless it names one. "+
+			"The server operator may restrict which commands can run with an allowlist and denylist."),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the pod")),
+		mcp.WithString("podName", mcp.Required(), mcp.Description("The name of the pod")),
+		mcp.WithString("container", mcp.Description("The container to run the command in; optional if the pod has one container or a default container")),
+		mcp.WithArray("command", mcp.Required(), mcp.Description("The command and its arguments, e.g. [\"cat\", \"/etc/resolv.conf\"]"),
+			mcp.WithStringItems()),
+		mcp.WithNumber("timeoutSeconds", mcp.Description("Seconds to wait for the command to finish (default: 30, max: 300)")),
+	)
+}
@@ -0,0 +1,18 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withExport adds the exportTo and exportName parameters to tools whose output
+// may be large, letting callers write the output to a file or bucket
+// configured on the server and get back a reference instead.
+func withExport() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithString("exportTo",
+			mcp.Description("Write the output to a server-configured export target ('file' or 's3') and return a reference instead of the output"),
+			mcp.Enum("file", "s3"),
+		)(t)
+		mcp.WithString("exportName", mcp.Description("File name for the exported output (default: the tool name and a timestamp)"))(t)
+	}
+}
@@ -0,0 +1,19 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetImagePullReportTool creates a tool for reporting image pull performance
+// and failures. It defines the tool's name, description, and parameters for
+// the namespace and time window.
+func GetImagePullReportTool() mcp.Tool {
+	return mcp.NewTool(
+		"getImagePullReport",
+		mcp.WithDescription("Aggregate the kubelet's Pulling, Pulled, Failed and BackOff image events over a time window into pull durations and "+
+			"failure rates per image, per registry and per node, with failures classified as rate limited, unauthorized, not found, timeout, network or disk. "+
+			"Use it to surface registry throttling, missing pull secrets, node-local pull problems and slow images."),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pods. If empty, all namespaces are covered.")),
+		mcp.WithNumber("windowMinutes", mcp.Description("Only count events seen within this many minutes (default: 60)")),
+	)
+}
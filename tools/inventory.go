@@ -0,0 +1,16 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ClusterInventoryTool creates a tool for summarizing what a cluster contains.
+// It defines the tool's name and description; the tool takes no parameters.
+func ClusterInventoryTool() mcp.Tool {
+	return mcp.NewTool(
+		"clusterInventory",
+		mcp.WithDescription("Summarize a cluster for orientation: the Kubernetes version, nodes counted by role, instance type, zone and readiness, "+
+			"the number of namespaces, workloads counted by kind with pods by phase, and the CustomResourceDefinitions installed per API group. "+
+			"Call this first when meeting a new cluster."),
+	)
+}
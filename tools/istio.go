@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetIstioTrafficConfigTool creates a tool for inspecting Istio traffic configuration.
+// It defines the tool's name, description, and parameters for the host,
+// workload, and namespace.
+func GetIstioTrafficConfigTool() mcp.Tool {
+	return mcp.NewTool(
+		"getIstioTrafficConfig",
+		mcp.WithDescription("Summarize the Istio VirtualServices, DestinationRules, Gateways and PeerAuthentication mTLS mode "+
+			"affecting a host or workload, including conflicting route definitions. Requires Istio to be installed."),
+		mcp.WithString("host", mcp.Description("The host to inspect (e.g. 'reviews' or 'reviews.default.svc.cluster.local'). Short names are resolved in the given namespace.")),
+		mcp.WithString("workload", mcp.Description("The name of a Deployment, StatefulSet or DaemonSet; the hosts of Services selecting its pods are inspected")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the host or workload (default: 'default')")),
+	)
+}
+
+// GetSidecarInjectionStatusTool creates a tool for checking mesh sidecar injection.
+// It defines the tool's name, description, and parameters for the namespace
+// and workload.
+func GetSidecarInjectionStatusTool() mcp.Tool {
+	return mcp.NewTool(
+		"getSidecarInjectionStatus",
+		mcp.WithDescription("Report per workload whether Istio or Linkerd sidecar injection is enabled by the namespace and pod template "+
+			"labels and annotations, and whether the running pods actually contain the proxy container at the control plane's version. "+
+			"Flags pods missing the proxy, running an outdated proxy, or keeping a proxy after injection was disabled."),
+		mcp.WithString("namespace", mcp.Description("The namespace to check (default: 'default')")),
+		mcp.WithString("workload", mcp.Description("The name of a Deployment, StatefulSet or DaemonSet to check (default: all of them)")),
+	)
+}
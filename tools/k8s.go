@@ -29,17 +29,45 @@ func GetAPIResourcesTool() mcp.Tool {
 
 // ListResourcesTool creates a tool for listing resources of a specific type.
 // It defines the tool's name, description, and parameters for kind, namespace,
-// labelSelector, and fieldPaths for limiting returned data.
+// labelSelector, fieldPaths for limiting returned data, limit and continue
+// for paging through large lists, and sortBy and order for sorting.
 func ListResourcesTool() mcp.Tool {
 	return mcp.NewTool(
 		"listResources",
 		mcp.WithDescription("List all resources in the Kubernetes cluster of a specific type. "+
 			"Use fieldPaths to limit the size of returned data by specifying which fields to include."),
-		mcp.WithString("Kind", mcp.Required(), mcp.Description("The type of resource to list")),
-		mcp.WithString("namespace", mcp.Description("The namespace to list resources in")),
+		mcp.WithString("Kind", mcp.Required(), mcp.Description("The type of resource to list. Accepts the Kind, plural, short name or group-qualified name (e.g. Deployment, deployments, deploy, deployments.apps)")),
+		mcp.WithString("namespace", mcp.Description("The namespace to list resources in. Ignored for cluster-scoped kinds; if empty, namespaced kinds are listed across all namespaces. "+
+			"'*' is the same as allNamespaces.")),
+		mcp.WithBoolean("allNamespaces", mcp.Description("List across every namespace the server may access, adding metadata.namespace to fieldPaths and reporting "+
+			"the number of resources per namespace in the result metadata (default: false)")),
 		mcp.WithString("labelSelector", mcp.Description("A label selector to filter resources")),
+		mcp.WithString("fieldSelector", mcp.Description("A field selector to filter resources (e.g. 'status.phase=Running')")),
 		mcp.WithString("fieldPaths", mcp.Description("Comma-separated list of JSON paths to include in response (e.g. 'metadata.name,metadata.namespace,status.phase'). "+
-			"If not specified, full objects are returned. Use this to reduce response size and prevent timeouts.")),
+			"If not specified, full objects are returned. Use this to reduce response size and prevent timeouts. "+
+			"Index lists with [n] or [*] and match all map values with *, e.g. 'spec.containers[*].image,status.conditions[0].type,metadata.labels.*'. "+
+			"Append @resolve to a path to inline a summary of the objects it references, e.g. 'spec.serviceAccountName@resolve,spec.volumes@resolve'.")),
+		mcp.WithString("excludeFieldPaths", mcp.Description("Comma-separated list of JSON paths to remove from the response, keeping the rest of the object "+
+			"(e.g. 'metadata.managedFields,spec.containers[*].env'). Quote keys containing dots, e.g. 'metadata.annotations.\"kubectl.kubernetes.io/last-applied-configuration\"'. "+
+			"Applied after fieldPaths.")),
+		mcp.WithString("excludeFields", mcp.Description("Same as excludeFieldPaths, kept for compatibility")),
+		mcp.WithBoolean("brief", mcp.Description("For custom resources listed without fieldPaths or excludeFieldPaths, return a brief view of name, namespace, "+
+			"creationTimestamp and the columns declared by the CRD's additionalPrinterColumns. Set to false for full objects (default: true)")),
+		mcp.WithBoolean("asTable", mcp.Description("Return the API server's Table representation instead of full objects: the columns kubectl get prints, "+
+			"including the additionalPrinterColumns of custom resources, with one row per resource. Cannot be combined with fieldPaths or excludeFieldPaths (default: false)")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of resources to return in one page. When limit or continue is set, the result is an object "+
+			"with the page's items and the continue token of the next page, which is empty on the last page (default: all resources)")),
+		mcp.WithString("continue", mcp.Description("The continue token returned with the previous page, to list the next page with the same kind, namespace and selectors")),
+		mcp.WithString("jsonPath", mcp.Description("A kubectl -o jsonpath template evaluated against the list, returning the text it prints instead of JSON objects, "+
+			"e.g. '{.items[*].metadata.name}' or '{range .items[*]}{.metadata.name}{\"\\t\"}{.status.phase}{\"\\n\"}{end}'. "+
+			"Supports filters such as [?(@.status.phase==\"Running\")]. An alternative to fieldPaths; cannot be combined with fieldPaths, excludeFieldPaths, asTable, limit or continue.")),
+		mcp.WithString("sortBy", mcp.Description("A dot-separated field path to sort by, e.g. 'metadata.creationTimestamp', 'status.startTime' or 'status.containerStatuses[0].restartCount'. "+
+			"Numbers sort numerically and strings, including timestamps, lexically; resources without the field sort last. "+
+			"The field need not be in fieldPaths. Cannot be combined with limit, continue or asTable.")),
+		mcp.WithString("order", mcp.Description("The sort order for sortBy: asc or desc (default: asc)"), mcp.Enum("asc", "desc")),
+		withExport(),
+		withTableOutputFormat(),
+		withConfirmLargeScan(),
 	)
 }
 
@@ -50,12 +78,23 @@ func GetResourcesTool() mcp.Tool {
 	return mcp.NewTool(
 		"getResource",
 		mcp.WithDescription("Get a specific resource in the Kubernetes cluster. "+
-			"Use fieldPaths to limit the size of returned data by specifying which fields to include."),
-		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to get")),
+			"Use fieldPaths to limit the size of returned data by specifying which fields to include, "+
+			"and excludeFieldPaths to drop large fields such as metadata.managedFields."),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to get. Accepts the Kind, plural, short name or group-qualified name (e.g. Deployment, deployments, deploy, deployments.apps)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource to get")),
-		mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the resource. Ignored for cluster-scoped kinds (e.g. Node, PersistentVolume); defaults to 'default' for namespaced kinds.")),
 		mcp.WithString("fieldPaths", mcp.Description("Comma-separated list of JSON paths to include in response (e.g. 'metadata.name,metadata.namespace,status.phase'). "+
-			"If not specified, full object is returned. Use this to reduce response size.")),
+			"If not specified, full object is returned. Use this to reduce response size. "+
+			"Index lists with [n] or [*] and match all map values with *, e.g. 'spec.containers[*].image,status.conditions[0].type,metadata.labels.*'. "+
+			"Append @resolve to a path to inline a summary of the objects it references, e.g. 'spec.serviceAccountName@resolve,spec.volumes@resolve'.")),
+		mcp.WithString("excludeFieldPaths", mcp.Description("Comma-separated list of JSON paths to remove from the response, keeping the rest of the object "+
+			"(e.g. 'metadata.managedFields,spec.containers[*].env'). Quote keys containing dots, e.g. 'metadata.annotations.\"kubectl.kubernetes.io/last-applied-configuration\"'. "+
+			"Applied after fieldPaths.")),
+		mcp.WithString("excludeFields", mcp.Description("Same as excludeFieldPaths, kept for compatibility")),
+		mcp.WithString("jsonPath", mcp.Description("A kubectl -o jsonpath template evaluated against the resource, returning the text it prints instead of JSON, "+
+			"e.g. '{.spec.containers[*].image}' or '{.status.conditions[?(@.type==\"Ready\")].status}'. "+
+			"An alternative to fieldPaths; cannot be combined with fieldPaths or excludeFieldPaths.")),
+		withTableOutputFormat(),
 	)
 }
 
@@ -66,58 +105,87 @@ func DescribeResourcesTool() mcp.Tool {
 	return mcp.NewTool(
 		"describeResource",
 		mcp.WithDescription("Describe a resource in the Kubernetes cluster based on given kind and name"),
-		mcp.WithString("Kind", mcp.Required(), mcp.Description("The type of resource to describe")),
+		mcp.WithString("Kind", mcp.Required(), mcp.Description("The type of resource to describe. Accepts the Kind, plural, short name or group-qualified name (e.g. Deployment, deployments, deploy, deployments.apps)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource to describe")),
-		mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the resource. Ignored for cluster-scoped kinds; defaults to 'default' for namespaced kinds.")),
+		withOutputFormat(),
 	)
 }
 
 // GetPodsLogsTools creates a tool for getting pod logs.
-// It defines the tool's name, description, and parameters for the pod name
-// and namespace.
+// It defines the tool's name, description, and parameters for the pod name,
+// namespace, container and the part of the log to return.
 func GetPodsLogsTools() mcp.Tool {
 	return mcp.NewTool(
 		"getPodsLogs",
-		mcp.WithDescription("Get logs of a specific pod in the Kubernetes cluster"),
+		mcp.WithDescription("Get logs of a specific pod in the Kubernetes cluster. Logs of all containers are returned unless a container is given. "+
+			"Use previous to read the logs of a crashed container's last run."),
 		mcp.WithString("Name", mcp.Required(), mcp.Description("The name of the pod to get logs from")),
 		mcp.WithString("containerName", mcp.Description("The name of the container to get logs from")),
+		mcp.WithString("container", mcp.Description("Alias for containerName")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the pod")),
+		mcp.WithNumber("tailLines", mcp.Description("Number of lines from the end of the log to return (default: 100; 0 for the whole log)")),
+		mcp.WithNumber("sinceSeconds", mcp.Description("Only return lines newer than this many seconds")),
+		mcp.WithBoolean("previous", mcp.Description("Return the logs of the previous, terminated instance of the container (default: false)")),
+		mcp.WithBoolean("timestamps", mcp.Description("Prefix each line with its RFC3339 timestamp (default: false)")),
+		withExport(),
 	)
 }
 
 // GetNodeMetricsTools creates a tool for getting node metrics.
-// It defines the tool's name, description, and parameters for the node name.
+// It defines the tool's name, description, and parameters for the node name,
+// or the selector and sort order of all nodes.
 func GetNodeMetricsTools() mcp.Tool {
 	return mcp.NewTool(
 		"getNodeMetrics",
-		mcp.WithDescription("Get resource usage of a specific node in the Kubernetes cluster"),
-		mcp.WithString("Name", mcp.Required(), mcp.Description("The name of the node to get resource usage from")),
+		mcp.WithDescription("Get the CPU and memory usage of nodes from the metrics API, like 'kubectl top nodes', next to their allocatable and capacity resources "+
+			"and the usage as a percentage of allocatable. Without Name, every node is returned, which answers which node is overloaded."),
+		mcp.WithString("Name", mcp.Description("The name of the node to get resource usage from; if empty, all nodes are returned")),
+		mcp.WithString("labelSelector", mcp.Description("A label selector to filter the nodes when Name is empty, e.g. 'node-role.kubernetes.io/worker'")),
+		mcp.WithString("sortBy", mcp.Description("Sort nodes by 'cpu' or 'memory' utilization, highest first, or by 'name' (default: name)"),
+			mcp.Enum("cpu", "memory", "name")),
 	)
 }
 
 // GetPodMetricsTool creates a tool for getting pod metrics.
 // It defines the tool's name, description, and parameters for the pod namespace
-// and name.
+// and name, or the selector, sort order and limit of the pods to rank.
 func GetPodMetricsTool() mcp.Tool {
 	return mcp.NewTool(
 		"getPodMetrics",
-		mcp.WithDescription("Get CPU and Memory metrics for a specific pod"),
-		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the pod")),
-		mcp.WithString("podName", mcp.Required(), mcp.Description("The name of the pod")),
+		mcp.WithDescription("Get CPU and Memory metrics for a specific pod, or rank the pods matching a selector like 'kubectl top pods --sort-by'. "+
+			"Each pod is reported with its total usage and a per-container breakdown."),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pods; required with podName, otherwise all namespaces are ranked when empty")),
+		mcp.WithString("podName", mcp.Description("The name of the pod; if empty, all pods matching labelSelector are returned")),
+		mcp.WithString("labelSelector", mcp.Description("A label selector to filter the pods when podName is empty")),
+		mcp.WithString("sortBy", mcp.Description("Sort pods by 'cpu' or 'memory' usage, highest first, or by 'name' (default: name)"),
+			mcp.Enum("cpu", "memory", "name")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of pods to return after sorting, e.g. 10 for the top consumers (default: all)")),
 	)
 }
 
 // GetEventsTool creates a tool for getting events in the Kubernetes cluster.
 // It defines the tool's name, description, and parameters for the namespace,
-// maxEvents, sortBy, and messageFilter.
+// maxEvents, sortBy, messageFilter, workload roll-up, and field projection.
 func GetEventsTool() mcp.Tool {
 	return mcp.NewTool(
 		"getEvents",
-		mcp.WithDescription("Get events in the Kubernetes cluster. Returns the most recent events by default."),
+		mcp.WithDescription("Get events in the Kubernetes cluster. Returns the most recent events by default. "+
+			"Events are read from events.k8s.io/v1 (falling back to core v1) and normalized to one schema including series counts, reportingController and involvedObject."),
 		mcp.WithString("namespace", mcp.Description("The namespace to get events from. If empty, gets events from all namespaces.")),
 		mcp.WithNumber("maxEvents", mcp.Description("Maximum number of events to return after filtering (default: 20)")),
 		mcp.WithString("sortBy", mcp.Description("Field to sort events by. Options: 'lastTime' (default), 'firstTime'. Events are returned in descending order (most recent first).")),
 		mcp.WithString("messageFilter", mcp.Description("Filter events by message content. Only events whose message contains this string (case-insensitive) will be returned.")),
+		mcp.WithString("workload", mcp.Description("Name of a workload whose events to return, including events of the ReplicaSets, Jobs and Pods it owns (resolved via ownerReferences). Namespace defaults to 'default'.")),
+		mcp.WithString("workloadKind", mcp.Description("Kind of the workload given in 'workload' (e.g. Deployment, StatefulSet, DaemonSet, Job, CronJob). Defaults to Deployment.")),
+		mcp.WithString("fieldPaths", mcp.Description("Comma-separated list of event fields to include in response (e.g. 'reason,message,involvedObject.name,count'). "+
+			"Available fields: apiVersion, name, namespace, type, reason, action, message (alias: note), source, reportingController, reportingInstance, "+
+			"involvedObject (alias: regarding), related, count, firstTime, lastTime. If not specified, all fields are returned.")),
+		mcp.WithString("excludeFieldPaths", mcp.Description("Comma-separated list of event fields to remove from the response (e.g. 'related,reportingInstance'). Applied after fieldPaths.")),
+		mcp.WithString("excludeFields", mcp.Description("Same as excludeFieldPaths, kept for compatibility")),
+		withExport(),
+		withOutputFormat(),
+		withConfirmLargeScan(),
 	)
 }
 
@@ -129,6 +197,7 @@ func CreateOrUpdateResourceJSONTool() mcp.Tool {
 		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to create")),
 		mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
 		mcp.WithString("manifest", mcp.Required(), mcp.Description("The manifest of the resource to create")),
+		withOutputFormat(),
 	)
 }
 
@@ -140,6 +209,7 @@ func CreateOrUpdateResourceYAMLTool() mcp.Tool {
 		mcp.WithString("kind", mcp.Description("The type of resource to create (optional, will be inferred from YAML manifest if not provided)")),
 		mcp.WithString("namespace", mcp.Description("The namespace of the resource (overrides namespace in YAML manifest if provided)")),
 		mcp.WithString("yamlManifest", mcp.Required(), mcp.Description("The YAML manifest of the resource to create or update. Must be valid Kubernetes YAML format.")),
+		withOutputFormat(),
 	)
 }
 
@@ -147,10 +217,31 @@ func CreateOrUpdateResourceYAMLTool() mcp.Tool {
 func DeleteResourceTool() mcp.Tool {
 	return mcp.NewTool(
 		"deleteResource",
-		mcp.WithDescription("Delete a resource in the Kubernetes cluster"),
-		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to delete")),
+		mcp.WithDescription("Delete a resource of any kind, including custom resources, in the Kubernetes cluster"),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to delete. Accepts the Kind, plural, short name or group-qualified name (e.g. Deployment, deployments, deploy, deployments.apps)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource to delete")),
-		mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the resource. Ignored for cluster-scoped kinds; defaults to 'default' for namespaced kinds.")),
+		mcp.WithNumber("gracePeriodSeconds", mcp.Description("Seconds to allow for graceful termination; 0 deletes immediately (default: the object's own grace period)")),
+		mcp.WithString("propagationPolicy", mcp.Description("How dependents such as a Deployment's ReplicaSets and Pods are deleted (default: the kind's default, usually Background)"),
+			mcp.Enum("Orphan", "Background", "Foreground")),
+		mcp.WithBoolean("dryRun", mcp.Description("Validate the deletion as a server-side dry run without deleting anything (default: false)")),
+	)
+}
+
+// CleanupFinishedTool creates a tool for deleting finished pods and Jobs.
+// It defines the tool's name, description, and parameters for the namespace,
+// label selector, age thresholds and dry run.
+func CleanupFinishedTool() mcp.Tool {
+	return mcp.NewTool(
+		"cleanupFinished",
+		mcp.WithDescription("Delete finished pods (Succeeded, or Failed including Evicted) older than a threshold, and completed or failed Jobs "+
+			"past their ttlSecondsAfterFinished or a default TTL, together with their pods. Pods of Jobs are deleted with their Job and mirror pods are skipped. "+
+			"Reports every pod and Job removed and how many were kept for being too recent. Use dryRun to preview what would be removed."),
+		mcp.WithString("namespace", mcp.Description("The namespace to clean up (default: all namespaces)")),
+		mcp.WithString("labelSelector", mcp.Description("Only consider pods and Jobs matching this label selector")),
+		mcp.WithNumber("podAgeMinutes", mcp.Description("Delete pods that finished at least this many minutes ago (default: 60)")),
+		mcp.WithNumber("jobTTLMinutes", mcp.Description("Delete Jobs that finished at least this many minutes ago, unless they set ttlSecondsAfterFinished (default: 1440)")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the deletions as server-side dry runs and report what would be removed (default: false)")),
 	)
 }
 
@@ -161,6 +252,48 @@ func GetIngressesTool() mcp.Tool {
 		"getIngresses",
 		mcp.WithDescription("Get ingresses in the Kubernetes cluster"),
 		mcp.WithString("host", mcp.Required(), mcp.Description("The host to get ingresses from")),
+		withOutputFormat(),
+	)
+}
+
+// CheckIngressPortsTool creates a tool for validating the port chain of Ingress backends.
+// It defines the tool's name, description, and parameters for the host, path and namespace.
+func CheckIngressPortsTool() mcp.Tool {
+	return mcp.NewTool(
+		"checkIngressPorts",
+		mcp.WithDescription("Validate the chain Ingress backend port -> Service port -> targetPort -> containerPort for the Ingress paths serving a host and path, "+
+			"and report mismatches such as a missing Service, a port the Service does not expose, a named targetPort no container declares, "+
+			"or a Service without ready pods: the most common causes of 502 and 503 responses."),
+		mcp.WithString("host", mcp.Description("The host to check, e.g. shop.example.com. Wildcard rules are matched. If empty, all rules are checked.")),
+		mcp.WithString("path", mcp.Description("The request path to check, e.g. /api/orders, matched by each path's pathType. If empty, all paths are checked.")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the Ingresses. If empty, Ingresses in all namespaces are checked.")),
+	)
+}
+
+// CheckStatefulSetDNSTool creates a tool for verifying the per-pod DNS records of a StatefulSet.
+// It defines the tool's name, description, and parameters for the StatefulSet and the cluster domain.
+func CheckStatefulSetDNSTool() mcp.Tool {
+	return mcp.NewTool(
+		"checkStatefulSetDNS",
+		mcp.WithDescription("Verify the serviceName wiring of a StatefulSet that gives its pods stable DNS names: the headless Service exists and selects the pods, "+
+			"each pod has its hostname and subdomain set and is published in the Service's EndpointSlices, and whether publishNotReadyAddresses is set. "+
+			"Records are resolved when the server runs in the cluster. A frequent cause of clustered stores failing to bootstrap."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the StatefulSet")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the StatefulSet (default: default)")),
+		mcp.WithString("clusterDomain", mcp.Description("The cluster DNS domain (default: cluster.local)")),
+	)
+}
+
+// DiagnoseLoadBalancersTool creates a tool for diagnosing LoadBalancer Services stuck in Pending.
+// It defines the tool's name, description, and parameters for the Service name and namespace.
+func DiagnoseLoadBalancersTool() mcp.Tool {
+	return mcp.NewTool(
+		"diagnoseLoadBalancers",
+		mcp.WithDescription("Diagnose Services of type LoadBalancer, especially those stuck in Pending: report the assigned ingress IP or hostname, "+
+			"the load balancer annotations and spec fields in use, recent cloud controller events, ready endpoints, and hints extracted from warning events "+
+			"such as exhausted quotas, firewall or health check failures, missing subnets or missing permissions."),
+		mcp.WithString("name", mcp.Description("The name of the Service. If empty, every LoadBalancer Service in the namespace is diagnosed.")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the Service. If empty with no name, all namespaces are diagnosed; defaults to 'default' when a name is given.")),
 	)
 }
 
@@ -172,5 +305,6 @@ func RolloutRestartTool() mcp.Tool {
 		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to restart (e.g., Deployment, DaemonSet)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the resource")),
+		withRolloutWait(),
 	)
 }
@@ -0,0 +1,17 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetKedaScalersTool creates a tool for reporting KEDA ScaledObjects and ScaledJobs.
+// It defines the tool's name, description, and parameters for the namespace and name.
+func GetKedaScalersTool() mcp.Tool {
+	return mcp.NewTool(
+		"getKedaScalers",
+		mcp.WithDescription("Report KEDA ScaledObjects and ScaledJobs: triggers, active and paused state, conditions, "+
+			"current metric values from the external metrics API, and the HPA each ScaledObject drives. Requires KEDA to be installed."),
+		mcp.WithString("namespace", mcp.Description("The namespace to inspect. If empty, all namespaces are inspected.")),
+		mcp.WithString("name", mcp.Description("Only report the ScaledObject or ScaledJob with this name")),
+	)
+}
@@ -0,0 +1,17 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetKnativeServicesTool creates a tool for reporting Knative Serving Services.
+// It defines the tool's name, description, and parameters for the namespace and name.
+func GetKnativeServicesTool() mcp.Tool {
+	return mcp.NewTool(
+		"getKnativeServices",
+		mcp.WithDescription("Report Knative Serving Services: Service and Revision readiness, traffic split per revision, "+
+			"autoscaling bounds, and the error of the latest failed revision. Requires Knative Serving to be installed."),
+		mcp.WithString("namespace", mcp.Description("The namespace to inspect. If empty, all namespaces are inspected.")),
+		mcp.WithString("name", mcp.Description("Only report the Knative Service with this name (requires namespace)")),
+	)
+}
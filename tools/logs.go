@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetLogsBySelectorTool creates a tool for getting the logs of all pods matching a label selector.
+// It defines the tool's name, description, and parameters for the selector,
+// the part of each log to return and how logs are combined.
+func GetLogsBySelectorTool() mcp.Tool {
+	return mcp.NewTool(
+		"getLogsBySelector",
+		mcp.WithDescription("Get the logs of all pods matching a label selector, like 'kubectl logs -l' or stern, e.g. every replica of a Deployment. "+
+			"Each line is prefixed with [pod/container]. Logs are interleaved by timestamp by default, or grouped per container."),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the pods")),
+		mcp.WithString("labelSelector", mcp.Required(), mcp.Description("A label selector matching the pods, e.g. 'app=web'")),
+		mcp.WithString("container", mcp.Description("Only read containers with this name")),
+		mcp.WithNumber("tailLines", mcp.Description("Number of lines from the end of each container's log (default: 50; 0 for the whole log)")),
+		mcp.WithNumber("sinceSeconds", mcp.Description("Only return lines newer than this many seconds")),
+		mcp.WithBoolean("previous", mcp.Description("Read the logs of the previous, terminated container instances (default: false)")),
+		mcp.WithBoolean("timestamps", mcp.Description("Keep the RFC3339 timestamp of each line (default: false)")),
+		mcp.WithString("mode", mcp.Description("How to combine logs: 'interleaved' by timestamp or 'grouped' per container (default: interleaved)"),
+			mcp.Enum("interleaved", "grouped")),
+		mcp.WithNumber("maxPods", mcp.Description("Maximum number of pods to read, sorted by name (default: 20)")),
+		withExport(),
+	)
+}
+
+// CorrelateEventLogsTool creates a tool for pairing Warning events with the container logs around them.
+// It defines the tool's name, description, and parameters for selecting the
+// events and the log window around each.
+func CorrelateEventLogsTool() mcp.Tool {
+	return mcp.NewTool(
+		"correlateEventLogs",
+		mcp.WithDescription("Pair Warning events of pods, such as BackOff or Unhealthy, with the log lines their container wrote around the event time. "+
+			"The container is taken from the event, or every container of the pod is read; for BackOff and similar events the crashed, previous container instance is read. "+
+			"Without eventName, the most recent matching Warning events in the namespace are correlated."),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the events")),
+		mcp.WithString("eventName", mcp.Description("The name of one event to correlate")),
+		mcp.WithString("podName", mcp.Description("Only correlate events of this pod")),
+		mcp.WithString("reason", mcp.Description("Only correlate events with this reason, e.g. 'BackOff' or 'Unhealthy'")),
+		mcp.WithNumber("secondsBefore", mcp.Description("Seconds of log before the event to return (default: 60)")),
+		mcp.WithNumber("secondsAfter", mcp.Description("Seconds of log after the event to return (default: 10)")),
+		mcp.WithNumber("maxEvents", mcp.Description("Maximum number of events to correlate, most recent first (default: 5)")),
+		mcp.WithNumber("maxLines", mcp.Description("Maximum number of log lines per container, keeping the latest (default: 200)")),
+		withExport(),
+	)
+}
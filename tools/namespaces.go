@@ -0,0 +1,16 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ListNamespacesTool creates a tool for listing namespaces with their activity.
+// It defines the tool's name, description, and the label selector parameter.
+func ListNamespacesTool() mcp.Tool {
+	return mcp.NewTool(
+		"listNamespaces",
+		mcp.WithDescription("List namespaces with their phase, age and labels, the number of pods each holds broken down by phase, "+
+			"and the ResourceQuotas and LimitRanges that constrain them, in a single call."),
+		mcp.WithString("labelSelector", mcp.Description("A label selector to filter the namespaces")),
+	)
+}
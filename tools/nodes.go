@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetPodsOnNodeTool creates a tool for listing the pods scheduled on a node.
+// It defines the tool's name, description, and parameters for the node name.
+func GetPodsOnNodeTool() mcp.Tool {
+	return mcp.NewTool(
+		"getPodsOnNode",
+		mcp.WithDescription("List the pods scheduled on a node with their phase, effective resource requests and limits, QoS class and controller "+
+			"(ReplicaSets resolved to their Deployment). Flags DaemonSet, mirror and local-storage pods for drain planning "+
+			"and compares total requests with the node's allocatable resources."),
+		mcp.WithString("nodeName", mcp.Required(), mcp.Description("The name of the node")),
+	)
+}
+
+// GetKubeletStatsTool creates a tool for reading kubelet statistics of a node.
+// It defines the tool's name, description, and parameters for the node name
+// and the number of pods to report.
+func GetKubeletStatsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getKubeletStats",
+		mcp.WithDescription("Fetch the kubelet's /stats/summary, /metrics and /metrics/cadvisor for a node via the node proxy subresource and return key figures: "+
+			"node, image and container filesystem usage, memory and CPU, network errors per interface, the pods using the most ephemeral storage, "+
+			"and counters such as running pods, evictions, OOM events and dropped packets. Requires get permission on nodes/proxy."),
+		mcp.WithString("nodeName", mcp.Required(), mcp.Description("The name of the node")),
+		mcp.WithNumber("topPods", mcp.Description("Number of pods with the highest ephemeral storage usage to report (default: 10)")),
+	)
+}
+
+// GetNodeLogsTool creates a tool for reading the system logs of a node.
+// It defines the tool's name, description, and parameters for the node, the
+// service or file to read, and the part of the log to return.
+func GetNodeLogsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getNodeLogs",
+		mcp.WithDescription("Read the system logs of a node through the node proxy /logs/ endpoint, for problems pod logs do not show, such as kubelet or container runtime errors. "+
+			"Use query for service logs such as 'kubelet' or 'containerd' (requires the NodeLogQuery feature), or path for a file under /var/log such as 'syslog'. "+
+			"Without either, the files in /var/log are listed. Requires get permission on nodes/proxy and the kubelet's system log handler."),
+		mcp.WithString("nodeName", mcp.Required(), mcp.Description("The name of the node")),
+		mcp.WithString("query", mcp.Description("The service to read logs for, e.g. 'kubelet' or 'containerd'")),
+		mcp.WithString("path", mcp.Description("A file or directory under /var/log, e.g. 'syslog' or 'pods/'")),
+		mcp.WithNumber("sinceSeconds", mcp.Description("Only return service log lines newer than this many seconds (query only)")),
+		mcp.WithNumber("tailLines", mcp.Description("Number of lines from the end of the log (default: 200, max: 5000)")),
+		mcp.WithString("pattern", mcp.Description("Only return lines matching this regular expression")),
+	)
+}
+
+// GetEvictionRiskTool creates a tool for ranking pods by memory-pressure eviction risk.
+// It defines the tool's name, description, and parameters for the node name and ranking size.
+func GetEvictionRiskTool() mcp.Tool {
+	return mcp.NewTool(
+		"getEvictionRisk",
+		mcp.WithDescription("Classify running pods by QoS class (Guaranteed/Burstable/BestEffort) per node and rank which pods the kubelet would evict first under memory pressure: "+
+			"pods using more memory than requested first, then lower priority, then the largest excess over the request. "+
+			"Uses pod metrics when available, otherwise ranks by QoS class and priority."),
+		mcp.WithString("nodeName", mcp.Description("Only report this node. If empty, all nodes with running pods are reported.")),
+		mcp.WithNumber("topN", mcp.Description("Number of pods to rank per node (default: 10)")),
+	)
+}
+
+// GetPortAllocationsTool creates a tool for reporting NodePort and hostPort allocations.
+// It defines the tool's name, description, and parameters for the NodePort range.
+func GetPortAllocationsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getPortAllocations",
+		mcp.WithDescription("List all NodePorts allocated to Services and all hostPorts declared by pods across the cluster, with their owners, "+
+			"and flag conflicts: NodePorts held by several Services, hostPorts bound by several pods on the same node, and hostPorts shadowed by a NodePort. "+
+			"Reports how much of the NodePort range is allocated and warns when it is nearly exhausted."),
+		mcp.WithString("nodePortRange", mcp.Description("The API server's --service-node-port-range, e.g. 30000-32767 (default: 30000-32767)")),
+	)
+}
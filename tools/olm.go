@@ -0,0 +1,16 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetOLMSubscriptionsTool creates a tool for reporting Operator Lifecycle Manager subscriptions.
+// It defines the tool's name, description, and parameters for the namespace.
+func GetOLMSubscriptionsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getOLMSubscriptions",
+		mcp.WithDescription("Report Operator Lifecycle Manager (OLM) Subscriptions, InstallPlans and ClusterServiceVersion phases, "+
+			"including InstallPlans pending manual approval and failed upgrades. For OpenShift/OLM-based clusters."),
+		mcp.WithString("namespace", mcp.Description("The namespace to inspect. If empty, all namespaces are inspected.")),
+	)
+}
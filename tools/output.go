@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withOutputFormat adds the outputFormat parameter to tools returning
+// Kubernetes resources, letting callers read them as YAML, which round-trips
+// into applyResource and createResourceYAML.
+func withOutputFormat() mcp.ToolOption {
+	return mcp.WithString("outputFormat",
+		mcp.Description("Format of the output: 'json' (default) or 'yaml'. A list of resources is returned as a multi-document YAML stream that can be applied as is."),
+		mcp.Enum("json", "yaml"),
+	)
+}
+
+// withTableOutputFormat adds the outputFormat parameter to tools listing
+// resources, offering a compact markdown table of the columns kubectl get
+// prints in addition to JSON and YAML.
+func withTableOutputFormat() mcp.ToolOption {
+	return mcp.WithString("outputFormat",
+		mcp.Description("Format of the output: 'json' (default), 'yaml', or 'table' for a markdown table of the columns kubectl get prints, "+
+			"from the API server's Table or, if it cannot render one, of name, ready, status and age. "+
+			"A list of resources is returned in YAML as a multi-document stream that can be applied as is."),
+		mcp.Enum("json", "yaml", "table"),
+	)
+}
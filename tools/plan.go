@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ExecutePlanTool creates a tool for running a sequence of mutating operations.
+// It defines the tool's name, description, and parameters for the ordered
+// steps and the dry-run and stop-on-error behaviour.
+func ExecutePlanTool() mcp.Tool {
+	return mcp.NewTool(
+		"executePlan",
+		mcp.WithDescription("Run an ordered list of mutating operations (scale, setImage, applyManifest) as one auditable remediation. "+
+			"All steps are validated before any runs; each step can be a server-side dry run, and by default the first failure stops the plan "+
+			"and skips the remaining steps. Returns a transcript with each step's status, result or error, and elapsed time."),
+		mcp.WithArray("steps", mcp.Required(), mcp.Description("Ordered steps to run"), mcp.Items(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"operation": map[string]interface{}{"type": "string", "enum": []string{"scale", "setImage", "applyManifest"}, "description": "The operation to run"},
+				"kind":      map[string]interface{}{"type": "string", "description": "Resource type (scale, setImage; optional for applyManifest)"},
+				"name":      map[string]interface{}{"type": "string", "description": "Resource name (scale, setImage)"},
+				"namespace": map[string]interface{}{"type": "string", "description": "Namespace of the resource (default: default)"},
+				"replicas":  map[string]interface{}{"type": "number", "description": "Target replica count (scale)"},
+				"container": map[string]interface{}{"type": "string", "description": "Container to update; optional if the workload has one container (setImage)"},
+				"image":     map[string]interface{}{"type": "string", "description": "New container image (setImage)"},
+				"manifest":  map[string]interface{}{"type": "string", "description": "YAML or JSON manifest to create or update (applyManifest)"},
+				"dryRun":    map[string]interface{}{"type": "boolean", "description": "Run this step as a server-side dry run"},
+			},
+			"required": []string{"operation"},
+		})),
+		mcp.WithBoolean("dryRun", mcp.Description("Run every step as a server-side dry run (default: false)")),
+		mcp.WithBoolean("stopOnError", mcp.Description("Stop at the first failing step and skip the rest (default: true)")),
+	)
+}
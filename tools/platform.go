@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CheckPlatformCompatibilityTool creates a tool for checking pod/node architecture compatibility.
+// It defines the tool's name, description, and parameters for the namespace,
+// labelSelector, and image inspection.
+func CheckPlatformCompatibilityTool() mcp.Tool {
+	return mcp.NewTool(
+		"checkPlatformCompatibility",
+		mcp.WithDescription("Cross-reference node OS/architecture labels against pod nodeSelectors, required node affinity "+
+			"and image platform metadata to flag pods that can never schedule or run on the available architectures "+
+			"(common in mixed amd64/arm64 clusters)."),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pods to check. If empty, all namespaces are checked.")),
+		mcp.WithString("labelSelector", mcp.Description("A label selector to filter pods")),
+		mcp.WithBoolean("inspectImages", mcp.Description("Fetch image manifests from their registries to compare published platforms (default: false). "+
+			"Only anonymously accessible images can be inspected.")),
+	)
+}
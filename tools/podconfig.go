@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetPodEnvironmentTool creates a tool for resolving the effective environment of a pod's containers.
+// It defines the tool's name, description, and parameters for the pod name, namespace, and container.
+func GetPodEnvironmentTool() mcp.Tool {
+	return mcp.NewTool(
+		"getPodEnvironment",
+		mcp.WithDescription("Resolve the effective environment variables of a pod's containers without exec: literal values, "+
+			"configMap and secret key references, envFrom sources and downward API fields, with $(VAR) references expanded. "+
+			"Secret values and literal values with credential-like names are redacted; missing ConfigMaps, Secrets or keys are reported as errors."),
+		mcp.WithString("podName", mcp.Required(), mcp.Description("The name of the pod")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pod (default: 'default')")),
+		mcp.WithString("container", mcp.Description("Only report this container. If empty, all containers and init containers are reported.")),
+	)
+}
+
+// GetPodVolumeMountsTool creates a tool for resolving a pod's volume mounts to their sources.
+// It defines the tool's name, description, and parameters for the pod name and namespace.
+func GetPodVolumeMountsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getPodVolumeMounts",
+		mcp.WithDescription("Resolve each volumeMount of a pod's containers to its source (ConfigMap, Secret, PVC, projected, emptyDir, hostPath, ...). "+
+			"Reports whether referenced ConfigMaps, Secrets and PVCs exist, which keys are projected to which file paths, and volumes that are not mounted. "+
+			"Secret values are never returned."),
+		mcp.WithString("podName", mcp.Required(), mcp.Description("The name of the pod")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pod (default: 'default')")),
+	)
+}
+
+// GetSecretUsageTool creates a tool for mapping everything that references a Secret.
+// It defines the tool's name, description, and parameters for the secret name and namespace.
+func GetSecretUsageTool() mcp.Tool {
+	return mcp.NewTool(
+		"getSecretUsage",
+		mcp.WithDescription("List every pod, Ingress, ServiceAccount and CSI volume referencing a Secret, to see what breaks if it is rotated or deleted. "+
+			"Pods are reported with their workload and how they consume the Secret (env, envFrom, volume, image pull secret); "+
+			"those that only see a new value after a restart are flagged and their workloads listed. Secret values are never returned."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the Secret")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the Secret (default: 'default')")),
+	)
+}
+
+// GetConfigMapUsageTool creates a tool for listing the consumers of a ConfigMap.
+// It defines the tool's name, description, and parameters for the ConfigMap name and namespace.
+func GetConfigMapUsageTool() mcp.Tool {
+	return mcp.NewTool(
+		"getConfigMapUsage",
+		mcp.WithDescription("List the workloads (Deployments, StatefulSets, DaemonSets, CronJobs) and bare pods consuming a ConfigMap through env, envFrom or volumes, "+
+			"to see the impact of changing it. Consumers that only see a change after a restart are flagged. "+
+			"Use restartConfigMapConsumers to restart them after a change."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the ConfigMap")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the ConfigMap (default: 'default')")),
+	)
+}
@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// StartPortForwardTool creates a tool for forwarding a local port to a pod.
+// It defines the tool's name, description, and parameters for the target
+// resource and the ports.
+func StartPortForwardTool() mcp.Tool {
+	return mcp.NewTool(
+		"startPortForward",
+		mcp.WithDescription("Forward a local port on the server host (127.0.0.1) to a port of a pod, like 'kubectl port-forward', so that follow-up HTTP probes can reach it. "+
+			"The target can be a Pod, a Service or a workload such as a Deployment, in which case a running pod it selects is used. "+
+			"Forwards belong to the MCP session and are stopped when the session ends."),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the target")),
+		mcp.WithString("kind", mcp.Description("The kind of the target: Pod, Service, or a workload kind such as Deployment (default: Pod)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the target")),
+		mcp.WithNumber("remotePort", mcp.Required(), mcp.Description("The port to forward to; for a Service, the service port")),
+		mcp.WithNumber("localPort", mcp.Description("The local port to listen on (default: a free port)")),
+	)
+}
+
+// ListPortForwardsTool creates a tool for listing the port forwards of the session.
+// It defines the tool's name and description; the tool takes no parameters.
+func ListPortForwardsTool() mcp.Tool {
+	return mcp.NewTool(
+		"listPortForwards",
+		mcp.WithDescription("List the port forwards started in this session with their local address, target pod and port, "+
+			"and whether they are still active; forwards close on their own when their pod goes away."),
+	)
+}
+
+// StopPortForwardTool creates a tool for stopping a port forward.
+// It defines the tool's name, description, and the forward ID parameter.
+func StopPortForwardTool() mcp.Tool {
+	return mcp.NewTool(
+		"stopPortForward",
+		mcp.WithDescription("Stop a port forward started in this session"),
+		mcp.WithString("id", mcp.Required(), mcp.Description("The ID of the port forward, as returned by startPortForward or listPortForwards")),
+	)
+}
@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetWorkloadManifestTool creates a tool for extracting a resource's manifest and provenance.
+// It defines the tool's name, description, and parameters for identifying the resource.
+func GetWorkloadManifestTool() mcp.Tool {
+	return mcp.NewTool(
+		"getWorkloadManifest",
+		mcp.WithDescription("Get the cleaned manifest of a workload or other resource (status and server-populated metadata removed) together with its provenance: "+
+			"Helm release annotations, Argo CD tracking labels, Flux ownership labels, the kubectl last-applied configuration and managedFields managers. "+
+			"Use the provenance to direct changes to the source repository or chart instead of editing the live object."),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The resource type, e.g. Deployment")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the resource (default: default)")),
+		withExport(),
+		withOutputFormat(),
+	)
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SaveQueryTool creates a tool for saving a named listResources query on the server.
+// It defines the tool's name, description, and parameters for the query's
+// name, kind, selectors and projection.
+func SaveQueryTool() mcp.Tool {
+	return mcp.NewTool(
+		"saveQuery",
+		mcp.WithDescription("Save a named query (kind, namespace, selectors and projection) on the server so that any session can re-run it "+
+			"by name with runQuery, e.g. a standard 'prod payment pods brief' view. Saving under an existing name replaces that query."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the query: letters, digits, spaces, '.', '_' or '-'")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to list")),
+		mcp.WithString("description", mcp.Description("What the query shows, for other users of the server")),
+		mcp.WithString("namespace", mcp.Description("The namespace to list in; omit to use the namespace or defaults of the caller")),
+		mcp.WithString("labelSelector", mcp.Description("A label selector to filter resources")),
+		mcp.WithString("fieldSelector", mcp.Description("A field selector to filter resources")),
+		mcp.WithString("fieldPaths", mcp.Description("Comma-separated field paths to include in the output")),
+		mcp.WithString("excludeFieldPaths", mcp.Description("Comma-separated field paths to remove from the output")),
+		mcp.WithString("excludeFields", mcp.Description("Same as excludeFieldPaths, kept for compatibility")),
+		mcp.WithBoolean("asTable", mcp.Description("Return the columns kubectl get prints instead of full objects (default: false)")),
+		mcp.WithString("jsonPath", mcp.Description("A kubectl -o jsonpath template to print instead of returning objects, e.g. '{.items[*].metadata.name}'")),
+	)
+}
+
+// RunQueryTool creates a tool for running a saved query.
+// It defines the tool's name, description, and parameters for the query name
+// and an optional namespace override.
+func RunQueryTool() mcp.Tool {
+	return mcp.NewTool(
+		"runQuery",
+		mcp.WithDescription("Run a query saved with saveQuery and return the listed resources, as listResources would."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the saved query")),
+		mcp.WithString("namespace", mcp.Description("Namespace to run the query in instead of the saved one")),
+		withExport(),
+		withTableOutputFormat(),
+		withConfirmLargeScan(),
+	)
+}
+
+// ListSavedQueriesTool creates a tool for listing the saved queries.
+// It defines the tool's name and description.
+func ListSavedQueriesTool() mcp.Tool {
+	return mcp.NewTool(
+		"listSavedQueries",
+		mcp.WithDescription("List the queries saved on the server with their kind, selectors and projection."),
+	)
+}
+
+// DeleteSavedQueryTool creates a tool for deleting a saved query.
+// It defines the tool's name, description, and parameters for the query name.
+func DeleteSavedQueryTool() mcp.Tool {
+	return mcp.NewTool(
+		"deleteSavedQuery",
+		mcp.WithDescription("Delete a query saved with saveQuery."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the saved query")),
+	)
+}
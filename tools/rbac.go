@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CompareRolesTool creates a tool for diffing the permissions of RBAC roles.
+// It defines the tool's name, description, and parameters for the role, the
+// role to compare it to, and the rules to compare it against.
+func CompareRolesTool() mcp.Tool {
+	return mcp.NewTool(
+		"compareRoles",
+		mcp.WithDescription("Diff the permissions of a Role or ClusterRole against another role, or against a requested set of rules. "+
+			"Reports the missing permissions the reference has but the role lacks, and the extra permissions the role grants beyond the reference, "+
+			"honouring wildcards, subresources, resourceNames and nonResourceURLs like the RBAC authorizer. "+
+			"Use it to find why a request is forbidden or to trim a role to least privilege. Give either compareTo or rules."),
+		mcp.WithString("role", mcp.Required(), mcp.Description("Name of the role to check")),
+		mcp.WithString("kind", mcp.Enum("Role", "ClusterRole"), mcp.Description("Kind of the role (default: Role if namespace is set, else ClusterRole)")),
+		mcp.WithString("namespace", mcp.Description("Namespace of the role, if it is a Role")),
+		mcp.WithString("compareTo", mcp.Description("Name of the role to compare against")),
+		mcp.WithString("compareToKind", mcp.Enum("Role", "ClusterRole"), mcp.Description("Kind of the role to compare against (default: Role if compareToNamespace is set, else ClusterRole)")),
+		mcp.WithString("compareToNamespace", mcp.Description("Namespace of the role to compare against, if it is a Role")),
+		mcp.WithArray("rules", mcp.Description("Requested rules to compare against, in the form of Role rules"), mcp.Items(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"apiGroups":       map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "API groups; \"\" is the core group"},
+				"resources":       map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Resources, e.g. pods or pods/log"},
+				"resourceNames":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Names of the resources; all if omitted"},
+				"nonResourceURLs": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Non-resource URLs, e.g. /healthz"},
+				"verbs":           map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Verbs, e.g. get, list, watch"},
+			},
+			"required": []string{"verbs"},
+		})),
+	)
+}
+
+// CanITool creates a tool for checking whether an action is allowed.
+// It defines the tool's name, description, and parameters for the verb and
+// the resource or non-resource URL it acts on.
+func CanITool() mcp.Tool {
+	return mcp.NewTool(
+		"canI",
+		mcp.WithDescription("Check whether an action is allowed before attempting it, like `kubectl auth can-i`. "+
+			"Asks the API server with a SelfSubjectAccessReview, or a SubjectAccessReview for the impersonated user when impersonating, "+
+			"and returns whether it is allowed or denied with the authorizer's reason, the rules that grant it, or the rule that would. "+
+			"Give either resource or nonResourceURL."),
+		mcp.WithString("verb", mcp.Required(), mcp.Description("Verb to check, e.g. get, list, create, patch, delete, or * for all")),
+		mcp.WithString("resource", mcp.Description("Resource to check, e.g. pods, deployments or pods/log; kinds and short names are resolved when group is omitted")),
+		mcp.WithString("group", mcp.Description("API group of the resource; \"\" is the core group (default: resolved through discovery)")),
+		mcp.WithString("subresource", mcp.Description("Subresource to check, e.g. log, exec or scale")),
+		mcp.WithString("name", mcp.Description("Name of the resource; any name if omitted")),
+		mcp.WithString("namespace", mcp.Description("Namespace to check in; all namespaces or cluster scope if omitted")),
+		mcp.WithString("nonResourceURL", mcp.Description("Non-resource URL to check instead of a resource, e.g. /metrics")),
+	)
+}
+
+// WhoCanTool creates a tool for finding who may perform an action.
+// It defines the tool's name, description, and parameters for the verb and
+// the resource or non-resource URL it acts on.
+func WhoCanTool() mcp.Tool {
+	return mcp.NewTool(
+		"whoCan",
+		mcp.WithDescription("Find who may perform an action, the inverse of canI, for access audits. "+
+			"Walks the ClusterRoles, ClusterRoleBindings and, when a namespace is given, the Roles and RoleBindings of that namespace, "+
+			"and returns each role granting the action with the bindings and subjects (users, groups and service accounts) it is bound to, "+
+			"plus granting roles that are not bound. Without a namespace only cluster-wide bindings count. Give either resource or nonResourceURL."),
+		mcp.WithString("verb", mcp.Required(), mcp.Description("Verb to check, e.g. get, list, create, patch, delete, or * for all")),
+		mcp.WithString("resource", mcp.Description("Resource to check, e.g. secrets, deployments or pods/exec; kinds and short names are resolved when group is omitted")),
+		mcp.WithString("group", mcp.Description("API group of the resource; \"\" is the core group (default: resolved through discovery)")),
+		mcp.WithString("subresource", mcp.Description("Subresource to check, e.g. log, exec or scale")),
+		mcp.WithString("name", mcp.Description("Name of the resource; any name if omitted, so rules limited to resourceNames do not count")),
+		mcp.WithString("namespace", mcp.Description("Namespace to check in; cluster-wide bindings only if omitted")),
+		mcp.WithString("nonResourceURL", mcp.Description("Non-resource URL to check instead of a resource, e.g. /metrics")),
+	)
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// FindRestartStormsTool creates a tool for detecting containers that are restarting repeatedly.
+// It defines the tool's name, description, and parameters for the namespace,
+// time window, sampling interval, and number of offenders to report.
+func FindRestartStormsTool() mcp.Tool {
+	return mcp.NewTool(
+		"findRestartStorms",
+		mcp.WithDescription("Scan for containers whose restart count increased recently and rank the worst offenders, surfacing brewing incidents before they page. "+
+			"A container is reported when its last termination finished within windowMinutes, or when its restart count increased between two pod listings "+
+			"taken sampleSeconds apart. Offenders are ranked by the increase observed while sampling, then by restarts per hour since the pod started."),
+		mcp.WithString("namespace", mcp.Description("Only scan this namespace. If empty, all namespaces are scanned.")),
+		mcp.WithNumber("windowMinutes", mcp.Description("Report containers whose last restart happened within this many minutes (default: 60)")),
+		mcp.WithNumber("sampleSeconds", mcp.Description("Seconds between two pod listings used to measure restart count increases (default: 0, single listing; max: 120)")),
+		mcp.WithNumber("topN", mcp.Description("Number of offenders to report (default: 10)")),
+		withConfirmLargeScan(),
+	)
+}
+
+// ListFailuresTool creates a tool for listing the failing pods of the cluster.
+// It defines the tool's name, description, and parameters for the namespace
+// and the page of failing pods to return.
+func ListFailuresTool() mcp.Tool {
+	return mcp.NewTool(
+		"listFailures",
+		mcp.WithDescription("List every pod across the cluster that is not Running or Completed, like kubectl get pods -A | grep -v Running: "+
+			"pods pending, failing to pull or start, crash looping, failed, evicted, or stuck terminating past their grace period. "+
+			"Each pod has the status kubectl prints and a one-line reason, and pods are grouped by namespace and by the controller owning them. "+
+			"Counts by status and namespace cover all failing pods; the pods themselves are paginated, and nextOffset gives the offset of the next page."),
+		mcp.WithString("namespace", mcp.Description("Only list this namespace. If empty, all namespaces are listed.")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of failing pods to return (default: 100, max: 500)")),
+		mcp.WithNumber("offset", mcp.Description("Number of failing pods to skip, the nextOffset of the previous page (default: 0)")),
+		withConfirmLargeScan(),
+	)
+}
+
+// DiagnoseInitContainersTool creates a tool for analyzing pods stuck initializing.
+// It defines the tool's name, description, and parameters for the namespace,
+// pod, label selector, and number of log lines.
+func DiagnoseInitContainersTool() mcp.Tool {
+	return mcp.NewTool(
+		"diagnoseInitContainers",
+		mcp.WithDescription("Analyze pods stuck in Init, such as Init:CrashLoopBackOff, Init:Error or Init:0/2: which init container is failing, "+
+			"its exit codes, the tail of its logs (of the previous attempt while it waits to restart), the pod's recent events, and whether the "+
+			"ConfigMaps, Secrets and PersistentVolumeClaims it references through env, envFrom and mounted volumes exist and hold the referenced keys. "+
+			"Secret values are never returned."),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pods (default: 'default')")),
+		mcp.WithString("podName", mcp.Description("The pod to analyze. If empty, every pod of the namespace stuck initializing is analyzed.")),
+		mcp.WithString("labelSelector", mcp.Description("Only analyze pods matching this label selector, when no podName is given")),
+		mcp.WithNumber("tailLines", mcp.Description("Number of log lines of the failing init container to return (default: 50)")),
+	)
+}
+
+// GetPodStartupBreakdownTool creates a tool for attributing slow pod starts.
+// It defines the tool's name, description, and parameters for the workload
+// or label selector and the number of pods to measure.
+func GetPodStartupBreakdownTool() mcp.Tool {
+	return mcp.NewTool(
+		"getPodStartupBreakdown",
+		mcp.WithDescription("Measure how long the most recent pods of a workload took to become ready, split into scheduling latency, "+
+			"image pull duration (from the kubelet's Pulled events), the remaining container start time (volumes, init containers, container creation) "+
+			"and probe warm-up until Ready, with the median and maximum of each phase and the dominant one. "+
+			"Use it to attribute slow starts to pulls, scheduling or probes. Give either name or labelSelector."),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pods (default: 'default')")),
+		mcp.WithString("kind", mcp.Description("Kind of the workload, e.g. Deployment, StatefulSet, DaemonSet or Job (default: Deployment)")),
+		mcp.WithString("name", mcp.Description("Name of the workload whose pods to measure")),
+		mcp.WithString("labelSelector", mcp.Description("Measure the pods matching this label selector instead of a workload's")),
+		mcp.WithNumber("maxPods", mcp.Description("Number of most recently created pods to measure (default: 10)")),
+	)
+}
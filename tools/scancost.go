@@ -0,0 +1,15 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withConfirmLargeScan adds the confirmLargeScan parameter to tools that may
+// list a kind across all namespaces, letting callers go ahead with a scan the
+// server estimated to be larger than its threshold.
+func withConfirmLargeScan() mcp.ToolOption {
+	return mcp.WithBoolean("confirmLargeScan",
+		mcp.Description("Go ahead with a scan across namespaces that the server estimates to return more objects than its threshold. "+
+			"Without it, such a scan returns a warning with the estimate instead (default: false)"),
+	)
+}
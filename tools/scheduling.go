@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ExplainSchedulingTool creates a tool for explaining where a pod can be scheduled.
+// It defines the tool's name, description, and parameters for the pod or
+// workload to simulate.
+func ExplainSchedulingTool() mcp.Tool {
+	return mcp.NewTool(
+		"explainScheduling",
+		mcp.WithDescription("Explain why a pod is Pending, or where a workload's pods could run, by simulating the scheduler's filters against every live node: "+
+			"nodeName, unschedulable nodes, nodeSelector, required node affinity, taints and tolerations, host ports and resource requests against free allocatable capacity. "+
+			"Returns the fitting nodes and, for every other node, each failing filter with its reason, plus a FailedScheduling-style summary. "+
+			"Inter-pod affinity, topology spread and volume filters are not simulated."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the pod or workload")),
+		mcp.WithString("kind", mcp.Description("Pod, or a workload with a pod template such as Deployment, StatefulSet, DaemonSet, Job or CronJob (default: Pod)")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pod or workload (default: 'default')")),
+	)
+}
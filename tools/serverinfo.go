@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetServerInfoTool creates a tool for reporting how the server is configured.
+// It defines the tool's name and description; the tool takes no parameters.
+func GetServerInfoTool() mcp.Tool {
+	return mcp.NewTool(
+		"getServerInfo",
+		mcp.WithDescription("Report how this MCP server is configured and connects to the cluster: whether it uses the in-cluster service account or a kubeconfig, "+
+			"the context, API server address and Kubernetes version, the user it is authenticated as, and server settings such as the transport and read-only mode."),
+	)
+}
+
+// RefreshCapabilitiesTool creates a tool for probing the cluster's optional APIs again.
+// It defines the tool's name and description; the tool takes no parameters.
+func RefreshCapabilitiesTool() mcp.Tool {
+	return mcp.NewTool(
+		"refreshCapabilities",
+		mcp.WithDescription("Probe the cluster again for optional APIs, such as the metrics API, Helm's release storage and the CRDs of Istio, KEDA, Knative, Velero, Cluster API, OLM, Argo CD and Flux, "+
+			"and register or remove the tools that depend on them. Tools are filtered this way at startup; call this after installing or removing one of these components."),
+	)
+}
+
+// CacheStatsTool creates a tool for reporting the state of the resource cache.
+// It defines the tool's name and description; the tool takes no parameters.
+func CacheStatsTool() mcp.Tool {
+	return mcp.NewTool(
+		"cacheStats",
+		mcp.WithDescription("Report the informer cache listResources serves hot kinds from: the cached kinds with the objects held, whether each informer has synced, "+
+			"when it started and was last used, and the lists served from the cache (hits), sent to the API server while an informer synced (misses), "+
+			"or sent there because the cache cannot evaluate their field selector (bypassed)."),
+	)
+}
@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SetSessionDefaultsTool creates a tool for pinning default arguments for the rest of the session.
+// It defines the tool's name, description, and parameters for the context,
+// namespace, label selector and identity to impersonate to pin.
+func SetSessionDefaultsTool() mcp.Tool {
+	return mcp.NewTool(
+		"setSessionDefaults",
+		mcp.WithDescription("Pin a default context, namespace, labelSelector and/or identity to impersonate for the rest of this session, like 'use namespace X'. "+
+			"Subsequent calls that omit these arguments use the pinned values, including executePlan steps; arguments given explicitly, "+
+			"even as empty strings, take precedence. Pass an empty string to unpin a value, or clear to unpin everything. Returns the active defaults."),
+		mcp.WithString("namespace", mcp.Description("Namespace to use when a call omits the namespace; empty to unpin")),
+		mcp.WithString("labelSelector", mcp.Description("Label selector to use when a call omits the labelSelector; empty to unpin")),
+		mcp.WithString("context", mcp.Description("Kubeconfig context to query when a call omits the context, see listContexts; empty to unpin")),
+		mcp.WithString("impersonateUser", mcp.Description("Kubernetes user to impersonate when a call names no user or service account, so calls are constrained to its RBAC; empty to unpin")),
+		mcp.WithString("impersonateServiceAccount", mcp.Description("Service account to impersonate, as namespace/name, when a call names no user or service account; empty to unpin")),
+		mcp.WithArray("impersonateGroups", mcp.Description("Groups to impersonate along with the pinned user or service account; an empty array to unpin"), mcp.WithStringItems()),
+		mcp.WithBoolean("clear", mcp.Description("Unpin all defaults before applying the other arguments (default: false)")),
+	)
+}
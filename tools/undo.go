@@ -0,0 +1,16 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// UndoLastChangeTool creates a tool for reverting the most recent change made by the server.
+// It defines the tool's name and description; the tool takes no parameters.
+func UndoLastChangeTool() mcp.Tool {
+	return mcp.NewTool(
+		"undoLastChange",
+		mcp.WithDescription("Revert the most recent change this server made in the current session (apply, delete, scale, setImage, rolloutRestart, "+
+			"pauseRollout/resumeRollout, setSuspended, setAutoscaling, including executePlan steps). The object is restored to the state recorded before "+
+			"the change, recreated if it was deleted, or deleted if the change created it. Call repeatedly to step further back; the last 50 changes are kept."),
+	)
+}
@@ -0,0 +1,20 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetUsageHistoryTool creates a tool for reading the sampled usage of a pod or
+// node. It defines the tool's name, description, and parameters for the
+// object and the time window to return.
+func GetUsageHistoryTool() mcp.Tool {
+	return mcp.NewTool(
+		"getUsageHistory",
+		mcp.WithDescription("Get the CPU and memory usage of a pod or node over the last minutes, from samples of the metrics API kept in memory by the server. "+
+			"Returns each sample and the minimum, maximum, average, latest value and change, to spot short-term trends such as a memory leak or a CPU spike."),
+		mcp.WithString("kind", mcp.Description("The kind of the object (default: Pod)"), mcp.Enum("Pod", "Node")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the pod or node")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pod (default: default)")),
+		mcp.WithNumber("minutes", mcp.Description("How many minutes of history to return, up to the server's retention (default: 30)")),
+	)
+}
@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetVeleroBackupsTool creates a tool for listing Velero backups and restores.
+// It defines the tool's name, description, and parameters for the Velero
+// namespace and whether restores are included.
+func GetVeleroBackupsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getVeleroBackups",
+		mcp.WithDescription("List Velero Backups and Restores with phase, errors, included namespaces and expiry. Requires Velero to be installed."),
+		mcp.WithString("veleroNamespace", mcp.Description("The namespace Velero is installed in (default: 'velero')")),
+		mcp.WithBoolean("includeRestores", mcp.Description("Include Restores in the result (default: true)")),
+	)
+}
+
+// CreateVeleroBackupTool creates a tool for triggering a Velero backup of a namespace.
+func CreateVeleroBackupTool() mcp.Tool {
+	return mcp.NewTool(
+		"createVeleroBackup",
+		mcp.WithDescription("Trigger a Velero Backup of a namespace, e.g. before a risky change"),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace to back up")),
+		mcp.WithString("backupName", mcp.Description("The name of the Backup (default: '<namespace>-<timestamp>')")),
+		mcp.WithString("ttl", mcp.Description("How long the backup is retained, as a duration (e.g. '72h'). Defaults to Velero's setting.")),
+		mcp.WithString("storageLocation", mcp.Description("The BackupStorageLocation to use. Defaults to Velero's default location.")),
+		mcp.WithString("veleroNamespace", mcp.Description("The namespace Velero is installed in (default: 'velero')")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the Backup as a server-side dry run without creating it (default: false)")),
+	)
+}
@@ -0,0 +1,25 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WaitForTool creates a tool for waiting until resources reach a condition.
+// It defines the tool's name, description, and parameters for the objects to
+// watch, the condition to wait for and the timeout.
+func WaitForTool() mcp.Tool {
+	return mcp.NewTool(
+		"waitFor",
+		mcp.WithDescription("Wait until an object, or every object matching a label selector, meets a condition, like kubectl wait. "+
+			"Use instead of polling getResource across turns, e.g. for a Pod to be Ready, a Deployment Available, a Job Complete or an object deleted. "+
+			"Returns whether the condition was met, the time waited and the last observed state of each object; on timeout met is false and pending lists what was still waiting. "+
+			"Progress notifications are sent while waiting when the request has a progress token."),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The kind of the objects to wait for, e.g. 'Pod', 'Deployment' or 'Job'")),
+		mcp.WithString("name", mcp.Description("The name of the object to wait for (set exactly one of name and labelSelector)")),
+		mcp.WithString("labelSelector", mcp.Description("A label selector; waits until every matching object meets the condition")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the objects (default: 'default' for namespaced kinds)")),
+		mcp.WithString("for", mcp.Required(), mcp.Description("The condition: 'condition=<type>[=<status>]' (status defaults to True, e.g. 'condition=Available'), "+
+			"'jsonpath={<path>}=<value>' (e.g. 'jsonpath={.status.phase}=Running') or 'delete'")),
+		mcp.WithNumber("timeoutSeconds", mcp.Description("How long to wait, up to 600 seconds (default: 60)")),
+	)
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WatchResourcesTool creates a tool for watching resources for a short window.
+// It defines the tool's name, description, and parameters for the kind,
+// selectors, duration and starting resourceVersion of the watch.
+func WatchResourcesTool() mcp.Tool {
+	return mcp.NewTool(
+		"watchResources",
+		mcp.WithDescription("Watch the objects of a kind for a bounded time and return the ADDED, MODIFIED and DELETED deltas observed, in order. "+
+			"Each delta has its offset in seconds, the object's phase and conditions and, for MODIFIED, the changed field paths. "+
+			"Use it to verify that a controller reacts to a change, e.g. watch Pods with the app's label right after updating a Deployment. "+
+			"Each delta is also sent as a notifications/message log notification as it arrives. The result's resourceVersion can be passed to the next call to resume without missing changes."),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The kind of the objects to watch, e.g. 'Pod' or 'Deployment'")),
+		mcp.WithString("namespace", mcp.Description("The namespace to watch; if empty, namespaced kinds are watched across all namespaces")),
+		mcp.WithString("labelSelector", mcp.Description("A label selector to filter the watched objects")),
+		mcp.WithString("fieldSelector", mcp.Description("A field selector to filter the watched objects, e.g. 'metadata.name=web'")),
+		mcp.WithNumber("durationSeconds", mcp.Description("How long to watch, up to 300 seconds (default: 30)")),
+		mcp.WithNumber("maxEvents", mcp.Description("Stop after this many deltas (default: 100)")),
+		mcp.WithString("resourceVersion", mcp.Description("Resume from this resourceVersion, as returned by a previous watch, instead of starting from the current state")),
+	)
+}
+
+// WatchEventsTool creates a tool for watching events in the background.
+// It defines the tool's name, description, and parameters for the namespace,
+// the event filters and how long the watch runs.
+func WatchEventsTool() mcp.Tool {
+	return mcp.NewTool(
+		"watchEvents",
+		mcp.WithDescription("Start watching Kubernetes events in the background, so transient failures are caught that polling getEvents misses. "+
+			"Each new event matching the filters is sent to the client as a notifications/message log notification and held until collected "+
+			"with getWatchedEvents. The watch belongs to the MCP session and runs until stopWatchEvents, its duration elapses, or the session ends."),
+		mcp.WithString("namespace", mcp.Description("The namespace to watch; if empty, events of all namespaces are watched")),
+		mcp.WithArray("types", mcp.Description("Only report events of these types (default: all)"), mcp.WithStringEnumItems([]string{"Normal", "Warning"})),
+		mcp.WithArray("reasons", mcp.Description("Only report events with these reasons, e.g. BackOff, FailedScheduling or Unhealthy (default: all)"), mcp.WithStringItems()),
+		mcp.WithNumber("durationMinutes", mcp.Description("How long to watch before the watch expires, up to 1440 minutes (default: 60)")),
+	)
+}
+
+// GetWatchedEventsTool creates a tool for collecting the events of an event watch.
+// It defines the tool's name, description, and the watch ID parameter.
+func GetWatchedEventsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getWatchedEvents",
+		mcp.WithDescription("Collect the events an event watch started with watchEvents has seen since they were last collected, oldest first, "+
+			"with the state of the watch. Each event is returned once."),
+		mcp.WithString("id", mcp.Required(), mcp.Description("The ID of the event watch, as returned by watchEvents")),
+	)
+}
+
+// StopWatchEventsTool creates a tool for stopping an event watch.
+// It defines the tool's name, description, and the watch ID parameter.
+func StopWatchEventsTool() mcp.Tool {
+	return mcp.NewTool(
+		"stopWatchEvents",
+		mcp.WithDescription("Stop an event watch started in this session, returning the events not yet collected"),
+		mcp.WithString("id", mcp.Required(), mcp.Description("The ID of the event watch, as returned by watchEvents")),
+	)
+}
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SetAutoscalingTool creates a tool for creating or updating a HorizontalPodAutoscaler.
+// It defines the tool's name, description, and parameters for the target workload,
+// replica bounds, and CPU/memory utilization targets.
+func SetAutoscalingTool() mcp.Tool {
+	return mcp.NewTool(
+		"setAutoscaling",
+		mcp.WithDescription("Create or update an autoscaling/v2 HorizontalPodAutoscaler for a workload, named after the workload. "+
+			"Sets min/max replicas and average CPU and/or memory utilization targets (percent of requests). "+
+			"If no target is given, a CPU target of 80% is used. Existing HPA behavior settings are preserved."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the workload to autoscale")),
+		mcp.WithString("kind", mcp.Description("The kind of the workload (default: Deployment). E.g. Deployment, StatefulSet, ReplicaSet")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the workload (default: 'default')")),
+		mcp.WithNumber("minReplicas", mcp.Description("Minimum number of replicas (default: 1)")),
+		mcp.WithNumber("maxReplicas", mcp.Required(), mcp.Description("Maximum number of replicas")),
+		mcp.WithNumber("cpuUtilization", mcp.Description("Target average CPU utilization in percent of requests")),
+		mcp.WithNumber("memoryUtilization", mcp.Description("Target average memory utilization in percent of requests")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the change as a server-side dry run without persisting it (default: false)")),
+	)
+}
+
+// withRolloutWait adds the waitForRollout and rolloutTimeoutSeconds parameters
+// to tools that change workloads, letting callers get the outcome of the
+// resulting rollout in the same result.
+func withRolloutWait() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithBoolean("waitForRollout",
+			mcp.Description("Wait until the resulting rollout of a Deployment, StatefulSet or DaemonSet is healthy or has failed, and include its outcome (default: false)"),
+		)(t)
+		mcp.WithNumber("rolloutTimeoutSeconds", mcp.Description("How long to wait for the rollout, up to 600 seconds (default: 120)"))(t)
+	}
+}
+
+// SetImageTool creates a tool for updating a container image in a workload.
+// It defines the tool's name, description, and parameters for the workload,
+// container, and image.
+func SetImageTool() mcp.Tool {
+	return mcp.NewTool(
+		"setImage",
+		mcp.WithDescription("Update the image of a single container in a Deployment, StatefulSet, DaemonSet, ReplicaSet, Job template or CronJob, "+
+			"patching only that field. Returns the previous and new image and the revision of the triggered rollout."),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The kind of the workload (e.g. Deployment, StatefulSet, DaemonSet, CronJob)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the workload")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the workload (default: 'default')")),
+		mcp.WithString("container", mcp.Description("The container to update. May be omitted if the workload has a single container. Init containers are also matched by name.")),
+		mcp.WithString("image", mcp.Required(), mcp.Description("The new image reference (e.g. 'nginx:1.27')")),
+		withRolloutWait(),
+	)
+}
+
+// PauseRolloutTool creates a tool for pausing a Deployment rollout.
+// It defines the tool's name, description, and parameters for the Deployment name and namespace.
+func PauseRolloutTool() mcp.Tool {
+	return mcp.NewTool(
+		"pauseRollout",
+		mcp.WithDescription("Pause the rollout of a Deployment (sets spec.paused). While paused, pod template changes do not start a new rollout, "+
+			"so several spec changes can be batched or a bad rollout halted mid-flight. Use resumeRollout to continue."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the Deployment")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the Deployment (default: 'default')")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the change as a server-side dry run without persisting it (default: false)")),
+	)
+}
+
+// ResumeRolloutTool creates a tool for resuming a paused Deployment rollout.
+// It defines the tool's name, description, and parameters for the Deployment name and namespace.
+func ResumeRolloutTool() mcp.Tool {
+	return mcp.NewTool(
+		"resumeRollout",
+		mcp.WithDescription("Resume a paused Deployment rollout (clears spec.paused). Pending pod template changes are rolled out."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the Deployment")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the Deployment (default: 'default')")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the change as a server-side dry run without persisting it (default: false)")),
+	)
+}
+
+// RolloutHistoryTool creates a tool for listing the revisions of a Deployment.
+// It defines the tool's name, description, and parameters for the Deployment name and namespace.
+func RolloutHistoryTool() mcp.Tool {
+	return mcp.NewTool(
+		"rolloutHistory",
+		mcp.WithDescription("List the revisions of a Deployment, like kubectl rollout history: for each ReplicaSet, the revision number, change cause, "+
+			"images, replica counts and creation time, oldest first. Use it to pick the revision for rolloutUndo."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the Deployment")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the Deployment (default: 'default')")),
+	)
+}
+
+// GetPodRevisionTool creates a tool for mapping a pod to the Deployment revision it was created from.
+// It defines the tool's name, description, and parameters for the pod name and namespace.
+func GetPodRevisionTool() mcp.Tool {
+	return mcp.NewTool(
+		"getPodRevision",
+		mcp.WithDescription("Map a pod to the Deployment revision it was created from, through its ReplicaSet and pod-template-hash label: "+
+			"the revision number, its change cause, whether it is the Deployment's current revision, and the change causes of newer revisions. "+
+			"Answers which rollout introduced a pod."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the pod")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the pod (default: 'default')")),
+	)
+}
+
+// RolloutUndoTool creates a tool for rolling a Deployment back to a previous revision.
+// It defines the tool's name, description, and parameters for the Deployment and target revision.
+func RolloutUndoTool() mcp.Tool {
+	return mcp.NewTool(
+		"rolloutUndo",
+		mcp.WithDescription("Roll a Deployment back to a previous revision, like kubectl rollout undo: the pod template of that revision's ReplicaSet "+
+			"is restored, which starts a new rollout. Returns the revisions rolled back from and to and the restored images."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the Deployment")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the Deployment (default: 'default')")),
+		mcp.WithNumber("toRevision", mcp.Description("The revision to roll back to, as listed by rolloutHistory (default: the previous revision)")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the change as a server-side dry run without persisting it (default: false)")),
+		withRolloutWait(),
+	)
+}
+
+// SetSuspendedTool creates a tool for suspending or resuming CronJobs and similar objects.
+// It defines the tool's name, description, and parameters for the object
+// and the desired suspended state.
+func SetSuspendedTool() mcp.Tool {
+	return mcp.NewTool(
+		"setSuspended",
+		mcp.WithDescription("Suspend or resume a CronJob by toggling spec.suspend, e.g. during an incident freeze. "+
+			"Also supports Jobs, Argo CronWorkflows and Flux Kustomizations, HelmReleases and sources. "+
+			"If the object is managed by Flux, the Flux annotation disabling reconciliation is set while suspended so Flux does not revert the change."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the object")),
+		mcp.WithString("kind", mcp.Description("The kind of the object (default: CronJob)")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the object (default: 'default')")),
+		mcp.WithBoolean("suspend", mcp.Description("true to suspend, false to resume (default: true)")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the change as a server-side dry run without persisting it (default: false)")),
+	)
+}
+
+// ScaleResourceTool creates a tool for scaling workloads.
+// It defines the tool's name, description, and parameters for the workload
+// and the desired replica count.
+func ScaleResourceTool() mcp.Tool {
+	return mcp.NewTool(
+		"scaleResource",
+		mcp.WithDescription("Set the replica count of a Deployment, StatefulSet, ReplicaSet or any other kind with the scale subresource, like kubectl scale. "+
+			"Returns the previous and new replica counts. If a HorizontalPodAutoscaler targets the workload, it may override the new count."),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The kind of the workload (e.g. Deployment, StatefulSet, ReplicaSet)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the workload")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the workload (default: 'default')")),
+		mcp.WithNumber("replicas", mcp.Required(), mcp.Description("The desired number of replicas (0 or more)")),
+		mcp.WithBoolean("dryRun", mcp.Description("Submit the change as a server-side dry run without persisting it (default: false)")),
+		withRolloutWait(),
+	)
+}
+
+// RolloutStatusTool creates a tool for checking the rollout of a workload.
+// It defines the tool's name, description, and parameters for the workload
+// and how long to wait.
+func RolloutStatusTool() mcp.Tool {
+	return mcp.NewTool(
+		"rolloutStatus",
+		mcp.WithDescription("Wait until the rollout of a Deployment, StatefulSet or DaemonSet is healthy or has failed, like kubectl rollout status. "+
+			"Returns the state (healthy, progressing, failed or unsupported), a message like kubectl's and the current revision. "+
+			"A rollout has failed when a Deployment exceeds its progress deadline or pods are stuck in CrashLoopBackOff, ImagePullBackOff or similar states; the stuck pods are listed."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the workload")),
+		mcp.WithString("kind", mcp.Description("The kind of the workload: Deployment, StatefulSet or DaemonSet (default: Deployment)")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the workload (default: 'default')")),
+		mcp.WithNumber("timeoutSeconds", mcp.Description("How long to wait, up to 600 seconds; 0 checks once without waiting (default: 60)")),
+	)
+}
+
+// RestartConfigMapConsumersTool creates a tool for restarting the workloads consuming a ConfigMap.
+// It defines the tool's name, description, and parameters for the ConfigMap, whether to
+// restart only consumers that need it, and waiting for the rollouts.
+func RestartConfigMapConsumersTool() mcp.Tool {
+	return mcp.NewTool(
+		"restartConfigMapConsumers",
+		mcp.WithDescription("Trigger a rollout restart of exactly the Deployments, StatefulSets and DaemonSets whose pod template references a ConfigMap, "+
+			"so they pick up a change to it. CronJobs and bare pods are reported as skipped. Each restart can be undone with undoLastChange."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the ConfigMap")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the ConfigMap and its consumers (default: 'default')")),
+		mcp.WithBoolean("onlyRestartRequired", mcp.Description("Skip consumers that mount the ConfigMap as a volume without subPath, which the kubelet updates in place. "+
+			"Only use this if the applications reload their config files (default: false)")),
+		withRolloutWait(),
+	)
+}